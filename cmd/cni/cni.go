@@ -10,6 +10,7 @@ import (
 	"github.com/containernetworking/cni/pkg/skel"
 	"github.com/containernetworking/cni/pkg/types"
 	current "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/cni/pkg/types/create"
 	"github.com/containernetworking/cni/pkg/version"
 
 	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
@@ -18,6 +19,9 @@ import (
 	"github.com/kubeovn/kube-ovn/versions"
 )
 
+// errPluginNotAvailable is defined by CNI spec 1.1.0 for the STATUS verb
+const errPluginNotAvailable uint = 50
+
 func CmdMain() {
 	// this ensures that main runs only on main thread (thread group leader).
 	// since namespace ops (unshare, setns) are done for a single thread, we
@@ -25,7 +29,12 @@ func CmdMain() {
 	runtime.LockOSThread()
 
 	about := fmt.Sprintf("CNI kube-ovn plugin %s", versions.VERSION)
-	skel.PluginMain(cmdAdd, nil, cmdDel, version.All, about)
+	skel.PluginMainFuncs(skel.CNIFuncs{
+		Add:    cmdAdd,
+		Del:    cmdDel,
+		Check:  cmdCheck,
+		Status: cmdStatus,
+	}, version.All, about)
 }
 
 func cmdAdd(args *skel.CmdArgs) error {
@@ -166,6 +175,90 @@ func cmdDel(args *skel.CmdArgs) error {
 	return nil
 }
 
+func cmdCheck(args *skel.CmdArgs) error {
+	netConf, _, err := loadNetConf(args.StdinData)
+	if err != nil {
+		return err
+	}
+	prevResult, err := parsePrevResult(netConf)
+	if err != nil {
+		return err
+	}
+	podName, err := parseValueFromArgs("K8S_POD_NAME", args.Args)
+	if err != nil {
+		return err
+	}
+	podNamespace, err := parseValueFromArgs("K8S_POD_NAMESPACE", args.Args)
+	if err != nil {
+		return err
+	}
+	if netConf.Provider == "" && netConf.Type == util.CniTypeName && args.IfName == "eth0" {
+		netConf.Provider = util.OvnProvider
+	}
+
+	var ips []string
+	for _, ip := range prevResult.IPs {
+		ips = append(ips, ip.Address.String())
+	}
+	var mac string
+	if len(prevResult.Interfaces) != 0 {
+		mac = prevResult.Interfaces[0].Mac
+	}
+
+	client := request.NewCniServerClient(netConf.ServerSocket)
+	err = client.Check(request.CniRequest{
+		CniType:                   netConf.Type,
+		PodName:                   podName,
+		PodNamespace:              podNamespace,
+		ContainerID:               args.ContainerID,
+		NetNs:                     args.Netns,
+		IfName:                    args.IfName,
+		Provider:                  netConf.Provider,
+		Routes:                    netConf.Routes,
+		DeviceID:                  netConf.DeviceID,
+		VhostUserSocketVolumeName: netConf.VhostUserSocketVolumeName,
+		PrevIPs:                   ips,
+		PrevMac:                   mac,
+	})
+	if err != nil {
+		return types.NewError(types.ErrInternal, "CHECK failed", err.Error())
+	}
+	return nil
+}
+
+// parsePrevResult returns the result of ADD which the runtime passes to CHECK in prevResult
+func parsePrevResult(n *netConf) (*current.Result, error) {
+	if n.RawPrevResult == nil {
+		return nil, types.NewError(types.ErrInvalidNetworkConfig, "Invalid Configuration", "prevResult is required by CHECK")
+	}
+	resultBytes, err := json.Marshal(n.RawPrevResult)
+	if err != nil {
+		return nil, types.NewError(types.ErrDecodingFailure, "failed to encode prevResult", err.Error())
+	}
+	res, err := create.Create(n.CNIVersion, resultBytes)
+	if err != nil {
+		return nil, types.NewError(types.ErrDecodingFailure, "failed to parse prevResult", err.Error())
+	}
+	result, err := current.NewResultFromResult(res)
+	if err != nil {
+		return nil, types.NewError(types.ErrDecodingFailure, "failed to convert prevResult", err.Error())
+	}
+	return result, nil
+}
+
+func cmdStatus(args *skel.CmdArgs) error {
+	netConf, _, err := loadNetConf(args.StdinData)
+	if err != nil {
+		return err
+	}
+
+	client := request.NewCniServerClient(netConf.ServerSocket)
+	if err = client.Status(); err != nil {
+		return types.NewError(errPluginNotAvailable, "kube-ovn is not available", err.Error())
+	}
+	return nil
+}
+
 type ipamConf struct {
 	ServerSocket string `json:"server_socket"`
 	Provider     string `json:"provider"`
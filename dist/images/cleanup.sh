@@ -10,6 +10,10 @@ while :; do
   sleep 5
 done
 
+for ippool in $(kubectl get ippool -o name); do
+  kubectl delete --ignore-not-found $ippool
+done
+
 set +e
 for subnet in $(kubectl get subnet -o name); do
   kubectl patch "$subnet" --type='json' -p '[{"op": "replace", "path": "/metadata/finalizers", "value": []}]'
@@ -57,7 +61,7 @@ sleep 5
 
 # Delete Kube-OVN components
 kubectl delete --ignore-not-found deploy kube-ovn-monitor -n kube-system
-kubectl delete --ignore-not-found cm ovn-config ovn-ic-config ovn-external-gw-config -n kube-system
+kubectl delete --ignore-not-found cm ovn-config ovn-ic-config ovn-external-gw-config ovn-ipam-checkpoint -n kube-system
 kubectl delete --ignore-not-found svc kube-ovn-pinger kube-ovn-controller kube-ovn-cni kube-ovn-monitor -n kube-system
 kubectl delete --ignore-not-found ds kube-ovn-cni -n kube-system
 kubectl delete --ignore-not-found deploy kube-ovn-controller -n kube-system
@@ -93,7 +97,9 @@ kubectl delete --ignore-not-found sa vpc-dns -n kube-system
 kubectl delete --ignore-not-found crd htbqoses.kubeovn.io security-groups.kubeovn.io ips.kubeovn.io subnets.kubeovn.io \
                                       vpc-nat-gateways.kubeovn.io vpcs.kubeovn.io vlans.kubeovn.io provider-networks.kubeovn.io \
                                       iptables-dnat-rules.kubeovn.io  iptables-eips.kubeovn.io  iptables-fip-rules.kubeovn.io \
-                                      iptables-snat-rules.kubeovn.io vips.kubeovn.io switch-lb-rules.kubeovn.io vpc-dnses.kubeovn.io
+                                      iptables-snat-rules.kubeovn.io vips.kubeovn.io switch-lb-rules.kubeovn.io vpc-dnses.kubeovn.io \
+                                      ovn-eips.kubeovn.io ovn-fips.kubeovn.io ovn-snat-rules.kubeovn.io ovn-dnat-rules.kubeovn.io \
+                                      ippools.kubeovn.io qos-policies.kubeovn.io fqdn-addresses.kubeovn.io
 
 # Remove annotations/labels in namespaces and nodes
 kubectl annotate no --all ovn.kubernetes.io/cidr-
@@ -104,6 +110,7 @@ kubectl annotate no --all ovn.kubernetes.io/mac_address-
 kubectl annotate no --all ovn.kubernetes.io/port_name-
 kubectl annotate no --all ovn.kubernetes.io/allocated-
 kubectl annotate no --all ovn.kubernetes.io/chassis- 
+kubectl annotate no --all ovn.kubernetes.io/qos_policy-
 kubectl label node --all kube-ovn/role-
 
 kubectl get no -o name | while read node; do
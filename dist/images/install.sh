@@ -12,12 +12,25 @@ VLAN_NIC=${VLAN_NIC:-}
 HW_OFFLOAD=${HW_OFFLOAD:-false}
 ENABLE_LB=${ENABLE_LB:-true}
 ENABLE_NP=${ENABLE_NP:-true}
+ENABLE_ANP=${ENABLE_ANP:-false}
 ENABLE_EIP_SNAT=${ENABLE_EIP_SNAT:-true}
 LS_DNAT_MOD_DL_DST=${LS_DNAT_MOD_DL_DST:-true}
 ENABLE_EXTERNAL_VPC=${ENABLE_EXTERNAL_VPC:-true}
 CNI_CONFIG_PRIORITY=${CNI_CONFIG_PRIORITY:-01}
 ENABLE_LB_SVC=${ENABLE_LB_SVC:-false}
+# allocate the ingress ips of loadbalancer services from the subnet or ippool, conflicts with ENABLE_LB_SVC
+LB_IPAM_SUBNET=${LB_IPAM_SUBNET:-}
+LB_IPAM_IPPOOL=${LB_IPAM_IPPOOL:-}
 ENABLE_KEEP_VM_IP=${ENABLE_KEEP_VM_IP:-true}
+ENABLE_IPAM_CHECKPOINT=${ENABLE_IPAM_CHECKPOINT:-false}
+ENABLE_OVN_NB_BATCH=${ENABLE_OVN_NB_BATCH:-false}
+# where the bandwidth limits of pods and nodes are enforced, host: ovs qos of the local
+# interfaces, ovn: qos rules of the logical switches which also support destination matches
+QOS_MODE=${QOS_MODE:-host}
+# snoop dns responses in kube-ovn-cni to resolve the domain names of fqdn egress rules
+ENABLE_FQDN_SNOOP=${ENABLE_FQDN_SNOOP:-false}
+# collect acl logs of network policies and security groups as json and metrics in kube-ovn-cni
+ENABLE_ACL_LOG_COLLECTOR=${ENABLE_ACL_LOG_COLLECTOR:-false}
 # exchange link names of OVS bridge and the provider nic
 # in the default provider-network
 EXCHANGE_LINK_NAME=${EXCHANGE_LINK_NAME:-false}
@@ -170,8 +183,12 @@ echo "Default Subnet CIDR:  $POD_CIDR"
 echo "Join Subnet CIDR:     $JOIN_CIDR"
 echo "Enable SVC LB:        $ENABLE_LB"
 echo "Enable Networkpolicy: $ENABLE_NP"
+echo "Enable AdminNetworkpolicy: $ENABLE_ANP"
 echo "Enable EIP and SNAT:  $ENABLE_EIP_SNAT"
 echo "Enable Mirror:        $ENABLE_MIRROR"
+echo "QoS Mode:             $QOS_MODE"
+echo "Enable FQDN Snoop:    $ENABLE_FQDN_SNOOP"
+echo "Enable ACL Log Collector: $ENABLE_ACL_LOG_COLLECTOR"
 echo "-------------------------------"
 
 if [[ $ENABLE_SSL = "true" ]];then
@@ -342,6 +359,21 @@ spec:
                   items:
                     type: string
                   type: array
+                healthCheck:
+                  type: object
+                  properties:
+                    interval:
+                      type: integer
+                      minimum: 1
+                    timeout:
+                      type: integer
+                      minimum: 1
+                    successCount:
+                      type: integer
+                      minimum: 1
+                    failureCount:
+                      type: integer
+                      minimum: 1
             status:
               type: object
               properties:
@@ -349,6 +381,19 @@ spec:
                   type: string
                 service:
                   type: string
+                backends:
+                  type: array
+                  items:
+                    type: object
+                    properties:
+                      ip:
+                        type: string
+                      port:
+                        type: integer
+                      protocol:
+                        type: string
+                      status:
+                        type: string
 ---
 apiVersion: apiextensions.k8s.io/v1
 kind: CustomResourceDefinition
@@ -417,6 +462,21 @@ spec:
                   items:
                     type: string
                   type: array
+                healthCheck:
+                  type: object
+                  properties:
+                    interval:
+                      type: integer
+                      minimum: 1
+                    timeout:
+                      type: integer
+                      minimum: 1
+                    successCount:
+                      type: integer
+                      minimum: 1
+                    failureCount:
+                      type: integer
+                      minimum: 1
             status:
               type: object
               properties:
@@ -424,6 +484,19 @@ spec:
                   type: string
                 service:
                   type: string
+                backends:
+                  type: array
+                  items:
+                    type: object
+                    properties:
+                      ip:
+                        type: string
+                      port:
+                        type: integer
+                      protocol:
+                        type: string
+                      status:
+                        type: string
 ---
 apiVersion: apiextensions.k8s.io/v1
 kind: CustomResourceDefinition
@@ -450,16 +523,32 @@ spec:
         - jsonPath: .spec.lanIp
           name: LanIP
           type: string
+        - jsonPath: .status.activePod
+          name: ActivePod
+          type: string
       name: v1
       served: true
       storage: true
+      subresources:
+        status: {}
       schema:
         openAPIV3Schema:
           type: object
           properties:
+            status:
+              type: object
+              properties:
+                activePod:
+                  type: string
+                activeLanIp:
+                  type: string
             spec:
               type: object
               properties:
+                ha:
+                  type: boolean
+                standbyLanIp:
+                  type: string
                 lanIp:
                   type: string
                 subnet:
@@ -537,6 +626,10 @@ spec:
                   type: string
                 redo:
                   type: string
+                ingressRate:
+                  type: string
+                egressRate:
+                  type: string
                 conditions:
                   type: array
                   items:
@@ -565,6 +658,10 @@ spec:
                   type: string
                 natGwDp:
                   type: string
+                ingressRate:
+                  type: string
+                egressRate:
+                  type: string
 ---
 apiVersion: apiextensions.k8s.io/v1
 kind: CustomResourceDefinition
@@ -622,6 +719,10 @@ spec:
                   type: string
                 redo:
                   type: string
+                ingressRate:
+                  type: string
+                egressRate:
+                  type: string
                 conditions:
                   type: array
                   items:
@@ -646,6 +747,10 @@ spec:
                   type: string
                 internalIp:
                   type: string
+                ingressRate:
+                  type: string
+                egressRate:
+                  type: string
 ---
 apiVersion: apiextensions.k8s.io/v1
 kind: CustomResourceDefinition
@@ -712,6 +817,10 @@ spec:
                   type: string
                 redo:
                   type: string
+                ingressRate:
+                  type: string
+                egressRate:
+                  type: string
                 conditions:
                   type: array
                   items:
@@ -742,6 +851,10 @@ spec:
                   type: string
                 internalPort:
                   type: string
+                ingressRate:
+                  type: string
+                egressRate:
+                  type: string
 ---
 apiVersion: apiextensions.k8s.io/v1
 kind: CustomResourceDefinition
@@ -826,6 +939,248 @@ spec:
 ---
 apiVersion: apiextensions.k8s.io/v1
 kind: CustomResourceDefinition
+metadata:
+  name: ovn-eips.kubeovn.io
+spec:
+  group: kubeovn.io
+  names:
+    plural: ovn-eips
+    singular: ovn-eip
+    shortNames:
+      - oeip
+    kind: OvnEip
+    listKind: OvnEipList
+  scope: Cluster
+  versions:
+    - name: v1
+      served: true
+      storage: true
+      subresources:
+        status: {}
+      additionalPrinterColumns:
+      - jsonPath: .status.v4ip
+        name: V4IP
+        type: string
+      - jsonPath: .status.macAddress
+        name: Mac
+        type: string
+      - jsonPath: .spec.type
+        name: Type
+        type: string
+      - jsonPath: .status.ready
+        name: Ready
+        type: boolean
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            status:
+              type: object
+              properties:
+                ready:
+                  type: boolean
+                v4ip:
+                  type: string
+                macAddress:
+                  type: string
+            spec:
+              type: object
+              properties:
+                v4ip:
+                  type: string
+                macAddress:
+                  type: string
+                type:
+                  type: string
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: ovn-fips.kubeovn.io
+spec:
+  group: kubeovn.io
+  names:
+    plural: ovn-fips
+    singular: ovn-fip
+    shortNames:
+      - ofip
+    kind: OvnFip
+    listKind: OvnFipList
+  scope: Cluster
+  versions:
+    - name: v1
+      served: true
+      storage: true
+      subresources:
+        status: {}
+      additionalPrinterColumns:
+      - jsonPath: .status.vpc
+        name: Vpc
+        type: string
+      - jsonPath: .status.v4Eip
+        name: V4Eip
+        type: string
+      - jsonPath: .status.v4Ip
+        name: V4Ip
+        type: string
+      - jsonPath: .status.ready
+        name: Ready
+        type: boolean
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            status:
+              type: object
+              properties:
+                ready:
+                  type: boolean
+                vpc:
+                  type: string
+                v4Eip:
+                  type: string
+                v4Ip:
+                  type: string
+            spec:
+              type: object
+              properties:
+                ovnEip:
+                  type: string
+                ipName:
+                  type: string
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: ovn-snat-rules.kubeovn.io
+spec:
+  group: kubeovn.io
+  names:
+    plural: ovn-snat-rules
+    singular: ovn-snat-rule
+    shortNames:
+      - osnat
+    kind: OvnSnatRule
+    listKind: OvnSnatRuleList
+  scope: Cluster
+  versions:
+    - name: v1
+      served: true
+      storage: true
+      subresources:
+        status: {}
+      additionalPrinterColumns:
+      - jsonPath: .status.vpc
+        name: Vpc
+        type: string
+      - jsonPath: .status.v4Eip
+        name: V4Eip
+        type: string
+      - jsonPath: .status.v4IpCidr
+        name: V4IpCidr
+        type: string
+      - jsonPath: .status.ready
+        name: Ready
+        type: boolean
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            status:
+              type: object
+              properties:
+                ready:
+                  type: boolean
+                vpc:
+                  type: string
+                v4Eip:
+                  type: string
+                v4IpCidr:
+                  type: string
+            spec:
+              type: object
+              properties:
+                ovnEip:
+                  type: string
+                vpcSubnet:
+                  type: string
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: ovn-dnat-rules.kubeovn.io
+spec:
+  group: kubeovn.io
+  names:
+    plural: ovn-dnat-rules
+    singular: ovn-dnat-rule
+    shortNames:
+      - odnat
+    kind: OvnDnatRule
+    listKind: OvnDnatRuleList
+  scope: Cluster
+  versions:
+    - name: v1
+      served: true
+      storage: true
+      subresources:
+        status: {}
+      additionalPrinterColumns:
+      - jsonPath: .status.vpc
+        name: Vpc
+        type: string
+      - jsonPath: .status.v4Eip
+        name: V4Eip
+        type: string
+      - jsonPath: .spec.protocol
+        name: Protocol
+        type: string
+      - jsonPath: .spec.externalPort
+        name: ExternalPort
+        type: string
+      - jsonPath: .status.v4Ip
+        name: V4Ip
+        type: string
+      - jsonPath: .spec.internalPort
+        name: InternalPort
+        type: string
+      - jsonPath: .status.ready
+        name: Ready
+        type: boolean
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            status:
+              type: object
+              properties:
+                ready:
+                  type: boolean
+                vpc:
+                  type: string
+                v4Eip:
+                  type: string
+                v4Ip:
+                  type: string
+            spec:
+              type: object
+              properties:
+                ovnEip:
+                  type: string
+                ipName:
+                  type: string
+                protocol:
+                  type: string
+                  enum:
+                    - tcp
+                    - udp
+                internalPort:
+                  type: string
+                externalPort:
+                  type: string
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
 metadata:
   name: vpcs.kubeovn.io
 spec:
@@ -847,6 +1202,8 @@ spec:
           properties:
             spec:
               properties:
+                enableExternal:
+                  type: boolean
                 namespaces:
                   items:
                     type: string
@@ -1018,6 +1375,114 @@ spec:
 ---
 apiVersion: apiextensions.k8s.io/v1
 kind: CustomResourceDefinition
+metadata:
+  name: ippools.kubeovn.io
+spec:
+  group: kubeovn.io
+  versions:
+    - name: v1
+      served: true
+      storage: true
+      subresources:
+        status: {}
+      additionalPrinterColumns:
+      - name: Subnet
+        type: string
+        jsonPath: .spec.subnet
+      - name: IPs
+        type: string
+        jsonPath: .spec.ips
+      - name: V4Used
+        type: number
+        jsonPath: .status.v4UsingIPs
+      - name: V4Available
+        type: number
+        jsonPath: .status.v4AvailableIPs
+      - name: V6Used
+        type: number
+        jsonPath: .status.v6UsingIPs
+      - name: V6Available
+        type: number
+        jsonPath: .status.v6AvailableIPs
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            spec:
+              type: object
+              required:
+                - subnet
+                - ips
+              properties:
+                subnet:
+                  type: string
+                ips:
+                  type: array
+                  minItems: 1
+                  items:
+                    type: string
+                namespaces:
+                  type: array
+                  items:
+                    type: string
+                selector:
+                  type: object
+                  properties:
+                    matchLabels:
+                      type: object
+                      additionalProperties:
+                        type: string
+                    matchExpressions:
+                      type: array
+                      items:
+                        type: object
+                        properties:
+                          key:
+                            type: string
+                          operator:
+                            type: string
+                          values:
+                            type: array
+                            items:
+                              type: string
+            status:
+              type: object
+              properties:
+                v4AvailableIPs:
+                  type: number
+                v4UsingIPs:
+                  type: number
+                v6AvailableIPs:
+                  type: number
+                v6UsingIPs:
+                  type: number
+                conditions:
+                  type: array
+                  items:
+                    type: object
+                    properties:
+                      type:
+                        type: string
+                      status:
+                        type: string
+                      reason:
+                        type: string
+                      message:
+                        type: string
+                      lastUpdateTime:
+                        type: string
+                      lastTransitionTime:
+                        type: string
+  scope: Cluster
+  names:
+    plural: ippools
+    singular: ippool
+    kind: IPPool
+    shortNames:
+      - ippool
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
 metadata:
   name: vips.kubeovn.io
 spec:
@@ -1194,6 +1659,19 @@ spec:
                   type: string
                 dhcpV6OptionsUUID:
                   type: string
+                cidrUsage:
+                  type: array
+                  items:
+                    type: object
+                    properties:
+                      cidr:
+                        type: string
+                      availableIPs:
+                        type: number
+                      usingIPs:
+                        type: number
+                dscp:
+                  type: integer
                 conditions:
                   type: array
                   items:
@@ -1226,6 +1704,10 @@ spec:
                     - Dual
                 cidrBlock:
                   type: string
+                secondaryCIDRBlocks:
+                  type: array
+                  items:
+                    type: string
                 namespaces:
                   type: array
                   items:
@@ -1282,6 +1764,10 @@ spec:
                   type: boolean
                 htbqos:
                   type: string
+                dscp:
+                  type: integer
+                  minimum: 0
+                  maximum: 63
                 enableDHCP:
                   type: boolean
                 dhcpV4Options:
@@ -1567,6 +2053,57 @@ spec:
                   type: boolean
                 egressLastSyncSuccess:
                   type: boolean
+                ingressRules:
+                  type: array
+                  items:
+                    type: object
+                    properties:
+                      index:
+                        type: integer
+                      priority:
+                        type: integer
+                      synced:
+                        type: boolean
+                      message:
+                        type: string
+                egressRules:
+                  type: array
+                  items:
+                    type: object
+                    properties:
+                      index:
+                        type: integer
+                      priority:
+                        type: integer
+                      synced:
+                        type: boolean
+                      message:
+                        type: string
+                ports:
+                  type: array
+                  items:
+                    type: string
+                pods:
+                  type: array
+                  items:
+                    type: string
+                conditions:
+                  type: array
+                  items:
+                    type: object
+                    properties:
+                      type:
+                        type: string
+                      status:
+                        type: string
+                      reason:
+                        type: string
+                      message:
+                        type: string
+                      lastUpdateTime:
+                        type: string
+                      lastTransitionTime:
+                        type: string
       subresources:
         status: {}
   conversion:
@@ -1602,6 +2139,172 @@ spec:
     kind: HtbQos
     shortNames:
       - htbqos
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: qos-policies.kubeovn.io
+spec:
+  group: kubeovn.io
+  names:
+    plural: qos-policies
+    singular: qos-policy
+    shortNames:
+      - qos
+    kind: QoSPolicy
+    listKind: QoSPolicyList
+  scope: Cluster
+  versions:
+    - name: v1
+      served: true
+      storage: true
+      subresources:
+        status: {}
+      additionalPrinterColumns:
+      - jsonPath: .spec.binding.type
+        name: BINDING
+        type: string
+      - jsonPath: .spec.ingressRate
+        name: INGRESS
+        type: string
+      - jsonPath: .spec.egressRate
+        name: EGRESS
+        type: string
+      - jsonPath: .spec.dscp
+        name: DSCP
+        type: integer
+      - jsonPath: .status.error
+        name: ERROR
+        type: string
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            status:
+              type: object
+              properties:
+                ports:
+                  type: array
+                  items:
+                    type: string
+                eips:
+                  type: array
+                  items:
+                    type: string
+                error:
+                  type: string
+            spec:
+              type: object
+              required:
+                - binding
+              properties:
+                binding:
+                  type: object
+                  required:
+                    - type
+                  properties:
+                    type:
+                      type: string
+                      enum:
+                        - Subnet
+                        - Namespace
+                        - Pod
+                        - EIP
+                        - Node
+                    names:
+                      type: array
+                      items:
+                        type: string
+                    namespace:
+                      type: string
+                    podSelector:
+                      type: object
+                      properties:
+                        matchLabels:
+                          type: object
+                          additionalProperties:
+                            type: string
+                        matchExpressions:
+                          type: array
+                          items:
+                            type: object
+                            properties:
+                              key:
+                                type: string
+                              operator:
+                                type: string
+                              values:
+                                type: array
+                                items:
+                                  type: string
+                ingressRate:
+                  type: string
+                egressRate:
+                  type: string
+                burst:
+                  type: string
+                priority:
+                  type: string
+                dscp:
+                  type: integer
+                  minimum: 0
+                  maximum: 63
+                destinationCIDRs:
+                  type: array
+                  items:
+                    type: string
+                destinationSubnets:
+                  type: array
+                  items:
+                    type: string
+                latency:
+                  type: string
+                limit:
+                  type: string
+                loss:
+                  type: string
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: fqdn-addresses.kubeovn.io
+spec:
+  group: kubeovn.io
+  names:
+    plural: fqdn-addresses
+    singular: fqdn-address
+    shortNames:
+      - fqdn
+    kind: FqdnAddress
+    listKind: FqdnAddressList
+  scope: Cluster
+  versions:
+    - name: v1
+      served: true
+      storage: true
+      subresources:
+        status: {}
+      additionalPrinterColumns:
+      - jsonPath: .status.addresses[*].ip
+        name: ADDRESSES
+        type: string
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            status:
+              type: object
+              properties:
+                addresses:
+                  type: array
+                  items:
+                    type: object
+                    properties:
+                      ip:
+                        type: string
+                      expireTime:
+                        type: string
+                        format: date-time
 EOF
 
 if $DPDK; then
@@ -1627,9 +2330,12 @@ rules:
       - vpcs
       - vpcs/status
       - vpc-nat-gateways
+      - vpc-nat-gateways/status
       - subnets
       - subnets/status
       - ips
+      - ippools
+      - ippools/status
       - vips
       - vips/status
       - vlans
@@ -1639,6 +2345,10 @@ rules:
       - security-groups
       - security-groups/status
       - htbqoses
+      - qos-policies
+      - qos-policies/status
+      - fqdn-addresses
+      - fqdn-addresses/status
       - iptables-eips
       - iptables-fip-rules
       - iptables-dnat-rules
@@ -1647,6 +2357,14 @@ rules:
       - iptables-fip-rules/status
       - iptables-dnat-rules/status
       - iptables-snat-rules/status
+      - ovn-eips
+      - ovn-fips
+      - ovn-snat-rules
+      - ovn-dnat-rules
+      - ovn-eips/status
+      - ovn-fips/status
+      - ovn-snat-rules/status
+      - ovn-dnat-rules/status
       - switch-lb-rules
       - switch-lb-rules/status
       - vpc-dnses
@@ -1678,6 +2396,23 @@ rules:
       - get
       - list
       - update
+  - apiGroups:
+      - policy.networking.k8s.io
+    resources:
+      - adminnetworkpolicies
+      - baselineadminnetworkpolicies
+    verbs:
+      - get
+      - list
+      - watch
+  - apiGroups:
+      - discovery.k8s.io
+    resources:
+      - endpointslices
+    verbs:
+      - get
+      - list
+      - watch
   - apiGroups:
       - ""
       - networking.k8s.io
@@ -2128,9 +2863,12 @@ rules:
       - vpcs
       - vpcs/status
       - vpc-nat-gateways
+      - vpc-nat-gateways/status
       - subnets
       - subnets/status
       - ips
+      - ippools
+      - ippools/status
       - vips
       - vips/status
       - vlans
@@ -2140,6 +2878,10 @@ rules:
       - security-groups
       - security-groups/status
       - htbqoses
+      - qos-policies
+      - qos-policies/status
+      - fqdn-addresses
+      - fqdn-addresses/status
       - iptables-eips
       - iptables-fip-rules
       - iptables-dnat-rules
@@ -2148,6 +2890,14 @@ rules:
       - iptables-fip-rules/status
       - iptables-dnat-rules/status
       - iptables-snat-rules/status
+      - ovn-eips
+      - ovn-fips
+      - ovn-snat-rules
+      - ovn-dnat-rules
+      - ovn-eips/status
+      - ovn-fips/status
+      - ovn-snat-rules/status
+      - ovn-dnat-rules/status
       - vpc-dnses
       - vpc-dnses/status
       - switch-lb-rules
@@ -2169,6 +2919,23 @@ rules:
       - watch
       - patch
       - update
+  - apiGroups:
+      - policy.networking.k8s.io
+    resources:
+      - adminnetworkpolicies
+      - baselineadminnetworkpolicies
+    verbs:
+      - get
+      - list
+      - watch
+  - apiGroups:
+      - discovery.k8s.io
+    resources:
+      - endpointslices
+    verbs:
+      - get
+      - list
+      - watch
   - apiGroups:
       - ""
       - networking.k8s.io
@@ -2829,6 +3596,7 @@ spec:
           - --pod-nic-type=$POD_NIC_TYPE
           - --enable-lb=$ENABLE_LB
           - --enable-np=$ENABLE_NP
+          - --enable-anp=$ENABLE_ANP
           - --enable-eip-snat=$ENABLE_EIP_SNAT
           - --enable-external-vpc=$ENABLE_EXTERNAL_VPC
           - --logtostderr=false
@@ -2838,8 +3606,13 @@ spec:
           - --log_file=/var/log/kube-ovn/kube-ovn-controller.log
           - --log_file_max_size=0
           - --enable-lb-svc=$ENABLE_LB_SVC
+          - --lb-ipam-subnet=$LB_IPAM_SUBNET
+          - --lb-ipam-ippool=$LB_IPAM_IPPOOL
           - --keep-vm-ip=$ENABLE_KEEP_VM_IP
           - --pod-default-fip-type=$POD_DEFAULT_FIP_TYPE
+          - --enable-ipam-checkpoint=$ENABLE_IPAM_CHECKPOINT
+          - --enable-ovn-nb-batch=$ENABLE_OVN_NB_BATCH
+          - --qos-mode=$QOS_MODE
           env:
             - name: ENABLE_SSL
               value: "$ENABLE_SSL"
@@ -2952,6 +3725,9 @@ spec:
           - /kube-ovn/start-cniserver.sh
         args:
           - --enable-mirror=$ENABLE_MIRROR
+          - --qos-mode=$QOS_MODE
+          - --enable-fqdn-snoop=$ENABLE_FQDN_SNOOP
+          - --enable-acl-log-collector=$ENABLE_ACL_LOG_COLLECTOR
           - --encap-checksum=true
           - --service-cluster-ip-range=$SVC_CIDR
           - --iface=${IFACE}
@@ -3009,6 +3785,8 @@ spec:
             name: localtime
           - mountPath: /tmp
             name: tmp
+          - mountPath: /var/run/tls
+            name: kube-ovn-tls
         livenessProbe:
           failureThreshold: 3
           initialDelaySeconds: 30
@@ -3077,6 +3855,10 @@ spec:
         - name: local-bin
           hostPath:
             path: /usr/local/bin
+        - name: kube-ovn-tls
+          secret:
+            optional: true
+            secretName: kube-ovn-tls
 
 ---
 kind: DaemonSet
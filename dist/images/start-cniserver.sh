@@ -55,4 +55,14 @@ set_sysctl net.ipv4.neigh.default.gc_thresh2 $gc_thresh2
 set_sysctl net.ipv4.neigh.default.gc_thresh3 $gc_thresh3
 set_sysctl net.netfilter.nf_conntrack_tcp_be_liberal $SYSCTL_NF_CONNTRACK_TCP_BE_LIBERAL
 
-./kube-ovn-daemon --ovs-socket=${OVS_SOCK} --bind-socket=${CNI_SOCK} "$@"
+# the nb address is used to verify the logical switch ports of pods in CNI CHECK
+OVN_NB_ADDR=""
+if [[ -n "${OVN_NB_SERVICE_HOST:-}" ]]; then
+  if [[ "$ENABLE_SSL" == "false" ]]; then
+    OVN_NB_ADDR="tcp:[${OVN_NB_SERVICE_HOST}]:${OVN_NB_SERVICE_PORT}"
+  else
+    OVN_NB_ADDR="ssl:[${OVN_NB_SERVICE_HOST}]:${OVN_NB_SERVICE_PORT}"
+  fi
+fi
+
+./kube-ovn-daemon --ovs-socket=${OVS_SOCK} --bind-socket=${CNI_SOCK} --ovn-nb-addr="${OVN_NB_ADDR}" "$@"
@@ -1,5 +1,8 @@
 #!/usr/bin/env bash
 
+# pods of a gateway in ha mode start as standby until the controller makes one of them active
+HA_ACTIVE_FLAG=/tmp/vpc-nat-gw-active
+
 function exec_cmd() {
     cmd=${@:1:${#}}
     $cmd
@@ -10,6 +13,10 @@ function exec_cmd() {
     fi
 }
 
+function is_standby() {
+    [ "$VPC_NAT_GW_HA" = "true" ] && [ ! -f $HA_ACTIVE_FLAG ]
+}
+
 function init() {
     # run once is enough
     iptables-save | grep DNAT_FILTER && exit 0
@@ -114,14 +121,31 @@ function add_eip() {
         gateway=${arr[1]}
 
         exec_cmd "ip addr replace $eip dev net1"
-        ip link set dev net1 arp on
         # gw may lost, even if add_vpc_external_route add route successfully
         exec_cmd "ip route replace default via $gateway dev net1"
         ip route | grep "default via $gateway dev net1"
+        # the standby pod holds the same eips without answering arp for them
+        is_standby && continue
+        ip link set dev net1 arp on
         exec_cmd "arping -I net1 -c 3 -D $eip_without_prefix"
     done
 }
 
+function ha_active() {
+    touch $HA_ACTIVE_FLAG
+    ip link set dev net1 arp on
+    # announce the eips taken over from the previous active pod
+    for eip in $(ip -4 -o addr show dev net1 | awk '{print $4}')
+    do
+        arping -I net1 -c 3 -U ${eip%/*}
+    done
+}
+
+function ha_standby() {
+    rm -f $HA_ACTIVE_FLAG
+    exec_cmd "ip link set dev net1 arp off"
+}
+
 function del_eip() {
     # make sure inited
     iptables-save -t nat | grep  SNAT_FILTER | grep SHARED_SNAT
@@ -136,6 +160,45 @@ function del_eip() {
     done
 }
 
+function add_eip_qos() {
+    # the clsact qdisc provides both the ingress and egress hooks of net1
+    tc qdisc show dev net1 | grep -q clsact || exec_cmd "tc qdisc add dev net1 clsact"
+    for rule in $@
+    do
+        arr=(${rule//,/ })
+        direction=${arr[0]}
+        priority=${arr[1]}
+        handle=${arr[2]}
+        eip=${arr[3]}
+        rate=${arr[4]}
+        protocol=${arr[5]}
+        port=${arr[6]}
+        # traffic to the eip is matched before dnat, traffic from the eip is matched after snat
+        if [ "$direction" = "ingress" ]; then
+            match="dst_ip $eip"
+            [ -n "$port" ] && match="ip_proto $protocol $match dst_port $port"
+        else
+            match="src_ip $eip"
+            [ -n "$port" ] && match="ip_proto $protocol $match src_port $port"
+        fi
+        # burst of 100ms at the rate
+        burst=$((rate * 12500))
+        exec_cmd "tc filter replace dev net1 $direction protocol ip prio $priority handle $handle flower $match action police rate ${rate}mbit burst $burst conform-exceed drop/ok"
+    done
+}
+
+function del_eip_qos() {
+    for rule in $@
+    do
+        arr=(${rule//,/ })
+        direction=${arr[0]}
+        priority=${arr[1]}
+        handle=${arr[2]}
+        tc filter show dev net1 $direction prio $priority 2>/dev/null | grep -qw "handle $(printf '0x%x' $handle)" || continue
+        exec_cmd "tc filter del dev net1 $direction protocol ip prio $priority handle $handle flower"
+    done
+}
+
 function add_floating_ip() {
     # make sure inited
     iptables-save -t nat | grep  SNAT_FILTER | grep SHARED_SNAT
@@ -287,6 +350,14 @@ case $opt in
         echo "snat-del $rules"
         del_snat $rules
         ;;
+ eip-qos-add)
+        echo "eip-qos-add $rules"
+        add_eip_qos $rules
+        ;;
+ eip-qos-del)
+        echo "eip-qos-del $rules"
+        del_eip_qos $rules
+        ;;
  floating-ip-add)
         echo "floating-ip-add $rules"
         add_floating_ip $rules
@@ -295,12 +366,20 @@ case $opt in
         echo "floating-ip-del $rules"
         del_floating_ip $rules
         ;;
+ ha-active)
+        echo "ha-active $rules"
+        ha_active $rules
+        ;;
+ ha-standby)
+        echo "ha-standby $rules"
+        ha_standby $rules
+        ;;
  get-iptables-version)
         echo "get-iptables-version $rules"
         get_iptables_version $rules
         ;;
  *)
-        echo "Usage: $0 [init|subnet-route-add|subnet-route-del|eip-add|eip-del|floating-ip-add|floating-ip-del|dnat-add|dnat-del|snat-add|snat-del] ..."
+        echo "Usage: $0 [init|subnet-route-add|subnet-route-del|eip-add|eip-del|eip-qos-add|eip-qos-del|floating-ip-add|floating-ip-del|dnat-add|dnat-del|snat-add|snat-del|ha-active|ha-standby] ..."
         exit 1
         ;;
 esac
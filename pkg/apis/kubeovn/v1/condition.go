@@ -352,3 +352,152 @@ func (s *ProviderNetworkStatus) RemoveNodeConditions(node string) bool {
 	}
 	return changed
 }
+
+func (s *IPPoolStatus) addCondition(ctype ConditionType, status corev1.ConditionStatus, reason, message string) {
+	now := metav1.Now()
+	c := &IPPoolCondition{
+		Type:               ctype,
+		LastUpdateTime:     now,
+		LastTransitionTime: now,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+	}
+	s.Conditions = append(s.Conditions, *c)
+}
+
+// setConditionValue updates or creates a new condition
+func (s *IPPoolStatus) setConditionValue(ctype ConditionType, status corev1.ConditionStatus, reason, message string) {
+	var c *IPPoolCondition
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == ctype {
+			c = &s.Conditions[i]
+		}
+	}
+	if c == nil {
+		s.addCondition(ctype, status, reason, message)
+	} else {
+		// check message ?
+		if c.Status == status && c.Reason == reason && c.Message == message {
+			return
+		}
+		now := metav1.Now()
+		c.LastUpdateTime = now
+		if c.Status != status {
+			c.LastTransitionTime = now
+		}
+		c.Status = status
+		c.Reason = reason
+		c.Message = message
+	}
+}
+
+// SetCondition updates or creates a new condition
+func (s *IPPoolStatus) SetCondition(ctype ConditionType, reason, message string) {
+	s.setConditionValue(ctype, corev1.ConditionTrue, reason, message)
+}
+
+// ClearCondition updates or creates a new condition
+func (s *IPPoolStatus) ClearCondition(ctype ConditionType, reason, message string) {
+	s.setConditionValue(ctype, corev1.ConditionFalse, reason, message)
+}
+
+// Ready - shortcut to set ready condition to true
+func (s *IPPoolStatus) Ready(reason, message string) {
+	s.SetCondition(Ready, reason, message)
+}
+
+// NotReady - shortcut to set ready condition to false
+func (s *IPPoolStatus) NotReady(reason, message string) {
+	s.ClearCondition(Ready, reason, message)
+}
+
+func (s *SecurityGroupStatus) addCondition(ctype ConditionType, status corev1.ConditionStatus, reason, message string) {
+	now := metav1.Now()
+	c := &SecurityGroupCondition{
+		Type:               ctype,
+		LastUpdateTime:     now,
+		LastTransitionTime: now,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+	}
+	s.Conditions = append(s.Conditions, *c)
+}
+
+// setConditionValue updates or creates a new condition
+func (s *SecurityGroupStatus) setConditionValue(ctype ConditionType, status corev1.ConditionStatus, reason, message string) {
+	var c *SecurityGroupCondition
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == ctype {
+			c = &s.Conditions[i]
+		}
+	}
+	if c == nil {
+		s.addCondition(ctype, status, reason, message)
+	} else {
+		// check message ?
+		if c.Status == status && c.Reason == reason && c.Message == message {
+			return
+		}
+		now := metav1.Now()
+		c.LastUpdateTime = now
+		if c.Status != status {
+			c.LastTransitionTime = now
+		}
+		c.Status = status
+		c.Reason = reason
+		c.Message = message
+	}
+}
+
+// GetCondition get existing condition
+func (s *SecurityGroupStatus) GetCondition(ctype ConditionType) *SecurityGroupCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == ctype {
+			return &s.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// IsConditionTrue - if condition is true
+func (s *SecurityGroupStatus) IsConditionTrue(ctype ConditionType) bool {
+	if c := s.GetCondition(ctype); c != nil {
+		return c.Status == corev1.ConditionTrue
+	}
+	return false
+}
+
+// IsReady returns true if ready condition is set
+func (s *SecurityGroupStatus) IsReady() bool { return s.IsConditionTrue(Ready) }
+
+// SetCondition updates or creates a new condition
+func (s *SecurityGroupStatus) SetCondition(ctype ConditionType, reason, message string) {
+	s.setConditionValue(ctype, corev1.ConditionTrue, reason, message)
+}
+
+// ClearCondition updates or creates a new condition
+func (s *SecurityGroupStatus) ClearCondition(ctype ConditionType, reason, message string) {
+	s.setConditionValue(ctype, corev1.ConditionFalse, reason, message)
+}
+
+// Ready - shortcut to set ready condition to true
+func (s *SecurityGroupStatus) Ready(reason, message string) {
+	s.SetCondition(Ready, reason, message)
+}
+
+// NotReady - shortcut to set ready condition to false
+func (s *SecurityGroupStatus) NotReady(reason, message string) {
+	s.ClearCondition(Ready, reason, message)
+}
+
+// Validated - shortcut to set validated condition to true
+func (s *SecurityGroupStatus) Validated(reason, message string) {
+	s.SetCondition(Validated, reason, message)
+}
+
+// NotValidated - shortcut to set validated condition to false
+func (s *SecurityGroupStatus) NotValidated(reason, message string) {
+	s.ClearCondition(Validated, reason, message)
+}
@@ -33,6 +33,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&IP{},
 		&IPList{},
+		&IPPool{},
+		&IPPoolList{},
 		&Subnet{},
 		&SubnetList{},
 		&Vlan{},
@@ -53,10 +55,22 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&IptablesDnatRuleList{},
 		&IptablesSnatRule{},
 		&IptablesSnatRuleList{},
+		&OvnEip{},
+		&OvnEipList{},
+		&OvnFip{},
+		&OvnFipList{},
+		&OvnSnatRule{},
+		&OvnSnatRuleList{},
+		&OvnDnatRule{},
+		&OvnDnatRuleList{},
 		&SecurityGroup{},
 		&SecurityGroupList{},
 		&HtbQos{},
 		&HtbQosList{},
+		&QoSPolicy{},
+		&QoSPolicyList{},
+		&FqdnAddress{},
+		&FqdnAddressList{},
 		&SwitchLBRule{},
 		&SwitchLBRuleList{},
 		&VpcDns{},
@@ -87,3 +87,63 @@ func (isnats *IptablesSnatRuleStatus) Bytes() ([]byte, error) {
 	klog.V(5).Info("status body", newStr)
 	return []byte(newStr), nil
 }
+
+func (oeips *OvnEipStatus) Bytes() ([]byte, error) {
+	bytes, err := json.Marshal(oeips)
+	if err != nil {
+		return nil, err
+	}
+	newStr := fmt.Sprintf(`{"status": %s}`, string(bytes))
+	klog.V(5).Info("status body", newStr)
+	return []byte(newStr), nil
+}
+
+func (ofips *OvnFipStatus) Bytes() ([]byte, error) {
+	bytes, err := json.Marshal(ofips)
+	if err != nil {
+		return nil, err
+	}
+	newStr := fmt.Sprintf(`{"status": %s}`, string(bytes))
+	klog.V(5).Info("status body", newStr)
+	return []byte(newStr), nil
+}
+
+func (osnats *OvnSnatRuleStatus) Bytes() ([]byte, error) {
+	bytes, err := json.Marshal(osnats)
+	if err != nil {
+		return nil, err
+	}
+	newStr := fmt.Sprintf(`{"status": %s}`, string(bytes))
+	klog.V(5).Info("status body", newStr)
+	return []byte(newStr), nil
+}
+
+func (odnats *OvnDnatRuleStatus) Bytes() ([]byte, error) {
+	bytes, err := json.Marshal(odnats)
+	if err != nil {
+		return nil, err
+	}
+	newStr := fmt.Sprintf(`{"status": %s}`, string(bytes))
+	klog.V(5).Info("status body", newStr)
+	return []byte(newStr), nil
+}
+
+func (vngs *VpcNatGatewayStatus) Bytes() ([]byte, error) {
+	bytes, err := json.Marshal(vngs)
+	if err != nil {
+		return nil, err
+	}
+	newStr := fmt.Sprintf(`{"status": %s}`, string(bytes))
+	klog.V(5).Info("status body", newStr)
+	return []byte(newStr), nil
+}
+
+func (qps *QoSPolicyStatus) Bytes() ([]byte, error) {
+	bytes, err := json.Marshal(qps)
+	if err != nil {
+		return nil, err
+	}
+	newStr := fmt.Sprintf(`{"status": %s}`, string(bytes))
+	klog.V(5).Info("status body", newStr)
+	return []byte(newStr), nil
+}
@@ -21,6 +21,8 @@ type SgRemoteType string
 const (
 	SgRemoteTypeAddress SgRemoteType = "address"
 	SgRemoteTypeSg      SgRemoteType = "securityGroup"
+	// the remote address is a domain name resolved to the addresses in the FqdnAddress of the name
+	SgRemoteTypeFQDN SgRemoteType = "fqdn"
 )
 
 type SgProtocol string
@@ -110,6 +112,10 @@ type SubnetSpec struct {
 	ExcludeIps []string `json:"excludeIps,omitempty"`
 	Provider   string   `json:"provider,omitempty"`
 
+	// SecondaryCIDRBlocks are additional ranges sharing the logical switch of the subnet, addresses are
+	// allocated from them in order once CIDRBlock is exhausted, the first address of each range is its gateway
+	SecondaryCIDRBlocks []string `json:"secondaryCIDRBlocks,omitempty"`
+
 	GatewayType string `json:"gatewayType,omitempty"`
 	GatewayNode string `json:"gatewayNode"`
 	NatOutgoing bool   `json:"natOutgoing"`
@@ -124,6 +130,9 @@ type SubnetSpec struct {
 
 	Vlan   string `json:"vlan,omitempty"`
 	HtbQos string `json:"htbqos,omitempty"`
+	// Dscp is marked on the egress traffic of the ports in the subnet,
+	// the pod annotation and qos policies take precedence over it
+	Dscp *int `json:"dscp,omitempty"`
 
 	Vips []string `json:"vips,omitempty"`
 
@@ -188,6 +197,17 @@ type SubnetStatus struct {
 	ActivateGateway   string  `json:"activateGateway"`
 	DHCPv4OptionsUUID string  `json:"dhcpV4OptionsUUID"`
 	DHCPv6OptionsUUID string  `json:"dhcpV6OptionsUUID"`
+
+	// CIDRUsage reports the address usage of CIDRBlock and each of SecondaryCIDRBlocks
+	CIDRUsage []SubnetCIDRUsage `json:"cidrUsage,omitempty"`
+	// Dscp is the value of the subnet marked by the ovn qos rules
+	Dscp *int `json:"dscp,omitempty"`
+}
+
+type SubnetCIDRUsage struct {
+	CIDR         string  `json:"cidr"`
+	AvailableIPs float64 `json:"availableIPs"`
+	UsingIPs     float64 `json:"usingIPs"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -348,6 +368,9 @@ type VpcSpec struct {
 	StaticRoutes []*StaticRoute `json:"staticRoutes,omitempty"`
 	PolicyRoutes []*PolicyRoute `json:"policyRoutes,omitempty"`
 	VpcPeerings  []*VpcPeering  `json:"vpcPeerings,omitempty"`
+	// EnableExternal connects the vpc to the external subnet with a distributed gateway port,
+	// which is required by the ovn eip, fip, snat and dnat rules of the vpc
+	EnableExternal bool `json:"enableExternal,omitempty"`
 }
 
 type VpcPeering struct {
@@ -443,7 +466,8 @@ type VpcNatGateway struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec VpcNatSpec `json:"spec"`
+	Spec   VpcNatSpec          `json:"spec"`
+	Status VpcNatGatewayStatus `json:"status,omitempty"`
 }
 
 type VpcNatSpec struct {
@@ -452,6 +476,17 @@ type VpcNatSpec struct {
 	LanIp       string             `json:"lanIp"`
 	Selector    []string           `json:"selector"`
 	Tolerations []VpcNatToleration `json:"tolerations"`
+
+	// HA runs an active and a standby gateway pod, the standby one uses StandbyLanIp
+	HA           bool   `json:"ha,omitempty"`
+	StandbyLanIp string `json:"standbyLanIp,omitempty"`
+}
+
+type VpcNatGatewayStatus struct {
+	// +optional
+	// +patchStrategy=merge
+	ActivePod   string `json:"activePod" patchStrategy:"merge"`
+	ActiveLanIp string `json:"activeLanIp" patchStrategy:"merge"`
 }
 
 type VpcNatToleration struct {
@@ -479,6 +514,9 @@ type IptablesEipSpec struct {
 	V6ip       string `json:"v6ip"`
 	MacAddress string `json:"macAddress"`
 	NatGwDp    string `json:"natGwDp"`
+	// rate limits in Mbit/s of the traffic to and from the eip, applied by tc in the nat gateway pod
+	IngressRate string `json:"ingressRate,omitempty"`
+	EgressRate  string `json:"egressRate,omitempty"`
 }
 
 // Condition describes the state of an object at a certain point.
@@ -509,6 +547,9 @@ type IptablesEipStatus struct {
 	IP    string `json:"ip" patchStrategy:"merge"`
 	Redo  string `json:"redo" patchStrategy:"merge"`
 	Nat   string `json:"nat" patchStrategy:"merge"`
+	// rate limits applied in the nat gateway pod
+	IngressRate string `json:"ingressRate,omitempty" patchStrategy:"merge"`
+	EgressRate  string `json:"egressRate,omitempty" patchStrategy:"merge"`
 
 	// Conditions represents the latest state of the object
 	// +optional
@@ -541,6 +582,9 @@ type IptablesFIPRule struct {
 type IptablesFIPRuleSpec struct {
 	EIP        string `json:"eip"`
 	InternalIp string `json:"internalIp"`
+	// rate limits in Mbit/s of the fip, take precedence over the ones of the eip
+	IngressRate string `json:"ingressRate,omitempty"`
+	EgressRate  string `json:"egressRate,omitempty"`
 }
 
 // Condition describes the state of an object at a certain point.
@@ -572,6 +616,9 @@ type IptablesFIPRuleStatus struct {
 	V6ip    string `json:"v6ip" patchStrategy:"merge"`
 	NatGwDp string `json:"natGwDp" patchStrategy:"merge"`
 	Redo    string `json:"redo" patchStrategy:"merge"`
+	// rate limits applied in the nat gateway pod
+	IngressRate string `json:"ingressRate,omitempty" patchStrategy:"merge"`
+	EgressRate  string `json:"egressRate,omitempty" patchStrategy:"merge"`
 
 	// Conditions represents the latest state of the object
 	// +optional
@@ -670,6 +717,9 @@ type IptablesDnatRuleSpec struct {
 	Protocol     string `json:"protocol,omitempty"`
 	InternalIp   string `json:"internalIp"`
 	InternalPort string `json:"internalPort"`
+	// rate limits in Mbit/s of the traffic via the external port, take precedence over the ones of the eip
+	IngressRate string `json:"ingressRate,omitempty"`
+	EgressRate  string `json:"egressRate,omitempty"`
 }
 
 // Condition describes the state of an object at a certain point.
@@ -701,6 +751,9 @@ type IptablesDnatRuleStatus struct {
 	V6ip    string `json:"v6ip" patchStrategy:"merge"`
 	NatGwDp string `json:"natGwDp" patchStrategy:"merge"`
 	Redo    string `json:"redo" patchStrategy:"merge"`
+	// rate limits applied in the nat gateway pod
+	IngressRate string `json:"ingressRate,omitempty" patchStrategy:"merge"`
+	EgressRate  string `json:"egressRate,omitempty" patchStrategy:"merge"`
 
 	// Conditions represents the latest state of the object
 	// +optional
@@ -718,6 +771,156 @@ type IptablesDnatRuleList struct {
 	Items []IptablesDnatRule `json:"items"`
 }
 
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+// +resourceName=ovn-eips
+
+type OvnEip struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OvnEipSpec   `json:"spec"`
+	Status OvnEipStatus `json:"status,omitempty"`
+}
+
+type OvnEipSpec struct {
+	// V4ip and MacAddress are allocated from the external subnet if they are not specified
+	V4ip       string `json:"v4ip"`
+	MacAddress string `json:"macAddress"`
+	// Type is lrp for the address of the vpc gateway port, or nat for the addresses used by nat rules
+	Type string `json:"type,omitempty"`
+}
+
+type OvnEipStatus struct {
+	// +optional
+	// +patchStrategy=merge
+	Ready      bool   `json:"ready" patchStrategy:"merge"`
+	V4ip       string `json:"v4ip" patchStrategy:"merge"`
+	MacAddress string `json:"macAddress" patchStrategy:"merge"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type OvnEipList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []OvnEip `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+// +resourceName=ovn-fips
+
+type OvnFip struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OvnFipSpec   `json:"spec"`
+	Status OvnFipStatus `json:"status,omitempty"`
+}
+
+type OvnFipSpec struct {
+	OvnEip string `json:"ovnEip"`
+	// IpName is the name of the ip crd of the internal address
+	IpName string `json:"ipName"`
+}
+
+type OvnFipStatus struct {
+	// +optional
+	// +patchStrategy=merge
+	Ready bool   `json:"ready" patchStrategy:"merge"`
+	Vpc   string `json:"vpc" patchStrategy:"merge"`
+	V4Eip string `json:"v4Eip" patchStrategy:"merge"`
+	V4Ip  string `json:"v4Ip" patchStrategy:"merge"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type OvnFipList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []OvnFip `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+// +resourceName=ovn-snat-rules
+
+type OvnSnatRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OvnSnatRuleSpec   `json:"spec"`
+	Status OvnSnatRuleStatus `json:"status,omitempty"`
+}
+
+type OvnSnatRuleSpec struct {
+	OvnEip    string `json:"ovnEip"`
+	VpcSubnet string `json:"vpcSubnet"`
+}
+
+type OvnSnatRuleStatus struct {
+	// +optional
+	// +patchStrategy=merge
+	Ready    bool   `json:"ready" patchStrategy:"merge"`
+	Vpc      string `json:"vpc" patchStrategy:"merge"`
+	V4Eip    string `json:"v4Eip" patchStrategy:"merge"`
+	V4IpCidr string `json:"v4IpCidr" patchStrategy:"merge"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type OvnSnatRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []OvnSnatRule `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+// +resourceName=ovn-dnat-rules
+
+type OvnDnatRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OvnDnatRuleSpec   `json:"spec"`
+	Status OvnDnatRuleStatus `json:"status,omitempty"`
+}
+
+type OvnDnatRuleSpec struct {
+	OvnEip       string `json:"ovnEip"`
+	IpName       string `json:"ipName"`
+	Protocol     string `json:"protocol,omitempty"`
+	InternalPort string `json:"internalPort"`
+	ExternalPort string `json:"externalPort"`
+}
+
+type OvnDnatRuleStatus struct {
+	// +optional
+	// +patchStrategy=merge
+	Ready bool   `json:"ready" patchStrategy:"merge"`
+	Vpc   string `json:"vpc" patchStrategy:"merge"`
+	V4Eip string `json:"v4Eip" patchStrategy:"merge"`
+	V4Ip  string `json:"v4Ip" patchStrategy:"merge"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type OvnDnatRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []OvnDnatRule `json:"items"`
+}
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 type VpcNatGatewayList struct {
@@ -747,12 +950,56 @@ type SecurityGroupSpec struct {
 }
 
 type SecurityGroupStatus struct {
+	// Conditions represents the latest state of the object
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []SecurityGroupCondition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
 	PortGroup              string `json:"portGroup"`
 	AllowSameGroupTraffic  bool   `json:"allowSameGroupTraffic"`
 	IngressMd5             string `json:"ingressMd5"`
 	EgressMd5              string `json:"egressMd5"`
 	IngressLastSyncSuccess bool   `json:"ingressLastSyncSuccess"`
 	EgressLastSyncSuccess  bool   `json:"egressLastSyncSuccess"`
+
+	// IngressRules and EgressRules are the results of the rules in the last sync, in the order of the spec
+	IngressRules []SgRuleStatus `json:"ingressRules"`
+	EgressRules  []SgRuleStatus `json:"egressRules"`
+	// Ports are the logical switch ports bound to the security group, and Pods are the pods owning them
+	Ports []string `json:"ports,omitempty"`
+	Pods  []string `json:"pods,omitempty"`
+}
+
+// SgRuleStatus is the result of a rule of the security group
+type SgRuleStatus struct {
+	Index    int  `json:"index"`
+	Priority int  `json:"priority"`
+	Synced   bool `json:"synced"`
+	// Message is the reason why the rule is not synced
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// Condition describes the state of an object at a certain point.
+// +k8s:deepcopy-gen=true
+type SecurityGroupCondition struct {
+	// Type of condition.
+	Type ConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+	// The reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// A human readable message indicating details about the transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Last time the condition was probed
+	// +optional
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+	// Last time the condition transitioned from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 }
 
 type SgRule struct {
@@ -801,6 +1048,123 @@ type HtbQosList struct {
 	Items []HtbQos `json:"items"`
 }
 
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+// +resourceName=qos-policies
+
+// QoSPolicy binds bandwidth limits, dscp marking and netem settings to the ports of pods
+// and nodes, or bandwidth limits to iptables eips.
+// A port uses at most one policy, a policy bound to pods by selector takes precedence over
+// the ones bound to the namespace and then to the subnet of the port, policies of the same
+// binding type are ordered by name. Values in the annotations of pods and nodes take
+// precedence over the ones in policies, as well as the rates in the spec of eips.
+type QoSPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   QoSPolicySpec   `json:"spec"`
+	Status QoSPolicyStatus `json:"status,omitempty"`
+}
+
+type QoSPolicyBindingType string
+
+const (
+	QoSBindingTypeSubnet    QoSPolicyBindingType = "Subnet"
+	QoSBindingTypeNamespace QoSPolicyBindingType = "Namespace"
+	QoSBindingTypePod       QoSPolicyBindingType = "Pod"
+	QoSBindingTypeEIP       QoSPolicyBindingType = "EIP"
+	QoSBindingTypeNode      QoSPolicyBindingType = "Node"
+)
+
+type QoSPolicyBinding struct {
+	Type QoSPolicyBindingType `json:"type"`
+	// names of the subnets, namespaces, iptables eips or nodes
+	Names []string `json:"names,omitempty"`
+	// namespace and labels of the pods bound by the Pod type
+	Namespace   string                `json:"namespace,omitempty"`
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+}
+
+type QoSPolicySpec struct {
+	Binding QoSPolicyBinding `json:"binding"`
+
+	// bandwidth limits in Mbit/s from the point of view of the pod
+	IngressRate string `json:"ingressRate,omitempty"`
+	EgressRate  string `json:"egressRate,omitempty"`
+	// burst in Mbit of the egress rate limit
+	Burst string `json:"burst,omitempty"`
+	// priority of the htb queue
+	Priority string `json:"priority,omitempty"`
+	// dscp value marked on the egress traffic of the ports
+	Dscp *int `json:"dscp,omitempty"`
+	// rate limits only apply to the traffic between the ports and the destinations,
+	// which are enforced by ovn qos rules and require the ovn qos mode
+	DestinationCIDRs   []string `json:"destinationCIDRs,omitempty"`
+	DestinationSubnets []string `json:"destinationSubnets,omitempty"`
+
+	// netem settings, latency in ms, limit in packets and loss in percentage
+	Latency string `json:"latency,omitempty"`
+	Limit   string `json:"limit,omitempty"`
+	Loss    string `json:"loss,omitempty"`
+}
+
+type QoSPolicyStatus struct {
+	// +optional
+	// +patchStrategy=merge
+	// logical switch ports the policy is applied to, fields are not omitted to be cleared by merge patches
+	Ports []string `json:"ports" patchStrategy:"merge"`
+	// iptables eips the policy is applied to
+	Eips []string `json:"eips" patchStrategy:"merge"`
+	// the reason why the policy is not applied
+	Error string `json:"error" patchStrategy:"merge"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type QoSPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []QoSPolicy `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+// +resourceName=fqdn-addresses
+
+// FqdnAddress holds the addresses a domain name is resolved to, the object is named by the domain name.
+// It is created by kube-ovn-controller for the names referred by security groups and network policies,
+// and the addresses are snooped by kube-ovn-cni from the dns responses received on the nodes.
+type FqdnAddress struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status FqdnAddressStatus `json:"status,omitempty"`
+}
+
+type FqdnAddressStatus struct {
+	// +optional
+	Addresses []FqdnAddressRecord `json:"addresses,omitempty"`
+}
+
+// FqdnAddressRecord is an address of the domain name, which is removed after the ttl of the dns record
+// expires unless it is snooped again
+type FqdnAddressRecord struct {
+	IP         string      `json:"ip"`
+	ExpireTime metav1.Time `json:"expireTime"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type FqdnAddressList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []FqdnAddress `json:"items"`
+}
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +genclient:nonNamespaced
@@ -935,12 +1299,34 @@ type SlrPort struct {
 	Protocol   string `json:"protocol"`
 }
 
+// SlrHealthCheck configures the health check of the backends by ovn, zero values use the defaults of ovn
+type SlrHealthCheck struct {
+	// Interval is the seconds between two checks
+	Interval int32 `json:"interval,omitempty"`
+	// Timeout is the seconds to wait for the response of a check
+	Timeout int32 `json:"timeout,omitempty"`
+	// SuccessCount is the number of successful checks to mark the backend online
+	SuccessCount int32 `json:"successCount,omitempty"`
+	// FailureCount is the number of failed checks to mark the backend offline
+	FailureCount int32 `json:"failureCount,omitempty"`
+}
+
 type SwitchLBRuleSpec struct {
-	Vip             string    `json:"vip"`
-	Namespace       string    `json:"namespace"`
-	Selector        []string  `json:"selector"`
-	SessionAffinity string    `json:"sessionAffinity,omitempty"`
-	Ports           []SlrPort `json:"ports"`
+	Vip             string          `json:"vip"`
+	Namespace       string          `json:"namespace"`
+	Selector        []string        `json:"selector"`
+	SessionAffinity string          `json:"sessionAffinity,omitempty"`
+	Ports           []SlrPort       `json:"ports"`
+	HealthCheck     *SlrHealthCheck `json:"healthCheck,omitempty"`
+}
+
+// SlrBackendStatus is the health status of a backend reported by ovn
+type SlrBackendStatus struct {
+	IP       string `json:"ip"`
+	Port     int32  `json:"port"`
+	Protocol string `json:"protocol"`
+	// Status is online, offline or unknown if the backend has not been checked yet
+	Status string `json:"status"`
 }
 
 type SwitchLBRuleStatus struct {
@@ -952,6 +1338,9 @@ type SwitchLBRuleStatus struct {
 
 	Ports   string `json:"ports" patchStrategy:"merge"`
 	Service string `json:"service" patchStrategy:"merge"`
+	// Backends are the health status of the backends if the health check is enabled
+	// +optional
+	Backends []SlrBackendStatus `json:"backends,omitempty"`
 }
 
 // +genclient
@@ -994,3 +1383,72 @@ type SwitchLBRuleCondition struct {
 	// +optional
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 }
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+// +resourceName=ippools
+
+type IPPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IPPoolSpec   `json:"spec"`
+	Status IPPoolStatus `json:"status,omitempty"`
+}
+
+type IPPoolSpec struct {
+	Subnet string `json:"subnet"`
+	// IPs is a list of addresses, ranges(e.g. 10.16.0.10..10.16.0.20) or CIDRs
+	// carved out of the subnet, they can only be allocated to bound pods
+	IPs []string `json:"ips"`
+	// Namespaces bind the pool to all pods in these namespaces
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Selector binds the pool to pods matching the label selector
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+type IPPoolStatus struct {
+	// Conditions represents the latest state of the object
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []IPPoolCondition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	V4AvailableIPs float64 `json:"v4AvailableIPs"`
+	V4UsingIPs     float64 `json:"v4UsingIPs"`
+	V6AvailableIPs float64 `json:"v6AvailableIPs"`
+	V6UsingIPs     float64 `json:"v6UsingIPs"`
+}
+
+// Condition describes the state of an object at a certain point.
+// +k8s:deepcopy-gen=true
+type IPPoolCondition struct {
+	// Type of condition.
+	Type ConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+	// The reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// A human readable message indicating details about the transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Last time the condition was probed
+	// +optional
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+	// Last time the condition transitioned from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type IPPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []IPPool `json:"items"`
+}
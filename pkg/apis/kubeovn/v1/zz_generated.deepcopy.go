@@ -22,6 +22,7 @@ limitations under the License.
 package v1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -62,6 +63,106 @@ func (in *CustomInterface) DeepCopy() *CustomInterface {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FqdnAddress) DeepCopyInto(out *FqdnAddress) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FqdnAddress.
+func (in *FqdnAddress) DeepCopy() *FqdnAddress {
+	if in == nil {
+		return nil
+	}
+	out := new(FqdnAddress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FqdnAddress) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FqdnAddressList) DeepCopyInto(out *FqdnAddressList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FqdnAddress, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FqdnAddressList.
+func (in *FqdnAddressList) DeepCopy() *FqdnAddressList {
+	if in == nil {
+		return nil
+	}
+	out := new(FqdnAddressList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FqdnAddressList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FqdnAddressRecord) DeepCopyInto(out *FqdnAddressRecord) {
+	*out = *in
+	in.ExpireTime.DeepCopyInto(&out.ExpireTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FqdnAddressRecord.
+func (in *FqdnAddressRecord) DeepCopy() *FqdnAddressRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(FqdnAddressRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FqdnAddressStatus) DeepCopyInto(out *FqdnAddressStatus) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]FqdnAddressRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FqdnAddressStatus.
+func (in *FqdnAddressStatus) DeepCopy() *FqdnAddressStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FqdnAddressStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HtbQos) DeepCopyInto(out *HtbQos) {
 	*out = *in
@@ -198,6 +299,139 @@ func (in *IPList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPPool) DeepCopyInto(out *IPPool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPool.
+func (in *IPPool) DeepCopy() *IPPool {
+	if in == nil {
+		return nil
+	}
+	out := new(IPPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IPPool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPPoolCondition) DeepCopyInto(out *IPPoolCondition) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPoolCondition.
+func (in *IPPoolCondition) DeepCopy() *IPPoolCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(IPPoolCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPPoolList) DeepCopyInto(out *IPPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IPPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPoolList.
+func (in *IPPoolList) DeepCopy() *IPPoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(IPPoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IPPoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPPoolSpec) DeepCopyInto(out *IPPoolSpec) {
+	*out = *in
+	if in.IPs != nil {
+		in, out := &in.IPs, &out.IPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPoolSpec.
+func (in *IPPoolSpec) DeepCopy() *IPPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IPPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPPoolStatus) DeepCopyInto(out *IPPoolStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]IPPoolCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPoolStatus.
+func (in *IPPoolStatus) DeepCopy() *IPPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IPPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IPSpec) DeepCopyInto(out *IPSpec) {
 	*out = *in
@@ -608,35 +842,414 @@ func (in *IptablesSnatRule) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
-	return nil
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IptablesSnatRuleCondition) DeepCopyInto(out *IptablesSnatRuleCondition) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IptablesSnatRuleCondition.
+func (in *IptablesSnatRuleCondition) DeepCopy() *IptablesSnatRuleCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(IptablesSnatRuleCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IptablesSnatRuleList) DeepCopyInto(out *IptablesSnatRuleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IptablesSnatRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IptablesSnatRuleList.
+func (in *IptablesSnatRuleList) DeepCopy() *IptablesSnatRuleList {
+	if in == nil {
+		return nil
+	}
+	out := new(IptablesSnatRuleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IptablesSnatRuleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IptablesSnatRuleSpec) DeepCopyInto(out *IptablesSnatRuleSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IptablesSnatRuleSpec.
+func (in *IptablesSnatRuleSpec) DeepCopy() *IptablesSnatRuleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IptablesSnatRuleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IptablesSnatRuleStatus) DeepCopyInto(out *IptablesSnatRuleStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]IptablesSnatRuleCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IptablesSnatRuleStatus.
+func (in *IptablesSnatRuleStatus) DeepCopy() *IptablesSnatRuleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IptablesSnatRuleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OvnDnatRule) DeepCopyInto(out *OvnDnatRule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnDnatRule.
+func (in *OvnDnatRule) DeepCopy() *OvnDnatRule {
+	if in == nil {
+		return nil
+	}
+	out := new(OvnDnatRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OvnDnatRule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OvnDnatRuleList) DeepCopyInto(out *OvnDnatRuleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OvnDnatRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnDnatRuleList.
+func (in *OvnDnatRuleList) DeepCopy() *OvnDnatRuleList {
+	if in == nil {
+		return nil
+	}
+	out := new(OvnDnatRuleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OvnDnatRuleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OvnDnatRuleSpec) DeepCopyInto(out *OvnDnatRuleSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnDnatRuleSpec.
+func (in *OvnDnatRuleSpec) DeepCopy() *OvnDnatRuleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OvnDnatRuleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OvnDnatRuleStatus) DeepCopyInto(out *OvnDnatRuleStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnDnatRuleStatus.
+func (in *OvnDnatRuleStatus) DeepCopy() *OvnDnatRuleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OvnDnatRuleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OvnEip) DeepCopyInto(out *OvnEip) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnEip.
+func (in *OvnEip) DeepCopy() *OvnEip {
+	if in == nil {
+		return nil
+	}
+	out := new(OvnEip)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OvnEip) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OvnEipList) DeepCopyInto(out *OvnEipList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OvnEip, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnEipList.
+func (in *OvnEipList) DeepCopy() *OvnEipList {
+	if in == nil {
+		return nil
+	}
+	out := new(OvnEipList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OvnEipList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OvnEipSpec) DeepCopyInto(out *OvnEipSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnEipSpec.
+func (in *OvnEipSpec) DeepCopy() *OvnEipSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OvnEipSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OvnEipStatus) DeepCopyInto(out *OvnEipStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnEipStatus.
+func (in *OvnEipStatus) DeepCopy() *OvnEipStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OvnEipStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OvnFip) DeepCopyInto(out *OvnFip) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnFip.
+func (in *OvnFip) DeepCopy() *OvnFip {
+	if in == nil {
+		return nil
+	}
+	out := new(OvnFip)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OvnFip) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OvnFipList) DeepCopyInto(out *OvnFipList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OvnFip, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnFipList.
+func (in *OvnFipList) DeepCopy() *OvnFipList {
+	if in == nil {
+		return nil
+	}
+	out := new(OvnFipList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OvnFipList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OvnFipSpec) DeepCopyInto(out *OvnFipSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnFipSpec.
+func (in *OvnFipSpec) DeepCopy() *OvnFipSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OvnFipSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OvnFipStatus) DeepCopyInto(out *OvnFipStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnFipStatus.
+func (in *OvnFipStatus) DeepCopy() *OvnFipStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OvnFipStatus)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IptablesSnatRuleCondition) DeepCopyInto(out *IptablesSnatRuleCondition) {
+func (in *OvnSnatRule) DeepCopyInto(out *OvnSnatRule) {
 	*out = *in
-	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	out.Status = in.Status
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IptablesSnatRuleCondition.
-func (in *IptablesSnatRuleCondition) DeepCopy() *IptablesSnatRuleCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnSnatRule.
+func (in *OvnSnatRule) DeepCopy() *OvnSnatRule {
 	if in == nil {
 		return nil
 	}
-	out := new(IptablesSnatRuleCondition)
+	out := new(OvnSnatRule)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OvnSnatRule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IptablesSnatRuleList) DeepCopyInto(out *IptablesSnatRuleList) {
+func (in *OvnSnatRuleList) DeepCopyInto(out *OvnSnatRuleList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]IptablesSnatRule, len(*in))
+		*out = make([]OvnSnatRule, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -644,18 +1257,18 @@ func (in *IptablesSnatRuleList) DeepCopyInto(out *IptablesSnatRuleList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IptablesSnatRuleList.
-func (in *IptablesSnatRuleList) DeepCopy() *IptablesSnatRuleList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnSnatRuleList.
+func (in *OvnSnatRuleList) DeepCopy() *OvnSnatRuleList {
 	if in == nil {
 		return nil
 	}
-	out := new(IptablesSnatRuleList)
+	out := new(OvnSnatRuleList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *IptablesSnatRuleList) DeepCopyObject() runtime.Object {
+func (in *OvnSnatRuleList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -663,40 +1276,33 @@ func (in *IptablesSnatRuleList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IptablesSnatRuleSpec) DeepCopyInto(out *IptablesSnatRuleSpec) {
+func (in *OvnSnatRuleSpec) DeepCopyInto(out *OvnSnatRuleSpec) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IptablesSnatRuleSpec.
-func (in *IptablesSnatRuleSpec) DeepCopy() *IptablesSnatRuleSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnSnatRuleSpec.
+func (in *OvnSnatRuleSpec) DeepCopy() *OvnSnatRuleSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(IptablesSnatRuleSpec)
+	out := new(OvnSnatRuleSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IptablesSnatRuleStatus) DeepCopyInto(out *IptablesSnatRuleStatus) {
+func (in *OvnSnatRuleStatus) DeepCopyInto(out *OvnSnatRuleStatus) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]IptablesSnatRuleCondition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IptablesSnatRuleStatus.
-func (in *IptablesSnatRuleStatus) DeepCopy() *IptablesSnatRuleStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnSnatRuleStatus.
+func (in *OvnSnatRuleStatus) DeepCopy() *OvnSnatRuleStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(IptablesSnatRuleStatus)
+	out := new(OvnSnatRuleStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -857,13 +1463,158 @@ func (in *ProviderNetworkStatus) DeepCopy() *ProviderNetworkStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QoSPolicy) DeepCopyInto(out *QoSPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QoSPolicy.
+func (in *QoSPolicy) DeepCopy() *QoSPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(QoSPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QoSPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QoSPolicyBinding) DeepCopyInto(out *QoSPolicyBinding) {
+	*out = *in
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QoSPolicyBinding.
+func (in *QoSPolicyBinding) DeepCopy() *QoSPolicyBinding {
+	if in == nil {
+		return nil
+	}
+	out := new(QoSPolicyBinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QoSPolicyList) DeepCopyInto(out *QoSPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]QoSPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QoSPolicyList.
+func (in *QoSPolicyList) DeepCopy() *QoSPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(QoSPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QoSPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QoSPolicySpec) DeepCopyInto(out *QoSPolicySpec) {
+	*out = *in
+	in.Binding.DeepCopyInto(&out.Binding)
+	if in.Dscp != nil {
+		in, out := &in.Dscp, &out.Dscp
+		*out = new(int)
+		**out = **in
+	}
+	if in.DestinationCIDRs != nil {
+		in, out := &in.DestinationCIDRs, &out.DestinationCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DestinationSubnets != nil {
+		in, out := &in.DestinationSubnets, &out.DestinationSubnets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QoSPolicySpec.
+func (in *QoSPolicySpec) DeepCopy() *QoSPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(QoSPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QoSPolicyStatus) DeepCopyInto(out *QoSPolicyStatus) {
+	*out = *in
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Eips != nil {
+		in, out := &in.Eips, &out.Eips
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QoSPolicyStatus.
+func (in *QoSPolicyStatus) DeepCopy() *QoSPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(QoSPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecurityGroup) DeepCopyInto(out *SecurityGroup) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -956,9 +1707,54 @@ func (in *SecurityGroupSpec) DeepCopy() *SecurityGroupSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityGroupCondition) DeepCopyInto(out *SecurityGroupCondition) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityGroupCondition.
+func (in *SecurityGroupCondition) DeepCopy() *SecurityGroupCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityGroupCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecurityGroupStatus) DeepCopyInto(out *SecurityGroupStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]SecurityGroupCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.IngressRules != nil {
+		in, out := &in.IngressRules, &out.IngressRules
+		*out = make([]SgRuleStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.EgressRules != nil {
+		in, out := &in.EgressRules, &out.EgressRules
+		*out = make([]SgRuleStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -988,6 +1784,54 @@ func (in *SgRule) DeepCopy() *SgRule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SgRuleStatus) DeepCopyInto(out *SgRuleStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SgRuleStatus.
+func (in *SgRuleStatus) DeepCopy() *SgRuleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SgRuleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlrBackendStatus) DeepCopyInto(out *SlrBackendStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlrBackendStatus.
+func (in *SlrBackendStatus) DeepCopy() *SlrBackendStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SlrBackendStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlrHealthCheck) DeepCopyInto(out *SlrHealthCheck) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlrHealthCheck.
+func (in *SlrHealthCheck) DeepCopy() *SlrHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(SlrHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SlrPort) DeepCopyInto(out *SlrPort) {
 	*out = *in
@@ -1048,6 +1892,22 @@ func (in *Subnet) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnetCIDRUsage) DeepCopyInto(out *SubnetCIDRUsage) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetCIDRUsage.
+func (in *SubnetCIDRUsage) DeepCopy() *SubnetCIDRUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnetCIDRUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SubnetCondition) DeepCopyInto(out *SubnetCondition) {
 	*out = *in
@@ -1112,11 +1972,21 @@ func (in *SubnetSpec) DeepCopyInto(out *SubnetSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.SecondaryCIDRBlocks != nil {
+		in, out := &in.SecondaryCIDRBlocks, &out.SecondaryCIDRBlocks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.AllowSubnets != nil {
 		in, out := &in.AllowSubnets, &out.AllowSubnets
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Dscp != nil {
+		in, out := &in.Dscp, &out.Dscp
+		*out = new(int)
+		**out = **in
+	}
 	if in.Vips != nil {
 		in, out := &in.Vips, &out.Vips
 		*out = make([]string, len(*in))
@@ -1150,6 +2020,16 @@ func (in *SubnetStatus) DeepCopyInto(out *SubnetStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.CIDRUsage != nil {
+		in, out := &in.CIDRUsage, &out.CIDRUsage
+		*out = make([]SubnetCIDRUsage, len(*in))
+		copy(*out, *in)
+	}
+	if in.Dscp != nil {
+		in, out := &in.Dscp, &out.Dscp
+		*out = new(int)
+		**out = **in
+	}
 	return
 }
 
@@ -1255,6 +2135,11 @@ func (in *SwitchLBRuleSpec) DeepCopyInto(out *SwitchLBRuleSpec) {
 		*out = make([]SlrPort, len(*in))
 		copy(*out, *in)
 	}
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(SlrHealthCheck)
+		**out = **in
+	}
 	return
 }
 
@@ -1278,6 +2163,11 @@ func (in *SwitchLBRuleStatus) DeepCopyInto(out *SwitchLBRuleStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Backends != nil {
+		in, out := &in.Backends, &out.Backends
+		*out = make([]SlrBackendStatus, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1740,6 +2630,7 @@ func (in *VpcNatGateway) DeepCopyInto(out *VpcNatGateway) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
 	return
 }
 
@@ -1794,6 +2685,22 @@ func (in *VpcNatGatewayList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VpcNatGatewayStatus) DeepCopyInto(out *VpcNatGatewayStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VpcNatGatewayStatus.
+func (in *VpcNatGatewayStatus) DeepCopy() *VpcNatGatewayStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VpcNatGatewayStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VpcNatSpec) DeepCopyInto(out *VpcNatSpec) {
 	*out = *in
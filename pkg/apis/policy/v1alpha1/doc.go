@@ -0,0 +1,4 @@
+// Package v1alpha1 mirrors the AdminNetworkPolicy and BaselineAdminNetworkPolicy API of
+// sigs.k8s.io/network-policy-api, the objects are watched by dynamic informers and converted
+// from the unstructured ones, so no clientset of the upstream project is required.
+package v1alpha1
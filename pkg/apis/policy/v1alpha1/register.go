@@ -0,0 +1,16 @@
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GroupName is the group of the upstream admin network policy API
+const GroupName = "policy.networking.k8s.io"
+
+// SchemeGroupVersion is group version of the admin network policy API
+var SchemeGroupVersion = schema.GroupVersion{Group: GroupName, Version: "v1alpha1"}
+
+var (
+	AdminNetworkPoliciesResource         = SchemeGroupVersion.WithResource("adminnetworkpolicies")
+	BaselineAdminNetworkPoliciesResource = SchemeGroupVersion.WithResource("baselineadminnetworkpolicies")
+)
@@ -28,10 +28,18 @@ type FakeKubeovnV1 struct {
 	*testing.Fake
 }
 
+func (c *FakeKubeovnV1) FqdnAddresses() v1.FqdnAddressInterface {
+	return &FakeFqdnAddresses{c}
+}
+
 func (c *FakeKubeovnV1) HtbQoses() v1.HtbQosInterface {
 	return &FakeHtbQoses{c}
 }
 
+func (c *FakeKubeovnV1) IPPools() v1.IPPoolInterface {
+	return &FakeIPPools{c}
+}
+
 func (c *FakeKubeovnV1) IPs() v1.IPInterface {
 	return &FakeIPs{c}
 }
@@ -52,10 +60,30 @@ func (c *FakeKubeovnV1) IptablesSnatRules() v1.IptablesSnatRuleInterface {
 	return &FakeIptablesSnatRules{c}
 }
 
+func (c *FakeKubeovnV1) OvnDnatRules() v1.OvnDnatRuleInterface {
+	return &FakeOvnDnatRules{c}
+}
+
+func (c *FakeKubeovnV1) OvnEips() v1.OvnEipInterface {
+	return &FakeOvnEips{c}
+}
+
+func (c *FakeKubeovnV1) OvnFips() v1.OvnFipInterface {
+	return &FakeOvnFips{c}
+}
+
+func (c *FakeKubeovnV1) OvnSnatRules() v1.OvnSnatRuleInterface {
+	return &FakeOvnSnatRules{c}
+}
+
 func (c *FakeKubeovnV1) ProviderNetworks() v1.ProviderNetworkInterface {
 	return &FakeProviderNetworks{c}
 }
 
+func (c *FakeKubeovnV1) QoSPolicies() v1.QoSPolicyInterface {
+	return &FakeQoSPolicies{c}
+}
+
 func (c *FakeKubeovnV1) SecurityGroups() v1.SecurityGroupInterface {
 	return &FakeSecurityGroups{c}
 }
@@ -18,10 +18,14 @@ limitations under the License.
 
 package v1
 
+type FqdnAddressExpansion interface{}
+
 type HtbQosExpansion interface{}
 
 type IPExpansion interface{}
 
+type IPPoolExpansion interface{}
+
 type IptablesDnatRuleExpansion interface{}
 
 type IptablesEIPExpansion interface{}
@@ -30,8 +34,18 @@ type IptablesFIPRuleExpansion interface{}
 
 type IptablesSnatRuleExpansion interface{}
 
+type OvnDnatRuleExpansion interface{}
+
+type OvnEipExpansion interface{}
+
+type OvnFipExpansion interface{}
+
+type OvnSnatRuleExpansion interface{}
+
 type ProviderNetworkExpansion interface{}
 
+type QoSPolicyExpansion interface{}
+
 type SecurityGroupExpansion interface{}
 
 type SubnetExpansion interface{}
@@ -28,13 +28,20 @@ import (
 
 type KubeovnV1Interface interface {
 	RESTClient() rest.Interface
+	FqdnAddressesGetter
 	HtbQosesGetter
+	IPPoolsGetter
 	IPsGetter
 	IptablesDnatRulesGetter
 	IptablesEIPsGetter
 	IptablesFIPRulesGetter
 	IptablesSnatRulesGetter
+	OvnDnatRulesGetter
+	OvnEipsGetter
+	OvnFipsGetter
+	OvnSnatRulesGetter
 	ProviderNetworksGetter
+	QoSPoliciesGetter
 	SecurityGroupsGetter
 	SubnetsGetter
 	SwitchLBRulesGetter
@@ -50,10 +57,18 @@ type KubeovnV1Client struct {
 	restClient rest.Interface
 }
 
+func (c *KubeovnV1Client) FqdnAddresses() FqdnAddressInterface {
+	return newFqdnAddresses(c)
+}
+
 func (c *KubeovnV1Client) HtbQoses() HtbQosInterface {
 	return newHtbQoses(c)
 }
 
+func (c *KubeovnV1Client) IPPools() IPPoolInterface {
+	return newIPPools(c)
+}
+
 func (c *KubeovnV1Client) IPs() IPInterface {
 	return newIPs(c)
 }
@@ -74,10 +89,30 @@ func (c *KubeovnV1Client) IptablesSnatRules() IptablesSnatRuleInterface {
 	return newIptablesSnatRules(c)
 }
 
+func (c *KubeovnV1Client) OvnDnatRules() OvnDnatRuleInterface {
+	return newOvnDnatRules(c)
+}
+
+func (c *KubeovnV1Client) OvnEips() OvnEipInterface {
+	return newOvnEips(c)
+}
+
+func (c *KubeovnV1Client) OvnFips() OvnFipInterface {
+	return newOvnFips(c)
+}
+
+func (c *KubeovnV1Client) OvnSnatRules() OvnSnatRuleInterface {
+	return newOvnSnatRules(c)
+}
+
 func (c *KubeovnV1Client) ProviderNetworks() ProviderNetworkInterface {
 	return newProviderNetworks(c)
 }
 
+func (c *KubeovnV1Client) QoSPolicies() QoSPolicyInterface {
+	return newQoSPolicies(c)
+}
+
 func (c *KubeovnV1Client) SecurityGroups() SecurityGroupInterface {
 	return newSecurityGroups(c)
 }
@@ -24,8 +24,12 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// FqdnAddresses returns a FqdnAddressInformer.
+	FqdnAddresses() FqdnAddressInformer
 	// HtbQoses returns a HtbQosInformer.
 	HtbQoses() HtbQosInformer
+	// IPPools returns a IPPoolInformer.
+	IPPools() IPPoolInformer
 	// IPs returns a IPInformer.
 	IPs() IPInformer
 	// IptablesDnatRules returns a IptablesDnatRuleInformer.
@@ -36,8 +40,18 @@ type Interface interface {
 	IptablesFIPRules() IptablesFIPRuleInformer
 	// IptablesSnatRules returns a IptablesSnatRuleInformer.
 	IptablesSnatRules() IptablesSnatRuleInformer
+	// OvnDnatRules returns a OvnDnatRuleInformer.
+	OvnDnatRules() OvnDnatRuleInformer
+	// OvnEips returns a OvnEipInformer.
+	OvnEips() OvnEipInformer
+	// OvnFips returns a OvnFipInformer.
+	OvnFips() OvnFipInformer
+	// OvnSnatRules returns a OvnSnatRuleInformer.
+	OvnSnatRules() OvnSnatRuleInformer
 	// ProviderNetworks returns a ProviderNetworkInformer.
 	ProviderNetworks() ProviderNetworkInformer
+	// QoSPolicies returns a QoSPolicyInformer.
+	QoSPolicies() QoSPolicyInformer
 	// SecurityGroups returns a SecurityGroupInformer.
 	SecurityGroups() SecurityGroupInformer
 	// Subnets returns a SubnetInformer.
@@ -67,11 +81,21 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// FqdnAddresses returns a FqdnAddressInformer.
+func (v *version) FqdnAddresses() FqdnAddressInformer {
+	return &fqdnAddressInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // HtbQoses returns a HtbQosInformer.
 func (v *version) HtbQoses() HtbQosInformer {
 	return &htbQosInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// IPPools returns a IPPoolInformer.
+func (v *version) IPPools() IPPoolInformer {
+	return &iPPoolInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // IPs returns a IPInformer.
 func (v *version) IPs() IPInformer {
 	return &iPInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
@@ -97,11 +121,36 @@ func (v *version) IptablesSnatRules() IptablesSnatRuleInformer {
 	return &iptablesSnatRuleInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// OvnDnatRules returns a OvnDnatRuleInformer.
+func (v *version) OvnDnatRules() OvnDnatRuleInformer {
+	return &ovnDnatRuleInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// OvnEips returns a OvnEipInformer.
+func (v *version) OvnEips() OvnEipInformer {
+	return &ovnEipInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// OvnFips returns a OvnFipInformer.
+func (v *version) OvnFips() OvnFipInformer {
+	return &ovnFipInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// OvnSnatRules returns a OvnSnatRuleInformer.
+func (v *version) OvnSnatRules() OvnSnatRuleInformer {
+	return &ovnSnatRuleInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // ProviderNetworks returns a ProviderNetworkInformer.
 func (v *version) ProviderNetworks() ProviderNetworkInformer {
 	return &providerNetworkInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// QoSPolicies returns a QoSPolicyInformer.
+func (v *version) QoSPolicies() QoSPolicyInformer {
+	return &qoSPolicyInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // SecurityGroups returns a SecurityGroupInformer.
 func (v *version) SecurityGroups() SecurityGroupInformer {
 	return &securityGroupInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
@@ -18,6 +18,10 @@ limitations under the License.
 
 package v1
 
+// FqdnAddressListerExpansion allows custom methods to be added to
+// FqdnAddressLister.
+type FqdnAddressListerExpansion interface{}
+
 // HtbQosListerExpansion allows custom methods to be added to
 // HtbQosLister.
 type HtbQosListerExpansion interface{}
@@ -26,6 +30,10 @@ type HtbQosListerExpansion interface{}
 // IPLister.
 type IPListerExpansion interface{}
 
+// IPPoolListerExpansion allows custom methods to be added to
+// IPPoolLister.
+type IPPoolListerExpansion interface{}
+
 // IptablesDnatRuleListerExpansion allows custom methods to be added to
 // IptablesDnatRuleLister.
 type IptablesDnatRuleListerExpansion interface{}
@@ -42,10 +50,30 @@ type IptablesFIPRuleListerExpansion interface{}
 // IptablesSnatRuleLister.
 type IptablesSnatRuleListerExpansion interface{}
 
+// OvnDnatRuleListerExpansion allows custom methods to be added to
+// OvnDnatRuleLister.
+type OvnDnatRuleListerExpansion interface{}
+
+// OvnEipListerExpansion allows custom methods to be added to
+// OvnEipLister.
+type OvnEipListerExpansion interface{}
+
+// OvnFipListerExpansion allows custom methods to be added to
+// OvnFipLister.
+type OvnFipListerExpansion interface{}
+
+// OvnSnatRuleListerExpansion allows custom methods to be added to
+// OvnSnatRuleLister.
+type OvnSnatRuleListerExpansion interface{}
+
 // ProviderNetworkListerExpansion allows custom methods to be added to
 // ProviderNetworkLister.
 type ProviderNetworkListerExpansion interface{}
 
+// QoSPolicyListerExpansion allows custom methods to be added to
+// QoSPolicyLister.
+type QoSPolicyListerExpansion interface{}
+
 // SecurityGroupListerExpansion allows custom methods to be added to
 // SecurityGroupLister.
 type SecurityGroupListerExpansion interface{}
@@ -0,0 +1,879 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	policyv1alpha1 "github.com/kubeovn/kube-ovn/pkg/apis/policy/v1alpha1"
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+const (
+	// all admin network policies are synced together by the key when the policies are changed since pass rules
+	// of a policy are excluded from the rules of the ones with lower precedence, while a single policy is synced
+	// by its key anp/<name> or banp/<name> when the pods, namespaces or nodes selected by it are changed
+	anpSyncKey = "admin-network-policies"
+
+	// priorities of admin network policies supported, each of which takes a range of acl priorities for its rules
+	anpMaxPolicyPriority = 99
+	anpMaxRules          = 100
+
+	anpKind  = "anp"
+	banpKind = "banp"
+	// the only baseline admin network policy allowed in the cluster
+	banpName = "default"
+)
+
+func (c *Controller) enqueueAddAnp(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	klog.V(3).Infof("enqueue add %s %s", obj.(*unstructured.Unstructured).GetKind(), obj.(*unstructured.Unstructured).GetName())
+	c.syncAnpQueue.Add(anpSyncKey)
+}
+
+func (c *Controller) enqueueUpdateAnp(old, new interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	oldAnp := old.(*unstructured.Unstructured)
+	newAnp := new.(*unstructured.Unstructured)
+	if !reflect.DeepEqual(oldAnp.Object["spec"], newAnp.Object["spec"]) {
+		klog.V(3).Infof("enqueue update %s %s", newAnp.GetKind(), newAnp.GetName())
+		c.syncAnpQueue.Add(anpSyncKey)
+	}
+}
+
+func (c *Controller) enqueueDeleteAnp(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	klog.V(3).Infof("enqueue delete admin network policy")
+	c.syncAnpQueue.Add(anpSyncKey)
+}
+
+// enqueueSyncAnps syncs the admin network policies of the keys when the pods, namespaces or nodes selected by them may be changed
+func (c *Controller) enqueueSyncAnps(keys ...string) {
+	for _, key := range keys {
+		klog.V(3).Infof("enqueue sync %s", key)
+		c.syncAnpQueue.Add(key)
+	}
+}
+
+// podMatchAnps returns the keys of the admin network policies whose subject or peers select the pod
+func (c *Controller) podMatchAnps(pod *corev1.Pod) []string {
+	if !c.config.EnableANP || pod.Spec.HostNetwork {
+		return nil
+	}
+	ns, err := c.namespacesLister.Get(pod.Namespace)
+	if err != nil {
+		klog.Errorf("failed to get namespace %s, %v", pod.Namespace, err)
+		return nil
+	}
+	return c.anpsMatching(func(namespaces *metav1.LabelSelector, pods *policyv1alpha1.NamespacedPod) bool {
+		return anpSelectsPod(namespaces, pods, ns.Labels, pod.Labels)
+	}, nil)
+}
+
+// namespaceMatchAnps returns the keys of the admin network policies whose subject or peers select the namespace
+func (c *Controller) namespaceMatchAnps(ns *corev1.Namespace) []string {
+	if !c.config.EnableANP {
+		return nil
+	}
+	return c.anpsMatching(func(namespaces *metav1.LabelSelector, pods *policyv1alpha1.NamespacedPod) bool {
+		return anpSelectsNamespace(namespaces, pods, ns.Labels)
+	}, nil)
+}
+
+// nodeMatchAnps returns the keys of the admin network policies whose egress peers select the node
+func (c *Controller) nodeMatchAnps(node *corev1.Node) []string {
+	if !c.config.EnableANP {
+		return nil
+	}
+	return c.anpsMatching(func(*metav1.LabelSelector, *policyv1alpha1.NamespacedPod) bool {
+		return false
+	}, func(nodes *metav1.LabelSelector) bool {
+		return labelSelectorMatches(nodes, node.Labels)
+	})
+}
+
+// anpsMatching returns the keys of the policies whose subject or peers are selected by selectsPods or whose node peers
+// are selected by selectsNodes. The key of all policies is returned instead if a named port of a pass rule may be
+// resolved by the selected pods, since the rules of the policies with lower precedence are changed as well
+func (c *Controller) anpsMatching(selectsPods func(namespaces *metav1.LabelSelector, pods *policyv1alpha1.NamespacedPod) bool,
+	selectsNodes func(nodes *metav1.LabelSelector) bool) []string {
+	var keys []string
+	for _, spec := range c.anpSpecs() {
+		subjectSelected := selectsPods(spec.subject.Namespaces, spec.subject.Pods)
+		selected := subjectSelected
+		for _, dir := range spec.directions() {
+			for _, rule := range dir.rules {
+				peerSelected := false
+				for _, peer := range rule.peers {
+					if selectsPods(peer.Namespaces, peer.Pods) || (selectsNodes != nil && peer.Nodes != nil && selectsNodes(peer.Nodes)) {
+						peerSelected = true
+						break
+					}
+				}
+				selected = selected || peerSelected
+				// named ports are resolved by the pods receiving the traffic
+				portPodSelected := peerSelected
+				if dir.name == anpIngress.name {
+					portPodSelected = subjectSelected
+				}
+				if portPodSelected && rule.action == policyv1alpha1.AdminNetworkPolicyRuleActionPass && anpHasNamedPort(rule.ports) {
+					return []string{anpSyncKey}
+				}
+			}
+		}
+		if selected {
+			keys = append(keys, spec.key())
+		}
+	}
+	return keys
+}
+
+// anpSelectsNamespace returns whether the namespaces selector or the namespace selector of the pods selector selects the namespace
+func anpSelectsNamespace(namespaces *metav1.LabelSelector, pods *policyv1alpha1.NamespacedPod, nsLabels map[string]string) bool {
+	switch {
+	case namespaces != nil:
+		return labelSelectorMatches(namespaces, nsLabels)
+	case pods != nil:
+		return labelSelectorMatches(&pods.NamespaceSelector, nsLabels)
+	default:
+		return false
+	}
+}
+
+// anpSelectsPod returns whether the namespaces selector or the pods selector selects the pod
+func anpSelectsPod(namespaces *metav1.LabelSelector, pods *policyv1alpha1.NamespacedPod, nsLabels, podLabels map[string]string) bool {
+	if !anpSelectsNamespace(namespaces, pods, nsLabels) {
+		return false
+	}
+	return namespaces != nil || labelSelectorMatches(&pods.PodSelector, podLabels)
+}
+
+func labelSelectorMatches(selector *metav1.LabelSelector, objLabels map[string]string) bool {
+	sel, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		klog.Errorf("error creating label selector, %v", err)
+		return false
+	}
+	return sel.Matches(labels.Set(objLabels))
+}
+
+func (c *Controller) runSyncAnpWorker() {
+	for c.processNextSyncAnpWorkItem() {
+	}
+}
+
+func (c *Controller) processNextSyncAnpWorkItem() bool {
+	obj, shutdown := c.syncAnpQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.syncAnpQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.syncAnpQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleSyncAnps(key); err != nil {
+			c.syncAnpQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.syncAnpQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+// anpPolicy is the port group, address sets and acls translated from an admin network policy
+type anpPolicy struct {
+	key         string
+	pgName      string
+	ports       []string
+	addressSets map[string][]string
+	acls        []*ovnnb.ACL
+}
+
+// anpRule is the common part of the rules of admin network policies and baseline admin network policies
+type anpRule struct {
+	action policyv1alpha1.AdminNetworkPolicyRuleAction
+	peers  []policyv1alpha1.AdminNetworkPolicyEgressPeer
+	ports  *[]policyv1alpha1.AdminNetworkPolicyPort
+}
+
+// anpSpec is the common part of admin network policies and baseline admin network policies,
+// the priority of a rule is maxPriority minus its index
+type anpSpec struct {
+	kind        string
+	name        string
+	subject     policyv1alpha1.AdminNetworkPolicySubject
+	ingress     []anpRule
+	egress      []anpRule
+	maxPriority int
+}
+
+// anpDirection is how the rules of a direction are translated
+type anpDirection struct {
+	name, direction, portField, ipField string
+}
+
+var (
+	anpIngress = anpDirection{"ingress", ovnnb.ACLDirectionToLport, "outport", "src"}
+	anpEgress  = anpDirection{"egress", ovnnb.ACLDirectionFromLport, "inport", "dst"}
+)
+
+type anpDirectionRules struct {
+	anpDirection
+	rules []anpRule
+}
+
+func newAnpSpec(anp *policyv1alpha1.AdminNetworkPolicy) *anpSpec {
+	spec := &anpSpec{
+		kind:        anpKind,
+		name:        anp.Name,
+		subject:     anp.Spec.Subject,
+		maxPriority: util.AnpACLMaxPriority - int(anp.Spec.Priority)*anpMaxRules,
+	}
+	for _, rule := range anp.Spec.Ingress {
+		spec.ingress = append(spec.ingress, anpRule{action: rule.Action, peers: anpIngressPeers(rule.From), ports: rule.Ports})
+	}
+	for _, rule := range anp.Spec.Egress {
+		spec.egress = append(spec.egress, anpRule{action: rule.Action, peers: rule.To, ports: rule.Ports})
+	}
+	return spec
+}
+
+func newBanpSpec(banp *policyv1alpha1.BaselineAdminNetworkPolicy) *anpSpec {
+	spec := &anpSpec{
+		kind:        banpKind,
+		name:        banp.Name,
+		subject:     banp.Spec.Subject,
+		maxPriority: util.BanpACLMaxPriority,
+	}
+	for _, rule := range banp.Spec.Ingress {
+		spec.ingress = append(spec.ingress, anpRule{action: rule.Action, peers: anpIngressPeers(rule.From), ports: rule.Ports})
+	}
+	for _, rule := range banp.Spec.Egress {
+		spec.egress = append(spec.egress, anpRule{action: rule.Action, peers: rule.To, ports: rule.Ports})
+	}
+	return spec
+}
+
+func (s *anpSpec) key() string {
+	return fmt.Sprintf("%s/%s", s.kind, s.name)
+}
+
+func (s *anpSpec) directions() []anpDirectionRules {
+	return []anpDirectionRules{{anpIngress, s.ingress}, {anpEgress, s.egress}}
+}
+
+// anpPortGroupName returns the port group name of the policy, '-' is not allowed in the names
+func anpPortGroupName(kind, name string) string {
+	return strings.ReplaceAll(fmt.Sprintf("%s_%s", kind, name), "-", ".")
+}
+
+func validateAnp(anp *policyv1alpha1.AdminNetworkPolicy) error {
+	if anp.Spec.Priority < 0 || anp.Spec.Priority > anpMaxPolicyPriority {
+		return fmt.Errorf("priority %d is not supported, should be in range 0-%d", anp.Spec.Priority, anpMaxPolicyPriority)
+	}
+	if len(anp.Spec.Ingress) > anpMaxRules || len(anp.Spec.Egress) > anpMaxRules {
+		return fmt.Errorf("at most %d ingress and %d egress rules are supported", anpMaxRules, anpMaxRules)
+	}
+	return nil
+}
+
+func validateBanp(banp *policyv1alpha1.BaselineAdminNetworkPolicy) error {
+	if banp.Name != banpName {
+		return fmt.Errorf("baseline admin network policy should be named %s", banpName)
+	}
+	if len(banp.Spec.Ingress) > anpMaxRules || len(banp.Spec.Egress) > anpMaxRules {
+		return fmt.Errorf("at most %d ingress and %d egress rules are supported", anpMaxRules, anpMaxRules)
+	}
+	for _, rule := range banp.Spec.Ingress {
+		if rule.Action == policyv1alpha1.AdminNetworkPolicyRuleActionPass {
+			return fmt.Errorf("action %s is not supported by baseline admin network policy", rule.Action)
+		}
+	}
+	for _, rule := range banp.Spec.Egress {
+		if rule.Action == policyv1alpha1.AdminNetworkPolicyRuleActionPass {
+			return fmt.Errorf("action %s is not supported by baseline admin network policy", rule.Action)
+		}
+	}
+	return nil
+}
+
+// listAnps returns the valid admin network policies ordered by precedence and the valid baseline admin network policy
+func (c *Controller) listAnps() ([]*policyv1alpha1.AdminNetworkPolicy, *policyv1alpha1.BaselineAdminNetworkPolicy, error) {
+	objs, err := c.anpsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list admin network policies, %v", err)
+		return nil, nil, err
+	}
+	anps := make([]*policyv1alpha1.AdminNetworkPolicy, 0, len(objs))
+	for _, obj := range objs {
+		anp := &policyv1alpha1.AdminNetworkPolicy{}
+		if err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.(*unstructured.Unstructured).UnstructuredContent(), anp); err == nil {
+			err = validateAnp(anp)
+		}
+		if err != nil {
+			klog.Errorf("invalid admin network policy %s, %v", obj.(*unstructured.Unstructured).GetName(), err)
+			c.recorder.Eventf(obj, corev1.EventTypeWarning, "InvalidAdminNetworkPolicy", err.Error())
+			continue
+		}
+		anps = append(anps, anp)
+	}
+	sort.Slice(anps, func(i, j int) bool {
+		if anps[i].Spec.Priority != anps[j].Spec.Priority {
+			return anps[i].Spec.Priority < anps[j].Spec.Priority
+		}
+		return anps[i].Name < anps[j].Name
+	})
+
+	objs, err = c.banpsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list baseline admin network policies, %v", err)
+		return nil, nil, err
+	}
+	var banp *policyv1alpha1.BaselineAdminNetworkPolicy
+	for _, obj := range objs {
+		policy := &policyv1alpha1.BaselineAdminNetworkPolicy{}
+		if err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.(*unstructured.Unstructured).UnstructuredContent(), policy); err == nil {
+			err = validateBanp(policy)
+		}
+		if err != nil {
+			klog.Errorf("invalid baseline admin network policy %s, %v", obj.(*unstructured.Unstructured).GetName(), err)
+			c.recorder.Eventf(obj, corev1.EventTypeWarning, "InvalidBaselineAdminNetworkPolicy", err.Error())
+			continue
+		}
+		banp = policy
+	}
+	return anps, banp, nil
+}
+
+// anpSpecs returns the admin network policies and the baseline admin network policies without validation,
+// which is used to find the policies selecting an object
+func (c *Controller) anpSpecs() []*anpSpec {
+	var specs []*anpSpec
+	objs, err := c.anpsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list admin network policies, %v", err)
+	}
+	for _, obj := range objs {
+		anp := &policyv1alpha1.AdminNetworkPolicy{}
+		if err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.(*unstructured.Unstructured).UnstructuredContent(), anp); err == nil {
+			specs = append(specs, newAnpSpec(anp))
+		}
+	}
+	if objs, err = c.banpsLister.List(labels.Everything()); err != nil {
+		klog.Errorf("failed to list baseline admin network policies, %v", err)
+	}
+	for _, obj := range objs {
+		banp := &policyv1alpha1.BaselineAdminNetworkPolicy{}
+		if err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.(*unstructured.Unstructured).UnstructuredContent(), banp); err == nil {
+			specs = append(specs, newBanpSpec(banp))
+		}
+	}
+	return specs
+}
+
+func (c *Controller) handleSyncAnps(key string) error {
+	anps, banp, err := c.listAnps()
+	if err != nil {
+		return err
+	}
+	specs := make([]*anpSpec, 0, len(anps)+1)
+	for _, anp := range anps {
+		specs = append(specs, newAnpSpec(anp))
+	}
+	if banp != nil {
+		specs = append(specs, newBanpSpec(banp))
+	}
+	if key != anpSyncKey {
+		return c.syncAnp(key, specs)
+	}
+	klog.V(3).Infof("sync %d admin network policies", len(anps))
+
+	var policies []*anpPolicy
+	// rules matching the traffic passed by rules with higher precedence are skipped
+	passMatches := make(map[string][]string, 2)
+	for _, spec := range specs {
+		// traffic passed by admin network policies is evaluated by baseline admin network policy as well
+		if spec.kind == banpKind {
+			passMatches = nil
+		}
+		policy, err := c.translateAnp(spec, passMatches)
+		if err != nil {
+			klog.Errorf("failed to translate %s, %v", spec.key(), err)
+			return err
+		}
+		policies = append(policies, policy)
+	}
+
+	expected := make(map[string]bool, len(policies))
+	for _, policy := range policies {
+		expected[policy.pgName] = true
+		if err = c.applyAnpPolicy(policy); err != nil {
+			klog.Errorf("failed to apply %s, %v", policy.key, err)
+			return err
+		}
+	}
+
+	pgs, err := c.ovnClient.ListPortGroups(map[string]string{"anp": ""})
+	if err != nil {
+		klog.Errorf("failed to list port groups of admin network policies, %v", err)
+		return err
+	}
+	for _, pg := range pgs {
+		if expected[pg.Name] {
+			continue
+		}
+		klog.Infof("delete port group %s of %s", pg.Name, pg.ExternalIDs["anp"])
+		if err = c.ovnClient.DeletePortGroup(pg.Name); err != nil {
+			klog.Errorf("failed to delete port group %s, %v", pg.Name, err)
+			return err
+		}
+		if err = c.deleteAnpAddressSets(pg.ExternalIDs["anp"], nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncAnp syncs the policy of the key only, the pass rules of the policies with higher precedence
+// are resolved for its rules without syncing those policies
+func (c *Controller) syncAnp(key string, specs []*anpSpec) error {
+	passMatches := make(map[string][]string, 2)
+	for _, spec := range specs {
+		if spec.key() != key {
+			if spec.kind == anpKind {
+				if err := c.appendAnpPassMatches(spec, passMatches); err != nil {
+					klog.Errorf("failed to resolve pass rules of %s, %v", spec.key(), err)
+					return err
+				}
+			}
+			continue
+		}
+
+		klog.V(3).Infof("sync %s", key)
+		if spec.kind == banpKind {
+			passMatches = nil
+		}
+		policy, err := c.translateAnp(spec, passMatches)
+		if err != nil {
+			klog.Errorf("failed to translate %s, %v", key, err)
+			return err
+		}
+		if err = c.applyAnpPolicy(policy); err != nil {
+			klog.Errorf("failed to apply %s, %v", key, err)
+			return err
+		}
+		return nil
+	}
+	// deleted or invalid policies are cleaned by the sync of all policies
+	klog.V(3).Infof("skip sync of %s, not found", key)
+	return nil
+}
+
+func anpIngressPeers(peers []policyv1alpha1.AdminNetworkPolicyIngressPeer) []policyv1alpha1.AdminNetworkPolicyEgressPeer {
+	result := make([]policyv1alpha1.AdminNetworkPolicyEgressPeer, 0, len(peers))
+	for _, peer := range peers {
+		result = append(result, policyv1alpha1.AdminNetworkPolicyEgressPeer{Namespaces: peer.Namespaces, Pods: peer.Pods})
+	}
+	return result
+}
+
+// translateAnp translates the rules of the policy to acls,
+// pass rules are not translated to acls but excluded from the following rules by passMatches
+func (c *Controller) translateAnp(spec *anpSpec, passMatches map[string][]string) (*anpPolicy, error) {
+	kind, name := spec.kind, spec.name
+	pgName := anpPortGroupName(kind, name)
+	policy := &anpPolicy{
+		key:         spec.key(),
+		pgName:      pgName,
+		addressSets: make(map[string][]string),
+	}
+
+	subjectPods, err := c.anpSelectedPods(spec.subject.Namespaces, spec.subject.Pods)
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range subjectPods {
+		ports, err := c.getPodOvnPorts(pod)
+		if err != nil {
+			return nil, err
+		}
+		policy.ports = append(policy.ports, ports...)
+	}
+
+	for _, dir := range spec.directions() {
+		for idx, rule := range dir.rules {
+			peerPods, addresses, err := c.anpPeerAddresses(rule.peers)
+			if err != nil {
+				return nil, err
+			}
+			for _, protocol := range []string{kubeovnv1.ProtocolIPv4, kubeovnv1.ProtocolIPv6} {
+				policy.addressSets[anpAddressSetName(pgName, dir.anpDirection, idx, protocol)] = addresses[protocol]
+			}
+
+			// named ports are resolved by the pods receiving the traffic
+			portPods := peerPods
+			if dir.name == "ingress" {
+				portPods = subjectPods
+			}
+			portsMatch, ok := anpPortsMatch(rule.ports, portPods)
+			if !ok {
+				klog.Warningf("skip %s rule %d of %s %s, no port is resolved", dir.name, idx, kind, name)
+				continue
+			}
+			match := anpRuleMatch(pgName, dir.anpDirection, idx, portsMatch)
+
+			aclMatch := match
+			for _, passMatch := range passMatches[dir.direction] {
+				aclMatch = fmt.Sprintf("%s && !(%s)", aclMatch, passMatch)
+			}
+			var action string
+			switch rule.action {
+			case policyv1alpha1.AdminNetworkPolicyRuleActionAllow:
+				action = ovnnb.ACLActionAllowRelated
+			case policyv1alpha1.AdminNetworkPolicyRuleActionDeny:
+				action = ovnnb.ACLActionDrop
+			case policyv1alpha1.AdminNetworkPolicyRuleActionPass:
+				passMatches[dir.direction] = append(passMatches[dir.direction], match)
+				continue
+			default:
+				klog.Warningf("skip %s rule %d of %s %s, unknown action %s", dir.name, idx, kind, name, rule.action)
+				continue
+			}
+			policy.acls = append(policy.acls, &ovnnb.ACL{
+				Action:      action,
+				Direction:   dir.direction,
+				Match:       aclMatch,
+				Priority:    spec.maxPriority - idx,
+				ExternalIDs: map[string]string{"anp": policy.key},
+			})
+		}
+	}
+	return policy, nil
+}
+
+// appendAnpPassMatches appends the matches of the pass rules of the policy to passMatches without translating its other rules
+func (c *Controller) appendAnpPassMatches(spec *anpSpec, passMatches map[string][]string) error {
+	pgName := anpPortGroupName(spec.kind, spec.name)
+	for _, dir := range spec.directions() {
+		for idx, rule := range dir.rules {
+			if rule.action != policyv1alpha1.AdminNetworkPolicyRuleActionPass {
+				continue
+			}
+			// named ports are resolved by the pods receiving the traffic
+			var portPods []*corev1.Pod
+			if anpHasNamedPort(rule.ports) {
+				if dir.name == anpIngress.name {
+					pods, err := c.anpSelectedPods(spec.subject.Namespaces, spec.subject.Pods)
+					if err != nil {
+						return err
+					}
+					portPods = pods
+				} else {
+					for _, peer := range rule.peers {
+						pods, err := c.anpSelectedPods(peer.Namespaces, peer.Pods)
+						if err != nil {
+							return err
+						}
+						portPods = append(portPods, pods...)
+					}
+				}
+			}
+			if portsMatch, ok := anpPortsMatch(rule.ports, portPods); ok {
+				passMatches[dir.direction] = append(passMatches[dir.direction], anpRuleMatch(pgName, dir.anpDirection, idx, portsMatch))
+			}
+		}
+	}
+	return nil
+}
+
+func anpAddressSetName(pgName string, dir anpDirection, idx int, protocol string) string {
+	return fmt.Sprintf("%s.%s.%d.%s", pgName, dir.name, idx, protocol)
+}
+
+// anpRuleMatch returns the match of the rule, the peers are matched by the address sets of the rule
+func anpRuleMatch(pgName string, dir anpDirection, idx int, portsMatch string) string {
+	return fmt.Sprintf("%s == @%s && (ip4.%s == $%s || ip6.%s == $%s)%s", dir.portField, pgName,
+		dir.ipField, anpAddressSetName(pgName, dir, idx, kubeovnv1.ProtocolIPv4),
+		dir.ipField, anpAddressSetName(pgName, dir, idx, kubeovnv1.ProtocolIPv6), portsMatch)
+}
+
+func anpHasNamedPort(ports *[]policyv1alpha1.AdminNetworkPolicyPort) bool {
+	if ports == nil {
+		return false
+	}
+	for _, port := range *ports {
+		if port.NamedPort != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// anpSelectedPods returns the alive pods of the selected namespaces or the pods selected in the namespaces
+func (c *Controller) anpSelectedPods(namespaces *metav1.LabelSelector, pods *policyv1alpha1.NamespacedPod) ([]*corev1.Pod, error) {
+	var nsSelector, podSelector *metav1.LabelSelector
+	switch {
+	case namespaces != nil:
+		nsSelector = namespaces
+	case pods != nil:
+		nsSelector, podSelector = &pods.NamespaceSelector, &pods.PodSelector
+	default:
+		return nil, nil
+	}
+
+	nsSel, err := metav1.LabelSelectorAsSelector(nsSelector)
+	if err != nil {
+		return nil, fmt.Errorf("error creating label selector, %v", err)
+	}
+	podSel := labels.Everything()
+	if podSelector != nil {
+		if podSel, err = metav1.LabelSelectorAsSelector(podSelector); err != nil {
+			return nil, fmt.Errorf("error creating label selector, %v", err)
+		}
+	}
+	nss, err := c.namespacesLister.List(nsSel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ns, %v", err)
+	}
+
+	var result []*corev1.Pod
+	for _, ns := range nss {
+		pods, err := c.podsLister.Pods(ns.Name).List(podSel)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods, %v", err)
+		}
+		for _, pod := range pods {
+			if isPodAlive(pod) && !pod.Spec.HostNetwork {
+				result = append(result, pod)
+			}
+		}
+	}
+	return result, nil
+}
+
+// getPodOvnPorts returns the logical switch ports of the pod in ovn subnets
+func (c *Controller) getPodOvnPorts(pod *corev1.Pod) ([]string, error) {
+	podNets, err := c.getPodKubeovnNets(pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod networks, %v", err)
+	}
+	podName := c.getNameByPod(pod)
+	var ports []string
+	for _, podNet := range podNets {
+		if isOvnSubnet(podNet.Subnet) && pod.Annotations[fmt.Sprintf(util.AllocatedAnnotationTemplate, podNet.ProviderName)] == "true" {
+			ports = append(ports, ovs.PodNameToPortName(podName, pod.Namespace, podNet.ProviderName))
+		}
+	}
+	return ports, nil
+}
+
+// anpPeerAddresses returns the pods and the addresses by protocol of the peers, including the cluster ips
+// of the services selecting the pods, the internal ips of the nodes and the networks
+func (c *Controller) anpPeerAddresses(peers []policyv1alpha1.AdminNetworkPolicyEgressPeer) ([]*corev1.Pod, map[string][]string, error) {
+	var pods []*corev1.Pod
+	addresses := map[string][]string{kubeovnv1.ProtocolIPv4: {}, kubeovnv1.ProtocolIPv6: {}}
+	for _, peer := range peers {
+		peerPods, err := c.anpSelectedPods(peer.Namespaces, peer.Pods)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, pod := range peerPods {
+			svcs, err := c.servicesLister.Services(pod.Namespace).List(labels.Everything())
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to list svc, %v", err)
+			}
+			for _, podIP := range pod.Status.PodIPs {
+				protocol := util.CheckProtocol(podIP.IP)
+				if protocol != kubeovnv1.ProtocolIPv4 && protocol != kubeovnv1.ProtocolIPv6 {
+					continue
+				}
+				addresses[protocol] = append(addresses[protocol], podIP.IP)
+				svcIPs, err := svcMatchPods(svcs, pod, protocol)
+				if err != nil {
+					return nil, nil, err
+				}
+				addresses[protocol] = append(addresses[protocol], svcIPs...)
+			}
+		}
+		pods = append(pods, peerPods...)
+
+		if peer.Nodes != nil {
+			sel, err := metav1.LabelSelectorAsSelector(peer.Nodes)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error creating label selector, %v", err)
+			}
+			nodes, err := c.nodesLister.List(sel)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to list nodes, %v", err)
+			}
+			for _, node := range nodes {
+				v4IP, v6IP := util.GetNodeInternalIP(*node)
+				if v4IP != "" {
+					addresses[kubeovnv1.ProtocolIPv4] = append(addresses[kubeovnv1.ProtocolIPv4], v4IP)
+				}
+				if v6IP != "" {
+					addresses[kubeovnv1.ProtocolIPv6] = append(addresses[kubeovnv1.ProtocolIPv6], v6IP)
+				}
+			}
+		}
+		for _, cidr := range peer.Networks {
+			if protocol := util.CheckProtocol(cidr); protocol == kubeovnv1.ProtocolIPv4 || protocol == kubeovnv1.ProtocolIPv6 {
+				addresses[protocol] = append(addresses[protocol], cidr)
+			}
+		}
+	}
+	for protocol := range addresses {
+		addresses[protocol] = util.UniqString(addresses[protocol])
+		sort.Strings(addresses[protocol])
+	}
+	return pods, addresses, nil
+}
+
+// anpPortsMatch returns the match of the destination ports, named ports are resolved by the container ports of the pods,
+// false is returned if the ports are specified but none of them is resolved
+func anpPortsMatch(ports *[]policyv1alpha1.AdminNetworkPolicyPort, pods []*corev1.Pod) (string, bool) {
+	if ports == nil {
+		return "", true
+	}
+
+	var matches []string
+	for _, port := range *ports {
+		switch {
+		case port.PortNumber != nil:
+			matches = append(matches, fmt.Sprintf("%s.dst == %d", strings.ToLower(string(port.PortNumber.Protocol)), port.PortNumber.Port))
+		case port.PortRange != nil:
+			protocol := port.PortRange.Protocol
+			if protocol == "" {
+				protocol = corev1.ProtocolTCP
+			}
+			matches = append(matches, fmt.Sprintf("%d <= %s.dst <= %d", port.PortRange.Start, strings.ToLower(string(protocol)), port.PortRange.End))
+		case port.NamedPort != nil:
+			for _, pod := range pods {
+				for _, container := range pod.Spec.Containers {
+					for _, containerPort := range container.Ports {
+						if containerPort.Name != *port.NamedPort {
+							continue
+						}
+						protocol := containerPort.Protocol
+						if protocol == "" {
+							protocol = corev1.ProtocolTCP
+						}
+						matches = append(matches, fmt.Sprintf("%s.dst == %d", strings.ToLower(string(protocol)), containerPort.ContainerPort))
+					}
+				}
+			}
+		}
+	}
+	matches = util.UniqString(matches)
+	sort.Strings(matches)
+	if len(matches) == 0 {
+		return "", false
+	}
+	return fmt.Sprintf(" && (%s)", strings.Join(matches, " || ")), true
+}
+
+// applyAnpPolicy makes the port group, address sets and acls of the policy consistent with the translated ones
+func (c *Controller) applyAnpPolicy(policy *anpPolicy) error {
+	if err := c.ovnClient.CreatePortGroup(policy.pgName, map[string]string{"anp": policy.key}); err != nil {
+		klog.Errorf("failed to create port group %s, %v", policy.pgName, err)
+		return err
+	}
+	if err := c.ovnClient.PortGroupSetPorts(policy.pgName, policy.ports); err != nil {
+		klog.Errorf("failed to set ports of port group %s, %v", policy.pgName, err)
+		return err
+	}
+
+	for asName, addresses := range policy.addressSets {
+		if err := c.ovnClient.CreateAddressSet(asName, map[string]string{"anp": policy.key}); err != nil {
+			klog.Errorf("failed to create address set %s, %v", asName, err)
+			return err
+		}
+		if err := c.ovnClient.SetAddressesToAddressSet(addresses, asName); err != nil {
+			klog.Errorf("failed to set addresses of address set %s, %v", asName, err)
+			return err
+		}
+	}
+
+	acls, err := c.ovnClient.ListPortGroupACLs(policy.pgName, "")
+	if err != nil {
+		klog.Errorf("failed to list acls of port group %s, %v", policy.pgName, err)
+		return err
+	}
+	existing := make([]string, 0, len(acls))
+	for _, acl := range acls {
+		existing = append(existing, anpACLKey(&acl))
+	}
+	expected := make([]string, 0, len(policy.acls))
+	for _, acl := range policy.acls {
+		expected = append(expected, anpACLKey(acl))
+	}
+	sort.Strings(existing)
+	sort.Strings(expected)
+	if !reflect.DeepEqual(existing, expected) {
+		klog.Infof("update acls of %s", policy.key)
+		if err = c.ovnClient.DeletePortGroupACLs(policy.pgName, ""); err != nil {
+			klog.Errorf("failed to delete acls of port group %s, %v", policy.pgName, err)
+			return err
+		}
+		if err = c.ovnClient.CreatePortGroupACLs(policy.pgName, policy.acls...); err != nil {
+			klog.Errorf("failed to create acls of port group %s, %v", policy.pgName, err)
+			return err
+		}
+	}
+
+	// address sets of removed rules are deleted after no acl refers to them
+	return c.deleteAnpAddressSets(policy.key, policy.addressSets)
+}
+
+func anpACLKey(acl *ovnnb.ACL) string {
+	return fmt.Sprintf("%s/%d/%s/%s", acl.Direction, acl.Priority, acl.Action, acl.Match)
+}
+
+// deleteAnpAddressSets deletes the address sets of the policy except the kept ones
+func (c *Controller) deleteAnpAddressSets(key string, kept map[string][]string) error {
+	asList, err := c.ovnClient.ListAddressSets(map[string]string{"anp": key})
+	if err != nil {
+		klog.Errorf("failed to list address sets of %s, %v", key, err)
+		return err
+	}
+	for _, as := range asList {
+		if _, ok := kept[as.Name]; ok {
+			continue
+		}
+		if err = c.ovnClient.DeleteAddressSet(as.Name); err != nil {
+			klog.Errorf("failed to delete address set %s, %v", as.Name, err)
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	policyv1alpha1 "github.com/kubeovn/kube-ovn/pkg/apis/policy/v1alpha1"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// setAnpListers replaces the listers of the dynamic informers with ones holding the policies
+func setAnpListers(t *testing.T, ctrl *fakeController, anps []*policyv1alpha1.AdminNetworkPolicy, banp *policyv1alpha1.BaselineAdminNetworkPolicy) {
+	t.Helper()
+	toUnstructured := func(obj interface{}) *unstructured.Unstructured {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		require.NoError(t, err)
+		return &unstructured.Unstructured{Object: content}
+	}
+
+	anpIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, anp := range anps {
+		require.NoError(t, anpIndexer.Add(toUnstructured(anp)))
+	}
+	banpIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if banp != nil {
+		require.NoError(t, banpIndexer.Add(toUnstructured(banp)))
+	}
+	ctrl.anpsLister = cache.NewGenericLister(anpIndexer, policyv1alpha1.AdminNetworkPoliciesResource.GroupResource())
+	ctrl.banpsLister = cache.NewGenericLister(banpIndexer, policyv1alpha1.BaselineAdminNetworkPoliciesResource.GroupResource())
+}
+
+func newAnpTestPod(namespace, name, ip string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+			Annotations: map[string]string{
+				util.AllocatedAnnotation:     "true",
+				util.LogicalSwitchAnnotation: util.DefaultSubnet,
+			},
+		},
+		Status: corev1.PodStatus{PodIPs: []corev1.PodIP{{IP: ip}}},
+	}
+}
+
+func Test_handleSyncAnps(t *testing.T) {
+	subnet := &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: util.DefaultSubnet},
+		Spec: kubeovnv1.SubnetSpec{
+			Vpc:       util.DefaultVpc,
+			CIDRBlock: "10.16.0.0/16",
+			Gateway:   "10.16.0.1",
+			Protocol:  kubeovnv1.ProtocolIPv4,
+		},
+	}
+	kubeObjects := []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod", Labels: map[string]string{"env": "prod"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev", Labels: map[string]string{"env": "dev"}}},
+		newAnpTestPod("prod", "web", "10.16.0.10", map[string]string{"app": "web"}),
+		newAnpTestPod("dev", "client", "10.16.0.20", nil),
+	}
+	ctrl := newFakeController(t, kubeObjects, []runtime.Object{subnet})
+
+	tcp80 := []policyv1alpha1.AdminNetworkPolicyPort{{PortNumber: &policyv1alpha1.Port{Protocol: corev1.ProtocolTCP, Port: 80}}}
+	devPeer := []policyv1alpha1.AdminNetworkPolicyIngressPeer{{
+		Namespaces: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}},
+	}}
+	anp := &policyv1alpha1.AdminNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "protect-prod"},
+		Spec: policyv1alpha1.AdminNetworkPolicySpec{
+			Priority: 10,
+			Subject: policyv1alpha1.AdminNetworkPolicySubject{
+				Namespaces: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+			},
+			Ingress: []policyv1alpha1.AdminNetworkPolicyIngressRule{
+				{Name: "pass-http", Action: policyv1alpha1.AdminNetworkPolicyRuleActionPass, From: devPeer, Ports: &tcp80},
+				{Name: "deny-dev", Action: policyv1alpha1.AdminNetworkPolicyRuleActionDeny, From: devPeer},
+			},
+		},
+	}
+	banp := &policyv1alpha1.BaselineAdminNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: banpName},
+		Spec: policyv1alpha1.BaselineAdminNetworkPolicySpec{
+			Subject: policyv1alpha1.AdminNetworkPolicySubject{
+				Namespaces: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+			},
+			Ingress: []policyv1alpha1.BaselineAdminNetworkPolicyIngressRule{
+				{Name: "deny-all", Action: policyv1alpha1.AdminNetworkPolicyRuleActionDeny, From: devPeer},
+			},
+		},
+	}
+	setAnpListers(t, ctrl, []*policyv1alpha1.AdminNetworkPolicy{anp}, banp)
+
+	require.NoError(t, ctrl.handleSyncAnps(anpSyncKey))
+
+	pg, err := ctrl.ovnClient.GetPortGroup("anp_protect.prod", false)
+	require.NoError(t, err)
+	require.Equal(t, "anp/protect-prod", pg.ExternalIDs["anp"])
+	as, err := ctrl.ovnClient.GetAddressSet("anp_protect.prod.ingress.1.IPv4", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.16.0.20"}, as.Addresses)
+
+	// the pass rule is excluded from the deny rule instead of being translated to an acl
+	acls, err := ctrl.ovnClient.ListPortGroupACLs("anp_protect.prod", "")
+	require.NoError(t, err)
+	require.Len(t, acls, 1)
+	require.Equal(t, ovnnb.ACLActionDrop, acls[0].Action)
+	require.Equal(t, util.AnpACLMaxPriority-10*anpMaxRules-1, acls[0].Priority)
+	require.Contains(t, acls[0].Match, "&& !(outport == @anp_protect.prod && ")
+	require.Contains(t, acls[0].Match, "tcp.dst == 80")
+
+	acls, err = ctrl.ovnClient.ListPortGroupACLs("banp_default", "")
+	require.NoError(t, err)
+	require.Len(t, acls, 1)
+	require.Equal(t, util.BanpACLMaxPriority, acls[0].Priority)
+	require.NotContains(t, acls[0].Match, "!(")
+
+	// port groups and address sets of the deleted policies are deleted
+	setAnpListers(t, ctrl, nil, nil)
+	require.NoError(t, ctrl.handleSyncAnps(anpSyncKey))
+	pgs, err := ctrl.ovnClient.ListPortGroups(map[string]string{"anp": ""})
+	require.NoError(t, err)
+	require.Empty(t, pgs)
+	asList, err := ctrl.ovnClient.ListAddressSets(map[string]string{"anp": "anp/protect-prod"})
+	require.NoError(t, err)
+	require.Empty(t, asList)
+}
+
+func Test_anpsMatching(t *testing.T) {
+	kubeObjects := []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod", Labels: map[string]string{"env": "prod"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev", Labels: map[string]string{"env": "dev"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test"}},
+	}
+	ctrl := newFakeController(t, kubeObjects, nil)
+	ctrl.config.EnableANP = true
+
+	devPeer := []policyv1alpha1.AdminNetworkPolicyIngressPeer{{
+		Namespaces: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}},
+	}}
+	protectProd := &policyv1alpha1.AdminNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "protect-prod"},
+		Spec: policyv1alpha1.AdminNetworkPolicySpec{
+			Priority: 10,
+			Subject: policyv1alpha1.AdminNetworkPolicySubject{
+				Namespaces: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+			},
+			Ingress: []policyv1alpha1.AdminNetworkPolicyIngressRule{
+				{Name: "deny-dev", Action: policyv1alpha1.AdminNetworkPolicyRuleActionDeny, From: devPeer},
+			},
+		},
+	}
+	denyNodes := &policyv1alpha1.AdminNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "deny-nodes"},
+		Spec: policyv1alpha1.AdminNetworkPolicySpec{
+			Priority: 20,
+			Subject: policyv1alpha1.AdminNetworkPolicySubject{
+				Pods: &policyv1alpha1.NamespacedPod{
+					NamespaceSelector: metav1.LabelSelector{},
+					PodSelector:       metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				},
+			},
+			Egress: []policyv1alpha1.AdminNetworkPolicyEgressRule{{
+				Name:   "deny-masters",
+				Action: policyv1alpha1.AdminNetworkPolicyRuleActionDeny,
+				To:     []policyv1alpha1.AdminNetworkPolicyEgressPeer{{Nodes: &metav1.LabelSelector{MatchLabels: map[string]string{"role": "master"}}}},
+			}},
+		},
+	}
+	setAnpListers(t, ctrl, []*policyv1alpha1.AdminNetworkPolicy{protectProd, denyNodes}, nil)
+
+	web := newAnpTestPod("prod", "web", "10.16.0.10", map[string]string{"app": "web"})
+	require.ElementsMatch(t, []string{"anp/protect-prod", "anp/deny-nodes"}, ctrl.podMatchAnps(web))
+	require.Equal(t, []string{"anp/protect-prod"}, ctrl.podMatchAnps(newAnpTestPod("dev", "client", "10.16.0.20", nil)))
+	require.Equal(t, []string{"anp/deny-nodes"}, ctrl.podMatchAnps(newAnpTestPod("test", "web", "10.16.0.30", map[string]string{"app": "web"})))
+	require.Empty(t, ctrl.podMatchAnps(newAnpTestPod("test", "db", "10.16.0.40", nil)))
+
+	// the pods of a namespace selected by the namespace selector of a pods selector may be selected
+	require.ElementsMatch(t, []string{"anp/protect-prod", "anp/deny-nodes"}, ctrl.namespaceMatchAnps(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev", Labels: map[string]string{"env": "dev"}}}))
+	require.Equal(t, []string{"anp/deny-nodes"}, ctrl.namespaceMatchAnps(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test"}}))
+
+	require.Equal(t, []string{"anp/deny-nodes"}, ctrl.nodeMatchAnps(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{"role": "master"}}}))
+	require.Empty(t, ctrl.nodeMatchAnps(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node2"}}))
+
+	// named ports of pass rules change the rules of the policies with lower precedence
+	name := "http"
+	protectProd.Spec.Ingress = append([]policyv1alpha1.AdminNetworkPolicyIngressRule{{
+		Name: "pass-http", Action: policyv1alpha1.AdminNetworkPolicyRuleActionPass, From: devPeer,
+		Ports: &[]policyv1alpha1.AdminNetworkPolicyPort{{NamedPort: &name}},
+	}}, protectProd.Spec.Ingress...)
+	setAnpListers(t, ctrl, []*policyv1alpha1.AdminNetworkPolicy{protectProd, denyNodes}, nil)
+	require.Equal(t, []string{anpSyncKey}, ctrl.podMatchAnps(web))
+	require.Equal(t, []string{"anp/protect-prod"}, ctrl.podMatchAnps(newAnpTestPod("dev", "client", "10.16.0.20", nil)))
+
+	ctrl.config.EnableANP = false
+	require.Empty(t, ctrl.podMatchAnps(web))
+}
+
+func Test_handleSyncAnps_singlePolicy(t *testing.T) {
+	subnet := &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: util.DefaultSubnet},
+		Spec: kubeovnv1.SubnetSpec{
+			Vpc:       util.DefaultVpc,
+			CIDRBlock: "10.16.0.0/16",
+			Gateway:   "10.16.0.1",
+			Protocol:  kubeovnv1.ProtocolIPv4,
+		},
+	}
+	kubeObjects := []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod", Labels: map[string]string{"env": "prod"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev", Labels: map[string]string{"env": "dev"}}},
+		newAnpTestPod("prod", "web", "10.16.0.10", map[string]string{"app": "web"}),
+		newAnpTestPod("dev", "client", "10.16.0.20", nil),
+	}
+	ctrl := newFakeController(t, kubeObjects, []runtime.Object{subnet})
+
+	prodSubject := policyv1alpha1.AdminNetworkPolicySubject{
+		Namespaces: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+	}
+	devPeer := []policyv1alpha1.AdminNetworkPolicyIngressPeer{{
+		Namespaces: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}},
+	}}
+	pass := &policyv1alpha1.AdminNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "pass-dev"},
+		Spec: policyv1alpha1.AdminNetworkPolicySpec{
+			Priority: 10,
+			Subject:  prodSubject,
+			Ingress: []policyv1alpha1.AdminNetworkPolicyIngressRule{
+				{Name: "pass-dev", Action: policyv1alpha1.AdminNetworkPolicyRuleActionPass, From: devPeer},
+			},
+		},
+	}
+	deny := &policyv1alpha1.AdminNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "deny-dev"},
+		Spec: policyv1alpha1.AdminNetworkPolicySpec{
+			Priority: 20,
+			Subject:  prodSubject,
+			Ingress: []policyv1alpha1.AdminNetworkPolicyIngressRule{
+				{Name: "deny-dev", Action: policyv1alpha1.AdminNetworkPolicyRuleActionDeny, From: devPeer},
+			},
+		},
+	}
+	setAnpListers(t, ctrl, []*policyv1alpha1.AdminNetworkPolicy{pass, deny}, nil)
+
+	// only the policy of the key is synced, with the pass rules of the policies with higher precedence resolved
+	require.NoError(t, ctrl.handleSyncAnps("anp/deny-dev"))
+	acls, err := ctrl.ovnClient.ListPortGroupACLs("anp_deny.dev", "")
+	require.NoError(t, err)
+	require.Len(t, acls, 1)
+	require.Contains(t, acls[0].Match, "&& !(outport == @anp_pass.dev && (ip4.src == $anp_pass.dev.ingress.0.IPv4 || ")
+	as, err := ctrl.ovnClient.GetAddressSet("anp_deny.dev.ingress.0.IPv4", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.16.0.20"}, as.Addresses)
+	_, err = ctrl.ovnClient.GetPortGroup("anp_pass.dev", false)
+	require.Error(t, err)
+
+	// the acls are the same as the ones synced with all policies
+	require.NoError(t, ctrl.handleSyncAnps(anpSyncKey))
+	synced, err := ctrl.ovnClient.ListPortGroupACLs("anp_deny.dev", "")
+	require.NoError(t, err)
+	require.Len(t, synced, 1)
+	require.Equal(t, acls[0].Match, synced[0].Match)
+
+	// policies not found are left to the sync of all policies
+	require.NoError(t, ctrl.handleSyncAnps("anp/not-found"))
+}
+
+func Test_validateBanp(t *testing.T) {
+	banp := &policyv1alpha1.BaselineAdminNetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+	require.Error(t, validateBanp(banp))
+
+	banp.Name = banpName
+	require.NoError(t, validateBanp(banp))
+
+	banp.Spec.Egress = []policyv1alpha1.BaselineAdminNetworkPolicyEgressRule{{Action: policyv1alpha1.AdminNetworkPolicyRuleActionPass}}
+	require.Error(t, validateBanp(banp))
+}
+
+func Test_anpPortsMatch(t *testing.T) {
+	name := "http"
+	pods := []*corev1.Pod{{
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+		}}},
+	}}
+
+	match, ok := anpPortsMatch(nil, pods)
+	require.True(t, ok)
+	require.Empty(t, match)
+
+	ports := []policyv1alpha1.AdminNetworkPolicyPort{
+		{NamedPort: &name},
+		{PortRange: &policyv1alpha1.PortRange{Protocol: corev1.ProtocolUDP, Start: 1000, End: 2000}},
+	}
+	match, ok = anpPortsMatch(&ports, pods)
+	require.True(t, ok)
+	require.Equal(t, " && (1000 <= udp.dst <= 2000 || tcp.dst == 8080)", match)
+
+	ports = []policyv1alpha1.AdminNetworkPolicyPort{{NamedPort: &name}}
+	_, ok = anpPortsMatch(&ports, nil)
+	require.False(t, ok)
+}
@@ -8,6 +8,7 @@ import (
 
 	attachnetclientset "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/client/clientset/versioned"
 	"github.com/spf13/pflag"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -37,6 +38,8 @@ type Configuration struct {
 	// with no timeout
 	KubeFactoryClient    kubernetes.Interface
 	KubeOvnFactoryClient clientset.Interface
+	// watches admin network policies which have no typed clientset
+	DynamicFactoryClient dynamic.Interface
 
 	DefaultLogicalSwitch  string
 	DefaultCIDR           string
@@ -78,6 +81,7 @@ type Configuration struct {
 
 	EnableLb          bool
 	EnableNP          bool
+	EnableANP         bool
 	EnableEipSnat     bool
 	EnableExternalVpc bool
 	EnableEcmp        bool
@@ -90,6 +94,21 @@ type Configuration struct {
 
 	GCInterval      int
 	InspectInterval int
+
+	EnableIPAMCheckpoint   bool
+	IPAMCheckpointInterval int
+
+	EnableOvnNbBatch   bool
+	OvnNbBatchSize     int
+	OvnNbBatchInterval int
+	OvnNbBatchWorkers  int
+
+	QoSMode string
+
+	AclLogRate int
+
+	LbIpamSubnet string
+	LbIpamIPPool string
 }
 
 // ParseFlags parses cmd args then init kubeclient and conf
@@ -138,6 +157,7 @@ func ParseFlags() (*Configuration, error) {
 		argPodDefaultFipType       = pflag.String("pod-default-fip-type", "", "The type of fip bind to pod automatically: iptables")
 		argEnableLb                = pflag.Bool("enable-lb", true, "Enable load balancer")
 		argEnableNP                = pflag.Bool("enable-np", true, "Enable network policy support")
+		argEnableANP               = pflag.Bool("enable-anp", false, "Enable admin network policy and baseline admin network policy support, the CRDs of policy.networking.k8s.io should be installed")
 		argEnableEipSnat           = pflag.Bool("enable-eip-snat", true, "Enable EIP and SNAT")
 		argEnableExternalVpc       = pflag.Bool("enable-external-vpc", true, "Enable external vpc support")
 		argEnableEcmp              = pflag.Bool("enable-ecmp", false, "Enable ecmp route for centralized subnet")
@@ -150,6 +170,21 @@ func ParseFlags() (*Configuration, error) {
 
 		argGCInterval      = pflag.Int("gc-interval", 360, "The interval between GC processes, default 360 seconds")
 		argInspectInterval = pflag.Int("inspect-interval", 20, "The interval between inspect processes, default 20 seconds")
+
+		argEnableIPAMCheckpoint   = pflag.Bool("enable-ipam-checkpoint", false, "Save ipam to a configmap periodically and resume from it on startup instead of rebuilding it from all pods")
+		argIPAMCheckpointInterval = pflag.Int("ipam-checkpoint-interval", 60, "The interval between saving ipam checkpoints, default 60 seconds")
+
+		argEnableOvnNbBatch   = pflag.Bool("enable-ovn-nb-batch", false, "Merge the ovn nb operations of concurrent pod workers into batched transactions")
+		argOvnNbBatchSize     = pflag.Int("ovn-nb-batch-size", 1000, "The max count of operations in one batched ovn nb transaction")
+		argOvnNbBatchInterval = pflag.Int("ovn-nb-batch-interval", 50, "The max milliseconds an ovn nb transaction waits for others to be batched with")
+		argOvnNbBatchWorkers  = pflag.Int("ovn-nb-batch-workers", 32, "The parallelism of add pod worker when ovn nb batch is enabled")
+
+		argQoSMode = pflag.String("qos-mode", util.QoSModeHost, "Where the bandwidth limits are enforced: host for ovs qos of the local interfaces, ovn for qos rules of the logical switches")
+
+		argAclLogRate = pflag.Int("acl-log-rate", 100, "The max packets per second logged by each acl of network policies and security groups")
+
+		argLbIpamSubnet = pflag.String("lb-ipam-subnet", "", "The subnet from which the ingress ips of loadbalancer services are allocated, conflicts with --enable-lb-svc")
+		argLbIpamIPPool = pflag.String("lb-ipam-ippool", "", "The ippool from which the ingress ips of loadbalancer services are allocated, conflicts with --enable-lb-svc")
 	)
 
 	klogFlags := flag.NewFlagSet("klog", flag.ExitOnError)
@@ -208,6 +243,7 @@ func ParseFlags() (*Configuration, error) {
 		PodDefaultFipType:             *argPodDefaultFipType,
 		EnableLb:                      *argEnableLb,
 		EnableNP:                      *argEnableNP,
+		EnableANP:                     *argEnableANP,
 		EnableEipSnat:                 *argEnableEipSnat,
 		EnableExternalVpc:             *argEnableExternalVpc,
 		ExternalGatewayConfigNS:       *argExternalGatewayConfigNS,
@@ -219,6 +255,28 @@ func ParseFlags() (*Configuration, error) {
 		GCInterval:                    *argGCInterval,
 		InspectInterval:               *argInspectInterval,
 		EnableLbSvc:                   *argEnableLbSvc,
+		EnableIPAMCheckpoint:          *argEnableIPAMCheckpoint,
+		IPAMCheckpointInterval:        *argIPAMCheckpointInterval,
+		EnableOvnNbBatch:              *argEnableOvnNbBatch,
+		OvnNbBatchSize:                *argOvnNbBatchSize,
+		OvnNbBatchInterval:            *argOvnNbBatchInterval,
+		OvnNbBatchWorkers:             *argOvnNbBatchWorkers,
+		QoSMode:                       *argQoSMode,
+		AclLogRate:                    *argAclLogRate,
+		LbIpamSubnet:                  *argLbIpamSubnet,
+		LbIpamIPPool:                  *argLbIpamIPPool,
+	}
+
+	if config.QoSMode != util.QoSModeHost && config.QoSMode != util.QoSModeOvn {
+		return nil, fmt.Errorf("invalid qos mode %s, should be %s or %s", config.QoSMode, util.QoSModeHost, util.QoSModeOvn)
+	}
+
+	if config.AclLogRate <= 0 {
+		return nil, fmt.Errorf("invalid acl log rate %d, should be positive", config.AclLogRate)
+	}
+
+	if config.EnableLbSvc && (config.LbIpamSubnet != "" || config.LbIpamIPPool != "") {
+		return nil, fmt.Errorf("loadbalancer ipam can not be enabled together with lb svc")
 	}
 
 	if config.NetworkType == util.NetworkTypeVlan && config.DefaultHostInterface == "" {
@@ -337,6 +395,13 @@ func (config *Configuration) initKubeFactoryClient() error {
 	}
 	config.KubeOvnFactoryClient = kubeOvnClient
 
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		klog.Errorf("init dynamic client failed %v", err)
+		return err
+	}
+	config.DynamicFactoryClient = dynamicClient
+
 	cfg.ContentType = "application/vnd.kubernetes.protobuf"
 	cfg.AcceptContentTypes = "application/vnd.kubernetes.protobuf,application/json"
 	kubeClient, err := kubernetes.NewForConfig(cfg)
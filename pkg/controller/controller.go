@@ -11,10 +11,12 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/scheme"
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	v1 "k8s.io/client-go/listers/core/v1"
+	discoveryv1 "k8s.io/client-go/listers/discovery/v1"
 	netv1 "k8s.io/client-go/listers/networking/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/leaderelection"
@@ -23,6 +25,7 @@ import (
 	"k8s.io/klog/v2"
 
 	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	policyv1alpha1 "github.com/kubeovn/kube-ovn/pkg/apis/policy/v1alpha1"
 	kubeovninformer "github.com/kubeovn/kube-ovn/pkg/client/informers/externalversions"
 	kubeovnlister "github.com/kubeovn/kube-ovn/pkg/client/listers/kubeovn/v1"
 	ovnipam "github.com/kubeovn/kube-ovn/pkg/ipam"
@@ -40,9 +43,13 @@ type Controller struct {
 	podSubnetMap *sync.Map
 	ipam         *ovnipam.IPAM
 
-	ovnLegacyClient *ovs.LegacyClient
-	ovnClient       *ovs.OvnClient
+	ovnLegacyClient ovs.LegacyOvnClient
+	ovnClient       ovs.NbClient
 	ovnPgKeyMutex   *keymutex.KeyMutex
+	ovnNbBatcher    *ovs.NbBatcher
+
+	// gateway type of the external gateway, centralized or distributed
+	externalGatewayType string
 
 	podsLister             v1.PodLister
 	podsSynced             cache.InformerSynced
@@ -68,6 +75,7 @@ type Controller struct {
 	updateVpcDnatQueue            workqueue.RateLimitingInterface
 	updateVpcSnatQueue            workqueue.RateLimitingInterface
 	updateVpcSubnetQueue          workqueue.RateLimitingInterface
+	updateVpcNatGwActiveQueue     workqueue.RateLimitingInterface
 	vpcNatGwKeyMutex              *keymutex.KeyMutex
 
 	switchLBRuleLister      kubeovnlister.SwitchLBRuleLister
@@ -93,6 +101,13 @@ type Controller struct {
 	ipsLister kubeovnlister.IPLister
 	ipSynced  cache.InformerSynced
 
+	ippoolLister            kubeovnlister.IPPoolLister
+	ippoolSynced            cache.InformerSynced
+	addOrUpdateIPPoolQueue  workqueue.RateLimitingInterface
+	updateIPPoolStatusQueue workqueue.RateLimitingInterface
+	deleteIPPoolQueue       workqueue.RateLimitingInterface
+	ippoolKeyMutex          *keymutex.KeyMutex
+
 	virtualIpsLister     kubeovnlister.VipLister
 	virtualIpsSynced     cache.InformerSynced
 	addVirtualIpQueue    workqueue.RateLimitingInterface
@@ -136,6 +151,26 @@ type Controller struct {
 	updateIptablesSnatRuleQueue workqueue.RateLimitingInterface
 	delIptablesSnatRuleQueue    workqueue.RateLimitingInterface
 
+	ovnEipsLister          kubeovnlister.OvnEipLister
+	ovnEipSynced           cache.InformerSynced
+	addOrUpdateOvnEipQueue workqueue.RateLimitingInterface
+	delOvnEipQueue         workqueue.RateLimitingInterface
+
+	ovnFipsLister          kubeovnlister.OvnFipLister
+	ovnFipSynced           cache.InformerSynced
+	addOrUpdateOvnFipQueue workqueue.RateLimitingInterface
+	delOvnFipQueue         workqueue.RateLimitingInterface
+
+	ovnSnatRulesLister          kubeovnlister.OvnSnatRuleLister
+	ovnSnatRuleSynced           cache.InformerSynced
+	addOrUpdateOvnSnatRuleQueue workqueue.RateLimitingInterface
+	delOvnSnatRuleQueue         workqueue.RateLimitingInterface
+
+	ovnDnatRulesLister          kubeovnlister.OvnDnatRuleLister
+	ovnDnatRuleSynced           cache.InformerSynced
+	addOrUpdateOvnDnatRuleQueue workqueue.RateLimitingInterface
+	delOvnDnatRuleQueue         workqueue.RateLimitingInterface
+
 	vlansLister kubeovnlister.VlanLister
 	vlanSynced  cache.InformerSynced
 
@@ -163,9 +198,9 @@ type Controller struct {
 	deleteServiceQueue workqueue.RateLimitingInterface
 	updateServiceQueue workqueue.RateLimitingInterface
 
-	endpointsLister     v1.EndpointsLister
-	endpointsSynced     cache.InformerSynced
-	updateEndpointQueue workqueue.RateLimitingInterface
+	endpointSlicesLister discoveryv1.EndpointSliceLister
+	endpointSlicesSynced cache.InformerSynced
+	updateEndpointQueue  workqueue.RateLimitingInterface
 
 	npsLister     netv1.NetworkPolicyLister
 	npsSynced     cache.InformerSynced
@@ -179,6 +214,20 @@ type Controller struct {
 	syncSgPortsQueue   workqueue.RateLimitingInterface
 	sgKeyMutex         *keymutex.KeyMutex
 
+	qosPoliciesLister  kubeovnlister.QoSPolicyLister
+	qosPolicySynced    cache.InformerSynced
+	syncQoSPolicyQueue workqueue.RateLimitingInterface
+
+	fqdnAddressesLister  kubeovnlister.FqdnAddressLister
+	fqdnAddressSynced    cache.InformerSynced
+	syncFqdnAddressQueue workqueue.RateLimitingInterface
+
+	anpsLister   cache.GenericLister
+	anpsSynced   cache.InformerSynced
+	banpsLister  cache.GenericLister
+	banpsSynced  cache.InformerSynced
+	syncAnpQueue workqueue.RateLimitingInterface
+
 	configMapsLister v1.ConfigMapLister
 	configMapsSynced cache.InformerSynced
 
@@ -186,6 +235,7 @@ type Controller struct {
 	informerFactory        kubeinformers.SharedInformerFactory
 	cmInformerFactory      kubeinformers.SharedInformerFactory
 	kubeovnInformerFactory kubeovninformer.SharedInformerFactory
+	anpInformerFactory     dynamicinformer.DynamicSharedInformerFactory
 	elector                *leaderelection.LeaderElector
 }
 
@@ -219,21 +269,28 @@ func NewController(config *Configuration) *Controller {
 	vpcNatGatewayInformer := kubeovnInformerFactory.Kubeovn().V1().VpcNatGateways()
 	subnetInformer := kubeovnInformerFactory.Kubeovn().V1().Subnets()
 	ipInformer := kubeovnInformerFactory.Kubeovn().V1().IPs()
+	ippoolInformer := kubeovnInformerFactory.Kubeovn().V1().IPPools()
 	virtualIpInformer := kubeovnInformerFactory.Kubeovn().V1().Vips()
 	iptablesEipInformer := kubeovnInformerFactory.Kubeovn().V1().IptablesEIPs()
 	iptablesFipInformer := kubeovnInformerFactory.Kubeovn().V1().IptablesFIPRules()
 	iptablesDnatRuleInformer := kubeovnInformerFactory.Kubeovn().V1().IptablesDnatRules()
 	iptablesSnatRuleInformer := kubeovnInformerFactory.Kubeovn().V1().IptablesSnatRules()
+	ovnEipInformer := kubeovnInformerFactory.Kubeovn().V1().OvnEips()
+	ovnFipInformer := kubeovnInformerFactory.Kubeovn().V1().OvnFips()
+	ovnSnatRuleInformer := kubeovnInformerFactory.Kubeovn().V1().OvnSnatRules()
+	ovnDnatRuleInformer := kubeovnInformerFactory.Kubeovn().V1().OvnDnatRules()
 	vlanInformer := kubeovnInformerFactory.Kubeovn().V1().Vlans()
 	providerNetworkInformer := kubeovnInformerFactory.Kubeovn().V1().ProviderNetworks()
 	sgInformer := kubeovnInformerFactory.Kubeovn().V1().SecurityGroups()
+	qosPolicyInformer := kubeovnInformerFactory.Kubeovn().V1().QoSPolicies()
+	fqdnAddressInformer := kubeovnInformerFactory.Kubeovn().V1().FqdnAddresses()
 	podInformer := informerFactory.Core().V1().Pods()
 	podAnnotatedIptablesEipInformer := informerFactory.Core().V1().Pods()
 	podAnnotatedIptablesFipInformer := informerFactory.Core().V1().Pods()
 	namespaceInformer := informerFactory.Core().V1().Namespaces()
 	nodeInformer := informerFactory.Core().V1().Nodes()
 	serviceInformer := informerFactory.Core().V1().Services()
-	endpointInformer := informerFactory.Core().V1().Endpoints()
+	endpointSliceInformer := informerFactory.Discovery().V1().EndpointSlices()
 	configMapInformer := cmInformerFactory.Core().V1().ConfigMaps()
 
 	controller := &Controller{
@@ -260,6 +317,7 @@ func NewController(config *Configuration) *Controller {
 		updateVpcDnatQueue:            workqueue.NewNamedRateLimitingQueue(custCrdRateLimiter, "UpdateVpcDnat"),
 		updateVpcSnatQueue:            workqueue.NewNamedRateLimitingQueue(custCrdRateLimiter, "UpdateVpcSnat"),
 		updateVpcSubnetQueue:          workqueue.NewNamedRateLimitingQueue(custCrdRateLimiter, "UpdateVpcSubnet"),
+		updateVpcNatGwActiveQueue:     workqueue.NewNamedRateLimitingQueue(custCrdRateLimiter, "UpdateVpcNatGwActive"),
 		vpcNatGwKeyMutex:              keymutex.New(97),
 
 		subnetsLister:           subnetInformer.Lister(),
@@ -274,6 +332,13 @@ func NewController(config *Configuration) *Controller {
 		ipsLister: ipInformer.Lister(),
 		ipSynced:  ipInformer.Informer().HasSynced,
 
+		ippoolLister:            ippoolInformer.Lister(),
+		ippoolSynced:            ippoolInformer.Informer().HasSynced,
+		addOrUpdateIPPoolQueue:  workqueue.NewNamedRateLimitingQueue(custCrdRateLimiter, "AddOrUpdateIPPool"),
+		updateIPPoolStatusQueue: workqueue.NewNamedRateLimitingQueue(custCrdRateLimiter, "UpdateIPPoolStatus"),
+		deleteIPPoolQueue:       workqueue.NewNamedRateLimitingQueue(custCrdRateLimiter, "DeleteIPPool"),
+		ippoolKeyMutex:          keymutex.New(97),
+
 		virtualIpsLister:     virtualIpInformer.Lister(),
 		virtualIpsSynced:     virtualIpInformer.Informer().HasSynced,
 		addVirtualIpQueue:    workqueue.NewNamedRateLimitingQueue(custCrdRateLimiter, "addVirtualIp"),
@@ -317,6 +382,26 @@ func NewController(config *Configuration) *Controller {
 		updateIptablesSnatRuleQueue: workqueue.NewNamedRateLimitingQueue(custCrdRateLimiter, "updateIptablesSnatRule"),
 		delIptablesSnatRuleQueue:    workqueue.NewNamedRateLimitingQueue(custCrdRateLimiter, "delIptablesSnatRule"),
 
+		ovnEipsLister:          ovnEipInformer.Lister(),
+		ovnEipSynced:           ovnEipInformer.Informer().HasSynced,
+		addOrUpdateOvnEipQueue: workqueue.NewNamedRateLimitingQueue(custCrdRateLimiter, "addOrUpdateOvnEip"),
+		delOvnEipQueue:         workqueue.NewNamedRateLimitingQueue(custCrdRateLimiter, "delOvnEip"),
+
+		ovnFipsLister:          ovnFipInformer.Lister(),
+		ovnFipSynced:           ovnFipInformer.Informer().HasSynced,
+		addOrUpdateOvnFipQueue: workqueue.NewNamedRateLimitingQueue(custCrdRateLimiter, "addOrUpdateOvnFip"),
+		delOvnFipQueue:         workqueue.NewNamedRateLimitingQueue(custCrdRateLimiter, "delOvnFip"),
+
+		ovnSnatRulesLister:          ovnSnatRuleInformer.Lister(),
+		ovnSnatRuleSynced:           ovnSnatRuleInformer.Informer().HasSynced,
+		addOrUpdateOvnSnatRuleQueue: workqueue.NewNamedRateLimitingQueue(custCrdRateLimiter, "addOrUpdateOvnSnatRule"),
+		delOvnSnatRuleQueue:         workqueue.NewNamedRateLimitingQueue(custCrdRateLimiter, "delOvnSnatRule"),
+
+		ovnDnatRulesLister:          ovnDnatRuleInformer.Lister(),
+		ovnDnatRuleSynced:           ovnDnatRuleInformer.Informer().HasSynced,
+		addOrUpdateOvnDnatRuleQueue: workqueue.NewNamedRateLimitingQueue(custCrdRateLimiter, "addOrUpdateOvnDnatRule"),
+		delOvnDnatRuleQueue:         workqueue.NewNamedRateLimitingQueue(custCrdRateLimiter, "delOvnDnatRule"),
+
 		vlansLister:     vlanInformer.Lister(),
 		vlanSynced:      vlanInformer.Informer().HasSynced,
 		addVlanQueue:    workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "AddVlan"),
@@ -351,9 +436,9 @@ func NewController(config *Configuration) *Controller {
 		deleteServiceQueue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "DeleteService"),
 		updateServiceQueue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "UpdateService"),
 
-		endpointsLister:     endpointInformer.Lister(),
-		endpointsSynced:     endpointInformer.Informer().HasSynced,
-		updateEndpointQueue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "UpdateEndpoint"),
+		endpointSlicesLister: endpointSliceInformer.Lister(),
+		endpointSlicesSynced: endpointSliceInformer.Informer().HasSynced,
+		updateEndpointQueue:  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "UpdateEndpoint"),
 
 		configMapsLister: configMapInformer.Lister(),
 		configMapsSynced: configMapInformer.Informer().HasSynced,
@@ -367,6 +452,14 @@ func NewController(config *Configuration) *Controller {
 		syncSgPortsQueue:   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "SyncSgPorts"),
 		sgKeyMutex:         keymutex.New(97),
 
+		qosPoliciesLister:  qosPolicyInformer.Lister(),
+		qosPolicySynced:    qosPolicyInformer.Informer().HasSynced,
+		syncQoSPolicyQueue: workqueue.NewNamedRateLimitingQueue(custCrdRateLimiter, "SyncQoSPolicy"),
+
+		fqdnAddressesLister:  fqdnAddressInformer.Lister(),
+		fqdnAddressSynced:    fqdnAddressInformer.Informer().HasSynced,
+		syncFqdnAddressQueue: workqueue.NewNamedRateLimitingQueue(custCrdRateLimiter, "SyncFqdnAddress"),
+
 		informerFactory:        informerFactory,
 		cmInformerFactory:      cmInformerFactory,
 		kubeovnInformerFactory: kubeovnInformerFactory,
@@ -376,6 +469,9 @@ func NewController(config *Configuration) *Controller {
 	if controller.ovnClient, err = ovs.NewOvnClient(config.OvnNbAddr, config.OvnTimeout); err != nil {
 		klog.Fatal(err)
 	}
+	if config.EnableOvnNbBatch {
+		controller.ovnNbBatcher = controller.ovnClient.NewBatcher(config.OvnNbBatchSize, time.Duration(config.OvnNbBatchInterval)*time.Millisecond)
+	}
 
 	podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc:    controller.enqueueAddPod,
@@ -401,9 +497,10 @@ func NewController(config *Configuration) *Controller {
 		UpdateFunc: controller.enqueueUpdateService,
 	})
 
-	endpointInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc:    controller.enqueueAddEndpoint,
-		UpdateFunc: controller.enqueueUpdateEndpoint,
+	endpointSliceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.enqueueAddEndpointSlice,
+		UpdateFunc: controller.enqueueUpdateEndpointSlice,
+		DeleteFunc: controller.enqueueDeleteEndpointSlice,
 	})
 
 	vpcInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -456,6 +553,12 @@ func NewController(config *Configuration) *Controller {
 		DeleteFunc: controller.enqueueAddOrDelIP,
 	})
 
+	ippoolInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.enqueueAddIPPool,
+		UpdateFunc: controller.enqueueUpdateIPPool,
+		DeleteFunc: controller.enqueueDeleteIPPool,
+	})
+
 	vlanInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc:    controller.enqueueAddVlan,
 		DeleteFunc: controller.enqueueDelVlan,
@@ -478,12 +581,40 @@ func NewController(config *Configuration) *Controller {
 			DeleteFunc: controller.enqueueDeleteNp,
 		})
 	}
+	if config.EnableANP {
+		controller.anpInformerFactory = dynamicinformer.NewDynamicSharedInformerFactory(config.DynamicFactoryClient, 0)
+		anpInformer := controller.anpInformerFactory.ForResource(policyv1alpha1.AdminNetworkPoliciesResource)
+		banpInformer := controller.anpInformerFactory.ForResource(policyv1alpha1.BaselineAdminNetworkPoliciesResource)
+		controller.anpsLister = anpInformer.Lister()
+		controller.anpsSynced = anpInformer.Informer().HasSynced
+		controller.banpsLister = banpInformer.Lister()
+		controller.banpsSynced = banpInformer.Informer().HasSynced
+		controller.syncAnpQueue = workqueue.NewNamedRateLimitingQueue(custCrdRateLimiter, "SyncAnp")
+		for _, informer := range []cache.SharedIndexInformer{anpInformer.Informer(), banpInformer.Informer()} {
+			informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+				AddFunc:    controller.enqueueAddAnp,
+				UpdateFunc: controller.enqueueUpdateAnp,
+				DeleteFunc: controller.enqueueDeleteAnp,
+			})
+		}
+	}
 	sgInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc:    controller.enqueueAddSg,
 		DeleteFunc: controller.enqueueDeleteSg,
 		UpdateFunc: controller.enqueueUpdateSg,
 	})
 
+	qosPolicyInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.enqueueAddQoSPolicy,
+		UpdateFunc: controller.enqueueUpdateQoSPolicy,
+		DeleteFunc: controller.enqueueDelQoSPolicy,
+	})
+	fqdnAddressInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.enqueueAddFqdnAddress,
+		UpdateFunc: controller.enqueueUpdateFqdnAddress,
+		DeleteFunc: controller.enqueueDeleteFqdnAddress,
+	})
+
 	virtualIpInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc:    controller.enqueueAddVirtualIp,
 		UpdateFunc: controller.enqueueUpdateVirtualIp,
@@ -514,6 +645,30 @@ func NewController(config *Configuration) *Controller {
 		DeleteFunc: controller.enqueueDelIptablesSnatRule,
 	})
 
+	ovnEipInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.enqueueAddOvnEip,
+		UpdateFunc: controller.enqueueUpdateOvnEip,
+		DeleteFunc: controller.enqueueDelOvnEip,
+	})
+
+	ovnFipInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.enqueueAddOvnFip,
+		UpdateFunc: controller.enqueueUpdateOvnFip,
+		DeleteFunc: controller.enqueueDelOvnFip,
+	})
+
+	ovnSnatRuleInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.enqueueAddOvnSnatRule,
+		UpdateFunc: controller.enqueueUpdateOvnSnatRule,
+		DeleteFunc: controller.enqueueDelOvnSnatRule,
+	})
+
+	ovnDnatRuleInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    controller.enqueueAddOvnDnatRule,
+		UpdateFunc: controller.enqueueUpdateOvnDnatRule,
+		DeleteFunc: controller.enqueueDelOvnDnatRule,
+	})
+
 	podAnnotatedIptablesEipInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc:    controller.enqueueAddPodAnnotatedIptablesEip,
 		UpdateFunc: controller.enqueueUpdatePodAnnotatedIptablesEip,
@@ -542,19 +697,26 @@ func (c *Controller) Run(stopCh <-chan struct{}) {
 	c.informerFactory.Start(stopCh)
 	c.cmInformerFactory.Start(stopCh)
 	c.kubeovnInformerFactory.Start(stopCh)
+	if c.config.EnableANP {
+		c.anpInformerFactory.Start(stopCh)
+	}
 
 	klog.Info("Waiting for informer caches to sync")
 	cacheSyncs := []cache.InformerSynced{
 		c.vpcNatGatewaySynced, c.vpcSynced, c.subnetSynced,
-		c.ipSynced, c.virtualIpsSynced, c.iptablesEipSynced,
+		c.ipSynced, c.ippoolSynced, c.virtualIpsSynced, c.iptablesEipSynced,
 		c.iptablesFipSynced, c.iptablesDnatRuleSynced, c.iptablesSnatRuleSynced,
+		c.ovnEipSynced, c.ovnFipSynced, c.ovnSnatRuleSynced, c.ovnDnatRuleSynced,
 		c.podAnnotatedIptablesEipSynced, c.podAnnotatedIptablesFipSynced,
 		c.vlanSynced, c.podsSynced, c.namespacesSynced, c.nodesSynced,
-		c.serviceSynced, c.endpointsSynced, c.configMapsSynced,
+		c.serviceSynced, c.endpointSlicesSynced, c.configMapsSynced, c.qosPolicySynced, c.fqdnAddressSynced,
 	}
 	if c.config.EnableNP {
 		cacheSyncs = append(cacheSyncs, c.npsSynced)
 	}
+	if c.config.EnableANP {
+		cacheSyncs = append(cacheSyncs, c.anpsSynced, c.banpsSynced)
+	}
 
 	if c.config.EnableLb {
 		cacheSyncs = append(cacheSyncs, c.switchLBRuleSynced, c.vpcDnsSynced)
@@ -651,6 +813,10 @@ func (c *Controller) shutdown() {
 	c.updateSubnetStatusQueue.ShutDown()
 	c.syncVirtualPortsQueue.ShutDown()
 
+	c.addOrUpdateIPPoolQueue.ShutDown()
+	c.updateIPPoolStatusQueue.ShutDown()
+	c.deleteIPPoolQueue.ShutDown()
+
 	c.addNodeQueue.ShutDown()
 	c.updateNodeQueue.ShutDown()
 	c.deleteNodeQueue.ShutDown()
@@ -678,6 +844,7 @@ func (c *Controller) shutdown() {
 	c.updateVpcDnatQueue.ShutDown()
 	c.updateVpcSnatQueue.ShutDown()
 	c.updateVpcSubnetQueue.ShutDown()
+	c.updateVpcNatGwActiveQueue.ShutDown()
 
 	if c.config.EnableLb {
 		c.addSwitchLBRuleQueue.ShutDown()
@@ -709,6 +876,18 @@ func (c *Controller) shutdown() {
 	c.updateIptablesSnatRuleQueue.ShutDown()
 	c.delIptablesSnatRuleQueue.ShutDown()
 
+	c.addOrUpdateOvnEipQueue.ShutDown()
+	c.delOvnEipQueue.ShutDown()
+
+	c.addOrUpdateOvnFipQueue.ShutDown()
+	c.delOvnFipQueue.ShutDown()
+
+	c.addOrUpdateOvnSnatRuleQueue.ShutDown()
+	c.delOvnSnatRuleQueue.ShutDown()
+
+	c.addOrUpdateOvnDnatRuleQueue.ShutDown()
+	c.delOvnDnatRuleQueue.ShutDown()
+
 	if c.config.PodDefaultFipType == util.IptablesFip {
 		c.addPodAnnotatedIptablesEipQueue.ShutDown()
 		c.updatePodAnnotatedIptablesEipQueue.ShutDown()
@@ -725,6 +904,11 @@ func (c *Controller) shutdown() {
 	c.addOrUpdateSgQueue.ShutDown()
 	c.delSgQueue.ShutDown()
 	c.syncSgPortsQueue.ShutDown()
+	c.syncQoSPolicyQueue.ShutDown()
+	c.syncFqdnAddressQueue.ShutDown()
+	if c.config.EnableANP {
+		c.syncAnpQueue.ShutDown()
+	}
 }
 
 func (c *Controller) startWorkers(stopCh <-chan struct{}) {
@@ -740,11 +924,15 @@ func (c *Controller) startWorkers(stopCh <-chan struct{}) {
 	go wait.Until(c.runUpdateVpcDnatWorker, time.Second, stopCh)
 	go wait.Until(c.runUpdateVpcSnatWorker, time.Second, stopCh)
 	go wait.Until(c.runUpdateVpcSubnetWorker, time.Second, stopCh)
+	go wait.Until(c.runUpdateVpcNatGwActiveWorker, time.Second, stopCh)
 
 	// add default/join subnet and wait them ready
 	go wait.Until(c.runAddSubnetWorker, time.Second, stopCh)
 	go wait.Until(c.runAddVlanWorker, time.Second, stopCh)
 	go wait.Until(c.runAddNamespaceWorker, time.Second, stopCh)
+	go wait.Until(c.runAddOrUpdateIPPoolWorker, time.Second, stopCh)
+	go wait.Until(c.runDeleteIPPoolWorker, time.Second, stopCh)
+	go wait.Until(c.runUpdateIPPoolStatusWorker, time.Second, stopCh)
 	for {
 		klog.Infof("wait for %s and %s ready", c.config.DefaultLogicalSwitch, c.config.NodeSwitch)
 		time.Sleep(3 * time.Second)
@@ -761,6 +949,13 @@ func (c *Controller) startWorkers(stopCh <-chan struct{}) {
 	go wait.Until(c.runAddSgWorker, time.Second, stopCh)
 	go wait.Until(c.runDelSgWorker, time.Second, stopCh)
 	go wait.Until(c.runSyncSgPortsWorker, time.Second, stopCh)
+	// sync all qos policies once to clean up the rules of the objects deleted while the controller is down
+	c.syncQoSPolicyQueue.Add(qosPolicySyncKey)
+	go wait.Until(c.runSyncQoSPolicyWorker, time.Second, stopCh)
+	go wait.Until(c.runSyncFqdnAddressWorker, time.Second, stopCh)
+	if c.config.EnableANP {
+		go wait.Until(c.runSyncAnpWorker, time.Second, stopCh)
+	}
 
 	// run node worker before handle any pods
 	for i := 0; i < c.config.WorkerNum; i++ {
@@ -799,6 +994,7 @@ func (c *Controller) startWorkers(stopCh <-chan struct{}) {
 		go wait.Until(c.runAddSwitchLBRuleWorker, time.Second, stopCh)
 		go wait.Until(c.runDelSwitchLBRuleWorker, time.Second, stopCh)
 		go wait.Until(c.runUpdateSwitchLBRuleWorker, time.Second, stopCh)
+		go wait.Until(c.syncSwitchLBRuleBackendStatus, 10*time.Second, stopCh)
 
 		go wait.Until(c.runAddOrUpdateVpcDnsWorker, time.Second, stopCh)
 		go wait.Until(c.runDelVpcDnsWorker, time.Second, stopCh)
@@ -807,8 +1003,17 @@ func (c *Controller) startWorkers(stopCh <-chan struct{}) {
 		}, 5*time.Second, stopCh)
 	}
 
-	for i := 0; i < c.config.WorkerNum; i++ {
+	addPodWorkers := c.config.WorkerNum
+	if c.ovnNbBatcher != nil {
+		// add pod workers wait for the batched transactions, more workers make larger batches
+		go c.ovnNbBatcher.Run(stopCh)
+		addPodWorkers = c.config.OvnNbBatchWorkers
+	}
+	for i := 0; i < addPodWorkers; i++ {
 		go wait.Until(c.runAddPodWorker, time.Second, stopCh)
+	}
+
+	for i := 0; i < c.config.WorkerNum; i++ {
 		go wait.Until(c.runDeletePodWorker, time.Second, stopCh)
 		go wait.Until(c.runUpdatePodWorker, time.Second, stopCh)
 		go wait.Until(c.runUpdatePodSecurityWorker, time.Second, stopCh)
@@ -860,6 +1065,10 @@ func (c *Controller) startWorkers(stopCh <-chan struct{}) {
 		}
 	}, time.Duration(c.config.InspectInterval)*time.Second, stopCh)
 
+	if c.config.EnableIPAMCheckpoint {
+		go wait.Until(c.saveIPAMCheckpoint, time.Duration(c.config.IPAMCheckpointInterval)*time.Second, stopCh)
+	}
+
 	if c.config.EnableExternalVpc {
 		go wait.Until(func() {
 			c.syncExternalVpc()
@@ -897,6 +1106,18 @@ func (c *Controller) startWorkers(stopCh <-chan struct{}) {
 	go wait.Until(c.runUpdateIptablesSnatRuleWorker, time.Second, stopCh)
 	go wait.Until(c.runDelIptablesSnatRuleWorker, time.Second, stopCh)
 
+	go wait.Until(c.runAddOrUpdateOvnEipWorker, time.Second, stopCh)
+	go wait.Until(c.runDelOvnEipWorker, time.Second, stopCh)
+
+	go wait.Until(c.runAddOrUpdateOvnFipWorker, time.Second, stopCh)
+	go wait.Until(c.runDelOvnFipWorker, time.Second, stopCh)
+
+	go wait.Until(c.runAddOrUpdateOvnSnatRuleWorker, time.Second, stopCh)
+	go wait.Until(c.runDelOvnSnatRuleWorker, time.Second, stopCh)
+
+	go wait.Until(c.runAddOrUpdateOvnDnatRuleWorker, time.Second, stopCh)
+	go wait.Until(c.runDelOvnDnatRuleWorker, time.Second, stopCh)
+
 	if c.config.PodDefaultFipType == util.IptablesFip {
 		go wait.Until(c.runAddPodAnnotatedIptablesEipWorker, time.Second, stopCh)
 		go wait.Until(c.runDelPodAnnotatedIptablesEipWorker, time.Second, stopCh)
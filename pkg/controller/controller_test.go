@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	kubescheme "k8s.io/client-go/kubernetes/scheme"
+
+	kubeovnfake "github.com/kubeovn/kube-ovn/pkg/client/clientset/versioned/fake"
+	kubeovnscheme "github.com/kubeovn/kube-ovn/pkg/client/clientset/versioned/scheme"
+	ovsfake "github.com/kubeovn/kube-ovn/pkg/ovs/fake"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+type fakeController struct {
+	*Controller
+	kubeClient    *kubefake.Clientset
+	kubeovnClient *kubeovnfake.Clientset
+	legacyClient  *ovsfake.LegacyClient
+}
+
+// newFakeController returns a controller backed by fake clientsets and an in-memory OVN NB database,
+// kubeObjects and kubeovnObjects are added to both the fake clientsets and the informer caches
+func newFakeController(t *testing.T, kubeObjects, kubeovnObjects []runtime.Object) *fakeController {
+	t.Helper()
+
+	nbServer, err := ovsfake.NewOvnNbServer()
+	require.NoError(t, err)
+	t.Cleanup(nbServer.Close)
+
+	kubeClient := kubefake.NewSimpleClientset(kubeObjects...)
+	kubeovnClient := kubeovnfake.NewSimpleClientset(kubeovnObjects...)
+	config := &Configuration{
+		KubeClient:           kubeClient,
+		KubeOvnClient:        kubeovnClient,
+		KubeFactoryClient:    kubeClient,
+		KubeOvnFactoryClient: kubeovnClient,
+		OvnNbAddr:            nbServer.Addr,
+		OvnTimeout:           5,
+		DefaultLogicalSwitch: util.DefaultSubnet,
+		ClusterRouter:        util.DefaultVpc,
+		NodeSwitch:           "join",
+		PodNamespace:         "kube-system",
+		EnableNP:             true,
+		EnableLb:             true,
+	}
+
+	c := NewController(config)
+	legacyClient, err := ovsfake.NewLegacyClient(nbServer.Addr, config.OvnTimeout)
+	require.NoError(t, err)
+	c.ovnLegacyClient = legacyClient
+
+	// informers are not started, so the objects are added to the caches directly
+	// and no event handler is triggered
+	for _, obj := range kubeObjects {
+		informer, err := c.informerFactory.ForResource(gvrOf(t, obj, kubescheme.Scheme))
+		require.NoError(t, err)
+		require.NoError(t, informer.Informer().GetIndexer().Add(obj))
+	}
+	for _, obj := range kubeovnObjects {
+		informer, err := c.kubeovnInformerFactory.ForResource(gvrOf(t, obj, kubeovnscheme.Scheme))
+		require.NoError(t, err)
+		require.NoError(t, informer.Informer().GetIndexer().Add(obj))
+	}
+
+	return &fakeController{
+		Controller:    c,
+		kubeClient:    kubeClient,
+		kubeovnClient: kubeovnClient,
+		legacyClient:  legacyClient,
+	}
+}
+
+// becomeLeader elects the controller as the leader with the fake clientset, so that the event handlers enqueue objects
+func becomeLeader(t *testing.T, ctrl *fakeController) {
+	t.Helper()
+	ctrl.elector = setupLeaderElection(&leaderElectionConfig{
+		Client:       ctrl.kubeClient,
+		ElectionID:   "kube-ovn-controller",
+		PodName:      "kube-ovn-controller",
+		PodNamespace: ctrl.config.PodNamespace,
+	})
+	require.Eventually(t, ctrl.isLeader, 10*time.Second, 100*time.Millisecond)
+}
+
+func gvrOf(t *testing.T, obj runtime.Object, scheme *runtime.Scheme) schema.GroupVersionResource {
+	t.Helper()
+	gvks, _, err := scheme.ObjectKinds(obj)
+	require.NoError(t, err)
+	gvr, _ := meta.UnsafeGuessKindToResource(gvks[0])
+	return gvr
+}
@@ -0,0 +1,490 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// endpointSliceServiceKey returns the key of the service the endpoint slice belongs to,
+// the slices mirrored from the endpoints of services without selectors are labeled as well
+func endpointSliceServiceKey(slice *discoveryv1.EndpointSlice) string {
+	name := slice.Labels[discoveryv1.LabelServiceName]
+	if name == "" {
+		return ""
+	}
+	return slice.Namespace + "/" + name
+}
+
+func (c *Controller) enqueueAddEndpointSlice(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	key := endpointSliceServiceKey(obj.(*discoveryv1.EndpointSlice))
+	if key == "" {
+		return
+	}
+	klog.V(3).Infof("enqueue add endpoint slice of service %s", key)
+	c.updateEndpointQueue.Add(key)
+}
+
+func (c *Controller) enqueueUpdateEndpointSlice(old, new interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	oldSlice := old.(*discoveryv1.EndpointSlice)
+	newSlice := new.(*discoveryv1.EndpointSlice)
+	if oldSlice.ResourceVersion == newSlice.ResourceVersion {
+		return
+	}
+
+	if len(oldSlice.Endpoints) == 0 && len(newSlice.Endpoints) == 0 {
+		return
+	}
+
+	oldKey, newKey := endpointSliceServiceKey(oldSlice), endpointSliceServiceKey(newSlice)
+	if oldKey != "" && oldKey != newKey {
+		klog.V(3).Infof("enqueue update endpoint slice of service %s", oldKey)
+		c.updateEndpointQueue.Add(oldKey)
+	}
+	if newKey != "" {
+		klog.V(3).Infof("enqueue update endpoint slice of service %s", newKey)
+		c.updateEndpointQueue.Add(newKey)
+	}
+}
+
+func (c *Controller) enqueueDeleteEndpointSlice(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var slice *discoveryv1.EndpointSlice
+	switch t := obj.(type) {
+	case *discoveryv1.EndpointSlice:
+		slice = t
+	case cache.DeletedFinalStateUnknown:
+		s, ok := t.Obj.(*discoveryv1.EndpointSlice)
+		if !ok {
+			klog.Warningf("unexpected object type: %T", t.Obj)
+			return
+		}
+		slice = s
+	default:
+		klog.Warningf("unexpected type: %T", obj)
+		return
+	}
+	key := endpointSliceServiceKey(slice)
+	if key == "" {
+		return
+	}
+	klog.V(3).Infof("enqueue delete endpoint slice of service %s", key)
+	c.updateEndpointQueue.Add(key)
+}
+
+func (c *Controller) runUpdateEndpointWorker() {
+	for c.processNextUpdateEndpointWorkItem() {
+	}
+}
+
+func (c *Controller) processNextUpdateEndpointWorkItem() bool {
+	obj, shutdown := c.updateEndpointQueue.Get()
+
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.updateEndpointQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.updateEndpointQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleUpdateEndpoint(key); err != nil {
+			c.updateEndpointQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.updateEndpointQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+func (c *Controller) handleUpdateEndpoint(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return nil
+	}
+	klog.Infof("update endpoint %s/%s", namespace, name)
+
+	cachedService, err := c.servicesLister.Services(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	svc := cachedService.DeepCopy()
+
+	var LbIPs []string
+	if vip, ok := svc.Annotations[util.SwitchLBRuleVipsAnnotation]; ok {
+		LbIPs = []string{vip}
+	} else {
+		LbIPs = svc.Spec.ClusterIPs
+		if len(LbIPs) == 0 && svc.Spec.ClusterIP != "" && svc.Spec.ClusterIP != v1.ClusterIPNone {
+			LbIPs = []string{svc.Spec.ClusterIP}
+		}
+		if len(LbIPs) == 0 || LbIPs[0] == v1.ClusterIPNone {
+			return nil
+		}
+	}
+	// the ingress ips are not affected by the internal traffic policy
+	var ingressIPs []string
+	if isLbIpamService(svc) {
+		ingressIPs = lbIpamIPs(svc)
+		LbIPs = append(LbIPs, ingressIPs...)
+	}
+
+	// the endpoints of a large service are split into multiple slices
+	slices, err := c.endpointSlicesLister.EndpointSlices(namespace).List(labels.Set{discoveryv1.LabelServiceName: name}.AsSelector())
+	if err != nil {
+		klog.Errorf("failed to get endpoint slices for service %s in namespace %s: %v", name, namespace, err)
+		return err
+	}
+
+	var vpcName string
+	for _, slice := range slices {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.TargetRef == nil || endpoint.TargetRef.Kind != "Pod" {
+				continue
+			}
+			pod, err := c.podsLister.Pods(namespace).Get(endpoint.TargetRef.Name)
+			if err != nil {
+				continue
+			}
+			if vpcName = pod.Annotations[util.LogicalRouterAnnotation]; vpcName != "" {
+				break
+			}
+		}
+		if vpcName != "" {
+			break
+		}
+	}
+
+	if vpcName == "" {
+		if vpcName = svc.Annotations[util.VpcAnnotation]; vpcName == "" {
+			vpcName = util.DefaultVpc
+		}
+	}
+
+	vpc, err := c.vpcsLister.Get(vpcName)
+	if err != nil {
+		klog.Errorf("failed to get vpc %s of lb, %v", vpcName, err)
+		return err
+	}
+
+	if svcVpc := svc.Annotations[util.VpcAnnotation]; svcVpc != vpcName {
+		if svc.Annotations == nil {
+			svc.Annotations = make(map[string]string, 1)
+		}
+		svc.Annotations[util.VpcAnnotation] = vpcName
+		if _, err = c.config.KubeClient.CoreV1().Services(namespace).Update(context.Background(), svc, metav1.UpdateOptions{}); err != nil {
+			klog.Errorf("failed to update service %s/%s: %v", namespace, svc.Name, err)
+			return err
+		}
+	}
+
+	if err = validateServiceLbHash(svc.Annotations[util.ServiceLbHashAnnotation]); err != nil {
+		klog.Errorf("invalid load balancer hash of service %s/%s, %v", namespace, name, err)
+		c.recorder.Eventf(svc, v1.EventTypeWarning, "InvalidLoadBalancerHash", err.Error())
+	}
+	tcpServiceLb, udpServiceLb := getServiceLoadBalancers(svc, vpc, c.ovnClient.LoadBalancerAffinityTimeoutSupported())
+	for _, lb := range []*serviceLoadBalancer{tcpServiceLb, udpServiceLb} {
+		if err = c.ensureServiceLoadBalancer(vpc, lb); err != nil {
+			return err
+		}
+	}
+
+	healthCheck, err := parseServiceHealthCheck(svc.Annotations[util.ServiceHealthCheckAnnotation])
+	if err != nil {
+		klog.Errorf("invalid health check of service %s/%s, %v", namespace, name, err)
+		c.recorder.Eventf(svc, v1.EventTypeWarning, "InvalidHealthCheck", err.Error())
+	}
+	// the vips are updated even if the health checks are not ready
+	var mappings map[string]string
+	var healthCheckErr error
+	if healthCheck != nil {
+		if mappings, healthCheckErr = c.getHealthCheckIPPortMappings(namespace, slices); healthCheckErr != nil {
+			klog.Errorf("failed to get ip port mappings of service %s/%s, %v", namespace, name, healthCheckErr)
+		}
+	}
+
+	// the ingress ips of the external subnet are reachable through the distributed gateway port of the vpc
+	var externalIPs []string
+	if vpc.Spec.EnableExternal {
+		externalIPs = c.lbIpamExternalIPs(svc)
+	}
+
+	localTraffic := c.serviceTrafficLocal(svc, vpcName)
+	templateSupported := c.ovnClient.ChassisTemplateVarSupported()
+	zoneScoped, nodeScoped := topologyAwareHintsEnabled(svc), internalTrafficLocal(svc) && templateSupported
+	if zoneScoped && !templateSupported {
+		klog.Warningf("ignore topology aware hints of service %s/%s, the running ovn does not support chassis template variables", namespace, name)
+		c.recorder.Eventf(svc, v1.EventTypeWarning, "TopologyAwareHintsIgnored",
+			"the running ovn does not support chassis template variables, all endpoints are used")
+		zoneScoped = false
+	}
+	var nodes []*v1.Node
+	if zoneScoped || nodeScoped {
+		if nodes, err = c.nodesLister.List(labels.Everything()); err != nil {
+			klog.Errorf("failed to list nodes, %v", err)
+			return err
+		}
+	}
+	// the external traffic enters the vpc through the distributed gateway port on the external gateway nodes
+	var gatewayNodes []string
+	if len(externalIPs) != 0 && externalTrafficLocal(svc) {
+		if gatewayNodes, err = c.getExternalGatewayNodes(); err != nil {
+			return err
+		}
+	}
+
+	for _, settingIP := range LbIPs {
+		for _, port := range svc.Spec.Ports {
+			vip := util.JoinHostPort(settingIP, port.Port)
+			isIngressIP := util.ContainsString(ingressIPs, settingIP)
+			var endpoints []serviceBackend
+			if !localTraffic || isIngressIP {
+				endpoints = getServicePortEndpoints(slices, port, settingIP)
+			}
+			backends := joinServiceBackends(endpoints)
+
+			// the ingress ips are not affected by the internal traffic policy
+			var chassisBackends map[string]string
+			switch {
+			case nodeScoped && !isIngressIP:
+				chassisBackends = chassisNodeBackends(nodes, endpoints)
+			case zoneScoped:
+				chassisBackends = chassisZoneBackends(nodes, endpoints)
+			}
+
+			serviceLb := tcpServiceLb
+			if port.Protocol != v1.ProtocolTCP {
+				serviceLb = udpServiceLb
+			}
+			lb, templateLb := serviceLb.name, templateLoadBalancer(serviceLb, vipAddressFamily(settingIP))
+			// the vip is put on the template load balancer if the backends are selected per node,
+			// and deleted from the load balancers if there are no backends for performance reason
+			if chassisBackends != nil && len(backends) != 0 {
+				if err = c.addTemplateVip(vpc, templateLb, vip, port.Protocol, chassisBackends); err != nil {
+					return err
+				}
+				if err = c.ovnClient.LoadBalancerDeleteVip(lb, vip); err != nil {
+					klog.Errorf("failed to delete vip %s from lb %s, %v", vip, lb, err)
+					return err
+				}
+			} else {
+				if len(backends) != 0 {
+					if err = c.ovnClient.LoadBalancerAddVip(lb, vip, backends); err != nil {
+						klog.Errorf("failed to update vip %s to lb %s, %v", vip, lb, err)
+						return err
+					}
+				} else {
+					if err = c.ovnClient.LoadBalancerDeleteVip(lb, vip); err != nil {
+						klog.Errorf("failed to delete vip %s from lb %s, %v", vip, lb, err)
+						return err
+					}
+				}
+				if templateSupported {
+					if err = c.deleteTemplateVip(templateLb.name, vip, port.Protocol); err != nil {
+						return err
+					}
+				}
+			}
+
+			if util.ContainsString(externalIPs, settingIP) {
+				externalBackends := backends
+				if gatewayNodes != nil {
+					externalBackends = joinServiceBackends(filterNodeBackends(endpoints, gatewayNodes))
+				}
+				if err = c.syncLbIpamRouterVip(vpcName, port.Protocol, vip, externalBackends); err != nil {
+					return err
+				}
+			}
+
+			// the health check is deleted together with the vip without backends,
+			// and not supported by the template load balancers
+			if len(backends) == 0 || chassisBackends != nil || healthCheckErr != nil {
+				continue
+			}
+			if err = c.syncVipHealthCheck(lb, vip, healthCheck, mappings); err != nil {
+				return err
+			}
+		}
+	}
+
+	return healthCheckErr
+}
+
+// syncVipHealthCheck adds the health check of the vip with the ip port mappings of its backends,
+// or deletes the health check if options is nil
+func (c *Controller) syncVipHealthCheck(lb, vip string, options, mappings map[string]string) error {
+	if options == nil {
+		if err := c.ovnClient.LoadBalancerDeleteHealthCheck(lb, vip); err != nil {
+			klog.Errorf("failed to delete health check of vip %s at lb %s, %v", vip, lb, err)
+			return err
+		}
+		return nil
+	}
+
+	if err := c.ovnClient.LoadBalancerAddHealthCheck(lb, vip, options); err != nil {
+		klog.Errorf("failed to add health check of vip %s to lb %s, %v", vip, lb, err)
+		return err
+	}
+	if err := c.ovnClient.LoadBalancerUpdateIPPortMappings(lb, mappings); err != nil {
+		klog.Errorf("failed to update ip port mappings of lb %s, %v", lb, err)
+		return err
+	}
+	return nil
+}
+
+// serviceTrafficLocal returns whether the vips of the service with the local internal traffic policy are left to
+// kube-proxy. The vips are put on the template load balancers selecting the endpoints on the node of the client if
+// the running ovn supports the chassis template variables. Otherwise they are not added to the load balancers, and
+// the traffic to them leaves the default vpc through the gateway on the node of the client, where kube-proxy applies
+// the policy.
+func (c *Controller) serviceTrafficLocal(svc *v1.Service, vpcName string) bool {
+	if !internalTrafficLocal(svc) || c.ovnClient.ChassisTemplateVarSupported() {
+		return false
+	}
+	if vpcName != util.DefaultVpc {
+		c.recorder.Eventf(svc, v1.EventTypeWarning, "InternalTrafficPolicyIgnored",
+			"internal traffic policy %s is not supported in vpc %s as the running ovn does not support chassis template variables",
+			v1.ServiceInternalTrafficPolicyLocal, vpcName)
+		return false
+	}
+	return true
+}
+
+type serviceBackend struct {
+	address string
+	node    string
+	zones   []string
+}
+
+// getServicePortEndpoints returns the endpoints of the service port in the address family of the service ip.
+// The ready endpoints are used, or the serving ones which are terminating if none is ready so that the
+// connections are not dropped during rolling updates.
+func getServicePortEndpoints(slices []*discoveryv1.EndpointSlice, servicePort v1.ServicePort, serviceIP string) []serviceBackend {
+	addressType := discoveryv1.AddressTypeIPv4
+	if util.CheckProtocol(serviceIP) == kubeovnv1.ProtocolIPv6 {
+		addressType = discoveryv1.AddressTypeIPv6
+	}
+
+	var ready, terminating []serviceBackend
+	seen := make(map[string]bool)
+	for _, slice := range slices {
+		if slice.AddressType != addressType {
+			continue
+		}
+
+		var targetPort int32
+		for _, port := range slice.Ports {
+			if port.Port == nil || (port.Protocol != nil && *port.Protocol != servicePort.Protocol) {
+				continue
+			}
+			if (port.Name == nil && servicePort.Name == "") || (port.Name != nil && *port.Name == servicePort.Name) {
+				targetPort = *port.Port
+				break
+			}
+		}
+		if targetPort == 0 {
+			continue
+		}
+
+		for _, endpoint := range slice.Endpoints {
+			if len(endpoint.Addresses) == 0 {
+				continue
+			}
+			// an endpoint may exist in two slices for a while when it is moved between slices
+			address := util.JoinHostPort(endpoint.Addresses[0], targetPort)
+			if seen[address] {
+				continue
+			}
+			seen[address] = true
+
+			backend := serviceBackend{address: address}
+			if endpoint.NodeName != nil {
+				backend.node = *endpoint.NodeName
+			}
+			if endpoint.Hints != nil {
+				backend.zones = make([]string, 0, len(endpoint.Hints.ForZones))
+				for _, z := range endpoint.Hints.ForZones {
+					backend.zones = append(backend.zones, z.Name)
+				}
+			}
+			conditions := endpoint.Conditions
+			if conditions.Ready == nil || *conditions.Ready {
+				ready = append(ready, backend)
+			} else if conditions.Serving != nil && *conditions.Serving && conditions.Terminating != nil && *conditions.Terminating {
+				terminating = append(terminating, backend)
+			}
+		}
+	}
+
+	if len(ready) == 0 {
+		return terminating
+	}
+	return ready
+}
+
+// joinServiceBackends returns the sorted addresses of the backends in the vips of the load balancers
+func joinServiceBackends(backends []serviceBackend) string {
+	addresses := make([]string, 0, len(backends))
+	for _, backend := range backends {
+		addresses = append(addresses, backend.address)
+	}
+	sort.Strings(addresses)
+	return strings.Join(addresses, ",")
+}
+
+// filterZoneBackends returns the backends hinted for the zone, all backends are returned
+// if any backend has no hints or none is hinted for the zone as kube-proxy does
+func filterZoneBackends(backends []serviceBackend, zone string) []serviceBackend {
+	if zone == "" {
+		return backends
+	}
+	var filtered []serviceBackend
+	for _, backend := range backends {
+		if backend.zones == nil {
+			return backends
+		}
+		if util.ContainsString(backend.zones, zone) {
+			filtered = append(filtered, backend)
+		}
+	}
+	if len(filtered) == 0 {
+		return backends
+	}
+	return filtered
+}
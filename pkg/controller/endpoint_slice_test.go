@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func newEndpointSlice(name string, addressType discoveryv1.AddressType, port int32, endpoints ...discoveryv1.Endpoint) *discoveryv1.EndpointSlice {
+	portName, protocol := "http", corev1.ProtocolTCP
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "web"},
+		},
+		AddressType: addressType,
+		Endpoints:   endpoints,
+		Ports:       []discoveryv1.EndpointPort{{Name: &portName, Protocol: &protocol, Port: &port}},
+	}
+}
+
+func newEndpoint(address string, ready, serving, terminating bool, zones ...string) discoveryv1.Endpoint {
+	endpoint := discoveryv1.Endpoint{
+		Addresses: []string{address},
+		Conditions: discoveryv1.EndpointConditions{
+			Ready:       &ready,
+			Serving:     &serving,
+			Terminating: &terminating,
+		},
+	}
+	if len(zones) != 0 {
+		endpoint.Hints = &discoveryv1.EndpointHints{}
+		for _, zone := range zones {
+			endpoint.Hints.ForZones = append(endpoint.Hints.ForZones, discoveryv1.ForZone{Name: zone})
+		}
+	}
+	return endpoint
+}
+
+func Test_getServicePortEndpoints(t *testing.T) {
+	port := corev1.ServicePort{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}
+	slices := []*discoveryv1.EndpointSlice{
+		newEndpointSlice("web-1", discoveryv1.AddressTypeIPv4, 8080,
+			newEndpoint("10.16.0.3", true, true, false, "zone-a"),
+			newEndpoint("10.16.0.4", false, true, true, "zone-b"),
+		),
+		newEndpointSlice("web-2", discoveryv1.AddressTypeIPv4, 8080,
+			newEndpoint("10.16.0.2", true, true, false, "zone-b"),
+			newEndpoint("10.16.0.3", true, true, false, "zone-a"),
+			newEndpoint("10.16.0.5", false, false, false, "zone-b"),
+		),
+		newEndpointSlice("web-3", discoveryv1.AddressTypeIPv6, 8080,
+			newEndpoint("fd00:10:16::2", true, true, false),
+		),
+	}
+
+	// the endpoints are merged from the slices of the address family
+	v4Endpoints := getServicePortEndpoints(slices, port, "10.96.0.10")
+	v6Endpoints := getServicePortEndpoints(slices, port, "fd00:10:96::10")
+	require.Equal(t, "10.16.0.2:8080,10.16.0.3:8080", joinServiceBackends(v4Endpoints))
+	require.Equal(t, "[fd00:10:16::2]:8080", joinServiceBackends(v6Endpoints))
+	require.Empty(t, getServicePortEndpoints(slices, corev1.ServicePort{Name: "https", Protocol: corev1.ProtocolTCP, Port: 443}, "10.96.0.10"))
+
+	// the backends are limited to the zone only when hinted
+	require.Equal(t, "10.16.0.2:8080", joinServiceBackends(filterZoneBackends(v4Endpoints, "zone-b")))
+	require.Equal(t, "10.16.0.2:8080,10.16.0.3:8080", joinServiceBackends(filterZoneBackends(v4Endpoints, "zone-c")))
+	require.Equal(t, "[fd00:10:16::2]:8080", joinServiceBackends(filterZoneBackends(v6Endpoints, "zone-a")))
+
+	// the serving terminating endpoints are used if none is ready
+	ready := false
+	for _, slice := range slices[:2] {
+		for i := range slice.Endpoints {
+			slice.Endpoints[i].Conditions.Ready = &ready
+		}
+	}
+	require.Equal(t, "10.16.0.4:8080", joinServiceBackends(getServicePortEndpoints(slices, port, "10.96.0.10")))
+}
+
+func Test_handleUpdateEndpoint(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "default",
+			Annotations: map[string]string{util.VpcAnnotation: util.DefaultVpc},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP:  "10.96.0.10",
+			ClusterIPs: []string{"10.96.0.10"},
+			Ports:      []corev1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}},
+		},
+	}
+	vpc := &kubeovnv1.Vpc{
+		ObjectMeta: metav1.ObjectMeta{Name: util.DefaultVpc},
+		Status: kubeovnv1.VpcStatus{
+			TcpLoadBalancer: "cluster-tcp-loadbalancer",
+			UdpLoadBalancer: "cluster-udp-loadbalancer",
+		},
+	}
+	slice1 := newEndpointSlice("web-1", discoveryv1.AddressTypeIPv4, 8080, newEndpoint("10.16.0.3", true, true, false))
+	slice2 := newEndpointSlice("web-2", discoveryv1.AddressTypeIPv4, 8080, newEndpoint("10.16.0.2", true, true, false))
+	ctrl := newFakeController(t, []runtime.Object{svc, slice1, slice2}, []runtime.Object{vpc})
+	require.NoError(t, ctrl.ovnClient.CreateLoadBalancer(vpc.Status.TcpLoadBalancer, "tcp", ""))
+	require.NoError(t, ctrl.ovnClient.CreateLoadBalancer(vpc.Status.UdpLoadBalancer, "udp", ""))
+
+	require.NoError(t, ctrl.handleUpdateEndpoint("default/web"))
+	vips, err := ctrl.ovnClient.GetLoadBalancerVips(vpc.Status.TcpLoadBalancer)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"10.96.0.10:80": "10.16.0.2:8080,10.16.0.3:8080"}, vips)
+
+	// the vip is removed when all slices are deleted
+	sliceIndexer := ctrl.informerFactory.Discovery().V1().EndpointSlices().Informer().GetIndexer()
+	require.NoError(t, sliceIndexer.Delete(slice1))
+	require.NoError(t, sliceIndexer.Delete(slice2))
+	require.NoError(t, ctrl.handleUpdateEndpoint("default/web"))
+	vips, err = ctrl.ovnClient.GetLoadBalancerVips(vpc.Status.TcpLoadBalancer)
+	require.NoError(t, err)
+	require.Empty(t, vips)
+}
+
+func Test_handleUpdateEndpointLocalTraffic(t *testing.T) {
+	local := corev1.ServiceInternalTrafficPolicyLocal
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "default",
+			Annotations: map[string]string{util.VpcAnnotation: util.DefaultVpc},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP:             "10.96.0.10",
+			ClusterIPs:            []string{"10.96.0.10"},
+			Ports:                 []corev1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}},
+			InternalTrafficPolicy: &local,
+		},
+	}
+	vpc := &kubeovnv1.Vpc{
+		ObjectMeta: metav1.ObjectMeta{Name: util.DefaultVpc},
+		Status: kubeovnv1.VpcStatus{
+			TcpLoadBalancer: "cluster-tcp-loadbalancer",
+			UdpLoadBalancer: "cluster-udp-loadbalancer",
+		},
+	}
+	slice := newEndpointSlice("web-1", discoveryv1.AddressTypeIPv4, 8080, newEndpoint("10.16.0.2", true, true, false))
+	ctrl := newFakeController(t, []runtime.Object{svc, slice}, []runtime.Object{vpc})
+	require.NoError(t, ctrl.ovnClient.CreateLoadBalancer(vpc.Status.TcpLoadBalancer, "tcp", ""))
+	require.NoError(t, ctrl.ovnClient.CreateLoadBalancer(vpc.Status.UdpLoadBalancer, "udp", ""))
+	require.NoError(t, ctrl.ovnClient.LoadBalancerAddVip(vpc.Status.TcpLoadBalancer, "10.96.0.10:80", "10.16.0.2:8080"))
+
+	// the vip is removed from the load balancer for kube-proxy to route the traffic to the local endpoints
+	require.NoError(t, ctrl.handleUpdateEndpoint("default/web"))
+	vips, err := ctrl.ovnClient.GetLoadBalancerVips(vpc.Status.TcpLoadBalancer)
+	require.NoError(t, err)
+	require.Empty(t, vips)
+
+	// the policy is ignored in custom vpcs
+	customVpc := &kubeovnv1.Vpc{
+		ObjectMeta: metav1.ObjectMeta{Name: "vpc1"},
+		Status: kubeovnv1.VpcStatus{
+			TcpLoadBalancer: "vpc-vpc1-tcp-load",
+			UdpLoadBalancer: "vpc-vpc1-udp-load",
+		},
+	}
+	require.NoError(t, ctrl.kubeovnInformerFactory.Kubeovn().V1().Vpcs().Informer().GetIndexer().Add(customVpc))
+	require.NoError(t, ctrl.ovnClient.CreateLoadBalancer(customVpc.Status.TcpLoadBalancer, "tcp", ""))
+	svc = svc.DeepCopy()
+	svc.Annotations[util.VpcAnnotation] = customVpc.Name
+	require.NoError(t, ctrl.informerFactory.Core().V1().Services().Informer().GetIndexer().Update(svc))
+	require.NoError(t, ctrl.handleUpdateEndpoint("default/web"))
+	vips, err = ctrl.ovnClient.GetLoadBalancerVips(customVpc.Status.TcpLoadBalancer)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"10.96.0.10:80": "10.16.0.2:8080"}, vips)
+}
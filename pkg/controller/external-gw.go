@@ -13,6 +13,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
 	"github.com/kubeovn/kube-ovn/pkg/util"
 )
 
@@ -60,7 +61,7 @@ func (c *Controller) resyncExternalGateway() {
 		}
 		exGwEnabled = "true"
 		lastExGwCM = cm.Data
-		c.ovnLegacyClient.ExternalGatewayType = cm.Data["type"]
+		c.externalGatewayType = cm.Data["type"]
 		klog.Info("finish establishing ovn external gw")
 	}
 }
@@ -156,10 +157,15 @@ func (c *Controller) establishExternalGateway(config map[string]string) error {
 		return fmt.Errorf("no available external gw")
 	}
 
-	if err := c.ovnLegacyClient.CreateGatewaySwitch(util.ExternalGatewaySwitch, c.config.ExternalGatewayNet, c.config.ExternalGatewayVlanID, config["nic-ip"], config["nic-mac"], chassises); err != nil {
+	if err := c.ovnLegacyClient.CreateGatewaySwitch(util.ExternalGatewaySwitch, c.config.ExternalGatewayNet, c.config.ExternalGatewayVlanID, config["nic-ip"], config["nic-mac"]); err != nil {
 		klog.Errorf("failed to create external gateway switch, %v", err)
 		return err
 	}
+	lrpName := ovs.LogicalRouterPortName(c.config.ClusterRouter, util.ExternalGatewaySwitch)
+	if err := c.ovnClient.SetGatewayChassises(lrpName, chassises); err != nil {
+		klog.Errorf("failed to set gateway chassises of router port %s, %v", lrpName, err)
+		return err
+	}
 
 	return nil
 }
@@ -2,13 +2,13 @@ package controller
 
 import (
 	"context"
-	"fmt"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/klog/v2"
 
 	v1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
 	"github.com/kubeovn/kube-ovn/pkg/util"
 )
 
@@ -83,7 +83,9 @@ func (c *Controller) syncExternalVpc() {
 
 func (c *Controller) getRouterStatus() (logicalRouters map[string]util.LogicalRouter, err error) {
 	logicalRouters = make(map[string]util.LogicalRouter)
-	externalOvnRouters, err := c.ovnLegacyClient.CustomFindEntity("logical_router", []string{"name", "port"}, fmt.Sprintf("external_ids{!=}vendor=%s", util.CniTypeName))
+	externalOvnRouters, err := c.ovnClient.ListLogicalRoutersWithFilter(func(lr *ovnnb.LogicalRouter) bool {
+		return lr.ExternalIDs["vendor"] != util.CniTypeName
+	})
 	if err != nil {
 		klog.Errorf("failed to list external logical router, %v", err)
 		return logicalRouters, err
@@ -95,16 +97,23 @@ func (c *Controller) getRouterStatus() (logicalRouters map[string]util.LogicalRo
 
 	for _, aExternalRouter := range externalOvnRouters {
 		var aLogicalRouter util.LogicalRouter
-		aLogicalRouter.Name = aExternalRouter["name"][0]
+		aLogicalRouter.Name = aExternalRouter.Name
+		portUUIDs := make(map[string]struct{}, len(aExternalRouter.Ports))
+		for _, portUUID := range aExternalRouter.Ports {
+			portUUIDs[portUUID] = struct{}{}
+		}
+		lrps, err := c.ovnClient.ListLogicalRouterPortsWithFilter(func(lrp *ovnnb.LogicalRouterPort) bool {
+			_, ok := portUUIDs[lrp.UUID]
+			return ok
+		})
+		if err != nil {
+			klog.Errorf("failed to list ports of logical router %s, %v", aExternalRouter.Name, err)
+			continue
+		}
 		var ports []util.Port
-		for _, portUUId := range aExternalRouter["port"] {
-			portName, err := c.ovnLegacyClient.GetEntityInfo("logical_router_port", portUUId, []string{"name"})
-			if err != nil {
-				klog.Info("get port error")
-				continue
-			}
+		for _, lrp := range lrps {
 			aPort := util.Port{
-				Name:   portName["name"],
+				Name:   lrp.Name,
 				Subnet: "",
 			}
 			ports = append(ports, aPort)
@@ -112,11 +121,12 @@ func (c *Controller) getRouterStatus() (logicalRouters map[string]util.LogicalRo
 		aLogicalRouter.Ports = ports
 		logicalRouters[aLogicalRouter.Name] = aLogicalRouter
 	}
-	UUID := "_uuid"
 	for routerName, logicalRouter := range logicalRouters {
 		tmpRouter := logicalRouter
 		for _, port := range logicalRouter.Ports {
-			peerPorts, err := c.ovnLegacyClient.CustomFindEntity("logical_switch_port", []string{UUID}, fmt.Sprintf("options:router-port=%s", port.Name))
+			peerPorts, err := c.ovnClient.ListLogicalSwitchPortsWithFilter(func(lsp *ovnnb.LogicalSwitchPort) bool {
+				return lsp.Options["router-port"] == port.Name
+			})
 			if err != nil || len(peerPorts) > 1 {
 				klog.Errorf("failed to list peer port of %s, %v", port, err)
 				continue
@@ -124,13 +134,16 @@ func (c *Controller) getRouterStatus() (logicalRouters map[string]util.LogicalRo
 			if len(peerPorts) == 0 {
 				continue
 			}
-			switches, err := c.ovnLegacyClient.CustomFindEntity("logical_switch", []string{"name"}, fmt.Sprintf("ports{>=}%s", peerPorts[0][UUID][0]))
-			if err != nil || len(switches) > 1 {
-				klog.Errorf("failed to list peer switch of %s, %v", peerPorts, err)
+			peerPortUUID := peerPorts[0].UUID
+			switches, err := c.ovnClient.ListLogicalSwitchesWithFilter(func(ls *ovnnb.LogicalSwitch) bool {
+				return util.ContainsString(ls.Ports, peerPortUUID)
+			})
+			if err != nil || len(switches) != 1 {
+				klog.Errorf("failed to list peer switch of %s, %v", peerPorts[0].Name, err)
 				continue
 			}
 			var aLogicalSwitch util.LogicalSwitch
-			aLogicalSwitch.Name = switches[0]["name"][0]
+			aLogicalSwitch.Name = switches[0].Name
 			tmpRouter.LogicalSwitches = append(tmpRouter.LogicalSwitches, aLogicalSwitch)
 		}
 		logicalRouters[routerName] = tmpRouter
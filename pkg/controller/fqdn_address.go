@@ -0,0 +1,239 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	netv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func (c *Controller) enqueueAddFqdnAddress(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	key := obj.(*kubeovnv1.FqdnAddress).Name
+	klog.V(3).Infof("enqueue add fqdn address %s", key)
+	c.syncFqdnAddressQueue.Add(key)
+}
+
+func (c *Controller) enqueueUpdateFqdnAddress(old, new interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	oldFqdn := old.(*kubeovnv1.FqdnAddress)
+	newFqdn := new.(*kubeovnv1.FqdnAddress)
+	if !reflect.DeepEqual(oldFqdn.Status, newFqdn.Status) {
+		klog.V(3).Infof("enqueue update fqdn address %s", newFqdn.Name)
+		c.syncFqdnAddressQueue.Add(newFqdn.Name)
+	}
+}
+
+func (c *Controller) enqueueDeleteFqdnAddress(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	klog.V(3).Infof("enqueue delete fqdn address %s", key)
+	c.syncFqdnAddressQueue.Add(key)
+}
+
+// enqueueSyncFqdns syncs the domain names when security groups or network policies referring to them are changed
+func (c *Controller) enqueueSyncFqdns(fqdns []string) {
+	for _, fqdn := range fqdns {
+		c.syncFqdnAddressQueue.Add(fqdn)
+	}
+}
+
+func (c *Controller) runSyncFqdnAddressWorker() {
+	for c.processNextSyncFqdnAddressWorkItem() {
+	}
+}
+
+func (c *Controller) processNextSyncFqdnAddressWorkItem() bool {
+	obj, shutdown := c.syncFqdnAddressQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.syncFqdnAddressQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.syncFqdnAddressQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleSyncFqdnAddress(key); err != nil {
+			c.syncFqdnAddressQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.syncFqdnAddressQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+// sgFqdns returns the domain names referred by the rules of the security group
+func sgFqdns(sg *kubeovnv1.SecurityGroup) []string {
+	var fqdns []string
+	for _, rules := range [][]*kubeovnv1.SgRule{sg.Spec.IngressRules, sg.Spec.EgressRules} {
+		for _, rule := range rules {
+			if rule.RemoteType == kubeovnv1.SgRemoteTypeFQDN {
+				fqdns = append(fqdns, rule.RemoteAddress)
+			}
+		}
+	}
+	sort.Strings(fqdns)
+	return util.UniqString(fqdns)
+}
+
+// npFqdns returns the valid domain names in the egress fqdns annotation of the network policy
+func npFqdns(np *netv1.NetworkPolicy) []string {
+	if np.Annotations[util.NetworkPolicyEgressFqdnsAnnotation] == "" {
+		return nil
+	}
+	var fqdns []string
+	for _, fqdn := range strings.Split(np.Annotations[util.NetworkPolicyEgressFqdnsAnnotation], ",") {
+		fqdn = strings.TrimSpace(fqdn)
+		if err := util.ValidateFqdn(fqdn); err != nil {
+			klog.Warningf("skip fqdn of np %s/%s, %v", np.Namespace, np.Name, err)
+			continue
+		}
+		fqdns = append(fqdns, fqdn)
+	}
+	sort.Strings(fqdns)
+	return util.UniqString(fqdns)
+}
+
+// isFqdnReferred returns whether the domain name is referred by any security group or network policy
+func (c *Controller) isFqdnReferred(fqdn string) (bool, error) {
+	sgs, err := c.sgsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list security groups, %v", err)
+		return false, err
+	}
+	for _, sg := range sgs {
+		if util.ContainsString(sgFqdns(sg), fqdn) {
+			return true, nil
+		}
+	}
+
+	if !c.config.EnableNP {
+		return false, nil
+	}
+	nps, err := c.npsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list network policies, %v", err)
+		return false, err
+	}
+	for _, np := range nps {
+		if util.ContainsString(npFqdns(np), fqdn) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// handleSyncFqdnAddress keeps the FqdnAddress and the address sets of the domain name as long as it is referred,
+// and sets the unexpired addresses to the address sets until the next address expires
+func (c *Controller) handleSyncFqdnAddress(key string) error {
+	referred, err := c.isFqdnReferred(key)
+	if err != nil {
+		return err
+	}
+	fqdnAddress, err := c.fqdnAddressesLister.Get(key)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		klog.Errorf("failed to get fqdn address %s, %v", key, err)
+		return err
+	}
+
+	if !referred {
+		if fqdnAddress != nil {
+			klog.Infof("delete fqdn address %s which is not referred", key)
+			if err = c.config.KubeOvnClient.KubeovnV1().FqdnAddresses().Delete(context.Background(), key, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+				klog.Errorf("failed to delete fqdn address %s, %v", key, err)
+				return err
+			}
+		}
+		asList, err := c.ovnClient.ListAddressSets(map[string]string{"fqdn": key})
+		if err != nil {
+			klog.Errorf("failed to list address sets of fqdn %s, %v", key, err)
+			return err
+		}
+		for _, as := range asList {
+			if err = c.ovnClient.DeleteAddressSet(as.Name); err != nil {
+				klog.Errorf("failed to delete address set %s, %v", as.Name, err)
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err = c.ovnClient.CreateFqdnAddressSets(key); err != nil {
+		klog.Errorf("failed to create address sets of fqdn %s, %v", key, err)
+		return err
+	}
+	if fqdnAddress == nil {
+		fqdnAddress = &kubeovnv1.FqdnAddress{ObjectMeta: metav1.ObjectMeta{Name: key}}
+		if _, err = c.config.KubeOvnClient.KubeovnV1().FqdnAddresses().Create(context.Background(), fqdnAddress, metav1.CreateOptions{}); err != nil && !k8serrors.IsAlreadyExists(err) {
+			klog.Errorf("failed to create fqdn address %s, %v", key, err)
+			return err
+		}
+		return nil
+	}
+
+	now := time.Now()
+	var nextExpireTime time.Time
+	addresses := map[string][]string{kubeovnv1.ProtocolIPv4: {}, kubeovnv1.ProtocolIPv6: {}}
+	for _, record := range fqdnAddress.Status.Addresses {
+		if !record.ExpireTime.Time.After(now) {
+			continue
+		}
+		protocol := util.CheckProtocol(record.IP)
+		if _, ok := addresses[protocol]; !ok {
+			continue
+		}
+		addresses[protocol] = append(addresses[protocol], record.IP)
+		if nextExpireTime.IsZero() || record.ExpireTime.Time.Before(nextExpireTime) {
+			nextExpireTime = record.ExpireTime.Time
+		}
+	}
+	for protocol, ips := range addresses {
+		ips = util.UniqString(ips)
+		sort.Strings(ips)
+		asName := ovs.GetFqdnAddressSetName(key, protocol)
+		if err = c.ovnClient.SetAddressesToAddressSet(ips, asName); err != nil {
+			klog.Errorf("failed to set addresses of address set %s, %v", asName, err)
+			return err
+		}
+	}
+
+	if !nextExpireTime.IsZero() {
+		c.syncFqdnAddressQueue.AddAfter(key, nextExpireTime.Sub(now))
+	}
+	return nil
+}
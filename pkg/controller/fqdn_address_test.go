@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	netv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func Test_handleSyncFqdnAddress(t *testing.T) {
+	fqdn := "api.github.com"
+	ctrl := newFakeController(t, nil, nil)
+
+	sg := &kubeovnv1.SecurityGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "github"},
+		Spec: kubeovnv1.SecurityGroupSpec{
+			EgressRules: []*kubeovnv1.SgRule{{
+				IPVersion:     "ipv4",
+				RemoteType:    kubeovnv1.SgRemoteTypeFQDN,
+				RemoteAddress: fqdn,
+				Policy:        kubeovnv1.PolicyAllow,
+			}},
+		},
+	}
+	sgIndexer := ctrl.kubeovnInformerFactory.Kubeovn().V1().SecurityGroups().Informer().GetIndexer()
+	require.NoError(t, sgIndexer.Add(sg))
+
+	// the FqdnAddress and the address sets are created for the referred domain name
+	require.NoError(t, ctrl.handleSyncFqdnAddress(fqdn))
+	fqdnAddress, err := ctrl.kubeovnClient.KubeovnV1().FqdnAddresses().Get(context.Background(), fqdn, metav1.GetOptions{})
+	require.NoError(t, err)
+	for _, protocol := range []string{kubeovnv1.ProtocolIPv4, kubeovnv1.ProtocolIPv6} {
+		as, err := ctrl.ovnClient.GetAddressSet(ovs.GetFqdnAddressSetName(fqdn, protocol), false)
+		require.NoError(t, err)
+		require.Equal(t, fqdn, as.ExternalIDs["fqdn"])
+		require.Empty(t, as.Addresses)
+	}
+
+	// only the unexpired addresses are set to the address sets
+	now := time.Now()
+	fqdnAddress.Status.Addresses = []kubeovnv1.FqdnAddressRecord{
+		{IP: "140.82.112.6", ExpireTime: metav1.NewTime(now.Add(time.Minute))},
+		{IP: "140.82.112.5", ExpireTime: metav1.NewTime(now.Add(-time.Minute))},
+		{IP: "2606:50c0:8000::154", ExpireTime: metav1.NewTime(now.Add(time.Hour))},
+	}
+	fqdnIndexer := ctrl.kubeovnInformerFactory.Kubeovn().V1().FqdnAddresses().Informer().GetIndexer()
+	require.NoError(t, fqdnIndexer.Add(fqdnAddress))
+	require.NoError(t, ctrl.handleSyncFqdnAddress(fqdn))
+	as, err := ctrl.ovnClient.GetAddressSet(ovs.GetFqdnAddressSetName(fqdn, kubeovnv1.ProtocolIPv4), false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"140.82.112.6"}, as.Addresses)
+	as, err = ctrl.ovnClient.GetAddressSet(ovs.GetFqdnAddressSetName(fqdn, kubeovnv1.ProtocolIPv6), false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"2606:50c0:8000::154"}, as.Addresses)
+
+	// the domain name is still referred by the network policy after the security group is deleted
+	np := &netv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "egress",
+			Namespace:   "default",
+			Annotations: map[string]string{util.NetworkPolicyEgressFqdnsAnnotation: "api.github.com, Invalid_Name"},
+		},
+	}
+	require.Equal(t, []string{fqdn}, npFqdns(np))
+	npIndexer := ctrl.informerFactory.Networking().V1().NetworkPolicies().Informer().GetIndexer()
+	require.NoError(t, npIndexer.Add(np))
+	require.NoError(t, sgIndexer.Delete(sg))
+	require.NoError(t, ctrl.handleSyncFqdnAddress(fqdn))
+	_, err = ctrl.kubeovnClient.KubeovnV1().FqdnAddresses().Get(context.Background(), fqdn, metav1.GetOptions{})
+	require.NoError(t, err)
+
+	// the FqdnAddress and the address sets are deleted when the domain name is not referred
+	require.NoError(t, npIndexer.Delete(np))
+	require.NoError(t, ctrl.handleSyncFqdnAddress(fqdn))
+	_, err = ctrl.kubeovnClient.KubeovnV1().FqdnAddresses().Get(context.Background(), fqdn, metav1.GetOptions{})
+	require.True(t, k8serrors.IsNotFound(err))
+	asList, err := ctrl.ovnClient.ListAddressSets(map[string]string{"fqdn": fqdn})
+	require.NoError(t, err)
+	require.Empty(t, asList)
+}
@@ -6,12 +6,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ovn-org/libovsdb/ovsdb"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 
 	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
@@ -36,6 +38,7 @@ func (c *Controller) gc() error {
 		c.gcVip,
 		c.gcLbSvcPods,
 		c.gcVpcDns,
+		c.gcIPPool,
 	}
 	for _, gcFunc := range gcFunctions {
 		if err := gcFunc(); err != nil {
@@ -151,21 +154,21 @@ func (c *Controller) gcLogicalSwitch() error {
 	}
 
 	klog.Infof("start to gc dhcp options")
-	dhcpOptions, err := c.ovnLegacyClient.ListDHCPOptions(c.config.EnableExternalVpc, "", "")
+	dhcpOptions, err := c.ovnClient.ListDHCPOptions(c.config.EnableExternalVpc, "", "")
 	if err != nil {
 		klog.Errorf("failed to list dhcp options, %v", err)
 		return err
 	}
 	var uuidToDeleteList = []string{}
 	for _, item := range dhcpOptions {
-		ls := item.ExternalIds["ls"]
+		ls := item.ExternalIDs["ls"]
 		if !util.IsStringIn(ls, subnetNames) {
 			uuidToDeleteList = append(uuidToDeleteList, item.UUID)
 		}
 	}
 	klog.Infof("gc dhcp options %v", uuidToDeleteList)
 	if len(uuidToDeleteList) > 0 {
-		if err = c.ovnLegacyClient.DeleteDHCPOptionsByUUIDs(uuidToDeleteList); err != nil {
+		if err = c.ovnClient.DeleteDHCPOptionsByUUIDs(uuidToDeleteList); err != nil {
 			klog.Errorf("failed to delete dhcp options by uuids, %v", err)
 			return err
 		}
@@ -349,7 +352,7 @@ func (c *Controller) markAndCleanLSP() error {
 		}
 
 		klog.Infof("gc logical switch port %s", lsp.Name)
-		if err := c.ovnLegacyClient.DeleteLogicalSwitchPort(lsp.Name); err != nil {
+		if err := c.ovnClient.DeleteLogicalSwitchPort(lsp.Name); err != nil {
 			klog.Errorf("failed to delete lsp %s, %v", lsp, err)
 			return err
 		}
@@ -393,12 +396,13 @@ func (c *Controller) gcLoadBalancer() error {
 					}
 					return err
 				}
-				err = c.ovnLegacyClient.RemoveLbFromLogicalSwitch(
+				err = c.ovnClient.LogicalSwitchUpdateLoadBalancers(
+					subnetName,
+					ovsdb.MutateOperationDelete,
 					vpc.Status.TcpLoadBalancer,
 					vpc.Status.TcpSessionLoadBalancer,
 					vpc.Status.UdpLoadBalancer,
-					vpc.Status.UdpSessionLoadBalancer,
-					subnetName)
+					vpc.Status.UdpSessionLoadBalancer)
 				if err != nil {
 					return err
 				}
@@ -419,140 +423,131 @@ func (c *Controller) gcLoadBalancer() error {
 		}
 
 		// delete
-		ovnLbs, err := c.ovnLegacyClient.ListLoadBalancer()
+		ovnLbs, err := c.ovnClient.ListLoadBalancers()
 		if err != nil {
 			klog.Errorf("failed to list load balancer, %v", err)
 			return err
 		}
-		if err = c.ovnLegacyClient.DeleteLoadBalancer(ovnLbs...); err != nil {
+		dnatLbs, err := c.getOvnDnatLbNames()
+		if err != nil {
+			return err
+		}
+		var lbs []string
+		for _, lb := range ovnLbs {
+			if !util.ContainsString(dnatLbs, lb) {
+				lbs = append(lbs, lb)
+			}
+		}
+		if err = c.ovnClient.DeleteLoadBalancers(lbs...); err != nil {
 			klog.Errorf("failed to delete load balancer, %v", err)
 			return err
 		}
 		return nil
 	}
 
-	svcs, err := c.servicesLister.List(labels.Everything())
+	vpcs, err := c.vpcsLister.List(labels.Everything())
 	if err != nil {
-		klog.Errorf("failed to list svc, %v", err)
+		klog.Errorf("failed to list vpc, %v", err)
 		return err
 	}
-	tcpVips := []string{}
-	udpVips := []string{}
-	tcpSessionVips := []string{}
-	udpSessionVips := []string{}
-	for _, svc := range svcs {
-		ip := svc.Spec.ClusterIP
-		if v, ok := svc.Annotations[util.SwitchLBRuleVipsAnnotation]; ok {
-			ip = v
-		}
-		for _, port := range svc.Spec.Ports {
-			if port.Protocol == corev1.ProtocolTCP {
-				if svc.Spec.SessionAffinity == corev1.ServiceAffinityClientIP {
-					tcpSessionVips = append(tcpSessionVips, fmt.Sprintf("%s:%d", ip, port.Port))
-				} else {
-					tcpVips = append(tcpVips, fmt.Sprintf("%s:%d", ip, port.Port))
-				}
-			} else {
-				if svc.Spec.SessionAffinity == corev1.ServiceAffinityClientIP {
-					udpSessionVips = append(udpSessionVips, fmt.Sprintf("%s:%d", ip, port.Port))
-				} else {
-					udpVips = append(udpVips, fmt.Sprintf("%s:%d", ip, port.Port))
-				}
-			}
-		}
+	vpcMap := make(map[string]*kubeovnv1.Vpc, len(vpcs))
+	for _, vpc := range vpcs {
+		vpcMap[vpc.Name] = vpc
 	}
 
-	vpcs, err := c.vpcsLister.List(labels.Everything())
+	// the vips expected on each load balancer
+	svcs, err := c.servicesLister.List(labels.Everything())
 	if err != nil {
-		klog.Errorf("failed to list vpc, %v", err)
+		klog.Errorf("failed to list svc, %v", err)
 		return err
 	}
-	var vpcLbs []string
-	for _, vpc := range vpcs {
-		tcpLb, udpLb := vpc.Status.TcpLoadBalancer, vpc.Status.UdpLoadBalancer
-		tcpSessLb, udpSessLb := vpc.Status.TcpSessionLoadBalancer, vpc.Status.UdpSessionLoadBalancer
-		vpcLbs = append(vpcLbs, tcpLb, udpLb, tcpSessLb, udpSessLb)
-
-		if tcpLb != "" {
-			lbUuid, err := c.ovnLegacyClient.FindLoadbalancer(tcpLb)
-			if err != nil {
-				klog.Errorf("failed to get lb %v", err)
-			}
-			vips, err := c.ovnLegacyClient.GetLoadBalancerVips(lbUuid)
-			if err != nil {
-				klog.Errorf("failed to get tcp lb vips %v", err)
-				return err
-			}
-			for vip := range vips {
-				if !util.IsStringIn(vip, tcpVips) {
-					err := c.ovnLegacyClient.DeleteLoadBalancerVip(vip, tcpLb)
-					if err != nil {
-						klog.Errorf("failed to delete vip %s from tcp lb %s, %v", vip, tcpLb, err)
-						return err
+	affinityTimeout := c.ovnClient.LoadBalancerAffinityTimeoutSupported()
+	lbVips := make(map[string]sets.String)
+	templateVarNames := sets.NewString()
+	for _, svc := range svcs {
+		vpcName := svc.Annotations[util.VpcAnnotation]
+		if vpcName == "" {
+			vpcName = util.DefaultVpc
+		}
+		vpc := vpcMap[vpcName]
+		if vpc == nil {
+			continue
+		}
+		tcpLb, udpLb := getServiceLoadBalancers(svc, vpc, affinityTimeout)
+		tcpVips, udpVips := getServiceVips(svc)
+		for protocol, vips := range map[corev1.Protocol]sets.String{corev1.ProtocolTCP: tcpVips, corev1.ProtocolUDP: udpVips} {
+			serviceLb := tcpLb
+			if protocol != corev1.ProtocolTCP {
+				serviceLb = udpLb
+			}
+			for _, vip := range vips.UnsortedList() {
+				lb, key := serviceLb.name, vip
+				if c.vipTemplateScoped(svc, parseVipAddr(vip)) {
+					lb = templateLoadBalancer(serviceLb, vipAddressFamily(parseVipAddr(vip))).name
+					key = templateVip(vip, protocol)
+					for name := range templateVars(vip, protocol, nil) {
+						templateVarNames.Insert(name)
 					}
 				}
+				if lbVips[lb] == nil {
+					lbVips[lb] = sets.NewString()
+				}
+				lbVips[lb].Insert(key)
 			}
 		}
-
-		if tcpSessLb != "" {
-			lbUuid, err := c.ovnLegacyClient.FindLoadbalancer(tcpSessLb)
-			if err != nil {
-				klog.Errorf("failed to get lb %v", err)
-			}
-			vips, err := c.ovnLegacyClient.GetLoadBalancerVips(lbUuid)
-			if err != nil {
-				klog.Errorf("failed to get tcp session lb vips %v", err)
-				return err
-			}
-			for vip := range vips {
-				if !util.IsStringIn(vip, tcpSessionVips) {
-					err := c.ovnLegacyClient.DeleteLoadBalancerVip(vip, tcpSessLb)
-					if err != nil {
-						klog.Errorf("failed to delete vip %s from tcp session lb %s, %v", vip, tcpSessLb, err)
-						return err
-					}
+		if !vpc.Spec.EnableExternal {
+			continue
+		}
+		for _, ip := range c.lbIpamExternalIPs(svc) {
+			for _, port := range svc.Spec.Ports {
+				protocol := util.ProtocolTCP
+				if port.Protocol != corev1.ProtocolTCP {
+					protocol = util.ProtocolUDP
+				}
+				lb := lbIpamRouterLoadBalancer(vpcName, protocol)
+				if lbVips[lb] == nil {
+					lbVips[lb] = sets.NewString()
 				}
+				lbVips[lb].Insert(util.JoinHostPort(ip, port.Port))
 			}
 		}
+	}
 
-		if udpLb != "" {
-			lbUuid, err := c.ovnLegacyClient.FindLoadbalancer(udpLb)
-			if err != nil {
-				klog.Errorf("failed to get lb %v", err)
-				return err
-			}
-			vips, err := c.ovnLegacyClient.GetLoadBalancerVips(lbUuid)
-			if err != nil {
-				klog.Errorf("failed to get udp lb vips %v", err)
-				return err
+	// the load balancers created on demand are destroyed once no service is put on them
+	var vpcLbs []string
+	for _, vpc := range vpcs {
+		lbs := []string{vpc.Status.TcpLoadBalancer, vpc.Status.UdpLoadBalancer, vpc.Status.TcpSessionLoadBalancer, vpc.Status.UdpSessionLoadBalancer}
+		vpcLbs = append(vpcLbs, lbs...)
+		onDemandLbs, err := c.getOnDemandLoadBalancers(vpc.Name)
+		if err != nil {
+			return err
+		}
+		for _, lb := range onDemandLbs {
+			if lbVips[lb.Name].Len() != 0 {
+				lbs = append(lbs, lb.Name)
+				vpcLbs = append(vpcLbs, lb.Name)
 			}
-			for vip := range vips {
-				if !util.IsStringIn(vip, udpVips) {
-					err := c.ovnLegacyClient.DeleteLoadBalancerVip(vip, udpLb)
-					if err != nil {
-						klog.Errorf("failed to delete vip %s from tcp lb %s, %v", vip, udpLb, err)
-						return err
-					}
-				}
+		}
+		for _, lb := range []string{lbIpamRouterLoadBalancer(vpc.Name, util.ProtocolTCP), lbIpamRouterLoadBalancer(vpc.Name, util.ProtocolUDP)} {
+			if lbVips[lb].Len() != 0 {
+				lbs = append(lbs, lb)
+				vpcLbs = append(vpcLbs, lb)
 			}
 		}
 
-		if udpSessLb != "" {
-			lbUuid, err := c.ovnLegacyClient.FindLoadbalancer(udpSessLb)
-			if err != nil {
-				klog.Errorf("failed to get lb %v", err)
-				return err
+		for _, lb := range lbs {
+			if lb == "" {
+				continue
 			}
-			vips, err := c.ovnLegacyClient.GetLoadBalancerVips(lbUuid)
+			vips, err := c.ovnClient.GetLoadBalancerVips(lb)
 			if err != nil {
-				klog.Errorf("failed to get udp session lb vips %v", err)
+				klog.Errorf("failed to get vips of lb %s, %v", lb, err)
 				return err
 			}
 			for vip := range vips {
-				if !util.IsStringIn(vip, udpSessionVips) {
-					err := c.ovnLegacyClient.DeleteLoadBalancerVip(vip, udpSessLb)
-					if err != nil {
-						klog.Errorf("failed to delete vip %s from udp session lb %s, %v", vip, udpSessLb, err)
+				if !lbVips[lb].Has(vip) {
+					if err = c.ovnClient.LoadBalancerDeleteVip(lb, vip); err != nil {
+						klog.Errorf("failed to delete vip %s from lb %s, %v", vip, lb, err)
 						return err
 					}
 				}
@@ -560,7 +555,17 @@ func (c *Controller) gcLoadBalancer() error {
 		}
 	}
 
-	ovnLbs, err := c.ovnLegacyClient.ListLoadBalancer()
+	if err = c.gcChassisTemplateVars(templateVarNames); err != nil {
+		return err
+	}
+
+	dnatLbs, err := c.getOvnDnatLbNames()
+	if err != nil {
+		return err
+	}
+	vpcLbs = append(vpcLbs, dnatLbs...)
+
+	ovnLbs, err := c.ovnClient.ListLoadBalancers()
 	if err != nil {
 		klog.Errorf("failed to list load balancer, %v", err)
 		return err
@@ -573,13 +578,54 @@ func (c *Controller) gcLoadBalancer() error {
 			continue
 		}
 		klog.Infof("start to destroy load balancer %s", lb)
-		if err := c.ovnLegacyClient.DeleteLoadBalancer(lb); err != nil {
+		if err := c.ovnClient.DeleteLoadBalancers(lb); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// gcChassisTemplateVars removes the template variables of the vips no longer on the template load balancers
+func (c *Controller) gcChassisTemplateVars(names sets.String) error {
+	if !c.ovnClient.ChassisTemplateVarSupported() {
+		return nil
+	}
+	vars, err := c.ovnClient.GetChassisTemplateVars()
+	if err != nil {
+		klog.Errorf("failed to get chassis template variables, %v", err)
+		return err
+	}
+	staleVars := make(map[string]map[string]string)
+	for name := range vars {
+		if names.Has(name) {
+			continue
+		}
+		if strings.HasPrefix(name, templateVipVarPrefix+"_") || strings.HasPrefix(name, templateBackendsVarPrefix+"_") {
+			klog.Infof("gc chassis template variable %s", name)
+			staleVars[name] = nil
+		}
+	}
+	if err = c.ovnClient.UpdateChassisTemplateVars(staleVars); err != nil {
+		klog.Errorf("failed to delete chassis template variables, %v", err)
+		return err
+	}
+	return nil
+}
+
+// getOvnDnatLbNames returns the load balancers used by ovn dnat rules, which are not managed by services
+func (c *Controller) getOvnDnatLbNames() ([]string, error) {
+	dnats, err := c.ovnDnatRulesLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list ovn dnat rules, %v", err)
+		return nil, err
+	}
+	names := make([]string, 0, len(dnats))
+	for _, dnat := range dnats {
+		names = append(names, ovnDnatLbName(dnat.Name))
+	}
+	return names, nil
+}
+
 func (c *Controller) gcPortGroup() error {
 	klog.Infof("start to gc network policy")
 	var npNames []string
@@ -639,7 +685,7 @@ func (c *Controller) gcPortGroup() error {
 
 func (c *Controller) gcStaticRoute() error {
 	klog.Infof("start to gc static routes")
-	routes, err := c.ovnLegacyClient.GetStaticRouteList(util.DefaultVpc)
+	routes, err := c.ovnClient.ListStaticRoutes(util.DefaultVpc)
 	if err != nil {
 		klog.Errorf("failed to list static route %v", err)
 		return err
@@ -662,13 +708,13 @@ func (c *Controller) gcStaticRoute() error {
 			continue
 		}
 		if route.CIDR != "0.0.0.0/0" && route.CIDR != "::/0" && c.ipam.ContainAddress(route.CIDR) {
-			exist, err := c.ovnLegacyClient.NatRuleExists(route.CIDR)
+			exist, err := c.ovnClient.NatRuleExists(route.CIDR)
 			if exist || err != nil {
 				klog.Errorf("failed to get NatRule by LogicalIP %s, %v", route.CIDR, err)
 				continue
 			}
 			klog.Infof("gc static route %s %s %s", route.Policy, route.CIDR, route.NextHop)
-			if err := c.ovnLegacyClient.DeleteStaticRoute(route.CIDR, c.config.ClusterRouter); err != nil {
+			if err := c.ovnClient.DeleteStaticRoute(route.CIDR, c.config.ClusterRouter); err != nil {
 				klog.Errorf("failed to delete stale route %s, %v", route.NextHop, err)
 			}
 		}
@@ -858,3 +904,22 @@ func (c *Controller) gcVpcDns() error {
 	}
 	return nil
 }
+
+func (c *Controller) gcIPPool() error {
+	klog.Infof("start to gc ippool")
+	for subnet, names := range c.ipam.ListIPPools() {
+		for _, name := range names {
+			pool, err := c.ippoolLister.Get(name)
+			if err != nil && !k8serrors.IsNotFound(err) {
+				klog.Errorf("failed to get ippool %s, %v", name, err)
+				return err
+			}
+			if err == nil && pool.Spec.Subnet == subnet {
+				continue
+			}
+			klog.Infof("gc ippool %s of subnet %s", name, subnet)
+			c.ipam.RemoveIPPool(subnet, name)
+		}
+	}
+	return nil
+}
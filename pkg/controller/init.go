@@ -14,7 +14,9 @@ import (
 	"k8s.io/klog/v2"
 
 	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ipam"
 	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
 	"github.com/kubeovn/kube-ovn/pkg/util"
 )
 
@@ -58,9 +60,19 @@ func (c *Controller) InitOVN() error {
 		return err
 	}
 
+	if err := c.initAclLogMeter(); err != nil {
+		klog.Errorf("init acl log meter failed: %v", err)
+		return err
+	}
+
 	return nil
 }
 
+// initAclLogMeter creates the meter limiting the rate of acl logs
+func (c *Controller) initAclLogMeter() error {
+	return c.ovnClient.CreateOrUpdateMeter(util.AclLogMeterName, ovnnb.MeterUnitPktps, c.config.AclLogRate)
+}
+
 func (c *Controller) InitDefaultVpc() error {
 	cachedVpc, err := c.vpcsLister.Get(util.DefaultVpc)
 	if err != nil {
@@ -217,28 +229,28 @@ func (c *Controller) initLoadBalancer() error {
 		vpc := cachedVpc.DeepCopy()
 		vpcLb := c.GenVpcLoadBalancer(vpc.Name)
 
-		tcpLb, err := c.ovnLegacyClient.FindLoadbalancer(vpcLb.TcpLoadBalancer)
+		tcpLbExists, err := c.ovnClient.LoadBalancerExists(vpcLb.TcpLoadBalancer)
 		if err != nil {
 			return fmt.Errorf("failed to find tcp lb: %v", err)
 		}
-		if tcpLb == "" {
+		if !tcpLbExists {
 			klog.Infof("init cluster tcp load balancer %s", vpcLb.TcpLoadBalancer)
-			err := c.ovnLegacyClient.CreateLoadBalancer(vpcLb.TcpLoadBalancer, util.ProtocolTCP, "")
+			err := c.ovnClient.CreateLoadBalancer(vpcLb.TcpLoadBalancer, util.ProtocolTCP, "")
 			if err != nil {
 				klog.Errorf("failed to create cluster tcp load balancer: %v", err)
 				return err
 			}
 		} else {
-			klog.Infof("tcp load balancer %s exists", tcpLb)
+			klog.Infof("tcp load balancer %s exists", vpcLb.TcpLoadBalancer)
 		}
 
-		tcpSessionLb, err := c.ovnLegacyClient.FindLoadbalancer(vpcLb.TcpSessLoadBalancer)
+		tcpSessionLbExists, err := c.ovnClient.LoadBalancerExists(vpcLb.TcpSessLoadBalancer)
 		if err != nil {
 			return fmt.Errorf("failed to find tcp session lb: %v", err)
 		}
-		if tcpSessionLb == "" {
+		if !tcpSessionLbExists {
 			klog.Infof("init cluster tcp session load balancer %s", vpcLb.TcpSessLoadBalancer)
-			err := c.ovnLegacyClient.CreateLoadBalancer(vpcLb.TcpSessLoadBalancer, util.ProtocolTCP, "ip_src")
+			err := c.ovnClient.CreateLoadBalancer(vpcLb.TcpSessLoadBalancer, util.ProtocolTCP, "ip_src")
 			if err != nil {
 				klog.Errorf("failed to create cluster tcp session load balancer: %v", err)
 				return err
@@ -247,28 +259,28 @@ func (c *Controller) initLoadBalancer() error {
 			klog.Infof("tcp session load balancer %s exists", vpcLb.TcpSessLoadBalancer)
 		}
 
-		udpLb, err := c.ovnLegacyClient.FindLoadbalancer(vpcLb.UdpLoadBalancer)
+		udpLbExists, err := c.ovnClient.LoadBalancerExists(vpcLb.UdpLoadBalancer)
 		if err != nil {
 			return fmt.Errorf("failed to find udp lb: %v", err)
 		}
-		if udpLb == "" {
+		if !udpLbExists {
 			klog.Infof("init cluster udp load balancer %s", vpcLb.UdpLoadBalancer)
-			err := c.ovnLegacyClient.CreateLoadBalancer(vpcLb.UdpLoadBalancer, util.ProtocolUDP, "")
+			err := c.ovnClient.CreateLoadBalancer(vpcLb.UdpLoadBalancer, util.ProtocolUDP, "")
 			if err != nil {
 				klog.Errorf("failed to create cluster udp load balancer: %v", err)
 				return err
 			}
 		} else {
-			klog.Infof("udp load balancer %s exists", udpLb)
+			klog.Infof("udp load balancer %s exists", vpcLb.UdpLoadBalancer)
 		}
 
-		udpSessionLb, err := c.ovnLegacyClient.FindLoadbalancer(vpcLb.UdpSessLoadBalancer)
+		udpSessionLbExists, err := c.ovnClient.LoadBalancerExists(vpcLb.UdpSessLoadBalancer)
 		if err != nil {
 			return fmt.Errorf("failed to find udp session lb: %v", err)
 		}
-		if udpSessionLb == "" {
+		if !udpSessionLbExists {
 			klog.Infof("init cluster udp session load balancer %s", vpcLb.UdpSessLoadBalancer)
-			err := c.ovnLegacyClient.CreateLoadBalancer(vpcLb.UdpSessLoadBalancer, util.ProtocolUDP, "ip_src")
+			err := c.ovnClient.CreateLoadBalancer(vpcLb.UdpSessLoadBalancer, util.ProtocolUDP, "ip_src")
 			if err != nil {
 				klog.Errorf("failed to create cluster udp session load balancer: %v", err)
 				return err
@@ -295,24 +307,35 @@ func (c *Controller) initLoadBalancer() error {
 
 func (c *Controller) InitIPAM() error {
 	start := time.Now()
-	subnets, err := c.subnetsLister.List(labels.Everything())
-	if err != nil {
-		klog.Errorf("failed to list subnet: %v", err)
-		return err
-	}
-	for _, subnet := range subnets {
-		if err := c.ipam.AddOrUpdateSubnet(subnet.Name, subnet.Spec.CIDRBlock, subnet.Spec.Gateway, subnet.Spec.ExcludeIps); err != nil {
-			klog.Errorf("failed to init subnet %s: %v", subnet.Name, err)
+
+	// resume from the checkpoint if possible, addresses recorded in it are
+	// only re-acquired when the related IP CR has changed since it was taken
+	var snapshot *ipam.Snapshot
+	if c.config.EnableIPAMCheckpoint {
+		if snapshot = c.loadIPAMCheckpoint(); snapshot != nil {
+			if err := c.ipam.Restore(snapshot); err != nil {
+				klog.Errorf("failed to restore ipam from checkpoint, rebuild it from scratch: %v", err)
+				snapshot = nil
+			} else {
+				klog.Infof("restored ipam from checkpoint with %d subnets", len(snapshot.Subnets))
+			}
 		}
 	}
 
-	result, err := c.ovnLegacyClient.CustomFindEntity("logical_switch_port", []string{"name"}, `external-ids:vendor{<}""`)
+	if err := c.initIPAMSubnets(snapshot); err != nil {
+		return err
+	}
+
+	result, err := c.ovnClient.ListLogicalSwitchPortsWithFilter(func(lsp *ovnnb.LogicalSwitchPort) bool {
+		_, ok := lsp.ExternalIDs["vendor"]
+		return !ok
+	})
 	if err != nil {
 		klog.Errorf("failed to find logical switch port without external-ids:vendor: %v", err)
 	}
 	lspWithoutVendor := make(map[string]struct{}, len(result))
 	for _, lsp := range result {
-		lspWithoutVendor[lsp["name"][0]] = struct{}{}
+		lspWithoutVendor[lsp.Name] = struct{}{}
 	}
 
 	pods, err := c.podsLister.List(labels.Everything())
@@ -327,14 +350,21 @@ func (c *Controller) InitIPAM() error {
 		return err
 	}
 
+	// nics holding addresses after the initialization, used to clean up the stale ones in the checkpoint
+	nics := make(map[string]struct{}, len(ips))
 	ipsMap := make(map[string]*kubeovnv1.IP, len(ips))
 	for _, ip := range ips {
 		ipsMap[ip.Name] = ip
+		if snapshot != nil && snapshot.ResourceVersions[ip.Name] != ip.ResourceVersion {
+			// the IP CR has changed after the checkpoint was taken
+			c.ipam.ReleaseAddressByNic(ip.Name)
+		}
 		// recover sts and kubevirt vm ip, other ip recover in later pod loop
 		if ip.Spec.PodType != "StatefulSet" && ip.Spec.PodType != util.Vm {
 			continue
 		}
 
+		nics[ip.Name] = struct{}{}
 		var ipamKey string
 		if ip.Spec.Namespace != "" {
 			ipamKey = fmt.Sprintf("%s/%s", ip.Spec.Namespace, ip.Spec.PodName)
@@ -372,14 +402,19 @@ func (c *Controller) InitIPAM() error {
 				ip := pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, podNet.ProviderName)]
 				mac := pod.Annotations[fmt.Sprintf(util.MacAddressAnnotationTemplate, podNet.ProviderName)]
 				subnet := pod.Annotations[fmt.Sprintf(util.LogicalSwitchAnnotationTemplate, podNet.ProviderName)]
-				_, _, _, err := c.ipam.GetStaticAddress(key, portName, ip, mac, subnet, true)
-				if err != nil {
-					klog.Errorf("failed to init pod %s.%s address %s: %v", podName, pod.Namespace, pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, podNet.ProviderName)], err)
-				} else {
-					ipCR := ipsMap[portName]
-					err = c.createOrUpdateCrdIPs(podName, ip, mac, subnet, pod.Namespace, pod.Spec.NodeName, podNet.ProviderName, podType, &ipCR)
-					if err != nil {
-						klog.Errorf("failed to create/update ips CR %s.%s with ip address %s: %v", podName, pod.Namespace, ip, err)
+				nics[portName] = struct{}{}
+				ipCR := ipsMap[portName]
+				// skip the address already recorded in the checkpoint
+				checkpointed := snapshot != nil && ipCR != nil && snapshot.ResourceVersions[portName] == ipCR.ResourceVersion &&
+					ipCR.Spec.IPAddress == ip && ipCR.Spec.Subnet == subnet
+				if !checkpointed {
+					if _, _, _, err := c.ipam.GetStaticAddress(key, portName, ip, mac, subnet, true); err != nil {
+						klog.Errorf("failed to init pod %s.%s address %s: %v", podName, pod.Namespace, pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, podNet.ProviderName)], err)
+					} else {
+						err = c.createOrUpdateCrdIPs(podName, ip, mac, subnet, pod.Namespace, pod.Spec.NodeName, podNet.ProviderName, podType, &ipCR)
+						if err != nil {
+							klog.Errorf("failed to create/update ips CR %s.%s with ip address %s: %v", podName, pod.Namespace, ip, err)
+						}
 					}
 				}
 
@@ -392,6 +427,70 @@ func (c *Controller) InitIPAM() error {
 		}
 	}
 
+	if err = c.initIPAMReservedAddresses(lspWithoutVendor, nics); err != nil {
+		return err
+	}
+
+	if snapshot != nil {
+		// release the addresses of the workloads removed after the checkpoint was taken
+		for _, subnet := range snapshot.Subnets {
+			for _, nic := range subnet.Nics() {
+				if _, ok := nics[nic]; !ok {
+					klog.Infof("release address of nic %s which no longer exists", nic)
+					c.ipam.ReleaseAddressByNic(nic)
+				}
+			}
+		}
+	}
+
+	klog.Infof("take %.2f seconds to initialize IPAM", time.Since(start).Seconds())
+	return nil
+}
+
+// initIPAMSubnets adds the subnets and ippools to ipam, subnets restored from
+// the checkpoint are only rebuilt when their specs have changed
+func (c *Controller) initIPAMSubnets(snapshot *ipam.Snapshot) error {
+	subnets, err := c.subnetsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list subnet: %v", err)
+		return err
+	}
+	subnetNames := make(map[string]struct{}, len(subnets))
+	for _, subnet := range subnets {
+		subnetNames[subnet.Name] = struct{}{}
+		cidrBlocks := strings.Join(util.SubnetCIDRBlocks(subnet), ",")
+		if snapshot != nil {
+			if s := snapshot.Subnets[subnet.Name]; s != nil && s.Matches(cidrBlocks, subnet.Spec.Gateway, subnet.Spec.ExcludeIps) {
+				continue
+			}
+		}
+		if err := c.ipam.AddOrUpdateSubnet(subnet.Name, cidrBlocks, subnet.Spec.Gateway, subnet.Spec.ExcludeIps); err != nil {
+			klog.Errorf("failed to init subnet %s: %v", subnet.Name, err)
+		}
+	}
+	if snapshot != nil {
+		for name := range snapshot.Subnets {
+			if _, ok := subnetNames[name]; !ok {
+				c.ipam.DeleteSubnet(name)
+			}
+		}
+	}
+
+	ippools, err := c.ippoolLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list ippool: %v", err)
+		return err
+	}
+	for _, ippool := range ippools {
+		if err = c.ipam.AddOrUpdateIPPool(ippool.Spec.Subnet, ippool.Name, ippool.Spec.IPs); err != nil {
+			klog.Errorf("failed to init ippool %s: %v", ippool.Name, err)
+		}
+	}
+	return nil
+}
+
+// initIPAMReservedAddresses recovers the addresses of vips, loadbalancer services, eips and nodes
+func (c *Controller) initIPAMReservedAddresses(lspWithoutVendor, nics map[string]struct{}) error {
 	vips, err := c.virtualIpsLister.List(labels.SelectorFromSet(labels.Set{util.IpReservedLabel: ""}))
 	if err != nil {
 		klog.Errorf("failed to list VIPs: %v", err)
@@ -404,22 +503,48 @@ func (c *Controller) InitIPAM() error {
 		} else {
 			ipamKey = vip.Name
 		}
+		nics[vip.Name] = struct{}{}
 		if _, _, _, err = c.ipam.GetStaticAddress(ipamKey, vip.Name, vip.Spec.V4ip, vip.Spec.MacAddress, vip.Spec.Subnet, false); err != nil {
 			klog.Errorf("failed to init IPAM from VIP CR %s: %v", vip.Name, err)
 		}
 	}
 
+	if c.lbIpamEnabled() {
+		svcs, err := c.servicesLister.List(labels.Everything())
+		if err != nil {
+			klog.Errorf("failed to list services: %v", err)
+			return err
+		}
+		c.initLbIpam(svcs)
+	}
+
 	eips, err := c.iptablesEipsLister.List(labels.Everything())
 	if err != nil {
 		klog.Errorf("failed to list EIPs: %v", err)
 		return err
 	}
 	for _, eip := range eips {
+		nics[eip.Name] = struct{}{}
 		if _, _, _, err = c.ipam.GetStaticAddress(eip.Name, eip.Name, eip.Spec.V4ip, eip.Spec.MacAddress, util.VpcExternalNet, false); err != nil {
 			klog.Errorf("failed to init IPAM from EIP CR %s: %v", eip.Name, err)
 		}
 	}
 
+	ovnEips, err := c.ovnEipsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list ovn EIPs: %v", err)
+		return err
+	}
+	for _, eip := range ovnEips {
+		if eip.Spec.V4ip == "" {
+			continue
+		}
+		nics[eip.Name] = struct{}{}
+		if _, _, _, err = c.ipam.GetStaticAddress(eip.Name, eip.Name, eip.Spec.V4ip, eip.Spec.MacAddress, util.VpcExternalNet, false); err != nil {
+			klog.Errorf("failed to init IPAM from ovn EIP CR %s: %v", eip.Name, err)
+		}
+	}
+
 	nodes, err := c.nodesLister.List(labels.Everything())
 	if err != nil {
 		klog.Errorf("failed to list nodes: %v", err)
@@ -428,6 +553,7 @@ func (c *Controller) InitIPAM() error {
 	for _, node := range nodes {
 		if node.Annotations[util.AllocatedAnnotation] == "true" {
 			portName := fmt.Sprintf("node-%s", node.Name)
+			nics[portName] = struct{}{}
 			v4IP, v6IP, _, err := c.ipam.GetStaticAddress(portName, portName,
 				node.Annotations[util.IpAddressAnnotation],
 				node.Annotations[util.MacAddressAnnotation],
@@ -446,8 +572,6 @@ func (c *Controller) InitIPAM() error {
 			}
 		}
 	}
-
-	klog.Infof("take %.2f seconds to initialize IPAM", time.Since(start).Seconds())
 	return nil
 }
 
@@ -695,19 +819,19 @@ func (c *Controller) initSyncCrdVlans() error {
 }
 
 func (c *Controller) migrateNodeRoute(af int, node, ip, nexthop string) error {
-	if err := c.ovnLegacyClient.DeleteStaticRoute(ip, c.config.ClusterRouter); err != nil {
+	if err := c.ovnClient.DeleteStaticRoute(ip, c.config.ClusterRouter); err != nil {
 		klog.Errorf("failed to delete obsolete static route for node %s: %v", node, err)
 		return err
 	}
 
 	asName := nodeUnderlayAddressSetName(node, af)
 	obsoleteMatch := fmt.Sprintf("ip%d.dst == %s && ip%d.src != $%s", af, ip, af, asName)
-	if err := c.ovnLegacyClient.DeletePolicyRoute(c.config.ClusterRouter, util.NodeRouterPolicyPriority, obsoleteMatch); err != nil {
+	if err := c.ovnClient.DeletePolicyRoute(c.config.ClusterRouter, util.NodeRouterPolicyPriority, obsoleteMatch); err != nil {
 		klog.Errorf("failed to delete obsolete logical router policy for node %s: %v", node, err)
 		return err
 	}
 
-	if err := c.ovnLegacyClient.DeleteAddressSet(asName); err != nil {
+	if err := c.ovnClient.DeleteAddressSet(asName); err != nil {
 		klog.Errorf("failed to delete obsolete address set %s for node %s: %v", asName, node, err)
 		return err
 	}
@@ -717,7 +841,7 @@ func (c *Controller) migrateNodeRoute(af int, node, ip, nexthop string) error {
 		"vendor": util.CniTypeName,
 		"node":   node,
 	}
-	if err := c.ovnLegacyClient.AddPolicyRoute(c.config.ClusterRouter, util.NodeRouterPolicyPriority, match, "reroute", nexthop, externalIDs); err != nil {
+	if err := c.ovnClient.AddPolicyRoute(c.config.ClusterRouter, util.NodeRouterPolicyPriority, match, "reroute", nexthop, externalIDs); err != nil {
 		klog.Errorf("failed to add logical router policy for node %s: %v", node, err)
 		return err
 	}
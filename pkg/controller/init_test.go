@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	ovsclient "github.com/kubeovn/kube-ovn/pkg/ovsdb/client"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func Test_initAclLogMeter(t *testing.T) {
+	ctrl := newFakeController(t, nil, nil)
+	// meter bands are not exposed by the ovn client
+	nbClient, err := ovsclient.NewNbClient(ctrl.config.OvnNbAddr, ctrl.config.OvnTimeout)
+	require.NoError(t, err)
+	t.Cleanup(nbClient.Close)
+
+	for _, rate := range []int{100, 100, 20} {
+		ctrl.config.AclLogRate = rate
+		require.NoError(t, ctrl.initAclLogMeter())
+
+		meter, err := ctrl.ovnClient.GetMeter(util.AclLogMeterName, false)
+		require.NoError(t, err)
+		require.Equal(t, ovnnb.MeterUnitPktps, meter.Unit)
+		require.NotNil(t, meter.Fair)
+		require.True(t, *meter.Fair)
+		require.Len(t, meter.Bands, 1)
+
+		var bands []ovnnb.MeterBand
+		require.NoError(t, nbClient.List(context.Background(), &bands))
+		require.Len(t, bands, 1)
+		require.Equal(t, rate, bands[0].Rate)
+		require.Equal(t, ovnnb.MeterBandActionDrop, bands[0].Action)
+	}
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	"github.com/kubeovn/kube-ovn/pkg/ipam"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+const (
+	ipamCheckpointKey = "ipam.json.gz"
+	// leave some room for the metadata under the 1MiB limit of configmap
+	maxIPAMCheckpointSize = 1000 * 1024
+)
+
+// loadIPAMCheckpoint returns the ipam snapshot saved by the last leader, nil if there is no valid one
+func (c *Controller) loadIPAMCheckpoint() *ipam.Snapshot {
+	cm, err := c.configMapsLister.ConfigMaps(c.config.PodNamespace).Get(util.IPAMCheckpointConfig)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			klog.Errorf("failed to get configmap %s: %v", util.IPAMCheckpointConfig, err)
+		}
+		return nil
+	}
+	data := cm.BinaryData[ipamCheckpointKey]
+	if len(data) == 0 {
+		return nil
+	}
+	snapshot, err := ipam.DecodeSnapshot(data)
+	if err != nil {
+		klog.Errorf("failed to decode ipam checkpoint: %v", err)
+		return nil
+	}
+	return snapshot
+}
+
+// saveIPAMCheckpoint snapshots the ipam to configmap, IP CRs are listed before the snapshot
+// is taken so that an IP CR changed in between is replayed when resuming
+func (c *Controller) saveIPAMCheckpoint() {
+	ips, err := c.ipsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list IPs: %v", err)
+		return
+	}
+	resourceVersions := make(map[string]string, len(ips))
+	for _, ip := range ips {
+		resourceVersions[ip.Name] = ip.ResourceVersion
+	}
+
+	snapshot := c.ipam.Snapshot()
+	snapshot.ResourceVersions = resourceVersions
+	data, err := snapshot.Encode()
+	if err != nil {
+		klog.Errorf("failed to encode ipam checkpoint: %v", err)
+		return
+	}
+	if len(data) > maxIPAMCheckpointSize {
+		klog.Warningf("ipam checkpoint size %d exceeds the limit %d, skip saving it", len(data), maxIPAMCheckpointSize)
+		return
+	}
+
+	cm, err := c.configMapsLister.ConfigMaps(c.config.PodNamespace).Get(util.IPAMCheckpointConfig)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			klog.Errorf("failed to get configmap %s: %v", util.IPAMCheckpointConfig, err)
+			return
+		}
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      util.IPAMCheckpointConfig,
+				Namespace: c.config.PodNamespace,
+			},
+			BinaryData: map[string][]byte{ipamCheckpointKey: data},
+		}
+		if _, err = c.config.KubeClient.CoreV1().ConfigMaps(c.config.PodNamespace).Create(context.Background(), cm, metav1.CreateOptions{}); err != nil {
+			klog.Errorf("failed to create configmap %s: %v", util.IPAMCheckpointConfig, err)
+		}
+		return
+	}
+
+	cm = cm.DeepCopy()
+	cm.BinaryData = map[string][]byte{ipamCheckpointKey: data}
+	if _, err = c.config.KubeClient.CoreV1().ConfigMaps(c.config.PodNamespace).Update(context.Background(), cm, metav1.UpdateOptions{}); err != nil {
+		klog.Errorf("failed to update configmap %s: %v", util.IPAMCheckpointConfig, err)
+		return
+	}
+	klog.V(3).Infof("saved ipam checkpoint of %d bytes", len(data))
+}
@@ -0,0 +1,255 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func (c *Controller) enqueueAddIPPool(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(obj); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	klog.V(3).Infof("enqueue add ippool %s", key)
+	c.addOrUpdateIPPoolQueue.Add(key)
+}
+
+func (c *Controller) enqueueUpdateIPPool(old, new interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(new); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+
+	oldPool := old.(*kubeovnv1.IPPool)
+	newPool := new.(*kubeovnv1.IPPool)
+	if oldPool.ResourceVersion == newPool.ResourceVersion ||
+		reflect.DeepEqual(oldPool.Spec, newPool.Spec) {
+		return
+	}
+	if oldPool.Spec.Subnet != newPool.Spec.Subnet {
+		// release the ranges in the old subnet
+		c.deleteIPPoolQueue.Add(oldPool)
+	}
+	klog.V(3).Infof("enqueue update ippool %s", key)
+	c.addOrUpdateIPPoolQueue.Add(key)
+}
+
+func (c *Controller) enqueueDeleteIPPool(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var pool *kubeovnv1.IPPool
+	switch t := obj.(type) {
+	case *kubeovnv1.IPPool:
+		pool = t
+	case cache.DeletedFinalStateUnknown:
+		p, ok := t.Obj.(*kubeovnv1.IPPool)
+		if !ok {
+			klog.Warningf("unexpected object type: %T", t.Obj)
+			return
+		}
+		pool = p
+	default:
+		klog.Warningf("unexpected type: %T", obj)
+		return
+	}
+	klog.V(3).Infof("enqueue delete ippool %s", pool.Name)
+	c.deleteIPPoolQueue.Add(pool)
+}
+
+// enqueueIPPoolsOfSubnet re-syncs the ippools after the subnet or its addresses changed
+func (c *Controller) enqueueIPPoolsOfSubnet(subnet string, statusOnly bool) {
+	pools, err := c.ippoolLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list ippools: %v", err)
+		return
+	}
+	for _, pool := range pools {
+		if pool.Spec.Subnet != subnet {
+			continue
+		}
+		if statusOnly {
+			c.updateIPPoolStatusQueue.Add(pool.Name)
+		} else {
+			c.addOrUpdateIPPoolQueue.Add(pool.Name)
+		}
+	}
+}
+
+func (c *Controller) runAddOrUpdateIPPoolWorker() {
+	for c.processNextWorkItem("addOrUpdateIPPool", c.addOrUpdateIPPoolQueue, c.handleAddOrUpdateIPPool) {
+	}
+}
+
+func (c *Controller) runUpdateIPPoolStatusWorker() {
+	for c.processNextWorkItem("updateIPPoolStatus", c.updateIPPoolStatusQueue, c.handleUpdateIPPoolStatus) {
+	}
+}
+
+func (c *Controller) runDeleteIPPoolWorker() {
+	for c.processNextDeleteIPPoolWorkItem() {
+	}
+}
+
+func (c *Controller) processNextDeleteIPPoolWorkItem() bool {
+	obj, shutdown := c.deleteIPPoolQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.deleteIPPoolQueue.Done(obj)
+		var pool *kubeovnv1.IPPool
+		var ok bool
+		if pool, ok = obj.(*kubeovnv1.IPPool); !ok {
+			c.deleteIPPoolQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected ippool in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleDeleteIPPool(pool); err != nil {
+			c.deleteIPPoolQueue.AddRateLimited(obj)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", pool.Name, err.Error())
+		}
+		c.deleteIPPoolQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+func (c *Controller) handleAddOrUpdateIPPool(key string) error {
+	c.ippoolKeyMutex.Lock(key)
+	defer c.ippoolKeyMutex.Unlock(key)
+
+	cachedPool, err := c.ippoolLister.Get(key)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		klog.Errorf("failed to get ippool %s: %v", key, err)
+		return err
+	}
+	pool := cachedPool.DeepCopy()
+	klog.Infof("handle add or update ippool %s", pool.Name)
+
+	if err = c.ipam.AddOrUpdateIPPool(pool.Spec.Subnet, pool.Name, pool.Spec.IPs); err != nil {
+		klog.Errorf("failed to add or update ippool %s: %v", pool.Name, err)
+		pool.Status.NotReady("AddOrUpdateIPPoolFailed", err.Error())
+		if _, err2 := c.config.KubeOvnClient.KubeovnV1().IPPools().UpdateStatus(context.Background(), pool, metav1.UpdateOptions{}); err2 != nil {
+			klog.Errorf("failed to update status of ippool %s: %v", pool.Name, err2)
+		}
+		return err
+	}
+
+	pool.Status.Ready("SetupSucceed", "")
+	if _, err = c.config.KubeOvnClient.KubeovnV1().IPPools().UpdateStatus(context.Background(), pool, metav1.UpdateOptions{}); err != nil {
+		klog.Errorf("failed to update status of ippool %s: %v", pool.Name, err)
+		return err
+	}
+	c.updateIPPoolStatusQueue.Add(pool.Name)
+	return nil
+}
+
+func (c *Controller) handleDeleteIPPool(pool *kubeovnv1.IPPool) error {
+	c.ippoolKeyMutex.Lock(pool.Name)
+	defer c.ippoolKeyMutex.Unlock(pool.Name)
+
+	klog.Infof("handle delete ippool %s", pool.Name)
+	c.ipam.RemoveIPPool(pool.Spec.Subnet, pool.Name)
+	return nil
+}
+
+func (c *Controller) handleUpdateIPPoolStatus(key string) error {
+	c.ippoolKeyMutex.Lock(key)
+	defer c.ippoolKeyMutex.Unlock(key)
+
+	cachedPool, err := c.ippoolLister.Get(key)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		klog.Errorf("failed to get ippool %s: %v", key, err)
+		return err
+	}
+
+	v4Available, v4Using, v6Available, v6Using, err := c.ipam.IPPoolStatistics(cachedPool.Spec.Subnet, cachedPool.Name)
+	if err != nil {
+		// the ippool has not been set up in ipam, handleAddOrUpdateIPPool will report it
+		klog.V(3).Infof("skip updating status of ippool %s: %v", key, err)
+		return nil
+	}
+	if cachedPool.Status.V4AvailableIPs == v4Available && cachedPool.Status.V4UsingIPs == v4Using &&
+		cachedPool.Status.V6AvailableIPs == v6Available && cachedPool.Status.V6UsingIPs == v6Using {
+		return nil
+	}
+
+	pool := cachedPool.DeepCopy()
+	pool.Status.V4AvailableIPs = v4Available
+	pool.Status.V4UsingIPs = v4Using
+	pool.Status.V6AvailableIPs = v6Available
+	pool.Status.V6UsingIPs = v6Using
+	if _, err = c.config.KubeOvnClient.KubeovnV1().IPPools().UpdateStatus(context.Background(), pool, metav1.UpdateOptions{}); err != nil {
+		klog.Errorf("failed to update status of ippool %s: %v", key, err)
+		return err
+	}
+	return nil
+}
+
+// getPodIPPool returns the ippool in the subnet the pod is bound to by namespace or label selector,
+// ippools are checked in name order so that the result is stable when several ippools match
+func (c *Controller) getPodIPPool(pod *v1.Pod, subnet string) (string, error) {
+	pools, err := c.ippoolLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list ippools: %v", err)
+		return "", err
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Name < pools[j].Name })
+
+	for _, pool := range pools {
+		if pool.Spec.Subnet != subnet {
+			continue
+		}
+		if util.ContainsString(pool.Spec.Namespaces, pod.Namespace) {
+			return pool.Name, nil
+		}
+		if pool.Spec.Selector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pool.Spec.Selector)
+		if err != nil {
+			klog.Errorf("invalid selector of ippool %s: %v", pool.Name, err)
+			continue
+		}
+		if !selector.Empty() && selector.Matches(labels.Set(pod.Labels)) {
+			return pool.Name, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ipam"
+)
+
+func newTestIPPool(name, subnet string, ips, namespaces []string, selector *metav1.LabelSelector) *kubeovnv1.IPPool {
+	return &kubeovnv1.IPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: name, ResourceVersion: "1"},
+		Spec: kubeovnv1.IPPoolSpec{
+			Subnet:     subnet,
+			IPs:        ips,
+			Namespaces: namespaces,
+			Selector:   selector,
+		},
+	}
+}
+
+func getIPPoolReadyCondition(t *testing.T, ctrl *fakeController, name string) kubeovnv1.IPPoolCondition {
+	t.Helper()
+	pool, err := ctrl.kubeovnClient.KubeovnV1().IPPools().Get(context.Background(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	for _, condition := range pool.Status.Conditions {
+		if condition.Type == kubeovnv1.Ready {
+			return condition
+		}
+	}
+	t.Fatalf("no ready condition of ippool %s", name)
+	return kubeovnv1.IPPoolCondition{}
+}
+
+func Test_handleAddOrUpdateIPPool(t *testing.T) {
+	pool1 := newTestIPPool("pool1", "subnet1", []string{"10.16.0.10..10.16.0.19"}, []string{"ns1"}, nil)
+	pool2 := newTestIPPool("pool2", "subnet1", []string{"10.16.0.16/28"}, []string{"ns2"}, nil)
+	ctrl := newFakeController(t, nil, []runtime.Object{pool1, pool2})
+	require.NoError(t, ctrl.ipam.AddOrUpdateSubnet("subnet1", "10.16.0.0/24", "10.16.0.1", nil))
+
+	require.NoError(t, ctrl.handleAddOrUpdateIPPool("pool1"))
+	require.Equal(t, map[string][]string{"subnet1": {"pool1"}}, ctrl.ipam.ListIPPools())
+	condition := getIPPoolReadyCondition(t, ctrl, "pool1")
+	require.Equal(t, corev1.ConditionTrue, condition.Status)
+	require.Equal(t, 1, ctrl.updateIPPoolStatusQueue.Len())
+
+	// overlapping ippools are not set up
+	require.ErrorIs(t, ctrl.handleAddOrUpdateIPPool("pool2"), ipam.ErrConflict)
+	require.Equal(t, map[string][]string{"subnet1": {"pool1"}}, ctrl.ipam.ListIPPools())
+	condition = getIPPoolReadyCondition(t, ctrl, "pool2")
+	require.Equal(t, corev1.ConditionFalse, condition.Status)
+	require.Equal(t, "AddOrUpdateIPPoolFailed", condition.Reason)
+
+	// deleted ippools are skipped
+	require.NoError(t, ctrl.handleAddOrUpdateIPPool("pool3"))
+}
+
+func Test_handleDeleteIPPool(t *testing.T) {
+	pool := newTestIPPool("pool1", "subnet1", []string{"10.16.0.2..10.16.0.5"}, []string{"ns1"}, nil)
+	ctrl := newFakeController(t, nil, []runtime.Object{pool})
+	require.NoError(t, ctrl.ipam.AddOrUpdateSubnet("subnet1", "10.16.0.0/29", "10.16.0.1", []string{"10.16.0.1", "10.16.0.6"}))
+	require.NoError(t, ctrl.handleAddOrUpdateIPPool("pool1"))
+
+	// the addresses of the ippool are not allocated to unbound pods until the ippool is deleted
+	_, _, _, err := ctrl.ipam.GetRandomAddress("pod1.ns2", "pod1.ns2", "", "subnet1", "", nil, true)
+	require.ErrorIs(t, err, ipam.ErrNoAvailable)
+
+	require.NoError(t, ctrl.handleDeleteIPPool(pool))
+	require.Empty(t, ctrl.ipam.ListIPPools())
+	ip, _, _, err := ctrl.ipam.GetRandomAddress("pod1.ns2", "pod1.ns2", "", "subnet1", "", nil, true)
+	require.NoError(t, err)
+	require.Equal(t, "10.16.0.2", ip)
+}
+
+func Test_handleUpdateIPPoolStatus(t *testing.T) {
+	pool := newTestIPPool("pool1", "subnet1", []string{"10.16.0.10..10.16.0.19"}, []string{"ns1"}, nil)
+	ctrl := newFakeController(t, nil, []runtime.Object{pool})
+	require.NoError(t, ctrl.ipam.AddOrUpdateSubnet("subnet1", "10.16.0.0/24", "10.16.0.1", nil))
+
+	// ippools not set up in ipam are skipped
+	require.NoError(t, ctrl.handleUpdateIPPoolStatus("pool1"))
+
+	require.NoError(t, ctrl.ipam.AddOrUpdateIPPool("subnet1", "pool1", pool.Spec.IPs))
+	_, _, _, err := ctrl.ipam.GetRandomAddress("pod1.ns1", "pod1.ns1", "", "subnet1", "pool1", nil, true)
+	require.NoError(t, err)
+	require.NoError(t, ctrl.handleUpdateIPPoolStatus("pool1"))
+
+	updated, err := ctrl.kubeovnClient.KubeovnV1().IPPools().Get(context.Background(), "pool1", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.EqualValues(t, 9, updated.Status.V4AvailableIPs)
+	require.EqualValues(t, 1, updated.Status.V4UsingIPs)
+	require.Zero(t, updated.Status.V6AvailableIPs)
+	require.Zero(t, updated.Status.V6UsingIPs)
+}
+
+func Test_getPodIPPool(t *testing.T) {
+	pools := []runtime.Object{
+		newTestIPPool("pool-b", "subnet1", []string{"10.16.0.20..10.16.0.29"}, nil,
+			&metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}),
+		newTestIPPool("pool-a", "subnet1", []string{"10.16.0.10..10.16.0.19"}, []string{"ns1"}, nil),
+		newTestIPPool("pool-c", "subnet2", []string{"10.17.0.10..10.17.0.19"}, []string{"ns2"}, nil),
+		newTestIPPool("pool-d", "subnet1", []string{"10.16.0.30..10.16.0.39"}, nil, &metav1.LabelSelector{}),
+	}
+	ctrl := newFakeController(t, nil, pools)
+
+	newPod := func(namespace string, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: namespace, Labels: labels}}
+	}
+	for _, tc := range []struct {
+		name   string
+		pod    *corev1.Pod
+		subnet string
+		pool   string
+	}{
+		{"bound by namespace", newPod("ns1", nil), "subnet1", "pool-a"},
+		{"bound by selector", newPod("ns3", map[string]string{"app": "web"}), "subnet1", "pool-b"},
+		{"first ippool in name order", newPod("ns1", map[string]string{"app": "web"}), "subnet1", "pool-a"},
+		{"ippool of other subnet", newPod("ns2", nil), "subnet1", ""},
+		{"bound in the subnet", newPod("ns2", nil), "subnet2", "pool-c"},
+		{"empty selector binds nothing", newPod("ns3", nil), "subnet1", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pool, err := ctrl.getPodIPPool(tc.pod, tc.subnet)
+			require.NoError(t, err)
+			require.Equal(t, tc.pool, pool)
+		})
+	}
+}
+
+func Test_checkPodStaticIPPool(t *testing.T) {
+	pool := newTestIPPool("pool1", "subnet1", []string{"10.16.0.10..10.16.0.19"}, []string{"ns1"}, nil)
+	ctrl := newFakeController(t, nil, []runtime.Object{pool})
+	require.NoError(t, ctrl.ipam.AddOrUpdateSubnet("subnet1", "10.16.0.0/24", "10.16.0.1", nil))
+	require.NoError(t, ctrl.ipam.AddOrUpdateIPPool("subnet1", "pool1", pool.Spec.IPs))
+
+	bound := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "ns1"}}
+	unbound := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod2", Namespace: "ns2"}}
+	require.NoError(t, ctrl.checkPodStaticIPPool(bound, "10.16.0.10", "subnet1"))
+	require.NoError(t, ctrl.checkPodStaticIPPool(unbound, "10.16.0.20", "subnet1"))
+	require.ErrorIs(t, ctrl.checkPodStaticIPPool(unbound, "10.16.0.10", "subnet1"), ipam.ErrConflict)
+	require.ErrorIs(t, ctrl.checkPodStaticIPPool(unbound, "10.16.0.20,10.16.0.11", "subnet1"), ipam.ErrConflict)
+}
+
+func Test_enqueueUpdateIPPool(t *testing.T) {
+	ctrl := newFakeController(t, nil, nil)
+	becomeLeader(t, ctrl)
+
+	oldPool := newTestIPPool("pool1", "subnet1", []string{"10.16.0.10..10.16.0.19"}, []string{"ns1"}, nil)
+	newPool := oldPool.DeepCopy()
+	newPool.ResourceVersion = "2"
+
+	// status updates are skipped
+	ctrl.enqueueUpdateIPPool(oldPool, newPool)
+	require.Zero(t, ctrl.addOrUpdateIPPoolQueue.Len())
+	require.Zero(t, ctrl.deleteIPPoolQueue.Len())
+
+	newPool.Spec.IPs = []string{"10.16.0.10..10.16.0.29"}
+	ctrl.enqueueUpdateIPPool(oldPool, newPool)
+	require.Equal(t, 1, ctrl.addOrUpdateIPPoolQueue.Len())
+	require.Zero(t, ctrl.deleteIPPoolQueue.Len())
+
+	// the ranges in the old subnet are released when the subnet is changed
+	newPool.Spec.Subnet = "subnet2"
+	ctrl.enqueueUpdateIPPool(oldPool, newPool)
+	require.Equal(t, 1, ctrl.deleteIPPoolQueue.Len())
+	obj, _ := ctrl.deleteIPPoolQueue.Get()
+	require.Equal(t, oldPool, obj)
+	key, _ := ctrl.addOrUpdateIPPoolQueue.Get()
+	require.Equal(t, "pool1", key)
+}
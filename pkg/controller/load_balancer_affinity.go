@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// serviceLoadBalancer is the load balancer of the vpc which the vips of services are put on
+type serviceLoadBalancer struct {
+	name     string
+	protocol string
+	// the load balancers for session affinity and hashing are created on demand,
+	// the others are created together with the vpc
+	onDemand        bool
+	selectionFields []string
+	options         map[string]string
+}
+
+// fiveTupleSelectionFields selects the same backend of a connection on every node, the protocol is implied by the load balancer
+var fiveTupleSelectionFields = []string{
+	ovnnb.LoadBalancerSelectionFieldsIPSrc,
+	ovnnb.LoadBalancerSelectionFieldsIPDst,
+	ovnnb.LoadBalancerSelectionFieldsTpSrc,
+	ovnnb.LoadBalancerSelectionFieldsTpDst,
+}
+
+// validateServiceLbHash validates the value of the load balancer hash annotation
+func validateServiceLbHash(hash string) error {
+	switch hash {
+	case "", util.LbHashClientIP, util.LbHash5Tuple:
+		return nil
+	}
+	return fmt.Errorf("invalid load balancer hash %q, should be %s or %s", hash, util.LbHashClientIP, util.LbHash5Tuple)
+}
+
+// getServiceAffinityTimeout returns the affinity timeout of the service with ClientIP session affinity
+func getServiceAffinityTimeout(svc *v1.Service) int32 {
+	if config := svc.Spec.SessionAffinityConfig; config != nil && config.ClientIP != nil && config.ClientIP.TimeoutSeconds != nil {
+		return *config.ClientIP.TimeoutSeconds
+	}
+	return v1.DefaultClientIPServiceAffinitySeconds
+}
+
+// getServiceLoadBalancers returns the load balancers of the vpc for the tcp and udp ports of the service.
+// Services hashed on the client ip share the session load balancers, services hashed on the 5-tuple share the
+// load balancers selecting backends by the 5-tuple, and the others share the default load balancers. ClientIP
+// session affinity services share the load balancers with the same affinity timeout if affinityTimeout is true,
+// which is whether the running OVN supports the timeout, or the session load balancers otherwise. Invalid hashes
+// are ignored
+func getServiceLoadBalancers(svc *v1.Service, vpc *kubeovnv1.Vpc, affinityTimeout bool) (*serviceLoadBalancer, *serviceLoadBalancer) {
+	tcpLb := &serviceLoadBalancer{name: vpc.Status.TcpLoadBalancer, protocol: util.ProtocolTCP}
+	udpLb := &serviceLoadBalancer{name: vpc.Status.UdpLoadBalancer, protocol: util.ProtocolUDP}
+
+	hash := svc.Annotations[util.ServiceLbHashAnnotation]
+	switch {
+	case hash == util.LbHashClientIP:
+		tcpLb.name, udpLb.name = vpc.Status.TcpSessionLoadBalancer, vpc.Status.UdpSessionLoadBalancer
+	case hash == util.LbHash5Tuple:
+		for _, lb := range []*serviceLoadBalancer{tcpLb, udpLb} {
+			if lb.name != "" {
+				lb.name, lb.onDemand, lb.selectionFields = lb.name+"-5-tuple", true, fiveTupleSelectionFields
+			}
+		}
+	case svc.Spec.SessionAffinity == v1.ServiceAffinityClientIP && !affinityTimeout:
+		tcpLb.name, udpLb.name = vpc.Status.TcpSessionLoadBalancer, vpc.Status.UdpSessionLoadBalancer
+	case svc.Spec.SessionAffinity == v1.ServiceAffinityClientIP:
+		timeout := getServiceAffinityTimeout(svc)
+		for _, lb := range []*serviceLoadBalancer{tcpLb, udpLb} {
+			if lb.name != "" {
+				lb.name, lb.onDemand = fmt.Sprintf("%s-affinity-%d", lb.name, timeout), true
+				// the backend is selected by the client ip as well, which keeps the client on it across nodes
+				lb.selectionFields = []string{ovnnb.LoadBalancerSelectionFieldsIPSrc}
+				lb.options = map[string]string{"affinity_timeout": strconv.Itoa(int(timeout))}
+			}
+		}
+	}
+	return tcpLb, udpLb
+}
+
+// ensureServiceLoadBalancer creates the load balancer on demand and adds it to the logical switches of the vpc
+func (c *Controller) ensureServiceLoadBalancer(vpc *kubeovnv1.Vpc, lb *serviceLoadBalancer) error {
+	if !lb.onDemand {
+		return nil
+	}
+
+	externalIDs := map[string]string{"vendor": util.CniTypeName, "vpc": vpc.Name}
+	if err := c.ovnClient.CreateOrUpdateLoadBalancer(lb.name, lb.protocol, lb.selectionFields, lb.options, externalIDs); err != nil {
+		klog.Errorf("failed to create load balancer %s, %v", lb.name, err)
+		return err
+	}
+	ovnLb, err := c.ovnClient.GetLoadBalancer(lb.name, false)
+	if err != nil {
+		return err
+	}
+
+	for _, subnet := range vpc.Status.Subnets {
+		if subnet == c.config.NodeSwitch {
+			continue
+		}
+		ls, err := c.ovnClient.GetLogicalSwitch(subnet, true)
+		if err != nil {
+			return err
+		}
+		if ls == nil || util.ContainsString(ls.LoadBalancer, ovnLb.UUID) {
+			continue
+		}
+		if err = c.ovnClient.LogicalSwitchUpdateLoadBalancers(subnet, ovsdb.MutateOperationInsert, lb.name); err != nil {
+			klog.Errorf("failed to add load balancer %s to logical switch %s, %v", lb.name, subnet, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// getOnDemandLoadBalancers returns the load balancers of the vpc created on demand
+func (c *Controller) getOnDemandLoadBalancers(vpc string) ([]ovnnb.LoadBalancer, error) {
+	lbs, err := c.ovnClient.ListLoadBalancersByExternalIDs(map[string]string{"vendor": util.CniTypeName, "vpc": vpc})
+	if err != nil {
+		klog.Errorf("failed to list load balancers of vpc %s, %v", vpc, err)
+		return nil, err
+	}
+	return lbs, nil
+}
+
+// getVpcLoadBalancers returns the names of all load balancers of the vpc for the protocol of the service port
+func (c *Controller) getVpcLoadBalancers(vpc string, protocol v1.Protocol) ([]string, error) {
+	if vpc == "" {
+		vpc = util.DefaultVpc
+	}
+	vpcLb := c.GenVpcLoadBalancer(vpc)
+	names, lbProtocol := []string{vpcLb.TcpLoadBalancer, vpcLb.TcpSessLoadBalancer}, util.ProtocolTCP
+	if protocol != v1.ProtocolTCP {
+		names, lbProtocol = []string{vpcLb.UdpLoadBalancer, vpcLb.UdpSessLoadBalancer}, util.ProtocolUDP
+	}
+
+	lbs, err := c.getOnDemandLoadBalancers(vpc)
+	if err != nil {
+		return nil, err
+	}
+	for _, lb := range lbs {
+		if lb.Protocol != nil && *lb.Protocol == lbProtocol {
+			names = append(names, lb.Name)
+		}
+	}
+	return names, nil
+}
+
+// getServiceVips returns the vips of the service on the tcp and udp load balancers
+func getServiceVips(svc *v1.Service) (tcpVips, udpVips sets.String) {
+	var ips []string
+	if vip, ok := svc.Annotations[util.SwitchLBRuleVipsAnnotation]; ok {
+		ips = []string{vip}
+	} else {
+		ips = append(ips, svc.Spec.ClusterIPs...)
+		if len(ips) == 0 && svc.Spec.ClusterIP != "" {
+			ips = []string{svc.Spec.ClusterIP}
+		}
+		if len(ips) == 0 || ips[0] == v1.ClusterIPNone {
+			ips = nil
+		}
+	}
+	if isLbIpamService(svc) {
+		ips = append(ips, lbIpamIPs(svc)...)
+	}
+
+	tcpVips, udpVips = sets.NewString(), sets.NewString()
+	for _, ip := range ips {
+		for _, port := range svc.Spec.Ports {
+			if port.Protocol == v1.ProtocolTCP {
+				tcpVips.Insert(util.JoinHostPort(ip, port.Port))
+			} else {
+				udpVips.Insert(util.JoinHostPort(ip, port.Port))
+			}
+		}
+	}
+	return tcpVips, udpVips
+}
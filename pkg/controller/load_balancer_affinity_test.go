@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func Test_getServiceLoadBalancers(t *testing.T) {
+	vpc := &kubeovnv1.Vpc{
+		ObjectMeta: metav1.ObjectMeta{Name: util.DefaultVpc},
+		Status: kubeovnv1.VpcStatus{
+			TcpLoadBalancer:        "cluster-tcp-loadbalancer",
+			TcpSessionLoadBalancer: "cluster-tcp-session-loadbalancer",
+			UdpLoadBalancer:        "cluster-udp-loadbalancer",
+			UdpSessionLoadBalancer: "cluster-udp-session-loadbalancer",
+		},
+	}
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+
+	tcpLb, udpLb := getServiceLoadBalancers(svc, vpc, true)
+	require.Equal(t, &serviceLoadBalancer{name: "cluster-tcp-loadbalancer", protocol: util.ProtocolTCP}, tcpLb)
+	require.Equal(t, &serviceLoadBalancer{name: "cluster-udp-loadbalancer", protocol: util.ProtocolUDP}, udpLb)
+
+	// the load balancers are shared by the services with the same affinity timeout
+	svc.Spec.SessionAffinity = corev1.ServiceAffinityClientIP
+	tcpLb, _ = getServiceLoadBalancers(svc, vpc, true)
+	require.Equal(t, "cluster-tcp-loadbalancer-affinity-10800", tcpLb.name)
+	require.True(t, tcpLb.onDemand)
+	require.Equal(t, map[string]string{"affinity_timeout": "10800"}, tcpLb.options)
+	require.Equal(t, []string{"ip_src"}, tcpLb.selectionFields)
+
+	// the session load balancers are used if the running ovn ignores the affinity timeout
+	tcpLb, udpLb = getServiceLoadBalancers(svc, vpc, false)
+	require.Equal(t, &serviceLoadBalancer{name: "cluster-tcp-session-loadbalancer", protocol: util.ProtocolTCP}, tcpLb)
+	require.Equal(t, &serviceLoadBalancer{name: "cluster-udp-session-loadbalancer", protocol: util.ProtocolUDP}, udpLb)
+
+	timeout := int32(60)
+	svc.Spec.SessionAffinityConfig = &corev1.SessionAffinityConfig{ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: &timeout}}
+	_, udpLb = getServiceLoadBalancers(svc, vpc, true)
+	require.Equal(t, "cluster-udp-loadbalancer-affinity-60", udpLb.name)
+	require.Equal(t, map[string]string{"affinity_timeout": "60"}, udpLb.options)
+
+	// the hash annotation takes precedence over the session affinity
+	svc.Annotations = map[string]string{util.ServiceLbHashAnnotation: util.LbHashClientIP}
+	tcpLb, udpLb = getServiceLoadBalancers(svc, vpc, true)
+	require.Equal(t, "cluster-tcp-session-loadbalancer", tcpLb.name)
+	require.Equal(t, "cluster-udp-session-loadbalancer", udpLb.name)
+	require.False(t, tcpLb.onDemand)
+
+	svc.Annotations[util.ServiceLbHashAnnotation] = util.LbHash5Tuple
+	tcpLb, _ = getServiceLoadBalancers(svc, vpc, true)
+	require.Equal(t, "cluster-tcp-loadbalancer-5-tuple", tcpLb.name)
+	require.Equal(t, fiveTupleSelectionFields, tcpLb.selectionFields)
+	require.Empty(t, tcpLb.options)
+
+	require.NoError(t, validateServiceLbHash(""))
+	require.NoError(t, validateServiceLbHash(util.LbHash5Tuple))
+	require.Error(t, validateServiceLbHash("src_ip"))
+}
+
+// affinityTimeoutNbClient is the NB client of an ovn release supporting the affinity timeout of load balancers
+type affinityTimeoutNbClient struct {
+	ovs.NbClient
+}
+
+func (affinityTimeoutNbClient) LoadBalancerAffinityTimeoutSupported() bool {
+	return true
+}
+
+func Test_handleUpdateEndpointSessionAffinity(t *testing.T) {
+	timeout := int32(60)
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "default",
+			Annotations: map[string]string{util.VpcAnnotation: util.DefaultVpc},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP:             "10.96.0.10",
+			ClusterIPs:            []string{"10.96.0.10"},
+			Ports:                 []corev1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}},
+			SessionAffinity:       corev1.ServiceAffinityClientIP,
+			SessionAffinityConfig: &corev1.SessionAffinityConfig{ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: &timeout}},
+		},
+	}
+	vpc := &kubeovnv1.Vpc{
+		ObjectMeta: metav1.ObjectMeta{Name: util.DefaultVpc},
+		Status: kubeovnv1.VpcStatus{
+			TcpLoadBalancer:        "cluster-tcp-loadbalancer",
+			TcpSessionLoadBalancer: "cluster-tcp-session-loadbalancer",
+			UdpLoadBalancer:        "cluster-udp-loadbalancer",
+			UdpSessionLoadBalancer: "cluster-udp-session-loadbalancer",
+			Subnets:                []string{util.DefaultSubnet},
+		},
+	}
+	slice := newEndpointSlice("web-1", discoveryv1.AddressTypeIPv4, 8080, newEndpoint("10.16.0.2", true, true, false))
+	ctrl := newFakeController(t, []runtime.Object{svc, slice}, []runtime.Object{vpc})
+	require.False(t, ctrl.ovnClient.LoadBalancerAffinityTimeoutSupported())
+	ctrl.ovnClient = affinityTimeoutNbClient{ctrl.ovnClient}
+	require.NoError(t, ctrl.legacyClient.CreateLogicalSwitch(util.DefaultSubnet, util.DefaultVpc, "10.16.0.0/16", "10.16.0.1", false))
+	for _, lb := range []string{vpc.Status.TcpLoadBalancer, vpc.Status.TcpSessionLoadBalancer} {
+		require.NoError(t, ctrl.ovnClient.CreateLoadBalancer(lb, "tcp", ""))
+	}
+	for _, lb := range []string{vpc.Status.UdpLoadBalancer, vpc.Status.UdpSessionLoadBalancer} {
+		require.NoError(t, ctrl.ovnClient.CreateLoadBalancer(lb, "udp", ""))
+	}
+
+	// the load balancer of the affinity timeout is created and added to the subnets of the vpc
+	affinityLb := "cluster-tcp-loadbalancer-affinity-60"
+	require.NoError(t, ctrl.handleUpdateEndpoint("default/web"))
+	lb, err := ctrl.ovnClient.GetLoadBalancer(affinityLb, false)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"affinity_timeout": "60"}, lb.Options)
+	require.Equal(t, []string{"ip_src"}, lb.SelectionFields)
+	require.Equal(t, map[string]string{"10.96.0.10:80": "10.16.0.2:8080"}, lb.Vips)
+	ls, err := ctrl.ovnClient.GetLogicalSwitch(util.DefaultSubnet, false)
+	require.NoError(t, err)
+	require.Contains(t, ls.LoadBalancer, lb.UUID)
+
+	// the vip is moved to the 5-tuple load balancer once the hash is changed
+	svc = svc.DeepCopy()
+	svc.Annotations[util.ServiceLbHashAnnotation] = util.LbHash5Tuple
+	require.NoError(t, ctrl.informerFactory.Core().V1().Services().Informer().GetIndexer().Update(svc))
+	require.NoError(t, ctrl.handleUpdateEndpoint("default/web"))
+	require.NoError(t, ctrl.handleUpdateService("default/web"))
+	lb, err = ctrl.ovnClient.GetLoadBalancer("cluster-tcp-loadbalancer-5-tuple", false)
+	require.NoError(t, err)
+	require.ElementsMatch(t, fiveTupleSelectionFields, lb.SelectionFields)
+	require.Equal(t, map[string]string{"10.96.0.10:80": "10.16.0.2:8080"}, lb.Vips)
+	vips, err := ctrl.ovnClient.GetLoadBalancerVips(affinityLb)
+	require.NoError(t, err)
+	require.Empty(t, vips)
+
+	// the load balancer without vips is destroyed
+	require.NoError(t, ctrl.gcLoadBalancer())
+	lb, err = ctrl.ovnClient.GetLoadBalancer(affinityLb, true)
+	require.NoError(t, err)
+	require.Nil(t, lb)
+	lb, err = ctrl.ovnClient.GetLoadBalancer("cluster-tcp-loadbalancer-5-tuple", false)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"10.96.0.10:80": "10.16.0.2:8080"}, lb.Vips)
+}
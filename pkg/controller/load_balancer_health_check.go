@@ -0,0 +1,291 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+const (
+	healthCheckInterval     = "interval"
+	healthCheckTimeout      = "timeout"
+	healthCheckSuccessCount = "success_count"
+	healthCheckFailureCount = "failure_count"
+
+	backendStatusUnknown = "unknown"
+)
+
+// parseServiceHealthCheck returns the options of the load balancer health check configured by the annotation,
+// nil if the health check is disabled. The annotation is true to use the defaults of ovn,
+// or the comma separated options like interval=5,timeout=20,success_count=3,failure_count=3
+func parseServiceHealthCheck(value string) (map[string]string, error) {
+	switch value {
+	case "", "false":
+		return nil, nil
+	case "true":
+		return map[string]string{}, nil
+	}
+
+	options := make(map[string]string)
+	for _, option := range strings.Split(value, ",") {
+		kv := strings.SplitN(strings.TrimSpace(option), "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid health check option %q", option)
+		}
+		switch kv[0] {
+		case healthCheckInterval, healthCheckTimeout, healthCheckSuccessCount, healthCheckFailureCount:
+		default:
+			return nil, fmt.Errorf("unknown health check option %q", kv[0])
+		}
+		if n, err := strconv.Atoi(kv[1]); err != nil || n <= 0 {
+			return nil, fmt.Errorf("health check option %s should be a positive integer", kv[0])
+		}
+		options[kv[0]] = kv[1]
+	}
+	return options, nil
+}
+
+// genSlrHealthCheckAnnotation returns the health check annotation of the headless service of the switch lb rule
+func genSlrHealthCheckAnnotation(hc *kubeovnv1.SlrHealthCheck) string {
+	if hc == nil {
+		return ""
+	}
+	var options []string
+	for _, option := range []struct {
+		key   string
+		value int32
+	}{
+		{healthCheckInterval, hc.Interval},
+		{healthCheckTimeout, hc.Timeout},
+		{healthCheckSuccessCount, hc.SuccessCount},
+		{healthCheckFailureCount, hc.FailureCount},
+	} {
+		if option.value > 0 {
+			options = append(options, fmt.Sprintf("%s=%d", option.key, option.value))
+		}
+	}
+	if len(options) == 0 {
+		return "true"
+	}
+	return strings.Join(options, ",")
+}
+
+// healthCheckVipName returns the name of the vip used as the source ip of the health checks in the subnet
+func healthCheckVipName(subnet string) string {
+	return fmt.Sprintf("%s-health-check", subnet)
+}
+
+// getHealthCheckSourceIP returns the source ip of the health checks in the subnet,
+// the vip holding the ip is created if not found and an error is returned until the ip is allocated
+func (c *Controller) getHealthCheckSourceIP(subnet string, isIPv6 bool) (string, error) {
+	name := healthCheckVipName(subnet)
+	vip, err := c.virtualIpsLister.Get(name)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			klog.Errorf("failed to get vip %s, %v", name, err)
+			return "", err
+		}
+		// the reserved label makes the address recovered by ipam on restart
+		vip = &kubeovnv1.Vip{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{util.SubnetNameLabel: subnet, util.IpReservedLabel: ""},
+			},
+			Spec: kubeovnv1.VipSpec{Subnet: subnet},
+		}
+		if _, err = c.config.KubeOvnClient.KubeovnV1().Vips().Create(context.Background(), vip, metav1.CreateOptions{}); err != nil && !k8serrors.IsAlreadyExists(err) {
+			klog.Errorf("failed to create vip %s, %v", name, err)
+			return "", err
+		}
+		return "", fmt.Errorf("waiting for the health check source ip of subnet %s to be allocated", subnet)
+	}
+
+	ip := vip.Status.V4ip
+	if isIPv6 {
+		ip = vip.Status.V6ip
+	}
+	if ip == "" {
+		return "", fmt.Errorf("waiting for the health check source ip of subnet %s to be allocated", subnet)
+	}
+	return ip, nil
+}
+
+// deleteHealthCheckVip deletes the vip used as the source ip of the health checks in the subnet
+func (c *Controller) deleteHealthCheckVip(subnet string) error {
+	name := healthCheckVipName(subnet)
+	if _, err := c.virtualIpsLister.Get(name); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if err := c.config.KubeOvnClient.KubeovnV1().Vips().Delete(context.Background(), name, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+		klog.Errorf("failed to delete vip %s, %v", name, err)
+		return err
+	}
+	return nil
+}
+
+// getHealthCheckIPPortMappings returns the ip port mappings of the load balancer for the pod endpoints,
+// which map the backend ip to the logical switch port of the pod and the source ip of the health checks
+func (c *Controller) getHealthCheckIPPortMappings(namespace string, slices []*discoveryv1.EndpointSlice) (map[string]string, error) {
+	ipAddressSuffix := strings.TrimPrefix(util.IpAddressAnnotationTemplate, "%s")
+	mappings := make(map[string]string)
+	for _, slice := range slices {
+		for _, endpoint := range slice.Endpoints {
+			if len(endpoint.Addresses) == 0 || endpoint.TargetRef == nil || endpoint.TargetRef.Kind != "Pod" {
+				continue
+			}
+			pod, err := c.podsLister.Pods(namespace).Get(endpoint.TargetRef.Name)
+			if err != nil {
+				if k8serrors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+
+			address := endpoint.Addresses[0]
+			for key, value := range pod.Annotations {
+				if !strings.HasSuffix(key, ipAddressSuffix) || !util.ContainsString(strings.Split(value, ","), address) {
+					continue
+				}
+				provider := strings.TrimSuffix(key, ipAddressSuffix)
+				subnet := pod.Annotations[fmt.Sprintf(util.LogicalSwitchAnnotationTemplate, provider)]
+				if subnet == "" {
+					break
+				}
+				isIPv6 := util.CheckProtocol(address) == kubeovnv1.ProtocolIPv6
+				srcIP, err := c.getHealthCheckSourceIP(subnet, isIPv6)
+				if err != nil {
+					return nil, err
+				}
+				portName := ovs.PodNameToPortName(c.getNameByPod(pod), pod.Namespace, provider)
+				if isIPv6 {
+					mappings[fmt.Sprintf("[%s]", address)] = fmt.Sprintf("%s:[%s]", portName, srcIP)
+				} else {
+					mappings[address] = fmt.Sprintf("%s:%s", portName, srcIP)
+				}
+				break
+			}
+		}
+	}
+	return mappings, nil
+}
+
+// syncSwitchLBRuleBackendStatus reports the health status of the backends of the switch lb rules
+func (c *Controller) syncSwitchLBRuleBackendStatus() {
+	slrs, err := c.switchLBRuleLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list switch lb rules, %v", err)
+		return
+	}
+
+	var monitors map[string]string
+	for _, slr := range slrs {
+		var backends []kubeovnv1.SlrBackendStatus
+		if slr.Spec.HealthCheck != nil {
+			if monitors == nil {
+				serviceMonitors, err := c.ovnLegacyClient.ListServiceMonitors()
+				if err != nil {
+					klog.Errorf("failed to list service monitors, %v", err)
+					return
+				}
+				monitors = make(map[string]string, len(serviceMonitors))
+				for _, m := range serviceMonitors {
+					monitors[fmt.Sprintf("%s/%s", m.Protocol, util.JoinHostPort(m.IP, m.Port))] = m.Status
+				}
+			}
+			if backends, err = c.getSwitchLBRuleBackendStatus(slr, monitors); err != nil {
+				klog.Errorf("failed to get backend status of switch lb rule %s, %v", slr.Name, err)
+				continue
+			}
+		}
+		if len(backends) == 0 && len(slr.Status.Backends) == 0 || reflect.DeepEqual(backends, slr.Status.Backends) {
+			continue
+		}
+
+		patch := map[string]interface{}{"status": map[string]interface{}{"backends": backends}}
+		bytes, err := json.Marshal(patch)
+		if err != nil {
+			klog.Errorf("failed to marshal backend status of switch lb rule %s, %v", slr.Name, err)
+			continue
+		}
+		if _, err = c.config.KubeOvnClient.KubeovnV1().SwitchLBRules().Patch(context.Background(), slr.Name, types.MergePatchType, bytes, metav1.PatchOptions{}, "status"); err != nil {
+			klog.Errorf("failed to patch backend status of switch lb rule %s, %v", slr.Name, err)
+		}
+	}
+}
+
+// getSwitchLBRuleBackendStatus returns the backends of the switch lb rule in the load balancers
+// with the status of the service monitors keyed by protocol/ip:port
+func (c *Controller) getSwitchLBRuleBackendStatus(slr *kubeovnv1.SwitchLBRule, monitors map[string]string) ([]kubeovnv1.SlrBackendStatus, error) {
+	svc, err := c.servicesLister.Services(slr.Spec.Namespace).Get(genSvcName(slr.Name))
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	vpcName := svc.Annotations[util.VpcAnnotation]
+	if vpcName == "" {
+		return nil, nil
+	}
+	vpc, err := c.vpcsLister.Get(vpcName)
+	if err != nil {
+		return nil, err
+	}
+
+	tcpServiceLb, udpServiceLb := getServiceLoadBalancers(svc, vpc, c.ovnClient.LoadBalancerAffinityTimeoutSupported())
+	tcpLb, udpLb := tcpServiceLb.name, udpServiceLb.name
+
+	var backends []kubeovnv1.SlrBackendStatus
+	for _, port := range svc.Spec.Ports {
+		lb := tcpLb
+		if port.Protocol != v1.ProtocolTCP {
+			lb = udpLb
+		}
+		vips, err := c.ovnClient.GetLoadBalancerVips(lb)
+		if err != nil {
+			return nil, err
+		}
+
+		protocol := strings.ToLower(string(port.Protocol))
+		for _, backend := range strings.Split(vips[util.JoinHostPort(slr.Spec.Vip, port.Port)], ",") {
+			host, portStr, err := net.SplitHostPort(backend)
+			if err != nil {
+				continue
+			}
+			backendPort, err := strconv.ParseInt(portStr, 10, 32)
+			if err != nil {
+				continue
+			}
+			status := monitors[fmt.Sprintf("%s/%s", protocol, backend)]
+			if status == "" {
+				status = backendStatusUnknown
+			}
+			backends = append(backends, kubeovnv1.SlrBackendStatus{
+				IP:       host,
+				Port:     int32(backendPort),
+				Protocol: protocol,
+				Status:   status,
+			})
+		}
+	}
+	return backends, nil
+}
@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func Test_parseServiceHealthCheck(t *testing.T) {
+	options, err := parseServiceHealthCheck("")
+	require.NoError(t, err)
+	require.Nil(t, options)
+
+	options, err = parseServiceHealthCheck("true")
+	require.NoError(t, err)
+	require.NotNil(t, options)
+	require.Empty(t, options)
+
+	options, err = parseServiceHealthCheck("interval=5, failure_count=3")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"interval": "5", "failure_count": "3"}, options)
+
+	for _, value := range []string{"interval", "interval=0", "timeout=1s", "retries=3"} {
+		_, err = parseServiceHealthCheck(value)
+		require.Error(t, err, value)
+	}
+
+	require.Empty(t, genSlrHealthCheckAnnotation(nil))
+	require.Equal(t, "true", genSlrHealthCheckAnnotation(&kubeovnv1.SlrHealthCheck{}))
+	hc := &kubeovnv1.SlrHealthCheck{Interval: 5, FailureCount: 3}
+	require.Equal(t, "interval=5,failure_count=3", genSlrHealthCheckAnnotation(hc))
+	options, err = parseServiceHealthCheck(genSlrHealthCheckAnnotation(hc))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"interval": "5", "failure_count": "3"}, options)
+}
+
+func Test_handleUpdateEndpointHealthCheck(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: "default",
+			Annotations: map[string]string{
+				util.VpcAnnotation:                util.DefaultVpc,
+				util.ServiceHealthCheckAnnotation: "interval=5",
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP:  "10.96.0.10",
+			ClusterIPs: []string{"10.96.0.10"},
+			Ports:      []corev1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web-0",
+			Namespace: "default",
+			Annotations: map[string]string{
+				util.IpAddressAnnotation:     "10.16.0.2",
+				util.LogicalSwitchAnnotation: util.DefaultSubnet,
+			},
+		},
+	}
+	vpc := &kubeovnv1.Vpc{
+		ObjectMeta: metav1.ObjectMeta{Name: util.DefaultVpc},
+		Status: kubeovnv1.VpcStatus{
+			TcpLoadBalancer: "cluster-tcp-loadbalancer",
+			UdpLoadBalancer: "cluster-udp-loadbalancer",
+		},
+	}
+	endpoint := newEndpoint("10.16.0.2", true, true, false)
+	endpoint.TargetRef = &corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: pod.Name}
+	slice := newEndpointSlice("web-1", discoveryv1.AddressTypeIPv4, 8080, endpoint)
+
+	ctrl := newFakeController(t, []runtime.Object{svc, pod, slice}, []runtime.Object{vpc})
+	lb := vpc.Status.TcpLoadBalancer
+	require.NoError(t, ctrl.ovnClient.CreateLoadBalancer(lb, "tcp", ""))
+	require.NoError(t, ctrl.ovnClient.CreateLoadBalancer(vpc.Status.UdpLoadBalancer, "udp", ""))
+
+	// the vip is added while the source ip of the subnet is being allocated
+	require.Error(t, ctrl.handleUpdateEndpoint("default/web"))
+	vips, err := ctrl.ovnClient.GetLoadBalancerVips(lb)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"10.96.0.10:80": "10.16.0.2:8080"}, vips)
+	vip, err := ctrl.kubeovnClient.KubeovnV1().Vips().Get(context.Background(), healthCheckVipName(util.DefaultSubnet), metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, util.DefaultSubnet, vip.Spec.Subnet)
+
+	vip.Status.V4ip = "10.16.0.254"
+	require.NoError(t, ctrl.kubeovnInformerFactory.Kubeovn().V1().Vips().Informer().GetIndexer().Add(vip))
+	require.NoError(t, ctrl.handleUpdateEndpoint("default/web"))
+	hc, err := ctrl.ovnClient.GetLoadBalancerHealthCheck(lb, "10.96.0.10:80")
+	require.NoError(t, err)
+	require.NotNil(t, hc)
+	require.Equal(t, map[string]string{"interval": "5"}, hc.Options)
+	ovnLb, err := ctrl.ovnClient.GetLoadBalancer(lb, false)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"10.16.0.2": "web-0.default:10.16.0.254"}, ovnLb.IPPortMappings)
+
+	// the health check and the mappings are removed when disabled
+	svc = svc.DeepCopy()
+	delete(svc.Annotations, util.ServiceHealthCheckAnnotation)
+	require.NoError(t, ctrl.informerFactory.Core().V1().Services().Informer().GetIndexer().Update(svc))
+	require.NoError(t, ctrl.handleUpdateEndpoint("default/web"))
+	hc, err = ctrl.ovnClient.GetLoadBalancerHealthCheck(lb, "10.96.0.10:80")
+	require.NoError(t, err)
+	require.Nil(t, hc)
+	ovnLb, err = ctrl.ovnClient.GetLoadBalancer(lb, false)
+	require.NoError(t, err)
+	require.Empty(t, ovnLb.IPPortMappings)
+	require.Empty(t, ovnLb.HealthCheck)
+}
+
+func Test_syncSwitchLBRuleBackendStatus(t *testing.T) {
+	slr := &kubeovnv1.SwitchLBRule{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: kubeovnv1.SwitchLBRuleSpec{
+			Vip:         "10.17.0.10",
+			Namespace:   "default",
+			Ports:       []kubeovnv1.SlrPort{{Name: "http", Port: 80, TargetPort: 8080, Protocol: "TCP"}},
+			HealthCheck: &kubeovnv1.SlrHealthCheck{},
+		},
+	}
+	svc := genHeadlessService(slr, nil)
+	require.Equal(t, "true", svc.Annotations[util.ServiceHealthCheckAnnotation])
+	svc.Annotations[util.VpcAnnotation] = "vpc1"
+	vpc := &kubeovnv1.Vpc{
+		ObjectMeta: metav1.ObjectMeta{Name: "vpc1"},
+		Status: kubeovnv1.VpcStatus{
+			TcpLoadBalancer: "vpc1-tcp-load",
+			UdpLoadBalancer: "vpc1-udp-load",
+		},
+	}
+
+	ctrl := newFakeController(t, []runtime.Object{svc}, []runtime.Object{vpc})
+	require.NoError(t, ctrl.kubeovnInformerFactory.Kubeovn().V1().SwitchLBRules().Informer().GetIndexer().Add(slr))
+	_, err := ctrl.kubeovnClient.KubeovnV1().SwitchLBRules().Create(context.Background(), slr, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, ctrl.ovnClient.CreateLoadBalancer(vpc.Status.TcpLoadBalancer, "tcp", ""))
+	require.NoError(t, ctrl.ovnClient.LoadBalancerAddVip(vpc.Status.TcpLoadBalancer, "10.17.0.10:80", "10.17.0.2:8080", "10.17.0.3:8080"))
+	ctrl.legacyClient.ServiceMonitors = []ovs.ServiceMonitor{
+		{IP: "10.17.0.2", Port: 8080, Protocol: "tcp", LogicalPort: "web-0.default", Status: "online"},
+		{IP: "10.17.0.3", Port: 8080, Protocol: "udp", LogicalPort: "web-1.default", Status: "offline"},
+	}
+
+	ctrl.syncSwitchLBRuleBackendStatus()
+	slr, err = ctrl.kubeovnClient.KubeovnV1().SwitchLBRules().Get(context.Background(), slr.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []kubeovnv1.SlrBackendStatus{
+		{IP: "10.17.0.2", Port: 8080, Protocol: "tcp", Status: "online"},
+		{IP: "10.17.0.3", Port: 8080, Protocol: "tcp", Status: backendStatusUnknown},
+	}, slr.Status.Backends)
+}
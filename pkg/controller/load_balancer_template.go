@@ -0,0 +1,216 @@
+package controller
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+const (
+	templateVipVarPrefix      = "VIP"
+	templateBackendsVarPrefix = "BACKENDS"
+)
+
+// templateVarNameReplacer keeps the template variable names to letters, digits and underscores
+var templateVarNameReplacer = strings.NewReplacer(".", "_", ":", "_", "[", "", "]", "")
+
+// topologyAwareHintsEnabled returns whether the service prefers the backends hinted for the zone of the client node
+func topologyAwareHintsEnabled(svc *v1.Service) bool {
+	switch svc.Annotations[v1.AnnotationTopologyAwareHints] {
+	case "auto", "Auto":
+		return true
+	}
+	return false
+}
+
+// internalTrafficLocal returns whether the service routes the internal traffic to the endpoints on the node of the client only
+func internalTrafficLocal(svc *v1.Service) bool {
+	return svc.Spec.InternalTrafficPolicy != nil && *svc.Spec.InternalTrafficPolicy == v1.ServiceInternalTrafficPolicyLocal
+}
+
+// externalTrafficLocal returns whether the service routes the external traffic to the endpoints on the node it enters
+func externalTrafficLocal(svc *v1.Service) bool {
+	return svc.Spec.ExternalTrafficPolicy == v1.ServiceExternalTrafficPolicyTypeLocal
+}
+
+// vipTemplateScoped returns whether the vips of the service ip are put on the template load balancers, whose
+// backends are selected by the chassis of the client. The logical switches span all nodes, so only the chassis
+// template variables of OVN 22.12 and later can give the clients on different nodes different backends.
+// The ingress ips are not affected by the internal traffic policy
+func (c *Controller) vipTemplateScoped(svc *v1.Service, ip string) bool {
+	if !c.ovnClient.ChassisTemplateVarSupported() {
+		return false
+	}
+	if internalTrafficLocal(svc) && !(isLbIpamService(svc) && util.ContainsString(lbIpamIPs(svc), ip)) {
+		return true
+	}
+	return topologyAwareHintsEnabled(svc)
+}
+
+// vipAddressFamily returns the address family of the vip in the options of the template load balancers
+func vipAddressFamily(ip string) string {
+	if util.CheckProtocol(ip) == kubeovnv1.ProtocolIPv6 {
+		return "ipv6"
+	}
+	return "ipv4"
+}
+
+// templateLoadBalancer returns the template load balancer of the address family created on demand next to the
+// load balancer of the service, the template load balancers support a single address family each
+func templateLoadBalancer(lb *serviceLoadBalancer, family string) *serviceLoadBalancer {
+	options := make(map[string]string, len(lb.options)+2)
+	for k, v := range lb.options {
+		options[k] = v
+	}
+	options["template"] = "true"
+	options["address-family"] = family
+	return &serviceLoadBalancer{
+		name:            fmt.Sprintf("%s-template-%s", lb.name, family),
+		protocol:        lb.protocol,
+		onDemand:        true,
+		selectionFields: lb.selectionFields,
+		options:         options,
+	}
+}
+
+// isTemplateLoadBalancer returns whether the load balancer is a template load balancer
+func isTemplateLoadBalancer(lb string) bool {
+	return strings.HasSuffix(lb, "-template-ipv4") || strings.HasSuffix(lb, "-template-ipv6")
+}
+
+// templateVarName returns the name of the template variable of the vip
+func templateVarName(prefix, vip string, protocol v1.Protocol) string {
+	return templateVarNameReplacer.Replace(fmt.Sprintf("%s_%s_%s", prefix, strings.ToUpper(string(protocol)), vip))
+}
+
+// templateVip returns the vip on the template load balancers, the address is a template variable
+// as the template load balancers do not parse the addresses, and the port is kept
+func templateVip(vip string, protocol v1.Protocol) string {
+	_, port, err := net.SplitHostPort(vip)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("^%s:%s", templateVarName(templateVipVarPrefix, vip, protocol), port)
+}
+
+// templateBackends returns the backends of the vip on the template load balancers
+func templateBackends(vip string, protocol v1.Protocol) string {
+	return "^" + templateVarName(templateBackendsVarPrefix, vip, protocol)
+}
+
+// loadBalancerVipKey returns the key of the vip in the vips of the load balancer
+func loadBalancerVipKey(lb, vip string, protocol v1.Protocol) string {
+	if isTemplateLoadBalancer(lb) {
+		return templateVip(vip, protocol)
+	}
+	return vip
+}
+
+// templateVars returns the values of the template variables of the vip by chassis,
+// the address is the same on all chassises while the backends are selected per chassis.
+// The variables are removed from all chassises if chassisBackends is nil
+func templateVars(vip string, protocol v1.Protocol, chassisBackends map[string]string) map[string]map[string]string {
+	var vipValues map[string]string
+	if chassisBackends != nil {
+		host, _, _ := net.SplitHostPort(vip)
+		vipValues = make(map[string]string, len(chassisBackends))
+		for chassis := range chassisBackends {
+			vipValues[chassis] = host
+		}
+	}
+	return map[string]map[string]string{
+		templateVarName(templateVipVarPrefix, vip, protocol):      vipValues,
+		templateVarName(templateBackendsVarPrefix, vip, protocol): chassisBackends,
+	}
+}
+
+// chassisZoneBackends returns the backends on the chassis of each node, which are the ones hinted for the zone of the node
+func chassisZoneBackends(nodes []*v1.Node, backends []serviceBackend) map[string]string {
+	chassisBackends := make(map[string]string, len(nodes))
+	for _, node := range nodes {
+		chassis := node.Annotations[util.ChassisAnnotation]
+		if chassis == "" {
+			continue
+		}
+		chassisBackends[chassis] = joinServiceBackends(filterZoneBackends(backends, node.Labels[v1.LabelTopologyZone]))
+	}
+	return chassisBackends
+}
+
+// chassisNodeBackends returns the backends on the chassis of each node, which are the ones on the node
+func chassisNodeBackends(nodes []*v1.Node, backends []serviceBackend) map[string]string {
+	chassisBackends := make(map[string]string, len(nodes))
+	for _, node := range nodes {
+		chassis := node.Annotations[util.ChassisAnnotation]
+		if chassis == "" {
+			continue
+		}
+		chassisBackends[chassis] = joinServiceBackends(filterNodeBackends(backends, []string{node.Name}))
+	}
+	return chassisBackends
+}
+
+// filterNodeBackends returns the backends on the nodes
+func filterNodeBackends(backends []serviceBackend, nodes []string) []serviceBackend {
+	var filtered []serviceBackend
+	for _, backend := range backends {
+		if util.ContainsString(nodes, backend.node) {
+			filtered = append(filtered, backend)
+		}
+	}
+	return filtered
+}
+
+// enqueueNodeScopedServices enqueues the services whose backends are selected by the node of the client,
+// or by the external gateway nodes for the external traffic
+func (c *Controller) enqueueNodeScopedServices() {
+	svcs, err := c.servicesLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list services, %v", err)
+		return
+	}
+	for _, svc := range svcs {
+		if topologyAwareHintsEnabled(svc) || internalTrafficLocal(svc) || (isLbIpamService(svc) && externalTrafficLocal(svc)) {
+			key := svc.Namespace + "/" + svc.Name
+			klog.V(3).Infof("enqueue update endpoint of service %s", key)
+			c.updateEndpointQueue.Add(key)
+		}
+	}
+}
+
+// addTemplateVip sets the template variables of the vip to the backends on each chassis, and adds the vip to the template load balancer
+func (c *Controller) addTemplateVip(vpc *kubeovnv1.Vpc, lb *serviceLoadBalancer, vip string, protocol v1.Protocol, chassisBackends map[string]string) error {
+	if err := c.ensureServiceLoadBalancer(vpc, lb); err != nil {
+		return err
+	}
+	// the variables are set before the vip refers to them
+	if err := c.ovnClient.UpdateChassisTemplateVars(templateVars(vip, protocol, chassisBackends)); err != nil {
+		klog.Errorf("failed to update template variables of vip %s, %v", vip, err)
+		return err
+	}
+	if err := c.ovnClient.LoadBalancerAddVip(lb.name, templateVip(vip, protocol), templateBackends(vip, protocol)); err != nil {
+		klog.Errorf("failed to add vip %s to lb %s, %v", vip, lb.name, err)
+		return err
+	}
+	return nil
+}
+
+// deleteTemplateVip deletes the vip from the template load balancer together with its template variables
+func (c *Controller) deleteTemplateVip(lb, vip string, protocol v1.Protocol) error {
+	if err := c.ovnClient.LoadBalancerDeleteVip(lb, templateVip(vip, protocol)); err != nil {
+		klog.Errorf("failed to delete vip %s from lb %s, %v", vip, lb, err)
+		return err
+	}
+	if err := c.ovnClient.UpdateChassisTemplateVars(templateVars(vip, protocol, nil)); err != nil {
+		klog.Errorf("failed to delete template variables of vip %s, %v", vip, err)
+		return err
+	}
+	return nil
+}
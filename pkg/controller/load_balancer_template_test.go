@@ -0,0 +1,213 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// templateNbClient is the NB client of an ovn release supporting the chassis template variables,
+// which are kept in memory as the table is not in the schema of the test server
+type templateNbClient struct {
+	ovs.NbClient
+	vars map[string]map[string]string
+}
+
+func (*templateNbClient) ChassisTemplateVarSupported() bool {
+	return true
+}
+
+func (c *templateNbClient) GetChassisTemplateVars() (map[string]map[string]string, error) {
+	return c.vars, nil
+}
+
+func (c *templateNbClient) UpdateChassisTemplateVars(vars map[string]map[string]string) error {
+	for name, values := range vars {
+		if len(values) == 0 {
+			delete(c.vars, name)
+		} else {
+			c.vars[name] = values
+		}
+	}
+	return nil
+}
+
+func Test_templateVip(t *testing.T) {
+	require.Equal(t, "^VIP_TCP_10_96_0_10_80:80", templateVip("10.96.0.10:80", corev1.ProtocolTCP))
+	require.Equal(t, "^VIP_UDP_fd00_10_96__10_53:53", templateVip("[fd00:10:96::10]:53", corev1.ProtocolUDP))
+	require.Equal(t, "^BACKENDS_TCP_10_96_0_10_80", templateBackends("10.96.0.10:80", corev1.ProtocolTCP))
+	require.Equal(t, "10.96.0.10:80", loadBalancerVipKey("cluster-tcp-loadbalancer", "10.96.0.10:80", corev1.ProtocolTCP))
+	require.Equal(t, "^VIP_TCP_10_96_0_10_80:80", loadBalancerVipKey("cluster-tcp-loadbalancer-template-ipv4", "10.96.0.10:80", corev1.ProtocolTCP))
+
+	lb := templateLoadBalancer(&serviceLoadBalancer{name: "cluster-tcp-loadbalancer", protocol: util.ProtocolTCP}, vipAddressFamily("fd00:10:96::10"))
+	require.Equal(t, "cluster-tcp-loadbalancer-template-ipv6", lb.name)
+	require.True(t, lb.onDemand)
+	require.Equal(t, map[string]string{"template": "true", "address-family": "ipv6"}, lb.options)
+	require.True(t, isTemplateLoadBalancer(lb.name))
+
+	// the address is the same on all chassises
+	vars := templateVars("10.96.0.10:80", corev1.ProtocolTCP, map[string]string{"chassis1": "10.16.0.2:8080", "chassis2": ""})
+	require.Equal(t, map[string]map[string]string{
+		"VIP_TCP_10_96_0_10_80":      {"chassis1": "10.96.0.10", "chassis2": "10.96.0.10"},
+		"BACKENDS_TCP_10_96_0_10_80": {"chassis1": "10.16.0.2:8080", "chassis2": ""},
+	}, vars)
+}
+
+func Test_handleUpdateEndpointTopologyAwareHints(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: "default",
+			Annotations: map[string]string{
+				util.VpcAnnotation:                  util.DefaultVpc,
+				corev1.AnnotationTopologyAwareHints: "auto",
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP:  "10.96.0.10",
+			ClusterIPs: []string{"10.96.0.10"},
+			Ports:      []corev1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}},
+		},
+	}
+	vpc := &kubeovnv1.Vpc{
+		ObjectMeta: metav1.ObjectMeta{Name: util.DefaultVpc},
+		Status: kubeovnv1.VpcStatus{
+			TcpLoadBalancer: "cluster-tcp-loadbalancer",
+			UdpLoadBalancer: "cluster-udp-loadbalancer",
+		},
+	}
+	newNode := func(name, zone string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      map[string]string{corev1.LabelTopologyZone: zone},
+			Annotations: map[string]string{util.ChassisAnnotation: "chassis-" + name},
+		}}
+	}
+	slice := newEndpointSlice("web-1", discoveryv1.AddressTypeIPv4, 8080,
+		newEndpoint("10.16.0.2", true, true, false, "zone-a"),
+		newEndpoint("10.16.0.3", true, true, false, "zone-b"),
+	)
+	ctrl := newFakeController(t, []runtime.Object{svc, slice, newNode("node1", "zone-a"), newNode("node2", "zone-b"), newNode("node3", "zone-c")}, []runtime.Object{vpc})
+	require.NoError(t, ctrl.ovnClient.CreateLoadBalancer(vpc.Status.TcpLoadBalancer, "tcp", ""))
+	require.NoError(t, ctrl.ovnClient.CreateLoadBalancer(vpc.Status.UdpLoadBalancer, "udp", ""))
+
+	// all endpoints are used unless the running ovn supports the chassis template variables
+	require.NoError(t, ctrl.handleUpdateEndpoint("default/web"))
+	vips, err := ctrl.ovnClient.GetLoadBalancerVips(vpc.Status.TcpLoadBalancer)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"10.96.0.10:80": "10.16.0.2:8080,10.16.0.3:8080"}, vips)
+
+	// the vip is moved to the template load balancer selecting the backends by the zone of the node
+	nbClient := &templateNbClient{NbClient: ctrl.ovnClient, vars: make(map[string]map[string]string)}
+	ctrl.ovnClient = nbClient
+	require.NoError(t, ctrl.handleUpdateEndpoint("default/web"))
+	vips, err = ctrl.ovnClient.GetLoadBalancerVips(vpc.Status.TcpLoadBalancer)
+	require.NoError(t, err)
+	require.Empty(t, vips)
+	templateLb, err := ctrl.ovnClient.GetLoadBalancer("cluster-tcp-loadbalancer-template-ipv4", false)
+	require.NoError(t, err)
+	require.Equal(t, "true", templateLb.Options["template"])
+	require.Equal(t, "ipv4", templateLb.Options["address-family"])
+	require.Equal(t, map[string]string{"^VIP_TCP_10_96_0_10_80:80": "^BACKENDS_TCP_10_96_0_10_80"}, templateLb.Vips)
+	require.Equal(t, map[string]map[string]string{
+		"VIP_TCP_10_96_0_10_80": {"chassis-node1": "10.96.0.10", "chassis-node2": "10.96.0.10", "chassis-node3": "10.96.0.10"},
+		"BACKENDS_TCP_10_96_0_10_80": {
+			"chassis-node1": "10.16.0.2:8080",
+			"chassis-node2": "10.16.0.3:8080",
+			"chassis-node3": "10.16.0.2:8080,10.16.0.3:8080",
+		},
+	}, nbClient.vars)
+
+	// the vip is moved back once the hints are disabled
+	svc = svc.DeepCopy()
+	delete(svc.Annotations, corev1.AnnotationTopologyAwareHints)
+	require.NoError(t, ctrl.informerFactory.Core().V1().Services().Informer().GetIndexer().Update(svc))
+	require.NoError(t, ctrl.handleUpdateEndpoint("default/web"))
+	vips, err = ctrl.ovnClient.GetLoadBalancerVips(vpc.Status.TcpLoadBalancer)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"10.96.0.10:80": "10.16.0.2:8080,10.16.0.3:8080"}, vips)
+	vips, err = ctrl.ovnClient.GetLoadBalancerVips(templateLb.Name)
+	require.NoError(t, err)
+	require.Empty(t, vips)
+	require.Empty(t, nbClient.vars)
+}
+
+func Test_handleUpdateEndpointInternalTrafficLocal(t *testing.T) {
+	local := corev1.ServiceInternalTrafficPolicyLocal
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "default",
+			Annotations: map[string]string{util.VpcAnnotation: "vpc1"},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP:             "10.96.0.10",
+			ClusterIPs:            []string{"10.96.0.10"},
+			Ports:                 []corev1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}},
+			InternalTrafficPolicy: &local,
+		},
+	}
+	vpc := &kubeovnv1.Vpc{
+		ObjectMeta: metav1.ObjectMeta{Name: "vpc1"},
+		Status: kubeovnv1.VpcStatus{
+			TcpLoadBalancer: "vpc-vpc1-tcp-load",
+			UdpLoadBalancer: "vpc-vpc1-udp-load",
+		},
+	}
+	newNode := func(name string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{util.ChassisAnnotation: "chassis-" + name},
+		}}
+	}
+	node1, node2 := "node1", "node2"
+	endpoint1, endpoint2 := newEndpoint("10.16.0.2", true, true, false), newEndpoint("10.16.0.3", true, true, false)
+	endpoint1.NodeName, endpoint2.NodeName = &node1, &node1
+	endpoint3 := newEndpoint("10.16.0.4", true, true, false)
+	endpoint3.NodeName = &node2
+	slice := newEndpointSlice("web-1", discoveryv1.AddressTypeIPv4, 8080, endpoint1, endpoint2, endpoint3)
+	ctrl := newFakeController(t, []runtime.Object{svc, slice, newNode(node1), newNode(node2), newNode("node3")}, []runtime.Object{vpc})
+	require.NoError(t, ctrl.ovnClient.CreateLoadBalancer(vpc.Status.TcpLoadBalancer, "tcp", ""))
+	require.NoError(t, ctrl.ovnClient.CreateLoadBalancer(vpc.Status.UdpLoadBalancer, "udp", ""))
+	nbClient := &templateNbClient{NbClient: ctrl.ovnClient, vars: make(map[string]map[string]string)}
+	ctrl.ovnClient = nbClient
+
+	// the vip is put on the template load balancer selecting the endpoints on the node of the client,
+	// which works in custom vpcs as well, and the clients on nodes without endpoints get no backends
+	require.NoError(t, ctrl.handleUpdateEndpoint("default/web"))
+	vips, err := ctrl.ovnClient.GetLoadBalancerVips(vpc.Status.TcpLoadBalancer)
+	require.NoError(t, err)
+	require.Empty(t, vips)
+	vips, err = ctrl.ovnClient.GetLoadBalancerVips("vpc-vpc1-tcp-load-template-ipv4")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"^VIP_TCP_10_96_0_10_80:80": "^BACKENDS_TCP_10_96_0_10_80"}, vips)
+	require.Equal(t, map[string]map[string]string{
+		"VIP_TCP_10_96_0_10_80": {"chassis-node1": "10.96.0.10", "chassis-node2": "10.96.0.10", "chassis-node3": "10.96.0.10"},
+		"BACKENDS_TCP_10_96_0_10_80": {
+			"chassis-node1": "10.16.0.2:8080,10.16.0.3:8080",
+			"chassis-node2": "10.16.0.4:8080",
+			"chassis-node3": "",
+		},
+	}, nbClient.vars)
+
+	// the vip is moved back once the policy is cluster
+	svc = svc.DeepCopy()
+	svc.Spec.InternalTrafficPolicy = nil
+	require.NoError(t, ctrl.informerFactory.Core().V1().Services().Informer().GetIndexer().Update(svc))
+	require.NoError(t, ctrl.handleUpdateEndpoint("default/web"))
+	vips, err = ctrl.ovnClient.GetLoadBalancerVips(vpc.Status.TcpLoadBalancer)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"10.96.0.10:80": "10.16.0.2:8080,10.16.0.3:8080,10.16.0.4:8080"}, vips)
+	vips, err = ctrl.ovnClient.GetLoadBalancerVips("vpc-vpc1-tcp-load-template-ipv4")
+	require.NoError(t, err)
+	require.Empty(t, vips)
+	require.Empty(t, nbClient.vars)
+}
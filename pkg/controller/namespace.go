@@ -27,6 +27,9 @@ func (c *Controller) enqueueAddNamespace(obj interface{}) {
 			c.updateNpQueue.Add(np)
 		}
 	}
+	if ns, ok := obj.(*v1.Namespace); ok {
+		c.enqueueSyncAnps(c.namespaceMatchAnps(ns)...)
+	}
 	var key string
 	var err error
 	if key, err = cache.MetaNamespaceKeyFunc(obj); err != nil {
@@ -46,6 +49,9 @@ func (c *Controller) enqueueDeleteNamespace(obj interface{}) {
 			c.updateNpQueue.Add(np)
 		}
 	}
+	if ns, ok := obj.(*v1.Namespace); ok {
+		c.enqueueSyncAnps(c.namespaceMatchAnps(ns)...)
+	}
 }
 
 func (c *Controller) enqueueUpdateNamespace(old, new interface{}) {
@@ -65,6 +71,9 @@ func (c *Controller) enqueueUpdateNamespace(old, new interface{}) {
 			c.updateNpQueue.Add(np)
 		}
 	}
+	if !reflect.DeepEqual(oldNs.Labels, newNs.Labels) {
+		c.enqueueSyncAnps(util.UniqString(append(c.namespaceMatchAnps(oldNs), c.namespaceMatchAnps(newNs)...))...)
+	}
 
 	// in case annotations are removed by other controllers
 	if newNs.Annotations == nil || newNs.Annotations[util.LogicalSwitchAnnotation] == "" {
@@ -3,6 +3,7 @@ package controller
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -11,12 +12,14 @@ import (
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
 	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
 	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
 	"github.com/kubeovn/kube-ovn/pkg/util"
 )
 
@@ -32,6 +35,7 @@ func (c *Controller) enqueueAddNp(obj interface{}) {
 	}
 	klog.V(3).Infof("enqueue add np %s", key)
 	c.updateNpQueue.Add(key)
+	c.enqueueSyncFqdns(npFqdns(obj.(*netv1.NetworkPolicy)))
 }
 
 func (c *Controller) enqueueDeleteNp(obj interface{}) {
@@ -46,6 +50,9 @@ func (c *Controller) enqueueDeleteNp(obj interface{}) {
 	}
 	klog.V(3).Infof("enqueue delete np %s", key)
 	c.deleteNpQueue.Add(key)
+	if np, ok := obj.(*netv1.NetworkPolicy); ok {
+		c.enqueueSyncFqdns(npFqdns(np))
+	}
 }
 
 func (c *Controller) enqueueUpdateNp(old, new interface{}) {
@@ -64,6 +71,7 @@ func (c *Controller) enqueueUpdateNp(old, new interface{}) {
 		}
 		klog.V(3).Infof("enqueue update np %s", key)
 		c.updateNpQueue.Add(key)
+		c.enqueueSyncFqdns(append(npFqdns(oldNp), npFqdns(newNp)...))
 	}
 }
 
@@ -182,6 +190,8 @@ func (c *Controller) handleUpdateNp(key string) error {
 	if np.Annotations[util.NetworkPolicyLogAnnotation] == "true" {
 		logEnable = true
 	}
+	// the policy is observed by the acl logs instead of enforced in audit mode
+	auditMode := np.Annotations[util.NetworkPolicyAuditAnnotation] == "true"
 
 	// TODO: ovn acl doesn't support address_set name with '-', now we replace '-' by '.'.
 	// This may cause conflict if two np with name test-np and test.np. Maybe hash is a better solution,
@@ -191,8 +201,10 @@ func (c *Controller) handleUpdateNp(key string) error {
 	ingressExceptAsNamePrefix := strings.Replace(fmt.Sprintf("%s.%s.ingress.except", np.Name, np.Namespace), "-", ".", -1)
 	egressAllowAsNamePrefix := strings.Replace(fmt.Sprintf("%s.%s.egress.allow", np.Name, np.Namespace), "-", ".", -1)
 	egressExceptAsNamePrefix := strings.Replace(fmt.Sprintf("%s.%s.egress.except", np.Name, np.Namespace), "-", ".", -1)
+	ingressNamedAsNamePrefix := strings.Replace(fmt.Sprintf("%s.%s.ingress.named", np.Name, np.Namespace), "-", ".", -1)
+	egressNamedAsNamePrefix := strings.Replace(fmt.Sprintf("%s.%s.egress.named", np.Name, np.Namespace), "-", ".", -1)
 
-	if err = c.ovnLegacyClient.CreateNpPortGroup(pgName, np.Namespace, np.Name); err != nil {
+	if err = c.ovnClient.CreatePortGroup(pgName, map[string]string{"np": key}); err != nil {
 		klog.Errorf("failed to create port group for np %s, %v", key, err)
 		return err
 	}
@@ -203,7 +215,7 @@ func (c *Controller) handleUpdateNp(key string) error {
 		return err
 	}
 
-	err = c.ovnLegacyClient.SetPortsToPortGroup(pgName, ports)
+	err = c.ovnClient.PortGroupSetPorts(pgName, ports)
 	if err != nil && !strings.Contains(err.Error(), "not found") {
 		klog.Errorf("failed to set port group, %v", err)
 		return err
@@ -225,45 +237,44 @@ func (c *Controller) handleUpdateNp(key string) error {
 			svcAsName = svcAsNameIPv6
 			svcIPs = svcIpv6s
 		}
-		if err = c.ovnLegacyClient.CreateNpAddressSet(svcAsName, np.Namespace, np.Name, "service"); err != nil {
+		if err = c.ovnClient.CreateNpAddressSet(svcAsName, np.Namespace, np.Name, "service"); err != nil {
 			klog.Errorf("failed to create address_set %s, %v", svcAsNameIPv4, err)
 			return err
 		}
-		if err = c.ovnLegacyClient.SetAddressesToAddressSet(svcIPs, svcAsName); err != nil {
+		if err = c.ovnClient.SetAddressesToAddressSet(svcIPs, svcAsName); err != nil {
 			klog.Errorf("failed to set netpol svc, %v", err)
 			return err
 		}
 	}
 
-	ingressAsNames, err := c.ovnLegacyClient.ListNpAddressSet(np.Namespace, np.Name, "ingress")
+	ingressAsNames, err := c.ovnClient.ListNpAddressSet(np.Namespace, np.Name, "ingress")
 	if err != nil {
 		klog.Errorf("failed to list ingress address_set, %v", err)
 		return err
 	}
 	for _, ingressAsName := range ingressAsNames {
-		if err = c.ovnLegacyClient.DeleteAddressSet(ingressAsName); err != nil {
+		if err = c.ovnClient.DeleteAddressSet(ingressAsName); err != nil {
 			klog.Errorf("failed to delete np %s address set, %v", key, err)
 			return err
 		}
 	}
 
-	var ingressAclCmd []string
-	exist, err := c.ovnLegacyClient.PortGroupExists(pgName)
-	if err != nil {
-		klog.Errorf("failed to query np %s port group, %v", key, err)
-		return err
-	}
-	if exist {
-		ingressAclCmd = []string{"--type=port-group", "acl-del", pgName, "to-lport"}
-	}
 	if hasIngressRule(np) {
+		// named ports of ingress rules are resolved by the pods selected by the policy
+		var sel labels.Selector
+		var selectedPods []*corev1.Pod
+		if sel, err = metav1.LabelSelectorAsSelector(&np.Spec.PodSelector); err != nil {
+			klog.Errorf("error creating label selector, %v", err)
+			return err
+		}
+		if selectedPods, err = c.podsLister.Pods(np.Namespace).List(sel); err != nil {
+			klog.Errorf("failed to list pods, %v", err)
+			return err
+		}
+
+		var ingressACLs []*ovnnb.ACL
 		for _, cidrBlock := range strings.Split(subnet.Spec.CIDRBlock, ",") {
 			protocol := util.CheckProtocol(cidrBlock)
-			svcAsName := svcAsNameIPv4
-			if protocol == kubeovnv1.ProtocolIPv6 {
-				svcAsName = svcAsNameIPv6
-			}
-
 			for idx, npr := range np.Spec.Ingress {
 				// A single address set must contain addresses of the same type and the name must be unique within table, so IPv4 and IPv6 address set should be different
 				ingressAllowAsName := fmt.Sprintf("%s.%s.%d", ingressAllowAsNamePrefix, protocol, idx)
@@ -288,60 +299,63 @@ func (c *Controller) handleUpdateNp(key string) error {
 					}
 				}
 				klog.Infof("UpdateNp Ingress, allows is %v, excepts is %v, log %v", allows, excepts, logEnable)
-				if err = c.ovnLegacyClient.CreateNpAddressSet(ingressAllowAsName, np.Namespace, np.Name, "ingress"); err != nil {
+				if err = c.ovnClient.CreateNpAddressSet(ingressAllowAsName, np.Namespace, np.Name, "ingress"); err != nil {
 					klog.Errorf("failed to create address_set %s, %v", ingressAllowAsName, err)
 					return err
 				}
-				if err = c.ovnLegacyClient.SetAddressesToAddressSet(allows, ingressAllowAsName); err != nil {
+				if err = c.ovnClient.SetAddressesToAddressSet(allows, ingressAllowAsName); err != nil {
 					klog.Errorf("failed to set ingress allow address_set, %v", err)
 					return err
 				}
 
-				if err = c.ovnLegacyClient.CreateNpAddressSet(ingressExceptAsName, np.Namespace, np.Name, "ingress"); err != nil {
+				if err = c.ovnClient.CreateNpAddressSet(ingressExceptAsName, np.Namespace, np.Name, "ingress"); err != nil {
 					klog.Errorf("failed to create address_set %s, %v", ingressExceptAsName, err)
 					return err
 				}
-				if err = c.ovnLegacyClient.SetAddressesToAddressSet(excepts, ingressExceptAsName); err != nil {
+				if err = c.ovnClient.SetAddressesToAddressSet(excepts, ingressExceptAsName); err != nil {
 					klog.Errorf("failed to set ingress except address_set, %v", err)
 					return err
 				}
 
+				var namedPorts map[string][]ovs.NamedPortInfo
+				if namedPorts, err = c.setNpNamedPortAddressSets(np, "ingress", ingressNamedAsNamePrefix, protocol, idx, npr.Ports, selectedPods); err != nil {
+					klog.Errorf("failed to set ingress named port address_set, %v", err)
+					return err
+				}
+
 				if len(allows) != 0 || len(excepts) != 0 {
-					ingressAclCmd = c.ovnLegacyClient.CombineIngressACLCmd(pgName, ingressAllowAsName, ingressExceptAsName, svcAsName, protocol, npr.Ports, logEnable, ingressAclCmd, idx)
+					ingressACLs = append(ingressACLs, ovs.NpIngressACLs(pgName, ingressAllowAsName, ingressExceptAsName, protocol, npr.Ports, namedPorts, logEnable, auditMode)...)
 				} else {
-					ingressAclCmd = c.ovnLegacyClient.CombineIngressACLCmd(pgName, ingressAllowAsName, ingressExceptAsName, svcAsName, protocol, []netv1.NetworkPolicyPort{}, logEnable, ingressAclCmd, idx)
+					ingressACLs = append(ingressACLs, ovs.NpIngressACLs(pgName, ingressAllowAsName, ingressExceptAsName, protocol, nil, nil, logEnable, auditMode)...)
 				}
 			}
 			if len(np.Spec.Ingress) == 0 {
 				ingressAllowAsName := fmt.Sprintf("%s.%s.all", ingressAllowAsNamePrefix, protocol)
 				ingressExceptAsName := fmt.Sprintf("%s.%s.all", ingressExceptAsNamePrefix, protocol)
-				if err = c.ovnLegacyClient.CreateNpAddressSet(ingressAllowAsName, np.Namespace, np.Name, "ingress"); err != nil {
+				if err = c.ovnClient.CreateNpAddressSet(ingressAllowAsName, np.Namespace, np.Name, "ingress"); err != nil {
 					klog.Errorf("failed to create address_set %s, %v", ingressAllowAsName, err)
 					return err
 				}
 
-				if err = c.ovnLegacyClient.CreateNpAddressSet(ingressExceptAsName, np.Namespace, np.Name, "ingress"); err != nil {
+				if err = c.ovnClient.CreateNpAddressSet(ingressExceptAsName, np.Namespace, np.Name, "ingress"); err != nil {
 					klog.Errorf("failed to create address_set %s, %v", ingressExceptAsName, err)
 					return err
 				}
-				ingressPorts := []netv1.NetworkPolicyPort{}
-				ingressAclCmd = c.ovnLegacyClient.CombineIngressACLCmd(pgName, ingressAllowAsName, ingressExceptAsName, svcAsName, protocol, ingressPorts, logEnable, ingressAclCmd, 0)
-			}
-
-			klog.Infof("create ingress acl cmd is: %v", ingressAclCmd)
-			if err = c.ovnLegacyClient.CreateACL(ingressAclCmd); err != nil {
-				klog.Errorf("failed to create ingress acls for np %s, %v", key, err)
-				return err
+				ingressACLs = append(ingressACLs, ovs.NpIngressACLs(pgName, ingressAllowAsName, ingressExceptAsName, protocol, nil, nil, logEnable, auditMode)...)
 			}
+		}
 
-			if err = c.ovnLegacyClient.SetAclLog(pgName, logEnable, true); err != nil {
-				// just log and do not return err here
-				klog.Errorf("failed to set ingress acl log for np %s, %v", key, err)
-			}
+		if err = c.ovnClient.UpdatePortGroupACLs(pgName, ovnnb.ACLDirectionToLport, ingressACLs...); err != nil {
+			klog.Errorf("failed to create ingress acls for np %s, %v", key, err)
+			return err
+		}
+		if err = c.ovnClient.SetAclLog(pgName, util.AclLogOwnerTypeNp, key, logEnable, true); err != nil {
+			// just log and do not return err here
+			klog.Errorf("failed to set ingress acl log for np %s, %v", key, err)
 		}
 
 		var asNames []string
-		if asNames, err = c.ovnLegacyClient.ListNpAddressSet(np.Namespace, np.Name, "ingress"); err != nil {
+		if asNames, err = c.ovnClient.ListNpAddressSet(np.Namespace, np.Name, "ingress"); err != nil {
 			klog.Errorf("failed to list address_set, %v", err)
 			return err
 		}
@@ -357,60 +371,55 @@ func (c *Controller) handleUpdateNp(key string) error {
 			}
 			idx, _ := strconv.Atoi(idxStr)
 			if idx >= len(np.Spec.Ingress) {
-				if err = c.ovnLegacyClient.DeleteAddressSet(asName); err != nil {
+				if err = c.ovnClient.DeleteAddressSet(asName); err != nil {
 					klog.Errorf("failed to delete np %s address set, %v", key, err)
 					return err
 				}
 			}
 		}
 	} else {
-		if err = c.ovnLegacyClient.DeleteACL(pgName, "to-lport"); err != nil {
+		if err = c.ovnClient.DeletePortGroupACLs(pgName, ovnnb.ACLDirectionToLport); err != nil {
 			klog.Errorf("failed to delete np %s ingress acls, %v", key, err)
 			return err
 		}
 
-		asNames, err := c.ovnLegacyClient.ListNpAddressSet(np.Namespace, np.Name, "ingress")
+		asNames, err := c.ovnClient.ListNpAddressSet(np.Namespace, np.Name, "ingress")
 		if err != nil {
 			klog.Errorf("failed to list address_set, %v", err)
 			return err
 		}
 		for _, asName := range asNames {
-			if err = c.ovnLegacyClient.DeleteAddressSet(asName); err != nil {
+			if err = c.ovnClient.DeleteAddressSet(asName); err != nil {
 				klog.Errorf("failed to delete np %s address set, %v", key, err)
 				return err
 			}
 		}
 	}
 
-	egressAsNames, err := c.ovnLegacyClient.ListNpAddressSet(np.Namespace, np.Name, "egress")
+	egressAsNames, err := c.ovnClient.ListNpAddressSet(np.Namespace, np.Name, "egress")
 	if err != nil {
 		klog.Errorf("failed to list egress address_set, %v", err)
 		return err
 	}
 	for _, egressAsName := range egressAsNames {
-		if err = c.ovnLegacyClient.DeleteAddressSet(egressAsName); err != nil {
+		if err = c.ovnClient.DeleteAddressSet(egressAsName); err != nil {
 			klog.Errorf("failed to delete np %s address set, %v", key, err)
 			return err
 		}
 	}
 
-	var egressAclCmd []string
-	exist, err = c.ovnLegacyClient.PortGroupExists(pgName)
-	if err != nil {
-		klog.Errorf("failed to query np %s port group, %v", key, err)
-		return err
-	}
-	if exist {
-		egressAclCmd = []string{"--type=port-group", "acl-del", pgName, "from-lport"}
-	}
 	if hasEgressRule(np) {
+		// address sets of the domain names are shared by all the policies and updated by the fqdn address controller
+		fqdns := npFqdns(np)
+		for _, fqdn := range fqdns {
+			if err = c.ovnClient.CreateFqdnAddressSets(fqdn); err != nil {
+				klog.Errorf("failed to create address_set of fqdn %s, %v", fqdn, err)
+				return err
+			}
+		}
+		var egressACLs []*ovnnb.ACL
 		for _, cidrBlock := range strings.Split(subnet.Spec.CIDRBlock, ",") {
 			protocol := util.CheckProtocol(cidrBlock)
-			svcAsName := svcAsNameIPv4
-			if protocol == kubeovnv1.ProtocolIPv6 {
-				svcAsName = svcAsNameIPv6
-			}
-
 			for idx, npr := range np.Spec.Egress {
 				// A single address set must contain addresses of the same type and the name must be unique within table, so IPv4 and IPv6 address set should be different
 				egressAllowAsName := fmt.Sprintf("%s.%s.%d", egressAllowAsNamePrefix, protocol, idx)
@@ -435,58 +444,78 @@ func (c *Controller) handleUpdateNp(key string) error {
 					}
 				}
 				klog.Infof("UpdateNp Egress, allows is %v, excepts is %v, log %v", allows, excepts, logEnable)
-				if err = c.ovnLegacyClient.CreateNpAddressSet(egressAllowAsName, np.Namespace, np.Name, "egress"); err != nil {
+				if err = c.ovnClient.CreateNpAddressSet(egressAllowAsName, np.Namespace, np.Name, "egress"); err != nil {
 					klog.Errorf("failed to create address_set %s, %v", egressAllowAsName, err)
 					return err
 				}
-				if err = c.ovnLegacyClient.SetAddressesToAddressSet(allows, egressAllowAsName); err != nil {
+				if err = c.ovnClient.SetAddressesToAddressSet(allows, egressAllowAsName); err != nil {
 					klog.Errorf("failed to set egress allow address_set, %v", err)
 					return err
 				}
 
-				if err = c.ovnLegacyClient.CreateNpAddressSet(egressExceptAsName, np.Namespace, np.Name, "egress"); err != nil {
+				if err = c.ovnClient.CreateNpAddressSet(egressExceptAsName, np.Namespace, np.Name, "egress"); err != nil {
 					klog.Errorf("failed to create address_set %s, %v", egressExceptAsName, err)
 					return err
 				}
-				if err = c.ovnLegacyClient.SetAddressesToAddressSet(excepts, egressExceptAsName); err != nil {
+				if err = c.ovnClient.SetAddressesToAddressSet(excepts, egressExceptAsName); err != nil {
 					klog.Errorf("failed to set egress except address_set, %v", err)
 					return err
 				}
 
+				// named ports of egress rules are resolved by the destination pods
+				var peerPods []*corev1.Pod
+				if len(npr.To) == 0 {
+					if peerPods, err = c.podsLister.List(labels.Everything()); err != nil {
+						klog.Errorf("failed to list pods, %v", err)
+						return err
+					}
+				}
+				for _, npp := range npr.To {
+					var pods []*corev1.Pod
+					if pods, err = c.fetchPolicyPeerPods(np.Namespace, npp); err != nil {
+						klog.Errorf("failed to fetch policy peer pods, %v", err)
+						return err
+					}
+					peerPods = append(peerPods, pods...)
+				}
+				var namedPorts map[string][]ovs.NamedPortInfo
+				if namedPorts, err = c.setNpNamedPortAddressSets(np, "egress", egressNamedAsNamePrefix, protocol, idx, npr.Ports, peerPods); err != nil {
+					klog.Errorf("failed to set egress named port address_set, %v", err)
+					return err
+				}
+
 				if len(allows) != 0 || len(excepts) != 0 {
-					egressAclCmd = c.ovnLegacyClient.CombineEgressACLCmd(pgName, egressAllowAsName, egressExceptAsName, protocol, npr.Ports, svcAsName, logEnable, egressAclCmd, idx)
+					egressACLs = append(egressACLs, ovs.NpEgressACLs(pgName, egressAllowAsName, egressExceptAsName, protocol, npr.Ports, namedPorts, logEnable, auditMode)...)
 				}
 			}
 			if len(np.Spec.Egress) == 0 {
 				egressAllowAsName := fmt.Sprintf("%s.%s.all", egressAllowAsNamePrefix, protocol)
 				egressExceptAsName := fmt.Sprintf("%s.%s.all", egressExceptAsNamePrefix, protocol)
-				if err = c.ovnLegacyClient.CreateNpAddressSet(egressAllowAsName, np.Namespace, np.Name, "egress"); err != nil {
+				if err = c.ovnClient.CreateNpAddressSet(egressAllowAsName, np.Namespace, np.Name, "egress"); err != nil {
 					klog.Errorf("failed to create address_set %s, %v", egressAllowAsName, err)
 					return err
 				}
 
-				if err = c.ovnLegacyClient.CreateNpAddressSet(egressExceptAsName, np.Namespace, np.Name, "egress"); err != nil {
+				if err = c.ovnClient.CreateNpAddressSet(egressExceptAsName, np.Namespace, np.Name, "egress"); err != nil {
 					klog.Errorf("failed to create address_set %s, %v", egressExceptAsName, err)
 					return err
 				}
-				egressPorts := []netv1.NetworkPolicyPort{}
-				egressAclCmd = c.ovnLegacyClient.CombineEgressACLCmd(pgName, egressAllowAsName, egressExceptAsName, protocol, egressPorts, svcAsName, logEnable, egressAclCmd, 0)
-			}
-
-			klog.Infof("create egress acl cmd is: %v", egressAclCmd)
-			if err = c.ovnLegacyClient.CreateACL(egressAclCmd); err != nil {
-				klog.Errorf("failed to create egress acls for np %s, %v", key, err)
-				return err
+				egressACLs = append(egressACLs, ovs.NpEgressACLs(pgName, egressAllowAsName, egressExceptAsName, protocol, nil, nil, logEnable, auditMode)...)
 			}
+			egressACLs = append(egressACLs, ovs.NpEgressFqdnACLs(pgName, protocol, fqdns)...)
+		}
 
-			if err = c.ovnLegacyClient.SetAclLog(pgName, logEnable, false); err != nil {
-				// just log and do not return err here
-				klog.Errorf("failed to set egress acl log for np %s, %v", key, err)
-			}
+		if err = c.ovnClient.UpdatePortGroupACLs(pgName, ovnnb.ACLDirectionFromLport, egressACLs...); err != nil {
+			klog.Errorf("failed to create egress acls for np %s, %v", key, err)
+			return err
+		}
+		if err = c.ovnClient.SetAclLog(pgName, util.AclLogOwnerTypeNp, key, logEnable, false); err != nil {
+			// just log and do not return err here
+			klog.Errorf("failed to set egress acl log for np %s, %v", key, err)
 		}
 
 		var asNames []string
-		if asNames, err = c.ovnLegacyClient.ListNpAddressSet(np.Namespace, np.Name, "egress"); err != nil {
+		if asNames, err = c.ovnClient.ListNpAddressSet(np.Namespace, np.Name, "egress"); err != nil {
 			klog.Errorf("failed to list address_set, %v", err)
 			return err
 		}
@@ -503,32 +532,32 @@ func (c *Controller) handleUpdateNp(key string) error {
 
 			idx, _ := strconv.Atoi(idxStr)
 			if idx >= len(np.Spec.Egress) {
-				if err = c.ovnLegacyClient.DeleteAddressSet(asName); err != nil {
+				if err = c.ovnClient.DeleteAddressSet(asName); err != nil {
 					klog.Errorf("failed to delete np %s address set, %v", key, err)
 					return err
 				}
 			}
 		}
 	} else {
-		if err = c.ovnLegacyClient.DeleteACL(pgName, "from-lport"); err != nil {
+		if err = c.ovnClient.DeletePortGroupACLs(pgName, ovnnb.ACLDirectionFromLport); err != nil {
 			klog.Errorf("failed to delete np %s egress acls, %v", key, err)
 			return err
 		}
 
-		asNames, err := c.ovnLegacyClient.ListNpAddressSet(np.Namespace, np.Name, "egress")
+		asNames, err := c.ovnClient.ListNpAddressSet(np.Namespace, np.Name, "egress")
 		if err != nil {
 			klog.Errorf("failed to list egress address_set, %v", err)
 			return err
 		}
 		for _, asName := range asNames {
-			if err = c.ovnLegacyClient.DeleteAddressSet(asName); err != nil {
+			if err = c.ovnClient.DeleteAddressSet(asName); err != nil {
 				klog.Errorf("failed to delete np %s address set, %v", key, err)
 				return err
 			}
 		}
 	}
 
-	if err = c.ovnLegacyClient.CreateGatewayACL(pgName, subnet.Spec.Gateway, subnet.Spec.CIDRBlock); err != nil {
+	if err = c.ovnClient.CreateGatewayACL(pgName, subnet.Spec.Gateway, subnet.Spec.CIDRBlock); err != nil {
 		klog.Errorf("failed to create gateway acl, %v", err)
 		return err
 	}
@@ -547,37 +576,37 @@ func (c *Controller) handleDeleteNp(key string) error {
 		klog.Errorf("failed to delete np %s port group, %v", key, err)
 	}
 
-	svcAsNames, err := c.ovnLegacyClient.ListNpAddressSet(namespace, name, "service")
+	svcAsNames, err := c.ovnClient.ListNpAddressSet(namespace, name, "service")
 	if err != nil {
 		klog.Errorf("failed to list svc address_set, %v", err)
 		return err
 	}
 	for _, asName := range svcAsNames {
-		if err := c.ovnLegacyClient.DeleteAddressSet(asName); err != nil {
+		if err := c.ovnClient.DeleteAddressSet(asName); err != nil {
 			klog.Errorf("failed to delete np %s address set, %v", key, err)
 			return err
 		}
 	}
 
-	ingressAsNames, err := c.ovnLegacyClient.ListNpAddressSet(namespace, name, "ingress")
+	ingressAsNames, err := c.ovnClient.ListNpAddressSet(namespace, name, "ingress")
 	if err != nil {
 		klog.Errorf("failed to list address_set, %v", err)
 		return err
 	}
 	for _, asName := range ingressAsNames {
-		if err := c.ovnLegacyClient.DeleteAddressSet(asName); err != nil {
+		if err := c.ovnClient.DeleteAddressSet(asName); err != nil {
 			klog.Errorf("failed to delete np %s address set, %v", key, err)
 			return err
 		}
 	}
 
-	egressAsNames, err := c.ovnLegacyClient.ListNpAddressSet(namespace, name, "egress")
+	egressAsNames, err := c.ovnClient.ListNpAddressSet(namespace, name, "egress")
 	if err != nil {
 		klog.Errorf("failed to list address_set, %v", err)
 		return err
 	}
 	for _, asName := range egressAsNames {
-		if err := c.ovnLegacyClient.DeleteAddressSet(asName); err != nil {
+		if err := c.ovnClient.DeleteAddressSet(asName); err != nil {
 			klog.Errorf("failed to delete np %s address set, %v", key, err)
 			return err
 		}
@@ -691,17 +720,56 @@ func (c *Controller) fetchPolicySelectedAddresses(namespace, protocol string, np
 		return selectedAddresses, exceptAddresses, nil
 	}
 
+	pods, err := c.fetchPolicyPeerPods(namespace, npp)
+	if err != nil {
+		return nil, nil, err
+	}
+	svcsByNs := make(map[string][]*corev1.Service)
+	for _, pod := range pods {
+		svcs, ok := svcsByNs[pod.Namespace]
+		if !ok {
+			if svcs, err = c.servicesLister.Services(pod.Namespace).List(labels.Everything()); err != nil {
+				klog.Errorf("failed to list svc, %v", err)
+				return nil, nil, fmt.Errorf("failed to list svc, %v", err)
+			}
+			svcsByNs[pod.Namespace] = svcs
+		}
+
+		for _, podIP := range pod.Status.PodIPs {
+			if podIP.IP != "" && util.CheckProtocol(podIP.IP) == protocol {
+				selectedAddresses = append(selectedAddresses, podIP.IP)
+				if len(svcs) == 0 {
+					continue
+				}
+
+				svcIPs, err := svcMatchPods(svcs, pod, protocol)
+				if err != nil {
+					return nil, nil, err
+				}
+				selectedAddresses = append(selectedAddresses, svcIPs...)
+			}
+		}
+	}
+	return selectedAddresses, exceptAddresses, nil
+}
+
+// fetchPolicyPeerPods returns the pods selected by the namespace selector and pod selector of the peer
+func (c *Controller) fetchPolicyPeerPods(namespace string, npp netv1.NetworkPolicyPeer) ([]*corev1.Pod, error) {
+	if npp.NamespaceSelector == nil && npp.PodSelector == nil {
+		return nil, nil
+	}
+
 	selectedNs := []string{}
 	if npp.NamespaceSelector == nil {
 		selectedNs = append(selectedNs, namespace)
 	} else {
 		sel, err := metav1.LabelSelectorAsSelector(npp.NamespaceSelector)
 		if err != nil {
-			return nil, nil, fmt.Errorf("error creating label selector, %v", err)
+			return nil, fmt.Errorf("error creating label selector, %v", err)
 		}
 		nss, err := c.namespacesLister.List(sel)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to list ns, %v", err)
+			return nil, fmt.Errorf("failed to list ns, %v", err)
 		}
 		for _, ns := range nss {
 			selectedNs = append(selectedNs, ns.Name)
@@ -715,35 +783,76 @@ func (c *Controller) fetchPolicySelectedAddresses(namespace, protocol string, np
 		sel, _ = metav1.LabelSelectorAsSelector(npp.PodSelector)
 	}
 
+	var result []*corev1.Pod
 	for _, ns := range selectedNs {
 		pods, err := c.podsLister.Pods(ns).List(sel)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to list pod, %v", err)
-		}
-		svcs, err := c.servicesLister.Services(ns).List(labels.Everything())
-		if err != nil {
-			klog.Errorf("failed to list svc, %v", err)
-			return nil, nil, fmt.Errorf("failed to list svc, %v", err)
+			return nil, fmt.Errorf("failed to list pod, %v", err)
 		}
+		result = append(result, pods...)
+	}
+	return result, nil
+}
 
-		for _, pod := range pods {
-			for _, podIP := range pod.Status.PodIPs {
-				if podIP.IP != "" && util.CheckProtocol(podIP.IP) == protocol {
-					selectedAddresses = append(selectedAddresses, podIP.IP)
-					if len(svcs) == 0 {
-						continue
-					}
+// setNpNamedPortAddressSets resolves the named ports of a rule by the container ports of the pods, the addresses of
+// the pods exposing a named port with the same protocol and port number are set to an address set of the rule
+func (c *Controller) setNpNamedPortAddressSets(np *netv1.NetworkPolicy, direction, asNamePrefix, protocol string, idx int,
+	npp []netv1.NetworkPolicyPort, pods []*corev1.Pod) (map[string][]ovs.NamedPortInfo, error) {
+	names := make(map[string]bool)
+	for _, port := range npp {
+		if port.Port != nil && port.Port.Type == intstr.String {
+			names[port.Port.StrVal] = true
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
 
-					svcIPs, err := svcMatchPods(svcs, pod, protocol)
-					if err != nil {
-						return nil, nil, err
+	type namedPort struct {
+		name     string
+		protocol corev1.Protocol
+		port     int32
+	}
+	addresses := make(map[namedPort][]string)
+	for _, pod := range pods {
+		if !isPodAlive(pod) {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			for _, containerPort := range container.Ports {
+				if !names[containerPort.Name] {
+					continue
+				}
+				key := namedPort{name: containerPort.Name, protocol: containerPort.Protocol, port: containerPort.ContainerPort}
+				if key.protocol == "" {
+					key.protocol = corev1.ProtocolTCP
+				}
+				for _, podIP := range pod.Status.PodIPs {
+					if podIP.IP != "" && util.CheckProtocol(podIP.IP) == protocol {
+						addresses[key] = append(addresses[key], podIP.IP)
 					}
-					selectedAddresses = append(selectedAddresses, svcIPs...)
 				}
 			}
 		}
 	}
-	return selectedAddresses, exceptAddresses, nil
+
+	namedPorts := make(map[string][]ovs.NamedPortInfo, len(names))
+	for key, ips := range addresses {
+		asName := strings.Replace(fmt.Sprintf("%s.%s.%s.%s.%d.%d", asNamePrefix, protocol, key.name, strings.ToLower(string(key.protocol)), key.port, idx), "-", ".", -1)
+		if err := c.ovnClient.CreateNpAddressSet(asName, np.Namespace, np.Name, direction); err != nil {
+			klog.Errorf("failed to create address_set %s, %v", asName, err)
+			return nil, err
+		}
+		if err := c.ovnClient.SetAddressesToAddressSet(ips, asName); err != nil {
+			klog.Errorf("failed to set named port address_set %s, %v", asName, err)
+			return nil, err
+		}
+		namedPorts[key.name] = append(namedPorts[key.name], ovs.NamedPortInfo{Protocol: key.protocol, PortID: key.port, AddressSet: asName})
+	}
+	for _, infos := range namedPorts {
+		sort.Slice(infos, func(i, j int) bool { return infos[i].AddressSet < infos[j].AddressSet })
+	}
+	return namedPorts, nil
 }
 
 func svcMatchPods(svcs []*corev1.Service, pod *corev1.Pod, protocol string) ([]string, error) {
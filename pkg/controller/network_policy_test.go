@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func Test_handleUpdateNp(t *testing.T) {
+	subnet := &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: util.DefaultSubnet},
+		Spec: kubeovnv1.SubnetSpec{
+			Vpc:       util.DefaultVpc,
+			CIDRBlock: "10.16.0.0/16",
+			Gateway:   "10.16.0.1",
+			Protocol:  kubeovnv1.ProtocolIPv4,
+		},
+	}
+	np := &netv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-np", Namespace: "test"},
+		Spec: netv1.NetworkPolicySpec{
+			PolicyTypes: []netv1.PolicyType{netv1.PolicyTypeIngress},
+			Ingress: []netv1.NetworkPolicyIngressRule{{
+				From: []netv1.NetworkPolicyPeer{{
+					IPBlock: &netv1.IPBlock{CIDR: "10.0.0.0/8", Except: []string{"10.1.0.0/16"}},
+				}},
+			}},
+		},
+	}
+	ctrl := newFakeController(t, []runtime.Object{np}, []runtime.Object{subnet})
+
+	require.NoError(t, ctrl.handleUpdateNp("test/test-np"))
+
+	pg, err := ctrl.ovnClient.GetPortGroup("test.np.test", false)
+	require.NoError(t, err)
+	require.Equal(t, "test/test-np", pg.ExternalIDs["np"])
+
+	allow, err := ctrl.ovnClient.GetAddressSet("test.np.test.ingress.allow.IPv4.0", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.0/8"}, allow.Addresses)
+	except, err := ctrl.ovnClient.GetAddressSet("test.np.test.ingress.except.IPv4.0", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.1.0.0/16"}, except.Addresses)
+	egressAsNames, err := ctrl.ovnClient.ListNpAddressSet(np.Namespace, np.Name, "egress")
+	require.NoError(t, err)
+	require.Empty(t, egressAsNames)
+
+	acls, err := ctrl.ovnClient.ListPortGroupACLs(pg.Name, ovnnb.ACLDirectionToLport)
+	require.NoError(t, err)
+	var matches []string
+	for _, acl := range acls {
+		matches = append(matches, acl.Match)
+		if strings.Contains(acl.Match, "@test.np.test") {
+			require.Equal(t, "test/test-np", acl.ExternalIDs[util.AclLogOwnerKey])
+		}
+	}
+	// the traffic from the gateway of the subnet is always allowed
+	require.ElementsMatch(t, []string{
+		"ip4.src == 10.16.0.1",
+		"outport==@test.np.test && ip",
+		"ip4.src == $test.np.test.ingress.allow.IPv4.0 && ip4.src != $test.np.test.ingress.except.IPv4.0 && outport==@test.np.test && ip",
+	}, matches)
+
+	// address sets and acls of the removed ingress rules are deleted
+	np = np.DeepCopy()
+	np.Spec.PolicyTypes = []netv1.PolicyType{netv1.PolicyTypeEgress}
+	np.Spec.Ingress = nil
+	require.NoError(t, ctrl.informerFactory.Networking().V1().NetworkPolicies().Informer().GetIndexer().Update(np))
+	require.NoError(t, ctrl.handleUpdateNp("test/test-np"))
+	ingressAsNames, err := ctrl.ovnClient.ListNpAddressSet(np.Namespace, np.Name, "ingress")
+	require.NoError(t, err)
+	require.Empty(t, ingressAsNames)
+	acls, err = ctrl.ovnClient.ListPortGroupACLs(pg.Name, ovnnb.ACLDirectionToLport)
+	require.NoError(t, err)
+	require.Len(t, acls, 1)
+	require.Equal(t, "ip4.src == 10.16.0.1", acls[0].Match)
+}
+
+func Test_handleUpdateNpNamedPorts(t *testing.T) {
+	subnet := &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: util.DefaultSubnet},
+		Spec: kubeovnv1.SubnetSpec{
+			Vpc:       util.DefaultVpc,
+			CIDRBlock: "10.16.0.0/16",
+			Gateway:   "10.16.0.1",
+			Protocol:  kubeovnv1.ProtocolIPv4,
+		},
+	}
+	newPod := func(name, ip string, containerPort int32) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test",
+				Labels:    map[string]string{"app": "web"},
+				Annotations: map[string]string{
+					util.AllocatedAnnotation:     "true",
+					util.LogicalSwitchAnnotation: util.DefaultSubnet,
+				},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Ports: []corev1.ContainerPort{{Name: "http-port", ContainerPort: containerPort}},
+			}}},
+			Status: corev1.PodStatus{PodIPs: []corev1.PodIP{{IP: ip}}},
+		}
+	}
+	httpPort := intstr.FromString("http-port")
+	np := &netv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+		Spec: netv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			PolicyTypes: []netv1.PolicyType{netv1.PolicyTypeIngress},
+			Ingress:     []netv1.NetworkPolicyIngressRule{{Ports: []netv1.NetworkPolicyPort{{Port: &httpPort}}}},
+		},
+	}
+	kubeObjects := []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test"}},
+		np, newPod("web1", "10.16.0.10", 8080), newPod("web2", "10.16.0.11", 80),
+	}
+	ctrl := newFakeController(t, kubeObjects, []runtime.Object{subnet})
+
+	require.NoError(t, ctrl.handleUpdateNp("test/web"))
+
+	// the named port is resolved to the port numbers of the selected pods
+	as, err := ctrl.ovnClient.GetAddressSet("web.test.ingress.named.IPv4.http.port.tcp.8080.0", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.16.0.10"}, as.Addresses)
+	as, err = ctrl.ovnClient.GetAddressSet("web.test.ingress.named.IPv4.http.port.tcp.80.0", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.16.0.11"}, as.Addresses)
+
+	// the acls allow the port numbers of the named port to the pods exposing them
+	acls, err := ctrl.ovnClient.ListPortGroupACLs("web.test", ovnnb.ACLDirectionToLport)
+	require.NoError(t, err)
+	var matches []string
+	for _, acl := range acls {
+		matches = append(matches, acl.Match)
+	}
+	require.ElementsMatch(t, []string{
+		"ip4.src == 10.16.0.1",
+		"outport==@web.test && ip",
+		"ip4.src == $web.test.ingress.allow.IPv4.0 && ip4.src != $web.test.ingress.except.IPv4.0 && ip4.dst == $web.test.ingress.named.IPv4.http.port.tcp.80.0 && tcp.dst == 80 && outport==@web.test && ip",
+		"ip4.src == $web.test.ingress.allow.IPv4.0 && ip4.src != $web.test.ingress.except.IPv4.0 && ip4.dst == $web.test.ingress.named.IPv4.http.port.tcp.8080.0 && tcp.dst == 8080 && outport==@web.test && ip",
+	}, matches)
+}
@@ -37,6 +37,11 @@ func (c *Controller) enqueueAddNode(obj interface{}) {
 	}
 	klog.V(3).Infof("enqueue add node %s", key)
 	c.addNodeQueue.Add(key)
+	c.enqueueSyncNodeQoSPolicy(key, obj.(*v1.Node).Annotations)
+	c.enqueueSyncAnps(c.nodeMatchAnps(obj.(*v1.Node))...)
+	if obj.(*v1.Node).Annotations[util.ChassisAnnotation] != "" {
+		c.enqueueNodeScopedServices()
+	}
 }
 
 func nodeReady(node *v1.Node) bool {
@@ -66,6 +71,20 @@ func (c *Controller) enqueueUpdateNode(oldObj, newObj interface{}) {
 		}
 		klog.V(3).Infof("enqueue update node %s", key)
 		c.updateNodeQueue.Add(key)
+		if c.ovnQoSAnnotationsChanged(oldNode.Annotations, newNode.Annotations) {
+			c.enqueueSyncNodeQoSPolicy(key, newNode.Annotations)
+		}
+		c.enqueueSyncQoSPolicyStatus(oldNode.Annotations, newNode.Annotations)
+	}
+	// nodes are selected by labels and matched by internal ips in admin network policies
+	if !reflect.DeepEqual(oldNode.Labels, newNode.Labels) || !reflect.DeepEqual(oldNode.Status.Addresses, newNode.Status.Addresses) {
+		c.enqueueSyncAnps(util.UniqString(append(c.nodeMatchAnps(oldNode), c.nodeMatchAnps(newNode)...))...)
+	}
+	// the backends of the services are selected by the chassis, the zone or the external gateway label of the node
+	if oldNode.Annotations[util.ChassisAnnotation] != newNode.Annotations[util.ChassisAnnotation] ||
+		oldNode.Labels[v1.LabelTopologyZone] != newNode.Labels[v1.LabelTopologyZone] ||
+		oldNode.Labels[util.ExGatewayLabel] != newNode.Labels[util.ExGatewayLabel] {
+		c.enqueueNodeScopedServices()
 	}
 }
 
@@ -81,6 +100,12 @@ func (c *Controller) enqueueDeleteNode(obj interface{}) {
 	}
 	klog.V(3).Infof("enqueue delete node %s", key)
 	c.deleteNodeQueue.Add(key)
+	if node, ok := obj.(*v1.Node); ok {
+		c.enqueueSyncNodeQoSPolicy(key, node.Annotations)
+		c.enqueueSyncQoSPolicyStatus(node.Annotations, nil)
+		c.enqueueSyncAnps(c.nodeMatchAnps(node)...)
+		c.enqueueNodeScopedServices()
+	}
 }
 
 func (c *Controller) runAddNodeWorker() {
@@ -248,7 +273,7 @@ func (c *Controller) handleAddNode(key string) error {
 			return err
 		}
 	} else {
-		v4IP, v6IP, mac, err = c.ipam.GetRandomAddress(portName, portName, "", c.config.NodeSwitch, nil, true)
+		v4IP, v6IP, mac, err = c.ipam.GetRandomAddress(portName, portName, "", c.config.NodeSwitch, "", nil, true)
 		if err != nil {
 			klog.Errorf("failed to alloc random ip addrs for node %v: %v", node.Name, err)
 			return err
@@ -276,7 +301,7 @@ func (c *Controller) handleAddNode(key string) error {
 				"node":           node.Name,
 				"address-family": strconv.Itoa(af),
 			}
-			if err = c.ovnLegacyClient.AddPolicyRoute(c.config.ClusterRouter, util.NodeRouterPolicyPriority, match, "reroute", ip, externalIDs); err != nil {
+			if err = c.ovnClient.AddPolicyRoute(c.config.ClusterRouter, util.NodeRouterPolicyPriority, match, "reroute", ip, externalIDs); err != nil {
 				klog.Errorf("failed to add logical router policy for node %s: %v", node.Name, err)
 				return err
 			}
@@ -307,6 +332,7 @@ func (c *Controller) handleAddNode(key string) error {
 	node.Annotations[util.LogicalSwitchAnnotation] = c.config.NodeSwitch
 	node.Annotations[util.AllocatedAnnotation] = "true"
 	node.Annotations[util.PortNameAnnotation] = fmt.Sprintf("node-%s", key)
+	node.Annotations[util.QoSModeAnnotation] = c.config.QoSMode
 	raw, _ := json.Marshal(node.Annotations)
 	patchPayload := fmt.Sprintf(patchPayloadTemplate, op, raw)
 	_, err = c.config.KubeClient.CoreV1().Nodes().Patch(context.Background(), key, types.JSONPatchType, []byte(patchPayload), metav1.PatchOptions{}, "")
@@ -338,7 +364,7 @@ func (c *Controller) handleAddNode(key string) error {
 
 	// ovn acl doesn't support address_set name with '-', so replace '-' by '.'
 	pgName := strings.Replace(node.Annotations[util.PortNameAnnotation], "-", ".", -1)
-	if err := c.ovnLegacyClient.CreateNpPortGroup(pgName, "node", key); err != nil {
+	if err := c.ovnClient.CreatePortGroup(pgName, map[string]string{"np": "node/" + key}); err != nil {
 		klog.Errorf("failed to create port group %s for node %s: %v", pgName, key, err)
 		return err
 	}
@@ -447,7 +473,7 @@ func (c *Controller) handleNodeAnnotationsForProviderNetworks(node *v1.Node) err
 
 func (c *Controller) handleDeleteNode(key string) error {
 	portName := fmt.Sprintf("node-%s", key)
-	if err := c.ovnLegacyClient.DeleteLogicalSwitchPort(portName); err != nil {
+	if err := c.ovnClient.DeleteLogicalSwitchPort(portName); err != nil {
 		klog.Errorf("failed to delete node switch port node-%s: %v", key, err)
 		return err
 	}
@@ -476,16 +502,16 @@ func (c *Controller) handleDeleteNode(key string) error {
 		if addr.Ip == "" {
 			continue
 		}
-		if err := c.ovnLegacyClient.DeletePolicyRouteByNexthop(c.config.ClusterRouter, util.NodeRouterPolicyPriority, addr.Ip); err != nil {
+		if err := c.ovnClient.DeletePolicyRouteByNexthop(c.config.ClusterRouter, util.NodeRouterPolicyPriority, addr.Ip); err != nil {
 			klog.Errorf("failed to delete router policy for node %s: %v", key, err)
 			return err
 		}
 	}
-	if err := c.ovnLegacyClient.DeleteAddressSet(nodeUnderlayAddressSetName(key, 4)); err != nil {
+	if err := c.ovnClient.DeleteAddressSet(nodeUnderlayAddressSetName(key, 4)); err != nil {
 		klog.Errorf("failed to delete address set for node %s: %v", key, err)
 		return err
 	}
-	if err := c.ovnLegacyClient.DeleteAddressSet(nodeUnderlayAddressSetName(key, 6)); err != nil {
+	if err := c.ovnClient.DeleteAddressSet(nodeUnderlayAddressSetName(key, 6)); err != nil {
 		klog.Errorf("failed to delete address set for node %s: %v", key, err)
 		return err
 	}
@@ -740,7 +766,7 @@ func (c *Controller) checkGatewayReady() error {
 		for _, node := range nodes {
 			ipStr := node.Annotations[util.IpAddressAnnotation]
 			for _, ip := range strings.Split(ipStr, ",") {
-				for _, cidrBlock := range strings.Split(subnet.Spec.CIDRBlock, ",") {
+				for _, cidrBlock := range util.SubnetCIDRBlocks(subnet) {
 					if util.CheckProtocol(cidrBlock) != util.CheckProtocol(ip) {
 						continue
 					}
@@ -823,7 +849,7 @@ func (c *Controller) checkGatewayReady() error {
 }
 
 func (c *Controller) checkRouteExist(nextHop, cidrBlock, routePolicy string) (bool, error) {
-	routes, err := c.ovnLegacyClient.GetStaticRouteList(c.config.ClusterRouter)
+	routes, err := c.ovnClient.ListStaticRoutes(c.config.ClusterRouter)
 	if err != nil {
 		klog.Errorf("failed to list static route %v", err)
 		return false, err
@@ -992,18 +1018,18 @@ func (c *Controller) checkAndUpdateNodePortGroup() error {
 		}
 		lastNpExists[node.Name] = networkPolicyExists
 
-		err = c.ovnLegacyClient.SetPortsToPortGroup(pgName, ports)
+		err = c.ovnClient.PortGroupSetPorts(pgName, ports)
 		if err != nil {
 			klog.Errorf("failed to set port group for node %v, %v", node.Name, err)
 			return err
 		}
 
 		if networkPolicyExists {
-			if err := c.ovnLegacyClient.CreateACLForNodePg(pgName, nodeIP); err != nil {
+			if err := c.ovnClient.CreateACLForNodePg(pgName, nodeIP); err != nil {
 				klog.Errorf("failed to create node acl for node pg %v, %v", pgName, err)
 			}
 		} else {
-			if err := c.ovnLegacyClient.DeleteAclForNodePg(pgName); err != nil {
+			if err := c.ovnClient.DeletePortGroupACLs(pgName, ""); err != nil {
 				klog.Errorf("failed to delete node acl for node pg %v, %v", pgName, err)
 			}
 		}
@@ -1046,7 +1072,7 @@ func (c *Controller) validateChassis(node *v1.Node) error {
 func (c *Controller) addNodeGwStaticRoute() error {
 	// If user not manage static route for default vpc, just add route about ovn-default to join
 	if vpc, err := c.vpcsLister.Get(util.DefaultVpc); err != nil || vpc.Spec.StaticRoutes != nil {
-		existRoute, err := c.ovnLegacyClient.GetStaticRouteList(c.config.ClusterRouter)
+		existRoute, err := c.ovnClient.ListStaticRoutes(c.config.ClusterRouter)
 		if err != nil {
 			klog.Errorf("failed to get vpc %s static route list, %v", c.config.ClusterRouter, err)
 		}
@@ -1069,7 +1095,7 @@ func (c *Controller) addNodeGwStaticRoute() error {
 
 			if !exist {
 				klog.Infof("add static route for node gw")
-				if err := c.ovnLegacyClient.AddStaticRoute("", cidrBlock, nextHop, c.config.ClusterRouter, util.NormalRouteType); err != nil {
+				if err := c.ovnClient.AddStaticRoute("", cidrBlock, nextHop, c.config.ClusterRouter, util.NormalRouteType); err != nil {
 					klog.Errorf("failed to add static route for node gw: %v", err)
 					return err
 				}
@@ -1085,7 +1111,7 @@ func (c *Controller) getPolicyRouteParas(cidr string) ([]string, map[string]stri
 		ipSuffix = "ip6"
 	}
 	match := fmt.Sprintf("%s.src == %s", ipSuffix, cidr)
-	nextHops, nameIpMap, err := c.ovnLegacyClient.GetPolicyRouteParas(util.GatewayRouterPolicyPriority, match)
+	nextHops, nameIpMap, err := c.ovnClient.GetPolicyRouteParas(c.config.ClusterRouter, util.GatewayRouterPolicyPriority, match)
 	if err != nil {
 		klog.Errorf("failed to get policy route paras, %v", err)
 		return nextHops, nameIpMap, err
@@ -1133,7 +1159,7 @@ func (c *Controller) deletePolicyRouteForNode(nodeName string) error {
 
 		if subnet.Spec.GatewayType == kubeovnv1.GWCentralizedType {
 			if c.config.EnableEcmp {
-				for _, cidrBlock := range strings.Split(subnet.Spec.CIDRBlock, ",") {
+				for _, cidrBlock := range util.SubnetCIDRBlocks(subnet) {
 					nextHops, nameIpMap, err := c.getPolicyRouteParas(cidrBlock)
 					if err != nil {
 						klog.Errorf("get ecmp policy route paras for subnet %v, error %v", subnet.Name, err)
@@ -1191,7 +1217,7 @@ func (c *Controller) addPolicyRouteForCentralizedSubnetOnNode(nodeName, nodeIP s
 			}
 
 			for _, nextHop := range strings.Split(nodeIP, ",") {
-				for _, cidrBlock := range strings.Split(subnet.Spec.CIDRBlock, ",") {
+				for _, cidrBlock := range util.SubnetCIDRBlocks(subnet) {
 					if util.CheckProtocol(cidrBlock) != util.CheckProtocol(nextHop) {
 						continue
 					}
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func Test_centralizedSubnetPolicyRoutesOnNode(t *testing.T) {
+	subnet := &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-subnet"},
+		Spec: kubeovnv1.SubnetSpec{
+			Vpc:                 util.DefaultVpc,
+			CIDRBlock:           "10.100.0.0/24",
+			SecondaryCIDRBlocks: []string{"10.200.0.0/24"},
+			Gateway:             "10.100.0.1",
+			Protocol:            kubeovnv1.ProtocolIPv4,
+			GatewayType:         kubeovnv1.GWCentralizedType,
+			GatewayNode:         "node1,node2",
+		},
+	}
+	ctrl := newFakeController(t, nil, []runtime.Object{subnet})
+	ctrl.config.EnableEcmp = true
+	require.NoError(t, ctrl.legacyClient.CreateLogicalRouter(util.DefaultVpc))
+
+	requireNextHops := func(expected map[string]string) {
+		t.Helper()
+		for _, cidrBlock := range util.SubnetCIDRBlocks(subnet) {
+			nextHops, nameIPMap, err := ctrl.getPolicyRouteParas(cidrBlock)
+			require.NoError(t, err)
+			var ips []string
+			for node, ip := range expected {
+				require.Equal(t, ip, nameIPMap[node], cidrBlock)
+				ips = append(ips, ip)
+			}
+			require.ElementsMatch(t, ips, nextHops, cidrBlock)
+		}
+	}
+
+	// the gateway nodes are added to the reroute policies of all cidr blocks
+	require.NoError(t, ctrl.addPolicyRouteForCentralizedSubnetOnNode("node1", "172.18.0.2"))
+	require.NoError(t, ctrl.addPolicyRouteForCentralizedSubnetOnNode("node2", "172.18.0.3"))
+	requireNextHops(map[string]string{"node1": "172.18.0.2", "node2": "172.18.0.3"})
+
+	// the other nodes are not gateways of the subnet
+	require.NoError(t, ctrl.addPolicyRouteForCentralizedSubnetOnNode("node3", "172.18.0.4"))
+	requireNextHops(map[string]string{"node1": "172.18.0.2", "node2": "172.18.0.3"})
+
+	// the deleted node is removed from the reroute policies of all cidr blocks
+	require.NoError(t, ctrl.deletePolicyRouteForNode("node1"))
+	requireNextHops(map[string]string{"node2": "172.18.0.3"})
+	_, nameIPMap, err := ctrl.getPolicyRouteParas("10.200.0.0/24")
+	require.NoError(t, err)
+	require.NotContains(t, nameIPMap, "node1")
+}
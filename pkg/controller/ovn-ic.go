@@ -104,13 +104,13 @@ func (c *Controller) resyncInterConnection() {
 				klog.Errorf("failed to remove learned static routes, %v", err)
 				return
 			}
-			c.ovnLegacyClient.OvnICSbAddress = genHostAddress(cm.Data["ic-db-host"], cm.Data["ic-sb-port"])
+			c.ovnLegacyClient.SetOvnICSbAddress(genHostAddress(cm.Data["ic-db-host"], cm.Data["ic-sb-port"]))
 
 			if err := c.RemoveOldChassisInSbDB(); err != nil {
 				klog.Errorf("failed to remove remote chassis: %v", err)
 			}
 
-			c.ovnLegacyClient.OvnICNbAddress = genHostAddress(cm.Data["ic-db-host"], cm.Data["ic-nb-port"])
+			c.ovnLegacyClient.SetOvnICNbAddress(genHostAddress(cm.Data["ic-db-host"], cm.Data["ic-nb-port"]))
 			klog.Info("start to reestablish ovn-ic")
 			if err := c.establishInterConnection(cm.Data); err != nil {
 				klog.Errorf("failed to reestablish ovn-ic, %v", err)
@@ -122,7 +122,7 @@ func (c *Controller) resyncInterConnection() {
 			return
 		}
 
-		c.ovnLegacyClient.OvnICNbAddress = genHostAddress(cm.Data["ic-db-host"], cm.Data["ic-nb-port"])
+		c.ovnLegacyClient.SetOvnICNbAddress(genHostAddress(cm.Data["ic-db-host"], cm.Data["ic-nb-port"]))
 		klog.Info("start to establish ovn-ic")
 		if err := c.establishInterConnection(cm.Data); err != nil {
 			klog.Errorf("failed to establish ovn-ic, %v", err)
@@ -254,8 +254,24 @@ func (c *Controller) establishInterConnection(config map[string]string) error {
 		return err
 	}
 
-	if err := c.ovnLegacyClient.CreateICLogicalRouterPort(config["az-name"], util.GenerateMac(), subnet, chassises); err != nil {
-		klog.Errorf("failed to create ovn-ic lrp %v", err)
+	lrpName := fmt.Sprintf("%s-ts", config["az-name"])
+	exist, err = c.ovnClient.LogicalRouterPortExists(lrpName)
+	if err != nil {
+		klog.Errorf("failed to check ovn-ic lrp %s, %v", lrpName, err)
+		return err
+	}
+	if !exist {
+		if err = c.ovnClient.AddLogicalRouterPort(c.config.ClusterRouter, lrpName, util.GenerateMac(), subnet); err != nil {
+			klog.Errorf("failed to create ovn-ic lrp %v", err)
+			return err
+		}
+	}
+	if err = c.ovnClient.CreateRouterTypePort(util.InterconnectionSwitch, fmt.Sprintf("ts-%s", config["az-name"]), lrpName); err != nil {
+		klog.Errorf("failed to create ovn-ic lsp %v", err)
+		return err
+	}
+	if err = c.ovnClient.SetGatewayChassises(lrpName, chassises); err != nil {
+		klog.Errorf("failed to set gateway chassises of ovn-ic lrp %s, %v", lrpName, err)
 		return err
 	}
 
@@ -336,45 +352,25 @@ func (c *Controller) waitTsReady() error {
 }
 
 func (c *Controller) delLearnedRoute() error {
-	originalPorts, err := c.ovnLegacyClient.CustomFindEntity("Logical_Router_Static_Route", []string{"_uuid", "ip_prefix"})
-	if err != nil {
-		klog.Errorf("failed to list static routes of logical router, %v", err)
-		return err
-	}
-	filteredPorts, err := c.ovnLegacyClient.CustomFindEntity("Logical_Router_Static_Route", []string{"_uuid", "ip_prefix"}, "external_ids:ic-learned-route{<=}1")
+	lrList, err := c.ovnClient.ListLogicalRoutersWithFilter(nil)
 	if err != nil {
-		klog.Errorf("failed to filter static routes of logical router, %v", err)
+		klog.Errorf("failed to list logical routers, %v", err)
 		return err
 	}
-	learnedPorts := []map[string][]string{}
-	for _, aOriPort := range originalPorts {
-		isFiltered := false
-		for _, aFtPort := range filteredPorts {
-			if aFtPort["_uuid"][0] == aOriPort["_uuid"][0] {
-				isFiltered = true
-			}
-		}
-		if !isFiltered {
-			learnedPorts = append(learnedPorts, aOriPort)
+	for _, lr := range lrList {
+		routes, err := c.ovnClient.ListLearnedStaticRoutes(lr.Name)
+		if err != nil {
+			klog.Errorf("failed to list learned static routes of logical router %s, %v", lr.Name, err)
+			return err
 		}
-	}
-	if len(learnedPorts) != 0 {
-		for _, aLdPort := range learnedPorts {
-			itsRouter, err := c.ovnLegacyClient.CustomFindEntity("Logical_Router", []string{"name"}, fmt.Sprintf("static_routes{>}%s", aLdPort["_uuid"][0]))
-			if err != nil {
-				klog.Errorf("failed to list logical router of static route %s, %v", aLdPort["_uuid"][0], err)
-				return err
-			} else if len(itsRouter) != 1 {
-				klog.Errorf("number wrong of logical router for static route %s, %v", aLdPort["_uuid"][0], itsRouter)
-				return nil
-			}
-			if err := c.ovnLegacyClient.DeleteStaticRoute(aLdPort["ip_prefix"][0], itsRouter[0]["name"][0]); err != nil {
-				klog.Errorf("failed to delete stale route %s, %v", aLdPort["ip_prefix"][0], err)
+		for _, route := range routes {
+			if err := c.ovnClient.DeleteStaticRoute(route.CIDR, lr.Name); err != nil {
+				klog.Errorf("failed to delete stale route %s, %v", route.CIDR, err)
 				return err
 			}
 		}
-		klog.V(5).Infof("finish removing learned routes")
 	}
+	klog.V(5).Infof("finish removing learned routes")
 	return nil
 }
 
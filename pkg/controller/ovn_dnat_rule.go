@@ -0,0 +1,275 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func (c *Controller) enqueueAddOvnDnatRule(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(obj); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	klog.V(3).Infof("enqueue add ovn dnat rule %s", key)
+	c.addOrUpdateOvnDnatRuleQueue.Add(key)
+}
+
+func (c *Controller) enqueueUpdateOvnDnatRule(old, new interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(new); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	oldDnat := old.(*kubeovnv1.OvnDnatRule)
+	newDnat := new.(*kubeovnv1.OvnDnatRule)
+	if oldDnat.Spec != newDnat.Spec {
+		klog.V(3).Infof("enqueue update ovn dnat rule %s", key)
+		c.addOrUpdateOvnDnatRuleQueue.Add(key)
+	}
+	if oldDnat.Spec.OvnEip != newDnat.Spec.OvnEip {
+		c.enqueueOvnEipOfNat(oldDnat.Spec.OvnEip)
+	}
+}
+
+func (c *Controller) enqueueDelOvnDnatRule(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(obj); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	klog.V(3).Infof("enqueue delete ovn dnat rule %s", key)
+	c.delOvnDnatRuleQueue.Add(obj)
+	if dnat, ok := obj.(*kubeovnv1.OvnDnatRule); ok {
+		c.enqueueOvnEipOfNat(dnat.Spec.OvnEip)
+	}
+}
+
+func (c *Controller) runAddOrUpdateOvnDnatRuleWorker() {
+	for c.processNextAddOrUpdateOvnDnatRuleWorkItem() {
+	}
+}
+
+func (c *Controller) runDelOvnDnatRuleWorker() {
+	for c.processNextDeleteOvnDnatRuleWorkItem() {
+	}
+}
+
+func (c *Controller) processNextAddOrUpdateOvnDnatRuleWorkItem() bool {
+	obj, shutdown := c.addOrUpdateOvnDnatRuleQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.addOrUpdateOvnDnatRuleQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.addOrUpdateOvnDnatRuleQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleAddOrUpdateOvnDnatRule(key); err != nil {
+			c.addOrUpdateOvnDnatRuleQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.addOrUpdateOvnDnatRuleQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+func (c *Controller) processNextDeleteOvnDnatRuleWorkItem() bool {
+	obj, shutdown := c.delOvnDnatRuleQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.delOvnDnatRuleQueue.Done(obj)
+		var dnat *kubeovnv1.OvnDnatRule
+		var ok bool
+		if dnat, ok = obj.(*kubeovnv1.OvnDnatRule); !ok {
+			c.delOvnDnatRuleQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected ovn dnat rule in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleDelOvnDnatRule(dnat); err != nil {
+			c.delOvnDnatRuleQueue.AddRateLimited(obj)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", dnat.Name, err.Error())
+		}
+		c.delOvnDnatRuleQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+// ovnDnatLbName returns the name of the load balancer implementing the dnat rule,
+// nat rules of ovn can not translate ports so a router load balancer is used instead
+func ovnDnatLbName(name string) string {
+	return fmt.Sprintf("ovn-dnat-%s", name)
+}
+
+func parseOvnDnatPort(port string) error {
+	p, err := strconv.Atoi(port)
+	if err != nil || p < 1 || p > 65535 {
+		return fmt.Errorf("invalid port %q", port)
+	}
+	return nil
+}
+
+func (c *Controller) handleAddOrUpdateOvnDnatRule(key string) error {
+	cachedDnat, err := c.ovnDnatRulesLister.Get(key)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	dnat := cachedDnat.DeepCopy()
+	klog.Infof("handle add or update ovn dnat rule %s", key)
+
+	for _, port := range []string{dnat.Spec.InternalPort, dnat.Spec.ExternalPort} {
+		if err = parseOvnDnatPort(port); err != nil {
+			klog.Errorf("failed to parse ports of ovn dnat rule %s, %v", key, err)
+			return err
+		}
+	}
+	protocol := dnat.Spec.Protocol
+	if protocol == "" {
+		protocol = util.ProtocolTCP
+	}
+	if protocol != util.ProtocolTCP && protocol != util.ProtocolUDP {
+		return fmt.Errorf("invalid protocol %q of ovn dnat rule %s", protocol, key)
+	}
+
+	eip, err := c.getReadyOvnEip(dnat.Spec.OvnEip)
+	if err != nil {
+		return err
+	}
+	ip, subnet, err := c.getOvnNatInternalIP(dnat.Spec.IpName)
+	if err != nil {
+		return err
+	}
+	vpcName, v4Eip, v4Ip := subnet.Spec.Vpc, eip.Status.V4ip, ip.Spec.V4IPAddress
+	vip := net.JoinHostPort(v4Eip, dnat.Spec.ExternalPort)
+	backend := net.JoinHostPort(v4Ip, dnat.Spec.InternalPort)
+
+	lbName := ovnDnatLbName(dnat.Name)
+	if dnat.Status.Vpc != "" && dnat.Status.Vpc != vpcName {
+		if err = c.ovnClient.LogicalRouterUpdateLoadBalancers(dnat.Status.Vpc, ovsdb.MutateOperationDelete, lbName); err != nil {
+			klog.Errorf("failed to remove load balancer %s from vpc %s, %v", lbName, dnat.Status.Vpc, err)
+			return err
+		}
+	}
+	lb, err := c.ovnClient.GetLoadBalancer(lbName, true)
+	if err != nil {
+		klog.Errorf("failed to get load balancer %s, %v", lbName, err)
+		return err
+	}
+	if lb != nil && (lb.Protocol == nil || *lb.Protocol != protocol) {
+		if err = c.ovnClient.LogicalRouterUpdateLoadBalancers(vpcName, ovsdb.MutateOperationDelete, lbName); err != nil {
+			klog.Errorf("failed to remove load balancer %s from vpc %s, %v", lbName, vpcName, err)
+			return err
+		}
+		if err = c.ovnClient.DeleteLoadBalancers(lbName); err != nil {
+			klog.Errorf("failed to delete load balancer %s, %v", lbName, err)
+			return err
+		}
+		lb = nil
+	}
+	if lb == nil {
+		if err = c.ovnClient.CreateLoadBalancer(lbName, protocol, ""); err != nil {
+			klog.Errorf("failed to create load balancer %s, %v", lbName, err)
+			return err
+		}
+	} else {
+		for existing := range lb.Vips {
+			if existing == vip {
+				continue
+			}
+			if err = c.ovnClient.LoadBalancerDeleteVip(lbName, existing); err != nil {
+				klog.Errorf("failed to delete vip %s from load balancer %s, %v", existing, lbName, err)
+				return err
+			}
+		}
+	}
+	if err = c.ovnClient.LoadBalancerAddVip(lbName, vip, backend); err != nil {
+		klog.Errorf("failed to add vip %s to load balancer %s, %v", vip, lbName, err)
+		return err
+	}
+	if err = c.ovnClient.LogicalRouterUpdateLoadBalancers(vpcName, ovsdb.MutateOperationInsert, lbName); err != nil {
+		klog.Errorf("failed to add load balancer %s to vpc %s, %v", lbName, vpcName, err)
+		return err
+	}
+
+	if dnat.Status.Ready && dnat.Status.Vpc == vpcName && dnat.Status.V4Eip == v4Eip && dnat.Status.V4Ip == v4Ip {
+		return nil
+	}
+	dnat.Status = kubeovnv1.OvnDnatRuleStatus{Ready: true, Vpc: vpcName, V4Eip: v4Eip, V4Ip: v4Ip}
+	bytes, err := dnat.Status.Bytes()
+	if err != nil {
+		return err
+	}
+	if _, err = c.config.KubeOvnClient.KubeovnV1().OvnDnatRules().Patch(context.Background(), key, types.MergePatchType,
+		bytes, metav1.PatchOptions{}, "status"); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		klog.Errorf("failed to patch ovn dnat rule %s, %v", key, err)
+		return err
+	}
+	return nil
+}
+
+func (c *Controller) handleDelOvnDnatRule(dnat *kubeovnv1.OvnDnatRule) error {
+	klog.Infof("delete ovn dnat rule %s", dnat.Name)
+	lbName := ovnDnatLbName(dnat.Name)
+	if dnat.Status.Vpc != "" {
+		if err := c.ovnClient.LogicalRouterUpdateLoadBalancers(dnat.Status.Vpc, ovsdb.MutateOperationDelete, lbName); err != nil {
+			klog.Errorf("failed to remove load balancer %s from vpc %s, %v", lbName, dnat.Status.Vpc, err)
+			return err
+		}
+	}
+	if err := c.ovnClient.DeleteLoadBalancers(lbName); err != nil {
+		klog.Errorf("failed to delete load balancer %s, %v", lbName, err)
+		return err
+	}
+	return nil
+}
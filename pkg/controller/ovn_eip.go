@@ -0,0 +1,366 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// ovnEipTypeLrp is the type of the eips held by the gateway ports of vpcs
+const ovnEipTypeLrp = "lrp"
+
+func (c *Controller) enqueueAddOvnEip(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(obj); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	klog.V(3).Infof("enqueue add ovn eip %s", key)
+	c.addOrUpdateOvnEipQueue.Add(key)
+}
+
+func (c *Controller) enqueueUpdateOvnEip(old, new interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(new); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	oldEip := old.(*kubeovnv1.OvnEip)
+	newEip := new.(*kubeovnv1.OvnEip)
+	if oldEip.Spec != newEip.Spec || oldEip.Status.Ready != newEip.Status.Ready ||
+		oldEip.DeletionTimestamp.IsZero() != newEip.DeletionTimestamp.IsZero() {
+		klog.V(3).Infof("enqueue update ovn eip %s", key)
+		c.addOrUpdateOvnEipQueue.Add(key)
+	}
+}
+
+func (c *Controller) enqueueDelOvnEip(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(obj); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	klog.V(3).Infof("enqueue delete ovn eip %s", key)
+	c.delOvnEipQueue.Add(obj)
+	c.updateSubnetStatusQueue.Add(util.VpcExternalNet)
+}
+
+func (c *Controller) runAddOrUpdateOvnEipWorker() {
+	for c.processNextAddOrUpdateOvnEipWorkItem() {
+	}
+}
+
+func (c *Controller) runDelOvnEipWorker() {
+	for c.processNextDeleteOvnEipWorkItem() {
+	}
+}
+
+func (c *Controller) processNextAddOrUpdateOvnEipWorkItem() bool {
+	obj, shutdown := c.addOrUpdateOvnEipQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.addOrUpdateOvnEipQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.addOrUpdateOvnEipQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleAddOrUpdateOvnEip(key); err != nil {
+			c.addOrUpdateOvnEipQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.addOrUpdateOvnEipQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+func (c *Controller) processNextDeleteOvnEipWorkItem() bool {
+	obj, shutdown := c.delOvnEipQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.delOvnEipQueue.Done(obj)
+		var eip *kubeovnv1.OvnEip
+		var ok bool
+		if eip, ok = obj.(*kubeovnv1.OvnEip); !ok {
+			c.delOvnEipQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected ovn eip in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleDelOvnEip(eip); err != nil {
+			c.delOvnEipQueue.AddRateLimited(obj)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", eip.Name, err.Error())
+		}
+		c.delOvnEipQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+// handleAddOrUpdateOvnEip allocates the address of the eip from the external subnet,
+// the nat rules using the eip are resynced once the address changes
+func (c *Controller) handleAddOrUpdateOvnEip(key string) error {
+	cachedEip, err := c.ovnEipsLister.Get(key)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !cachedEip.DeletionTimestamp.IsZero() {
+		if _, err = c.handleOvnEipFinalizer(cachedEip.DeepCopy()); err != nil {
+			klog.Errorf("failed to handle finalizer for ovn eip %s, %v", key, err)
+			return err
+		}
+		return nil
+	}
+	if cachedEip.Status.Ready && cachedEip.Spec.V4ip == cachedEip.Status.V4ip && util.ContainsString(cachedEip.Finalizers, util.ControllerName) {
+		return nil
+	}
+	klog.Infof("handle add or update ovn eip %s", key)
+
+	eip := cachedEip.DeepCopy()
+	changed := eip.Status.V4ip != "" && eip.Spec.V4ip != eip.Status.V4ip
+	if changed {
+		c.ipam.ReleaseAddressByPod(key)
+	}
+	var v4ip, mac string
+	if eip.Spec.V4ip != "" {
+		v4ip, _, mac, err = c.acquireStaticEip(eip.Name, "", eip.Name, eip.Spec.V4ip)
+	} else {
+		v4ip, _, mac, err = c.acquireEip(eip.Name, "", eip.Name)
+	}
+	if err != nil {
+		klog.Errorf("failed to allocate address for ovn eip %s, %v", key, err)
+		return err
+	}
+
+	// the finalizer keeps the eip until no nat rule uses it
+	if eip.Spec.V4ip != v4ip || eip.Spec.MacAddress != mac || eip.Labels[util.SubnetNameLabel] != util.VpcExternalNet ||
+		!util.ContainsString(eip.Finalizers, util.ControllerName) {
+		eip.Spec.V4ip = v4ip
+		eip.Spec.MacAddress = mac
+		if eip.Labels == nil {
+			eip.Labels = map[string]string{}
+		}
+		eip.Labels[util.SubnetNameLabel] = util.VpcExternalNet
+		if !util.ContainsString(eip.Finalizers, util.ControllerName) {
+			eip.Finalizers = append(eip.Finalizers, util.ControllerName)
+		}
+		if eip, err = c.config.KubeOvnClient.KubeovnV1().OvnEips().Update(context.Background(), eip, metav1.UpdateOptions{}); err != nil {
+			klog.Errorf("failed to update ovn eip %s, %v", key, err)
+			return err
+		}
+	}
+	if err = c.patchOvnEipStatus(eip.Name, v4ip, mac, true); err != nil {
+		klog.Errorf("failed to patch status of ovn eip %s, %v", key, err)
+		return err
+	}
+	c.updateSubnetStatusQueue.Add(util.VpcExternalNet)
+	if eip.Spec.Type == ovnEipTypeLrp && eip.Labels[util.VpcNameLabel] != "" {
+		c.addOrUpdateVpcQueue.Add(eip.Labels[util.VpcNameLabel])
+	}
+
+	if changed {
+		if err = c.resyncOvnNatsOfEip(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleDelOvnEip releases the address of the eip, which is already done by the finalizer
+// unless the eip is deleted without it
+func (c *Controller) handleDelOvnEip(eip *kubeovnv1.OvnEip) error {
+	klog.Infof("release address of ovn eip %s", eip.Name)
+	c.ipam.ReleaseAddressByPod(eip.Name)
+	return nil
+}
+
+// handleOvnEipFinalizer removes the finalizer from the eip being deleted once no ovn fip, snat or dnat rule
+// uses it any more, the finalizer is added when the address is allocated. True is returned if the eip can go away
+func (c *Controller) handleOvnEipFinalizer(eip *kubeovnv1.OvnEip) (bool, error) {
+	if !util.ContainsString(eip.Finalizers, util.ControllerName) {
+		return true, nil
+	}
+	fips, snats, dnats, err := c.getOvnNatsOfEip(eip.Name)
+	if err != nil {
+		return false, err
+	}
+	if len(fips) != 0 || len(snats) != 0 || len(dnats) != 0 {
+		// the eip is enqueued again once the nat rules are deleted
+		klog.Infof("ovn eip %s is still used by fips [%s], snat rules [%s] and dnat rules [%s]", eip.Name,
+			strings.Join(fips, ","), strings.Join(snats, ","), strings.Join(dnats, ","))
+		return false, nil
+	}
+
+	klog.Infof("release address of ovn eip %s", eip.Name)
+	c.ipam.ReleaseAddressByPod(eip.Name)
+	eip.Finalizers = util.RemoveString(eip.Finalizers, util.ControllerName)
+	raw, _ := json.Marshal(eip.Finalizers)
+	patchPayloadTemplate := `[{ "op": "replace", "path": "/metadata/finalizers", "value": %s }]`
+	patchPayload := fmt.Sprintf(patchPayloadTemplate, raw)
+	if _, err := c.config.KubeOvnClient.KubeovnV1().OvnEips().Patch(context.Background(), eip.Name, types.JSONPatchType, []byte(patchPayload), metav1.PatchOptions{}); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return true, nil
+		}
+		klog.Errorf("failed to remove finalizer from ovn eip %s, %v", eip.Name, err)
+		return false, err
+	}
+	c.updateSubnetStatusQueue.Add(util.VpcExternalNet)
+	return true, nil
+}
+
+// enqueueOvnEipOfNat enqueues the eip used by a deleted nat rule or a nat rule switching to another eip,
+// so that the finalizer of the eip being deleted can be removed
+func (c *Controller) enqueueOvnEipOfNat(eipName string) {
+	if eipName != "" {
+		c.addOrUpdateOvnEipQueue.Add(eipName)
+	}
+}
+
+// getOvnNatsOfEip returns the names of the ovn fips, snat and dnat rules using the eip
+func (c *Controller) getOvnNatsOfEip(eipName string) (fipNames, snatNames, dnatNames []string, err error) {
+	fips, err := c.ovnFipsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list ovn fips, %v", err)
+		return nil, nil, nil, err
+	}
+	for _, fip := range fips {
+		if fip.Spec.OvnEip == eipName {
+			fipNames = append(fipNames, fip.Name)
+		}
+	}
+
+	snats, err := c.ovnSnatRulesLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list ovn snat rules, %v", err)
+		return nil, nil, nil, err
+	}
+	for _, snat := range snats {
+		if snat.Spec.OvnEip == eipName {
+			snatNames = append(snatNames, snat.Name)
+		}
+	}
+
+	dnats, err := c.ovnDnatRulesLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list ovn dnat rules, %v", err)
+		return nil, nil, nil, err
+	}
+	for _, dnat := range dnats {
+		if dnat.Spec.OvnEip == eipName {
+			dnatNames = append(dnatNames, dnat.Name)
+		}
+	}
+	return fipNames, snatNames, dnatNames, nil
+}
+
+// resyncOvnNatsOfEip enqueues the ovn fips, snat and dnat rules using the eip
+func (c *Controller) resyncOvnNatsOfEip(eipName string) error {
+	fips, snats, dnats, err := c.getOvnNatsOfEip(eipName)
+	if err != nil {
+		return err
+	}
+	for _, fip := range fips {
+		c.addOrUpdateOvnFipQueue.Add(fip)
+	}
+	for _, snat := range snats {
+		c.addOrUpdateOvnSnatRuleQueue.Add(snat)
+	}
+	for _, dnat := range dnats {
+		c.addOrUpdateOvnDnatRuleQueue.Add(dnat)
+	}
+	return nil
+}
+
+// getReadyOvnEip returns the eip used by a nat rule, an error is returned if it is not ready
+func (c *Controller) getReadyOvnEip(name string) (*kubeovnv1.OvnEip, error) {
+	eip, err := c.ovnEipsLister.Get(name)
+	if err != nil {
+		klog.Errorf("failed to get ovn eip %s, %v", name, err)
+		return nil, err
+	}
+	if !eip.Status.Ready || eip.Status.V4ip == "" {
+		return nil, fmt.Errorf("ovn eip %s is not ready", name)
+	}
+	if !eip.DeletionTimestamp.IsZero() {
+		return nil, fmt.Errorf("ovn eip %s is being deleted", name)
+	}
+	if eip.Spec.Type == ovnEipTypeLrp {
+		return nil, fmt.Errorf("ovn eip %s is used by the gateway port of vpc", name)
+	}
+	return eip, nil
+}
+
+func (c *Controller) patchOvnEipStatus(key, v4ip, mac string, ready bool) error {
+	cachedEip, err := c.ovnEipsLister.Get(key)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	eip := cachedEip.DeepCopy()
+	if eip.Status.Ready == ready && eip.Status.V4ip == v4ip && eip.Status.MacAddress == mac {
+		return nil
+	}
+	eip.Status.Ready = ready
+	eip.Status.V4ip = v4ip
+	eip.Status.MacAddress = mac
+	bytes, err := eip.Status.Bytes()
+	if err != nil {
+		return err
+	}
+	if _, err = c.config.KubeOvnClient.KubeovnV1().OvnEips().Patch(context.Background(), key, types.MergePatchType,
+		bytes, metav1.PatchOptions{}, "status"); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		klog.Errorf("failed to patch ovn eip %s, %v", key, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func newOvnNatTestController(t *testing.T, kubeovnObjects ...runtime.Object) *fakeController {
+	t.Helper()
+	external := &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: util.VpcExternalNet},
+		Spec: kubeovnv1.SubnetSpec{
+			CIDRBlock:  "172.18.0.0/24",
+			Gateway:    "172.18.0.1",
+			ExcludeIps: []string{"172.18.0.1"},
+			Protocol:   kubeovnv1.ProtocolIPv4,
+		},
+	}
+	ctrl := newFakeController(t, nil, append([]runtime.Object{external}, kubeovnObjects...))
+	require.NoError(t, ctrl.ipam.AddOrUpdateSubnet(external.Name, external.Spec.CIDRBlock, external.Spec.Gateway, external.Spec.ExcludeIps))
+	return ctrl
+}
+
+// addOvnEip adds the eip to the fake clientset and the informer cache, objects of resources
+// with hyphenated names can not be added by the object tracker of the fake clientset
+func addOvnEip(t *testing.T, ctrl *fakeController, eip *kubeovnv1.OvnEip) {
+	t.Helper()
+	eip, err := ctrl.kubeovnClient.KubeovnV1().OvnEips().Create(context.Background(), eip, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, ctrl.kubeovnInformerFactory.Kubeovn().V1().OvnEips().Informer().GetIndexer().Add(eip))
+}
+
+func Test_handleAddOrUpdateOvnEip(t *testing.T) {
+	ctrl := newOvnNatTestController(t)
+	addOvnEip(t, ctrl, &kubeovnv1.OvnEip{
+		ObjectMeta: metav1.ObjectMeta{Name: "eip-static"},
+		Spec:       kubeovnv1.OvnEipSpec{V4ip: "172.18.0.10"},
+	})
+	addOvnEip(t, ctrl, &kubeovnv1.OvnEip{
+		ObjectMeta: metav1.ObjectMeta{Name: "eip-lrp", Labels: map[string]string{util.VpcNameLabel: "test-vpc"}},
+		Spec:       kubeovnv1.OvnEipSpec{Type: ovnEipTypeLrp},
+	})
+
+	require.NoError(t, ctrl.handleAddOrUpdateOvnEip("eip-static"))
+	eip, err := ctrl.kubeovnClient.KubeovnV1().OvnEips().Get(context.Background(), "eip-static", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.True(t, eip.Status.Ready)
+	require.Equal(t, "172.18.0.10", eip.Status.V4ip)
+	require.NotEmpty(t, eip.Status.MacAddress)
+	require.Equal(t, eip.Status.MacAddress, eip.Spec.MacAddress)
+	require.Equal(t, util.VpcExternalNet, eip.Labels[util.SubnetNameLabel])
+	require.Equal(t, 0, ctrl.addOrUpdateVpcQueue.Len())
+
+	// the address in use can not be allocated again
+	addOvnEip(t, ctrl, &kubeovnv1.OvnEip{
+		ObjectMeta: metav1.ObjectMeta{Name: "eip-conflict"},
+		Spec:       kubeovnv1.OvnEipSpec{V4ip: "172.18.0.10"},
+	})
+	require.Error(t, ctrl.handleAddOrUpdateOvnEip("eip-conflict"))
+
+	require.NoError(t, ctrl.handleAddOrUpdateOvnEip("eip-lrp"))
+	eip, err = ctrl.kubeovnClient.KubeovnV1().OvnEips().Get(context.Background(), "eip-lrp", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.True(t, eip.Status.Ready)
+	require.NotEmpty(t, eip.Status.V4ip)
+	require.NotEqual(t, "172.18.0.10", eip.Status.V4ip)
+	require.Equal(t, 1, ctrl.addOrUpdateVpcQueue.Len())
+
+	require.NoError(t, ctrl.handleDelOvnEip(eip))
+	require.Empty(t, ctrl.ipam.GetPodAddress("eip-lrp"))
+}
+
+func Test_handleOvnEipFinalizer(t *testing.T) {
+	ctrl := newOvnNatTestController(t)
+	addOvnEip(t, ctrl, &kubeovnv1.OvnEip{
+		ObjectMeta: metav1.ObjectMeta{Name: "eip"},
+		Spec:       kubeovnv1.OvnEipSpec{V4ip: "172.18.0.10"},
+	})
+	require.NoError(t, ctrl.handleAddOrUpdateOvnEip("eip"))
+	eip, err := ctrl.kubeovnClient.KubeovnV1().OvnEips().Get(context.Background(), "eip", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Contains(t, eip.Finalizers, util.ControllerName)
+
+	fip := &kubeovnv1.OvnFip{
+		ObjectMeta: metav1.ObjectMeta{Name: "fip"},
+		Spec:       kubeovnv1.OvnFipSpec{OvnEip: eip.Name, IpName: "pod1.ns"},
+	}
+	fipIndexer := ctrl.kubeovnInformerFactory.Kubeovn().V1().OvnFips().Informer().GetIndexer()
+	require.NoError(t, fipIndexer.Add(fip))
+
+	now := metav1.Now()
+	eip.DeletionTimestamp = &now
+	eip, err = ctrl.kubeovnClient.KubeovnV1().OvnEips().Update(context.Background(), eip, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, ctrl.kubeovnInformerFactory.Kubeovn().V1().OvnEips().Informer().GetIndexer().Update(eip))
+
+	// the eip used by the fip is kept
+	require.NoError(t, ctrl.handleAddOrUpdateOvnEip(eip.Name))
+	eip, err = ctrl.kubeovnClient.KubeovnV1().OvnEips().Get(context.Background(), eip.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Contains(t, eip.Finalizers, util.ControllerName)
+	require.NotEmpty(t, ctrl.ipam.GetPodAddress(eip.Name))
+	_, err = ctrl.getReadyOvnEip(eip.Name)
+	require.Error(t, err)
+
+	// and released once the fip is gone
+	require.NoError(t, fipIndexer.Delete(fip))
+	ctrl.enqueueOvnEipOfNat(fip.Spec.OvnEip)
+	require.Equal(t, 1, ctrl.addOrUpdateOvnEipQueue.Len())
+	require.NoError(t, ctrl.handleAddOrUpdateOvnEip(eip.Name))
+	eip, err = ctrl.kubeovnClient.KubeovnV1().OvnEips().Get(context.Background(), eip.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotContains(t, eip.Finalizers, util.ControllerName)
+	require.Empty(t, ctrl.ipam.GetPodAddress(eip.Name))
+}
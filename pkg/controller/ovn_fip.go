@@ -0,0 +1,238 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+)
+
+func (c *Controller) enqueueAddOvnFip(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(obj); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	klog.V(3).Infof("enqueue add ovn fip %s", key)
+	c.addOrUpdateOvnFipQueue.Add(key)
+}
+
+func (c *Controller) enqueueUpdateOvnFip(old, new interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(new); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	oldFip := old.(*kubeovnv1.OvnFip)
+	newFip := new.(*kubeovnv1.OvnFip)
+	if oldFip.Spec != newFip.Spec {
+		klog.V(3).Infof("enqueue update ovn fip %s", key)
+		c.addOrUpdateOvnFipQueue.Add(key)
+	}
+	if oldFip.Spec.OvnEip != newFip.Spec.OvnEip {
+		c.enqueueOvnEipOfNat(oldFip.Spec.OvnEip)
+	}
+}
+
+func (c *Controller) enqueueDelOvnFip(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(obj); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	klog.V(3).Infof("enqueue delete ovn fip %s", key)
+	c.delOvnFipQueue.Add(obj)
+	if fip, ok := obj.(*kubeovnv1.OvnFip); ok {
+		c.enqueueOvnEipOfNat(fip.Spec.OvnEip)
+	}
+}
+
+func (c *Controller) runAddOrUpdateOvnFipWorker() {
+	for c.processNextAddOrUpdateOvnFipWorkItem() {
+	}
+}
+
+func (c *Controller) runDelOvnFipWorker() {
+	for c.processNextDeleteOvnFipWorkItem() {
+	}
+}
+
+func (c *Controller) processNextAddOrUpdateOvnFipWorkItem() bool {
+	obj, shutdown := c.addOrUpdateOvnFipQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.addOrUpdateOvnFipQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.addOrUpdateOvnFipQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleAddOrUpdateOvnFip(key); err != nil {
+			c.addOrUpdateOvnFipQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.addOrUpdateOvnFipQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+func (c *Controller) processNextDeleteOvnFipWorkItem() bool {
+	obj, shutdown := c.delOvnFipQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.delOvnFipQueue.Done(obj)
+		var fip *kubeovnv1.OvnFip
+		var ok bool
+		if fip, ok = obj.(*kubeovnv1.OvnFip); !ok {
+			c.delOvnFipQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected ovn fip in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleDelOvnFip(fip); err != nil {
+			c.delOvnFipQueue.AddRateLimited(obj)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", fip.Name, err.Error())
+		}
+		c.delOvnFipQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+// handleAddOrUpdateOvnFip programs a distributed dnat_and_snat rule of the eip and the internal address
+// on the router of the vpc the address belongs to
+func (c *Controller) handleAddOrUpdateOvnFip(key string) error {
+	cachedFip, err := c.ovnFipsLister.Get(key)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	fip := cachedFip.DeepCopy()
+
+	eip, err := c.getReadyOvnEip(fip.Spec.OvnEip)
+	if err != nil {
+		return err
+	}
+	ip, subnet, err := c.getOvnNatInternalIP(fip.Spec.IpName)
+	if err != nil {
+		return err
+	}
+	vpcName, v4Eip, v4Ip := subnet.Spec.Vpc, eip.Status.V4ip, ip.Spec.V4IPAddress
+	if fip.Status.Ready && fip.Status.Vpc == vpcName && fip.Status.V4Eip == v4Eip && fip.Status.V4Ip == v4Ip {
+		return nil
+	}
+	klog.Infof("handle add or update ovn fip %s", key)
+
+	if fip.Status.Vpc != "" && fip.Status.V4Ip != "" && (fip.Status.Vpc != vpcName || fip.Status.V4Ip != v4Ip) {
+		if err = c.ovnClient.UpdateDnatAndSnat(fip.Status.Vpc, "", fip.Status.V4Ip, "", "", ""); err != nil {
+			klog.Errorf("failed to delete stale fip of %s, %v", fip.Status.V4Ip, err)
+			return err
+		}
+	}
+	portName := ovs.PodNameToPortName(ip.Spec.PodName, ip.Spec.Namespace, subnet.Spec.Provider)
+	if err = c.ovnClient.UpdateDnatAndSnat(vpcName, v4Eip, v4Ip, portName, ip.Spec.MacAddress, kubeovnv1.GWDistributedType); err != nil {
+		klog.Errorf("failed to add fip %s to %s, %v", v4Eip, v4Ip, err)
+		return err
+	}
+
+	fip.Status = kubeovnv1.OvnFipStatus{Ready: true, Vpc: vpcName, V4Eip: v4Eip, V4Ip: v4Ip}
+	bytes, err := fip.Status.Bytes()
+	if err != nil {
+		return err
+	}
+	if _, err = c.config.KubeOvnClient.KubeovnV1().OvnFips().Patch(context.Background(), key, types.MergePatchType,
+		bytes, metav1.PatchOptions{}, "status"); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		klog.Errorf("failed to patch ovn fip %s, %v", key, err)
+		return err
+	}
+	return nil
+}
+
+func (c *Controller) handleDelOvnFip(fip *kubeovnv1.OvnFip) error {
+	if fip.Status.Vpc == "" || fip.Status.V4Ip == "" {
+		return nil
+	}
+	klog.Infof("delete ovn fip %s", fip.Name)
+	if err := c.ovnClient.UpdateDnatAndSnat(fip.Status.Vpc, "", fip.Status.V4Ip, "", "", ""); err != nil {
+		klog.Errorf("failed to delete fip of %s, %v", fip.Status.V4Ip, err)
+		return err
+	}
+	return nil
+}
+
+// getOvnNatInternalIP returns the ip crd used by an ovn nat rule and its subnet,
+// the subnet must be in a vpc connected to the external subnet
+func (c *Controller) getOvnNatInternalIP(ipName string) (*kubeovnv1.IP, *kubeovnv1.Subnet, error) {
+	ip, err := c.ipsLister.Get(ipName)
+	if err != nil {
+		klog.Errorf("failed to get ip %s, %v", ipName, err)
+		return nil, nil, err
+	}
+	if ip.Spec.V4IPAddress == "" {
+		return nil, nil, fmt.Errorf("ip %s has no ipv4 address", ipName)
+	}
+	subnet, err := c.subnetsLister.Get(ip.Spec.Subnet)
+	if err != nil {
+		klog.Errorf("failed to get subnet %s, %v", ip.Spec.Subnet, err)
+		return nil, nil, err
+	}
+	if err = c.checkVpcExternal(subnet.Spec.Vpc); err != nil {
+		return nil, nil, err
+	}
+	return ip, subnet, nil
+}
+
+func (c *Controller) checkVpcExternal(vpcName string) error {
+	vpc, err := c.vpcsLister.Get(vpcName)
+	if err != nil {
+		klog.Errorf("failed to get vpc %s, %v", vpcName, err)
+		return err
+	}
+	if !vpc.Spec.EnableExternal {
+		return fmt.Errorf("vpc %s is not connected to the external subnet", vpcName)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func Test_handleAddOrUpdateOvnFip(t *testing.T) {
+	vpc := &kubeovnv1.Vpc{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpc"},
+		Spec:       kubeovnv1.VpcSpec{EnableExternal: true},
+	}
+	subnet := &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpc-subnet"},
+		Spec: kubeovnv1.SubnetSpec{
+			Vpc:       vpc.Name,
+			CIDRBlock: "10.0.0.0/24",
+			Gateway:   "10.0.0.1",
+			Protocol:  kubeovnv1.ProtocolIPv4,
+			Provider:  util.OvnProvider,
+		},
+	}
+	ip := &kubeovnv1.IP{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod1.ns"},
+		Spec: kubeovnv1.IPSpec{
+			PodName:     "pod1",
+			Namespace:   "ns",
+			Subnet:      subnet.Name,
+			V4IPAddress: "10.0.0.10",
+			MacAddress:  "00:00:00:00:00:01",
+		},
+	}
+	ctrl := newOvnNatTestController(t, vpc, subnet, ip)
+	require.NoError(t, ctrl.legacyClient.CreateLogicalRouter(vpc.Name))
+	addOvnEip(t, ctrl, &kubeovnv1.OvnEip{
+		ObjectMeta: metav1.ObjectMeta{Name: "eip"},
+		Spec:       kubeovnv1.OvnEipSpec{V4ip: "172.18.0.10"},
+	})
+
+	fip := &kubeovnv1.OvnFip{
+		ObjectMeta: metav1.ObjectMeta{Name: "fip"},
+		Spec:       kubeovnv1.OvnFipSpec{OvnEip: "eip", IpName: ip.Name},
+	}
+	fip, err := ctrl.kubeovnClient.KubeovnV1().OvnFips().Create(context.Background(), fip, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, ctrl.kubeovnInformerFactory.Kubeovn().V1().OvnFips().Informer().GetIndexer().Add(fip))
+
+	// the eip is not ready yet
+	require.Error(t, ctrl.handleAddOrUpdateOvnFip(fip.Name))
+
+	require.NoError(t, ctrl.handleAddOrUpdateOvnEip("eip"))
+	eip, err := ctrl.kubeovnClient.KubeovnV1().OvnEips().Get(context.Background(), "eip", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NoError(t, ctrl.kubeovnInformerFactory.Kubeovn().V1().OvnEips().Informer().GetIndexer().Update(eip))
+
+	require.NoError(t, ctrl.handleAddOrUpdateOvnFip(fip.Name))
+	nats, err := ctrl.ovnClient.ListNats(vpc.Name, ovnnb.NATTypeDNATAndSNAT, ip.Spec.V4IPAddress)
+	require.NoError(t, err)
+	require.Len(t, nats, 1)
+	require.Equal(t, "172.18.0.10", nats[0].ExternalIP)
+
+	fip, err = ctrl.kubeovnClient.KubeovnV1().OvnFips().Get(context.Background(), fip.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, kubeovnv1.OvnFipStatus{Ready: true, Vpc: vpc.Name, V4Eip: "172.18.0.10", V4Ip: "10.0.0.10"}, fip.Status)
+
+	require.NoError(t, ctrl.handleDelOvnFip(fip))
+	nats, err = ctrl.ovnClient.ListNats(vpc.Name, ovnnb.NATTypeDNATAndSNAT, ip.Spec.V4IPAddress)
+	require.NoError(t, err)
+	require.Empty(t, nats)
+}
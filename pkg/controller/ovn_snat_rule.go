@@ -0,0 +1,224 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func (c *Controller) enqueueAddOvnSnatRule(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(obj); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	klog.V(3).Infof("enqueue add ovn snat rule %s", key)
+	c.addOrUpdateOvnSnatRuleQueue.Add(key)
+}
+
+func (c *Controller) enqueueUpdateOvnSnatRule(old, new interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(new); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	oldSnat := old.(*kubeovnv1.OvnSnatRule)
+	newSnat := new.(*kubeovnv1.OvnSnatRule)
+	if oldSnat.Spec != newSnat.Spec {
+		klog.V(3).Infof("enqueue update ovn snat rule %s", key)
+		c.addOrUpdateOvnSnatRuleQueue.Add(key)
+	}
+	if oldSnat.Spec.OvnEip != newSnat.Spec.OvnEip {
+		c.enqueueOvnEipOfNat(oldSnat.Spec.OvnEip)
+	}
+}
+
+func (c *Controller) enqueueDelOvnSnatRule(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(obj); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	klog.V(3).Infof("enqueue delete ovn snat rule %s", key)
+	c.delOvnSnatRuleQueue.Add(obj)
+	if snat, ok := obj.(*kubeovnv1.OvnSnatRule); ok {
+		c.enqueueOvnEipOfNat(snat.Spec.OvnEip)
+	}
+}
+
+func (c *Controller) runAddOrUpdateOvnSnatRuleWorker() {
+	for c.processNextAddOrUpdateOvnSnatRuleWorkItem() {
+	}
+}
+
+func (c *Controller) runDelOvnSnatRuleWorker() {
+	for c.processNextDeleteOvnSnatRuleWorkItem() {
+	}
+}
+
+func (c *Controller) processNextAddOrUpdateOvnSnatRuleWorkItem() bool {
+	obj, shutdown := c.addOrUpdateOvnSnatRuleQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.addOrUpdateOvnSnatRuleQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.addOrUpdateOvnSnatRuleQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleAddOrUpdateOvnSnatRule(key); err != nil {
+			c.addOrUpdateOvnSnatRuleQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.addOrUpdateOvnSnatRuleQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+func (c *Controller) processNextDeleteOvnSnatRuleWorkItem() bool {
+	obj, shutdown := c.delOvnSnatRuleQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.delOvnSnatRuleQueue.Done(obj)
+		var snat *kubeovnv1.OvnSnatRule
+		var ok bool
+		if snat, ok = obj.(*kubeovnv1.OvnSnatRule); !ok {
+			c.delOvnSnatRuleQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected ovn snat rule in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleDelOvnSnatRule(snat); err != nil {
+			c.delOvnSnatRuleQueue.AddRateLimited(obj)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", snat.Name, err.Error())
+		}
+		c.delOvnSnatRuleQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+// handleAddOrUpdateOvnSnatRule programs snat rules of the eip for all ipv4 cidr blocks of the subnet
+func (c *Controller) handleAddOrUpdateOvnSnatRule(key string) error {
+	cachedSnat, err := c.ovnSnatRulesLister.Get(key)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	snat := cachedSnat.DeepCopy()
+
+	eip, err := c.getReadyOvnEip(snat.Spec.OvnEip)
+	if err != nil {
+		return err
+	}
+	subnet, err := c.subnetsLister.Get(snat.Spec.VpcSubnet)
+	if err != nil {
+		klog.Errorf("failed to get subnet %s, %v", snat.Spec.VpcSubnet, err)
+		return err
+	}
+	if err = c.checkVpcExternal(subnet.Spec.Vpc); err != nil {
+		return err
+	}
+	var cidrs []string
+	for _, cidr := range util.SubnetCIDRBlocks(subnet) {
+		if util.CheckProtocol(cidr) == kubeovnv1.ProtocolIPv4 {
+			cidrs = append(cidrs, cidr)
+		}
+	}
+	if len(cidrs) == 0 {
+		return fmt.Errorf("subnet %s has no ipv4 cidr block", subnet.Name)
+	}
+
+	vpcName, v4Eip, v4IpCidr := subnet.Spec.Vpc, eip.Status.V4ip, strings.Join(cidrs, ",")
+	if snat.Status.Ready && snat.Status.Vpc == vpcName && snat.Status.V4Eip == v4Eip && snat.Status.V4IpCidr == v4IpCidr {
+		return nil
+	}
+	klog.Infof("handle add or update ovn snat rule %s", key)
+
+	for _, cidr := range strings.Split(snat.Status.V4IpCidr, ",") {
+		if cidr == "" || (snat.Status.Vpc == vpcName && util.ContainsString(cidrs, cidr)) {
+			continue
+		}
+		if err = c.ovnClient.UpdateSnat(snat.Status.Vpc, "", cidr); err != nil {
+			klog.Errorf("failed to delete stale snat of %s, %v", cidr, err)
+			return err
+		}
+	}
+	for _, cidr := range cidrs {
+		if err = c.ovnClient.UpdateSnat(vpcName, v4Eip, cidr); err != nil {
+			klog.Errorf("failed to add snat %s to %s, %v", v4Eip, cidr, err)
+			return err
+		}
+	}
+
+	snat.Status = kubeovnv1.OvnSnatRuleStatus{Ready: true, Vpc: vpcName, V4Eip: v4Eip, V4IpCidr: v4IpCidr}
+	bytes, err := snat.Status.Bytes()
+	if err != nil {
+		return err
+	}
+	if _, err = c.config.KubeOvnClient.KubeovnV1().OvnSnatRules().Patch(context.Background(), key, types.MergePatchType,
+		bytes, metav1.PatchOptions{}, "status"); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		klog.Errorf("failed to patch ovn snat rule %s, %v", key, err)
+		return err
+	}
+	return nil
+}
+
+func (c *Controller) handleDelOvnSnatRule(snat *kubeovnv1.OvnSnatRule) error {
+	if snat.Status.Vpc == "" || snat.Status.V4IpCidr == "" {
+		return nil
+	}
+	klog.Infof("delete ovn snat rule %s", snat.Name)
+	for _, cidr := range strings.Split(snat.Status.V4IpCidr, ",") {
+		if err := c.ovnClient.UpdateSnat(snat.Status.Vpc, "", cidr); err != nil {
+			klog.Errorf("failed to delete snat of %s, %v", cidr, err)
+			return err
+		}
+	}
+	return nil
+}
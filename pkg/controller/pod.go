@@ -14,6 +14,7 @@ import (
 	"github.com/kubeovn/kube-ovn/pkg/ipam"
 	"github.com/kubeovn/kube-ovn/pkg/ovs"
 	"github.com/kubeovn/kube-ovn/pkg/util"
+	"github.com/ovn-org/libovsdb/ovsdb"
 	"gopkg.in/k8snetworkplumbingwg/multus-cni.v3/pkg/logging"
 	multustypes "gopkg.in/k8snetworkplumbingwg/multus-cni.v3/pkg/types"
 	v1 "k8s.io/api/core/v1"
@@ -72,6 +73,10 @@ func (c *Controller) enqueueAddPod(obj interface{}) {
 	if p.Spec.HostNetwork {
 		return
 	}
+	if p.Annotations[util.AllocatedAnnotation] == "true" {
+		c.enqueueSyncPodQoSPolicy(p.Namespace+"/"+p.Name, p.Annotations)
+	}
+	c.enqueueSyncAnps(c.podMatchAnps(p)...)
 
 	if !isPodAlive(p) {
 		isStateful, statefulSetName := isStatefulSetPod(p)
@@ -142,6 +147,12 @@ func (c *Controller) enqueueDeletePod(obj interface{}) {
 	if p.Spec.HostNetwork {
 		return
 	}
+	c.enqueueSyncPodQoSPolicy(p.Namespace+"/"+p.Name, p.Annotations)
+	c.enqueueSyncQoSPolicyStatus(p.Annotations, nil)
+	c.enqueueSyncAnps(c.podMatchAnps(p)...)
+	if gwName, ok := p.Annotations[util.VpcNatGatewayAnnotation]; ok {
+		c.updateVpcNatGwActiveQueue.Add(gwName)
+	}
 
 	isStateful, statefulSetName := isStatefulSetPod(p)
 	isVmPod, vmName := isVmPod(p)
@@ -199,6 +210,23 @@ func (c *Controller) enqueueUpdatePod(oldObj, newObj interface{}) {
 	if newPod.Spec.HostNetwork {
 		return
 	}
+	// qos policies are bound by labels and applied once addresses are allocated
+	if c.ovnQoSAnnotationsChanged(oldPod.Annotations, newPod.Annotations) ||
+		oldPod.Annotations[util.AllocatedAnnotation] != newPod.Annotations[util.AllocatedAnnotation] ||
+		isPodAlive(oldPod) != isPodAlive(newPod) ||
+		(!reflect.DeepEqual(oldPod.Labels, newPod.Labels) && c.podSelectorQoSPolicyExists(newPod.Namespace)) {
+		c.enqueueSyncPodQoSPolicy(newPod.Namespace+"/"+newPod.Name, newPod.Annotations)
+	}
+	c.enqueueSyncQoSPolicyStatus(oldPod.Annotations, newPod.Annotations)
+	// admin network policies selecting the pod before or after the update are synced
+	if !reflect.DeepEqual(oldPod.Labels, newPod.Labels) || !reflect.DeepEqual(oldPod.Status.PodIPs, newPod.Status.PodIPs) ||
+		oldPod.Annotations[util.AllocatedAnnotation] != newPod.Annotations[util.AllocatedAnnotation] ||
+		isPodAlive(oldPod) != isPodAlive(newPod) {
+		c.enqueueSyncAnps(util.UniqString(append(c.podMatchAnps(oldPod), c.podMatchAnps(newPod)...))...)
+	}
+	if gwName, ok := newPod.Annotations[util.VpcNatGatewayAnnotation]; ok {
+		c.updateVpcNatGwActiveQueue.Add(gwName)
+	}
 
 	var key string
 	var err error
@@ -506,8 +534,10 @@ func (c *Controller) handleAddPod(key string) error {
 		ipStr := util.GetStringIP(v4IP, v6IP)
 		pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, podNet.ProviderName)] = ipStr
 		pod.Annotations[fmt.Sprintf(util.MacAddressAnnotationTemplate, podNet.ProviderName)] = mac
-		pod.Annotations[fmt.Sprintf(util.CidrAnnotationTemplate, podNet.ProviderName)] = subnet.Spec.CIDRBlock
-		pod.Annotations[fmt.Sprintf(util.GatewayAnnotationTemplate, podNet.ProviderName)] = subnet.Spec.Gateway
+		// addresses in a secondary cidr block use the gateway of the block
+		cidr, gw := util.GetSubnetCidrAndGateway(subnet, ipStr)
+		pod.Annotations[fmt.Sprintf(util.CidrAnnotationTemplate, podNet.ProviderName)] = cidr
+		pod.Annotations[fmt.Sprintf(util.GatewayAnnotationTemplate, podNet.ProviderName)] = gw
 		pod.Annotations[fmt.Sprintf(util.LogicalSwitchAnnotationTemplate, podNet.ProviderName)] = subnet.Name
 		pod.Annotations[fmt.Sprintf(util.AllocatedAnnotationTemplate, podNet.ProviderName)] = "true"
 		if pod.Annotations[fmt.Sprintf(util.PodNicAnnotationTemplate, podNet.ProviderName)] == "" {
@@ -517,7 +547,7 @@ func (c *Controller) handleAddPod(key string) error {
 			pod.Annotations[fmt.Sprintf(util.VmTemplate, podNet.ProviderName)] = vmName
 		}
 
-		if err := util.ValidatePodCidr(podNet.Subnet.Spec.CIDRBlock, ipStr); err != nil {
+		if err := util.ValidatePodCidr(strings.Join(util.SubnetCIDRBlocks(podNet.Subnet), ","), ipStr); err != nil {
 			klog.Errorf("validate pod %s/%s failed: %v", namespace, name, err)
 			c.recorder.Eventf(pod, v1.EventTypeWarning, "ValidatePodNetworkFailed", err.Error())
 			return err
@@ -564,16 +594,24 @@ func (c *Controller) handleAddPod(key string) error {
 				DHCPv4OptionsUUID: subnet.Status.DHCPv4OptionsUUID,
 				DHCPv6OptionsUUID: subnet.Status.DHCPv6OptionsUUID,
 			}
-			if err := c.ovnLegacyClient.CreatePort(subnet.Name, portName, ipStr, mac, podName, pod.Namespace, portSecurity, securityGroupAnnotation, vips, podNet.AllowLiveMigration, podNet.Subnet.Spec.EnableDHCP, dhcpOptions); err != nil {
-				c.recorder.Eventf(pod, v1.EventTypeWarning, "CreateOVNPortFailed", err.Error())
-				return err
-			}
-
-			if pod.Annotations[fmt.Sprintf(util.Layer2ForwardAnnotationTemplate, podNet.ProviderName)] == "true" {
-				if err := c.ovnLegacyClient.EnablePortLayer2forward(subnet.Name, portName); err != nil {
-					c.recorder.Eventf(pod, v1.EventTypeWarning, "EnablePortLayer2forwardFailed", err.Error())
+			layer2Forward := pod.Annotations[fmt.Sprintf(util.Layer2ForwardAnnotationTemplate, podNet.ProviderName)] == "true"
+			if c.ovnNbBatcher != nil {
+				if err := c.batchCreatePort(pod, podNet, subnet, portName, ipStr, mac, portSecurity, securityGroupAnnotation, vips, dhcpOptions, layer2Forward); err != nil {
+					c.recorder.Eventf(pod, v1.EventTypeWarning, "CreateOVNPortFailed", err.Error())
+					return err
+				}
+			} else {
+				if err := c.ovnLegacyClient.CreatePort(subnet.Name, portName, ipStr, mac, podName, pod.Namespace, portSecurity, securityGroupAnnotation, vips, podNet.AllowLiveMigration, podNet.Subnet.Spec.EnableDHCP, dhcpOptions); err != nil {
+					c.recorder.Eventf(pod, v1.EventTypeWarning, "CreateOVNPortFailed", err.Error())
 					return err
 				}
+
+				if layer2Forward {
+					if err := c.ovnLegacyClient.EnablePortLayer2forward(subnet.Name, portName); err != nil {
+						c.recorder.Eventf(pod, v1.EventTypeWarning, "EnablePortLayer2forwardFailed", err.Error())
+						return err
+					}
+				}
 			}
 
 			if portSecurity {
@@ -614,6 +652,127 @@ func (c *Controller) handleAddPod(key string) error {
 	return nil
 }
 
+// batchCreatePort creates the logical switch port in a transaction batched with the ones of other pods,
+// the port is added to the port groups and address sets of its security groups and gets its qos rules
+// in the same transaction
+func (c *Controller) batchCreatePort(pod *v1.Pod, podNet *kubeovnNet, subnet *kubeovnv1.Subnet, port, ip, mac string, portSecurity bool, securityGroups, vips string, dhcpOptions *ovs.DHCPOptionsUUIDs, layer2Forward bool) error {
+	lspUUID, ops, err := c.ovnClient.CreatePortOps(subnet.Name, port, ip, mac, c.getNameByPod(pod), pod.Namespace, portSecurity, securityGroups, vips, podNet.AllowLiveMigration, podNet.Subnet.Spec.EnableDHCP, dhcpOptions, layer2Forward)
+	if err != nil {
+		klog.Errorf("failed to generate operations creating port %s: %v", port, err)
+		return err
+	}
+
+	tx := c.ovnClient.NewTransaction()
+	tx.Add(ops...)
+	if portSecurity && securityGroups != "" {
+		if ops, err = c.sgPortOps(securityGroups, lspUUID, ip, vips); err != nil {
+			klog.Errorf("failed to generate operations adding port %s to security groups: %v", port, err)
+			return err
+		}
+		tx.Add(ops...)
+	}
+	if ops, err = c.podPortQoSOps(pod, podNet.ProviderName, port, subnet); err != nil {
+		klog.Errorf("failed to generate operations creating qos rules of port %s: %v", port, err)
+		return err
+	}
+	tx.Add(ops...)
+
+	if err = c.ovnNbBatcher.Commit(tx); err != nil {
+		klog.Errorf("create port %s failed: %v", port, err)
+		return err
+	}
+	return nil
+}
+
+// sgPortOps generates the operations adding the port to the port groups of the security groups
+// and its addresses to the associated address sets, the security groups not created yet are
+// left to syncSgLogicalPort
+func (c *Controller) sgPortOps(securityGroups, lspUUID, ip, vips string) ([]ovsdb.Operation, error) {
+	var v4s, v6s []string
+	for _, address := range append(strings.Split(ip, ","), strings.Split(vips, ",")...) {
+		switch util.CheckProtocol(address) {
+		case kubeovnv1.ProtocolIPv4:
+			v4s = append(v4s, address)
+		case kubeovnv1.ProtocolIPv6:
+			v6s = append(v6s, address)
+		}
+	}
+
+	var ops []ovsdb.Operation
+	for _, sgName := range strings.Split(securityGroups, ",") {
+		if sgName == "" {
+			continue
+		}
+		sg, err := c.sgsLister.Get(sgName)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			klog.Errorf("failed to get security group %s: %v", sgName, err)
+			return nil, err
+		}
+		if sg.Status.PortGroup == "" {
+			continue
+		}
+		pgOps, err := c.ovnClient.PortGroupAddPortsOps(sg.Status.PortGroup, lspUUID)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, pgOps...)
+		for _, as := range []struct {
+			name      string
+			addresses []string
+		}{{ovs.GetSgV4AssociatedName(sgName), v4s}, {ovs.GetSgV6AssociatedName(sgName), v6s}} {
+			if len(as.addresses) == 0 {
+				continue
+			}
+			asOps, err := c.ovnClient.AddressSetAddAddressesOps(as.name, as.addresses...)
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, asOps...)
+		}
+	}
+	return ops, nil
+}
+
+// portGroupPortOp adds the port to or removes it from the port group,
+// the operations are batched with the ones of other pods if ovn nb batch is enabled
+func (c *Controller) portGroupPortOp(pgName, portName string, opIsAdd bool) error {
+	if c.ovnNbBatcher == nil {
+		c.ovnPgKeyMutex.Lock(pgName)
+		defer c.ovnPgKeyMutex.Unlock(pgName)
+		if opIsAdd {
+			return c.ovnClient.PortGroupAddPort(pgName, portName)
+		}
+		return c.ovnClient.PortGroupRemovePort(pgName, portName)
+	}
+
+	lsp, err := c.ovnClient.GetLogicalSwitchPort(portName, false)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	var ops []ovsdb.Operation
+	if opIsAdd {
+		ops, err = c.ovnClient.PortGroupAddPortsOps(pgName, lsp.UUID)
+	} else {
+		ops, err = c.ovnClient.PortGroupRemovePortsOps(pgName, lsp.UUID)
+	}
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	tx := c.ovnClient.NewTransaction()
+	tx.Add(ops...)
+	if err = c.ovnNbBatcher.Commit(tx); err != nil {
+		klog.Errorf("failed to update ports of port group %s: %v", pgName, err)
+		return err
+	}
+	return nil
+}
+
 func (c *Controller) handleDeletePod(pod *v1.Pod) error {
 	var key string
 	var err error
@@ -655,12 +814,12 @@ func (c *Controller) handleDeletePod(pod *v1.Pod) error {
 			}
 			// If pod has snat or eip, also need delete staticRoute when delete pod
 			if vpc.Name == util.DefaultVpc {
-				if err := c.ovnLegacyClient.DeleteStaticRoute(address.Ip, vpc.Name); err != nil {
+				if err := c.ovnClient.DeleteStaticRoute(address.Ip, vpc.Name); err != nil {
 					return err
 				}
 			}
 			if exGwEnabled == "true" {
-				if err := c.ovnLegacyClient.DeleteNatRule(address.Ip, vpc.Name); err != nil {
+				if err := c.ovnClient.DeleteNatRule(address.Ip, vpc.Name); err != nil {
 					return err
 				}
 			}
@@ -679,7 +838,7 @@ func (c *Controller) handleDeletePod(pod *v1.Pod) error {
 			klog.Warningf("failed to get port '%s' sg, %v", port.Name, err)
 		}
 		// when lsp is deleted, the port of pod is deleted from any port-group automatically.
-		if err := c.ovnLegacyClient.DeleteLogicalSwitchPort(port.Name); err != nil {
+		if err := c.ovnClient.DeleteLogicalSwitchPort(port.Name); err != nil {
 			klog.Errorf("failed to delete lsp %s, %v", port.Name, err)
 			return err
 		}
@@ -832,19 +991,16 @@ func (c *Controller) handleUpdatePod(key string) error {
 					nextHop = strings.Split(nextHop, "/")[0]
 				}
 
-				if err := c.ovnLegacyClient.AddStaticRoute(ovs.PolicySrcIP, podIP, nextHop, c.config.ClusterRouter, util.NormalRouteType); err != nil {
+				if err := c.ovnClient.AddStaticRoute(ovs.PolicySrcIP, podIP, nextHop, c.config.ClusterRouter, util.NormalRouteType); err != nil {
 					klog.Errorf("failed to add static route, %v", err)
 					return err
 				}
 
 				// remove lsp from port group to make EIP/SNAT work
 				portName := ovs.PodNameToPortName(podName, pod.Namespace, podNet.ProviderName)
-				c.ovnPgKeyMutex.Lock(pgName)
-				if err = c.ovnClient.PortGroupRemovePort(pgName, portName); err != nil {
-					c.ovnPgKeyMutex.Unlock(pgName)
+				if err = c.portGroupPortOp(pgName, portName, false); err != nil {
 					return err
 				}
-				c.ovnPgKeyMutex.Unlock(pgName)
 
 			} else {
 				if subnet.Spec.GatewayType == kubeovnv1.GWDistributedType && pod.Annotations[util.NorthGatewayAnnotation] == "" {
@@ -861,12 +1017,9 @@ func (c *Controller) handleUpdatePod(key string) error {
 							}
 
 							portName := ovs.PodNameToPortName(podName, pod.Namespace, podNet.ProviderName)
-							c.ovnPgKeyMutex.Lock(pgName)
-							if err = c.ovnClient.PortGroupAddPort(pgName, portName); err != nil {
-								c.ovnPgKeyMutex.Unlock(pgName)
+							if err = c.portGroupPortOp(pgName, portName, true); err != nil {
 								return err
 							}
-							c.ovnPgKeyMutex.Unlock(pgName)
 
 							added = true
 							break
@@ -878,7 +1031,7 @@ func (c *Controller) handleUpdatePod(key string) error {
 				}
 
 				if pod.Annotations[util.NorthGatewayAnnotation] != "" {
-					if err := c.ovnLegacyClient.AddStaticRoute(ovs.PolicySrcIP, podIP, pod.Annotations[util.NorthGatewayAnnotation], c.config.ClusterRouter, util.NormalRouteType); err != nil {
+					if err := c.ovnClient.AddStaticRoute(ovs.PolicySrcIP, podIP, pod.Annotations[util.NorthGatewayAnnotation], c.config.ClusterRouter, util.NormalRouteType); err != nil {
 						klog.Errorf("failed to add static route, %v", err)
 						return err
 					}
@@ -887,12 +1040,12 @@ func (c *Controller) handleUpdatePod(key string) error {
 
 			if c.config.EnableEipSnat {
 				for _, ipStr := range strings.Split(podIP, ",") {
-					if err := c.ovnLegacyClient.UpdateNatRule("dnat_and_snat", ipStr, pod.Annotations[util.EipAnnotation], c.config.ClusterRouter, pod.Annotations[util.MacAddressAnnotation], fmt.Sprintf("%s.%s", podName, pod.Namespace)); err != nil {
+					if err := c.ovnClient.UpdateDnatAndSnat(c.config.ClusterRouter, pod.Annotations[util.EipAnnotation], ipStr, fmt.Sprintf("%s.%s", podName, pod.Namespace), pod.Annotations[util.MacAddressAnnotation], c.externalGatewayType); err != nil {
 						klog.Errorf("failed to add nat rules, %v", err)
 						return err
 					}
 
-					if err := c.ovnLegacyClient.UpdateNatRule("snat", ipStr, pod.Annotations[util.SnatAnnotation], c.config.ClusterRouter, "", ""); err != nil {
+					if err := c.ovnClient.UpdateSnat(c.config.ClusterRouter, pod.Annotations[util.SnatAnnotation], ipStr); err != nil {
 						klog.Errorf("failed to add nat rules, %v", err)
 						return err
 					}
@@ -1246,11 +1399,15 @@ func (c *Controller) acquireAddress(pod *v1.Pod, podNet *kubeovnNet) (string, st
 	// Random allocate
 	if pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, podNet.ProviderName)] == "" &&
 		pod.Annotations[fmt.Sprintf(util.IpPoolAnnotationTemplate, podNet.ProviderName)] == "" {
+		poolName, err := c.getPodIPPool(pod, podNet.Subnet.Name)
+		if err != nil {
+			return "", "", "", podNet.Subnet, err
+		}
 		var skippedAddrs []string
 		for {
 			portName := ovs.PodNameToPortName(podName, pod.Namespace, podNet.ProviderName)
 
-			ipv4, ipv6, mac, err := c.ipam.GetRandomAddress(key, portName, macStr, podNet.Subnet.Name, skippedAddrs, !podNet.AllowLiveMigration)
+			ipv4, ipv6, mac, err := c.ipam.GetRandomAddress(key, portName, macStr, podNet.Subnet.Name, poolName, skippedAddrs, !podNet.AllowLiveMigration)
 			if err != nil {
 				return "", "", "", podNet.Subnet, err
 			}
@@ -1283,6 +1440,10 @@ func (c *Controller) acquireAddress(pod *v1.Pod, podNet *kubeovnNet) (string, st
 		ipStr := pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, podNet.ProviderName)]
 
 		for _, net := range nsNets {
+			if err = c.checkPodStaticIPPool(pod, ipStr, net.Subnet.Name); err != nil {
+				klog.Error(err)
+				continue
+			}
 			v4IP, v6IP, mac, err = c.acquireStaticAddress(key, portName, ipStr, macStr, net.Subnet.Name, net.AllowLiveMigration)
 			if err == nil {
 				return v4IP, v6IP, mac, net.Subnet, nil
@@ -1307,6 +1468,10 @@ func (c *Controller) acquireAddress(pod *v1.Pod, podNet *kubeovnNet) (string, st
 					}
 				}
 
+				if err = c.checkPodStaticIPPool(pod, staticIPs, net.Subnet.Name); err != nil {
+					klog.Error(err)
+					continue
+				}
 				v4IP, v6IP, mac, err = c.acquireStaticAddress(key, portName, staticIPs, macStr, net.Subnet.Name, net.AllowLiveMigration)
 				if err == nil {
 					return v4IP, v6IP, mac, net.Subnet, nil
@@ -1320,6 +1485,10 @@ func (c *Controller) acquireAddress(pod *v1.Pod, podNet *kubeovnNet) (string, st
 		index, _ := strconv.Atoi(numStr)
 		if index < len(ipPool) {
 			for _, net := range nsNets {
+				if err = c.checkPodStaticIPPool(pod, ipPool[index], net.Subnet.Name); err != nil {
+					klog.Error(err)
+					continue
+				}
 				v4IP, v6IP, mac, err = c.acquireStaticAddress(key, portName, ipPool[index], macStr, net.Subnet.Name, net.AllowLiveMigration)
 				if err == nil {
 					return v4IP, v6IP, mac, net.Subnet, nil
@@ -1332,6 +1501,21 @@ func (c *Controller) acquireAddress(pod *v1.Pod, podNet *kubeovnNet) (string, st
 	return "", "", "", podNet.Subnet, ipam.ErrNoAvailable
 }
 
+// checkPodStaticIPPool returns an error if any of the static ips is in an ippool of the subnet the pod is not bound to
+func (c *Controller) checkPodStaticIPPool(pod *v1.Pod, ips, subnet string) error {
+	poolName, err := c.getPodIPPool(pod, subnet)
+	if err != nil {
+		return err
+	}
+	for _, ip := range strings.Split(ips, ",") {
+		if pool := c.ipam.IPPoolOfIP(subnet, strings.TrimSpace(ip)); pool != "" && pool != poolName {
+			return fmt.Errorf("%w: static address %s of pod %s/%s is in ippool %s which the pod is not bound to",
+				ipam.ErrConflict, ip, pod.Namespace, pod.Name, pool)
+		}
+	}
+	return nil
+}
+
 func (c *Controller) acquireStaticAddress(key, nicName, ip, mac, subnet string, liveMigration bool) (string, string, string, error) {
 	var v4IP, v6IP string
 	var err error
@@ -1406,7 +1590,7 @@ func appendCheckPodToDel(c *Controller, pod *v1.Pod, ownerRefName, ownerRefKind
 		klog.Errorf("failed to get subnet %s, %v", pod.Annotations[util.LogicalSwitchAnnotation], err)
 		return false, err
 	}
-	if podSubnet != nil && !util.CIDRContainIP(podSubnet.Spec.CIDRBlock, pod.Annotations[util.IpAddressAnnotation]) {
+	if podSubnet != nil && !util.SubnetContainIP(podSubnet, pod.Annotations[util.IpAddressAnnotation]) {
 		klog.Infof("pod's ip %s is not in the range of subnet %s, delete pod", pod.Annotations[util.IpAddressAnnotation], podSubnet.Name)
 		return true, nil
 	}
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func Test_batchCreatePort(t *testing.T) {
+	dscp := 10
+	subnet := &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: util.DefaultSubnet},
+		Spec:       kubeovnv1.SubnetSpec{Dscp: &dscp},
+	}
+	sg := &kubeovnv1.SecurityGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Status:     kubeovnv1.SecurityGroupStatus{PortGroup: ovs.GetSgPortGroupName("web")},
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	ctrl := newFakeController(t, []runtime.Object{pod}, []runtime.Object{subnet})
+	require.NoError(t, ctrl.kubeovnInformerFactory.Kubeovn().V1().SecurityGroups().Informer().GetIndexer().Add(sg))
+	require.NoError(t, ctrl.legacyClient.CreateLogicalSwitch(subnet.Name, util.DefaultVpc, "10.16.0.0/16", "10.16.0.1", false))
+	require.NoError(t, ctrl.ovnClient.CreatePortGroup(sg.Status.PortGroup, map[string]string{"sg": sg.Name}))
+	require.NoError(t, ctrl.ovnClient.CreateSgAssociatedAddressSet(sg.Name))
+
+	ctrl.ovnNbBatcher = ctrl.ovnClient.NewBatcher(100, 10*time.Millisecond)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go ctrl.ovnNbBatcher.Run(stopCh)
+
+	podNet := &kubeovnNet{Type: providerTypeOriginal, ProviderName: util.OvnProvider, Subnet: subnet, IsDefault: true}
+	port := ovs.PodNameToPortName(pod.Name, pod.Namespace, util.OvnProvider)
+	require.NoError(t, ctrl.batchCreatePort(pod, podNet, subnet, port, "10.16.0.2", "00:00:00:00:00:01", true, sg.Name, "", nil, false))
+
+	// the port, its security group membership and its qos rules are committed together
+	lsp, err := ctrl.ovnClient.GetLogicalSwitchPort(port, false)
+	require.NoError(t, err)
+	ls, err := ctrl.ovnClient.GetLogicalSwitch(subnet.Name, false)
+	require.NoError(t, err)
+	require.Contains(t, ls.Ports, lsp.UUID)
+	pg, err := ctrl.ovnClient.GetPortGroup(sg.Status.PortGroup, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{lsp.UUID}, pg.Ports)
+	as, err := ctrl.ovnClient.GetAddressSet(ovs.GetSgV4AssociatedName(sg.Name), false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.16.0.2"}, as.Addresses)
+	qosList, err := ctrl.ovnClient.ListQoSs(map[string]string{"qos-type": ovnQoSTypeDscp, "port": port})
+	require.NoError(t, err)
+	require.Len(t, qosList, 1)
+	require.Contains(t, ls.QOSRules, qosList[0].UUID)
+
+	// creating the port again does not duplicate the qos rules
+	require.NoError(t, ctrl.batchCreatePort(pod, podNet, subnet, port, "10.16.0.2", "00:00:00:00:00:01", true, sg.Name, "", nil, false))
+	qosList, err = ctrl.ovnClient.ListQoSs(map[string]string{"qos-type": "", "port": port})
+	require.NoError(t, err)
+	require.Len(t, qosList, 1)
+
+	// the port is not created in a missing switch, where it would be garbage collected
+	missing := &kubeovnv1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: "missing"}}
+	podNet.Subnet = missing
+	require.Error(t, ctrl.batchCreatePort(pod, podNet, missing, "web.default.missing", "10.17.0.2", "00:00:00:00:00:02", false, "", "", nil, false))
+	lsp, err = ctrl.ovnClient.GetLogicalSwitchPort("web.default.missing", true)
+	require.NoError(t, err)
+	require.Nil(t, lsp)
+}
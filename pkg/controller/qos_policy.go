@@ -0,0 +1,992 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+const (
+	// the key syncing all qos policies, which is enqueued once the policies or the dscp of subnets are changed
+	qosPolicySyncKey = "qos-policies"
+	// prefixes of the keys syncing the qos policy of a single pod or node, and the status of a single policy
+	qosPolicyPodKeyPrefix    = "pod/"
+	qosPolicyNodeKeyPrefix   = "node/"
+	qosPolicyStatusKeyPrefix = "policy/"
+	// suffix of the annotations recording the policies applied to the nics of pods and nodes
+	qosPolicyAnnotationSuffix = ".kubernetes.io/qos_policy"
+	// priority of the ovn qos rules marking dscp of the ports bound by qos policies
+	qosPolicyDscpPriority = 100
+	// priority of the ovn qos rules limiting the rates of the ports in ovn qos mode
+	ovnQoSRatePriority = 100
+
+	ovnQoSTypeDscp        = "dscp"
+	ovnQoSTypeIngressRate = "rate-ingress"
+	ovnQoSTypeEgressRate  = "rate-egress"
+)
+
+func (c *Controller) enqueueAddQoSPolicy(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	klog.V(3).Infof("enqueue add qos policy %s", obj.(*kubeovnv1.QoSPolicy).Name)
+	c.syncQoSPolicyQueue.Add(qosPolicySyncKey)
+}
+
+func (c *Controller) enqueueUpdateQoSPolicy(old, new interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	oldPolicy := old.(*kubeovnv1.QoSPolicy)
+	newPolicy := new.(*kubeovnv1.QoSPolicy)
+	if !reflect.DeepEqual(oldPolicy.Spec, newPolicy.Spec) {
+		klog.V(3).Infof("enqueue update qos policy %s", newPolicy.Name)
+		c.syncQoSPolicyQueue.Add(qosPolicySyncKey)
+	}
+}
+
+func (c *Controller) enqueueDelQoSPolicy(obj interface{}) {
+	if !c.isLeader() {
+		return
+	}
+	klog.V(3).Infof("enqueue delete qos policy")
+	c.syncQoSPolicyQueue.Add(qosPolicySyncKey)
+}
+
+// enqueueSyncPodQoSPolicy syncs the qos policy of the pod if any qos rule may be applied to it
+func (c *Controller) enqueueSyncPodQoSPolicy(key string, annotations map[string]string) {
+	if c.qosPolicyMayApply(annotations) {
+		klog.V(3).Infof("enqueue sync qos policy of pod %s", key)
+		c.syncQoSPolicyQueue.Add(qosPolicyPodKeyPrefix + key)
+	}
+}
+
+// enqueueSyncNodeQoSPolicy syncs the qos policy of the node if any qos rule may be applied to it
+func (c *Controller) enqueueSyncNodeQoSPolicy(name string, annotations map[string]string) {
+	if c.qosPolicyMayApply(annotations) {
+		klog.V(3).Infof("enqueue sync qos policy of node %s", name)
+		c.syncQoSPolicyQueue.Add(qosPolicyNodeKeyPrefix + name)
+	}
+}
+
+// enqueueSyncQoSPolicyStatus syncs the status of the policies whose qos policy annotations are changed
+func (c *Controller) enqueueSyncQoSPolicyStatus(oldAnnotations, newAnnotations map[string]string) {
+	for _, annotations := range []map[string]string{oldAnnotations, newAnnotations} {
+		for k, v := range annotations {
+			if v != "" && oldAnnotations[k] != newAnnotations[k] && strings.HasSuffix(k, qosPolicyAnnotationSuffix) {
+				c.syncQoSPolicyQueue.Add(qosPolicyStatusKeyPrefix + v)
+			}
+		}
+	}
+}
+
+// enqueueSyncEipQoSPolicyStatus syncs the status of the policies bound to the eip
+func (c *Controller) enqueueSyncEipQoSPolicyStatus(eipName string) {
+	policies, err := c.qosPoliciesLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list qos policies, %v", err)
+		return
+	}
+	for _, policy := range policies {
+		if policy.Spec.Binding.Type == kubeovnv1.QoSBindingTypeEIP && util.ContainsString(policy.Spec.Binding.Names, eipName) {
+			c.syncQoSPolicyQueue.Add(qosPolicyStatusKeyPrefix + policy.Name)
+		}
+	}
+}
+
+// qosPolicyMayApply checks whether any ovn qos rule or qos policy may be applied to the pod or node with the annotations,
+// which is the case if there are qos policies or subnets with dscp, or the object has qos annotations
+func (c *Controller) qosPolicyMayApply(annotations map[string]string) bool {
+	for k := range annotations {
+		if strings.HasSuffix(k, qosPolicyAnnotationSuffix) {
+			return true
+		}
+	}
+	if c.ovnQoSAnnotationsChanged(nil, annotations) {
+		return true
+	}
+	policies, err := c.qosPoliciesLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list qos policies, %v", err)
+		return true
+	}
+	if len(policies) != 0 {
+		return true
+	}
+	subnets, err := c.subnetsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list subnets, %v", err)
+		return true
+	}
+	for _, subnet := range subnets {
+		if subnet.Spec.Dscp != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// podSelectorQoSPolicyExists checks whether any qos policy selects pods in the namespace by labels
+func (c *Controller) podSelectorQoSPolicyExists(namespace string) bool {
+	policies, err := c.qosPoliciesLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list qos policies, %v", err)
+		return true
+	}
+	for _, policy := range policies {
+		if policy.Spec.Binding.Type == kubeovnv1.QoSBindingTypePod && policy.Spec.Binding.Namespace == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// ovnQoSAnnotationsChanged checks whether the annotations turned into ovn qos rules are changed,
+// which are the dscp ones of any provider and the rate ones in ovn qos mode
+func (c *Controller) ovnQoSAnnotationsChanged(oldAnnotations, newAnnotations map[string]string) bool {
+	suffixes := []string{".kubernetes.io/dscp"}
+	if c.config.QoSMode == util.QoSModeOvn {
+		suffixes = append(suffixes, ".kubernetes.io/ingress_rate", ".kubernetes.io/egress_rate")
+	}
+	for _, annotations := range []map[string]string{oldAnnotations, newAnnotations} {
+		for k := range annotations {
+			if oldAnnotations[k] == newAnnotations[k] {
+				continue
+			}
+			for _, suffix := range suffixes {
+				if strings.HasSuffix(k, suffix) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (c *Controller) runSyncQoSPolicyWorker() {
+	for c.processNextSyncQoSPolicyWorkItem() {
+	}
+}
+
+func (c *Controller) processNextSyncQoSPolicyWorkItem() bool {
+	obj, shutdown := c.syncQoSPolicyQueue.Get()
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.syncQoSPolicyQueue.Done(obj)
+		var key string
+		var ok bool
+		if key, ok = obj.(string); !ok {
+			c.syncQoSPolicyQueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.handleSyncQoSPolicies(key); err != nil {
+			c.syncQoSPolicyQueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.syncQoSPolicyQueue.Forget(obj)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+	return true
+}
+
+// ovnQoSRule is a qos rule of a logical switch port managed by the controller, which marks dscp
+// of the egress traffic or limits the rate of the traffic in one direction, pod is the key of the
+// pod owning the port so that the rules are found once the pod is deleted
+type ovnQoSRule struct {
+	qosType   string
+	policy    string
+	pod       string
+	ls        string
+	port      string
+	direction string
+	match     string
+	dscp      int
+	rate      int
+	burst     int
+}
+
+func (r ovnQoSRule) key() string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s/%s/%s/%d/%d/%d", r.qosType, r.policy, r.pod, r.ls, r.port, r.direction, r.match, r.dscp, r.rate, r.burst)
+}
+
+func (r ovnQoSRule) qos() *ovnnb.QoS {
+	qos := &ovnnb.QoS{
+		Direction:   r.direction,
+		Match:       r.match,
+		ExternalIDs: map[string]string{"qos-type": r.qosType, "port": r.port, "ls": r.ls},
+	}
+	if r.policy != "" {
+		qos.ExternalIDs["qos-policy"] = r.policy
+	}
+	if r.pod != "" {
+		qos.ExternalIDs["pod"] = r.pod
+	}
+	if r.qosType == ovnQoSTypeDscp {
+		qos.Priority = qosPolicyDscpPriority
+		qos.Action = map[string]int{ovnnb.QoSActionDSCP: r.dscp}
+		return qos
+	}
+	qos.Priority = ovnQoSRatePriority
+	qos.Bandwidth = map[string]int{ovnnb.QoSBandwidthRate: r.rate}
+	if r.burst != 0 {
+		qos.Bandwidth[ovnnb.QoSBandwidthBurst] = r.burst
+	}
+	return qos
+}
+
+// ovnQoSRuleFromQoS converts the qos row created by the controller back to the rule
+func ovnQoSRuleFromQoS(qos *ovnnb.QoS) ovnQoSRule {
+	return ovnQoSRule{
+		qosType:   qos.ExternalIDs["qos-type"],
+		policy:    qos.ExternalIDs["qos-policy"],
+		pod:       qos.ExternalIDs["pod"],
+		ls:        qos.ExternalIDs["ls"],
+		port:      qos.ExternalIDs["port"],
+		direction: qos.Direction,
+		match:     qos.Match,
+		dscp:      qos.Action[ovnnb.QoSActionDSCP],
+		rate:      qos.Bandwidth[ovnnb.QoSBandwidthRate],
+		burst:     qos.Bandwidth[ovnnb.QoSBandwidthBurst],
+	}
+}
+
+// qosDestinationMatch returns the match of the ip field, src or dst, with the destination cidrs
+func qosDestinationMatch(field string, cidrs []string) string {
+	var v4, v6 []string
+	for _, cidr := range cidrs {
+		switch util.CheckProtocol(cidr) {
+		case kubeovnv1.ProtocolIPv4:
+			v4 = append(v4, cidr)
+		case kubeovnv1.ProtocolIPv6:
+			v6 = append(v6, cidr)
+		}
+	}
+	var matches []string
+	if len(v4) != 0 {
+		matches = append(matches, fmt.Sprintf("ip4.%s == {%s}", field, strings.Join(v4, ", ")))
+	}
+	if len(v6) != 0 {
+		matches = append(matches, fmt.Sprintf("ip6.%s == {%s}", field, strings.Join(v6, ", ")))
+	}
+	switch len(matches) {
+	case 0:
+		return ""
+	case 1:
+		return " && " + matches[0]
+	}
+	return fmt.Sprintf(" && (%s)", strings.Join(matches, " || "))
+}
+
+// ovnQoSDscpRule returns the dscp rule of the port, the value in the annotation takes precedence over
+// the one of the policy, and then the one of the subnet
+func ovnQoSDscpRule(port string, subnet *kubeovnv1.Subnet, policy *kubeovnv1.QoSPolicy, annotation string) *ovnQoSRule {
+	rule := &ovnQoSRule{
+		qosType:   ovnQoSTypeDscp,
+		ls:        subnet.Name,
+		port:      port,
+		direction: ovnnb.QoSDirectionFromLport,
+		match:     fmt.Sprintf("inport == \"%s\"", port),
+	}
+	switch {
+	case annotation != "":
+		dscp, err := util.ParseDscp(annotation)
+		if err != nil {
+			klog.Warningf("ignore invalid dscp %s of port %s, %v", annotation, port, err)
+			return nil
+		}
+		rule.dscp = dscp
+	case policy != nil && policy.Spec.Dscp != nil:
+		rule.policy, rule.dscp = policy.Name, *policy.Spec.Dscp
+	case subnet.Spec.Dscp != nil:
+		rule.dscp = *subnet.Spec.Dscp
+	default:
+		return nil
+	}
+	return rule
+}
+
+// qosPolicyDestinations returns the destination cidrs of the policy, including the ones of the destination subnets
+func (c *Controller) qosPolicyDestinations(policy *kubeovnv1.QoSPolicy) []string {
+	cidrs := append([]string{}, policy.Spec.DestinationCIDRs...)
+	for _, name := range policy.Spec.DestinationSubnets {
+		subnet, err := c.subnetsLister.Get(name)
+		if err != nil {
+			klog.Warningf("failed to get destination subnet %s of qos policy %s, %v", name, policy.Name, err)
+			continue
+		}
+		cidrs = append(cidrs, util.SubnetCIDRBlocks(subnet)...)
+	}
+	return cidrs
+}
+
+// ovnQoSRateRules returns the rate limit rules of the port in ovn qos mode, the rates in the annotations take
+// precedence over the ones of the policy, and only the rates of the policy are limited to the destinations
+func (c *Controller) ovnQoSRateRules(ls, port string, policy *kubeovnv1.QoSPolicy, ingressRate, egressRate string) []ovnQoSRule {
+	var policyName, policyIngressRate, policyEgressRate, burst string
+	var destinations []string
+	if policy != nil {
+		policyName, policyIngressRate, policyEgressRate, burst = policy.Name, policy.Spec.IngressRate, policy.Spec.EgressRate, policy.Spec.Burst
+		destinations = c.qosPolicyDestinations(policy)
+	}
+
+	var rules []ovnQoSRule
+	for _, dir := range []struct {
+		qosType, direction, match, field, rate, policyRate string
+	}{
+		{ovnQoSTypeIngressRate, ovnnb.QoSDirectionToLport, fmt.Sprintf("outport == \"%s\"", port), "src", ingressRate, policyIngressRate},
+		{ovnQoSTypeEgressRate, ovnnb.QoSDirectionFromLport, fmt.Sprintf("inport == \"%s\"", port), "dst", egressRate, policyEgressRate},
+	} {
+		rule := ovnQoSRule{qosType: dir.qosType, ls: ls, port: port, direction: dir.direction, match: dir.match}
+		rate := dir.rate
+		if rate == "" && dir.policyRate != "" {
+			rate = dir.policyRate
+			rule.policy = policyName
+			rule.match += qosDestinationMatch(dir.field, destinations)
+		}
+		if rate == "" {
+			continue
+		}
+		v, err := strconv.Atoi(rate)
+		if err != nil || v <= 0 {
+			klog.Warningf("ignore invalid rate %s of port %s", rate, port)
+			continue
+		}
+		// rates in Mbit/s and burst in Mbit are converted to kbps and kbits
+		rule.rate = v * 1000
+		if dir.qosType == ovnQoSTypeEgressRate && burst != "" {
+			if b, err := strconv.Atoi(burst); err == nil && b > 0 {
+				rule.burst = b * 1000
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// podQoSPolicy returns the policy with the highest precedence applied to the nic of the pod in the subnet
+func podQoSPolicy(policies []*kubeovnv1.QoSPolicy, pod *v1.Pod, subnet string) *kubeovnv1.QoSPolicy {
+	for _, bindingType := range []kubeovnv1.QoSPolicyBindingType{kubeovnv1.QoSBindingTypePod, kubeovnv1.QoSBindingTypeNamespace, kubeovnv1.QoSBindingTypeSubnet} {
+		for _, policy := range policies {
+			binding := policy.Spec.Binding
+			if binding.Type != bindingType {
+				continue
+			}
+			switch bindingType {
+			case kubeovnv1.QoSBindingTypePod:
+				if binding.Namespace != pod.Namespace {
+					continue
+				}
+				// the selector has been checked when listing the policies
+				sel, _ := metav1.LabelSelectorAsSelector(binding.PodSelector)
+				if sel.Matches(labels.Set(pod.Labels)) {
+					return policy
+				}
+			case kubeovnv1.QoSBindingTypeNamespace:
+				if util.ContainsString(binding.Names, pod.Namespace) {
+					return policy
+				}
+			case kubeovnv1.QoSBindingTypeSubnet:
+				if util.ContainsString(binding.Names, subnet) {
+					return policy
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// namedQoSPolicy returns the first policy by name bound to the eip or node
+func namedQoSPolicy(policies []*kubeovnv1.QoSPolicy, bindingType kubeovnv1.QoSPolicyBindingType, name string) *kubeovnv1.QoSPolicy {
+	for _, policy := range policies {
+		if policy.Spec.Binding.Type == bindingType && util.ContainsString(policy.Spec.Binding.Names, name) {
+			return policy
+		}
+	}
+	return nil
+}
+
+// listQoSPolicies returns all policies and the valid ones ordered by name, the status of invalid ones contains the error
+func (c *Controller) listQoSPolicies() ([]*kubeovnv1.QoSPolicy, []*kubeovnv1.QoSPolicy, map[string]*kubeovnv1.QoSPolicyStatus, error) {
+	policies, err := c.qosPoliciesLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list qos policies, %v", err)
+		return nil, nil, nil, err
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Name < policies[j].Name })
+
+	validPolicies := make([]*kubeovnv1.QoSPolicy, 0, len(policies))
+	statuses := make(map[string]*kubeovnv1.QoSPolicyStatus, len(policies))
+	for _, policy := range policies {
+		status := &kubeovnv1.QoSPolicyStatus{}
+		statuses[policy.Name] = status
+		err := util.ValidateQoSPolicy(*policy)
+		if err == nil && policy.Spec.Binding.PodSelector != nil {
+			_, err = metav1.LabelSelectorAsSelector(policy.Spec.Binding.PodSelector)
+		}
+		if err != nil {
+			klog.Errorf("invalid qos policy %s, %v", policy.Name, err)
+			status.Error = err.Error()
+			continue
+		}
+		validPolicies = append(validPolicies, policy)
+	}
+	return policies, validPolicies, statuses, nil
+}
+
+func (c *Controller) handleSyncQoSPolicies(key string) error {
+	switch {
+	case strings.HasPrefix(key, qosPolicyPodKeyPrefix):
+		return c.handleSyncPodQoSPolicy(strings.TrimPrefix(key, qosPolicyPodKeyPrefix))
+	case strings.HasPrefix(key, qosPolicyNodeKeyPrefix):
+		return c.handleSyncNodeQoSPolicy(strings.TrimPrefix(key, qosPolicyNodeKeyPrefix))
+	case strings.HasPrefix(key, qosPolicyStatusKeyPrefix):
+		return c.handleSyncQoSPolicyStatus(strings.TrimPrefix(key, qosPolicyStatusKeyPrefix))
+	}
+
+	policies, validPolicies, statuses, err := c.listQoSPolicies()
+	if err != nil {
+		return err
+	}
+	klog.V(3).Infof("sync %d qos policies", len(policies))
+
+	podRules, err := c.syncPodQoSPolicies(validPolicies, statuses)
+	if err != nil {
+		return err
+	}
+	nodeRules, err := c.syncNodeQoSPolicies(validPolicies, statuses)
+	if err != nil {
+		return err
+	}
+	if err = c.syncIptablesEipQoSPolicies(validPolicies, statuses); err != nil {
+		return err
+	}
+	qosList, err := c.ovnClient.ListQoSs(map[string]string{"qos-type": ""})
+	if err != nil {
+		klog.Errorf("failed to list ovn qos rules, %v", err)
+		return err
+	}
+	if err = c.syncOvnQoSRules(qosList, append(podRules, nodeRules...)); err != nil {
+		return err
+	}
+	if err = c.syncSubnetDscpStatus(); err != nil {
+		return err
+	}
+
+	for _, policy := range policies {
+		if err = c.patchQoSPolicyStatus(policy, statuses[policy.Name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleSyncPodQoSPolicy syncs the qos policy annotations and the ovn qos rules of the ports of a single pod
+func (c *Controller) handleSyncPodQoSPolicy(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return nil
+	}
+	pod, err := c.podsLister.Pods(namespace).Get(name)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		klog.Errorf("failed to get pod %s, %v", key, err)
+		return err
+	}
+
+	var rules []ovnQoSRule
+	var ports []string
+	if pod != nil && !pod.Spec.HostNetwork && isPodAlive(pod) {
+		_, policies, _, err := c.listQoSPolicies()
+		if err != nil {
+			return err
+		}
+		var bindings map[string]string
+		if rules, bindings, err = c.syncPodQoSPolicy(pod, policies); err != nil {
+			return err
+		}
+		for port := range bindings {
+			ports = append(ports, port)
+		}
+	}
+
+	// the rules of a deleted pod are found by the pod key, and the ones created before by the port
+	qosList, err := c.ovnClient.ListQoSs(map[string]string{"qos-type": "", "pod": key})
+	if err != nil {
+		klog.Errorf("failed to list ovn qos rules of pod %s, %v", key, err)
+		return err
+	}
+	for _, port := range ports {
+		portQoSList, err := c.ovnClient.ListQoSs(map[string]string{"qos-type": "", "port": port})
+		if err != nil {
+			klog.Errorf("failed to list ovn qos rules of port %s, %v", port, err)
+			return err
+		}
+		for _, qos := range portQoSList {
+			if qos.ExternalIDs["pod"] != key {
+				qosList = append(qosList, qos)
+			}
+		}
+	}
+	return c.syncOvnQoSRules(qosList, rules)
+}
+
+// handleSyncNodeQoSPolicy syncs the qos policy annotation and the ovn qos rules of the port of a single node
+func (c *Controller) handleSyncNodeQoSPolicy(name string) error {
+	node, err := c.nodesLister.Get(name)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		klog.Errorf("failed to get node %s, %v", name, err)
+		return err
+	}
+
+	var rules []ovnQoSRule
+	if node != nil {
+		_, policies, _, err := c.listQoSPolicies()
+		if err != nil {
+			return err
+		}
+		joinSubnet, err := c.getQoSJoinSubnet()
+		if err != nil {
+			return err
+		}
+		if rules, _, err = c.syncNodeQoSPolicy(node, joinSubnet, policies); err != nil {
+			return err
+		}
+	}
+
+	qosList, err := c.ovnClient.ListQoSs(map[string]string{"qos-type": "", "port": nodeQoSPortName(name)})
+	if err != nil {
+		klog.Errorf("failed to list ovn qos rules of node %s, %v", name, err)
+		return err
+	}
+	return c.syncOvnQoSRules(qosList, rules)
+}
+
+// handleSyncQoSPolicyStatus records the ports and eips a single policy is applied to in its status,
+// the ports are the ones of the pods and nodes annotated with the policy
+func (c *Controller) handleSyncQoSPolicyStatus(name string) error {
+	policies, validPolicies, statuses, err := c.listQoSPolicies()
+	if err != nil {
+		return err
+	}
+	var policy *kubeovnv1.QoSPolicy
+	for _, p := range policies {
+		if p.Name == name {
+			policy = p
+			break
+		}
+	}
+	if policy == nil {
+		return nil
+	}
+	status := statuses[name]
+	if status.Error != "" {
+		return c.patchQoSPolicyStatus(policy, status)
+	}
+
+	pods, err := c.podsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list pods, %v", err)
+		return err
+	}
+	for _, pod := range pods {
+		if pod.Spec.HostNetwork || !isPodAlive(pod) {
+			continue
+		}
+		for k, v := range pod.Annotations {
+			if v == name && strings.HasSuffix(k, qosPolicyAnnotationSuffix) {
+				provider := strings.TrimSuffix(k, qosPolicyAnnotationSuffix)
+				status.Ports = append(status.Ports, ovs.PodNameToPortName(c.getNameByPod(pod), pod.Namespace, provider))
+			}
+		}
+	}
+	nodes, err := c.nodesLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list nodes, %v", err)
+		return err
+	}
+	for _, node := range nodes {
+		if node.Annotations[util.QoSPolicyAnnotation] == name {
+			status.Ports = append(status.Ports, nodeQoSPortName(node.Name))
+		}
+	}
+	eips, err := c.iptablesEipsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list iptables eips, %v", err)
+		return err
+	}
+	for _, eip := range eips {
+		if p := namedQoSPolicy(validPolicies, kubeovnv1.QoSBindingTypeEIP, eip.Name); p != nil && p.Name == name {
+			status.Eips = append(status.Eips, eip.Name)
+		}
+	}
+	return c.patchQoSPolicyStatus(policy, status)
+}
+
+func (c *Controller) patchQoSPolicyStatus(policy *kubeovnv1.QoSPolicy, status *kubeovnv1.QoSPolicyStatus) error {
+	sort.Strings(status.Ports)
+	sort.Strings(status.Eips)
+	if reflect.DeepEqual(policy.Status, *status) {
+		return nil
+	}
+	bytes, err := status.Bytes()
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	if _, err = c.config.KubeOvnClient.KubeovnV1().QoSPolicies().Patch(context.Background(), policy.Name, types.MergePatchType, bytes, metav1.PatchOptions{}, "status"); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		klog.Errorf("failed to patch status of qos policy %s, %v", policy.Name, err)
+		return err
+	}
+	return nil
+}
+
+// syncPodQoSPolicies annotates the pods with the policies applied to their nics, and returns the ovn qos rules of the ports
+func (c *Controller) syncPodQoSPolicies(policies []*kubeovnv1.QoSPolicy, statuses map[string]*kubeovnv1.QoSPolicyStatus) ([]ovnQoSRule, error) {
+	pods, err := c.podsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list pods, %v", err)
+		return nil, err
+	}
+
+	var rules []ovnQoSRule
+	for _, pod := range pods {
+		if pod.Spec.HostNetwork || !isPodAlive(pod) {
+			continue
+		}
+		podRules, bindings, err := c.syncPodQoSPolicy(pod, policies)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, podRules...)
+		for port, policy := range bindings {
+			if policy != "" {
+				statuses[policy].Ports = append(statuses[policy].Ports, port)
+			}
+		}
+	}
+	return rules, nil
+}
+
+// syncPodQoSPolicy annotates the pod with the policies applied to its nics, and returns the ovn qos rules
+// of the ports together with the names of the policies bound to the ports, which are empty for unbound ones
+func (c *Controller) syncPodQoSPolicy(oriPod *v1.Pod, policies []*kubeovnv1.QoSPolicy) ([]ovnQoSRule, map[string]string, error) {
+	podNets, err := c.getPodKubeovnNets(oriPod)
+	if err != nil {
+		klog.V(3).Infof("skip qos policies of pod %s/%s, %v", oriPod.Namespace, oriPod.Name, err)
+		return nil, nil, nil
+	}
+
+	pod := oriPod.DeepCopy()
+	podName := c.getNameByPod(pod)
+	var rules []ovnQoSRule
+	bindings := make(map[string]string, len(podNets))
+	for _, podNet := range podNets {
+		if !isOvnSubnet(podNet.Subnet) || pod.Annotations[fmt.Sprintf(util.AllocatedAnnotationTemplate, podNet.ProviderName)] != "true" {
+			continue
+		}
+		annotation := fmt.Sprintf(util.QoSPolicyAnnotationTemplate, podNet.ProviderName)
+		policy := podQoSPolicy(policies, pod, podNet.Subnet.Name)
+		portName := ovs.PodNameToPortName(podName, pod.Namespace, podNet.ProviderName)
+		rules = append(rules, c.podPortQoSRules(pod, podNet.ProviderName, portName, podNet.Subnet, policy)...)
+		if policy == nil {
+			bindings[portName] = ""
+			delete(pod.Annotations, annotation)
+			continue
+		}
+
+		bindings[portName] = policy.Name
+		pod.Annotations[annotation] = policy.Name
+	}
+	if reflect.DeepEqual(oriPod.Annotations, pod.Annotations) {
+		return rules, bindings, nil
+	}
+
+	patch, err := util.GenerateStrategicMergePatchPayload(oriPod, pod)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err = c.config.KubeClient.CoreV1().Pods(pod.Namespace).Patch(context.Background(), pod.Name,
+		types.StrategicMergePatchType, patch, metav1.PatchOptions{}, ""); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return rules, bindings, nil
+		}
+		klog.Errorf("failed to patch qos policy annotation of pod %s/%s, %v", pod.Namespace, pod.Name, err)
+		return nil, nil, err
+	}
+	return rules, bindings, nil
+}
+
+// podPortQoSRules returns the ovn qos rules of the port of the pod in the subnet
+func (c *Controller) podPortQoSRules(pod *v1.Pod, providerName, portName string, subnet *kubeovnv1.Subnet, policy *kubeovnv1.QoSPolicy) []ovnQoSRule {
+	var rules []ovnQoSRule
+	// the ports of vpc nat gateway pods are marked as well
+	if rule := ovnQoSDscpRule(portName, subnet, policy, pod.Annotations[fmt.Sprintf(util.DscpAnnotationTemplate, providerName)]); rule != nil {
+		rules = append(rules, *rule)
+	}
+	if c.config.QoSMode == util.QoSModeOvn {
+		rules = append(rules, c.ovnQoSRateRules(subnet.Name, portName, policy,
+			pod.Annotations[fmt.Sprintf(util.IngressRateAnnotationTemplate, providerName)],
+			pod.Annotations[fmt.Sprintf(util.EgressRateAnnotationTemplate, providerName)])...)
+	}
+	for i := range rules {
+		rules[i].pod = pod.Namespace + "/" + pod.Name
+	}
+	return rules
+}
+
+// podPortQoSOps generates the operations creating the ovn qos rules of the port of the pod,
+// so that they are committed together with the port
+func (c *Controller) podPortQoSOps(pod *v1.Pod, providerName, portName string, subnet *kubeovnv1.Subnet) ([]ovsdb.Operation, error) {
+	_, policies, _, err := c.listQoSPolicies()
+	if err != nil {
+		return nil, err
+	}
+	rules := c.podPortQoSRules(pod, providerName, portName, subnet, podQoSPolicy(policies, pod, subnet.Name))
+	qosList, err := c.ovnClient.ListQoSs(map[string]string{"qos-type": "", "port": portName})
+	if err != nil {
+		klog.Errorf("failed to list ovn qos rules of port %s, %v", portName, err)
+		return nil, err
+	}
+	return c.ovnQoSRulesOps(qosList, rules)
+}
+
+// syncNodeQoSPolicies annotates the nodes with the policies applied to the node ports, and returns the ovn qos rules of the ports
+func (c *Controller) syncNodeQoSPolicies(policies []*kubeovnv1.QoSPolicy, statuses map[string]*kubeovnv1.QoSPolicyStatus) ([]ovnQoSRule, error) {
+	nodes, err := c.nodesLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list nodes, %v", err)
+		return nil, err
+	}
+	joinSubnet, err := c.getQoSJoinSubnet()
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []ovnQoSRule
+	for _, node := range nodes {
+		nodeRules, policy, err := c.syncNodeQoSPolicy(node, joinSubnet, policies)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, nodeRules...)
+		if policy != "" {
+			statuses[policy].Ports = append(statuses[policy].Ports, nodeQoSPortName(node.Name))
+		}
+	}
+	return rules, nil
+}
+
+// getQoSJoinSubnet returns the join subnet whose dscp the node ports are marked with, nil is returned if it does not exist
+func (c *Controller) getQoSJoinSubnet() (*kubeovnv1.Subnet, error) {
+	joinSubnet, err := c.subnetsLister.Get(c.config.NodeSwitch)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		klog.Errorf("failed to get subnet %s, %v", c.config.NodeSwitch, err)
+		return nil, err
+	}
+	return joinSubnet, nil
+}
+
+func nodeQoSPortName(node string) string {
+	return fmt.Sprintf("node-%s", node)
+}
+
+// syncNodeQoSPolicy annotates the node with the policy applied to the node port, and returns the ovn qos rules
+// of the port together with the name of the policy, which is empty if no policy is bound
+func (c *Controller) syncNodeQoSPolicy(oriNode *v1.Node, joinSubnet *kubeovnv1.Subnet, policies []*kubeovnv1.QoSPolicy) ([]ovnQoSRule, string, error) {
+	node := oriNode.DeepCopy()
+	portName := nodeQoSPortName(node.Name)
+	policy := namedQoSPolicy(policies, kubeovnv1.QoSBindingTypeNode, node.Name)
+	var rules []ovnQoSRule
+	if joinSubnet != nil {
+		if rule := ovnQoSDscpRule(portName, joinSubnet, nil, ""); rule != nil {
+			rules = append(rules, *rule)
+		}
+	}
+	if c.config.QoSMode == util.QoSModeOvn {
+		rules = append(rules, c.ovnQoSRateRules(c.config.NodeSwitch, portName, policy,
+			node.Annotations[util.IngressRateAnnotation], node.Annotations[util.EgressRateAnnotation])...)
+	}
+	var policyName string
+	if policy != nil {
+		policyName = policy.Name
+		if node.Annotations == nil {
+			node.Annotations = make(map[string]string)
+		}
+		node.Annotations[util.QoSPolicyAnnotation] = policy.Name
+	} else {
+		delete(node.Annotations, util.QoSPolicyAnnotation)
+	}
+	if reflect.DeepEqual(oriNode.Annotations, node.Annotations) {
+		return rules, policyName, nil
+	}
+
+	patch, err := util.GenerateStrategicMergePatchPayload(oriNode, node)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err = c.config.KubeClient.CoreV1().Nodes().Patch(context.Background(), node.Name,
+		types.StrategicMergePatchType, patch, metav1.PatchOptions{}, ""); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return rules, policyName, nil
+		}
+		klog.Errorf("failed to patch qos policy annotation of node %s, %v", node.Name, err)
+		return nil, "", err
+	}
+	return rules, policyName, nil
+}
+
+// syncIptablesEipQoSPolicies enqueues the eips whose rate limits are changed by the policies
+func (c *Controller) syncIptablesEipQoSPolicies(policies []*kubeovnv1.QoSPolicy, statuses map[string]*kubeovnv1.QoSPolicyStatus) error {
+	eips, err := c.iptablesEipsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list iptables eips, %v", err)
+		return err
+	}
+
+	for _, eip := range eips {
+		if policy := namedQoSPolicy(policies, kubeovnv1.QoSBindingTypeEIP, eip.Name); policy != nil {
+			statuses[policy.Name].Eips = append(statuses[policy.Name].Eips, eip.Name)
+		}
+		if !eip.Status.Ready || !eip.DeletionTimestamp.IsZero() {
+			continue
+		}
+		ingressRate, egressRate := iptablesEipRates(policies, eip)
+		if natGwQoSChanged(ingressRate, egressRate, eip.Status.IngressRate, eip.Status.EgressRate, false) {
+			klog.V(3).Infof("enqueue update iptables eip %s for qos policies", eip.Name)
+			c.updateIptablesEipQueue.Add(eip.Name)
+		}
+	}
+	return nil
+}
+
+// iptablesEipRates returns the rate limits of the eip, the ones in spec take precedence over the policy
+func iptablesEipRates(policies []*kubeovnv1.QoSPolicy, eip *kubeovnv1.IptablesEIP) (string, string) {
+	ingressRate, egressRate := eip.Spec.IngressRate, eip.Spec.EgressRate
+	if policy := namedQoSPolicy(policies, kubeovnv1.QoSBindingTypeEIP, eip.Name); policy != nil {
+		if ingressRate == "" {
+			ingressRate = policy.Spec.IngressRate
+		}
+		if egressRate == "" {
+			egressRate = policy.Spec.EgressRate
+		}
+	}
+	return ingressRate, egressRate
+}
+
+// getIptablesEipRates returns the rate limits of the eip with the valid policies in cache
+func (c *Controller) getIptablesEipRates(eip *kubeovnv1.IptablesEIP) (string, string, error) {
+	_, policies, _, err := c.listQoSPolicies()
+	if err != nil {
+		return "", "", err
+	}
+	ingressRate, egressRate := iptablesEipRates(policies, eip)
+	return ingressRate, egressRate, nil
+}
+
+// syncOvnQoSRules makes the existing ovn qos rules managed by the controller consistent with the expected ones
+func (c *Controller) syncOvnQoSRules(qosList []ovnnb.QoS, rules []ovnQoSRule) error {
+	ops, err := c.ovnQoSRulesOps(qosList, rules)
+	if err != nil {
+		return err
+	}
+	txn := c.ovnClient.NewTransaction()
+	txn.Add(ops...)
+	if err = txn.Commit(); err != nil {
+		klog.Errorf("failed to sync ovn qos rules, %v", err)
+		return err
+	}
+	return nil
+}
+
+// ovnQoSRulesOps generates the operations deleting the qos rows not in the expected rules and creating the missing ones
+func (c *Controller) ovnQoSRulesOps(qosList []ovnnb.QoS, rules []ovnQoSRule) ([]ovsdb.Operation, error) {
+	expected := make(map[string]ovnQoSRule, len(rules))
+	for _, rule := range rules {
+		expected[rule.key()] = rule
+	}
+	var ops []ovsdb.Operation
+	for i := range qosList {
+		rule := ovnQoSRuleFromQoS(&qosList[i])
+		if _, ok := expected[rule.key()]; ok {
+			delete(expected, rule.key())
+			continue
+		}
+		delOps, err := c.ovnClient.DeleteQoSOps(rule.ls, qosList[i].UUID)
+		if err != nil {
+			klog.Error(err)
+			return nil, err
+		}
+		ops = append(ops, delOps...)
+	}
+
+	for _, rule := range expected {
+		createOps, err := c.ovnClient.CreateQoSOps(rule.ls, rule.qos())
+		if err != nil {
+			klog.Error(err)
+			return nil, err
+		}
+		ops = append(ops, createOps...)
+	}
+	return ops, nil
+}
+
+// syncSubnetDscpStatus records the dscp of the subnets marked by the ovn qos rules in their status
+func (c *Controller) syncSubnetDscpStatus() error {
+	subnets, err := c.subnetsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list subnets, %v", err)
+		return err
+	}
+	for _, subnet := range subnets {
+		if reflect.DeepEqual(subnet.Spec.Dscp, subnet.Status.Dscp) {
+			continue
+		}
+		// null is kept in the patch to clean the recorded value
+		dscp := "null"
+		if subnet.Spec.Dscp != nil {
+			dscp = strconv.Itoa(*subnet.Spec.Dscp)
+		}
+		if _, err = c.config.KubeOvnClient.KubeovnV1().Subnets().Patch(context.Background(), subnet.Name, types.MergePatchType,
+			[]byte(fmt.Sprintf(`{"status":{"dscp":%s}}`, dscp)), metav1.PatchOptions{}, "status"); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			klog.Errorf("failed to patch dscp status of subnet %s, %v", subnet.Name, err)
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,318 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func newQoSPolicyTestPod(namespace, name, subnet string, labels, annotations map[string]string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+			Annotations: map[string]string{
+				util.AllocatedAnnotation:     "true",
+				util.LogicalSwitchAnnotation: subnet,
+			},
+		},
+	}
+	for k, v := range annotations {
+		pod.Annotations[k] = v
+	}
+	return pod
+}
+
+// addQoSPolicy adds the policy to the fake clientset and the informer cache
+func addQoSPolicy(t *testing.T, ctrl *fakeController, policy *kubeovnv1.QoSPolicy) {
+	t.Helper()
+	policy, err := ctrl.kubeovnClient.KubeovnV1().QoSPolicies().Create(context.Background(), policy, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, ctrl.kubeovnInformerFactory.Kubeovn().V1().QoSPolicies().Informer().GetIndexer().Add(policy))
+}
+
+func Test_handleSyncQoSPolicies(t *testing.T) {
+	dscp := func(v int) *int { return &v }
+	subnets := []runtime.Object{
+		&kubeovnv1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: "ovn-default"}},
+		&kubeovnv1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: "net1"}},
+	}
+	pods := []runtime.Object{
+		newQoSPolicyTestPod("default", "web", "ovn-default", map[string]string{"app": "web"}, nil),
+		newQoSPolicyTestPod("prod", "db", "ovn-default", nil, nil),
+		newQoSPolicyTestPod("default", "client", "ovn-default", nil, nil),
+		newQoSPolicyTestPod("default", "stale", "net1", nil, map[string]string{util.QoSPolicyAnnotation: "removed"}),
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}},
+	}
+	ctrl := newFakeController(t, pods, subnets)
+	require.NoError(t, ctrl.legacyClient.CreateLogicalSwitch("ovn-default", util.DefaultVpc, "10.16.0.0/16", "10.16.0.1", false))
+
+	for _, policy := range []*kubeovnv1.QoSPolicy{{
+		ObjectMeta: metav1.ObjectMeta{Name: "subnet"},
+		Spec: kubeovnv1.QoSPolicySpec{
+			Binding:     kubeovnv1.QoSPolicyBinding{Type: kubeovnv1.QoSBindingTypeSubnet, Names: []string{"ovn-default"}},
+			IngressRate: "10",
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: "namespace"},
+		Spec: kubeovnv1.QoSPolicySpec{
+			Binding: kubeovnv1.QoSPolicyBinding{Type: kubeovnv1.QoSBindingTypeNamespace, Names: []string{"prod"}},
+			Dscp:    dscp(10),
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: "pod"},
+		Spec: kubeovnv1.QoSPolicySpec{
+			Binding: kubeovnv1.QoSPolicyBinding{
+				Type:        kubeovnv1.QoSBindingTypePod,
+				Namespace:   "default",
+				PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+			Dscp: dscp(20),
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: "invalid"},
+		Spec: kubeovnv1.QoSPolicySpec{
+			Binding: kubeovnv1.QoSPolicyBinding{Type: kubeovnv1.QoSBindingTypeSubnet},
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: "node"},
+		Spec: kubeovnv1.QoSPolicySpec{
+			Binding:    kubeovnv1.QoSPolicyBinding{Type: kubeovnv1.QoSBindingTypeNode, Names: []string{"node1"}},
+			EgressRate: "100",
+		},
+	}} {
+		addQoSPolicy(t, ctrl, policy)
+	}
+
+	require.NoError(t, ctrl.handleSyncQoSPolicies(qosPolicySyncKey))
+
+	// pod selector takes precedence over namespace and subnet
+	for name, policy := range map[string]string{"default/web": "pod", "prod/db": "namespace", "default/client": "subnet", "default/stale": ""} {
+		namespace, podName, _ := strings.Cut(name, "/")
+		pod, err := ctrl.kubeClient.CoreV1().Pods(namespace).Get(context.Background(), podName, metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, policy, pod.Annotations[util.QoSPolicyAnnotation], name)
+	}
+	node, err := ctrl.kubeClient.CoreV1().Nodes().Get(context.Background(), "node1", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "node", node.Annotations[util.QoSPolicyAnnotation])
+
+	statuses := map[string]kubeovnv1.QoSPolicyStatus{
+		"subnet":    {Ports: []string{"client.default"}},
+		"namespace": {Ports: []string{"db.prod"}},
+		"pod":       {Ports: []string{"web.default"}},
+		"node":      {Ports: []string{"node-node1"}},
+	}
+	for name, status := range statuses {
+		policy, err := ctrl.kubeovnClient.KubeovnV1().QoSPolicies().Get(context.Background(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, status, policy.Status, name)
+	}
+	invalid, err := ctrl.kubeovnClient.KubeovnV1().QoSPolicies().Get(context.Background(), "invalid", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotEmpty(t, invalid.Status.Error)
+
+	qosList, err := ctrl.ovnClient.ListQoSs(map[string]string{"qos-policy": ""})
+	require.NoError(t, err)
+	dscps := make(map[string]int, len(qosList))
+	for _, qos := range qosList {
+		dscps[qos.Match] = qos.Action["dscp"]
+	}
+	require.Equal(t, map[string]int{`inport == "web.default"`: 20, `inport == "db.prod"`: 10}, dscps)
+
+	// the dscp rule is updated, and the one of the deleted policy is removed from the port bound to the subnet
+	policy, err := ctrl.kubeovnClient.KubeovnV1().QoSPolicies().Get(context.Background(), "pod", metav1.GetOptions{})
+	require.NoError(t, err)
+	policy.Spec.Dscp = dscp(30)
+	require.NoError(t, ctrl.kubeovnInformerFactory.Kubeovn().V1().QoSPolicies().Informer().GetIndexer().Update(policy))
+	policy, err = ctrl.kubeovnClient.KubeovnV1().QoSPolicies().Get(context.Background(), "namespace", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NoError(t, ctrl.kubeovnInformerFactory.Kubeovn().V1().QoSPolicies().Informer().GetIndexer().Delete(policy))
+
+	require.NoError(t, ctrl.handleSyncQoSPolicies(qosPolicySyncKey))
+	qosList, err = ctrl.ovnClient.ListQoSs(map[string]string{"qos-policy": ""})
+	require.NoError(t, err)
+	require.Len(t, qosList, 1)
+	require.Equal(t, `inport == "web.default"`, qosList[0].Match)
+	require.Equal(t, map[string]int{"dscp": 30}, qosList[0].Action)
+	pod, err := ctrl.kubeClient.CoreV1().Pods("prod").Get(context.Background(), "db", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "subnet", pod.Annotations[util.QoSPolicyAnnotation])
+}
+
+func Test_handleSyncQoSPoliciesOvnMode(t *testing.T) {
+	subnets := []runtime.Object{
+		&kubeovnv1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: "ovn-default"}},
+		&kubeovnv1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: "net1"}, Spec: kubeovnv1.SubnetSpec{CIDRBlock: "10.17.0.0/16,fd00::/64"}},
+	}
+	pods := []runtime.Object{
+		newQoSPolicyTestPod("default", "web", "ovn-default", map[string]string{"app": "web"}, nil),
+		newQoSPolicyTestPod("default", "anno", "ovn-default", nil, map[string]string{util.IngressRateAnnotation: "5"}),
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Annotations: map[string]string{util.EgressRateAnnotation: "20"}}},
+	}
+	ctrl := newFakeController(t, pods, subnets)
+	ctrl.config.QoSMode = util.QoSModeOvn
+	require.NoError(t, ctrl.legacyClient.CreateLogicalSwitch("ovn-default", util.DefaultVpc, "10.16.0.0/16", "10.16.0.1", false))
+	require.NoError(t, ctrl.legacyClient.CreateLogicalSwitch("join", util.DefaultVpc, "100.64.0.0/16", "100.64.0.1", false))
+
+	addQoSPolicy(t, ctrl, &kubeovnv1.QoSPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: kubeovnv1.QoSPolicySpec{
+			Binding: kubeovnv1.QoSPolicyBinding{
+				Type:        kubeovnv1.QoSBindingTypePod,
+				Namespace:   "default",
+				PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+			EgressRate:         "10",
+			Burst:              "2",
+			DestinationCIDRs:   []string{"192.168.0.0/24"},
+			DestinationSubnets: []string{"net1"},
+		},
+	})
+
+	require.NoError(t, ctrl.handleSyncQoSPolicies(qosPolicySyncKey))
+
+	type rate struct {
+		ls, direction string
+		bandwidth     map[string]int
+	}
+	rates := func() map[string]rate {
+		qosList, err := ctrl.ovnClient.ListQoSs(map[string]string{"qos-type": ""})
+		require.NoError(t, err)
+		rates := make(map[string]rate, len(qosList))
+		for _, qos := range qosList {
+			rates[qos.Match] = rate{qos.ExternalIDs["ls"], qos.Direction, qos.Bandwidth}
+		}
+		return rates
+	}
+	// only the rates of the policy are limited to the destinations
+	require.Equal(t, map[string]rate{
+		`inport == "web.default" && (ip4.dst == {192.168.0.0/24, 10.17.0.0/16} || ip6.dst == {fd00::/64})`: {"ovn-default", "from-lport", map[string]int{"rate": 10000, "burst": 2000}},
+		`outport == "anno.default"`: {"ovn-default", "to-lport", map[string]int{"rate": 5000}},
+		`inport == "node-node1"`:    {"join", "from-lport", map[string]int{"rate": 20000}},
+	}, rates())
+
+	// the rule is removed with the annotation
+	pod, err := ctrl.kubeClient.CoreV1().Pods("default").Get(context.Background(), "anno", metav1.GetOptions{})
+	require.NoError(t, err)
+	delete(pod.Annotations, util.IngressRateAnnotation)
+	require.NoError(t, ctrl.informerFactory.Core().V1().Pods().Informer().GetIndexer().Update(pod))
+	require.NoError(t, ctrl.handleSyncQoSPolicies(qosPolicySyncKey))
+	require.Len(t, rates(), 2)
+	require.NotContains(t, rates(), `outport == "anno.default"`)
+}
+
+func Test_handleSyncQoSPoliciesDscp(t *testing.T) {
+	dscp := func(v int) *int { return &v }
+	subnets := []runtime.Object{
+		&kubeovnv1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: "ovn-default"}, Spec: kubeovnv1.SubnetSpec{Dscp: dscp(8)}},
+		&kubeovnv1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: "join"}, Spec: kubeovnv1.SubnetSpec{Dscp: dscp(16)}},
+	}
+	pods := []runtime.Object{
+		newQoSPolicyTestPod("default", "web", "ovn-default", map[string]string{"app": "web"}, nil),
+		newQoSPolicyTestPod("default", "anno", "ovn-default", map[string]string{"app": "web"}, map[string]string{util.DscpAnnotation: "46"}),
+		newQoSPolicyTestPod("default", "client", "ovn-default", nil, nil),
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}},
+	}
+	ctrl := newFakeController(t, pods, subnets)
+	require.NoError(t, ctrl.legacyClient.CreateLogicalSwitch("ovn-default", util.DefaultVpc, "10.16.0.0/16", "10.16.0.1", false))
+	require.NoError(t, ctrl.legacyClient.CreateLogicalSwitch("join", util.DefaultVpc, "100.64.0.0/16", "100.64.0.1", false))
+	addQoSPolicy(t, ctrl, &kubeovnv1.QoSPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: kubeovnv1.QoSPolicySpec{
+			Binding: kubeovnv1.QoSPolicyBinding{
+				Type:        kubeovnv1.QoSBindingTypePod,
+				Namespace:   "default",
+				PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+			Dscp: dscp(20),
+		},
+	})
+
+	require.NoError(t, ctrl.handleSyncQoSPolicies(qosPolicySyncKey))
+
+	qosList, err := ctrl.ovnClient.ListQoSs(map[string]string{"qos-type": ovnQoSTypeDscp})
+	require.NoError(t, err)
+	dscps := make(map[string]int, len(qosList))
+	for _, qos := range qosList {
+		require.Equal(t, "from-lport", qos.Direction)
+		dscps[qos.Match] = qos.Action["dscp"]
+	}
+	// the annotation takes precedence over the policy, and then the subnet
+	require.Equal(t, map[string]int{
+		`inport == "anno.default"`:   46,
+		`inport == "web.default"`:    20,
+		`inport == "client.default"`: 8,
+		`inport == "node-node1"`:     16,
+	}, dscps)
+
+	for _, name := range []string{"ovn-default", "join"} {
+		subnet, err := ctrl.kubeovnClient.KubeovnV1().Subnets().Get(context.Background(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, subnet.Spec.Dscp, subnet.Status.Dscp, name)
+	}
+}
+
+func Test_handleSyncPodQoSPolicy(t *testing.T) {
+	pods := []runtime.Object{
+		newQoSPolicyTestPod("default", "web", "ovn-default", map[string]string{"app": "web"}, nil),
+		newQoSPolicyTestPod("default", "anno", "ovn-default", nil, map[string]string{util.IngressRateAnnotation: "5"}),
+	}
+	ctrl := newFakeController(t, pods, []runtime.Object{&kubeovnv1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: "ovn-default"}}})
+	ctrl.config.QoSMode = util.QoSModeOvn
+	require.NoError(t, ctrl.legacyClient.CreateLogicalSwitch("ovn-default", util.DefaultVpc, "10.16.0.0/16", "10.16.0.1", false))
+	addQoSPolicy(t, ctrl, &kubeovnv1.QoSPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: kubeovnv1.QoSPolicySpec{
+			Binding: kubeovnv1.QoSPolicyBinding{
+				Type:        kubeovnv1.QoSBindingTypePod,
+				Namespace:   "default",
+				PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+			EgressRate: "10",
+		},
+	})
+
+	ports := func() []string {
+		qosList, err := ctrl.ovnClient.ListQoSs(map[string]string{"qos-type": ""})
+		require.NoError(t, err)
+		var ports []string
+		for _, qos := range qosList {
+			ports = append(ports, qos.ExternalIDs["port"])
+		}
+		return ports
+	}
+	require.NoError(t, ctrl.handleSyncQoSPolicies(qosPolicyPodKeyPrefix+"default/web"))
+	require.Equal(t, []string{"web.default"}, ports())
+	require.NoError(t, ctrl.handleSyncQoSPolicies(qosPolicyPodKeyPrefix+"default/anno"))
+	require.ElementsMatch(t, []string{"web.default", "anno.default"}, ports())
+
+	// the status of the policy is built from the annotation of the pod
+	pod, err := ctrl.kubeClient.CoreV1().Pods("default").Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "web", pod.Annotations[util.QoSPolicyAnnotation])
+	require.NoError(t, ctrl.informerFactory.Core().V1().Pods().Informer().GetIndexer().Update(pod))
+	require.NoError(t, ctrl.handleSyncQoSPolicies(qosPolicyStatusKeyPrefix+"web"))
+	policy, err := ctrl.kubeovnClient.KubeovnV1().QoSPolicies().Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"web.default"}, policy.Status.Ports)
+	require.NoError(t, ctrl.kubeovnInformerFactory.Kubeovn().V1().QoSPolicies().Informer().GetIndexer().Update(policy))
+
+	// only the rules of the deleted pod are removed
+	require.NoError(t, ctrl.informerFactory.Core().V1().Pods().Informer().GetIndexer().Delete(pod))
+	require.NoError(t, ctrl.handleSyncQoSPolicies(qosPolicyPodKeyPrefix+"default/web"))
+	require.Equal(t, []string{"anno.default"}, ports())
+	require.NoError(t, ctrl.handleSyncQoSPolicies(qosPolicyStatusKeyPrefix+"web"))
+	policy, err = ctrl.kubeovnClient.KubeovnV1().QoSPolicies().Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Empty(t, policy.Status.Ports)
+}
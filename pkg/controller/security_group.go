@@ -2,12 +2,15 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"net"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/cnf/structhash"
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -33,6 +36,7 @@ func (c *Controller) enqueueAddSg(obj interface{}) {
 	}
 	klog.V(3).Infof("enqueue add securityGroup %s", key)
 	c.addOrUpdateSgQueue.Add(key)
+	c.enqueueSyncFqdns(sgFqdns(obj.(*kubeovnv1.SecurityGroup)))
 }
 
 func (c *Controller) enqueueUpdateSg(old, new interface{}) {
@@ -41,7 +45,8 @@ func (c *Controller) enqueueUpdateSg(old, new interface{}) {
 	}
 	oldSg := old.(*kubeovnv1.SecurityGroup)
 	newSg := new.(*kubeovnv1.SecurityGroup)
-	if !reflect.DeepEqual(oldSg.Spec, newSg.Spec) {
+	if !reflect.DeepEqual(oldSg.Spec, newSg.Spec) ||
+		oldSg.Annotations[util.NetworkPolicyLogAnnotation] != newSg.Annotations[util.NetworkPolicyLogAnnotation] {
 		var key string
 		var err error
 		if key, err = cache.MetaNamespaceKeyFunc(new); err != nil {
@@ -50,6 +55,7 @@ func (c *Controller) enqueueUpdateSg(old, new interface{}) {
 		}
 		klog.V(3).Infof("enqueue update securityGroup %s", key)
 		c.addOrUpdateSgQueue.Add(key)
+		c.enqueueSyncFqdns(append(sgFqdns(oldSg), sgFqdns(newSg)...))
 	}
 }
 
@@ -65,6 +71,9 @@ func (c *Controller) enqueueDeleteSg(obj interface{}) {
 	}
 	klog.V(3).Infof("enqueue delete securityGroup %s", key)
 	c.delSgQueue.Add(key)
+	if sg, ok := obj.(*kubeovnv1.SecurityGroup); ok {
+		c.enqueueSyncFqdns(sgFqdns(sg))
+	}
 }
 
 func (c *Controller) runAddSgWorker() {
@@ -175,30 +184,37 @@ func (c *Controller) processNextDeleteSgWorkItem() bool {
 }
 
 func (c *Controller) initDenyAllSecurityGroup() error {
-	if err := c.ovnLegacyClient.CreateSgPortGroup(util.DenyAllSecurityGroup); err != nil {
+	if err := c.createSgPortGroup(util.DenyAllSecurityGroup); err != nil {
 		return err
 	}
-	if err := c.ovnLegacyClient.CreateSgDenyAllACL(); err != nil {
+	if err := c.ovnClient.CreateSgDenyAllACL(); err != nil {
 		return err
 	}
 	c.addOrUpdateSgQueue.Add(util.DenyAllSecurityGroup)
 	return nil
 }
 
+func (c *Controller) createSgPortGroup(sgName string) error {
+	pgName := ovs.GetSgPortGroupName(sgName)
+	return c.ovnClient.CreatePortGroup(pgName, map[string]string{"type": "security_group", "sg": sgName, "name": pgName})
+}
+
 func (c *Controller) updateDenyAllSgPorts() error {
-	results, err := c.ovnLegacyClient.CustomFindEntity("logical_switch_port", []string{"_uuid", "name", "port_security"}, "external_ids:security_groups!=\"\"")
+	results, err := c.ovnClient.ListLogicalSwitchPortsWithFilter(func(lsp *ovnnb.LogicalSwitchPort) bool {
+		return lsp.ExternalIDs["security_groups"] != ""
+	})
 	if err != nil {
 		klog.Errorf("failed to find logical port, %v", err)
 		return err
 	}
 	var ports []string
 	for _, ret := range results {
-		if len(ret["port_security"]) < 2 {
+		if len(lspPortSecurityAddresses(ret)) == 0 {
 			continue
 		}
-		ports = append(ports, ret["name"][0])
+		ports = append(ports, ret.Name)
 	}
-	return c.ovnLegacyClient.SetPortsToPortGroup(ovs.GetSgPortGroupName(util.DenyAllSecurityGroup), ports)
+	return c.ovnClient.PortGroupSetPorts(ovs.GetSgPortGroupName(util.DenyAllSecurityGroup), ports)
 }
 
 func (c *Controller) handleAddOrUpdateSg(key string) error {
@@ -222,16 +238,31 @@ func (c *Controller) handleAddOrUpdateSg(key string) error {
 	}
 	sg := cachedSg.DeepCopy()
 
-	if err = c.validateSgRule(sg); err != nil {
+	ingressErrors, egressErrors := c.validateSgRules(sg.Spec.IngressRules), c.validateSgRules(sg.Spec.EgressRules)
+	if len(ingressErrors) != 0 || len(egressErrors) != 0 {
+		sg.Status.IngressRules = sgRuleStatuses(sg.Spec.IngressRules, ingressErrors)
+		sg.Status.EgressRules = sgRuleStatuses(sg.Spec.EgressRules, egressErrors)
+		err = fmt.Errorf("invalid rules of sg %s, %s", sg.Name, sgRuleErrorsMessage(sg.Status))
+		sg.Status.NotValidated("ValidateSecurityGroupFailed", err.Error())
+		sg.Status.NotReady("ValidateSecurityGroupFailed", err.Error())
+		c.recorder.Eventf(sg, corev1.EventTypeWarning, "ValidateSecurityGroupFailed", err.Error())
+		c.patchSgStatus(sg)
 		return err
 	}
+	sg.Status.Validated("ValidateSecurityGroupSuccess", "")
 
-	if err = c.ovnLegacyClient.CreateSgPortGroup(sg.Name); err != nil {
+	if err = c.createSgPortGroup(sg.Name); err != nil {
 		return fmt.Errorf("failed to create sg port_group %s, %v", key, err.Error())
 	}
-	if err = c.ovnLegacyClient.CreateSgAssociatedAddressSet(sg.Name); err != nil {
+	if err = c.ovnClient.CreateSgAssociatedAddressSet(sg.Name); err != nil {
 		return fmt.Errorf("failed to create sg associated address_set %s, %v", key, err.Error())
 	}
+	// address sets of the domain names are created before acls referring to them
+	for _, fqdn := range sgFqdns(sg) {
+		if err = c.ovnClient.CreateFqdnAddressSets(fqdn); err != nil {
+			return fmt.Errorf("failed to create address_set of fqdn %s, %v", fqdn, err.Error())
+		}
+	}
 
 	ingressNeedUpdate := false
 	egressNeedUpdate := false
@@ -257,80 +288,127 @@ func (c *Controller) handleAddOrUpdateSg(key string) error {
 
 	// update sg rule
 	if ingressNeedUpdate {
-		if err = c.ovnLegacyClient.UpdateSgACL(sg, ovs.SgAclIngressDirection); err != nil {
+		if err = c.ovnClient.UpdateSgACL(sg, ovs.SgAclIngressDirection); err != nil {
 			sg.Status.IngressLastSyncSuccess = false
-			c.patchSgStatus(sg)
+			c.patchSgSyncFailedStatus(sg, sg.Spec.IngressRules, &sg.Status.IngressRules, err)
 			return err
 		}
 		sg.Status.IngressMd5 = newIngressMd5
 		sg.Status.IngressLastSyncSuccess = true
+		sg.Status.IngressRules = sgRuleStatuses(sg.Spec.IngressRules, nil)
 		c.patchSgStatus(sg)
 	}
 	if egressNeedUpdate {
-		if err = c.ovnLegacyClient.UpdateSgACL(sg, ovs.SgAclEgressDirection); err != nil {
+		if err = c.ovnClient.UpdateSgACL(sg, ovs.SgAclEgressDirection); err != nil {
 			sg.Status.EgressLastSyncSuccess = false
-			c.patchSgStatus(sg)
+			c.patchSgSyncFailedStatus(sg, sg.Spec.EgressRules, &sg.Status.EgressRules, err)
 			return err
 		}
 		sg.Status.EgressMd5 = newEgressMd5
 		sg.Status.EgressLastSyncSuccess = true
+		sg.Status.EgressRules = sgRuleStatuses(sg.Spec.EgressRules, nil)
 		c.patchSgStatus(sg)
 	}
 
+	// the acl log switch shares the annotation with network policies
+	logEnable := sg.Annotations[util.NetworkPolicyLogAnnotation] == "true"
+	pgName := ovs.GetSgPortGroupName(sg.Name)
+	for _, isIngress := range []bool{true, false} {
+		if err = c.ovnClient.SetAclLog(pgName, util.AclLogOwnerTypeSg, sg.Name, logEnable, isIngress); err != nil {
+			// just log and do not return err here
+			klog.Errorf("failed to set acl log for sg %s, %v", sg.Name, err)
+		}
+	}
+
 	// update status
 	sg.Status.PortGroup = ovs.GetSgPortGroupName(sg.Name)
 	sg.Status.AllowSameGroupTraffic = sg.Spec.AllowSameGroupTraffic
+	// the rules of sgs synced by previous versions have no results
+	if len(sg.Status.IngressRules) != len(sg.Spec.IngressRules) {
+		sg.Status.IngressRules = sgRuleStatuses(sg.Spec.IngressRules, nil)
+	}
+	if len(sg.Status.EgressRules) != len(sg.Spec.EgressRules) {
+		sg.Status.EgressRules = sgRuleStatuses(sg.Spec.EgressRules, nil)
+	}
+	if !sg.Status.IsReady() || ingressNeedUpdate || egressNeedUpdate {
+		c.recorder.Eventf(sg, corev1.EventTypeNormal, "SecurityGroupSynced", "acls of %d ingress rules and %d egress rules are synced", len(sg.Spec.IngressRules), len(sg.Spec.EgressRules))
+	}
+	sg.Status.Ready("SyncSecurityGroupSuccess", "")
 	c.patchSgStatus(sg)
 	c.syncSgPortsQueue.Add(key)
 	return nil
 }
 
-func (c *Controller) validateSgRule(sg *kubeovnv1.SecurityGroup) error {
-	// check sg rules
-	allRules := append(sg.Spec.IngressRules, sg.Spec.EgressRules...)
-	for _, rule := range allRules {
-		if rule.IPVersion != "ipv4" && rule.IPVersion != "ipv6" {
-			return fmt.Errorf("IPVersion should be 'ipv4' or 'ipv6'")
+// patchSgSyncFailedStatus records the results of the rules in one direction when the acls fail to be updated
+func (c *Controller) patchSgSyncFailedStatus(sg *kubeovnv1.SecurityGroup, rules []*kubeovnv1.SgRule, statuses *[]kubeovnv1.SgRuleStatus, err error) {
+	var ruleErrors ovs.SgRuleACLErrors
+	if errors.As(err, &ruleErrors) {
+		*statuses = sgRuleStatuses(rules, ruleErrors)
+	} else {
+		// none of the rules is synced if the acls are not cleared
+		ruleErrors = make(ovs.SgRuleACLErrors, len(rules))
+		for i := range rules {
+			ruleErrors[i] = err
 		}
+		*statuses = sgRuleStatuses(rules, ruleErrors)
+	}
+	sg.Status.NotReady("SyncSecurityGroupFailed", err.Error())
+	c.recorder.Eventf(sg, corev1.EventTypeWarning, "SyncSecurityGroupFailed", err.Error())
+	c.patchSgStatus(sg)
+}
 
-		if rule.Priority < 1 || rule.Priority > 200 {
-			return fmt.Errorf("priority '%d' is not in the range of 1 to 200", rule.Priority)
+// sgRuleStatuses returns the results of the rules, the errors are indexed by the failed rules
+func sgRuleStatuses(rules []*kubeovnv1.SgRule, ruleErrors map[int]error) []kubeovnv1.SgRuleStatus {
+	statuses := make([]kubeovnv1.SgRuleStatus, 0, len(rules))
+	for i, rule := range rules {
+		status := kubeovnv1.SgRuleStatus{Index: i, Priority: rule.Priority, Synced: ruleErrors[i] == nil}
+		if ruleErrors[i] != nil {
+			status.Message = ruleErrors[i].Error()
 		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
 
-		switch rule.RemoteType {
-		case kubeovnv1.SgRemoteTypeAddress:
-			if strings.Contains(rule.RemoteAddress, "/") {
-				if _, _, err := net.ParseCIDR(rule.RemoteAddress); err != nil {
-					return fmt.Errorf("invalid CIDR '%s'", rule.RemoteAddress)
-				}
-			} else {
-				if net.ParseIP(rule.RemoteAddress) == nil {
-					return fmt.Errorf("invalid ip address '%s'", rule.RemoteAddress)
-				}
+// sgRuleErrorsMessage joins the messages of the rules failed to sync
+func sgRuleErrorsMessage(status kubeovnv1.SecurityGroupStatus) string {
+	var msgs []string
+	for _, direction := range []struct {
+		name     string
+		statuses []kubeovnv1.SgRuleStatus
+	}{
+		{"ingress", status.IngressRules},
+		{"egress", status.EgressRules},
+	} {
+		for _, s := range direction.statuses {
+			if !s.Synced {
+				msgs = append(msgs, fmt.Sprintf("%s rule %d: %s", direction.name, s.Index, s.Message))
 			}
-		case kubeovnv1.SgRemoteTypeSg:
-			_, err := c.sgsLister.Get(rule.RemoteSecurityGroup)
-			if err != nil {
-				return fmt.Errorf("failed to get remote sg '%s', %v", rule.RemoteSecurityGroup, err)
-			}
-		default:
-			return fmt.Errorf("not support sgRemoteType '%s'", rule.RemoteType)
 		}
+	}
+	return strings.Join(msgs, "; ")
+}
 
-		if rule.Protocol == kubeovnv1.ProtocolTCP || rule.Protocol == kubeovnv1.ProtocolUDP {
-			if rule.PortRangeMin < 1 || rule.PortRangeMin > 65535 || rule.PortRangeMax < 1 || rule.PortRangeMax > 65535 {
-				return fmt.Errorf("portRange is out of range")
-			}
-			if rule.PortRangeMin > rule.PortRangeMax {
-				return fmt.Errorf("portRange err, range Minimum value greater than maximum value")
-			}
+// validateSgRules checks the rules in the same direction and the existence of the remote sgs,
+// the errors are indexed by the invalid rules
+func (c *Controller) validateSgRules(rules []*kubeovnv1.SgRule) map[int]error {
+	ruleErrors := util.ValidateSgRules(rules)
+	for i, rule := range rules {
+		if ruleErrors[i] != nil || rule.RemoteType != kubeovnv1.SgRemoteTypeSg {
+			continue
+		}
+		if _, err := c.sgsLister.Get(rule.RemoteSecurityGroup); err != nil {
+			ruleErrors[i] = fmt.Errorf("failed to get remote sg '%s', %v", rule.RemoteSecurityGroup, err)
 		}
 	}
-	return nil
+	return ruleErrors
 }
 
 func (c *Controller) patchSgStatus(sg *kubeovnv1.SecurityGroup) {
-	bytes, err := sg.Status.Bytes()
+	// the bound ports are patched by syncSgLogicalPort only
+	status := sg.Status.DeepCopy()
+	status.Ports, status.Pods = nil, nil
+	bytes, err := status.Bytes()
 	if err != nil {
 		klog.Error(err)
 		return
@@ -361,20 +439,27 @@ func (c *Controller) syncSgLogicalPort(key string) error {
 		return err
 	}
 
-	results, err := c.ovnLegacyClient.CustomFindEntity("logical_switch_port", []string{"_uuid", "name", "port_security"}, fmt.Sprintf("external_ids:associated_sg_%s=true", key))
+	sgKey := fmt.Sprintf("associated_sg_%s", key)
+	results, err := c.ovnClient.ListLogicalSwitchPortsWithFilter(func(lsp *ovnnb.LogicalSwitchPort) bool {
+		return lsp.ExternalIDs[sgKey] == "true"
+	})
 	if err != nil {
 		klog.Errorf("failed to find logical port, %v", err)
 		return err
 	}
 
 	var v4s, v6s []string
-	var ports []string
+	var ports, pods []string
 	for _, ret := range results {
-		if len(ret["port_security"]) < 2 {
+		addresses := lspPortSecurityAddresses(ret)
+		if len(addresses) == 0 {
 			continue
 		}
-		ports = append(ports, ret["name"][0])
-		for _, address := range ret["port_security"][1:] {
+		ports = append(ports, ret.Name)
+		if pod := ret.ExternalIDs["pod"]; pod != "" {
+			pods = append(pods, pod)
+		}
+		for _, address := range addresses {
 			if strings.Contains(address, ":") {
 				v6s = append(v6s, address)
 			} else {
@@ -383,22 +468,42 @@ func (c *Controller) syncSgLogicalPort(key string) error {
 		}
 	}
 
-	if err = c.ovnLegacyClient.SetPortsToPortGroup(sg.Status.PortGroup, ports); err != nil {
+	if err = c.ovnClient.PortGroupSetPorts(sg.Status.PortGroup, ports); err != nil {
 		klog.Errorf("failed to set port to sg, %v", err)
 		return err
 	}
-	if err = c.ovnLegacyClient.SetAddressesToAddressSet(v4s, ovs.GetSgV4AssociatedName(key)); err != nil {
+	if err = c.ovnClient.SetAddressesToAddressSet(v4s, ovs.GetSgV4AssociatedName(key)); err != nil {
 		klog.Errorf("failed to set address_set, %v", err)
 		return err
 	}
-	if err = c.ovnLegacyClient.SetAddressesToAddressSet(v6s, ovs.GetSgV6AssociatedName(key)); err != nil {
+	if err = c.ovnClient.SetAddressesToAddressSet(v6s, ovs.GetSgV6AssociatedName(key)); err != nil {
 		klog.Errorf("failed to set address_set, %v", err)
 		return err
 	}
+	c.patchSgBoundPorts(sg, ports, pods)
 	c.addOrUpdateSgQueue.Add(util.DenyAllSecurityGroup)
 	return nil
 }
 
+// patchSgBoundPorts records the ports and pods bound to the sg in the status,
+// only the two fields are patched to avoid overriding the status updated by handleAddOrUpdateSg
+func (c *Controller) patchSgBoundPorts(sg *kubeovnv1.SecurityGroup, ports, pods []string) {
+	sort.Strings(ports)
+	sort.Strings(pods)
+	pods = util.UniqString(pods)
+	if reflect.DeepEqual(ports, sg.Status.Ports) && reflect.DeepEqual(pods, sg.Status.Pods) {
+		return
+	}
+	bytes, err := json.Marshal(map[string]interface{}{"status": map[string][]string{"ports": ports, "pods": pods}})
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+	if _, err = c.config.KubeOvnClient.KubeovnV1().SecurityGroups().Patch(context.Background(), sg.Name, types.MergePatchType, bytes, metav1.PatchOptions{}, "status"); err != nil {
+		klog.Errorf("failed to patch bound ports of sg %s, %v", sg.Name, err)
+	}
+}
+
 func (c *Controller) getPortSg(port *ovnnb.LogicalSwitchPort) ([]string, error) {
 	var sgList []string
 	for key, value := range port.ExternalIDs {
@@ -446,3 +551,15 @@ func (c *Controller) reconcilePortSg(portName, securityGroups string) error {
 	}
 	return nil
 }
+
+// lspPortSecurityAddresses returns the ip addresses in the port security of the logical switch port
+func lspPortSecurityAddresses(lsp ovnnb.LogicalSwitchPort) []string {
+	var addresses []string
+	for _, ps := range lsp.PortSecurity {
+		// the first field of each port security entry is the mac address
+		if fields := strings.Fields(ps); len(fields) > 1 {
+			addresses = append(addresses, fields[1:]...)
+		}
+	}
+	return addresses
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// sgACLErrorsClient fails the acls of the ingress rules of security groups with the errors
+type sgACLErrorsClient struct {
+	ovs.NbClient
+	errors ovs.SgRuleACLErrors
+}
+
+func (c *sgACLErrorsClient) UpdateSgACL(sg *kubeovnv1.SecurityGroup, direction ovs.AclDirection) error {
+	if err := c.NbClient.UpdateSgACL(sg, direction); err != nil {
+		return err
+	}
+	if direction == ovs.SgAclIngressDirection && len(c.errors) != 0 {
+		return c.errors
+	}
+	return nil
+}
+
+func Test_handleAddOrUpdateSgStatus(t *testing.T) {
+	ctrl := newFakeController(t, nil, nil)
+	ovnClient := &sgACLErrorsClient{NbClient: ctrl.ovnClient}
+	ctrl.ovnClient = ovnClient
+	sgIndexer := ctrl.kubeovnInformerFactory.Kubeovn().V1().SecurityGroups().Informer().GetIndexer()
+	sg := &kubeovnv1.SecurityGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: kubeovnv1.SecurityGroupSpec{
+			IngressRules: []*kubeovnv1.SgRule{
+				{IPVersion: "ipv4", Protocol: kubeovnv1.ProtocolTCP, Priority: 10, RemoteType: kubeovnv1.SgRemoteTypeAddress, RemoteAddress: "10.0.0.0/8", PortRangeMin: 80, PortRangeMax: 80, Policy: kubeovnv1.PolicyAllow},
+				{IPVersion: "ipv4", Protocol: kubeovnv1.ProtocolALL, Priority: 10, RemoteType: kubeovnv1.SgRemoteTypeAddress, RemoteAddress: "0.0.0.0/0", Policy: kubeovnv1.PolicyDrop},
+			},
+			EgressRules: []*kubeovnv1.SgRule{
+				{IPVersion: "ipv6", Protocol: kubeovnv1.ProtocolALL, Priority: 1, RemoteType: kubeovnv1.SgRemoteTypeAddress, RemoteAddress: "10.0.0.1", Policy: kubeovnv1.PolicyAllow},
+			},
+		},
+	}
+	_, err := ctrl.kubeovnClient.KubeovnV1().SecurityGroups().Create(context.Background(), sg, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	// sync refreshes the cache with the updated spec and the patched status as the informer does
+	sync := func() (*kubeovnv1.SecurityGroup, error) {
+		sg, err := ctrl.kubeovnClient.KubeovnV1().SecurityGroups().Update(context.Background(), sg, metav1.UpdateOptions{})
+		require.NoError(t, err)
+		require.NoError(t, sgIndexer.Add(sg))
+		err = ctrl.handleAddOrUpdateSg(sg.Name)
+		sg, _ = ctrl.kubeovnClient.KubeovnV1().SecurityGroups().Get(context.Background(), sg.Name, metav1.GetOptions{})
+		return sg, err
+	}
+
+	// invalid rules are reported without touching the acls
+	sg, err = sync()
+	require.Error(t, err)
+	require.Equal(t, corev1.ConditionFalse, sg.Status.GetCondition(kubeovnv1.Validated).Status)
+	require.False(t, sg.Status.IsReady())
+	require.Equal(t, []bool{true, false}, []bool{sg.Status.IngressRules[0].Synced, sg.Status.IngressRules[1].Synced})
+	require.Contains(t, sg.Status.IngressRules[1].Message, "overlaps with rule 0")
+	require.False(t, sg.Status.EgressRules[0].Synced)
+	acls, err := ctrl.ovnClient.ListPortGroupACLs(ovs.GetSgPortGroupName(sg.Name), "")
+	require.NoError(t, err)
+	require.Empty(t, acls)
+
+	// the failed rule is reported when the acls are rejected
+	sg.Spec.IngressRules[1].Priority = 20
+	sg.Spec.EgressRules[0].RemoteAddress = "fd00::1"
+	ovnClient.errors = ovs.SgRuleACLErrors{1: errors.New("syntax error")}
+	sg, err = sync()
+	require.Error(t, err)
+	require.True(t, sg.Status.IsConditionTrue(kubeovnv1.Validated))
+	require.False(t, sg.Status.IsReady())
+	require.False(t, sg.Status.IngressLastSyncSuccess)
+	require.Equal(t, []kubeovnv1.SgRuleStatus{
+		{Index: 0, Priority: 10, Synced: true},
+		{Index: 1, Priority: 20, Message: "syntax error"},
+	}, sg.Status.IngressRules)
+
+	ovnClient.errors = nil
+	sg, err = sync()
+	require.NoError(t, err)
+	require.True(t, sg.Status.IsReady())
+	require.True(t, sg.Status.IngressLastSyncSuccess)
+	require.True(t, sg.Status.EgressLastSyncSuccess)
+	require.Equal(t, []kubeovnv1.SgRuleStatus{{Index: 0, Priority: 1, Synced: true}}, sg.Status.EgressRules)
+	require.True(t, sg.Status.IngressRules[1].Synced)
+
+	// the acls of the rules are logged with the owner
+	acls, err = ctrl.ovnClient.ListPortGroupACLs(sg.Status.PortGroup, "")
+	require.NoError(t, err)
+	var matches []string
+	for _, acl := range acls {
+		matches = append(matches, acl.Match)
+		require.Equal(t, sg.Name, acl.ExternalIDs[util.AclLogOwnerKey])
+		require.NotNil(t, acl.Name)
+		require.Equal(t, ovs.GetAclLogName(util.AclLogOwnerTypeSg, sg.Name), *acl.Name)
+	}
+	require.ElementsMatch(t, []string{
+		"outport==@ovn.sg.web && ip4 && ip4.src==10.0.0.0/8 && 80<=tcp.dst<=80",
+		"outport==@ovn.sg.web && ip4 && ip4.src==0.0.0.0/0",
+		"inport==@ovn.sg.web && ip6 && ip6.dst==fd00::1",
+	}, matches)
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
@@ -49,7 +50,7 @@ func (c *Controller) enqueueAddService(obj interface{}) {
 		}
 	}
 
-	if c.config.EnableLbSvc {
+	if c.config.EnableLbSvc || c.lbIpamEnabled() {
 		klog.V(3).Infof("enqueue add service %s", key)
 		c.addServiceQueue.Add(key)
 	}
@@ -94,6 +95,12 @@ func (c *Controller) enqueueDeleteService(obj interface{}) {
 			klog.Infof("delete vpc service %v", vpcSvc)
 			c.deleteServiceQueue.Add(vpcSvc)
 		}
+
+		// release the loadbalancer ips before the service with the same name is added again
+		if ips := lbIpamIPs(svc); len(ips) != 0 {
+			c.releaseLbIpamAddress(lbIpamKey(svc))
+			c.enqueueDeleteLbIpamVips(svc, ips)
+		}
 	}
 }
 
@@ -115,6 +122,35 @@ func (c *Controller) enqueueUpdateService(old, new interface{}) {
 	}
 	klog.V(3).Infof("enqueue update service %s", key)
 	c.updateServiceQueue.Add(key)
+
+	// the backends of the vips are selected by the node of the client or the external gateway nodes by the traffic policies
+	if !reflect.DeepEqual(oldSvc.Spec.InternalTrafficPolicy, newSvc.Spec.InternalTrafficPolicy) ||
+		oldSvc.Spec.ExternalTrafficPolicy != newSvc.Spec.ExternalTrafficPolicy {
+		c.updateEndpointQueue.Add(key)
+	}
+
+	// the vips are moved to or from the template load balancers by the topology aware hints
+	if topologyAwareHintsEnabled(oldSvc) != topologyAwareHintsEnabled(newSvc) {
+		c.updateEndpointQueue.Add(key)
+	}
+
+	// the vips are moved to the load balancers of the session affinity
+	if oldSvc.Spec.SessionAffinity != newSvc.Spec.SessionAffinity ||
+		getServiceAffinityTimeout(oldSvc) != getServiceAffinityTimeout(newSvc) ||
+		oldSvc.Annotations[util.ServiceLbHashAnnotation] != newSvc.Annotations[util.ServiceLbHashAnnotation] {
+		c.updateEndpointQueue.Add(key)
+	}
+
+	// the loadbalancer ips are allocated again once they are changed by others
+	if c.lbIpamEnabled() && (oldSvc.Spec.Type != newSvc.Spec.Type ||
+		!reflect.DeepEqual(oldSvc.Spec.LoadBalancerClass, newSvc.Spec.LoadBalancerClass) ||
+		oldSvc.Spec.LoadBalancerIP != newSvc.Spec.LoadBalancerIP ||
+		oldSvc.Annotations[util.LbIpamSubnetAnnotation] != newSvc.Annotations[util.LbIpamSubnetAnnotation] ||
+		oldSvc.Annotations[util.LbIpamIPPoolAnnotation] != newSvc.Annotations[util.LbIpamIPPoolAnnotation] ||
+		oldSvc.Annotations[util.LbIpamIPAnnotation] != newSvc.Annotations[util.LbIpamIPAnnotation] ||
+		!reflect.DeepEqual(oldSvc.Status.LoadBalancer.Ingress, newSvc.Status.LoadBalancer.Ingress)) {
+		c.addServiceQueue.Add(key)
+	}
 }
 
 func (c *Controller) runAddServiceWorker() {
@@ -236,24 +272,28 @@ func (c *Controller) handleDeleteService(service *vpcService) error {
 		}
 	}
 
-	vpcLbConfig := c.GenVpcLoadBalancer(service.Vpc)
+	lbs, err := c.getVpcLoadBalancers(service.Vpc, service.Protocol)
+	if err != nil {
+		return err
+	}
 	vip := service.Vip
-	if service.Protocol == v1.ProtocolTCP {
-		if err := c.ovnLegacyClient.DeleteLoadBalancerVip(vip, vpcLbConfig.TcpLoadBalancer); err != nil {
-			klog.Errorf("failed to delete vip %s from tcp lb, %v", vip, err)
-			return err
-		}
-		if err := c.ovnLegacyClient.DeleteLoadBalancerVip(vip, vpcLbConfig.TcpSessLoadBalancer); err != nil {
-			klog.Errorf("failed to delete vip %s from tcp session lb, %v", vip, err)
-			return err
-		}
-	} else {
-		if err := c.ovnLegacyClient.DeleteLoadBalancerVip(vip, vpcLbConfig.UdpLoadBalancer); err != nil {
-			klog.Errorf("failed to delete vip %s from udp lb, %v", vip, err)
+	vpcName := service.Vpc
+	if vpcName == "" {
+		vpcName = util.DefaultVpc
+	}
+	lbProtocol := util.ProtocolTCP
+	if service.Protocol != v1.ProtocolTCP {
+		lbProtocol = util.ProtocolUDP
+	}
+	for _, lb := range append(lbs, lbIpamRouterLoadBalancer(vpcName, lbProtocol)) {
+		if err := c.ovnClient.LoadBalancerDeleteVip(lb, loadBalancerVipKey(lb, vip, service.Protocol)); err != nil {
+			klog.Errorf("failed to delete vip %s from lb %s, %v", vip, lb, err)
 			return err
 		}
-		if err := c.ovnLegacyClient.DeleteLoadBalancerVip(vip, vpcLbConfig.UdpSessLoadBalancer); err != nil {
-			klog.Errorf("failed to delete vip %s from udp session lb, %v", vip, err)
+	}
+	if c.ovnClient.ChassisTemplateVarSupported() {
+		if err := c.ovnClient.UpdateChassisTemplateVars(templateVars(vip, service.Protocol, nil)); err != nil {
+			klog.Errorf("failed to delete template variables of vip %s, %v", vip, err)
 			return err
 		}
 	}
@@ -305,56 +345,68 @@ func (c *Controller) handleUpdateService(key string) error {
 		return err
 	}
 
-	tcpLb, udpLb := vpc.Status.TcpLoadBalancer, vpc.Status.UdpLoadBalancer
-	oTcpLb, oUdpLb := vpc.Status.TcpSessionLoadBalancer, vpc.Status.UdpSessionLoadBalancer
-	if svc.Spec.SessionAffinity == v1.ServiceAffinityClientIP {
-		tcpLb, udpLb = vpc.Status.TcpSessionLoadBalancer, vpc.Status.UdpSessionLoadBalancer
-		oTcpLb, oUdpLb = vpc.Status.TcpLoadBalancer, vpc.Status.UdpLoadBalancer
+	// the vips are moved from the other load balancers of the vpc once the session affinity is changed
+	tcpServiceLb, udpServiceLb := getServiceLoadBalancers(svc, vpc, c.ovnClient.LoadBalancerAffinityTimeoutSupported())
+	tcpLb, udpLb := tcpServiceLb.name, udpServiceLb.name
+	tcpLbs, err := c.getVpcLoadBalancers(vpcName, v1.ProtocolTCP)
+	if err != nil {
+		return err
+	}
+	udpLbs, err := c.getVpcLoadBalancers(vpcName, v1.ProtocolUDP)
+	if err != nil {
+		return err
 	}
 
-	for _, port := range svc.Spec.Ports {
-		if port.Protocol == v1.ProtocolTCP {
-			if util.CheckProtocol(ip) == kubeovnv1.ProtocolIPv6 {
-				tcpVips = append(tcpVips, fmt.Sprintf("[%s]:%d", ip, port.Port))
-			} else {
-				tcpVips = append(tcpVips, fmt.Sprintf("%s:%d", ip, port.Port))
-			}
-		} else if port.Protocol == v1.ProtocolUDP {
-			if util.CheckProtocol(ip) == kubeovnv1.ProtocolIPv6 {
-				udpVips = append(udpVips, fmt.Sprintf("[%s]:%d", ip, port.Port))
-			} else {
-				udpVips = append(udpVips, fmt.Sprintf("%s:%d", ip, port.Port))
+	ips := []string{ip}
+	if isLbIpamService(svc) {
+		ips = append(ips, lbIpamIPs(svc)...)
+	}
+	for _, ip := range ips {
+		for _, port := range svc.Spec.Ports {
+			if port.Protocol == v1.ProtocolTCP {
+				if util.CheckProtocol(ip) == kubeovnv1.ProtocolIPv6 {
+					tcpVips = append(tcpVips, fmt.Sprintf("[%s]:%d", ip, port.Port))
+				} else {
+					tcpVips = append(tcpVips, fmt.Sprintf("%s:%d", ip, port.Port))
+				}
+			} else if port.Protocol == v1.ProtocolUDP {
+				if util.CheckProtocol(ip) == kubeovnv1.ProtocolIPv6 {
+					udpVips = append(udpVips, fmt.Sprintf("[%s]:%d", ip, port.Port))
+				} else {
+					udpVips = append(udpVips, fmt.Sprintf("%s:%d", ip, port.Port))
+				}
 			}
 		}
 	}
 	// for service update
-	lbUuid, err := c.ovnLegacyClient.FindLoadbalancer(tcpLb)
-	if err != nil {
-		klog.Errorf("failed to get lb %v", err)
-		return err
-	}
-	vips, err := c.ovnLegacyClient.GetLoadBalancerVips(lbUuid)
+	vips, err := c.ovnClient.GetLoadBalancerVips(tcpLb)
 	if err != nil {
 		klog.Errorf("failed to get tcp lb vips %v", err)
 		return err
 	}
 	klog.V(3).Infof("exist tcp vips are %v", vips)
 	for _, vip := range tcpVips {
-		if err := c.ovnLegacyClient.DeleteLoadBalancerVip(vip, oTcpLb); err != nil {
-			klog.Errorf("failed to delete lb %s form %s, %v", vip, oTcpLb, err)
-			return err
+		// the template load balancer of the service is synced with the endpoints
+		templateLb := templateLoadBalancer(tcpServiceLb, vipAddressFamily(parseVipAddr(vip))).name
+		for _, lb := range tcpLbs {
+			if lb == tcpLb || lb == templateLb {
+				continue
+			}
+			if err := c.ovnClient.LoadBalancerDeleteVip(lb, loadBalancerVipKey(lb, vip, v1.ProtocolTCP)); err != nil {
+				klog.Errorf("failed to delete lb %s form %s, %v", vip, lb, err)
+				return err
+			}
 		}
 		if _, ok := vips[vip]; !ok {
-			klog.Infof("add vip %s to tcp lb %s", vip, oTcpLb)
+			klog.Infof("add vip %s to tcp lb %s", vip, tcpLb)
 			c.updateEndpointQueue.Add(key)
-			break
 		}
 	}
 
 	for vip := range vips {
-		if parseVipAddr(vip) == ip && !util.IsStringIn(vip, tcpVips) {
+		if util.ContainsString(ips, parseVipAddr(vip)) && !util.IsStringIn(vip, tcpVips) {
 			klog.Infof("remove stall vip %s", vip)
-			err := c.ovnLegacyClient.DeleteLoadBalancerVip(vip, tcpLb)
+			err := c.ovnClient.LoadBalancerDeleteVip(tcpLb, vip)
 			if err != nil {
 				klog.Errorf("failed to delete vip %s from tcp lb %v", vip, err)
 				return err
@@ -362,33 +414,34 @@ func (c *Controller) handleUpdateService(key string) error {
 		}
 	}
 
-	lbUuid, err = c.ovnLegacyClient.FindLoadbalancer(udpLb)
-	if err != nil {
-		klog.Errorf("failed to get lb %v", err)
-		return err
-	}
-	vips, err = c.ovnLegacyClient.GetLoadBalancerVips(lbUuid)
+	vips, err = c.ovnClient.GetLoadBalancerVips(udpLb)
 	if err != nil {
 		klog.Errorf("failed to get udp lb vips %v", err)
 		return err
 	}
 	klog.Infof("exist udp vips are %v", vips)
 	for _, vip := range udpVips {
-		if err := c.ovnLegacyClient.DeleteLoadBalancerVip(vip, oUdpLb); err != nil {
-			klog.Errorf("failed to delete lb %s form %s, %v", vip, oUdpLb, err)
-			return err
+		// the template load balancer of the service is synced with the endpoints
+		templateLb := templateLoadBalancer(udpServiceLb, vipAddressFamily(parseVipAddr(vip))).name
+		for _, lb := range udpLbs {
+			if lb == udpLb || lb == templateLb {
+				continue
+			}
+			if err := c.ovnClient.LoadBalancerDeleteVip(lb, loadBalancerVipKey(lb, vip, v1.ProtocolUDP)); err != nil {
+				klog.Errorf("failed to delete lb %s form %s, %v", vip, lb, err)
+				return err
+			}
 		}
 		if _, ok := vips[vip]; !ok {
-			klog.Infof("add vip %s to udp lb %s", vip, oUdpLb)
+			klog.Infof("add vip %s to udp lb %s", vip, udpLb)
 			c.updateEndpointQueue.Add(key)
-			break
 		}
 	}
 
 	for vip := range vips {
-		if parseVipAddr(vip) == ip && !util.IsStringIn(vip, udpVips) {
+		if util.ContainsString(ips, parseVipAddr(vip)) && !util.IsStringIn(vip, udpVips) {
 			klog.Infof("remove stall vip %s", vip)
-			if err := c.ovnLegacyClient.DeleteLoadBalancerVip(vip, udpLb); err != nil {
+			if err := c.ovnClient.LoadBalancerDeleteVip(udpLb, vip); err != nil {
 				klog.Errorf("failed to delete vip %s from udp lb %v", vip, err)
 				return err
 			}
@@ -421,6 +474,9 @@ func (c *Controller) handleAddService(key string) error {
 		}
 		return err
 	}
+	if c.lbIpamEnabled() {
+		return c.handleLbIpam(svc)
+	}
 	if svc.Spec.Type != v1.ServiceTypeLoadBalancer || !c.config.EnableLbSvc {
 		return nil
 	}
@@ -430,6 +486,11 @@ func (c *Controller) handleAddService(key string) error {
 		klog.Errorf("failed to validate lb svc, %v", err)
 		return err
 	}
+	if svc.Spec.ExternalTrafficPolicy == v1.ServiceExternalTrafficPolicyTypeLocal {
+		// the lb svc pod forwards the traffic to the cluster ip with masquerade for the replies to return through it
+		c.recorder.Eventf(svc, v1.EventTypeWarning, "ExternalTrafficPolicyIgnored",
+			"external traffic policy %s is not supported by the lb svc pod, the client source ip is not preserved", v1.ServiceExternalTrafficPolicyTypeLocal)
+	}
 
 	if err = c.checkAttachNetwork(svc); err != nil {
 		klog.Errorf("failed to check attachment network, %v", err)
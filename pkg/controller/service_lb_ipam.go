@@ -0,0 +1,381 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func (c *Controller) lbIpamEnabled() bool {
+	return c.config.LbIpamSubnet != "" || c.config.LbIpamIPPool != ""
+}
+
+// isLbIpamService returns whether the ingress ips of the service are allocated by kube-ovn,
+// services with a loadbalancer class are left to the implementation of the class
+func isLbIpamService(svc *v1.Service) bool {
+	return svc.Spec.Type == v1.ServiceTypeLoadBalancer && svc.Spec.LoadBalancerClass == nil
+}
+
+// lbIpamKey returns the ipam key of the ingress ips of the service, which never conflicts with the keys of pods
+func lbIpamKey(svc *v1.Service) string {
+	return fmt.Sprintf("service/%s/%s", svc.Namespace, svc.Name)
+}
+
+// lbIpamIPs returns the ingress ips allocated to the service
+func lbIpamIPs(svc *v1.Service) []string {
+	if ips := svc.Annotations[util.LbIpamIPAnnotation]; ips != "" {
+		return strings.Split(ips, ",")
+	}
+	return nil
+}
+
+// getLbIpamSubnet returns the subnet and ippool to allocate the ingress ips of the service from,
+// the ones in the annotations of the service take precedence over the default ones
+func (c *Controller) getLbIpamSubnet(svc *v1.Service) (string, string, error) {
+	subnet, pool := c.config.LbIpamSubnet, c.config.LbIpamIPPool
+	if name := svc.Annotations[util.LbIpamSubnetAnnotation]; name != "" {
+		subnet, pool = name, ""
+	}
+	if name := svc.Annotations[util.LbIpamIPPoolAnnotation]; name != "" {
+		if svc.Annotations[util.LbIpamSubnetAnnotation] == "" {
+			subnet = ""
+		}
+		pool = name
+	}
+
+	if pool != "" {
+		ippool, err := c.ippoolLister.Get(pool)
+		if err != nil {
+			klog.Errorf("failed to get ippool %s, %v", pool, err)
+			return "", "", err
+		}
+		if subnet != "" && subnet != ippool.Spec.Subnet {
+			return "", "", fmt.Errorf("ippool %s does not belong to subnet %s", pool, subnet)
+		}
+		subnet = ippool.Spec.Subnet
+	}
+	if subnet == "" {
+		return "", "", fmt.Errorf("no subnet to allocate loadbalancer ips from")
+	}
+	return subnet, pool, nil
+}
+
+// handleLbIpam allocates the ingress ips of the loadbalancer service, or releases them once the service is no longer
+// a loadbalancer one. The ips are recorded in the annotation of the service, from which the vips are added to the
+// load balancers of the vpc and announced by kube-ovn-speaker
+func (c *Controller) handleLbIpam(svc *v1.Service) error {
+	key := lbIpamKey(svc)
+	allocated := lbIpamIPs(svc)
+	if !isLbIpamService(svc) {
+		if len(allocated) == 0 {
+			return nil
+		}
+		klog.Infof("release loadbalancer ips %v of service %s/%s", allocated, svc.Namespace, svc.Name)
+		c.releaseLbIpamAddress(key)
+		c.enqueueDeleteLbIpamVips(svc, allocated)
+		return c.patchLbIpam(svc, nil)
+	}
+
+	subnet, pool, err := c.getLbIpamSubnet(svc)
+	if err != nil {
+		klog.Errorf("failed to get loadbalancer ipam subnet of service %s/%s, %v", svc.Namespace, svc.Name, err)
+		c.recorder.Eventf(svc, v1.EventTypeWarning, "AllocateLoadBalancerIPFailed", err.Error())
+		return err
+	}
+	c.checkLbIpamSubnetReachable(svc, subnet)
+
+	// the allocated ips are kept unless they are out of the subnet or differ from the requested one
+	requested := svc.Spec.LoadBalancerIP
+	assigned := c.getLbIpamAssignedIPs(key, subnet)
+	valid := len(allocated) != 0
+	for _, ip := range allocated {
+		if !util.ContainsString(assigned, ip) {
+			valid = false
+		}
+	}
+	if valid && requested != "" && !util.ContainsString(allocated, requested) {
+		valid = false
+	}
+
+	ips := allocated
+	if !valid {
+		c.releaseLbIpamAddress(key)
+		var v4IP, v6IP string
+		if requested != "" {
+			v4IP, v6IP, _, err = c.ipam.GetStaticAddress(key, key, requested, "", subnet, true)
+		} else {
+			v4IP, v6IP, _, err = c.ipam.GetRandomAddress(key, key, "", subnet, pool, nil, true)
+		}
+		if err != nil {
+			klog.Errorf("failed to allocate loadbalancer ip for service %s/%s from subnet %s, %v", svc.Namespace, svc.Name, subnet, err)
+			c.recorder.Eventf(svc, v1.EventTypeWarning, "AllocateLoadBalancerIPFailed", "failed to allocate ip from subnet %s: %v", subnet, err)
+			return err
+		}
+
+		ips = nil
+		for _, ip := range []string{v4IP, v6IP} {
+			if ip != "" && serviceHasIPFamily(svc, ip) {
+				ips = append(ips, ip)
+			}
+		}
+		if len(ips) == 0 {
+			c.releaseLbIpamAddress(key)
+			return fmt.Errorf("subnet %s has no ip family of service %s/%s", subnet, svc.Namespace, svc.Name)
+		}
+		klog.Infof("allocate loadbalancer ips %v for service %s/%s", ips, svc.Namespace, svc.Name)
+		var stale []string
+		for _, ip := range allocated {
+			if !util.ContainsString(ips, ip) {
+				stale = append(stale, ip)
+			}
+		}
+		c.enqueueDeleteLbIpamVips(svc, stale)
+		c.updateSubnetStatusQueue.Add(subnet)
+	}
+
+	if err = c.patchLbIpam(svc, ips); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(ips, allocated) {
+		c.updateEndpointQueue.Add(fmt.Sprintf("%s/%s", svc.Namespace, svc.Name))
+	}
+	return nil
+}
+
+// checkLbIpamSubnetReachable warns if the ingress ips are allocated from an underlay subnet other than the external
+// one. OVN never answers arp requests from the localnet port of an underlay subnet unless the vip is owned by a
+// distributed gateway port on it, which only the vpcs connected to the external subnet have
+func (c *Controller) checkLbIpamSubnetReachable(svc *v1.Service, subnet string) {
+	if subnet == util.VpcExternalNet {
+		return
+	}
+	s, err := c.subnetsLister.Get(subnet)
+	if err != nil || s.Spec.Vlan == "" {
+		return
+	}
+	c.recorder.Eventf(svc, v1.EventTypeWarning, "LoadBalancerIPUnreachable",
+		"the underlay network does not resolve ingress ips of subnet %s, allocate them from subnet %s or announce them by bgp", subnet, util.VpcExternalNet)
+}
+
+// lbIpamRouterLoadBalancer returns the load balancer attached to the router of the vpc for the protocol, which holds
+// the vips of the ingress ips allocated from the external subnet. OVN answers arp requests for these vips with the
+// mac of the distributed gateway port of the vpc on its gateway chassis, and announces them through the localnet
+// port of the external subnet
+func lbIpamRouterLoadBalancer(vpc, protocol string) string {
+	return fmt.Sprintf("vpc-%s-%s-lb-ipam", vpc, protocol)
+}
+
+// lbIpamExternalIPs returns the ingress ips of the service allocated from the external subnet, the distributed
+// gateway ports of the vpcs have ipv4 addresses only
+func (c *Controller) lbIpamExternalIPs(svc *v1.Service) []string {
+	if !isLbIpamService(svc) {
+		return nil
+	}
+	subnet, err := c.subnetsLister.Get(util.VpcExternalNet)
+	if err != nil {
+		return nil
+	}
+	var ips []string
+	for _, ip := range lbIpamIPs(svc) {
+		if util.CheckProtocol(ip) == kubeovnv1.ProtocolIPv4 && util.SubnetContainIP(subnet, ip) {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
+// getExternalGatewayNodes returns the names of the nodes labeled as external gateway, the external traffic to the
+// ingress ips of the external subnet enters the vpcs through the distributed gateway ports on them
+func (c *Controller) getExternalGatewayNodes() ([]string, error) {
+	nodes, err := c.nodesLister.List(labels.SelectorFromSet(labels.Set{util.ExGatewayLabel: "true"}))
+	if err != nil {
+		klog.Errorf("failed to list external gateway nodes, %v", err)
+		return nil, err
+	}
+	names := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	return names, nil
+}
+
+// syncLbIpamRouterVip adds the vip with the backends to the router load balancer of the vpc,
+// or deletes it if there are no backends
+func (c *Controller) syncLbIpamRouterVip(vpc string, protocol v1.Protocol, vip, backends string) error {
+	lbProtocol := util.ProtocolTCP
+	if protocol != v1.ProtocolTCP {
+		lbProtocol = util.ProtocolUDP
+	}
+	lb := lbIpamRouterLoadBalancer(vpc, lbProtocol)
+	if len(backends) == 0 {
+		if err := c.ovnClient.LoadBalancerDeleteVip(lb, vip); err != nil {
+			klog.Errorf("failed to delete vip %s from lb %s, %v", vip, lb, err)
+			return err
+		}
+		return nil
+	}
+
+	externalIDs := map[string]string{"vendor": util.CniTypeName, "lb-ipam-vpc": vpc}
+	if err := c.ovnClient.CreateOrUpdateLoadBalancer(lb, lbProtocol, nil, nil, externalIDs); err != nil {
+		klog.Errorf("failed to create load balancer %s, %v", lb, err)
+		return err
+	}
+	if err := c.ovnClient.LogicalRouterUpdateLoadBalancers(vpc, ovsdb.MutateOperationInsert, lb); err != nil {
+		klog.Errorf("failed to add load balancer %s to logical router %s, %v", lb, vpc, err)
+		return err
+	}
+	if err := c.ovnClient.LoadBalancerAddVip(lb, vip, backends); err != nil {
+		klog.Errorf("failed to add vip %s to lb %s, %v", vip, lb, err)
+		return err
+	}
+	return nil
+}
+
+// getLbIpamAssignedIPs returns the ips of the subnet assigned to the ipam key in ipam
+func (c *Controller) getLbIpamAssignedIPs(key, subnet string) []string {
+	var ips []string
+	for _, address := range c.ipam.GetPodAddress(key) {
+		if address.Subnet.Name == subnet {
+			ips = append(ips, address.Ip)
+		}
+	}
+	return ips
+}
+
+// releaseLbIpamAddress releases the ips assigned to the ipam key and refreshes the status of their subnets
+func (c *Controller) releaseLbIpamAddress(key string) {
+	for _, address := range c.ipam.GetPodAddress(key) {
+		c.updateSubnetStatusQueue.Add(address.Subnet.Name)
+	}
+	c.ipam.ReleaseAddressByPod(key)
+}
+
+// lbIpamUsingIPs returns the count of loadbalancer services holding ingress ips of the subnet,
+// a dual-stack service is counted once as a dual-stack pod is
+func (c *Controller) lbIpamUsingIPs(subnet string) (float64, error) {
+	svcs, err := c.servicesLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list services, %v", err)
+		return 0, err
+	}
+	var count float64
+	for _, svc := range svcs {
+		if !isLbIpamService(svc) || len(lbIpamIPs(svc)) == 0 {
+			continue
+		}
+		if len(c.getLbIpamAssignedIPs(lbIpamKey(svc), subnet)) != 0 {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// serviceHasIPFamily returns whether the family of the ip is one of the service
+func serviceHasIPFamily(svc *v1.Service, ip string) bool {
+	if len(svc.Spec.IPFamilies) == 0 {
+		return true
+	}
+	family := v1.IPv4Protocol
+	if util.CheckProtocol(ip) == kubeovnv1.ProtocolIPv6 {
+		family = v1.IPv6Protocol
+	}
+	for _, f := range svc.Spec.IPFamilies {
+		if f == family {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueueDeleteLbIpamVips deletes the vips of the ingress ips which are no longer allocated to the service
+func (c *Controller) enqueueDeleteLbIpamVips(svc *v1.Service, ips []string) {
+	for _, ip := range ips {
+		for _, port := range svc.Spec.Ports {
+			vpcSvc := &vpcService{
+				Vip:      util.JoinHostPort(ip, port.Port),
+				Protocol: port.Protocol,
+				Vpc:      svc.Annotations[util.VpcAnnotation],
+				Svc:      svc,
+			}
+			klog.Infof("delete vpc service %v", vpcSvc)
+			c.deleteServiceQueue.Add(vpcSvc)
+		}
+	}
+}
+
+// patchLbIpam records the allocated ips in the annotation and the ingress of the service, nil ips clear them
+func (c *Controller) patchLbIpam(svc *v1.Service, ips []string) error {
+	var annotation interface{}
+	ingress := make([]v1.LoadBalancerIngress, 0, len(ips))
+	for _, ip := range ips {
+		ingress = append(ingress, v1.LoadBalancerIngress{IP: ip})
+	}
+	if len(ips) != 0 {
+		annotation = strings.Join(ips, ",")
+	}
+
+	if svc.Annotations[util.LbIpamIPAnnotation] != strings.Join(ips, ",") {
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{util.LbIpamIPAnnotation: annotation},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if _, err = c.config.KubeClient.CoreV1().Services(svc.Namespace).Patch(context.Background(), svc.Name,
+			types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			klog.Errorf("failed to patch annotations of service %s/%s, %v", svc.Namespace, svc.Name, err)
+			return err
+		}
+	}
+
+	if len(svc.Status.LoadBalancer.Ingress) == 0 && len(ingress) == 0 || reflect.DeepEqual(svc.Status.LoadBalancer.Ingress, ingress) {
+		return nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"loadBalancer": map[string]interface{}{"ingress": ingress},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err = c.config.KubeClient.CoreV1().Services(svc.Namespace).Patch(context.Background(), svc.Name,
+		types.MergePatchType, patch, metav1.PatchOptions{}, "status"); err != nil {
+		klog.Errorf("failed to patch status of service %s/%s, %v", svc.Namespace, svc.Name, err)
+		return err
+	}
+	return nil
+}
+
+// initLbIpam recovers the ingress ips allocated to the loadbalancer services
+func (c *Controller) initLbIpam(svcs []*v1.Service) {
+	for _, svc := range svcs {
+		ips := lbIpamIPs(svc)
+		if len(ips) == 0 || !isLbIpamService(svc) {
+			continue
+		}
+		subnet, _, err := c.getLbIpamSubnet(svc)
+		if err != nil {
+			klog.Errorf("failed to get loadbalancer ipam subnet of service %s/%s, %v", svc.Namespace, svc.Name, err)
+			continue
+		}
+		key := lbIpamKey(svc)
+		if _, _, _, err = c.ipam.GetStaticAddress(key, key, strings.Join(ips, ","), "", subnet, false); err != nil {
+			klog.Errorf("failed to init IPAM from loadbalancer ips %v of service %s/%s: %v", ips, svc.Namespace, svc.Name, err)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func Test_handleLbIpam(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "default",
+			Annotations: map[string]string{util.VpcAnnotation: util.DefaultVpc},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:       corev1.ServiceTypeLoadBalancer,
+			ClusterIP:  "10.96.0.10",
+			ClusterIPs: []string{"10.96.0.10"},
+			IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+			Ports:      []corev1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}},
+		},
+	}
+	vpc := &kubeovnv1.Vpc{
+		ObjectMeta: metav1.ObjectMeta{Name: util.DefaultVpc},
+		Status: kubeovnv1.VpcStatus{
+			TcpLoadBalancer: "cluster-tcp-loadbalancer",
+			UdpLoadBalancer: "cluster-udp-loadbalancer",
+		},
+	}
+	subnet := &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "external"},
+		Spec: kubeovnv1.SubnetSpec{
+			CIDRBlock:  "192.168.100.0/29",
+			Gateway:    "192.168.100.1",
+			ExcludeIps: []string{"192.168.100.1"},
+			Protocol:   kubeovnv1.ProtocolIPv4,
+		},
+	}
+	slice := newEndpointSlice("web-1", discoveryv1.AddressTypeIPv4, 8080, newEndpoint("10.16.0.2", true, true, false))
+	ctrl := newFakeController(t, []runtime.Object{svc, slice}, []runtime.Object{vpc, subnet})
+	ctrl.config.LbIpamSubnet = "external"
+	require.NoError(t, ctrl.ipam.AddOrUpdateSubnet("external", "192.168.100.0/29", "192.168.100.1", []string{"192.168.100.1"}))
+	require.NoError(t, ctrl.ovnClient.CreateLoadBalancer(vpc.Status.TcpLoadBalancer, "tcp", ""))
+	require.NoError(t, ctrl.ovnClient.CreateLoadBalancer(vpc.Status.UdpLoadBalancer, "udp", ""))
+
+	getService := func() *corev1.Service {
+		svc, err := ctrl.kubeClient.CoreV1().Services("default").Get(context.Background(), "web", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.NoError(t, ctrl.informerFactory.Core().V1().Services().Informer().GetIndexer().Update(svc))
+		return svc
+	}
+
+	// the ingress ip is allocated from the subnet
+	require.NoError(t, ctrl.handleLbIpam(svc))
+	svc = getService()
+	ip := svc.Annotations[util.LbIpamIPAnnotation]
+	require.Equal(t, []string{ip}, ctrl.getLbIpamAssignedIPs(lbIpamKey(svc), "external"))
+	require.Equal(t, []corev1.LoadBalancerIngress{{IP: ip}}, svc.Status.LoadBalancer.Ingress)
+	require.Equal(t, 1, ctrl.updateEndpointQueue.Len())
+
+	// the ingress ip is counted in the status of the subnet
+	require.Equal(t, 1, ctrl.updateSubnetStatusQueue.Len())
+	require.NoError(t, calcSubnetStatusIP(subnet, ctrl.Controller))
+	updated, err := ctrl.kubeovnClient.KubeovnV1().Subnets().Get(context.Background(), subnet.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, float64(1), updated.Status.V4UsingIPs)
+	require.Equal(t, float64(4), updated.Status.V4AvailableIPs)
+
+	// the vip of the ingress ip is added to the load balancer
+	require.NoError(t, ctrl.handleUpdateEndpoint("default/web"))
+	vips, err := ctrl.ovnClient.GetLoadBalancerVips(vpc.Status.TcpLoadBalancer)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"10.96.0.10:80": "10.16.0.2:8080", ip + ":80": "10.16.0.2:8080"}, vips)
+
+	// the allocated ip is kept
+	require.NoError(t, ctrl.handleLbIpam(svc))
+	require.Equal(t, ip, getService().Annotations[util.LbIpamIPAnnotation])
+
+	// the ip is recovered on restart
+	ctrl.ipam.ReleaseAddressByPod(lbIpamKey(svc))
+	ctrl.initLbIpam([]*corev1.Service{svc})
+	require.Equal(t, []string{ip}, ctrl.getLbIpamAssignedIPs(lbIpamKey(svc), "external"))
+
+	// the requested ip replaces the allocated one
+	svc = svc.DeepCopy()
+	svc.Spec.LoadBalancerIP = "192.168.100.5"
+	_, err = ctrl.kubeClient.CoreV1().Services("default").Update(context.Background(), svc, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, ctrl.handleLbIpam(svc))
+	svc = getService()
+	require.Equal(t, []string{"192.168.100.5"}, ctrl.getLbIpamAssignedIPs(lbIpamKey(svc), "external"))
+	require.Equal(t, "192.168.100.5", svc.Annotations[util.LbIpamIPAnnotation])
+	require.Equal(t, []corev1.LoadBalancerIngress{{IP: "192.168.100.5"}}, svc.Status.LoadBalancer.Ingress)
+	if ip != "192.168.100.5" {
+		require.Equal(t, 1, ctrl.deleteServiceQueue.Len())
+	}
+
+	// the ip is released once the service is no longer a loadbalancer one
+	svc = svc.DeepCopy()
+	svc.Spec.Type = corev1.ServiceTypeClusterIP
+	svc.Spec.LoadBalancerIP = ""
+	_, err = ctrl.kubeClient.CoreV1().Services("default").Update(context.Background(), svc, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, ctrl.handleLbIpam(svc))
+	svc = getService()
+	require.NotContains(t, svc.Annotations, util.LbIpamIPAnnotation)
+	require.Empty(t, svc.Status.LoadBalancer.Ingress)
+	require.Empty(t, ctrl.getLbIpamAssignedIPs(lbIpamKey(svc), "external"))
+	using, err := ctrl.lbIpamUsingIPs("external")
+	require.NoError(t, err)
+	require.Zero(t, using)
+}
+
+func Test_getLbIpamSubnet(t *testing.T) {
+	pool := &kubeovnv1.IPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool1"},
+		Spec:       kubeovnv1.IPPoolSpec{Subnet: "external"},
+	}
+	ctrl := newFakeController(t, nil, []runtime.Object{pool})
+	ctrl.config.LbIpamSubnet = "ovn-default"
+
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	subnet, ippool, err := ctrl.getLbIpamSubnet(svc)
+	require.NoError(t, err)
+	require.Equal(t, "ovn-default", subnet)
+	require.Empty(t, ippool)
+
+	// the ippool of the annotation takes precedence over the default subnet
+	svc.Annotations = map[string]string{util.LbIpamIPPoolAnnotation: "pool1"}
+	subnet, ippool, err = ctrl.getLbIpamSubnet(svc)
+	require.NoError(t, err)
+	require.Equal(t, "external", subnet)
+	require.Equal(t, "pool1", ippool)
+
+	svc.Annotations[util.LbIpamSubnetAnnotation] = "ovn-default"
+	_, _, err = ctrl.getLbIpamSubnet(svc)
+	require.Error(t, err)
+}
+
+func Test_syncLbIpamRouterVip(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: "default",
+			Annotations: map[string]string{
+				util.VpcAnnotation:      "test-vpc",
+				util.LbIpamIPAnnotation: "172.18.0.10,fd00::10",
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:       corev1.ServiceTypeLoadBalancer,
+			ClusterIP:  "10.96.0.10",
+			ClusterIPs: []string{"10.96.0.10"},
+			Ports:      []corev1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}},
+		},
+	}
+	vpc := &kubeovnv1.Vpc{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpc"},
+		Spec:       kubeovnv1.VpcSpec{EnableExternal: true},
+		Status: kubeovnv1.VpcStatus{
+			TcpLoadBalancer: "vpc-test-vpc-tcp-load",
+			UdpLoadBalancer: "vpc-test-vpc-udp-load",
+		},
+	}
+	external := &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: util.VpcExternalNet},
+		Spec:       kubeovnv1.SubnetSpec{CIDRBlock: "172.18.0.0/24,fd00::/120", Vlan: "vlan1"},
+	}
+	gatewayNode, node := "node1", "node2"
+	endpoint1, endpoint2 := newEndpoint("10.16.0.2", true, true, false), newEndpoint("10.16.0.3", true, true, false)
+	endpoint1.NodeName, endpoint2.NodeName = &gatewayNode, &node
+	slice := newEndpointSlice("web-1", discoveryv1.AddressTypeIPv4, 8080, endpoint1, endpoint2)
+	nodes := []runtime.Object{
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: gatewayNode, Labels: map[string]string{util.ExGatewayLabel: "true"}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: node}},
+	}
+	ctrl := newFakeController(t, append([]runtime.Object{svc, slice}, nodes...), []runtime.Object{vpc, external})
+	require.NoError(t, ctrl.legacyClient.CreateLogicalRouter(vpc.Name))
+	require.NoError(t, ctrl.ovnClient.CreateLoadBalancer(vpc.Status.TcpLoadBalancer, "tcp", ""))
+	require.NoError(t, ctrl.ovnClient.CreateLoadBalancer(vpc.Status.UdpLoadBalancer, "udp", ""))
+
+	// only the ipv4 ingress ips of the external subnet are reachable through the gateway port
+	require.Equal(t, []string{"172.18.0.10"}, ctrl.lbIpamExternalIPs(svc))
+
+	// the vip is put on the load balancer of the router owning the gateway port
+	require.NoError(t, ctrl.handleUpdateEndpoint("default/web"))
+	lbName := lbIpamRouterLoadBalancer(vpc.Name, util.ProtocolTCP)
+	vips, err := ctrl.ovnClient.GetLoadBalancerVips(lbName)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"172.18.0.10:80": "10.16.0.2:8080,10.16.0.3:8080"}, vips)
+	lb, err := ctrl.ovnClient.GetLoadBalancer(lbName, false)
+	require.NoError(t, err)
+	lr, err := ctrl.ovnClient.GetLogicalRouter(vpc.Name, false)
+	require.NoError(t, err)
+	require.Contains(t, lr.LoadBalancer, lb.UUID)
+
+	// the external traffic is routed to the endpoints on the external gateway nodes it enters only
+	svc = svc.DeepCopy()
+	svc.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeLocal
+	require.NoError(t, ctrl.informerFactory.Core().V1().Services().Informer().GetIndexer().Update(svc))
+	require.NoError(t, ctrl.handleUpdateEndpoint("default/web"))
+	vips, err = ctrl.ovnClient.GetLoadBalancerVips(lbName)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"172.18.0.10:80": "10.16.0.2:8080"}, vips)
+	vips, err = ctrl.ovnClient.GetLoadBalancerVips(vpc.Status.TcpLoadBalancer)
+	require.NoError(t, err)
+	require.Equal(t, "10.16.0.2:8080,10.16.0.3:8080", vips["172.18.0.10:80"])
+
+	// the vip is deleted together with the ones of the switches
+	require.NoError(t, ctrl.handleDeleteService(&vpcService{Vip: "172.18.0.10:80", Protocol: corev1.ProtocolTCP, Vpc: vpc.Name, Svc: svc}))
+	vips, err = ctrl.ovnClient.GetLoadBalancerVips(lbName)
+	require.NoError(t, err)
+	require.Empty(t, vips)
+}
@@ -2,12 +2,14 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"reflect"
 	"strings"
 	"time"
 
+	"github.com/ovn-org/libovsdb/ovsdb"
 	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -21,6 +23,7 @@ import (
 	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
 	"github.com/kubeovn/kube-ovn/pkg/ipam"
 	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
 	"github.com/kubeovn/kube-ovn/pkg/util"
 )
 
@@ -36,6 +39,9 @@ func (c *Controller) enqueueAddSubnet(obj interface{}) {
 	}
 	klog.V(3).Infof("enqueue add subnet %s", key)
 	c.addOrUpdateSubnetQueue.Add(key)
+	if obj.(*kubeovnv1.Subnet).Spec.Dscp != nil {
+		c.syncQoSPolicyQueue.Add(qosPolicySyncKey)
+	}
 }
 
 func (c *Controller) enqueueDeleteSubnet(obj interface{}) {
@@ -81,8 +87,15 @@ func (c *Controller) enqueueUpdateSubnet(old, new interface{}) {
 		return
 	}
 
+	// dscp of the subnet is applied to the ports together with the qos policies
+	if !reflect.DeepEqual(oldSubnet.Spec.Dscp, newSubnet.Spec.Dscp) {
+		klog.V(3).Infof("enqueue sync dscp of subnet %s", key)
+		c.syncQoSPolicyQueue.Add(qosPolicySyncKey)
+	}
+
 	if oldSubnet.Spec.Private != newSubnet.Spec.Private ||
 		oldSubnet.Spec.CIDRBlock != newSubnet.Spec.CIDRBlock ||
+		!reflect.DeepEqual(oldSubnet.Spec.SecondaryCIDRBlocks, newSubnet.Spec.SecondaryCIDRBlocks) ||
 		!reflect.DeepEqual(oldSubnet.Spec.AllowSubnets, newSubnet.Spec.AllowSubnets) ||
 		!reflect.DeepEqual(oldSubnet.Spec.Namespaces, newSubnet.Spec.Namespaces) ||
 		oldSubnet.Spec.GatewayType != newSubnet.Spec.GatewayType ||
@@ -367,6 +380,16 @@ func checkAndUpdateCIDR(subnet *kubeovnv1.Subnet) (bool, error) {
 		cidrBlocks = append(cidrBlocks, ipNet.String())
 	}
 	subnet.Spec.CIDRBlock = strings.Join(cidrBlocks, ",")
+	for i, cidr := range subnet.Spec.SecondaryCIDRBlocks {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return false, fmt.Errorf("subnet %s secondary cidr %s is invalid", subnet.Name, cidr)
+		}
+		if ipNet.String() != cidr {
+			subnet.Spec.SecondaryCIDRBlocks[i] = ipNet.String()
+			changed = true
+		}
+	}
 	return changed, nil
 }
 
@@ -398,6 +421,7 @@ func checkAndUpdateExcludeIps(subnet *kubeovnv1.Subnet) bool {
 	changed := false
 	var excludeIps []string
 	excludeIps = append(excludeIps, strings.Split(subnet.Spec.Gateway, ",")...)
+	excludeIps = append(excludeIps, util.SubnetSecondaryGateways(subnet)...)
 	if len(subnet.Spec.ExcludeIps) == 0 {
 		subnet.Spec.ExcludeIps = excludeIps
 		changed = true
@@ -437,6 +461,14 @@ func (c *Controller) handleSubnetFinalizer(subnet *kubeovnv1.Subnet) (bool, erro
 		usingIps = subnet.Status.V6UsingIPs
 	}
 
+	if !subnet.DeletionTimestamp.IsZero() {
+		// the source ip of the load balancer health checks is released with the subnet
+		if err := c.deleteHealthCheckVip(subnet.Name); err != nil {
+			klog.Errorf("failed to delete health check vip of subnet %s, %v", subnet.Name, err)
+			return false, err
+		}
+	}
+
 	if !subnet.DeletionTimestamp.IsZero() && usingIps == 0 {
 		subnet.Finalizers = util.RemoveString(subnet.Finalizers, util.ControllerName)
 		if _, err := c.config.KubeOvnClient.KubeovnV1().Subnets().Update(context.Background(), subnet, metav1.UpdateOptions{}); err != nil {
@@ -549,9 +581,13 @@ func (c *Controller) handleAddOrUpdateSubnet(key string) error {
 		return err
 	}
 
-	if err := c.ipam.AddOrUpdateSubnet(subnet.Name, subnet.Spec.CIDRBlock, subnet.Spec.Gateway, subnet.Spec.ExcludeIps); err != nil {
+	if err := c.ipam.AddOrUpdateSubnet(subnet.Name, strings.Join(util.SubnetCIDRBlocks(subnet), ","), subnet.Spec.Gateway, subnet.Spec.ExcludeIps); err != nil {
+		if errors.Is(err, ipam.ErrOutOfRange) {
+			c.patchSubnetStatus(subnet, "UpdateCIDRFailed", err.Error())
+		}
 		return err
 	}
+	c.enqueueIPPoolsOfSubnet(subnet.Name, false)
 
 	if !isOvnSubnet(subnet) {
 		return nil
@@ -576,8 +612,8 @@ func (c *Controller) handleAddOrUpdateSubnet(key string) error {
 			continue
 		}
 
-		if util.CIDROverlap(sub.Spec.CIDRBlock, subnet.Spec.CIDRBlock) {
-			err = fmt.Errorf("subnet %s cidr %s is conflict with subnet %s cidr %s", subnet.Name, subnet.Spec.CIDRBlock, sub.Name, sub.Spec.CIDRBlock)
+		if cidrs, subCIDRs := strings.Join(util.SubnetCIDRBlocks(subnet), ","), strings.Join(util.SubnetCIDRBlocks(sub), ","); util.CIDROverlap(subCIDRs, cidrs) {
+			err = fmt.Errorf("subnet %s cidr %s is conflict with subnet %s cidr %s", subnet.Name, cidrs, sub.Name, subCIDRs)
 			klog.Error(err)
 			c.patchSubnetStatus(subnet, "ValidateLogicalSwitchFailed", err.Error())
 			return err
@@ -600,11 +636,16 @@ func (c *Controller) handleAddOrUpdateSubnet(key string) error {
 		}
 		for _, node := range nodes {
 			for _, addr := range node.Status.Addresses {
-				if addr.Type == v1.NodeInternalIP && util.CIDRContainIP(subnet.Spec.CIDRBlock, addr.Address) {
-					err = fmt.Errorf("subnet %s cidr %s conflict with node %s address %s", subnet.Name, subnet.Spec.CIDRBlock, node.Name, addr.Address)
-					klog.Error(err)
-					c.patchSubnetStatus(subnet, "ValidateLogicalSwitchFailed", err.Error())
-					return err
+				if addr.Type != v1.NodeInternalIP {
+					continue
+				}
+				for _, cidrBlock := range util.SubnetCIDRBlocks(subnet) {
+					if util.CIDRContainIP(cidrBlock, addr.Address) {
+						err = fmt.Errorf("subnet %s cidr %s conflict with node %s address %s", subnet.Name, cidrBlock, node.Name, addr.Address)
+						klog.Error(err)
+						c.patchSubnetStatus(subnet, "ValidateLogicalSwitchFailed", err.Error())
+						return err
+					}
 				}
 			}
 		}
@@ -626,15 +667,9 @@ func (c *Controller) handleAddOrUpdateSubnet(key string) error {
 			return err
 		}
 
-		if needRouter {
-			if err := c.reconcileRouterPortBySubnet(vpc, subnet); err != nil {
-				klog.Errorf("failed to connect switch %s to router %s, %v", subnet.Name, vpc.Name, err)
-				return err
-			}
-		}
 	} else {
 		// logical switch exists, only update other_config
-		if err := c.ovnLegacyClient.SetLogicalSwitchConfig(subnet.Name, vpc.Status.Router, subnet.Spec.Protocol, subnet.Spec.CIDRBlock, subnet.Spec.Gateway, subnet.Spec.ExcludeIps, needRouter); err != nil {
+		if err := c.ovnLegacyClient.SetLogicalSwitchConfig(subnet.Name); err != nil {
 			c.patchSubnetStatus(subnet, "SetLogicalSwitchConfigFailed", err.Error())
 			return err
 		}
@@ -645,9 +680,22 @@ func (c *Controller) handleAddOrUpdateSubnet(key string) error {
 			}
 		}
 	}
+	if needRouter {
+		if err := c.reconcileRouterPortBySubnet(vpc, subnet); err != nil {
+			klog.Errorf("failed to connect switch %s to router %s, %v", subnet.Name, vpc.Name, err)
+			return err
+		}
+		// the router port holds a gateway in each of the cidr blocks, follow changes of any of them
+		lrpName := ovs.LogicalRouterPortName(vpc.Status.Router, subnet.Name)
+		if err := c.ovnClient.SetLogicalRouterPortNetworks(lrpName, util.GetSubnetRouterNetworks(subnet)); err != nil {
+			klog.Errorf("failed to set networks of router port %s, %v", lrpName, err)
+			c.patchSubnetStatus(subnet, "SetLogicalSwitchConfigFailed", err.Error())
+			return err
+		}
+	}
 
 	var dhcpOptionsUUIDs *ovs.DHCPOptionsUUIDs
-	dhcpOptionsUUIDs, err = c.ovnLegacyClient.UpdateDHCPOptions(subnet.Name, subnet.Spec.CIDRBlock, subnet.Spec.Gateway, subnet.Spec.DHCPv4Options, subnet.Spec.DHCPv6Options, subnet.Spec.EnableDHCP)
+	dhcpOptionsUUIDs, err = c.ovnClient.UpdateDHCPOptions(subnet.Name, subnet.Spec.CIDRBlock, subnet.Spec.Gateway, subnet.Spec.DHCPv4Options, subnet.Spec.DHCPv6Options, subnet.Spec.EnableDHCP)
 	if err != nil {
 		klog.Errorf("failed to update dhcp options for switch %s, %v", subnet.Name, err)
 		return err
@@ -676,7 +724,15 @@ func (c *Controller) handleAddOrUpdateSubnet(key string) error {
 	}
 
 	if c.config.EnableLb && subnet.Name != c.config.NodeSwitch {
-		if err := c.ovnLegacyClient.AddLbToLogicalSwitch(vpc.Status.TcpLoadBalancer, vpc.Status.TcpSessionLoadBalancer, vpc.Status.UdpLoadBalancer, vpc.Status.UdpSessionLoadBalancer, subnet.Name); err != nil {
+		lbs := []string{vpc.Status.TcpLoadBalancer, vpc.Status.TcpSessionLoadBalancer, vpc.Status.UdpLoadBalancer, vpc.Status.UdpSessionLoadBalancer}
+		onDemandLbs, err := c.getOnDemandLoadBalancers(vpc.Name)
+		if err != nil {
+			return err
+		}
+		for _, lb := range onDemandLbs {
+			lbs = append(lbs, lb.Name)
+		}
+		if err := c.ovnClient.LogicalSwitchUpdateLoadBalancers(subnet.Name, ovsdb.MutateOperationInsert, lbs...); err != nil {
 			c.patchSubnetStatus(subnet, "AddLbToLogicalSwitchFailed", err.Error())
 			return err
 		}
@@ -688,20 +744,20 @@ func (c *Controller) handleAddOrUpdateSubnet(key string) error {
 	}
 
 	if subnet.Spec.Private {
-		if err := c.ovnLegacyClient.SetPrivateLogicalSwitch(subnet.Name, subnet.Spec.CIDRBlock, subnet.Spec.AllowSubnets); err != nil {
+		if err := c.ovnClient.SetLogicalSwitchPrivate(subnet.Name, strings.Join(util.SubnetCIDRBlocks(subnet), ","), c.config.NodeSwitchCIDR, subnet.Spec.AllowSubnets); err != nil {
 			c.patchSubnetStatus(subnet, "SetPrivateLogicalSwitchFailed", err.Error())
 			return err
 		}
 		c.patchSubnetStatus(subnet, "SetPrivateLogicalSwitchSuccess", "")
 	} else {
-		if err := c.ovnLegacyClient.ResetLogicalSwitchAcl(subnet.Name); err != nil {
+		if err := c.ovnClient.DeleteLogicalSwitchACLs(subnet.Name); err != nil {
 			c.patchSubnetStatus(subnet, "ResetLogicalSwitchAclFailed", err.Error())
 			return err
 		}
 		c.patchSubnetStatus(subnet, "ResetLogicalSwitchAclSuccess", "")
 	}
 
-	if err := c.ovnLegacyClient.UpdateSubnetACL(subnet.Name, subnet.Spec.Acls); err != nil {
+	if err := c.ovnClient.UpdateSubnetACL(subnet.Name, subnet.Spec.Acls); err != nil {
 		c.patchSubnetStatus(subnet, "SetLogicalSwitchAclsFailed", err.Error())
 		return err
 	}
@@ -722,6 +778,7 @@ func (c *Controller) handleUpdateSubnetStatus(key string) error {
 		}
 		return err
 	}
+	c.enqueueIPPoolsOfSubnet(key, true)
 	if util.CheckProtocol(subnet.Spec.CIDRBlock) == kubeovnv1.ProtocolDual {
 		return calcDualSubnetStatusIP(subnet, c)
 	} else {
@@ -752,12 +809,12 @@ func (c *Controller) handleDeleteLogicalSwitch(key string) (err error) {
 		return nil
 	}
 
-	if err = c.ovnLegacyClient.CleanLogicalSwitchAcl(key); err != nil {
+	if err = c.ovnClient.DeleteLogicalSwitchACLs(key); err != nil {
 		klog.Errorf("failed to delete acl of logical switch %s %v", key, err)
 		return err
 	}
 
-	if err = c.ovnLegacyClient.DeleteDHCPOptions(key, kubeovnv1.ProtocolDual); err != nil {
+	if err = c.ovnClient.DeleteDHCPOptions(key, kubeovnv1.ProtocolDual); err != nil {
 		klog.Errorf("failed to delete dhcp options of logical switch %s %v", key, err)
 		return err
 	}
@@ -875,7 +932,9 @@ func (c *Controller) reconcileSubnet(subnet *kubeovnv1.Subnet) error {
 
 func (c *Controller) reconcileVips(subnet *kubeovnv1.Subnet) error {
 	// 1. get all vip port
-	results, err := c.ovnLegacyClient.CustomFindEntity("logical_switch_port", []string{"name", "options"}, "type=virtual", fmt.Sprintf("external_ids:ls=%s", subnet.Name))
+	results, err := c.ovnClient.ListLogicalSwitchPortsWithFilter(func(lsp *ovnnb.LogicalSwitchPort) bool {
+		return lsp.Type == "virtual" && lsp.ExternalIDs["ls"] == subnet.Name
+	})
 	if err != nil {
 		klog.Errorf("failed to find virtual port, %v", err)
 		return err
@@ -884,23 +943,17 @@ func (c *Controller) reconcileVips(subnet *kubeovnv1.Subnet) error {
 	// 2. remove no need port
 	var existVips []string
 	for _, ret := range results {
-		options := ret["options"]
-		for _, value := range options {
-			if !strings.HasPrefix(value, "virtual-ip=") {
-				continue
-			}
-			vip := strings.TrimPrefix(value, "virtual-ip=")
-			if vip == "" || net.ParseIP(vip) == nil {
-				continue
-			}
-			if !util.ContainsString(subnet.Spec.Vips, vip) {
-				if err = c.ovnLegacyClient.DeleteLogicalSwitchPort(ret["name"][0]); err != nil {
-					klog.Errorf("failed to delete virtual port, %v", err)
-					return err
-				}
-			} else {
-				existVips = append(existVips, vip)
+		vip := ret.Options["virtual-ip"]
+		if vip == "" || net.ParseIP(vip) == nil {
+			continue
+		}
+		if !util.ContainsString(subnet.Spec.Vips, vip) {
+			if err = c.ovnClient.DeleteLogicalSwitchPort(ret.Name); err != nil {
+				klog.Errorf("failed to delete virtual port, %v", err)
+				return err
 			}
+		} else {
+			existVips = append(existVips, vip)
 		}
 	}
 
@@ -930,8 +983,9 @@ func (c *Controller) syncVirtualPort(key string) error {
 		return nil
 	}
 
-	results, err := c.ovnLegacyClient.CustomFindEntity("logical_switch_port", []string{"name", "external_ids"},
-		fmt.Sprintf("external_ids:ls=%s", subnet.Name), "external_ids:attach-vips=true")
+	results, err := c.ovnClient.ListLogicalSwitchPortsWithFilter(func(lsp *ovnnb.LogicalSwitchPort) bool {
+		return lsp.ExternalIDs["ls"] == subnet.Name && lsp.ExternalIDs["attach-vips"] == "true"
+	})
 	if err != nil {
 		klog.Errorf("failed to list logical_switch_port, %v", err)
 		return err
@@ -939,15 +993,12 @@ func (c *Controller) syncVirtualPort(key string) error {
 	vipVirtualParentsMap := map[string][]string{}
 	for _, ret := range results {
 		var associatedVips []string
-		for _, value := range ret["external_ids"] {
-			if strings.HasPrefix(value, "vips") {
-				vips := strings.Split(value, "=")[1]
-				associatedVips = strings.Split(strings.ReplaceAll(vips, " ", ""), "/")
-			}
+		if vips := ret.ExternalIDs["vips"]; vips != "" {
+			associatedVips = strings.Split(strings.ReplaceAll(vips, " ", ""), "/")
 		}
 		klog.Infof("associatedVips %v", associatedVips)
 		for _, vip := range associatedVips {
-			vipVirtualParentsMap[vip] = append(vipVirtualParentsMap[vip], ret["name"][0])
+			vipVirtualParentsMap[vip] = append(vipVirtualParentsMap[vip], ret.Name)
 		}
 	}
 
@@ -998,6 +1049,11 @@ func (c *Controller) reconcileOvnRoute(subnet *kubeovnv1.Subnet) error {
 		return nil
 	}
 
+	if err := c.deleteStalePolicyRoutesForSubnet(subnet); err != nil {
+		klog.Errorf("failed to delete stale policy routes of subnet %s: %v", subnet.Name, err)
+		return err
+	}
+
 	if subnet.Name == c.config.NodeSwitch {
 		if err := c.addCommonRoutesForSubnet(subnet); err != nil {
 			klog.Error(err)
@@ -1026,7 +1082,7 @@ func (c *Controller) reconcileOvnRoute(subnet *kubeovnv1.Subnet) error {
 		}
 
 		if !subnet.Spec.LogicalGateway {
-			if err := c.ovnLegacyClient.DeleteLogicalSwitchPort(fmt.Sprintf("%s-%s", subnet.Name, c.config.ClusterRouter)); err != nil {
+			if err := c.ovnClient.DeleteLogicalSwitchPort(fmt.Sprintf("%s-%s", subnet.Name, c.config.ClusterRouter)); err != nil {
 				klog.Errorf("failed to delete lsp %s-%s, %v", subnet.Name, c.config.ClusterRouter, err)
 				return err
 			}
@@ -1126,7 +1182,7 @@ func (c *Controller) reconcileOvnRoute(subnet *kubeovnv1.Subnet) error {
 							continue
 						}
 
-						if err := c.ovnLegacyClient.AddStaticRoute(ovs.PolicySrcIP, pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, podNet.ProviderName)], nextHop, c.config.ClusterRouter, util.NormalRouteType); err != nil {
+						if err := c.ovnClient.AddStaticRoute(ovs.PolicySrcIP, pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, podNet.ProviderName)], nextHop, c.config.ClusterRouter, util.NormalRouteType); err != nil {
 							klog.Errorf("add static route failed, %v", err)
 							return err
 						}
@@ -1169,7 +1225,7 @@ func (c *Controller) reconcileOvnRoute(subnet *kubeovnv1.Subnet) error {
 					for port := range pgPorts {
 						newPgPorts = append(newPgPorts, port)
 					}
-					if err = c.ovnLegacyClient.SetPortsToPortGroup(pgName, newPgPorts); err != nil {
+					if err = c.ovnClient.PortGroupSetPorts(pgName, newPgPorts); err != nil {
 						c.ovnPgKeyMutex.Unlock(pgName)
 						klog.Errorf("failed to set ports to port group %v, %v", pgName, err)
 						return err
@@ -1300,7 +1356,7 @@ func (c *Controller) reconcileOvnRoute(subnet *kubeovnv1.Subnet) error {
 
 func (c *Controller) deleteStaticRoute(ip, router string) error {
 	for _, ipStr := range strings.Split(ip, ",") {
-		if err := c.ovnLegacyClient.DeleteStaticRoute(ipStr, router); err != nil {
+		if err := c.ovnClient.DeleteStaticRoute(ipStr, router); err != nil {
 			klog.Errorf("failed to delete static route %s, %v", ipStr, err)
 			return err
 		}
@@ -1352,16 +1408,8 @@ func calcDualSubnetStatusIP(subnet *kubeovnv1.Subnet, c *Controller) error {
 		return err
 	}
 
-	// subnet.Spec.ExcludeIps contains both v4 and v6 addresses
-	v4ExcludeIps, v6ExcludeIps := util.SplitIpsByProtocol(subnet.Spec.ExcludeIps)
 	// gateway always in excludeIPs
-	cidrBlocks := strings.Split(subnet.Spec.CIDRBlock, ",")
-	v4toSubIPs := util.ExpandExcludeIPs(v4ExcludeIps, cidrBlocks[0])
-	v6toSubIPs := util.ExpandExcludeIPs(v6ExcludeIps, cidrBlocks[1])
-	_, v4CIDR, _ := net.ParseCIDR(cidrBlocks[0])
-	_, v6CIDR, _ := net.ParseCIDR(cidrBlocks[1])
-	v4availableIPs := util.AddressCount(v4CIDR) - util.CountIpNums(v4toSubIPs)
-	v6availableIPs := util.AddressCount(v6CIDR) - util.CountIpNums(v6toSubIPs)
+	v4availableIPs, v6availableIPs := subnetAddressCount(util.SubnetCIDRBlocks(subnet), subnet.Spec.ExcludeIps)
 
 	usingIPs := float64(len(podUsedIPs.Items))
 
@@ -1374,6 +1422,11 @@ func calcDualSubnetStatusIP(subnet *kubeovnv1.Subnet, c *Controller) error {
 		return err
 	}
 	usingIPs += float64(len(vips.Items))
+	lbIPs, err := c.lbIpamUsingIPs(subnet.Name)
+	if err != nil {
+		return err
+	}
+	usingIPs += lbIPs
 
 	if subnet.Name == util.VpcExternalNet {
 		eips, err := c.config.KubeOvnClient.KubeovnV1().IptablesEIPs().List(context.Background(), metav1.ListOptions{
@@ -1383,6 +1436,13 @@ func calcDualSubnetStatusIP(subnet *kubeovnv1.Subnet, c *Controller) error {
 			return err
 		}
 		usingIPs += float64(len(eips.Items))
+		ovnEips, err := c.config.KubeOvnClient.KubeovnV1().OvnEips().List(context.Background(), metav1.ListOptions{
+			LabelSelector: fields.OneTermEqualSelector(util.SubnetNameLabel, subnet.Name).String(),
+		})
+		if err != nil {
+			return err
+		}
+		usingIPs += float64(len(ovnEips.Items))
 	}
 	v4availableIPs = v4availableIPs - usingIPs
 	if v4availableIPs < 0 {
@@ -1393,10 +1453,12 @@ func calcDualSubnetStatusIP(subnet *kubeovnv1.Subnet, c *Controller) error {
 		v6availableIPs = 0
 	}
 
+	cidrUsage := c.subnetCIDRUsage(subnet)
 	if subnet.Status.V4AvailableIPs == v4availableIPs &&
 		subnet.Status.V6AvailableIPs == v6availableIPs &&
 		subnet.Status.V4UsingIPs == usingIPs &&
-		subnet.Status.V6UsingIPs == usingIPs {
+		subnet.Status.V6UsingIPs == usingIPs &&
+		reflect.DeepEqual(subnet.Status.CIDRUsage, cidrUsage) {
 		return nil
 	}
 
@@ -1404,6 +1466,7 @@ func calcDualSubnetStatusIP(subnet *kubeovnv1.Subnet, c *Controller) error {
 	subnet.Status.V6AvailableIPs = v6availableIPs
 	subnet.Status.V4UsingIPs = usingIPs
 	subnet.Status.V6UsingIPs = usingIPs
+	subnet.Status.CIDRUsage = cidrUsage
 	bytes, err := subnet.Status.Bytes()
 	if err != nil {
 		return err
@@ -1412,6 +1475,39 @@ func calcDualSubnetStatusIP(subnet *kubeovnv1.Subnet, c *Controller) error {
 	return err
 }
 
+// subnetAddressCount returns the count of ipv4 and ipv6 addresses in the cidr blocks except the excluded ones
+func subnetAddressCount(cidrBlocks, excludeIps []string) (float64, float64) {
+	// subnet.Spec.ExcludeIps contains both v4 and v6 addresses
+	v4ExcludeIps, v6ExcludeIps := util.SplitIpsByProtocol(util.ExpandExcludeIPs(excludeIps, strings.Join(cidrBlocks, ",")))
+	var v4Count, v6Count float64
+	for _, cidrBlock := range cidrBlocks {
+		_, cidr, err := net.ParseCIDR(cidrBlock)
+		if err != nil {
+			continue
+		}
+		if cidr.IP.To4() != nil {
+			v4Count += util.AddressCount(cidr)
+		} else {
+			v6Count += util.AddressCount(cidr)
+		}
+	}
+	return v4Count - util.CountIpNums(v4ExcludeIps), v6Count - util.CountIpNums(v6ExcludeIps)
+}
+
+// subnetCIDRUsage returns the address usage of each cidr block of the subnet recorded by ipam,
+// it is empty until the subnet is added to ipam
+func (c *Controller) subnetCIDRUsage(subnet *kubeovnv1.Subnet) []kubeovnv1.SubnetCIDRUsage {
+	stats, err := c.ipam.CIDRStatistics(subnet.Name)
+	if err != nil {
+		return nil
+	}
+	usage := make([]kubeovnv1.SubnetCIDRUsage, 0, len(stats))
+	for _, s := range stats {
+		usage = append(usage, kubeovnv1.SubnetCIDRUsage{CIDR: s.CIDR, AvailableIPs: s.Available, UsingIPs: s.Using})
+	}
+	return usage
+}
+
 func calcSubnetStatusIP(subnet *kubeovnv1.Subnet, c *Controller) error {
 	_, cidr, err := net.ParseCIDR(subnet.Spec.CIDRBlock)
 	if err != nil {
@@ -1424,8 +1520,10 @@ func calcSubnetStatusIP(subnet *kubeovnv1.Subnet, c *Controller) error {
 		return err
 	}
 	// gateway always in excludeIPs
-	toSubIPs := util.ExpandExcludeIPs(subnet.Spec.ExcludeIps, subnet.Spec.CIDRBlock)
-	availableIPs := util.AddressCount(cidr) - util.CountIpNums(toSubIPs)
+	availableIPs, v6availableIPs := subnetAddressCount(util.SubnetCIDRBlocks(subnet), subnet.Spec.ExcludeIps)
+	if cidr.IP.To4() == nil {
+		availableIPs = v6availableIPs
+	}
 	usingIPs := float64(len(podUsedIPs.Items))
 	vipSelectors := fields.AndSelectors(fields.OneTermEqualSelector(util.SubnetNameLabel, subnet.Name),
 		fields.OneTermEqualSelector(util.IpReservedLabel, "")).String()
@@ -1436,6 +1534,11 @@ func calcSubnetStatusIP(subnet *kubeovnv1.Subnet, c *Controller) error {
 		return err
 	}
 	usingIPs += float64(len(vips.Items))
+	lbIPs, err := c.lbIpamUsingIPs(subnet.Name)
+	if err != nil {
+		return err
+	}
+	usingIPs += lbIPs
 	if subnet.Name == util.VpcExternalNet {
 		eips, err := c.config.KubeOvnClient.KubeovnV1().IptablesEIPs().List(context.Background(), metav1.ListOptions{
 			LabelSelector: fields.OneTermEqualSelector(util.SubnetNameLabel, subnet.Name).String(),
@@ -1444,6 +1547,13 @@ func calcSubnetStatusIP(subnet *kubeovnv1.Subnet, c *Controller) error {
 			return err
 		}
 		usingIPs += float64(len(eips.Items))
+		ovnEips, err := c.config.KubeOvnClient.KubeovnV1().OvnEips().List(context.Background(), metav1.ListOptions{
+			LabelSelector: fields.OneTermEqualSelector(util.SubnetNameLabel, subnet.Name).String(),
+		})
+		if err != nil {
+			return err
+		}
+		usingIPs += float64(len(ovnEips.Items))
 	}
 
 	availableIPs = availableIPs - usingIPs
@@ -1468,14 +1578,16 @@ func calcSubnetStatusIP(subnet *kubeovnv1.Subnet, c *Controller) error {
 		subnet.Status.V4AvailableIPs = 0
 		subnet.Status.V4UsingIPs = 0
 	}
+	cidrUsage := c.subnetCIDRUsage(subnet)
 	if cachedFields == [4]float64{
 		subnet.Status.V4AvailableIPs,
 		subnet.Status.V4UsingIPs,
 		subnet.Status.V6AvailableIPs,
 		subnet.Status.V6UsingIPs,
-	} {
+	} && reflect.DeepEqual(subnet.Status.CIDRUsage, cidrUsage) {
 		return nil
 	}
+	subnet.Status.CIDRUsage = cidrUsage
 
 	bytes, err := subnet.Status.Bytes()
 	if err != nil {
@@ -1607,7 +1719,7 @@ func (c *Controller) getPgPorts(idNameMap map[string]string, pgName string) (map
 }
 
 func (c *Controller) addCommonRoutesForSubnet(subnet *kubeovnv1.Subnet) error {
-	for _, cidr := range strings.Split(subnet.Spec.CIDRBlock, ",") {
+	for _, cidr := range util.SubnetCIDRBlocks(subnet) {
 		if cidr == "" {
 			continue
 		}
@@ -1630,13 +1742,13 @@ func (c *Controller) addCommonRoutesForSubnet(subnet *kubeovnv1.Subnet) error {
 			af = 6
 		}
 		match := fmt.Sprintf("ip%d.dst == %s", af, cidr)
-		exist, err := c.ovnLegacyClient.PolicyRouteExists(util.SubnetRouterPolicyPriority, match)
+		exist, err := c.ovnClient.PolicyRouteExists(c.config.ClusterRouter, util.SubnetRouterPolicyPriority, match)
 		if err != nil {
 			return err
 		}
 		if !exist {
 			externalIDs := map[string]string{"vendor": util.CniTypeName, "subnet": subnet.Name}
-			if err = c.ovnLegacyClient.AddPolicyRoute(c.config.ClusterRouter, util.SubnetRouterPolicyPriority, match, "allow", "", externalIDs); err != nil {
+			if err = c.ovnClient.AddPolicyRoute(c.config.ClusterRouter, util.SubnetRouterPolicyPriority, match, "allow", "", externalIDs); err != nil {
 				klog.Errorf("failed to add logical router policy for CIDR %s of subnet %s: %v", cidr, subnet.Name, err)
 				return err
 			}
@@ -1646,6 +1758,46 @@ func (c *Controller) addCommonRoutesForSubnet(subnet *kubeovnv1.Subnet) error {
 	return nil
 }
 
+// deleteStalePolicyRoutesForSubnet deletes the policy routes matching a cidr the subnet no longer has,
+// e.g. routes of the old cidr after the subnet is expanded
+func (c *Controller) deleteStalePolicyRoutesForSubnet(subnet *kubeovnv1.Subnet) error {
+	policies, err := c.ovnClient.GetLogicalRouterPoliciesByExtID("subnet", subnet.Name)
+	if err != nil {
+		klog.Errorf("failed to list policy routes of subnet %s: %v", subnet.Name, err)
+		return err
+	}
+
+	cidrs := util.SubnetCIDRBlocks(subnet)
+	var lr *ovnnb.LogicalRouter
+	for _, policy := range policies {
+		if policy.Priority != util.SubnetRouterPolicyPriority && policy.Priority != util.GatewayRouterPolicyPriority {
+			continue
+		}
+		// policy routes of cidr are in the form of "ip4.dst == 10.16.0.0/16",
+		// policy routes of distributed gateway match address sets and are skipped
+		fields := strings.Fields(policy.Match)
+		if len(fields) != 3 || fields[1] != "==" || !strings.Contains(fields[2], "/") {
+			continue
+		}
+		if util.ContainsString(cidrs, fields[2]) {
+			continue
+		}
+
+		if lr == nil {
+			if lr, err = c.ovnClient.GetLogicalRouter(c.config.ClusterRouter, false); err != nil {
+				klog.Errorf("failed to get logical router %s: %v", c.config.ClusterRouter, err)
+				return err
+			}
+		}
+		klog.Infof("delete stale policy route %q of subnet %s", policy.Match, subnet.Name)
+		if err = c.ovnClient.DeleteRouterPolicy(lr, policy.UUID); err != nil {
+			klog.Errorf("failed to delete policy route %q of subnet %s: %v", policy.Match, subnet.Name, err)
+			return err
+		}
+	}
+	return nil
+}
+
 func getOverlaySubnetsPortGroupName(subnetName, nodeName string) string {
 	return strings.Replace(fmt.Sprintf("%s.%s", subnetName, nodeName), "-", ".", -1)
 }
@@ -1659,7 +1811,7 @@ func (c *Controller) createPortGroupForDistributedSubnet(node *v1.Node, subnet *
 	}
 
 	pgName := getOverlaySubnetsPortGroupName(subnet.Name, node.Name)
-	if err := c.ovnLegacyClient.CreateNpPortGroup(pgName, subnet.Name, node.Name); err != nil {
+	if err := c.ovnClient.CreatePortGroup(pgName, map[string]string{"np": subnet.Name + "/" + node.Name}); err != nil {
 		klog.Errorf("failed to create port group for subnet %s and node %s, %v", subnet.Name, node.Name, err)
 		return err
 	}
@@ -1674,7 +1826,7 @@ func (c *Controller) updatePolicyRouteForCentralizedSubnet(subnetName, cidr stri
 	match := fmt.Sprintf("%s.src == %s", ipSuffix, cidr)
 
 	// there's no way to update policy route when activeGateway changed for subnet, so delete and readd policy route
-	if err := c.ovnLegacyClient.DeletePolicyRoute(c.config.ClusterRouter, util.GatewayRouterPolicyPriority, match); err != nil {
+	if err := c.ovnClient.DeletePolicyRoute(c.config.ClusterRouter, util.GatewayRouterPolicyPriority, match); err != nil {
 		klog.Errorf("failed to delete policy route for centralized subnet %s: %v", subnetName, err)
 		return err
 	}
@@ -1689,7 +1841,7 @@ func (c *Controller) updatePolicyRouteForCentralizedSubnet(subnetName, cidr stri
 	for node, ip := range nameIpMap {
 		externalIDs[node] = ip
 	}
-	if err := c.ovnLegacyClient.AddPolicyRoute(c.config.ClusterRouter, util.GatewayRouterPolicyPriority, match, "reroute", nextHopIp, externalIDs); err != nil {
+	if err := c.ovnClient.AddPolicyRoute(c.config.ClusterRouter, util.GatewayRouterPolicyPriority, match, "reroute", nextHopIp, externalIDs); err != nil {
 		klog.Errorf("failed to add policy route for centralized subnet %s: %v", subnetName, err)
 		return err
 	}
@@ -1699,7 +1851,7 @@ func (c *Controller) updatePolicyRouteForCentralizedSubnet(subnetName, cidr stri
 
 func (c *Controller) addPolicyRouteForCentralizedSubnet(subnet *kubeovnv1.Subnet, nodeName string, ipNameMap map[string]string, nodeIPs []string) error {
 	for _, nodeIP := range nodeIPs {
-		for _, cidrBlock := range strings.Split(subnet.Spec.CIDRBlock, ",") {
+		for _, cidrBlock := range util.SubnetCIDRBlocks(subnet) {
 			if util.CheckProtocol(cidrBlock) != util.CheckProtocol(nodeIP) {
 				continue
 			}
@@ -1731,13 +1883,13 @@ func (c *Controller) addPolicyRouteForCentralizedSubnet(subnet *kubeovnv1.Subnet
 }
 
 func (c *Controller) deletePolicyRouteForCentralizedSubnet(subnet *kubeovnv1.Subnet) error {
-	for _, cidr := range strings.Split(subnet.Spec.CIDRBlock, ",") {
+	for _, cidr := range util.SubnetCIDRBlocks(subnet) {
 		ipSuffix := "ip4"
 		if util.CheckProtocol(cidr) == kubeovnv1.ProtocolIPv6 {
 			ipSuffix = "ip6"
 		}
 		match := fmt.Sprintf("%s.src == %s", ipSuffix, cidr)
-		if err := c.ovnLegacyClient.DeletePolicyRoute(c.config.ClusterRouter, util.GatewayRouterPolicyPriority, match); err != nil {
+		if err := c.ovnClient.DeletePolicyRoute(c.config.ClusterRouter, util.GatewayRouterPolicyPriority, match); err != nil {
 			klog.Errorf("failed to delete policy route for centralized subnet %s: %v", subnet.Name, err)
 			return err
 		}
@@ -1765,7 +1917,7 @@ func (c *Controller) addPolicyRouteForDistributedSubnet(subnet *kubeovnv1.Subnet
 
 		pgAs := fmt.Sprintf("%s_%s", pgName, ipSuffix)
 		match := fmt.Sprintf("%s.src == $%s", ipSuffix, pgAs)
-		exist, err := c.ovnLegacyClient.PolicyRouteExists(util.GatewayRouterPolicyPriority, match)
+		exist, err := c.ovnClient.PolicyRouteExists(c.config.ClusterRouter, util.GatewayRouterPolicyPriority, match)
 		if err != nil {
 			return err
 		}
@@ -1778,7 +1930,7 @@ func (c *Controller) addPolicyRouteForDistributedSubnet(subnet *kubeovnv1.Subnet
 			"subnet": subnet.Name,
 			"node":   nodeName,
 		}
-		if err = c.ovnLegacyClient.AddPolicyRoute(c.config.ClusterRouter, util.GatewayRouterPolicyPriority, match, "reroute", nodeIP, externalIDs); err != nil {
+		if err = c.ovnClient.AddPolicyRoute(c.config.ClusterRouter, util.GatewayRouterPolicyPriority, match, "reroute", nodeIP, externalIDs); err != nil {
 			klog.Errorf("failed to add logical router policy for port-group address-set %s: %v", pgAs, err)
 			return err
 		}
@@ -1795,7 +1947,7 @@ func (c *Controller) deletePolicyRouteForDistributedSubnet(subnet *kubeovnv1.Sub
 		}
 		pgAs := fmt.Sprintf("%s_%s", pgName, ipSuffix)
 		match := fmt.Sprintf("%s.src == $%s", ipSuffix, pgAs)
-		if err := c.ovnLegacyClient.DeletePolicyRoute(c.config.ClusterRouter, util.GatewayRouterPolicyPriority, match); err != nil {
+		if err := c.ovnClient.DeletePolicyRoute(c.config.ClusterRouter, util.GatewayRouterPolicyPriority, match); err != nil {
 			klog.Errorf("failed to delete policy route for subnet %s: %v", subnet.Name, err)
 			return err
 		}
@@ -1808,7 +1960,7 @@ func (c *Controller) deletePolicyRouteByGatewayType(subnet *kubeovnv1.Subnet, ga
 		return nil
 	}
 
-	for _, cidr := range strings.Split(subnet.Spec.CIDRBlock, ",") {
+	for _, cidr := range util.SubnetCIDRBlocks(subnet) {
 		if cidr == "" || !isDelete {
 			continue
 		}
@@ -1819,7 +1971,7 @@ func (c *Controller) deletePolicyRouteByGatewayType(subnet *kubeovnv1.Subnet, ga
 		}
 		match := fmt.Sprintf("ip%d.dst == %s", af, cidr)
 		klog.Infof("delete policy route for subnet %s, match %s", subnet.Name, match)
-		if err := c.ovnLegacyClient.DeletePolicyRoute(c.config.ClusterRouter, util.SubnetRouterPolicyPriority, match); err != nil {
+		if err := c.ovnClient.DeletePolicyRoute(c.config.ClusterRouter, util.SubnetRouterPolicyPriority, match); err != nil {
 			klog.Errorf("failed to delete logical router policy for CIDR %s of subnet %s: %v", cidr, subnet.Name, err)
 			return err
 		}
@@ -0,0 +1,250 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ipam"
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func Test_handleAddOrUpdateSubnet(t *testing.T) {
+	vpc := &kubeovnv1.Vpc{
+		ObjectMeta: metav1.ObjectMeta{Name: util.DefaultVpc},
+		Status: kubeovnv1.VpcStatus{
+			Standby: true,
+			Default: true,
+			Router:  util.DefaultVpc,
+		},
+	}
+	subnet := &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-subnet"},
+		Spec: kubeovnv1.SubnetSpec{
+			Vpc:         util.DefaultVpc,
+			CIDRBlock:   "10.100.0.0/24",
+			Gateway:     "10.100.0.1",
+			ExcludeIps:  []string{"10.100.0.1"},
+			Protocol:    kubeovnv1.ProtocolIPv4,
+			Provider:    util.OvnProvider,
+			GatewayType: kubeovnv1.GWDistributedType,
+			EnableDHCP:  true,
+		},
+	}
+	ctrl := newFakeController(t, nil, []runtime.Object{vpc, subnet})
+	require.NoError(t, ctrl.legacyClient.CreateLogicalRouter(util.DefaultVpc))
+
+	require.NoError(t, ctrl.handleAddOrUpdateSubnet(subnet.Name))
+
+	ls, err := ctrl.ovnClient.GetLogicalSwitch(subnet.Name, false)
+	require.NoError(t, err)
+	require.Equal(t, util.CniTypeName, ls.ExternalIDs["vendor"])
+	lrp, err := ctrl.ovnClient.GetLogicalRouterPort(ovs.LogicalRouterPortName(util.DefaultVpc, subnet.Name), false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.100.0.1/24"}, lrp.Networks)
+	acls, err := ctrl.ovnClient.ListLogicalSwitchACLs(subnet.Name, nil)
+	require.NoError(t, err)
+	require.Empty(t, acls)
+	require.Equal(t, 1, ctrl.updateVpcStatusQueue.Len())
+	dhcpOptions, err := ctrl.ovnClient.ListDHCPOptions(true, subnet.Name, "")
+	require.NoError(t, err)
+	require.Len(t, dhcpOptions, 1)
+	require.Equal(t, "10.100.0.0/24", dhcpOptions[0].Cidr)
+	require.Equal(t, "10.100.0.1", dhcpOptions[0].Options["router"])
+	require.NotEmpty(t, dhcpOptions[0].Options["server_mac"])
+
+	updated, err := ctrl.kubeovnClient.KubeovnV1().Subnets().Get(context.Background(), subnet.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Contains(t, updated.Finalizers, util.ControllerName)
+	require.Equal(t, dhcpOptions[0].UUID, updated.Status.DHCPv4OptionsUUID)
+
+	// the logical switch is only updated once it exists
+	ctrl.legacyClient.Reset()
+	require.NoError(t, ctrl.handleAddOrUpdateSubnet(subnet.Name))
+	require.False(t, ctrl.legacyClient.Called("CreateLogicalSwitch"))
+	require.True(t, ctrl.legacyClient.Called("SetLogicalSwitchConfig"))
+	// the dhcp options are updated in place and keep the server mac
+	options, err := ctrl.ovnClient.ListDHCPOptions(true, subnet.Name, "")
+	require.NoError(t, err)
+	require.Equal(t, dhcpOptions, options)
+}
+
+func Test_handleAddOrUpdateSubnetError(t *testing.T) {
+	vpc := &kubeovnv1.Vpc{
+		ObjectMeta: metav1.ObjectMeta{Name: util.DefaultVpc},
+		Status:     kubeovnv1.VpcStatus{Standby: true, Default: true, Router: util.DefaultVpc},
+	}
+	subnet := &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-subnet"},
+		Spec: kubeovnv1.SubnetSpec{
+			Vpc:         util.DefaultVpc,
+			CIDRBlock:   "10.100.0.0/24",
+			Gateway:     "10.100.0.1",
+			ExcludeIps:  []string{"10.100.0.1"},
+			Protocol:    kubeovnv1.ProtocolIPv4,
+			Provider:    util.OvnProvider,
+			GatewayType: kubeovnv1.GWDistributedType,
+			EnableDHCP:  true,
+		},
+	}
+	ctrl := newFakeController(t, nil, []runtime.Object{vpc, subnet})
+	ctrl.legacyClient.Errors["CreateLogicalSwitch"] = errors.New("ovn-nbctl failed")
+
+	require.Error(t, ctrl.handleAddOrUpdateSubnet(subnet.Name))
+	exists, err := ctrl.ovnClient.LogicalSwitchExists(subnet.Name)
+	require.NoError(t, err)
+	require.False(t, exists)
+	dhcpOptions, err := ctrl.ovnClient.ListDHCPOptions(true, subnet.Name, "")
+	require.NoError(t, err)
+	require.Empty(t, dhcpOptions)
+}
+
+func Test_handleAddOrUpdateSubnetCIDRChange(t *testing.T) {
+	vpc := &kubeovnv1.Vpc{
+		ObjectMeta: metav1.ObjectMeta{Name: util.DefaultVpc},
+		Status:     kubeovnv1.VpcStatus{Standby: true, Default: true, Router: util.DefaultVpc},
+	}
+	subnet := &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-subnet"},
+		Spec: kubeovnv1.SubnetSpec{
+			Vpc:         util.DefaultVpc,
+			CIDRBlock:   "10.100.0.0/24",
+			Gateway:     "10.100.0.1",
+			ExcludeIps:  []string{"10.100.0.1"},
+			Protocol:    kubeovnv1.ProtocolIPv4,
+			Provider:    util.OvnProvider,
+			GatewayType: kubeovnv1.GWDistributedType,
+		},
+	}
+	ctrl := newFakeController(t, nil, []runtime.Object{vpc, subnet})
+	require.NoError(t, ctrl.legacyClient.CreateLogicalRouter(util.DefaultVpc))
+	require.NoError(t, ctrl.handleAddOrUpdateSubnet(subnet.Name))
+	_, _, _, err := ctrl.ipam.GetStaticAddress("pod1.ns", "pod1.ns", "10.100.0.200", "", subnet.Name, true)
+	require.NoError(t, err)
+
+	// the policy route of the old cidr
+	policies, err := ctrl.ovnClient.GetLogicalRouterPoliciesByExtID("subnet", subnet.Name)
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	require.Equal(t, "ip4.dst == 10.100.0.0/24", policies[0].Match)
+
+	updateSubnet := func(cidr string) {
+		cachedSubnet, err := ctrl.kubeovnClient.KubeovnV1().Subnets().Get(context.Background(), subnet.Name, metav1.GetOptions{})
+		require.NoError(t, err)
+		newSubnet := cachedSubnet.DeepCopy()
+		newSubnet.Spec.CIDRBlock = cidr
+		newSubnet, err = ctrl.kubeovnClient.KubeovnV1().Subnets().Update(context.Background(), newSubnet, metav1.UpdateOptions{})
+		require.NoError(t, err)
+		require.NoError(t, ctrl.kubeovnInformerFactory.Kubeovn().V1().Subnets().Informer().GetIndexer().Update(newSubnet))
+	}
+
+	// expand the cidr
+	ctrl.legacyClient.Reset()
+	updateSubnet("10.100.0.0/23")
+	require.NoError(t, ctrl.handleAddOrUpdateSubnet(subnet.Name))
+	require.Equal(t, "10.100.0.0/23", ctrl.ipam.Subnets[subnet.Name].V4CIDR.String())
+	addresses := ctrl.ipam.GetPodAddress("pod1.ns")
+	require.Len(t, addresses, 1)
+	require.Equal(t, "10.100.0.200", addresses[0].Ip)
+	require.True(t, ctrl.legacyClient.Called("SetLogicalSwitchConfig"))
+	lrp, err := ctrl.ovnClient.GetLogicalRouterPort(ovs.LogicalRouterPortName(util.DefaultVpc, subnet.Name), false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.100.0.1/23"}, lrp.Networks)
+	policies, err = ctrl.ovnClient.GetLogicalRouterPoliciesByExtID("subnet", subnet.Name)
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	require.Equal(t, "ip4.dst == 10.100.0.0/23", policies[0].Match)
+
+	// shrink the cidr with an allocated address out of it
+	updateSubnet("10.100.0.0/25")
+	require.ErrorIs(t, ctrl.handleAddOrUpdateSubnet(subnet.Name), ipam.ErrOutOfRange)
+	require.Equal(t, "10.100.0.0/23", ctrl.ipam.Subnets[subnet.Name].V4CIDR.String())
+	updated, err := ctrl.kubeovnClient.KubeovnV1().Subnets().Get(context.Background(), subnet.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	cond := updated.Status.GetCondition(kubeovnv1.Validated)
+	require.NotNil(t, cond)
+	require.Equal(t, "UpdateCIDRFailed", cond.Reason)
+}
+
+func Test_handleAddOrUpdateSubnetSecondaryCIDR(t *testing.T) {
+	vpc := &kubeovnv1.Vpc{
+		ObjectMeta: metav1.ObjectMeta{Name: util.DefaultVpc},
+		Status:     kubeovnv1.VpcStatus{Standby: true, Default: true, Router: util.DefaultVpc},
+	}
+	subnet := &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-subnet"},
+		Spec: kubeovnv1.SubnetSpec{
+			Vpc:                 util.DefaultVpc,
+			CIDRBlock:           "10.100.0.0/24",
+			SecondaryCIDRBlocks: []string{"10.101.0.0/24"},
+			Gateway:             "10.100.0.1",
+			ExcludeIps:          []string{"10.100.0.1"},
+			Protocol:            kubeovnv1.ProtocolIPv4,
+			Provider:            util.OvnProvider,
+			GatewayType:         kubeovnv1.GWDistributedType,
+			Private:             true,
+		},
+	}
+	ctrl := newFakeController(t, nil, []runtime.Object{vpc, subnet})
+	require.NoError(t, ctrl.legacyClient.CreateLogicalRouter(util.DefaultVpc))
+
+	require.NoError(t, ctrl.handleAddOrUpdateSubnet(subnet.Name))
+
+	updated, err := ctrl.kubeovnClient.KubeovnV1().Subnets().Get(context.Background(), subnet.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Contains(t, updated.Spec.ExcludeIps, "10.101.0.1")
+	ipamSubnet := ctrl.ipam.Subnets[subnet.Name]
+	require.Equal(t, "10.100.0.0/24", ipamSubnet.V4CIDR.String())
+	require.Len(t, ipamSubnet.V4SecondaryCIDRs, 1)
+	require.Equal(t, "10.101.0.0/24", ipamSubnet.V4SecondaryCIDRs[0].String())
+
+	lrpName := ovs.LogicalRouterPortName(util.DefaultVpc, subnet.Name)
+	lrp, err := ctrl.ovnClient.GetLogicalRouterPort(lrpName, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.100.0.1/24", "10.101.0.1/24"}, lrp.Networks)
+
+	for _, match := range []string{"ip4.dst == 10.100.0.0/24", "ip4.dst == 10.101.0.0/24"} {
+		exist, err := ctrl.ovnClient.PolicyRouteExists(util.DefaultVpc, util.SubnetRouterPolicyPriority, match)
+		require.NoError(t, err)
+		require.True(t, exist, match)
+	}
+	// the acls of a private subnet cover all the cidr blocks
+	acls, err := ctrl.ovnClient.ListLogicalSwitchACLs(subnet.Name, nil)
+	require.NoError(t, err)
+	var matches []string
+	for _, acl := range acls {
+		matches = append(matches, acl.Match)
+	}
+	require.Contains(t, matches, "ip")
+	require.Contains(t, matches, "ip4.src==10.100.0.0/24 && ip4.dst==10.100.0.0/24")
+	require.Contains(t, matches, "ip4.src==10.101.0.0/24 && ip4.dst==10.101.0.0/24")
+
+	// the networks of the existing router port follow a new secondary cidr block
+	newSubnet := updated.DeepCopy()
+	newSubnet.Spec.SecondaryCIDRBlocks = append(newSubnet.Spec.SecondaryCIDRBlocks, "10.102.0.0/24")
+	newSubnet, err = ctrl.kubeovnClient.KubeovnV1().Subnets().Update(context.Background(), newSubnet, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, ctrl.kubeovnInformerFactory.Kubeovn().V1().Subnets().Informer().GetIndexer().Update(newSubnet))
+	require.NoError(t, ctrl.handleAddOrUpdateSubnet(subnet.Name))
+	lrp, err = ctrl.ovnClient.GetLogicalRouterPort(lrpName, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.100.0.1/24", "10.101.0.1/24", "10.102.0.1/24"}, lrp.Networks)
+	require.Len(t, ctrl.ipam.Subnets[subnet.Name].V4SecondaryCIDRs, 2)
+
+	// and drop the gateway of a removed one
+	newSubnet = newSubnet.DeepCopy()
+	newSubnet.Spec.SecondaryCIDRBlocks = []string{"10.102.0.0/24"}
+	newSubnet, err = ctrl.kubeovnClient.KubeovnV1().Subnets().Update(context.Background(), newSubnet, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, ctrl.kubeovnInformerFactory.Kubeovn().V1().Subnets().Informer().GetIndexer().Update(newSubnet))
+	require.NoError(t, ctrl.handleAddOrUpdateSubnet(subnet.Name))
+	lrp, err = ctrl.ovnClient.GetLogicalRouterPort(lrpName, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.100.0.1/24", "10.102.0.1/24"}, lrp.Networks)
+}
@@ -255,6 +255,19 @@ func genHeadlessService(slr *kubeovnv1.SwitchLBRule, oldSvc *corev1.Service) *co
 		resourceVersion = oldSvc.ResourceVersion
 	}
 	annotations[util.SwitchLBRuleVipsAnnotation] = slr.Spec.Vip
+	if healthCheck := genSlrHealthCheckAnnotation(slr.Spec.HealthCheck); healthCheck != "" {
+		annotations[util.ServiceHealthCheckAnnotation] = healthCheck
+	} else {
+		delete(annotations, util.ServiceHealthCheckAnnotation)
+	}
+	// the hash modes of the session affinity are not supported by services
+	sessionAffinity := corev1.ServiceAffinity(slr.Spec.SessionAffinity)
+	if slr.Spec.SessionAffinity == util.LbHash5Tuple {
+		sessionAffinity = corev1.ServiceAffinityNone
+		annotations[util.ServiceLbHashAnnotation] = util.LbHash5Tuple
+	} else {
+		delete(annotations, util.ServiceLbHashAnnotation)
+	}
 
 	svc := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -268,7 +281,7 @@ func genHeadlessService(slr *kubeovnv1.SwitchLBRule, oldSvc *corev1.Service) *co
 			Selector:        selectors,
 			ClusterIP:       corev1.ClusterIPNone,
 			Type:            corev1.ServiceTypeClusterIP,
-			SessionAffinity: corev1.ServiceAffinity(slr.Spec.SessionAffinity),
+			SessionAffinity: sessionAffinity,
 		},
 	}
 	return svc
@@ -303,7 +303,7 @@ func (c *Controller) acquireVirtualAddress(subnetName, name, nicName string) (st
 	checkConflict := false
 	var err error
 	for {
-		v4ip, v6ip, mac, err = c.ipam.GetRandomAddress(name, nicName, mac, subnetName, skippedAddrs, checkConflict)
+		v4ip, v6ip, mac, err = c.ipam.GetRandomAddress(name, nicName, mac, subnetName, "", skippedAddrs, checkConflict)
 		if err != nil {
 			return "", "", "", err
 		}
@@ -326,7 +326,7 @@ func (c *Controller) setLocalnetTag(subnet string, vlanID int) error {
 
 func (c *Controller) delLocalnet(subnet string) error {
 	localnetPort := ovs.PodNameToLocalnetName(subnet)
-	if err := c.ovnLegacyClient.DeleteLogicalSwitchPort(localnetPort); err != nil {
+	if err := c.ovnClient.DeleteLogicalSwitchPort(localnetPort); err != nil {
 		klog.Errorf("failed to delete localnet port %s: %v", localnetPort, err)
 		return err
 	}
@@ -62,6 +62,7 @@ func (c *Controller) enqueueUpdateVpc(old, new interface{}) {
 		!reflect.DeepEqual(oldVpc.Spec.StaticRoutes, newVpc.Spec.StaticRoutes) ||
 		!reflect.DeepEqual(oldVpc.Spec.PolicyRoutes, newVpc.Spec.PolicyRoutes) ||
 		!reflect.DeepEqual(oldVpc.Spec.VpcPeerings, newVpc.Spec.VpcPeerings) ||
+		oldVpc.Spec.EnableExternal != newVpc.Spec.EnableExternal ||
 		!reflect.DeepEqual(oldVpc.Annotations, newVpc.Annotations) {
 		klog.V(3).Infof("enqueue update vpc %s", key)
 		c.addOrUpdateVpcQueue.Add(key)
@@ -105,6 +106,9 @@ func (c *Controller) handleDelVpc(vpc *kubeovnv1.Vpc) error {
 	if err := c.deleteVpcLb(vpc); err != nil {
 		return err
 	}
+	if err := c.handleDelVpcExternal(vpc.Name); err != nil {
+		return err
+	}
 
 	err := c.deleteVpcRouter(vpc.Status.Router)
 	if err != nil {
@@ -189,7 +193,7 @@ func (c *Controller) reconcileRouterPorts(vpc *kubeovnv1.Vpc) error {
 
 			klog.V(1).InfoS("router port not exists, trying to create", "vpc", vpc.Name, "subnet", subnetName)
 
-			networks := util.GetIpAddrWithMask(subnet.Spec.Gateway, subnet.Spec.CIDRBlock)
+			networks := strings.Join(util.GetSubnetRouterNetworks(subnet), ",")
 			if err := c.ovnClient.AddLogicalRouterPort(router, routerPortName, "", networks); err != nil {
 				klog.ErrorS(err, "unable to create router port", "vpc", vpc.Name, "subnet", subnetName)
 				return err
@@ -217,7 +221,7 @@ func (c *Controller) reconcileRouterPortBySubnet(vpc *kubeovnv1.Vpc, subnet *kub
 			return err
 		}
 
-		networks := util.GetIpAddrWithMask(subnet.Spec.Gateway, subnet.Spec.CIDRBlock)
+		networks := strings.Join(util.GetSubnetRouterNetworks(subnet), ",")
 		klog.Infof("router port does not exist, trying to create %s with ip %s", routerPortName, networks)
 
 		if err := c.ovnClient.AddLogicalRouterPort(router, routerPortName, "", networks); err != nil {
@@ -256,64 +260,64 @@ func (c *Controller) GenVpcLoadBalancer(vpcKey string) *VpcLoadBalancer {
 func (c *Controller) addLoadBalancer(vpc string) (*VpcLoadBalancer, error) {
 	vpcLbConfig := c.GenVpcLoadBalancer(vpc)
 
-	tcpLb, err := c.ovnLegacyClient.FindLoadbalancer(vpcLbConfig.TcpLoadBalancer)
+	tcpLbExists, err := c.ovnClient.LoadBalancerExists(vpcLbConfig.TcpLoadBalancer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find tcp lb %v", err)
 	}
-	if tcpLb == "" {
+	if !tcpLbExists {
 		klog.Infof("init cluster tcp load balancer %s", vpcLbConfig.TcpLoadBalancer)
-		err := c.ovnLegacyClient.CreateLoadBalancer(vpcLbConfig.TcpLoadBalancer, util.ProtocolTCP, "")
+		err := c.ovnClient.CreateLoadBalancer(vpcLbConfig.TcpLoadBalancer, util.ProtocolTCP, "")
 		if err != nil {
 			klog.Errorf("failed to create cluster tcp load balancer %v", err)
 			return nil, err
 		}
 	} else {
-		klog.Infof("tcp load balancer %s exists", tcpLb)
+		klog.Infof("tcp load balancer %s exists", vpcLbConfig.TcpLoadBalancer)
 	}
 
-	tcpSessionLb, err := c.ovnLegacyClient.FindLoadbalancer(vpcLbConfig.TcpSessLoadBalancer)
+	tcpSessionLbExists, err := c.ovnClient.LoadBalancerExists(vpcLbConfig.TcpSessLoadBalancer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find tcp session lb %v", err)
 	}
-	if tcpSessionLb == "" {
+	if !tcpSessionLbExists {
 		klog.Infof("init cluster tcp session load balancer %s", vpcLbConfig.TcpSessLoadBalancer)
-		err := c.ovnLegacyClient.CreateLoadBalancer(vpcLbConfig.TcpSessLoadBalancer, util.ProtocolTCP, "ip_src")
+		err := c.ovnClient.CreateLoadBalancer(vpcLbConfig.TcpSessLoadBalancer, util.ProtocolTCP, "ip_src")
 		if err != nil {
 			klog.Errorf("failed to create cluster tcp session load balancer %v", err)
 			return nil, err
 		}
 	} else {
-		klog.Infof("tcp session load balancer %s exists", tcpSessionLb)
+		klog.Infof("tcp session load balancer %s exists", vpcLbConfig.TcpSessLoadBalancer)
 	}
 
-	udpLb, err := c.ovnLegacyClient.FindLoadbalancer(vpcLbConfig.UdpLoadBalancer)
+	udpLbExists, err := c.ovnClient.LoadBalancerExists(vpcLbConfig.UdpLoadBalancer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find udp lb %v", err)
 	}
-	if udpLb == "" {
+	if !udpLbExists {
 		klog.Infof("init cluster udp load balancer %s", vpcLbConfig.UdpLoadBalancer)
-		err := c.ovnLegacyClient.CreateLoadBalancer(vpcLbConfig.UdpLoadBalancer, util.ProtocolUDP, "")
+		err := c.ovnClient.CreateLoadBalancer(vpcLbConfig.UdpLoadBalancer, util.ProtocolUDP, "")
 		if err != nil {
 			klog.Errorf("failed to create cluster udp load balancer %v", err)
 			return nil, err
 		}
 	} else {
-		klog.Infof("udp load balancer %s exists", udpLb)
+		klog.Infof("udp load balancer %s exists", vpcLbConfig.UdpLoadBalancer)
 	}
 
-	udpSessionLb, err := c.ovnLegacyClient.FindLoadbalancer(vpcLbConfig.UdpSessLoadBalancer)
+	udpSessionLbExists, err := c.ovnClient.LoadBalancerExists(vpcLbConfig.UdpSessLoadBalancer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find udp session lb %v", err)
 	}
-	if udpSessionLb == "" {
+	if !udpSessionLbExists {
 		klog.Infof("init cluster udp session load balancer %s", vpcLbConfig.UdpSessLoadBalancer)
-		err := c.ovnLegacyClient.CreateLoadBalancer(vpcLbConfig.UdpSessLoadBalancer, util.ProtocolUDP, "ip_src")
+		err := c.ovnClient.CreateLoadBalancer(vpcLbConfig.UdpSessLoadBalancer, util.ProtocolUDP, "ip_src")
 		if err != nil {
 			klog.Errorf("failed to create cluster udp session load balancer %v", err)
 			return nil, err
 		}
 	} else {
-		klog.Infof("udp session load balancer %s exists", udpSessionLb)
+		klog.Infof("udp session load balancer %s exists", vpcLbConfig.UdpSessLoadBalancer)
 	}
 
 	return vpcLbConfig, nil
@@ -364,33 +368,52 @@ func (c *Controller) handleAddOrUpdateVpc(key string) error {
 		}
 	}
 
+	staticRoutes := vpc.Spec.StaticRoutes
+	if vpc.Spec.EnableExternal {
+		route, err := c.handleAddVpcExternal(vpc.Name)
+		if err != nil {
+			klog.Errorf("failed to connect vpc %s to external subnet %s, %v", vpc.Name, util.VpcExternalNet, err)
+			return err
+		}
+		if route != nil && !hasDefaultRoute(staticRoutes, route.CIDR) {
+			staticRoutes = append([]*kubeovnv1.StaticRoute{route}, staticRoutes...)
+		}
+	} else if err = c.handleDelVpcExternal(vpc.Name); err != nil {
+		klog.Errorf("failed to disconnect vpc %s from external subnet %s, %v", vpc.Name, util.VpcExternalNet, err)
+		return err
+	}
+
+	if staticRoutes, err = c.switchNatGwNextHops(vpc.Name, staticRoutes); err != nil {
+		return err
+	}
+
 	// handle static route
-	existRoute, err := c.ovnLegacyClient.GetStaticRouteList(vpc.Name)
+	existRoute, err := c.ovnClient.ListStaticRoutes(vpc.Name)
 	if err != nil {
 		klog.Errorf("failed to get vpc %s static route list, %v", vpc.Name, err)
 		return err
 	}
 
-	routeNeedDel, routeNeedAdd, err := diffStaticRoute(existRoute, vpc.Spec.StaticRoutes)
+	routeNeedDel, routeNeedAdd, err := diffStaticRoute(existRoute, staticRoutes)
 	if err != nil {
 		klog.Errorf("failed to diff vpc %s static route, %v", vpc.Name, err)
 		return err
 	}
 	for _, item := range routeNeedDel {
-		if err = c.ovnLegacyClient.DeleteStaticRoute(item.CIDR, vpc.Name); err != nil {
+		if err = c.ovnClient.DeleteStaticRoute(item.CIDR, vpc.Name); err != nil {
 			klog.Errorf("del vpc %s static route failed, %v", vpc.Name, err)
 			return err
 		}
 	}
 
 	for _, item := range routeNeedAdd {
-		if err = c.ovnLegacyClient.AddStaticRoute(convertPolicy(item.Policy), item.CIDR, item.NextHopIP, vpc.Name, util.NormalRouteType); err != nil {
+		if err = c.ovnClient.AddStaticRoute(convertPolicy(item.Policy), item.CIDR, item.NextHopIP, vpc.Name, util.NormalRouteType); err != nil {
 			klog.Errorf("add static route to vpc %s failed, %v", vpc.Name, err)
 			return err
 		}
 	}
 	// handle policy route
-	existPolicyRoute, err := c.ovnLegacyClient.GetPolicyRouteList(vpc.Name)
+	existPolicyRoute, err := c.ovnClient.GetPolicyRouteList(vpc.Name)
 	if err != nil {
 		klog.Errorf("failed to get vpc %s policy route list, %v", vpc.Name, err)
 		return err
@@ -402,14 +425,14 @@ func (c *Controller) handleAddOrUpdateVpc(key string) error {
 		return err
 	}
 	for _, item := range policyRouteNeedDel {
-		if err = c.ovnLegacyClient.DeletePolicyRoute(vpc.Name, item.Priority, item.Match); err != nil {
+		if err = c.ovnClient.DeletePolicyRoute(vpc.Name, item.Priority, item.Match); err != nil {
 			klog.Errorf("del vpc %s policy route failed, %v", vpc.Name, err)
 			return err
 		}
 	}
 	for _, item := range policyRouteNeedAdd {
 		externalIDs := map[string]string{"vendor": util.CniTypeName}
-		if err = c.ovnLegacyClient.AddPolicyRoute(vpc.Name, item.Priority, item.Match, string(item.Action), item.NextHopIP, externalIDs); err != nil {
+		if err = c.ovnClient.AddPolicyRoute(vpc.Name, item.Priority, item.Match, string(item.Action), item.NextHopIP, externalIDs); err != nil {
 			klog.Errorf("add policy route to vpc %s failed, %v", vpc.Name, err)
 			return err
 		}
@@ -720,3 +743,171 @@ func (c *Controller) createVpcRouter(lr string) error {
 func (c *Controller) deleteVpcRouter(lr string) error {
 	return c.ovnLegacyClient.DeleteLogicalRouter(lr)
 }
+
+// handleAddVpcExternal connects the vpc router to the external subnet with a distributed gateway port,
+// whose address is held by an ovn eip of type lrp. The default route to the gateway of the external subnet
+// is returned, nil is returned if the address of the port is not allocated yet.
+func (c *Controller) handleAddVpcExternal(vpcName string) (*kubeovnv1.StaticRoute, error) {
+	subnet, err := c.subnetsLister.Get(util.VpcExternalNet)
+	if err != nil {
+		klog.Errorf("failed to get external subnet %s, %v", util.VpcExternalNet, err)
+		return nil, err
+	}
+	v4Cidr, _ := util.SplitStringIP(subnet.Spec.CIDRBlock)
+	v4Gw, _ := util.SplitStringIP(subnet.Spec.Gateway)
+	if v4Cidr == "" || v4Gw == "" {
+		return nil, fmt.Errorf("external subnet %s has no ipv4 cidr", util.VpcExternalNet)
+	}
+
+	lrpName := ovs.LogicalRouterPortName(vpcName, util.VpcExternalNet)
+	eip, err := c.config.KubeOvnClient.KubeovnV1().OvnEips().Get(context.Background(), lrpName, metav1.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			klog.Errorf("failed to get ovn eip %s, %v", lrpName, err)
+			return nil, err
+		}
+		eip = &kubeovnv1.OvnEip{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   lrpName,
+				Labels: map[string]string{util.VpcNameLabel: vpcName},
+			},
+			Spec: kubeovnv1.OvnEipSpec{Type: ovnEipTypeLrp},
+		}
+		if _, err = c.config.KubeOvnClient.KubeovnV1().OvnEips().Create(context.Background(), eip, metav1.CreateOptions{}); err != nil {
+			klog.Errorf("failed to create ovn eip %s, %v", lrpName, err)
+			return nil, err
+		}
+	}
+	if !eip.Status.Ready {
+		// the vpc is enqueued again once the eip is ready
+		klog.Infof("wait for the address of ovn eip %s", lrpName)
+		return nil, nil
+	}
+
+	exists, err := c.ovnClient.LogicalRouterPortExists(lrpName)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		_, ipNet, err := net.ParseCIDR(v4Cidr)
+		if err != nil {
+			return nil, err
+		}
+		ones, _ := ipNet.Mask.Size()
+		networks := fmt.Sprintf("%s/%d", eip.Status.V4ip, ones)
+		if err = c.ovnClient.AddLogicalRouterPort(vpcName, lrpName, eip.Status.MacAddress, networks); err != nil {
+			klog.Errorf("failed to create router port %s, %v", lrpName, err)
+			return nil, err
+		}
+	}
+	lspName := ovs.LogicalSwitchPortName(vpcName, util.VpcExternalNet)
+	if err = c.ovnClient.CreateRouterTypePort(util.VpcExternalNet, lspName, lrpName); err != nil {
+		klog.Errorf("failed to create switch port %s, %v", lspName, err)
+		return nil, err
+	}
+	// ovn-controller on the gateway chassis announces the nat ips and load balancer vips of the router
+	// by gratuitous arp through the localnet port, which tags them with the vlan of the external subnet
+	if err = c.ovnClient.SetLogicalSwitchPortOptions(lspName, map[string]string{"nat-addresses": "router"}); err != nil {
+		klog.Errorf("failed to set nat addresses of switch port %s, %v", lspName, err)
+		return nil, err
+	}
+
+	chassises, err := c.getExternalGatewayChassises()
+	if err != nil {
+		return nil, err
+	}
+	if err = c.ovnClient.SetGatewayChassises(lrpName, chassises); err != nil {
+		klog.Errorf("failed to set gateway chassises of router port %s, %v", lrpName, err)
+		return nil, err
+	}
+
+	return &kubeovnv1.StaticRoute{Policy: kubeovnv1.PolicyDst, CIDR: "0.0.0.0/0", NextHopIP: v4Gw}, nil
+}
+
+// handleDelVpcExternal removes the distributed gateway port of the vpc and releases its address
+func (c *Controller) handleDelVpcExternal(vpcName string) error {
+	lrpName := ovs.LogicalRouterPortName(vpcName, util.VpcExternalNet)
+	exists, err := c.ovnClient.LogicalRouterPortExists(lrpName)
+	if err != nil {
+		return err
+	}
+	if exists {
+		klog.Infof("disconnect vpc %s from external subnet %s", vpcName, util.VpcExternalNet)
+		if err = c.ovnLegacyClient.DeleteLogicalRouterPort(lrpName); err != nil {
+			return err
+		}
+		if err = c.ovnClient.DeleteLogicalSwitchPort(ovs.LogicalSwitchPortName(vpcName, util.VpcExternalNet)); err != nil {
+			return err
+		}
+	}
+
+	if err = c.config.KubeOvnClient.KubeovnV1().OvnEips().Delete(context.Background(), lrpName, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+		klog.Errorf("failed to delete ovn eip %s, %v", lrpName, err)
+		return err
+	}
+	return nil
+}
+
+// getExternalGatewayChassises returns the chassises of the nodes labeled as external gateway
+func (c *Controller) getExternalGatewayChassises() ([]string, error) {
+	sel := labels.SelectorFromSet(labels.Set{util.ExGatewayLabel: "true"})
+	nodes, err := c.nodesLister.List(sel)
+	if err != nil {
+		klog.Errorf("failed to list external gateway nodes, %v", err)
+		return nil, err
+	}
+	chassises := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		chassis, err := c.ovnLegacyClient.GetChassis(node.Name)
+		if err != nil {
+			klog.Errorf("failed to get chassis of node %s, %v", node.Name, err)
+			return nil, err
+		}
+		if chassis == "" {
+			return nil, fmt.Errorf("no chassis for external gateway node %s", node.Name)
+		}
+		chassises = append(chassises, chassis)
+	}
+	if len(chassises) == 0 {
+		return nil, fmt.Errorf("no node is labeled with %s=true", util.ExGatewayLabel)
+	}
+	return chassises, nil
+}
+
+// switchNatGwNextHops replaces the next hop of the static routes via the lan ip of a vpc nat gateway
+// in ha mode with the address of its active pod
+func (c *Controller) switchNatGwNextHops(vpcName string, routes []*kubeovnv1.StaticRoute) ([]*kubeovnv1.StaticRoute, error) {
+	gws, err := c.vpcNatGatewayLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list vpc nat gateways, %v", err)
+		return nil, err
+	}
+	nextHops := make(map[string]string)
+	for _, gw := range gws {
+		if gw.Spec.Vpc == vpcName && gw.Spec.HA && gw.Status.ActiveLanIp != "" && gw.Status.ActiveLanIp != gw.Spec.LanIp {
+			nextHops[gw.Spec.LanIp] = gw.Status.ActiveLanIp
+		}
+	}
+	if len(nextHops) == 0 {
+		return routes, nil
+	}
+
+	result := make([]*kubeovnv1.StaticRoute, 0, len(routes))
+	for _, route := range routes {
+		if nextHop, ok := nextHops[route.NextHopIP]; ok {
+			route = route.DeepCopy()
+			route.NextHopIP = nextHop
+		}
+		result = append(result, route)
+	}
+	return result, nil
+}
+
+func hasDefaultRoute(routes []*kubeovnv1.StaticRoute, cidr string) bool {
+	for _, route := range routes {
+		if route.CIDR == cidr {
+			return true
+		}
+	}
+	return false
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -36,6 +37,8 @@ const (
 	natGwInit              = "init"
 	natGwEipAdd            = "eip-add"
 	natGwEipDel            = "eip-del"
+	natGwEipQoSAdd         = "eip-qos-add"
+	natGwEipQoSDel         = "eip-qos-del"
 	natGwDnatAdd           = "dnat-add"
 	natGwDnatDel           = "dnat-del"
 	natGwSnatAdd           = "snat-add"
@@ -45,8 +48,13 @@ const (
 	natGwSubnetRouteAdd    = "subnet-route-add"
 	natGwSubnetRouteDel    = "subnet-route-del"
 	natGwExtSubnetRouteAdd = "ext-subnet-route-add"
+	natGwHaActive          = "ha-active"
+	natGwHaStandby         = "ha-standby"
 
 	getIptablesVersion = "get-iptables-version"
+
+	natGwRoleActive  = "active"
+	natGwRoleStandby = "standby"
 )
 
 func genNatGwStsName(name string) string {
@@ -180,6 +188,11 @@ func (c *Controller) runUpdateVpcSubnetWorker() {
 	}
 }
 
+func (c *Controller) runUpdateVpcNatGwActiveWorker() {
+	for c.processNextWorkItem("updateVpcNatGwActive", c.updateVpcNatGwActiveQueue, c.handleUpdateVpcNatGwActive) {
+	}
+}
+
 func (c *Controller) processNextWorkItem(processName string, queue workqueue.RateLimitingInterface, handler func(key string) error) bool {
 	obj, shutdown := queue.Get()
 	if shutdown {
@@ -247,6 +260,11 @@ func (c *Controller) handleAddOrUpdateVpcNatGw(key string) error {
 		klog.Errorf("failed to get subnet '%s', err: %v", gw.Spec.Subnet, err)
 		return err
 	}
+	if gw.Spec.HA && (gw.Spec.StandbyLanIp == "" || gw.Spec.StandbyLanIp == gw.Spec.LanIp) {
+		err = fmt.Errorf("vpc nat gw %s in ha mode requires a standby lan ip different from the lan ip", gw.Name)
+		klog.Error(err)
+		return err
+	}
 
 	// check or create statefulset
 	needToCreate := false
@@ -281,6 +299,7 @@ func (c *Controller) handleAddOrUpdateVpcNatGw(key string) error {
 			return err
 		}
 	}
+	c.updateVpcNatGwActiveQueue.Add(key)
 	return nil
 }
 
@@ -309,41 +328,40 @@ func (c *Controller) handleInitVpcNatGw(key string) error {
 		return err
 	}
 
-	oriPod, err := c.getNatGwPod(key)
+	pods, err := c.getNatGwPods(key)
 	if err != nil {
 		return err
 	}
-	pod := oriPod.DeepCopy()
-
-	if pod.Status.Phase != corev1.PodRunning {
-		time.Sleep(10 * time.Second)
-		return fmt.Errorf("failed to init vpc nat gateway, pod is not ready")
-	}
 
-	if _, hasInit := pod.Annotations[util.VpcNatGatewayInitAnnotation]; hasInit {
-		return nil
-	}
-	NAT_GW_CREATED_AT = pod.CreationTimestamp.Format("2006-01-02T15:04:05")
-	klog.V(3).Infof("nat gw pod '%s' inited at %s", key, NAT_GW_CREATED_AT)
-	if err = c.execNatGwRules(pod, natGwInit, []string{v4Cidr}); err != nil {
-		klog.Errorf("failed to init vpc nat gateway, %v", err)
-		return err
-	}
-	c.updateVpcFloatingIpQueue.Add(key)
-	c.updateVpcDnatQueue.Add(key)
-	c.updateVpcSnatQueue.Add(key)
-	c.updateVpcSubnetQueue.Add(key)
-	c.updateVpcEipQueue.Add(key)
-	pod.Annotations[util.VpcNatGatewayInitAnnotation] = "true"
-	patch, err := util.GenerateStrategicMergePatchPayload(oriPod, pod)
-	if err != nil {
-		return err
-	}
-	if _, err := c.config.KubeClient.CoreV1().Pods(pod.Namespace).Patch(context.Background(), pod.Name,
-		types.StrategicMergePatchType, patch, metav1.PatchOptions{}, ""); err != nil {
-		klog.Errorf("patch pod %s/%s failed %v", pod.Name, pod.Namespace, err)
-		return err
+	// all pods of a gateway in ha mode are inited and get the same rules
+	for _, oriPod := range pods {
+		if _, hasInit := oriPod.Annotations[util.VpcNatGatewayInitAnnotation]; hasInit {
+			continue
+		}
+		pod := oriPod.DeepCopy()
+		NAT_GW_CREATED_AT = pod.CreationTimestamp.Format("2006-01-02T15:04:05")
+		klog.V(3).Infof("nat gw pod '%s' inited at %s", pod.Name, NAT_GW_CREATED_AT)
+		if err = c.execNatGwRules(pod, natGwInit, []string{v4Cidr}); err != nil {
+			klog.Errorf("failed to init vpc nat gateway, %v", err)
+			return err
+		}
+		c.updateVpcFloatingIpQueue.Add(key)
+		c.updateVpcDnatQueue.Add(key)
+		c.updateVpcSnatQueue.Add(key)
+		c.updateVpcSubnetQueue.Add(key)
+		c.updateVpcEipQueue.Add(key)
+		pod.Annotations[util.VpcNatGatewayInitAnnotation] = "true"
+		patch, err := util.GenerateStrategicMergePatchPayload(oriPod, pod)
+		if err != nil {
+			return err
+		}
+		if _, err := c.config.KubeClient.CoreV1().Pods(pod.Namespace).Patch(context.Background(), pod.Name,
+			types.StrategicMergePatchType, patch, metav1.PatchOptions{}, ""); err != nil {
+			klog.Errorf("patch pod %s/%s failed %v", pod.Name, pod.Namespace, err)
+			return err
+		}
 	}
+	c.updateVpcNatGwActiveQueue.Add(key)
 	return nil
 }
 
@@ -515,11 +533,21 @@ func (c *Controller) handleUpdateNatGwSubnetRoute(natGwKey string) error {
 		return err
 	}
 
-	oriPod, err := c.getNatGwPod(natGwKey)
+	pods, err := c.getNatGwPods(natGwKey)
 	if err != nil {
 		return err
 	}
+	for _, pod := range pods {
+		if err = c.updateNatGwPodSubnetRoute(gw, pod); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Controller) updateNatGwPodSubnetRoute(gw *kubeovnv1.VpcNatGateway, oriPod *corev1.Pod) error {
 	pod := oriPod.DeepCopy()
+	var err error
 	var extRules []string
 	var v4ExternalGw, v4InternalGw, v4ExternalCidr string
 	if subnet, ok := c.ipam.Subnets[util.VpcExternalNet]; ok {
@@ -610,6 +638,125 @@ func (c *Controller) handleUpdateNatGwSubnetRoute(natGwKey string) error {
 	return nil
 }
 
+// handleUpdateVpcNatGwActive elects the active pod of the gateway. The active pod is kept as long as it is running,
+// otherwise a running standby pod takes over the eips, and the vpc is resynced to switch the static routes
+// whose next hop is the lan ip of the gateway to the address of the new active pod.
+func (c *Controller) handleUpdateVpcNatGwActive(key string) error {
+	if vpcNatEnabled != "true" {
+		return fmt.Errorf("iptables nat gw not enable")
+	}
+	c.vpcNatGwKeyMutex.Lock(key)
+	defer c.vpcNatGwKeyMutex.Unlock(key)
+	gw, err := c.vpcNatGatewayLister.Get(key)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	pods, err := c.listNatGwPods(key)
+	if err != nil {
+		klog.Errorf("failed to list pods of vpc nat gw %s, %v", key, err)
+		return err
+	}
+
+	var candidates []*corev1.Pod
+	for _, pod := range pods {
+		if isNatGwPodRunning(pod) && pod.Annotations[util.VpcNatGatewayInitAnnotation] == "true" {
+			candidates = append(candidates, pod)
+		}
+	}
+	var active *corev1.Pod
+	var standbys []*corev1.Pod
+	for _, pod := range candidates {
+		if pod.Name == gw.Status.ActivePod {
+			active = pod
+		}
+	}
+	if active == nil && len(candidates) != 0 {
+		active = candidates[0]
+	}
+	for _, pod := range candidates {
+		if pod != active {
+			standbys = append(standbys, pod)
+		}
+	}
+
+	if gw.Spec.HA {
+		// demote the standby pods before promoting the active one, so that no eip is announced twice
+		for _, pod := range standbys {
+			if err = c.setNatGwPodRole(pod, natGwRoleStandby); err != nil {
+				return err
+			}
+		}
+		if active != nil {
+			if err = c.setNatGwPodRole(active, natGwRoleActive); err != nil {
+				return err
+			}
+		}
+	}
+
+	status := kubeovnv1.VpcNatGatewayStatus{}
+	if active != nil {
+		status.ActivePod = active.Name
+		status.ActiveLanIp, _ = util.SplitStringIP(active.Annotations[util.IpAddressAnnotation])
+	} else if len(pods) != 0 {
+		klog.Warningf("no running pod of vpc nat gw %s", key)
+	}
+	if gw.Status == status {
+		return nil
+	}
+	if gw.Status.ActivePod != "" && gw.Status.ActivePod != status.ActivePod {
+		klog.Infof("active pod of vpc nat gw %s changed from %s to %s", key, gw.Status.ActivePod, status.ActivePod)
+	}
+	bytes, err := status.Bytes()
+	if err != nil {
+		return err
+	}
+	if _, err = c.config.KubeOvnClient.KubeovnV1().VpcNatGateways().Patch(context.Background(), key, types.MergePatchType,
+		bytes, metav1.PatchOptions{}, "status"); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		klog.Errorf("failed to patch status of vpc nat gw %s, %v", key, err)
+		return err
+	}
+	if gw.Status.ActiveLanIp != status.ActiveLanIp {
+		c.addOrUpdateVpcQueue.Add(gw.Spec.Vpc)
+	}
+	return nil
+}
+
+// setNatGwPodRole makes the pod announce the eips if it is active, the role is recorded in
+// an annotation of the pod, so a recreated pod with the same name is set up again
+func (c *Controller) setNatGwPodRole(oriPod *corev1.Pod, role string) error {
+	if oriPod.Annotations[util.VpcNatGatewayRoleAnnotation] == role {
+		return nil
+	}
+	operation := natGwHaStandby
+	if role == natGwRoleActive {
+		operation = natGwHaActive
+	}
+	klog.Infof("set role of vpc nat gw pod %s to %s", oriPod.Name, role)
+	if err := c.execNatGwRules(oriPod, operation, nil); err != nil {
+		klog.Errorf("failed to set role of vpc nat gw pod %s to %s, %v", oriPod.Name, role, err)
+		return err
+	}
+
+	pod := oriPod.DeepCopy()
+	pod.Annotations[util.VpcNatGatewayRoleAnnotation] = role
+	patch, err := util.GenerateStrategicMergePatchPayload(oriPod, pod)
+	if err != nil {
+		return err
+	}
+	if _, err := c.config.KubeClient.CoreV1().Pods(pod.Namespace).Patch(context.Background(), pod.Name,
+		types.StrategicMergePatchType, patch, metav1.PatchOptions{}, ""); err != nil {
+		klog.Errorf("patch pod %s/%s failed %v", pod.Name, pod.Namespace, err)
+		return err
+	}
+	return nil
+}
+
 func (c *Controller) execNatGwRules(pod *corev1.Pod, operation string, rules []string) error {
 	cmd := fmt.Sprintf("bash /kube-ovn/nat-gateway.sh %s %s", operation, strings.Join(rules, " "))
 	klog.V(3).Infof(cmd)
@@ -638,6 +785,9 @@ func (c *Controller) execNatGwRules(pod *corev1.Pod, operation string, rules []s
 
 func (c *Controller) genNatGwStatefulSet(gw *kubeovnv1.VpcNatGateway, oldSts *v1.StatefulSet) (newSts *v1.StatefulSet) {
 	replicas := int32(1)
+	if gw.Spec.HA {
+		replicas = 2
+	}
 	name := genNatGwStsName(gw.Name)
 	allowPrivilegeEscalation := true
 	privileged := true
@@ -656,6 +806,14 @@ func (c *Controller) genNatGwStatefulSet(gw *kubeovnv1.VpcNatGateway, oldSts *v1
 		util.LogicalSwitchAnnotation:     gw.Spec.Subnet,
 		util.IpAddressAnnotation:         gw.Spec.LanIp,
 	}
+	if gw.Spec.HA {
+		// pods of the statefulset get the addresses in the pool by their ordinal
+		delete(podAnnotations, util.IpAddressAnnotation)
+		delete(newPodAnnotations, util.IpAddressAnnotation)
+		podAnnotations[util.IpPoolAnnotation] = fmt.Sprintf("%s;%s", gw.Spec.LanIp, gw.Spec.StandbyLanIp)
+	} else {
+		delete(newPodAnnotations, util.IpPoolAnnotation)
+	}
 	for key, value := range podAnnotations {
 		newPodAnnotations[key] = value
 	}
@@ -670,6 +828,21 @@ func (c *Controller) genNatGwStatefulSet(gw *kubeovnv1.VpcNatGateway, oldSts *v1
 	}
 	klog.V(3).Infof("prepare for vpc nat gateway pod, node selector: %v", selectors)
 
+	var env []corev1.EnvVar
+	var affinity *corev1.Affinity
+	if gw.Spec.HA {
+		env = []corev1.EnvVar{{Name: "VPC_NAT_GW_HA", Value: "true"}}
+		// the active and standby pods must not share a node
+		affinity = &corev1.Affinity{
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: labels},
+					TopologyKey:   "kubernetes.io/hostname",
+				}},
+			},
+		}
+	}
+
 	var tolerations []corev1.Toleration
 	for _, t := range gw.Spec.Tolerations {
 		toleration := corev1.Toleration{
@@ -707,6 +880,7 @@ func (c *Controller) genNatGwStatefulSet(gw *kubeovnv1.VpcNatGateway, oldSts *v1
 							Image:           vpcNatImage,
 							Command:         []string{"bash"},
 							Args:            []string{"-c", "while true; do sleep 10000; done"},
+							Env:             env,
 							ImagePullPolicy: corev1.PullIfNotPresent,
 							SecurityContext: &corev1.SecurityContext{
 								Privileged:               &privileged,
@@ -716,6 +890,7 @@ func (c *Controller) genNatGwStatefulSet(gw *kubeovnv1.VpcNatGateway, oldSts *v1
 					},
 					NodeSelector: selectors,
 					Tolerations:  tolerations,
+					Affinity:     affinity,
 				},
 			},
 			UpdateStrategy: v1.StatefulSetUpdateStrategy{
@@ -738,25 +913,62 @@ func (c *Controller) cleanUpVpcNatGw() error {
 	return nil
 }
 
-func (c *Controller) getNatGwPod(name string) (*corev1.Pod, error) {
+func (c *Controller) listNatGwPods(name string) ([]*corev1.Pod, error) {
 	sel, _ := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
 		MatchLabels: map[string]string{"app": genNatGwStsName(name), util.VpcNatGatewayLabel: "true"},
 	})
-
 	pods, err := c.podsLister.Pods(c.config.PodNamespace).List(sel)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	return pods, nil
+}
+
+// getNatGwPods returns the running pods of the gateway, a gateway in ha mode has up to two pods
+func (c *Controller) getNatGwPods(name string) ([]*corev1.Pod, error) {
+	pods, err := c.listNatGwPods(name)
 	if err != nil {
 		return nil, err
 	} else if len(pods) == 0 {
 		return nil, k8serrors.NewNotFound(v1.Resource("pod"), name)
-	} else if len(pods) != 1 {
-		time.Sleep(5 * time.Second)
-		return nil, fmt.Errorf("too many pod")
-	} else if pods[0].Status.Phase != "Running" {
+	}
+
+	running := make([]*corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if isNatGwPodRunning(pod) {
+			running = append(running, pod)
+		}
+	}
+	if len(running) == 0 {
 		time.Sleep(5 * time.Second)
 		return nil, fmt.Errorf("pod is not active now")
 	}
+	return running, nil
+}
+
+// isNatGwPodRunning returns whether rules can be executed in the pod, pods on a lost node are not ready
+func isNatGwPodRunning(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
 
-	return pods[0], nil
+// execNatGwRulesInPods executes the rules in all the pods, so that the standby pod
+// of a gateway in ha mode is kept in sync with the active one
+func (c *Controller) execNatGwRulesInPods(pods []*corev1.Pod, operation string, rules []string) error {
+	for _, pod := range pods {
+		if err := c.execNatGwRules(pod, operation, rules); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (c *Controller) checkVpcExternalNet() (err error) {
@@ -775,11 +987,11 @@ func (c *Controller) initCreateAt(key string) (err error) {
 	if NAT_GW_CREATED_AT != "" {
 		return nil
 	}
-	pod, err := c.getNatGwPod(key)
+	pods, err := c.getNatGwPods(key)
 	if err != nil {
 		return err
 	}
-	NAT_GW_CREATED_AT = pod.CreationTimestamp.Format("2006-01-02T15:04:05")
+	NAT_GW_CREATED_AT = pods[0].CreationTimestamp.Format("2006-01-02T15:04:05")
 	return nil
 }
 
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func Test_genNatGwStatefulSet(t *testing.T) {
+	ctrl := newFakeController(t, nil, nil)
+	gw := &kubeovnv1.VpcNatGateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "gw"},
+		Spec: kubeovnv1.VpcNatSpec{
+			Vpc:    "test-vpc",
+			Subnet: "test-vpc-subnet",
+			LanIp:  "10.0.0.254",
+		},
+	}
+
+	sts := ctrl.genNatGwStatefulSet(gw, nil)
+	require.EqualValues(t, 1, *sts.Spec.Replicas)
+	require.Equal(t, "10.0.0.254", sts.Spec.Template.Annotations[util.IpAddressAnnotation])
+	require.Nil(t, sts.Spec.Template.Spec.Affinity)
+
+	gw.Spec.HA = true
+	gw.Spec.StandbyLanIp = "10.0.0.253"
+	sts = ctrl.genNatGwStatefulSet(gw, sts)
+	require.EqualValues(t, 2, *sts.Spec.Replicas)
+	require.NotContains(t, sts.Spec.Template.Annotations, util.IpAddressAnnotation)
+	require.Equal(t, "10.0.0.254;10.0.0.253", sts.Spec.Template.Annotations[util.IpPoolAnnotation])
+	require.NotNil(t, sts.Spec.Template.Spec.Affinity.PodAntiAffinity)
+	require.Equal(t, []corev1.EnvVar{{Name: "VPC_NAT_GW_HA", Value: "true"}}, sts.Spec.Template.Spec.Containers[0].Env)
+}
+
+func newNatGwPod(gw *kubeovnv1.VpcNatGateway, index, ip, role string, ready bool) *corev1.Pod {
+	status := corev1.ConditionTrue
+	if !ready {
+		status = corev1.ConditionFalse
+	}
+	name := genNatGwStsName(gw.Name)
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-" + index,
+			Namespace: "kube-system",
+			Labels:    map[string]string{"app": name, util.VpcNatGatewayLabel: "true"},
+			Annotations: map[string]string{
+				util.VpcNatGatewayAnnotation:     gw.Name,
+				util.VpcNatGatewayInitAnnotation: "true",
+				util.VpcNatGatewayRoleAnnotation: role,
+				util.IpAddressAnnotation:         ip,
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+}
+
+func Test_handleUpdateVpcNatGwActive(t *testing.T) {
+	enabled := vpcNatEnabled
+	vpcNatEnabled = "true"
+	t.Cleanup(func() { vpcNatEnabled = enabled })
+
+	gw := &kubeovnv1.VpcNatGateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "gw"},
+		Spec: kubeovnv1.VpcNatSpec{
+			Vpc:          "test-vpc",
+			Subnet:       "test-vpc-subnet",
+			LanIp:        "10.0.0.254",
+			HA:           true,
+			StandbyLanIp: "10.0.0.253",
+		},
+		Status: kubeovnv1.VpcNatGatewayStatus{ActivePod: "vpc-nat-gw-gw-0", ActiveLanIp: "10.0.0.254"},
+	}
+	vpc := &kubeovnv1.Vpc{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpc"},
+		Spec: kubeovnv1.VpcSpec{
+			StaticRoutes: []*kubeovnv1.StaticRoute{{
+				Policy:    kubeovnv1.PolicyDst,
+				CIDR:      "0.0.0.0/0",
+				NextHopIP: "10.0.0.254",
+			}},
+		},
+	}
+	// the node of the active pod is lost, the standby pod has been promoted
+	active := newNatGwPod(gw, "0", "10.0.0.254", natGwRoleActive, false)
+	standby := newNatGwPod(gw, "1", "10.0.0.253", natGwRoleActive, true)
+	ctrl := newFakeController(t, []runtime.Object{active, standby}, []runtime.Object{vpc})
+	gw, err := ctrl.kubeovnClient.KubeovnV1().VpcNatGateways().Create(context.Background(), gw, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, ctrl.kubeovnInformerFactory.Kubeovn().V1().VpcNatGateways().Informer().GetIndexer().Add(gw))
+
+	require.NoError(t, ctrl.handleUpdateVpcNatGwActive(gw.Name))
+	updated, err := ctrl.kubeovnClient.KubeovnV1().VpcNatGateways().Get(context.Background(), gw.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, kubeovnv1.VpcNatGatewayStatus{ActivePod: standby.Name, ActiveLanIp: "10.0.0.253"}, updated.Status)
+	require.Equal(t, 1, ctrl.addOrUpdateVpcQueue.Len())
+
+	// static routes via the lan ip of the gateway are switched to the active pod
+	require.NoError(t, ctrl.kubeovnInformerFactory.Kubeovn().V1().VpcNatGateways().Informer().GetIndexer().Update(updated))
+	require.NoError(t, ctrl.handleAddOrUpdateVpc(vpc.Name))
+	routes, err := ctrl.ovnClient.ListStaticRoutes(vpc.Name)
+	require.NoError(t, err)
+	require.Len(t, routes, 1)
+	require.Equal(t, "10.0.0.253", routes[0].NextHop)
+	cached, err := ctrl.vpcsLister.Get(vpc.Name)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.254", cached.Spec.StaticRoutes[0].NextHopIP)
+}
@@ -36,6 +36,7 @@ func (c *Controller) enqueueAddIptablesEip(obj interface{}) {
 		return
 	}
 	c.addIptablesEipQueue.Add(key)
+	c.enqueueSyncEipQoSPolicyStatus(key)
 }
 
 func (c *Controller) enqueueUpdateIptablesEip(old, new interface{}) {
@@ -52,6 +53,8 @@ func (c *Controller) enqueueUpdateIptablesEip(old, new interface{}) {
 	newEip := new.(*kubeovnv1.IptablesEIP)
 	if !newEip.DeletionTimestamp.IsZero() ||
 		oldEip.Spec.V4ip != newEip.Spec.V4ip ||
+		oldEip.Spec.IngressRate != newEip.Spec.IngressRate ||
+		oldEip.Spec.EgressRate != newEip.Spec.EgressRate ||
 		oldEip.Status.Redo != newEip.Status.Redo {
 		c.updateIptablesEipQueue.Add(key)
 	}
@@ -70,6 +73,7 @@ func (c *Controller) enqueueDelIptablesEip(obj interface{}) {
 	}
 	c.delIptablesEipQueue.Add(key)
 	c.updateSubnetStatusQueue.Add(util.VpcExternalNet)
+	c.enqueueSyncEipQoSPolicyStatus(key)
 }
 
 func (c *Controller) runAddIptablesEipWorker() {
@@ -265,6 +269,9 @@ func (c *Controller) handleAddIptablesEip(key string) error {
 		klog.Errorf("failed to patch status for eip %s, %v", key, err)
 		return err
 	}
+	if err = c.syncIptablesEipQoS(eip, v4ip, false); err != nil {
+		return err
+	}
 	if _, err = c.handleIptablesEipFinalizer(eip, false); err != nil {
 		klog.Errorf("failed to handle finalizer for eip %s, %v", key, err)
 		return err
@@ -346,6 +353,10 @@ func (c *Controller) handleUpdateIptablesEip(key string) error {
 			klog.Errorf("failed to clean eip %s, %v", key, err)
 			return err
 		}
+		if err = c.cleanNatGwQoS(eip.Spec.NatGwDp, "eip", eip.Name, natGwQoSEipPriority, eip.Status.IngressRate, eip.Status.EgressRate); err != nil {
+			klog.Errorf("failed to clean qos of eip '%s' in pod, %v", key, err)
+			return err
+		}
 		if err = c.deleteEipInPod(eip.Spec.NatGwDp, v4Cidr); err != nil {
 			klog.Errorf("failed to clean eip '%s' in pod, %v", key, err)
 			return err
@@ -397,6 +408,9 @@ func (c *Controller) handleUpdateIptablesEip(key string) error {
 			klog.Errorf("failed to clean eip, %v", err)
 			return err
 		}
+		if err = c.syncIptablesEipQoS(eip, v4ip, true); err != nil {
+			return err
+		}
 		if err = c.createOrUpdateCrdEip(key, v4ip, v6ip, mac, eip.Spec.NatGwDp); err != nil {
 			klog.Errorf("failed to update eip %s, %v", key, err)
 			return err
@@ -499,12 +513,21 @@ func (c *Controller) handleUpdateIptablesEip(key string) error {
 			klog.Errorf("failed to create eip, %v", err)
 			return err
 		}
+		if err = c.syncIptablesEipQoS(eip, eip.Status.IP, true); err != nil {
+			return err
+		}
 		if err = c.patchEipStatus(key, "", "", "", true); err != nil {
 			klog.Errorf("failed to patch status for eip %s, %v", key, err)
 			return err
 		}
 		return nil
 	}
+	// rate limits are changed
+	if eip.Status.Ready && eip.Status.IP != "" {
+		if err = c.syncIptablesEipQoS(eip, eip.Status.IP, false); err != nil {
+			return err
+		}
+	}
 	if _, err = c.handleIptablesEipFinalizer(eip, false); err != nil {
 		klog.Errorf("failed to handle finalizer for eip, %v", err)
 		return err
@@ -532,21 +555,21 @@ func (c *Controller) GetEip(eipName string) (*kubeovnv1.IptablesEIP, error) {
 }
 
 func (c *Controller) createEipInPod(dp, gw, v4Cidr string) error {
-	gwPod, err := c.getNatGwPod(dp)
+	gwPods, err := c.getNatGwPods(dp)
 	if err != nil {
 		return err
 	}
 	var addRules []string
 	rule := fmt.Sprintf("%s,%s", v4Cidr, gw)
 	addRules = append(addRules, rule)
-	if err = c.execNatGwRules(gwPod, natGwEipAdd, addRules); err != nil {
+	if err = c.execNatGwRulesInPods(gwPods, natGwEipAdd, addRules); err != nil {
 		return err
 	}
 	return nil
 }
 
 func (c *Controller) deleteEipInPod(dp, v4Cidr string) error {
-	gwPod, err := c.getNatGwPod(dp)
+	gwPods, err := c.getNatGwPods(dp)
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
 			return nil
@@ -556,7 +579,7 @@ func (c *Controller) deleteEipInPod(dp, v4Cidr string) error {
 	var delRules []string
 	rule := v4Cidr
 	delRules = append(delRules, rule)
-	if err = c.execNatGwRules(gwPod, natGwEipDel, delRules); err != nil {
+	if err = c.execNatGwRulesInPods(gwPods, natGwEipDel, delRules); err != nil {
 		return err
 	}
 	return nil
@@ -582,7 +605,7 @@ func (c *Controller) acquireStaticEip(name, namespace, nicName, ip string) (stri
 func (c *Controller) acquireEip(name, namespace, nicName string) (string, string, string, error) {
 	var skippedAddrs []string
 	for {
-		ipv4, ipv6, mac, err := c.ipam.GetRandomAddress(name, nicName, "", util.VpcExternalNet, skippedAddrs, true)
+		ipv4, ipv6, mac, err := c.ipam.GetRandomAddress(name, nicName, "", util.VpcExternalNet, "", skippedAddrs, true)
 		if err != nil {
 			return "", "", "", err
 		}
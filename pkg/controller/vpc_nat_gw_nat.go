@@ -54,6 +54,8 @@ func (c *Controller) enqueueUpdateIptablesFip(old, new interface{}) {
 	}
 	if oldFip.Status.V4ip != newFip.Status.V4ip ||
 		oldFip.Spec.EIP != newFip.Spec.EIP ||
+		oldFip.Spec.IngressRate != newFip.Spec.IngressRate ||
+		oldFip.Spec.EgressRate != newFip.Spec.EgressRate ||
 		oldFip.Status.Redo != newFip.Status.Redo {
 		klog.V(3).Infof("enqueue update fip %s", key)
 		c.updateIptablesFipQueue.Add(key)
@@ -113,6 +115,8 @@ func (c *Controller) enqueueUpdateIptablesDnatRule(old, new interface{}) {
 
 	if oldDnat.Status.V4ip != newDnat.Status.V4ip ||
 		oldDnat.Spec.EIP != newDnat.Spec.EIP ||
+		oldDnat.Spec.IngressRate != newDnat.Spec.IngressRate ||
+		oldDnat.Spec.EgressRate != newDnat.Spec.EgressRate ||
 		oldDnat.Status.Redo != newDnat.Status.Redo {
 		klog.V(3).Infof("enqueue update dnat %s", key)
 		c.updateIptablesDnatRuleQueue.Add(key)
@@ -570,6 +574,9 @@ func (c *Controller) handleAddIptablesFip(key string) error {
 		klog.Errorf("failed to patch status for fip %s, %v", key, err)
 		return err
 	}
+	if err = c.syncIptablesFipQoS(fip, eip.Spec.NatGwDp, eip.Spec.V4ip, false); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -588,6 +595,10 @@ func (c *Controller) handleUpdateIptablesFip(key string) error {
 	// should delete
 	if !fip.DeletionTimestamp.IsZero() {
 		klog.V(3).Infof("clean fip '%s' in pod", key)
+		if err = c.cleanNatGwQoS(fip.Status.NatGwDp, "fip", fip.Name, natGwQoSRulePriority, fip.Status.IngressRate, fip.Status.EgressRate); err != nil {
+			klog.Errorf("failed to clean qos of fip %s, %v", key, err)
+			return err
+		}
 		if err = c.deleteFipInPod(fip.Status.NatGwDp, fip.Status.V4ip, fip.Spec.InternalIp); err != nil {
 			klog.Errorf("failed to delete fip %s, %v", key, err)
 			return err
@@ -635,6 +646,15 @@ func (c *Controller) handleUpdateIptablesFip(key string) error {
 			klog.Errorf("failed to create new fip, %v", err)
 			return err
 		}
+		if fip.Status.NatGwDp != eip.Spec.NatGwDp {
+			if err = c.cleanNatGwQoS(fip.Status.NatGwDp, "fip", fip.Name, natGwQoSRulePriority, fip.Status.IngressRate, fip.Status.EgressRate); err != nil {
+				klog.Errorf("failed to clean old qos of fip %s, %v", key, err)
+				return err
+			}
+		}
+		if err = c.syncIptablesFipQoS(fip, eip.Spec.NatGwDp, eip.Spec.V4ip, true); err != nil {
+			return err
+		}
 		if err = c.natLabelEip(eipName, fip.Name); err != nil {
 			klog.Errorf("failed to label fip '%s' in eip %s, %v", fip.Name, eipName, err)
 			return err
@@ -663,11 +683,20 @@ func (c *Controller) handleUpdateIptablesFip(key string) error {
 			klog.Errorf("failed to create fip, %v", err)
 			return err
 		}
+		if err = c.syncIptablesFipQoS(fip, eip.Spec.NatGwDp, fip.Status.V4ip, true); err != nil {
+			return err
+		}
 		if err = c.patchFipStatus(key, "", "", "", "", true); err != nil {
 			klog.Errorf("failed to patch status for fip %s, %v", key, err)
 			return err
 		}
 	}
+	// rate limits are changed
+	if fip.Status.Ready && fip.Status.V4ip != "" {
+		if err = c.syncIptablesFipQoS(fip, eip.Spec.NatGwDp, fip.Status.V4ip, false); err != nil {
+			return err
+		}
+	}
 	if _, err = c.handleIptablesFipFinalizer(fip, false); err != nil {
 		klog.Errorf("failed to handle finalizer for eip %s, %v", key, err)
 		return err
@@ -744,6 +773,9 @@ func (c *Controller) handleAddIptablesDnatRule(key string) error {
 		klog.Errorf("failed to patch status for dnat %s, %v", key, err)
 		return err
 	}
+	if err = c.syncIptablesDnatQoS(dnat, eip.Spec.NatGwDp, eip.Spec.V4ip, false); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -762,6 +794,10 @@ func (c *Controller) handleUpdateIptablesDnatRule(key string) error {
 	// should delete
 	if !dnat.DeletionTimestamp.IsZero() {
 		klog.V(3).Infof("clean dnat '%s' in pod", key)
+		if err = c.cleanNatGwQoS(dnat.Status.NatGwDp, "dnat", dnat.Name, natGwQoSRulePriority, dnat.Status.IngressRate, dnat.Status.EgressRate); err != nil {
+			klog.Errorf("failed to clean qos of dnat %s, %v", key, err)
+			return err
+		}
 		if err = c.deleteDnatInPod(dnat.Status.NatGwDp, dnat.Spec.Protocol,
 			dnat.Status.V4ip, dnat.Spec.InternalIp,
 			dnat.Spec.ExternalPort, dnat.Spec.InternalPort,
@@ -812,6 +848,15 @@ func (c *Controller) handleUpdateIptablesDnatRule(key string) error {
 			klog.Errorf("failed to create new dnat %s, %v", key, err)
 			return err
 		}
+		if dnat.Status.NatGwDp != eip.Spec.NatGwDp {
+			if err = c.cleanNatGwQoS(dnat.Status.NatGwDp, "dnat", dnat.Name, natGwQoSRulePriority, dnat.Status.IngressRate, dnat.Status.EgressRate); err != nil {
+				klog.Errorf("failed to clean old qos of dnat %s, %v", key, err)
+				return err
+			}
+		}
+		if err = c.syncIptablesDnatQoS(dnat, eip.Spec.NatGwDp, eip.Spec.V4ip, true); err != nil {
+			return err
+		}
 		if err = c.natLabelEip(eipName, dnat.Name); err != nil {
 			klog.Errorf("failed to label dnat '%s' in eip %s, %v", dnat.Name, eipName, err)
 			return err
@@ -842,11 +887,20 @@ func (c *Controller) handleUpdateIptablesDnatRule(key string) error {
 			klog.Errorf("failed to create dnat %s, %v", key, err)
 			return err
 		}
+		if err = c.syncIptablesDnatQoS(dnat, eip.Spec.NatGwDp, dnat.Status.V4ip, true); err != nil {
+			return err
+		}
 		if err = c.patchDnatStatus(key, "", "", "", "", true); err != nil {
 			klog.Errorf("failed to patch status for dnat %s, %v", key, err)
 			return err
 		}
 	}
+	// rate limits are changed
+	if dnat.Status.Ready && dnat.Status.V4ip != "" {
+		if err = c.syncIptablesDnatQoS(dnat, eip.Spec.NatGwDp, dnat.Status.V4ip, false); err != nil {
+			return err
+		}
+	}
 	if _, err = c.handleIptablesDnatRuleFinalizer(dnat, false); err != nil {
 		klog.Errorf("failed to handle finalizer for dnat %s, %v", key, err)
 		return err
@@ -1541,14 +1595,14 @@ func (c *Controller) redoSnat(key, redo string, eipReady bool) error {
 }
 
 func (c *Controller) createFipInPod(dp, v4ip, internalIP string) error {
-	gwPod, err := c.getNatGwPod(dp)
+	gwPods, err := c.getNatGwPods(dp)
 	if err != nil {
 		return err
 	}
 	var addRules []string
 	rule := fmt.Sprintf("%s,%s", v4ip, internalIP)
 	addRules = append(addRules, rule)
-	if err = c.execNatGwRules(gwPod, natGwSubnetFipAdd, addRules); err != nil {
+	if err = c.execNatGwRulesInPods(gwPods, natGwSubnetFipAdd, addRules); err != nil {
 		klog.Errorf("failed to create fip, err: %v", err)
 		return err
 	}
@@ -1556,7 +1610,7 @@ func (c *Controller) createFipInPod(dp, v4ip, internalIP string) error {
 }
 
 func (c *Controller) deleteFipInPod(dp, v4ip, internalIP string) error {
-	gwPod, err := c.getNatGwPod(dp)
+	gwPods, err := c.getNatGwPods(dp)
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
 			return nil
@@ -1567,7 +1621,7 @@ func (c *Controller) deleteFipInPod(dp, v4ip, internalIP string) error {
 	var delRules []string
 	rule := fmt.Sprintf("%s,%s", v4ip, internalIP)
 	delRules = append(delRules, rule)
-	if err = c.execNatGwRules(gwPod, natGwSubnetFipDel, delRules); err != nil {
+	if err = c.execNatGwRulesInPods(gwPods, natGwSubnetFipDel, delRules); err != nil {
 		klog.Errorf("failed to delete fip, err: %v", err)
 		return err
 	}
@@ -1575,7 +1629,7 @@ func (c *Controller) deleteFipInPod(dp, v4ip, internalIP string) error {
 }
 
 func (c *Controller) createDnatInPod(dp, protocol, v4ip, internalIp, externalPort, internalPort string) error {
-	gwPod, err := c.getNatGwPod(dp)
+	gwPods, err := c.getNatGwPods(dp)
 	if err != nil {
 		klog.Errorf("failed to get nat gw pod, %v", err)
 		return err
@@ -1584,7 +1638,7 @@ func (c *Controller) createDnatInPod(dp, protocol, v4ip, internalIp, externalPor
 	rule := fmt.Sprintf("%s,%s,%s,%s,%s", v4ip, externalPort, protocol, internalIp, internalPort)
 	addRules = append(addRules, rule)
 
-	if err = c.execNatGwRules(gwPod, natGwDnatAdd, addRules); err != nil {
+	if err = c.execNatGwRulesInPods(gwPods, natGwDnatAdd, addRules); err != nil {
 		klog.Errorf("failed to create dnat, err: %v", err)
 		return err
 	}
@@ -1592,7 +1646,7 @@ func (c *Controller) createDnatInPod(dp, protocol, v4ip, internalIp, externalPor
 }
 
 func (c *Controller) deleteDnatInPod(dp, protocol, v4ip, internalIp, externalPort, internalPort string) error {
-	gwPod, err := c.getNatGwPod(dp)
+	gwPods, err := c.getNatGwPods(dp)
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
 			return nil
@@ -1604,7 +1658,7 @@ func (c *Controller) deleteDnatInPod(dp, protocol, v4ip, internalIp, externalPor
 	var delRules []string
 	rule := fmt.Sprintf("%s,%s,%s,%s,%s", v4ip, externalPort, protocol, internalIp, internalPort)
 	delRules = append(delRules, rule)
-	if err = c.execNatGwRules(gwPod, natGwDnatDel, delRules); err != nil {
+	if err = c.execNatGwRulesInPods(gwPods, natGwDnatDel, delRules); err != nil {
 		klog.Errorf("failed to delete dnat, err: %v", err)
 		return err
 	}
@@ -1612,7 +1666,7 @@ func (c *Controller) deleteDnatInPod(dp, protocol, v4ip, internalIp, externalPor
 }
 
 func (c *Controller) createSnatInPod(dp, v4ip, internalCIDR string) error {
-	gwPod, err := c.getNatGwPod(dp)
+	gwPods, err := c.getNatGwPods(dp)
 	if err != nil {
 		klog.Errorf("failed to get nat gw pod, %v", err)
 		return err
@@ -1620,7 +1674,7 @@ func (c *Controller) createSnatInPod(dp, v4ip, internalCIDR string) error {
 	var rules []string
 	rule := fmt.Sprintf("%s,%s", v4ip, internalCIDR)
 
-	version, err := c.getIptablesVersion(gwPod)
+	version, err := c.getIptablesVersion(gwPods[0])
 	if err != nil {
 		version = "1.0.0"
 		klog.Warningf("failed to checking iptables version, assuming version at least %s: %v", version, err)
@@ -1630,7 +1684,7 @@ func (c *Controller) createSnatInPod(dp, v4ip, internalCIDR string) error {
 	}
 
 	rules = append(rules, rule)
-	if err = c.execNatGwRules(gwPod, natGwSnatAdd, rules); err != nil {
+	if err = c.execNatGwRulesInPods(gwPods, natGwSnatAdd, rules); err != nil {
 		klog.Errorf("failed to exec nat gateway rule, err: %v", err)
 		return err
 	}
@@ -1638,7 +1692,7 @@ func (c *Controller) createSnatInPod(dp, v4ip, internalCIDR string) error {
 }
 
 func (c *Controller) deleteSnatInPod(dp, v4ip, internalCIDR string) error {
-	gwPod, err := c.getNatGwPod(dp)
+	gwPods, err := c.getNatGwPods(dp)
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
 			return nil
@@ -1649,7 +1703,7 @@ func (c *Controller) deleteSnatInPod(dp, v4ip, internalCIDR string) error {
 	var delRules []string
 	rule := fmt.Sprintf("%s,%s", v4ip, internalCIDR)
 	delRules = append(delRules, rule)
-	if err = c.execNatGwRules(gwPod, natGwSnatDel, delRules); err != nil {
+	if err = c.execNatGwRulesInPods(gwPods, natGwSnatDel, delRules); err != nil {
 		klog.Errorf("failed to delete snat, err: %v", err)
 		return err
 	}
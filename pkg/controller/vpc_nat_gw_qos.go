@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+)
+
+const (
+	natGwQoSIngress = "ingress"
+	natGwQoSEgress  = "egress"
+
+	// filters of fips and dnat rules are matched before the ones of eips
+	natGwQoSRulePriority = 1
+	natGwQoSEipPriority  = 2
+)
+
+// natGwQoS describes the rate limits of an eip, fip or dnat rule in the nat gateway pod,
+// the traffic of dnat rules is matched by the protocol and the external port
+type natGwQoS struct {
+	priority    int
+	handle      uint32
+	v4ip        string
+	protocol    string
+	port        string
+	ingressRate string
+	egressRate  string
+}
+
+// natGwQoSHandle returns the handle of the tc filters of the object, which is stable across updates
+func natGwQoSHandle(kind, name string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(kind + "/" + name))
+	if handle := h.Sum32(); handle != 0 {
+		return handle
+	}
+	return 1
+}
+
+func validateNatGwRate(rate string) error {
+	if rate == "" {
+		return nil
+	}
+	if v, err := strconv.Atoi(rate); err != nil || v <= 0 {
+		return fmt.Errorf("invalid rate '%s', should be a positive integer in Mbit/s", rate)
+	}
+	return nil
+}
+
+// rules generates the rules to add the filters of the limited directions and delete the others
+func (q natGwQoS) rules() (addRules, delRules []string) {
+	for _, dir := range []struct{ name, rate string }{{natGwQoSIngress, q.ingressRate}, {natGwQoSEgress, q.egressRate}} {
+		if dir.rate == "" {
+			delRules = append(delRules, fmt.Sprintf("%s,%d,%d", dir.name, q.priority, q.handle))
+			continue
+		}
+		rule := fmt.Sprintf("%s,%d,%d,%s,%s", dir.name, q.priority, q.handle, q.v4ip, dir.rate)
+		if q.port != "" {
+			rule = fmt.Sprintf("%s,%s,%s", rule, strings.ToLower(q.protocol), q.port)
+		}
+		addRules = append(addRules, rule)
+	}
+	return addRules, delRules
+}
+
+func (c *Controller) setNatGwQoS(dp string, qos natGwQoS) error {
+	if err := validateNatGwRate(qos.ingressRate); err != nil {
+		return err
+	}
+	if err := validateNatGwRate(qos.egressRate); err != nil {
+		return err
+	}
+	gwPods, err := c.getNatGwPods(dp)
+	if err != nil {
+		if k8serrors.IsNotFound(err) && qos.ingressRate == "" && qos.egressRate == "" {
+			return nil
+		}
+		return err
+	}
+	addRules, delRules := qos.rules()
+	if len(delRules) != 0 {
+		if err = c.execNatGwRulesInPods(gwPods, natGwEipQoSDel, delRules); err != nil {
+			klog.Errorf("failed to delete qos, err: %v", err)
+			return err
+		}
+	}
+	if len(addRules) != 0 {
+		if err = c.execNatGwRulesInPods(gwPods, natGwEipQoSAdd, addRules); err != nil {
+			klog.Errorf("failed to add qos, err: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// natGwQoSChanged checks whether the rate limits in spec are not applied yet, all of them
+// should be applied again once the nat gateway pod or the address is changed
+func natGwQoSChanged(specIngress, specEgress, statusIngress, statusEgress string, reapply bool) bool {
+	if specIngress != statusIngress || specEgress != statusEgress {
+		return true
+	}
+	return reapply && (specIngress != "" || specEgress != "")
+}
+
+func natGwQoSStatusPatch(ingressRate, egressRate string) []byte {
+	// the empty rates are kept in the patch to clean the applied ones
+	return []byte(fmt.Sprintf(`{"status":{"ingressRate":%q,"egressRate":%q}}`, ingressRate, egressRate))
+}
+
+// syncIptablesEipQoS applies the rate limits in spec of the eip or the ones of the qos policy bound to it
+func (c *Controller) syncIptablesEipQoS(eip *kubeovnv1.IptablesEIP, v4ip string, reapply bool) error {
+	ingressRate, egressRate, err := c.getIptablesEipRates(eip)
+	if err != nil {
+		return err
+	}
+	if !natGwQoSChanged(ingressRate, egressRate, eip.Status.IngressRate, eip.Status.EgressRate, reapply) {
+		return nil
+	}
+	klog.V(3).Infof("set qos of eip %s, ingress rate '%s', egress rate '%s'", eip.Name, ingressRate, egressRate)
+	if err = c.setNatGwQoS(eip.Spec.NatGwDp, natGwQoS{
+		priority:    natGwQoSEipPriority,
+		handle:      natGwQoSHandle("eip", eip.Name),
+		v4ip:        v4ip,
+		ingressRate: ingressRate,
+		egressRate:  egressRate,
+	}); err != nil {
+		klog.Errorf("failed to set qos of eip %s, %v", eip.Name, err)
+		return err
+	}
+	if _, err = c.config.KubeOvnClient.KubeovnV1().IptablesEIPs().Patch(context.Background(), eip.Name, types.MergePatchType,
+		natGwQoSStatusPatch(ingressRate, egressRate), metav1.PatchOptions{}, "status"); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		klog.Errorf("failed to patch qos status of eip %s, %v", eip.Name, err)
+		return err
+	}
+	return nil
+}
+
+func (c *Controller) syncIptablesFipQoS(fip *kubeovnv1.IptablesFIPRule, natGwDp, v4ip string, reapply bool) error {
+	if !natGwQoSChanged(fip.Spec.IngressRate, fip.Spec.EgressRate, fip.Status.IngressRate, fip.Status.EgressRate, reapply) {
+		return nil
+	}
+	klog.V(3).Infof("set qos of fip %s, ingress rate '%s', egress rate '%s'", fip.Name, fip.Spec.IngressRate, fip.Spec.EgressRate)
+	if err := c.setNatGwQoS(natGwDp, natGwQoS{
+		priority:    natGwQoSRulePriority,
+		handle:      natGwQoSHandle("fip", fip.Name),
+		v4ip:        v4ip,
+		ingressRate: fip.Spec.IngressRate,
+		egressRate:  fip.Spec.EgressRate,
+	}); err != nil {
+		klog.Errorf("failed to set qos of fip %s, %v", fip.Name, err)
+		return err
+	}
+	if _, err := c.config.KubeOvnClient.KubeovnV1().IptablesFIPRules().Patch(context.Background(), fip.Name, types.MergePatchType,
+		natGwQoSStatusPatch(fip.Spec.IngressRate, fip.Spec.EgressRate), metav1.PatchOptions{}, "status"); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		klog.Errorf("failed to patch qos status of fip %s, %v", fip.Name, err)
+		return err
+	}
+	return nil
+}
+
+func (c *Controller) syncIptablesDnatQoS(dnat *kubeovnv1.IptablesDnatRule, natGwDp, v4ip string, reapply bool) error {
+	if !natGwQoSChanged(dnat.Spec.IngressRate, dnat.Spec.EgressRate, dnat.Status.IngressRate, dnat.Status.EgressRate, reapply) {
+		return nil
+	}
+	klog.V(3).Infof("set qos of dnat %s, ingress rate '%s', egress rate '%s'", dnat.Name, dnat.Spec.IngressRate, dnat.Spec.EgressRate)
+	if err := c.setNatGwQoS(natGwDp, natGwQoS{
+		priority:    natGwQoSRulePriority,
+		handle:      natGwQoSHandle("dnat", dnat.Name),
+		v4ip:        v4ip,
+		protocol:    dnat.Spec.Protocol,
+		port:        dnat.Spec.ExternalPort,
+		ingressRate: dnat.Spec.IngressRate,
+		egressRate:  dnat.Spec.EgressRate,
+	}); err != nil {
+		klog.Errorf("failed to set qos of dnat %s, %v", dnat.Name, err)
+		return err
+	}
+	if _, err := c.config.KubeOvnClient.KubeovnV1().IptablesDnatRules().Patch(context.Background(), dnat.Name, types.MergePatchType,
+		natGwQoSStatusPatch(dnat.Spec.IngressRate, dnat.Spec.EgressRate), metav1.PatchOptions{}, "status"); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		klog.Errorf("failed to patch qos status of dnat %s, %v", dnat.Name, err)
+		return err
+	}
+	return nil
+}
+
+// cleanNatGwQoS deletes the filters of an object being deleted if any rate limit is applied
+func (c *Controller) cleanNatGwQoS(dp, kind, name string, priority int, ingressRate, egressRate string) error {
+	if ingressRate == "" && egressRate == "" {
+		return nil
+	}
+	klog.V(3).Infof("clean qos of %s %s", kind, name)
+	return c.setNatGwQoS(dp, natGwQoS{priority: priority, handle: natGwQoSHandle(kind, name)})
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_natGwQoSRules(t *testing.T) {
+	handle := natGwQoSHandle("dnat", "dnat-http")
+	require.Equal(t, handle, natGwQoSHandle("dnat", "dnat-http"))
+	require.NotEqual(t, handle, natGwQoSHandle("fip", "dnat-http"))
+
+	tests := []struct {
+		name    string
+		qos     natGwQoS
+		addRule []string
+		delRule []string
+	}{
+		{
+			name:    "eip",
+			qos:     natGwQoS{priority: natGwQoSEipPriority, handle: 10, v4ip: "172.18.0.10", ingressRate: "10", egressRate: "20"},
+			addRule: []string{"ingress,2,10,172.18.0.10,10", "egress,2,10,172.18.0.10,20"},
+		},
+		{
+			name:    "dnat ingress only",
+			qos:     natGwQoS{priority: natGwQoSRulePriority, handle: handle, v4ip: "172.18.0.10", protocol: "TCP", port: "80", ingressRate: "5"},
+			addRule: []string{fmt.Sprintf("ingress,1,%d,172.18.0.10,5,tcp,80", handle)},
+			delRule: []string{fmt.Sprintf("egress,1,%d", handle)},
+		},
+		{
+			name:    "clean",
+			qos:     natGwQoS{priority: natGwQoSRulePriority, handle: 10},
+			delRule: []string{"ingress,1,10", "egress,1,10"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addRules, delRules := tt.qos.rules()
+			require.Equal(t, tt.addRule, addRules)
+			require.Equal(t, tt.delRule, delRules)
+		})
+	}
+
+	require.NoError(t, validateNatGwRate(""))
+	require.NoError(t, validateNatGwRate("100"))
+	require.Error(t, validateNatGwRate("0"))
+	require.Error(t, validateNatGwRate("10M"))
+
+	require.False(t, natGwQoSChanged("10", "", "10", "", false))
+	require.True(t, natGwQoSChanged("10", "", "10", "", true))
+	require.True(t, natGwQoSChanged("", "", "10", "", false))
+	require.False(t, natGwQoSChanged("", "", "", "", true))
+}
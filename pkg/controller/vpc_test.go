@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func Test_handleAddOrUpdateVpc(t *testing.T) {
+	vpc := &kubeovnv1.Vpc{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpc"},
+		Spec: kubeovnv1.VpcSpec{
+			Namespaces: []string{"test"},
+			StaticRoutes: []*kubeovnv1.StaticRoute{{
+				Policy:    kubeovnv1.PolicyDst,
+				CIDR:      "0.0.0.0/0",
+				NextHopIP: "10.0.0.254",
+			}},
+		},
+	}
+	subnet := &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpc-subnet"},
+		Spec: kubeovnv1.SubnetSpec{
+			Vpc:       vpc.Name,
+			CIDRBlock: "10.0.0.0/24",
+			Gateway:   "10.0.0.1",
+			Protocol:  kubeovnv1.ProtocolIPv4,
+		},
+	}
+	ctrl := newFakeController(t, nil, []runtime.Object{vpc, subnet})
+
+	require.NoError(t, ctrl.handleAddOrUpdateVpc(vpc.Name))
+
+	exists, err := ctrl.ovnClient.LogicalRouterExists(vpc.Name)
+	require.NoError(t, err)
+	require.True(t, exists)
+	routes, err := ctrl.ovnClient.ListStaticRoutes(vpc.Name)
+	require.NoError(t, err)
+	require.Len(t, routes, 1)
+	require.Equal(t, "0.0.0.0/0", routes[0].CIDR)
+	require.Equal(t, "10.0.0.254", routes[0].NextHop)
+
+	updated, err := ctrl.kubeovnClient.KubeovnV1().Vpcs().Get(context.Background(), vpc.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.True(t, updated.Status.Standby)
+	require.Equal(t, vpc.Name, updated.Status.Router)
+	require.Equal(t, 1, ctrl.addOrUpdateSubnetQueue.Len())
+
+	// the stale static route is replaced
+	updated.Spec.StaticRoutes[0].NextHopIP = "10.0.0.253"
+	_, err = ctrl.kubeovnClient.KubeovnV1().Vpcs().Update(context.Background(), updated, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, ctrl.handleAddOrUpdateVpc(vpc.Name))
+	routes, err = ctrl.ovnClient.ListStaticRoutes(vpc.Name)
+	require.NoError(t, err)
+	require.Len(t, routes, 1)
+	require.Equal(t, "10.0.0.253", routes[0].NextHop)
+	require.Len(t, ctrl.legacyClient.Calls("CreateLogicalRouter"), 1)
+}
+
+func Test_handleAddOrUpdateVpcExternal(t *testing.T) {
+	vpc := &kubeovnv1.Vpc{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-vpc"},
+		Spec:       kubeovnv1.VpcSpec{EnableExternal: true},
+	}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "gw-node", Labels: map[string]string{util.ExGatewayLabel: "true"}},
+	}
+	external := &kubeovnv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: util.VpcExternalNet},
+		Spec: kubeovnv1.SubnetSpec{
+			CIDRBlock:  "172.18.0.0/24",
+			Gateway:    "172.18.0.1",
+			ExcludeIps: []string{"172.18.0.1"},
+			Protocol:   kubeovnv1.ProtocolIPv4,
+		},
+	}
+	ctrl := newFakeController(t, []runtime.Object{node}, []runtime.Object{vpc, external})
+	ctrl.legacyClient.Chassis[node.Name] = "chassis-1"
+	require.NoError(t, ctrl.ipam.AddOrUpdateSubnet(external.Name, external.Spec.CIDRBlock, external.Spec.Gateway, external.Spec.ExcludeIps))
+	require.NoError(t, ctrl.legacyClient.CreateLogicalSwitch(external.Name, "", external.Spec.CIDRBlock, external.Spec.Gateway, false))
+
+	// the gateway port is created once the address of the eip is allocated
+	require.NoError(t, ctrl.handleAddOrUpdateVpc(vpc.Name))
+	lrpName := ovs.LogicalRouterPortName(vpc.Name, util.VpcExternalNet)
+	eip, err := ctrl.kubeovnClient.KubeovnV1().OvnEips().Get(context.Background(), lrpName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, ovnEipTypeLrp, eip.Spec.Type)
+	exists, err := ctrl.ovnClient.LogicalRouterPortExists(lrpName)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	require.NoError(t, ctrl.kubeovnInformerFactory.Kubeovn().V1().OvnEips().Informer().GetIndexer().Add(eip))
+	require.NoError(t, ctrl.handleAddOrUpdateOvnEip(lrpName))
+	eip, err = ctrl.kubeovnClient.KubeovnV1().OvnEips().Get(context.Background(), lrpName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NoError(t, ctrl.handleAddOrUpdateVpc(vpc.Name))
+
+	lrp, err := ctrl.ovnClient.GetLogicalRouterPort(lrpName, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{eip.Status.V4ip + "/24"}, lrp.Networks)
+	require.Equal(t, eip.Status.MacAddress, lrp.MAC)
+	require.Len(t, lrp.GatewayChassis, 1)
+	lsp, err := ctrl.ovnClient.GetLogicalSwitchPort(ovs.LogicalSwitchPortName(vpc.Name, util.VpcExternalNet), false)
+	require.NoError(t, err)
+	require.Equal(t, "router", lsp.Type)
+	require.Equal(t, "router", lsp.Options["nat-addresses"])
+	routes, err := ctrl.ovnClient.ListStaticRoutes(vpc.Name)
+	require.NoError(t, err)
+	require.Len(t, routes, 1)
+	require.Equal(t, "0.0.0.0/0", routes[0].CIDR)
+	require.Equal(t, "172.18.0.1", routes[0].NextHop)
+
+	require.NoError(t, ctrl.handleDelVpcExternal(vpc.Name))
+	require.True(t, ctrl.legacyClient.Called("DeleteLogicalRouterPort"))
+	_, err = ctrl.kubeovnClient.KubeovnV1().OvnEips().Get(context.Background(), lrpName, metav1.GetOptions{})
+	require.True(t, k8serrors.IsNotFound(err))
+}
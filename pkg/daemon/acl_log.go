@@ -0,0 +1,238 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// aclLogPattern matches the acl logs of ovn-controller like
+// 2023-01-01T00:00:00.000Z|00001|acl_log(ovn_pinctrl0)|INFO|name="np:0123456789abcdef", verdict=drop, severity=warning, direction=to-lport: tcp,...,nw_src=10.16.0.2,...
+var aclLogPattern = regexp.MustCompile(`^([^|]+)\|[^|]*\|acl_log\([^)]*\)\|[^|]*\|name=("[^"]*"|[^,]*), verdict=([^,]+), severity=([^,:]+)(?:, direction=([^:]+))?: (.*)$`)
+
+// AclLogEvent is the structured acl log traced back to the network policy or security group owning the acl
+type AclLogEvent struct {
+	Time       string `json:"time"`
+	Node       string `json:"node"`
+	PolicyType string `json:"policyType"`
+	Namespace  string `json:"namespace,omitempty"`
+	Policy     string `json:"policy"`
+	Direction  string `json:"direction"`
+	Verdict    string `json:"verdict"`
+	Severity   string `json:"severity"`
+	Protocol   string `json:"protocol"`
+	Src        string `json:"src,omitempty"`
+	Dst        string `json:"dst,omitempty"`
+	SrcPort    int    `json:"srcPort,omitempty"`
+	DstPort    int    `json:"dstPort,omitempty"`
+}
+
+// parseAclLog returns the event of the acl log line, nil is returned if the line is not an acl log of any policy.
+// The owner of the acl is resolved from the acl name by resolveOwner, and the packets allowed by the default drop
+// acls of network policies in audit mode have the verdict audit
+func parseAclLog(line string, resolveOwner func(name string) string) *AclLogEvent {
+	matches := aclLogPattern.FindStringSubmatch(line)
+	if matches == nil {
+		return nil
+	}
+	name := strings.Trim(matches[2], `"`)
+	ownerType, _ := ovs.ParseAclLogName(name)
+	verdict := matches[3]
+	switch ownerType {
+	case util.AclLogOwnerTypeNp, util.AclLogOwnerTypeSg:
+	case util.AclLogOwnerTypeNpAudit:
+		ownerType, verdict = util.AclLogOwnerTypeNp, util.AclLogVerdictAudit
+	default:
+		return nil
+	}
+	owner := resolveOwner(name)
+	if owner == "" {
+		return nil
+	}
+
+	event := &AclLogEvent{
+		Time:       matches[1],
+		PolicyType: ownerType,
+		Policy:     owner,
+		Verdict:    verdict,
+		Severity:   matches[4],
+	}
+	if ownerType == util.AclLogOwnerTypeNp {
+		if idx := strings.Index(owner, "/"); idx >= 0 {
+			event.Namespace, event.Policy = owner[:idx], owner[idx+1:]
+		}
+	}
+	switch matches[5] {
+	case "to-lport":
+		event.Direction = "ingress"
+	case "from-lport":
+		event.Direction = "egress"
+	default:
+		event.Direction = matches[5]
+	}
+
+	// the flow is the protocol followed by the comma separated fields
+	fields := strings.Split(matches[6], ",")
+	event.Protocol = fields[0]
+	for _, field := range fields[1:] {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "nw_src", "ipv6_src":
+			event.Src = kv[1]
+		case "nw_dst", "ipv6_dst":
+			event.Dst = kv[1]
+		case "tp_src":
+			event.SrcPort, _ = strconv.Atoi(kv[1])
+		case "tp_dst":
+			event.DstPort, _ = strconv.Atoi(kv[1])
+		}
+	}
+	return event
+}
+
+// logTailer reads the lines appended to the log file, the file rotated by renaming or truncating is followed
+type logTailer struct {
+	path    string
+	file    *os.File
+	reader  *bufio.Reader
+	offset  int64
+	partial string
+}
+
+// open opens the log file at the end for the first time or at the beginning after the log is rotated
+func (t *logTailer) open(fromEnd bool) error {
+	file, err := os.Open(t.path)
+	if err != nil {
+		return err
+	}
+	offset := int64(0)
+	if fromEnd {
+		if offset, err = file.Seek(0, io.SeekEnd); err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+	t.file, t.reader, t.offset, t.partial = file, bufio.NewReader(file), offset, ""
+	return nil
+}
+
+// readLines calls handle for each complete line appended since the last call
+func (t *logTailer) readLines(handle func(line string)) error {
+	if t.file == nil {
+		if err := t.open(true); err != nil {
+			return err
+		}
+	}
+
+	for {
+		line, err := t.reader.ReadString('\n')
+		t.offset += int64(len(line))
+		if err == nil {
+			handle(t.partial + strings.TrimSuffix(line, "\n"))
+			t.partial = ""
+			continue
+		}
+		t.partial += line
+		if err != io.EOF {
+			return err
+		}
+		break
+	}
+
+	current, err := t.file.Stat()
+	if err != nil {
+		return err
+	}
+	latest, err := os.Stat(t.path)
+	if err != nil {
+		// the log is being rotated
+		return nil
+	}
+	if !os.SameFile(current, latest) {
+		_ = t.file.Close()
+		return t.open(false)
+	}
+	if latest.Size() < t.offset {
+		if _, err = t.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		t.reader.Reset(t.file)
+		t.offset, t.partial = 0, ""
+	}
+	return nil
+}
+
+// aclLogOwnerResolver resolves the owners of the acl log names by the external ids of the acls in the ovn nb,
+// the owners are cached as the names are derived from them
+type aclLogOwnerResolver struct {
+	client *ovs.LegacyClient
+	owners map[string]string
+}
+
+func (r *aclLogOwnerResolver) resolve(name string) string {
+	if owner, ok := r.owners[name]; ok {
+		return owner
+	}
+	owner, err := r.client.GetAclLogOwner(name)
+	if err != nil {
+		klog.Errorf("failed to resolve the owner of acl %s, %v", name, err)
+		return ""
+	}
+	if owner != "" {
+		r.owners[name] = owner
+	}
+	return owner
+}
+
+// runAclLogCollector collects the acl logs of ovn-controller, writes them as json lines and counts them by policy
+func (c *Controller) runAclLogCollector(stopCh <-chan struct{}) {
+	if c.config.OvnNbAddr == "" {
+		klog.Error("the acl log collector requires --ovn-nb-addr to resolve the owners of the acls")
+		return
+	}
+	resolver := &aclLogOwnerResolver{
+		client: &ovs.LegacyClient{OvnNbAddress: c.config.OvnNbAddr, OvnTimeout: c.config.OvnTimeout},
+		owners: make(map[string]string),
+	}
+
+	output, err := os.OpenFile(c.config.AclLogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		klog.Errorf("failed to open acl log file %s, %v", c.config.AclLogFile, err)
+		return
+	}
+	defer output.Close()
+
+	encoder := json.NewEncoder(output)
+	tailer := &logTailer{path: c.config.OvnControllerLogFile}
+	klog.Infof("start collecting acl logs from %s", c.config.OvnControllerLogFile)
+	wait.Until(func() {
+		err := tailer.readLines(func(line string) {
+			event := parseAclLog(line, resolver.resolve)
+			if event == nil {
+				return
+			}
+			event.Node = c.config.NodeName
+			aclLogPacketsTotal.WithLabelValues(c.config.NodeName, event.PolicyType, event.Namespace, event.Policy, event.Direction, event.Verdict).Inc()
+			if err := encoder.Encode(event); err != nil {
+				klog.Errorf("failed to write acl log, %v", err)
+			}
+		})
+		if err != nil {
+			klog.Errorf("failed to read %s, %v", c.config.OvnControllerLogFile, err)
+		}
+	}, time.Second, stopCh)
+}
@@ -0,0 +1,110 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseAclLog(t *testing.T) {
+	owners := map[string]string{
+		"np:1111111111111111":       "default/web",
+		"np-audit:1111111111111111": "default/web",
+		"sg:2222222222222222":       "github",
+	}
+	resolve := func(name string) string { return owners[name] }
+
+	line := `2023-01-01T00:00:00.000Z|00012|acl_log(ovn_pinctrl0)|INFO|name="np:1111111111111111", verdict=drop, severity=warning, direction=to-lport: tcp,vlan_tci=0x0000,dl_src=00:00:00:00:00:01,dl_dst=00:00:00:00:00:02,nw_src=10.16.0.2,nw_dst=10.16.0.3,nw_tos=0,nw_ecn=0,nw_ttl=63,tp_src=45678,tp_dst=80,tcp_flags=syn`
+	require.Equal(t, &AclLogEvent{
+		Time:       "2023-01-01T00:00:00.000Z",
+		PolicyType: "np",
+		Namespace:  "default",
+		Policy:     "web",
+		Direction:  "ingress",
+		Verdict:    "drop",
+		Severity:   "warning",
+		Protocol:   "tcp",
+		Src:        "10.16.0.2",
+		Dst:        "10.16.0.3",
+		SrcPort:    45678,
+		DstPort:    80,
+	}, parseAclLog(line, resolve))
+
+	line = `2023-01-01T00:00:00.000Z|00013|acl_log(ovn_pinctrl0)|INFO|name="sg:2222222222222222", verdict=allow, severity=info, direction=from-lport: icmp6,vlan_tci=0x0000,dl_src=00:00:00:00:00:01,dl_dst=00:00:00:00:00:02,ipv6_src=fd00::2,ipv6_dst=fd00::3,icmp_type=128,icmp_code=0`
+	require.Equal(t, &AclLogEvent{
+		Time:       "2023-01-01T00:00:00.000Z",
+		PolicyType: "sg",
+		Policy:     "github",
+		Direction:  "egress",
+		Verdict:    "allow",
+		Severity:   "info",
+		Protocol:   "icmp6",
+		Src:        "fd00::2",
+		Dst:        "fd00::3",
+	}, parseAclLog(line, resolve))
+
+	// packets which would be dropped by the network policy in audit mode
+	line = `2023-01-01T00:00:00.000Z|00014|acl_log(ovn_pinctrl0)|INFO|name="np-audit:1111111111111111", verdict=allow, severity=warning, direction=from-lport: udp,vlan_tci=0x0000,dl_src=00:00:00:00:00:01,dl_dst=00:00:00:00:00:02,nw_src=10.16.0.2,nw_dst=10.16.0.10,nw_tos=0,nw_ecn=0,nw_ttl=64,tp_src=45678,tp_dst=53`
+	require.Equal(t, &AclLogEvent{
+		Time:       "2023-01-01T00:00:00.000Z",
+		PolicyType: "np",
+		Namespace:  "default",
+		Policy:     "web",
+		Direction:  "egress",
+		Verdict:    "audit",
+		Severity:   "warning",
+		Protocol:   "udp",
+		Src:        "10.16.0.2",
+		Dst:        "10.16.0.10",
+		SrcPort:    45678,
+		DstPort:    53,
+	}, parseAclLog(line, resolve))
+
+	// acls not owned by any policy and other logs are ignored
+	require.Nil(t, parseAclLog(`2023-01-01T00:00:00.000Z|00014|acl_log(ovn_pinctrl0)|INFO|name=<unnamed>, verdict=drop, severity=alert, direction=to-lport: tcp,nw_src=10.16.0.2`, resolve))
+	// acls whose owners are not found
+	require.Nil(t, parseAclLog(`2023-01-01T00:00:00.000Z|00016|acl_log(ovn_pinctrl0)|INFO|name="np:3333333333333333", verdict=drop, severity=warning, direction=to-lport: tcp,nw_src=10.16.0.2`, resolve))
+	require.Nil(t, parseAclLog(`2023-01-01T00:00:00.000Z|00015|binding|INFO|Claiming lport web.default for this chassis.`, resolve))
+}
+
+func Test_logTailer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ovn-controller.log")
+	require.NoError(t, os.WriteFile(path, []byte("history\n"), 0o644))
+
+	var lines []string
+	handle := func(line string) { lines = append(lines, line) }
+	tailer := &logTailer{path: path}
+	require.NoError(t, tailer.readLines(handle))
+	require.Empty(t, lines)
+
+	appendLog := func(content string) {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+		require.NoError(t, err)
+		_, err = f.WriteString(content)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+	}
+
+	// partial lines are handled when completed
+	appendLog("line1\nli")
+	require.NoError(t, tailer.readLines(handle))
+	appendLog("ne2\n")
+	require.NoError(t, tailer.readLines(handle))
+	require.Equal(t, []string{"line1", "line2"}, lines)
+
+	// the log is truncated by copytruncate
+	require.NoError(t, os.Truncate(path, 0))
+	require.NoError(t, tailer.readLines(handle))
+	appendLog("line3\n")
+	require.NoError(t, tailer.readLines(handle))
+	require.Equal(t, []string{"line1", "line2", "line3"}, lines)
+
+	// the log is rotated by renaming
+	require.NoError(t, os.Rename(path, path+".1"))
+	require.NoError(t, os.WriteFile(path, []byte("line4\n"), 0o644))
+	require.NoError(t, tailer.readLines(handle))
+	require.NoError(t, tailer.readLines(handle))
+	require.Equal(t, []string{"line1", "line2", "line3", "line4"}, lines)
+}
@@ -11,11 +11,13 @@ import (
 	"regexp"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -55,6 +57,15 @@ type Configuration struct {
 	DefaultProviderName     string
 	DefaultInterfaceName    string
 	ExternalGatewayConfigNS string
+	QoSMode                 string
+	EnableFqdnSnoop         bool
+	FqdnSnoopDNSService     string
+	FqdnSnoopDNSServers     string
+	EnableAclLogCollector   bool
+	OvnControllerLogFile    string
+	AclLogFile              string
+	OvnNbAddr               string
+	OvnTimeout              int
 }
 
 // ParseFlags will parse cmd args then init kubeClient and configuration
@@ -84,6 +95,15 @@ func ParseFlags() *Configuration {
 		argsDefaultProviderName    = pflag.String("default-provider-name", "provider", "The vlan or vxlan type default provider interface name")
 		argsDefaultInterfaceName   = pflag.String("default-interface-name", "", "The default host interface name in the vlan/vxlan type")
 		argExternalGatewayConfigNS = pflag.String("external-gateway-config-ns", "kube-system", "The namespace of configmap external-gateway-config, default: kube-system")
+		argQoSMode                 = pflag.String("qos-mode", util.QoSModeHost, "Where the bandwidth limits are enforced, the rate limits are left to kube-ovn-controller in ovn mode, must be the same as the one of kube-ovn-controller")
+		argEnableFqdnSnoop         = pflag.Bool("enable-fqdn-snoop", false, "Snoop dns responses to resolve the domain names referred by security groups and network policies (default false)")
+		argFqdnSnoopDNSService     = pflag.String("fqdn-snoop-dns-service", "kube-system/kube-dns", "The cluster dns service whose cluster ips and endpoints are trusted by the dns snooper")
+		argFqdnSnoopDNSServers     = pflag.String("fqdn-snoop-dns-servers", "", "Comma-separated ips of the upstream dns servers trusted by the dns snooper besides the cluster dns service and the node local dns")
+		argEnableAclLogCollector   = pflag.Bool("enable-acl-log-collector", false, "Collect the acl logs of network policies and security groups from ovn-controller (default false)")
+		argOvnControllerLogFile    = pflag.String("ovn-controller-log-file", "/var/log/ovn/ovn-controller.log", "The log file of ovn-controller the acl logs are collected from")
+		argAclLogFile              = pflag.String("acl-log-file", "/var/log/kube-ovn/kube-ovn-acl.log", "The file the collected acl logs are written to as json lines")
+		argOvnNbAddr               = pflag.String("ovn-nb-addr", "", "ovn-nb address used to verify the logical switch ports of pods in CNI CHECK and to resolve the owners of acls in the acl logs, the verification is skipped if not set")
+		argOvnTimeout              = pflag.Int("ovn-timeout", 60, "The seconds to wait ovn command timeout")
 	)
 
 	// mute info log for ipset lib
@@ -130,6 +150,15 @@ func ParseFlags() *Configuration {
 		DefaultProviderName:     *argsDefaultProviderName,
 		DefaultInterfaceName:    *argsDefaultInterfaceName,
 		ExternalGatewayConfigNS: *argExternalGatewayConfigNS,
+		QoSMode:                 *argQoSMode,
+		EnableFqdnSnoop:         *argEnableFqdnSnoop,
+		FqdnSnoopDNSService:     *argFqdnSnoopDNSService,
+		FqdnSnoopDNSServers:     *argFqdnSnoopDNSServers,
+		EnableAclLogCollector:   *argEnableAclLogCollector,
+		OvnControllerLogFile:    *argOvnControllerLogFile,
+		AclLogFile:              *argAclLogFile,
+		OvnNbAddr:               *argOvnNbAddr,
+		OvnTimeout:              *argOvnTimeout,
 	}
 	return config
 }
@@ -153,6 +182,9 @@ func (config *Configuration) Init(nicBridgeMappings map[string]string) error {
 	if err := config.initNicConfig(nicBridgeMappings); err != nil {
 		return err
 	}
+	if err := config.checkQoSMode(); err != nil {
+		return err
+	}
 
 	klog.Infof("daemon config: %v", config)
 	return nil
@@ -256,6 +288,31 @@ func (config *Configuration) initNicConfig(nicBridgeMappings map[string]string)
 	return setEncapIP(encapIP)
 }
 
+// checkQoSMode waits for kube-ovn-controller to record its qos mode on the node and checks the daemon runs with the same one,
+// otherwise the rate limits would be enforced twice or not at all
+func (config *Configuration) checkQoSMode() error {
+	var mode string
+	err := wait.PollImmediateInfinite(time.Second, func() (bool, error) {
+		node, err := config.KubeClient.CoreV1().Nodes().Get(context.Background(), config.NodeName, metav1.GetOptions{})
+		if err != nil {
+			klog.Errorf("failed to get node %s: %v", config.NodeName, err)
+			return false, nil
+		}
+		if mode = node.Annotations[util.QoSModeAnnotation]; mode == "" {
+			klog.Infof("waiting for kube-ovn-controller to record the qos mode on node %s", config.NodeName)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+	if mode != config.QoSMode {
+		return fmt.Errorf("qos mode %q of the daemon mismatches qos mode %q of kube-ovn-controller", config.QoSMode, mode)
+	}
+	return nil
+}
+
 func (config *Configuration) getEncapIP(node *corev1.Node) string {
 	if podIP := os.Getenv(util.POD_IP); podIP != "" {
 		return podIP
@@ -0,0 +1,24 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func Test_checkQoSMode(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:        "node1",
+		Annotations: map[string]string{util.QoSModeAnnotation: util.QoSModeOvn},
+	}}
+	config := &Configuration{NodeName: "node1", KubeClient: fake.NewSimpleClientset(node), QoSMode: util.QoSModeOvn}
+	require.NoError(t, config.checkQoSMode())
+
+	config.QoSMode = util.QoSModeHost
+	require.EqualError(t, config.checkQoSMode(), `qos mode "host" of the daemon mismatches qos mode "ovn" of kube-ovn-controller`)
+}
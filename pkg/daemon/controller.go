@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os/exec"
+	"reflect"
 	"strconv"
+	"strings"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
@@ -54,6 +56,13 @@ type Controller struct {
 	htbQosLister kubeovnlister.HtbQosLister
 	htbQosSynced cache.InformerSynced
 
+	qosPoliciesLister kubeovnlister.QoSPolicyLister
+	qosPolicySynced   cache.InformerSynced
+
+	fqdnAddressesLister kubeovnlister.FqdnAddressLister
+	fqdnAddressSynced   cache.InformerSynced
+	fqdnSnooper         *fqdnSnooper
+
 	recorder record.EventRecorder
 
 	protocol string
@@ -73,6 +82,8 @@ func NewController(config *Configuration, podInformerFactory informers.SharedInf
 	podInformer := podInformerFactory.Core().V1().Pods()
 	nodeInformer := nodeInformerFactory.Core().V1().Nodes()
 	htbQosInformer := kubeovnInformerFactory.Kubeovn().V1().HtbQoses()
+	qosPolicyInformer := kubeovnInformerFactory.Kubeovn().V1().QoSPolicies()
+	fqdnAddressInformer := kubeovnInformerFactory.Kubeovn().V1().FqdnAddresses()
 
 	controller := &Controller{
 		config: config,
@@ -96,6 +107,13 @@ func NewController(config *Configuration, podInformerFactory informers.SharedInf
 		htbQosLister: htbQosInformer.Lister(),
 		htbQosSynced: htbQosInformer.Informer().HasSynced,
 
+		qosPoliciesLister: qosPolicyInformer.Lister(),
+		qosPolicySynced:   qosPolicyInformer.Informer().HasSynced,
+
+		fqdnAddressesLister: fqdnAddressInformer.Lister(),
+		fqdnAddressSynced:   fqdnAddressInformer.Informer().HasSynced,
+		fqdnSnooper:         newFqdnSnooper(),
+
 		recorder: recorder,
 	}
 
@@ -123,6 +141,9 @@ func NewController(config *Configuration, podInformerFactory informers.SharedInf
 	podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		UpdateFunc: controller.enqueuePod,
 	})
+	qosPolicyInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: controller.enqueueUpdateQoSPolicy,
+	})
 
 	return controller, nil
 }
@@ -474,7 +495,8 @@ func (c *Controller) enqueuePod(old, new interface{}) {
 		oldPod.Annotations[util.NetemQosLatencyAnnotation] != newPod.Annotations[util.NetemQosLatencyAnnotation] ||
 		oldPod.Annotations[util.NetemQosLimitAnnotation] != newPod.Annotations[util.NetemQosLimitAnnotation] ||
 		oldPod.Annotations[util.NetemQosLossAnnotation] != newPod.Annotations[util.NetemQosLossAnnotation] ||
-		oldPod.Annotations[util.MirrorControlAnnotation] != newPod.Annotations[util.MirrorControlAnnotation] {
+		oldPod.Annotations[util.MirrorControlAnnotation] != newPod.Annotations[util.MirrorControlAnnotation] ||
+		oldPod.Annotations[util.QoSPolicyAnnotation] != newPod.Annotations[util.QoSPolicyAnnotation] {
 		var key string
 		var err error
 		if key, err = cache.MetaNamespaceKeyFunc(new); err != nil {
@@ -497,7 +519,8 @@ func (c *Controller) enqueuePod(old, new interface{}) {
 				oldPod.Annotations[fmt.Sprintf(util.NetemQosLatencyAnnotationTemplate, provider)] != newPod.Annotations[fmt.Sprintf(util.NetemQosLatencyAnnotationTemplate, provider)] ||
 				oldPod.Annotations[fmt.Sprintf(util.NetemQosLimitAnnotationTemplate, provider)] != newPod.Annotations[fmt.Sprintf(util.NetemQosLimitAnnotationTemplate, provider)] ||
 				oldPod.Annotations[fmt.Sprintf(util.NetemQosLossAnnotationTemplate, provider)] != newPod.Annotations[fmt.Sprintf(util.NetemQosLossAnnotationTemplate, provider)] ||
-				oldPod.Annotations[fmt.Sprintf(util.MirrorControlAnnotationTemplate, provider)] != newPod.Annotations[fmt.Sprintf(util.MirrorControlAnnotationTemplate, provider)] {
+				oldPod.Annotations[fmt.Sprintf(util.MirrorControlAnnotationTemplate, provider)] != newPod.Annotations[fmt.Sprintf(util.MirrorControlAnnotationTemplate, provider)] ||
+				oldPod.Annotations[fmt.Sprintf(util.QoSPolicyAnnotationTemplate, provider)] != newPod.Annotations[fmt.Sprintf(util.QoSPolicyAnnotationTemplate, provider)] {
 				var key string
 				var err error
 				if key, err = cache.MetaNamespaceKeyFunc(new); err != nil {
@@ -645,6 +668,90 @@ func (c *Controller) getSubnetQosPriority(subnetName string) string {
 	return priority
 }
 
+// podQoS is the qos settings of a pod nic
+type podQoS struct {
+	ingressRate string
+	egressRate  string
+	burst       string
+	priority    string
+	latency     string
+	limit       string
+	loss        string
+}
+
+// getPodQoS returns the qos settings of the pod nic of the provider, values in the annotations of the pod
+// take precedence over the ones of the qos policy, and then the priority of the htb qos of the subnet
+func (c *Controller) getPodQoS(pod *v1.Pod, provider string) podQoS {
+	qos := podQoS{
+		ingressRate: pod.Annotations[fmt.Sprintf(util.IngressRateAnnotationTemplate, provider)],
+		egressRate:  pod.Annotations[fmt.Sprintf(util.EgressRateAnnotationTemplate, provider)],
+		priority:    pod.Annotations[fmt.Sprintf(util.PriorityAnnotationTemplate, provider)],
+		latency:     pod.Annotations[fmt.Sprintf(util.NetemQosLatencyAnnotationTemplate, provider)],
+		limit:       pod.Annotations[fmt.Sprintf(util.NetemQosLimitAnnotationTemplate, provider)],
+		loss:        pod.Annotations[fmt.Sprintf(util.NetemQosLossAnnotationTemplate, provider)],
+	}
+	if name := pod.Annotations[fmt.Sprintf(util.QoSPolicyAnnotationTemplate, provider)]; name != "" {
+		policy, err := c.qosPoliciesLister.Get(name)
+		if err != nil {
+			klog.Errorf("failed to get qos policy %s: %v", name, err)
+		} else {
+			for _, field := range []struct {
+				value  *string
+				policy string
+			}{
+				{&qos.ingressRate, policy.Spec.IngressRate},
+				{&qos.egressRate, policy.Spec.EgressRate},
+				{&qos.burst, policy.Spec.Burst},
+				{&qos.priority, policy.Spec.Priority},
+				{&qos.latency, policy.Spec.Latency},
+				{&qos.limit, policy.Spec.Limit},
+				{&qos.loss, policy.Spec.Loss},
+			} {
+				if *field.value == "" {
+					*field.value = field.policy
+				}
+			}
+		}
+	}
+	if qos.priority == "" {
+		qos.priority = c.getSubnetQosPriority(pod.Annotations[fmt.Sprintf(util.LogicalSwitchAnnotationTemplate, provider)])
+	}
+	if c.config.QoSMode == util.QoSModeOvn {
+		// rate limits are enforced by ovn qos rules of the logical switch
+		qos.ingressRate, qos.egressRate, qos.burst = "", "", ""
+	}
+	return qos
+}
+
+// enqueueUpdateQoSPolicy enqueues the local pods with nics bound to the updated policy
+func (c *Controller) enqueueUpdateQoSPolicy(old, new interface{}) {
+	oldPolicy := old.(*kubeovnv1.QoSPolicy)
+	newPolicy := new.(*kubeovnv1.QoSPolicy)
+	if reflect.DeepEqual(oldPolicy.Spec, newPolicy.Spec) {
+		return
+	}
+
+	pods, err := c.podsLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list pods: %v", err)
+		return
+	}
+	for _, pod := range pods {
+		for k, v := range pod.Annotations {
+			if v != newPolicy.Name || !strings.HasSuffix(k, ".kubernetes.io/qos_policy") {
+				continue
+			}
+			key, err := cache.MetaNamespaceKeyFunc(pod)
+			if err != nil {
+				utilruntime.HandleError(err)
+				break
+			}
+			c.podQueue.Add(key)
+			break
+		}
+	}
+}
+
 // Run starts controller
 func (c *Controller) Run(stopCh <-chan struct{}) {
 	defer utilruntime.HandleCrash()
@@ -658,7 +765,7 @@ func (c *Controller) Run(stopCh <-chan struct{}) {
 	go wait.Until(rotateLog, 1*time.Hour, stopCh)
 	go wait.Until(c.operateMod, 10*time.Second, stopCh)
 
-	if ok := cache.WaitForCacheSync(stopCh, c.providerNetworksSynced, c.subnetsSynced, c.podsSynced, c.nodesSynced, c.htbQosSynced); !ok {
+	if ok := cache.WaitForCacheSync(stopCh, c.providerNetworksSynced, c.subnetsSynced, c.podsSynced, c.nodesSynced, c.htbQosSynced, c.qosPolicySynced, c.fqdnAddressSynced); !ok {
 		klog.Fatalf("failed to wait for caches to sync")
 		return
 	}
@@ -676,6 +783,12 @@ func (c *Controller) Run(stopCh <-chan struct{}) {
 	go wait.Until(c.runPodWorker, time.Second, stopCh)
 	go wait.Until(c.runGateway, 3*time.Second, stopCh)
 	go wait.Until(c.loopEncapIpCheck, 3*time.Second, stopCh)
+	if c.config.EnableFqdnSnoop {
+		go c.runFqdnSnooper(stopCh)
+	}
+	if c.config.EnableAclLogCollector {
+		go c.runAclLogCollector(stopCh)
+	}
 	go wait.Until(func() {
 		if err := c.markAndCleanInternalPort(); err != nil {
 			klog.Errorf("gc ovs port error: %v", err)
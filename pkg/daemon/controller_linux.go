@@ -127,7 +127,7 @@ func (c *Controller) reconcileRouters(event subnetEvent) error {
 			continue
 		}
 
-		for _, cidrBlock := range strings.Split(subnet.Spec.CIDRBlock, ",") {
+		for _, cidrBlock := range util.SubnetCIDRBlocks(subnet) {
 			if _, ipNet, err := net.ParseCIDR(cidrBlock); err != nil {
 				klog.Errorf("%s is not a valid cidr block", cidrBlock)
 			} else {
@@ -415,16 +415,10 @@ func (c *Controller) handlePod(key string) error {
 		podName = pod.Annotations[fmt.Sprintf(util.VmTemplate, util.OvnProvider)]
 	}
 
-	priority := pod.Annotations[util.PriorityAnnotation]
-	subnetName := pod.Annotations[util.LogicalSwitchAnnotation]
-	subnetPriority := c.getSubnetQosPriority(subnetName)
-	if priority == "" && subnetPriority != "" {
-		priority = subnetPriority
-	}
-
 	// set default nic bandwidth
+	qos := c.getPodQoS(pod, util.OvnProvider)
 	ifaceID := ovs.PodNameToPortName(podName, pod.Namespace, util.OvnProvider)
-	err = ovs.SetInterfaceBandwidth(podName, pod.Namespace, ifaceID, pod.Annotations[util.EgressRateAnnotation], pod.Annotations[util.IngressRateAnnotation], priority)
+	err = ovs.SetInterfaceBandwidthWithBurst(podName, pod.Namespace, ifaceID, qos.egressRate, qos.ingressRate, qos.burst, qos.priority)
 	if err != nil {
 		return err
 	}
@@ -433,7 +427,7 @@ func (c *Controller) handlePod(key string) error {
 		return err
 	}
 	// set linux-netem qos
-	err = ovs.SetNetemQos(podName, pod.Namespace, ifaceID, pod.Annotations[util.NetemQosLatencyAnnotation], pod.Annotations[util.NetemQosLimitAnnotation], pod.Annotations[util.NetemQosLossAnnotation])
+	err = ovs.SetNetemQos(podName, pod.Namespace, ifaceID, qos.latency, qos.limit, qos.loss)
 	if err != nil {
 		return err
 	}
@@ -450,14 +444,8 @@ func (c *Controller) handlePod(key string) error {
 		}
 		if pod.Annotations[fmt.Sprintf(util.AllocatedAnnotationTemplate, provider)] == "true" {
 			ifaceID = ovs.PodNameToPortName(podName, pod.Namespace, provider)
-			priority := pod.Annotations[fmt.Sprintf(util.PriorityAnnotationTemplate, provider)]
-			subnetName := pod.Annotations[fmt.Sprintf(util.LogicalSwitchAnnotationTemplate, provider)]
-			subnetPriority := c.getSubnetQosPriority(subnetName)
-			if priority == "" && subnetPriority != "" {
-				priority = subnetPriority
-			}
-
-			err = ovs.SetInterfaceBandwidth(podName, pod.Namespace, ifaceID, pod.Annotations[fmt.Sprintf(util.EgressRateAnnotationTemplate, provider)], pod.Annotations[fmt.Sprintf(util.IngressRateAnnotationTemplate, provider)], priority)
+			qos := c.getPodQoS(pod, provider)
+			err = ovs.SetInterfaceBandwidthWithBurst(podName, pod.Namespace, ifaceID, qos.egressRate, qos.ingressRate, qos.burst, qos.priority)
 			if err != nil {
 				return err
 			}
@@ -465,7 +453,7 @@ func (c *Controller) handlePod(key string) error {
 			if err != nil {
 				return err
 			}
-			err = ovs.SetNetemQos(podName, pod.Namespace, ifaceID, pod.Annotations[fmt.Sprintf(util.NetemQosLatencyAnnotationTemplate, provider)], pod.Annotations[fmt.Sprintf(util.NetemQosLimitAnnotationTemplate, provider)], pod.Annotations[fmt.Sprintf(util.NetemQosLossAnnotationTemplate, provider)])
+			err = ovs.SetNetemQos(podName, pod.Namespace, ifaceID, qos.latency, qos.limit, qos.loss)
 			if err != nil {
 				return err
 			}
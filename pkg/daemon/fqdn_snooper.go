@@ -0,0 +1,340 @@
+package daemon
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+)
+
+// fqdnMinTTL keeps the addresses with a short ttl long enough to avoid flapping acls
+const fqdnMinTTL = 30 * time.Second
+
+// dnsAnswer is an address a domain name is resolved to
+type dnsAnswer struct {
+	fqdn string
+	ip   string
+	ttl  time.Duration
+}
+
+// fqdnSnooper collects the addresses resolved by the dns responses to the pods on the node
+// and merges them into the status of the FqdnAddresses in batches, only the responses from
+// the trusted dns servers are accepted so that pods can not open acls by spoofed responses
+type fqdnSnooper struct {
+	mutex   sync.Mutex
+	pending map[string]map[string]time.Time
+	servers map[string]bool
+}
+
+func newFqdnSnooper() *fqdnSnooper {
+	return &fqdnSnooper{pending: make(map[string]map[string]time.Time)}
+}
+
+func (s *fqdnSnooper) setServers(servers map[string]bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.servers = servers
+}
+
+func (s *fqdnSnooper) trusted(ip net.IP) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.servers[ip.String()]
+}
+
+func (s *fqdnSnooper) record(answers []dnsAnswer, now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, answer := range answers {
+		ttl := answer.ttl
+		if ttl < fqdnMinTTL {
+			ttl = fqdnMinTTL
+		}
+		expireTime := now.Add(ttl)
+		if s.pending[answer.fqdn] == nil {
+			s.pending[answer.fqdn] = make(map[string]time.Time)
+		}
+		if expireTime.After(s.pending[answer.fqdn][answer.ip]) {
+			s.pending[answer.fqdn][answer.ip] = expireTime
+		}
+	}
+}
+
+func (s *fqdnSnooper) takePending() map[string]map[string]time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	pending := s.pending
+	s.pending = make(map[string]map[string]time.Time)
+	return pending
+}
+
+// handleDNSFrame records the addresses in the dns response carried by the ethernet frame
+// if the domain name is referred by any FqdnAddress
+func (c *Controller) handleDNSFrame(frame []byte) {
+	src, payload := dnsPayloadFromFrame(frame)
+	if payload == nil {
+		return
+	}
+	if !c.fqdnSnooper.trusted(src) {
+		klog.V(5).Infof("ignore dns response from untrusted server %s", src)
+		return
+	}
+	answers, err := parseDNSResponse(payload, func(fqdn string) bool {
+		_, err := c.fqdnAddressesLister.Get(fqdn)
+		return err == nil
+	})
+	if err != nil {
+		klog.V(5).Infof("failed to parse dns response, %v", err)
+		return
+	}
+	if len(answers) != 0 {
+		c.fqdnSnooper.record(answers, time.Now())
+	}
+}
+
+// syncDNSServers refreshes the dns servers trusted by the snooper, which are the cluster ips and the endpoints
+// of the cluster dns service, the node local dns and the configured upstream dns servers
+func (c *Controller) syncDNSServers() {
+	servers := make(map[string]bool)
+	for _, s := range append(strings.Split(c.config.FqdnSnoopDNSServers, ","), c.config.NodeLocalDnsIP) {
+		if ip := net.ParseIP(strings.TrimSpace(s)); ip != nil {
+			servers[ip.String()] = true
+		}
+	}
+
+	if c.config.FqdnSnoopDNSService != "" {
+		namespace, name, err := cache.SplitMetaNamespaceKey(c.config.FqdnSnoopDNSService)
+		if err != nil {
+			klog.Errorf("invalid dns service %s, %v", c.config.FqdnSnoopDNSService, err)
+			return
+		}
+		svc, err := c.config.KubeClient.CoreV1().Services(namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			klog.Errorf("failed to get dns service %s, %v", c.config.FqdnSnoopDNSService, err)
+			return
+		}
+		if svc != nil {
+			for _, ip := range svc.Spec.ClusterIPs {
+				if parsed := net.ParseIP(ip); parsed != nil {
+					servers[parsed.String()] = true
+				}
+			}
+		}
+		ep, err := c.config.KubeClient.CoreV1().Endpoints(namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			klog.Errorf("failed to get endpoints of dns service %s, %v", c.config.FqdnSnoopDNSService, err)
+			return
+		}
+		if ep != nil {
+			for _, subset := range ep.Subsets {
+				for _, addr := range append(subset.Addresses, subset.NotReadyAddresses...) {
+					if parsed := net.ParseIP(addr.IP); parsed != nil {
+						servers[parsed.String()] = true
+					}
+				}
+			}
+		}
+	}
+	c.fqdnSnooper.setServers(servers)
+}
+
+// dnsPayloadFromFrame returns the source ip and the udp payload of the ethernet frame sent from port 53
+func dnsPayloadFromFrame(frame []byte) (net.IP, []byte) {
+	if len(frame) < 14 {
+		return nil, nil
+	}
+	var proto byte
+	var src net.IP
+	var l4 []byte
+	switch binary.BigEndian.Uint16(frame[12:14]) {
+	case 0x0800:
+		ip := frame[14:]
+		if len(ip) < 20 || ip[0]>>4 != 4 {
+			return nil, nil
+		}
+		ihl := int(ip[0]&0x0f) * 4
+		if ihl < 20 || len(ip) < ihl {
+			return nil, nil
+		}
+		proto, src, l4 = ip[9], net.IP(ip[12:16]), ip[ihl:]
+	case 0x86dd:
+		ip := frame[14:]
+		if len(ip) < 40 || ip[0]>>4 != 6 {
+			return nil, nil
+		}
+		proto, src, l4 = ip[6], net.IP(ip[8:24]), ip[40:]
+	default:
+		return nil, nil
+	}
+	if proto != 17 || len(l4) < 8 || binary.BigEndian.Uint16(l4[0:2]) != 53 {
+		return nil, nil
+	}
+	return src, l4[8:]
+}
+
+// parseDNSResponse returns the A and AAAA answers of the response to the questions for the watched domain names,
+// the answers of the names aliased by CNAME records are attributed to the domain names in the questions
+func parseDNSResponse(payload []byte, watched func(fqdn string) bool) ([]dnsAnswer, error) {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(payload)
+	if err != nil {
+		return nil, err
+	}
+	if !header.Response || header.RCode != dnsmessage.RCodeSuccess {
+		return nil, nil
+	}
+	questions, err := parser.AllQuestions()
+	if err != nil {
+		return nil, err
+	}
+
+	// aliases maps the target of a CNAME record to the names pointing to it
+	aliases := make(map[string][]string)
+	var records []dnsAnswer
+	for {
+		h, err := parser.AnswerHeader()
+		if err == dnsmessage.ErrSectionDone {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := canonicalFqdn(h.Name.String())
+		ttl := time.Duration(h.TTL) * time.Second
+		switch h.Type {
+		case dnsmessage.TypeA:
+			r, err := parser.AResource()
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, dnsAnswer{fqdn: name, ip: net.IP(r.A[:]).String(), ttl: ttl})
+		case dnsmessage.TypeAAAA:
+			r, err := parser.AAAAResource()
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, dnsAnswer{fqdn: name, ip: net.IP(r.AAAA[:]).String(), ttl: ttl})
+		case dnsmessage.TypeCNAME:
+			r, err := parser.CNAMEResource()
+			if err != nil {
+				return nil, err
+			}
+			target := canonicalFqdn(r.CNAME.String())
+			aliases[target] = append(aliases[target], name)
+		default:
+			if err = parser.SkipAnswer(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	queried := make(map[string]bool, len(questions))
+	for _, q := range questions {
+		if fqdn := canonicalFqdn(q.Name.String()); watched(fqdn) {
+			queried[fqdn] = true
+		}
+	}
+	if len(queried) == 0 {
+		return nil, nil
+	}
+
+	var answers []dnsAnswer
+	for _, record := range records {
+		// walk up the CNAME chain, the visited set guards against loops in malformed responses
+		visited := map[string]bool{}
+		names := []string{record.fqdn}
+		for len(names) != 0 {
+			name := names[0]
+			names = names[1:]
+			if visited[name] {
+				continue
+			}
+			visited[name] = true
+			if queried[name] {
+				answers = append(answers, dnsAnswer{fqdn: name, ip: record.ip, ttl: record.ttl})
+			}
+			names = append(names, aliases[name]...)
+		}
+	}
+	return answers, nil
+}
+
+func canonicalFqdn(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+// syncFqdnAddresses merges the pending addresses into the FqdnAddresses and prunes the expired ones
+func (c *Controller) syncFqdnAddresses() {
+	now := time.Now()
+	for fqdn, addresses := range c.fqdnSnooper.takePending() {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			fqdnAddress, err := c.config.KubeOvnClient.KubeovnV1().FqdnAddresses().Get(context.Background(), fqdn, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			status := mergeFqdnAddresses(fqdnAddress.Status, addresses, now)
+			if len(status.Addresses) == len(fqdnAddress.Status.Addresses) && equalFqdnAddresses(status, fqdnAddress.Status) {
+				return nil
+			}
+			fqdnAddress.Status = status
+			_, err = c.config.KubeOvnClient.KubeovnV1().FqdnAddresses().UpdateStatus(context.Background(), fqdnAddress, metav1.UpdateOptions{})
+			return err
+		})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			klog.Errorf("failed to update addresses of fqdn %s, %v", fqdn, err)
+		}
+	}
+}
+
+// mergeFqdnAddresses returns the unexpired addresses with the later expire time of the existing and the resolved ones
+func mergeFqdnAddresses(status kubeovnv1.FqdnAddressStatus, addresses map[string]time.Time, now time.Time) kubeovnv1.FqdnAddressStatus {
+	expireTimes := make(map[string]time.Time, len(status.Addresses)+len(addresses))
+	var ips []string
+	for _, record := range status.Addresses {
+		if _, ok := expireTimes[record.IP]; !ok {
+			ips = append(ips, record.IP)
+		}
+		if record.ExpireTime.Time.After(expireTimes[record.IP]) {
+			expireTimes[record.IP] = record.ExpireTime.Time
+		}
+	}
+	for ip, expireTime := range addresses {
+		if _, ok := expireTimes[ip]; !ok {
+			ips = append(ips, ip)
+		}
+		if expireTime.After(expireTimes[ip]) {
+			expireTimes[ip] = expireTime
+		}
+	}
+
+	sort.Strings(ips)
+	var merged kubeovnv1.FqdnAddressStatus
+	for _, ip := range ips {
+		if expireTimes[ip].After(now) {
+			// the api server stores the time in seconds
+			merged.Addresses = append(merged.Addresses, kubeovnv1.FqdnAddressRecord{IP: ip, ExpireTime: metav1.NewTime(expireTimes[ip].Truncate(time.Second))})
+		}
+	}
+	return merged
+}
+
+func equalFqdnAddresses(a, b kubeovnv1.FqdnAddressStatus) bool {
+	for i := range a.Addresses {
+		if a.Addresses[i].IP != b.Addresses[i].IP || !a.Addresses[i].ExpireTime.Equal(&b.Addresses[i].ExpireTime) {
+			return false
+		}
+	}
+	return true
+}
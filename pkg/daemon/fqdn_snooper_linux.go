@@ -0,0 +1,97 @@
+package daemon
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// dnsResponseFilter accepts the unfragmented udp packets over ipv4 or ipv6 sent from port 53,
+// the source addresses are checked against the trusted dns servers in handleDNSFrame
+var dnsResponseFilter = []bpf.Instruction{
+	bpf.LoadAbsolute{Off: 12, Size: 2},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0x0800, SkipFalse: 7},
+	// ipv4
+	bpf.LoadAbsolute{Off: 23, Size: 1},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: 17, SkipFalse: 11},
+	bpf.LoadAbsolute{Off: 20, Size: 2},
+	bpf.JumpIf{Cond: bpf.JumpBitsSet, Val: 0x1fff, SkipTrue: 9},
+	bpf.LoadMemShift{Off: 14},
+	bpf.LoadIndirect{Off: 14, Size: 2},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: 53, SkipTrue: 5, SkipFalse: 6},
+	// ipv6
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0x86dd, SkipFalse: 5},
+	bpf.LoadAbsolute{Off: 20, Size: 1},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: 17, SkipFalse: 3},
+	bpf.LoadAbsolute{Off: 54, Size: 2},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: 53, SkipFalse: 1},
+	bpf.RetConstant{Val: 65535},
+	bpf.RetConstant{Val: 0},
+}
+
+// runFqdnSnooper captures the dns responses on all the interfaces of the node,
+// including the veths of the pods, and keeps the FqdnAddresses up to date.
+// The first connection may be dropped if it is initiated before the acls are updated.
+func (c *Controller) runFqdnSnooper(stopCh <-chan struct{}) {
+	fd, err := openDNSSnoopSocket()
+	if err != nil {
+		klog.Errorf("failed to open dns snoop socket, %v", err)
+		return
+	}
+	go func() {
+		<-stopCh
+		_ = unix.Close(fd)
+	}()
+
+	go wait.Until(c.syncDNSServers, 30*time.Second, stopCh)
+	go wait.Until(c.syncFqdnAddresses, time.Second, stopCh)
+
+	klog.Info("start snooping dns responses")
+	buf := make([]byte, 65535)
+	for {
+		n, _, err := unix.Recvfrom(fd, buf, 0)
+		if err != nil {
+			select {
+			case <-stopCh:
+				return
+			default:
+			}
+			if err == unix.EINTR || err == unix.EAGAIN {
+				continue
+			}
+			klog.Errorf("failed to receive dns response, %v", err)
+			return
+		}
+		c.handleDNSFrame(buf[:n])
+	}
+}
+
+func openDNSSnoopSocket() (int, error) {
+	insts, err := bpf.Assemble(dnsResponseFilter)
+	if err != nil {
+		return -1, fmt.Errorf("failed to assemble bpf filter, %v", err)
+	}
+	filter := make([]unix.SockFilter, 0, len(insts))
+	for _, inst := range insts {
+		filter = append(filter, unix.SockFilter{Code: inst.Op, Jt: inst.Jt, Jf: inst.Jf, K: inst.K})
+	}
+
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW, int(htons(unix.ETH_P_ALL)))
+	if err != nil {
+		return -1, err
+	}
+	prog := &unix.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]}
+	if err = unix.SetsockoptSockFprog(fd, unix.SOL_SOCKET, unix.SO_ATTACH_FILTER, prog); err != nil {
+		_ = unix.Close(fd)
+		return -1, fmt.Errorf("failed to attach bpf filter, %v", err)
+	}
+	return fd, nil
+}
+
+func htons(i uint16) uint16 {
+	return i<<8 | i>>8
+}
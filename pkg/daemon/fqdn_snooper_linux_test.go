@@ -0,0 +1,41 @@
+package daemon
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/bpf"
+)
+
+func Test_dnsResponseFilter(t *testing.T) {
+	vm, err := bpf.NewVM(dnsResponseFilter)
+	require.NoError(t, err)
+
+	ipv4 := newDNSFrame([]byte{0})
+	n, err := vm.Run(ipv4)
+	require.NoError(t, err)
+	require.NotZero(t, n)
+
+	// packets to port 53
+	binary.BigEndian.PutUint16(ipv4[34:36], 40000)
+	binary.BigEndian.PutUint16(ipv4[36:38], 53)
+	n, err = vm.Run(ipv4)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	ipv6 := make([]byte, 14+40+8+1)
+	binary.BigEndian.PutUint16(ipv6[12:14], 0x86dd)
+	ipv6[14] = 0x60
+	ipv6[14+6] = 17
+	binary.BigEndian.PutUint16(ipv6[54:56], 53)
+	n, err = vm.Run(ipv6)
+	require.NoError(t, err)
+	require.NotZero(t, n)
+
+	// tcp
+	ipv6[14+6] = 6
+	n, err = vm.Run(ipv6)
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
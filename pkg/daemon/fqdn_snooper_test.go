@@ -0,0 +1,136 @@
+package daemon
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	kubeovnlister "github.com/kubeovn/kube-ovn/pkg/client/listers/kubeovn/v1"
+)
+
+// newDNSResponse returns a response to the A query of api.github.com resolved through a CNAME record
+func newDNSResponse(t *testing.T) []byte {
+	t.Helper()
+	builder := dnsmessage.NewBuilder(nil, dnsmessage.Header{Response: true, RCode: dnsmessage.RCodeSuccess})
+	require.NoError(t, builder.StartQuestions())
+	require.NoError(t, builder.Question(dnsmessage.Question{
+		Name:  dnsmessage.MustNewName("API.github.com."),
+		Type:  dnsmessage.TypeA,
+		Class: dnsmessage.ClassINET,
+	}))
+	require.NoError(t, builder.StartAnswers())
+	require.NoError(t, builder.CNAMEResource(
+		dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName("api.github.com."), Class: dnsmessage.ClassINET, TTL: 3600},
+		dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName("lb.github.com.")},
+	))
+	require.NoError(t, builder.AResource(
+		dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName("lb.github.com."), Class: dnsmessage.ClassINET, TTL: 60},
+		dnsmessage.AResource{A: [4]byte{140, 82, 112, 6}},
+	))
+	require.NoError(t, builder.AResource(
+		dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName("other.github.com."), Class: dnsmessage.ClassINET, TTL: 60},
+		dnsmessage.AResource{A: [4]byte{140, 82, 112, 7}},
+	))
+	msg, err := builder.Finish()
+	require.NoError(t, err)
+	return msg
+}
+
+// newDNSFrame wraps the dns payload in an ethernet frame of an ipv4 udp packet from port 53 of 10.96.0.10
+func newDNSFrame(payload []byte) []byte {
+	frame := make([]byte, 14+20+8, 14+20+8+len(payload))
+	binary.BigEndian.PutUint16(frame[12:14], 0x0800)
+	frame[14] = 0x45
+	frame[14+9] = 17
+	copy(frame[14+12:14+16], []byte{10, 96, 0, 10})
+	binary.BigEndian.PutUint16(frame[34:36], 53)
+	binary.BigEndian.PutUint16(frame[36:38], 40000)
+	return append(frame, payload...)
+}
+
+func Test_parseDNSResponse(t *testing.T) {
+	payload := newDNSResponse(t)
+
+	answers, err := parseDNSResponse(payload, func(fqdn string) bool { return fqdn == "api.github.com" })
+	require.NoError(t, err)
+	require.Equal(t, []dnsAnswer{{fqdn: "api.github.com", ip: "140.82.112.6", ttl: time.Minute}}, answers)
+
+	answers, err = parseDNSResponse(payload, func(string) bool { return false })
+	require.NoError(t, err)
+	require.Empty(t, answers)
+
+	src, p := dnsPayloadFromFrame(newDNSFrame(payload))
+	require.Equal(t, payload, p)
+	require.Equal(t, "10.96.0.10", src.String())
+	frame := newDNSFrame(payload)
+	binary.BigEndian.PutUint16(frame[34:36], 5353)
+	_, p = dnsPayloadFromFrame(frame)
+	require.Nil(t, p)
+}
+
+func Test_handleDNSFrame(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, indexer.Add(&kubeovnv1.FqdnAddress{ObjectMeta: metav1.ObjectMeta{Name: "api.github.com"}}))
+	kubeClient := fake.NewSimpleClientset(
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "kube-dns", Namespace: "kube-system"},
+			Spec:       corev1.ServiceSpec{ClusterIPs: []string{"10.96.0.10"}},
+		},
+		&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "kube-dns", Namespace: "kube-system"},
+			Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.16.0.5"}}}},
+		},
+	)
+	c := &Controller{
+		config: &Configuration{
+			KubeClient:          kubeClient,
+			FqdnSnoopDNSService: "kube-system/kube-dns",
+			FqdnSnoopDNSServers: "8.8.8.8, 1.1.1.1",
+		},
+		fqdnAddressesLister: kubeovnlister.NewFqdnAddressLister(indexer),
+		fqdnSnooper:         newFqdnSnooper(),
+	}
+	c.syncDNSServers()
+	for _, ip := range []string{"10.96.0.10", "10.16.0.5", "8.8.8.8", "1.1.1.1"} {
+		require.True(t, c.fqdnSnooper.trusted(net.ParseIP(ip)), ip)
+	}
+
+	// the spoofed response from a pod is ignored
+	frame := newDNSFrame(newDNSResponse(t))
+	copy(frame[14+12:14+16], []byte{10, 16, 0, 100})
+	c.handleDNSFrame(frame)
+	require.Empty(t, c.fqdnSnooper.takePending())
+
+	c.handleDNSFrame(newDNSFrame(newDNSResponse(t)))
+	pending := c.fqdnSnooper.takePending()
+	require.Contains(t, pending, "api.github.com")
+	require.Contains(t, pending["api.github.com"], "140.82.112.6")
+}
+
+func Test_mergeFqdnAddresses(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	status := kubeovnv1.FqdnAddressStatus{Addresses: []kubeovnv1.FqdnAddressRecord{
+		{IP: "140.82.112.5", ExpireTime: metav1.NewTime(now.Add(-time.Second))},
+		{IP: "140.82.112.6", ExpireTime: metav1.NewTime(now.Add(time.Hour))},
+	}}
+	addresses := map[string]time.Time{
+		"140.82.112.6": now.Add(time.Minute),
+		"140.82.112.7": now.Add(time.Minute),
+	}
+
+	merged := mergeFqdnAddresses(status, addresses, now)
+	require.Equal(t, []kubeovnv1.FqdnAddressRecord{
+		{IP: "140.82.112.6", ExpireTime: metav1.NewTime(now.Add(time.Hour))},
+		{IP: "140.82.112.7", ExpireTime: metav1.NewTime(now.Add(time.Minute))},
+	}, merged.Addresses)
+	require.True(t, equalFqdnAddresses(merged, mergeFqdnAddresses(merged, nil, now)))
+}
@@ -0,0 +1,9 @@
+package daemon
+
+import (
+	"k8s.io/klog/v2"
+)
+
+func (c *Controller) runFqdnSnooper(_ <-chan struct{}) {
+	klog.Warning("dns snooping is not supported on windows")
+}
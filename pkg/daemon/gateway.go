@@ -46,13 +46,35 @@ func (c *Controller) setGatewayBandwidth() error {
 		return err
 	}
 	ingress, egress, priority := node.Annotations[util.IngressRateAnnotation], node.Annotations[util.EgressRateAnnotation], node.Annotations[util.PriorityAnnotation]
+	var burst string
+	// values in the annotations of the node take precedence over the ones of the qos policy
+	if name := node.Annotations[util.QoSPolicyAnnotation]; name != "" {
+		policy, err := c.qosPoliciesLister.Get(name)
+		if err != nil {
+			klog.Errorf("failed to get qos policy %s: %v", name, err)
+		} else {
+			if ingress == "" {
+				ingress = policy.Spec.IngressRate
+			}
+			if egress == "" {
+				egress = policy.Spec.EgressRate
+			}
+			if priority == "" {
+				priority = policy.Spec.Priority
+			}
+			burst = policy.Spec.Burst
+		}
+	}
+	if c.config.QoSMode == util.QoSModeOvn {
+		ingress, egress, burst = "", "", ""
+	}
 	ifaceId := fmt.Sprintf("node-%s", c.config.NodeName)
 	if ingress == "" && egress == "" && priority == "" {
 		if htbQos, _ := ovs.IsHtbQos(ifaceId); !htbQos {
 			return nil
 		}
 	}
-	return ovs.SetInterfaceBandwidth("", "", ifaceId, egress, ingress, priority)
+	return ovs.SetInterfaceBandwidthWithBurst("", "", ifaceId, egress, ingress, burst, priority)
 }
 
 func (c *Controller) setICGateway() error {
@@ -98,8 +120,7 @@ func (c *Controller) getSubnetsNeedNAT(protocol string) ([]string, error) {
 			(subnet.Spec.Vlan == "" || subnet.Spec.LogicalGateway) &&
 			subnet.Spec.Vpc == util.DefaultVpc &&
 			(subnet.Spec.Protocol == kubeovnv1.ProtocolDual || subnet.Spec.Protocol == protocol) {
-			cidrBlock := getCidrByProtocol(subnet.Spec.CIDRBlock, protocol)
-			subnetsNeedNat = append(subnetsNeedNat, cidrBlock)
+			subnetsNeedNat = append(subnetsNeedNat, getSubnetCidrsByProtocol(subnet, protocol)...)
 		}
 	}
 	return subnetsNeedNat, nil
@@ -119,8 +140,7 @@ func (c *Controller) getSubnetsDistributedGateway(protocol string) ([]string, er
 			subnet.Spec.Vpc == util.DefaultVpc &&
 			subnet.Spec.GatewayType == kubeovnv1.GWDistributedType &&
 			(subnet.Spec.Protocol == kubeovnv1.ProtocolDual || subnet.Spec.Protocol == protocol) {
-			cidrBlock := getCidrByProtocol(subnet.Spec.CIDRBlock, protocol)
-			result = append(result, cidrBlock)
+			result = append(result, getSubnetCidrsByProtocol(subnet, protocol)...)
 		}
 	}
 	return result, nil
@@ -149,8 +169,7 @@ func (c *Controller) getDefaultVpcSubnetsCIDR(protocol string) ([]string, error)
 	}
 	for _, subnet := range subnets {
 		if subnet.Spec.Vpc == util.DefaultVpc && (subnet.Spec.Vlan == "" || subnet.Spec.LogicalGateway) {
-			cidrBlock := getCidrByProtocol(subnet.Spec.CIDRBlock, protocol)
-			ret = append(ret, cidrBlock)
+			ret = append(ret, getSubnetCidrsByProtocol(subnet, protocol)...)
 		}
 	}
 	return ret, nil
@@ -193,6 +212,17 @@ func getCidrByProtocol(cidr, protocol string) string {
 	return cidrStr
 }
 
+// getSubnetCidrsByProtocol returns the cidr and secondary cidr blocks of the subnet in the protocol
+func getSubnetCidrsByProtocol(subnet *kubeovnv1.Subnet, protocol string) []string {
+	cidrs := []string{getCidrByProtocol(subnet.Spec.CIDRBlock, protocol)}
+	for _, cidr := range subnet.Spec.SecondaryCIDRBlocks {
+		if util.CheckProtocol(cidr) == protocol {
+			cidrs = append(cidrs, cidr)
+		}
+	}
+	return cidrs
+}
+
 func (c *Controller) getEgressNatIpByNode(nodeName string) (map[string]string, error) {
 	var subnetsNatIp = make(map[string]string)
 	subnetList, err := c.subnetsLister.List(labels.Everything())
@@ -2,8 +2,10 @@ package daemon
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"os/exec"
 	"strconv"
 	"strings"
 	"time"
@@ -34,10 +36,15 @@ type cniServerHandler struct {
 	KubeClient    kubernetes.Interface
 	KubeOvnClient clientset.Interface
 	Controller    *Controller
+	// ovnLegacyClient verifies the logical switch ports in CHECK, nil if the nb address is not configured
+	ovnLegacyClient *ovs.LegacyClient
 }
 
 func createCniServerHandler(config *Configuration, controller *Controller) *cniServerHandler {
 	csh := &cniServerHandler{KubeClient: config.KubeClient, KubeOvnClient: config.KubeOvnClient, Config: config, Controller: controller}
+	if config.OvnNbAddr != "" {
+		csh.ovnLegacyClient = &ovs.LegacyClient{OvnNbAddress: config.OvnNbAddr, OvnTimeout: config.OvnTimeout}
+	}
 	return csh
 }
 
@@ -119,6 +126,9 @@ func (csh cniServerHandler) handleAdd(req *restful.Request, resp *restful.Respon
 		subnet = pod.Annotations[fmt.Sprintf(util.LogicalSwitchAnnotationTemplate, podRequest.Provider)]
 		ingress = pod.Annotations[fmt.Sprintf(util.IngressRateAnnotationTemplate, podRequest.Provider)]
 		egress = pod.Annotations[fmt.Sprintf(util.EgressRateAnnotationTemplate, podRequest.Provider)]
+		if csh.Config.QoSMode == util.QoSModeOvn {
+			ingress, egress = "", ""
+		}
 		priority = pod.Annotations[fmt.Sprintf(util.PriorityAnnotationTemplate, podRequest.Provider)]
 		latency = pod.Annotations[fmt.Sprintf(util.NetemQosLatencyAnnotationTemplate, podRequest.Provider)]
 		limit = pod.Annotations[fmt.Sprintf(util.NetemQosLimitAnnotationTemplate, podRequest.Provider)]
@@ -480,3 +490,133 @@ func (csh cniServerHandler) handleDel(req *restful.Request, resp *restful.Respon
 
 	resp.WriteHeader(http.StatusNoContent)
 }
+
+func (csh cniServerHandler) handleCheck(req *restful.Request, resp *restful.Response) {
+	var podRequest request.CniRequest
+	if err := req.ReadEntity(&podRequest); err != nil {
+		errMsg := fmt.Errorf("parse check request failed %v", err)
+		klog.Error(errMsg)
+		if err := resp.WriteHeaderAndEntity(http.StatusBadRequest, request.CniResponse{Err: errMsg.Error()}); err != nil {
+			klog.Errorf("failed to write response, %v", err)
+		}
+		return
+	}
+	klog.V(5).Infof("check port request: %v", podRequest)
+
+	if err := csh.checkPod(&podRequest); err != nil {
+		klog.Errorf("check pod %s/%s failed: %v", podRequest.PodNamespace, podRequest.PodName, err)
+		if err := resp.WriteHeaderAndEntity(http.StatusInternalServerError, request.CniResponse{Err: err.Error()}); err != nil {
+			klog.Errorf("failed to write response, %v", err)
+		}
+		return
+	}
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// checkPod compares the network of the pod with its annotations
+func (csh cniServerHandler) checkPod(podRequest *request.CniRequest) error {
+	if !csh.providerExists(podRequest.Provider) {
+		return fmt.Errorf("provider %s not bind to any subnet", podRequest.Provider)
+	}
+	if err := csh.validatePodRequest(podRequest); err != nil {
+		return err
+	}
+
+	pod, err := csh.Controller.podsLister.Pods(podRequest.PodNamespace).Get(podRequest.PodName)
+	if err != nil {
+		return fmt.Errorf("get pod %s/%s failed %v", podRequest.PodNamespace, podRequest.PodName, err)
+	}
+	if pod.Annotations[fmt.Sprintf(util.AllocatedAnnotationTemplate, podRequest.Provider)] != "true" {
+		return fmt.Errorf("no address allocated to pod %s/%s provider %s", pod.Namespace, pod.Name, podRequest.Provider)
+	}
+	subnet := pod.Annotations[fmt.Sprintf(util.LogicalSwitchAnnotationTemplate, podRequest.Provider)]
+	if !strings.HasSuffix(podRequest.Provider, util.OvnProvider) || subnet == "" {
+		// the nic is not created by kube-ovn
+		return nil
+	}
+	if podRequest.DeviceID != "" || podRequest.VhostUserSocketVolumeName != "" {
+		klog.Infof("skip checking offload or dpdk nic of pod %s/%s", pod.Namespace, pod.Name)
+		return nil
+	}
+
+	ifName := podRequest.IfName
+	if ifName == "" {
+		ifName = "eth0"
+	}
+	var isDefaultRoute bool
+	switch pod.Annotations[fmt.Sprintf(util.DefaultRouteAnnotationTemplate, podRequest.Provider)] {
+	case "true":
+		isDefaultRoute = true
+	case "false":
+		isDefaultRoute = false
+	default:
+		isDefaultRoute = ifName == "eth0"
+	}
+
+	podName := podRequest.PodName
+	if vmName := pod.Annotations[fmt.Sprintf(util.VmTemplate, podRequest.Provider)]; vmName != "" {
+		podName = vmName
+	}
+	macAddr := pod.Annotations[fmt.Sprintf(util.MacAddressAnnotationTemplate, podRequest.Provider)]
+	ip := pod.Annotations[fmt.Sprintf(util.IpAddressAnnotationTemplate, podRequest.Provider)]
+	cidr := pod.Annotations[fmt.Sprintf(util.CidrAnnotationTemplate, podRequest.Provider)]
+	gw := pod.Annotations[fmt.Sprintf(util.GatewayAnnotationTemplate, podRequest.Provider)]
+	nicType := pod.Annotations[fmt.Sprintf(util.PodNicAnnotationTemplate, podRequest.Provider)]
+	if err = checkPrevResult(podRequest.PrevIPs, podRequest.PrevMac, util.GetIpAddrWithMask(ip, cidr), macAddr); err != nil {
+		return err
+	}
+	return csh.checkNic(podName, podRequest.PodNamespace, podRequest.Provider, podRequest.NetNs, podRequest.ContainerID, ifName, macAddr, util.GetIpAddrWithMask(ip, cidr), gw, isDefaultRoute, podRequest.Routes, nicType)
+}
+
+func (csh cniServerHandler) handleStatus(req *restful.Request, resp *restful.Response) {
+	if err := checkNodeStatus(); err != nil {
+		klog.Error(err)
+		if err := resp.WriteHeaderAndEntity(http.StatusInternalServerError, request.CniResponse{Err: err.Error()}); err != nil {
+			klog.Errorf("failed to write response, %v", err)
+		}
+		return
+	}
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// checkNodeStatus returns an error if ovs or ovn-controller on the node is not working
+func checkNodeStatus() error {
+	if output, err := exec.Command("ovs-appctl", "-t", "ovs-vswitchd", "version").CombinedOutput(); err != nil {
+		return fmt.Errorf("ovs-vswitchd is not running: %v, %q", err, output)
+	}
+	if _, err := ovs.Exec("br-exists", "br-int"); err != nil {
+		return fmt.Errorf("ovs bridge br-int is not ready: %v", err)
+	}
+
+	output, err := exec.Command("ovn-appctl", "-t", "ovn-controller", "connection-status").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ovn-controller is not running: %v, %q", err, output)
+	}
+	if status := strings.TrimSpace(string(output)); status != "connected" {
+		return fmt.Errorf("ovn-controller is %s to ovn southbound database", status)
+	}
+	return nil
+}
+
+// checkPrevResult compares the result of ADD passed back by the runtime with the addresses allocated to the pod
+func checkPrevResult(prevIPs []string, prevMac, ipAddr, mac string) error {
+	var mismatches []string
+	expected := strings.Split(ipAddr, ",")
+	for _, ip := range prevIPs {
+		if !util.ContainsString(expected, ip) {
+			mismatches = append(mismatches, fmt.Sprintf("address %s in prevResult is not allocated to the pod", ip))
+		}
+	}
+	for _, ip := range expected {
+		if !util.ContainsString(prevIPs, ip) {
+			mismatches = append(mismatches, fmt.Sprintf("address %s is not found in prevResult", ip))
+		}
+	}
+	if !strings.EqualFold(prevMac, mac) {
+		mismatches = append(mismatches, fmt.Sprintf("mac %q in prevResult differs from the allocated %s", prevMac, mac))
+	}
+	if len(mismatches) != 0 {
+		return errors.New(strings.Join(mismatches, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_checkPrevResult(t *testing.T) {
+	ips := []string{"10.16.0.2/16", "fd00:10:16::2/64"}
+	require.NoError(t, checkPrevResult(ips, "00:00:00:11:22:33", "10.16.0.2/16,fd00:10:16::2/64", "00:00:00:11:22:33"))
+	// the mac is compared case-insensitively
+	require.NoError(t, checkPrevResult(ips, "00:00:00:AA:BB:CC", "10.16.0.2/16,fd00:10:16::2/64", "00:00:00:aa:bb:cc"))
+
+	err := checkPrevResult(ips[:1], "00:00:00:11:22:44", "10.16.0.3/16,fd00:10:16::2/64", "00:00:00:11:22:33")
+	require.EqualError(t, err, `address 10.16.0.2/16 in prevResult is not allocated to the pod; address 10.16.0.3/16 is not found in prevResult; `+
+		`address fd00:10:16::2/64 is not found in prevResult; mac "00:00:00:11:22:44" in prevResult differs from the allocated 00:00:00:11:22:33`)
+}
@@ -48,6 +48,14 @@ var (
 		[]string{"node_name"},
 	)
 
+	aclLogPacketsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "acl_log_packets_total",
+			Help: "The count of packets logged by the acls of network policies and security groups",
+		},
+		[]string{"node_name", "policy_type", "namespace", "policy", "direction", "verdict"},
+	)
+
 	// client metrics
 	requestLatency = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -128,6 +136,7 @@ func InitMetrics() {
 	prometheus.MustRegister(cniOperationHistogram)
 	prometheus.MustRegister(cniWaitAddressResult)
 	prometheus.MustRegister(cniConnectivityResult)
+	prometheus.MustRegister(aclLogPacketsTotal)
 }
 
 // registerClientMetrics sets up the client latency metrics from client-go
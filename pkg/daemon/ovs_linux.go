@@ -181,6 +181,126 @@ func (csh cniServerHandler) deleteNic(podName, podNamespace, containerID, netns,
 	return nil
 }
 
+// checkNic verifies the ovs port and the container nic created by configureNic or configureNicWithInternalPort
+// and returns all the mismatches found
+func (csh cniServerHandler) checkNic(podName, podNamespace, provider, netns, containerID, ifName, mac, ip, gateway string, isDefaultRoute bool, routes []request.Route, nicType string) error {
+	hostNicName, containerNicName := generateNicName(containerID, ifName)
+	ovsNicName, podNicName := hostNicName, ifName
+	if nicType == util.InternalType {
+		ovsNicName, podNicName = containerNicName, containerNicName
+	}
+
+	var mismatches []string
+	ifaceID := ovs.PodNameToPortName(podName, podNamespace, provider)
+	if id, err := ovs.GetInterfaceExternalID(ovsNicName, "iface-id"); err != nil {
+		return err
+	} else if id != ifaceID {
+		mismatches = append(mismatches, fmt.Sprintf("ovs interface %s has iface-id %q, expected %q", ovsNicName, id, ifaceID))
+	} else if installed, err := ovs.GetInterfaceExternalID(ovsNicName, "ovn-installed"); err != nil {
+		return err
+	} else if installed != "true" {
+		mismatches = append(mismatches, fmt.Sprintf("logical switch port %s is not installed by ovn-controller", ifaceID))
+	}
+	if csh.ovnLegacyClient != nil {
+		if exists, err := csh.ovnLegacyClient.LogicalSwitchPortExists(ifaceID); err != nil {
+			return err
+		} else if !exists {
+			mismatches = append(mismatches, fmt.Sprintf("logical switch port %s is not found in ovn nb", ifaceID))
+		}
+	}
+
+	err := ns.WithNetNSPath(netns, func(_ ns.NetNS) error {
+		link, err := netlink.LinkByName(podNicName)
+		if err != nil {
+			mismatches = append(mismatches, fmt.Sprintf("failed to find nic %s in netns %s: %v", podNicName, netns, err))
+			return nil
+		}
+		addrs, err := netlink.AddrList(link, netlink.FAMILY_ALL)
+		if err != nil {
+			return fmt.Errorf("failed to list addresses of nic %s: %v", podNicName, err)
+		}
+		nlRoutes, err := netlink.RouteList(link, netlink.FAMILY_ALL)
+		if err != nil {
+			return fmt.Errorf("failed to list routes of nic %s: %v", podNicName, err)
+		}
+		mismatches = append(mismatches, checkContainerNic(link, addrs, nlRoutes, mac, ip, gateway, isDefaultRoute, routes)...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check nic in netns %s: %v", netns, err)
+	}
+
+	if len(mismatches) != 0 {
+		return errors.New(strings.Join(mismatches, "; "))
+	}
+	return nil
+}
+
+// checkContainerNic compares the state, addresses and routes of the container nic with the expected ones
+func checkContainerNic(link netlink.Link, addrs []netlink.Addr, nlRoutes []netlink.Route, mac, ipAddr, gateway string, isDefaultRoute bool, routes []request.Route) []string {
+	var mismatches []string
+	nicName := link.Attrs().Name
+	if link.Attrs().Flags&net.FlagUp == 0 {
+		mismatches = append(mismatches, fmt.Sprintf("nic %s is down", nicName))
+	}
+	if link.Attrs().HardwareAddr.String() != strings.ToLower(mac) {
+		mismatches = append(mismatches, fmt.Sprintf("nic %s has mac %s, expected %s", nicName, link.Attrs().HardwareAddr, mac))
+	}
+
+	for _, ip := range strings.Split(ipAddr, ",") {
+		found := false
+		ipStr, cidr, _ := net.ParseCIDR(ip)
+		for _, addr := range addrs {
+			if cidr != nil && addr.IP.Equal(ipStr) && addr.Mask.String() == cidr.Mask.String() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			mismatches = append(mismatches, fmt.Sprintf("address %s is not found on nic %s", ip, nicName))
+		}
+	}
+
+	expected := make([]request.Route, 0, len(routes)+2)
+	if isDefaultRoute {
+		for _, gw := range strings.Split(gateway, ",") {
+			if gw == "" {
+				continue
+			}
+			if util.CheckProtocol(gw) == kubeovnv1.ProtocolIPv4 {
+				expected = append(expected, request.Route{Destination: "0.0.0.0/0", Gateway: gw})
+			} else {
+				expected = append(expected, request.Route{Destination: "::/0", Gateway: gw})
+			}
+		}
+	}
+	expected = append(expected, routes...)
+	for _, r := range expected {
+		_, dstNet, err := net.ParseCIDR(r.Destination)
+		if err != nil {
+			// invalid routes are ignored by configureContainerNic
+			continue
+		}
+		found := false
+		for _, route := range nlRoutes {
+			dst := "0.0.0.0/0"
+			if route.Dst != nil {
+				dst = route.Dst.String()
+			} else if route.Family == netlink.FAMILY_V6 {
+				dst = "::/0"
+			}
+			if dst == dstNet.String() && (r.Gateway == "" || route.Gw.Equal(net.ParseIP(r.Gateway))) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			mismatches = append(mismatches, fmt.Sprintf("route to %s via %q is not found on nic %s", r.Destination, r.Gateway, nicName))
+		}
+	}
+	return mismatches
+}
+
 func generateNicName(containerID, ifname string) (string, string) {
 	if ifname == "eth0" {
 		return fmt.Sprintf("%s_h", containerID[0:12]), fmt.Sprintf("%s_c", containerID[0:12])
@@ -0,0 +1,46 @@
+package daemon
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vishvananda/netlink"
+
+	"github.com/kubeovn/kube-ovn/pkg/request"
+)
+
+func Test_checkContainerNic(t *testing.T) {
+	mac, _ := net.ParseMAC("00:00:00:11:22:33")
+	link := &netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: "eth0", Flags: net.FlagUp, HardwareAddr: mac}}
+	parseAddr := func(s string) netlink.Addr {
+		addr, err := netlink.ParseAddr(s)
+		require.NoError(t, err)
+		return *addr
+	}
+	addrs := []netlink.Addr{parseAddr("10.16.0.2/16"), parseAddr("fd00:10:16::2/64")}
+	_, dst, _ := net.ParseCIDR("192.168.0.0/24")
+	nlRoutes := []netlink.Route{
+		{Family: netlink.FAMILY_V4, Gw: net.ParseIP("10.16.0.1")},
+		{Family: netlink.FAMILY_V6, Gw: net.ParseIP("fd00:10:16::1")},
+		{Family: netlink.FAMILY_V4, Dst: dst, Gw: net.ParseIP("10.16.0.254")},
+	}
+	routes := []request.Route{{Destination: "192.168.0.0/24", Gateway: "10.16.0.254"}}
+
+	mismatches := checkContainerNic(link, addrs, nlRoutes, "00:00:00:11:22:33", "10.16.0.2/16,fd00:10:16::2/64", "10.16.0.1,fd00:10:16::1", true, routes)
+	require.Empty(t, mismatches)
+
+	// the default routes are not expected on the nic of a non-default network
+	mismatches = checkContainerNic(link, addrs, nlRoutes[2:], "00:00:00:11:22:33", "10.16.0.2/16,fd00:10:16::2/64", "10.16.0.1,fd00:10:16::1", false, routes)
+	require.Empty(t, mismatches)
+
+	down := &netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: "eth0", HardwareAddr: mac}}
+	mismatches = checkContainerNic(down, addrs[:1], nlRoutes[1:], "00:00:00:11:22:44", "10.16.0.3/16,fd00:10:16::2/64", "10.16.0.1,fd00:10:16::1", true, routes)
+	require.Equal(t, []string{
+		"nic eth0 is down",
+		"nic eth0 has mac 00:00:00:11:22:33, expected 00:00:00:11:22:44",
+		"address 10.16.0.3/16 is not found on nic eth0",
+		"address fd00:10:16::2/64 is not found on nic eth0",
+		`route to 0.0.0.0/0 via "10.16.0.1" is not found on nic eth0`,
+	}, mismatches)
+}
@@ -230,6 +230,10 @@ func (csh cniServerHandler) deleteNic(podName, podNamespace, containerID, netns,
 	return hns.RemoveHnsEndpoint(epName, netns, containerID)
 }
 
+func (csh cniServerHandler) checkNic(podName, podNamespace, provider, netns, containerID, ifName, mac, ip, gateway string, isDefaultRoute bool, routes []request.Route, nicType string) error {
+	return errors.New("CNI CHECK is not supported on Windows")
+}
+
 func generateNicName(containerID, ifname string) (string, string) {
 	if ifname == "eth0" {
 		return fmt.Sprintf("%s_h", containerID[0:12]), fmt.Sprintf("%s_c", containerID[0:12])
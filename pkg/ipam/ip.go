@@ -2,6 +2,7 @@ package ipam
 
 import (
 	"math/big"
+	"sort"
 	"strings"
 
 	"github.com/kubeovn/kube-ovn/pkg/util"
@@ -171,3 +172,75 @@ func splitRange(a, b *IPRange) IPRangeList {
 	ipr2 := IPRange{Start: b.End.Add(1), End: a.End}
 	return IPRangeList{&ipr1, &ipr2}
 }
+
+func (ipr IPRange) Count() float64 {
+	count := big.NewInt(0).Sub(util.Ip2BigInt(string(ipr.End)), util.Ip2BigInt(string(ipr.Start)))
+	f, _ := new(big.Float).SetInt(count.Add(count, big.NewInt(1))).Float64()
+	return f
+}
+
+func (iprl IPRangeList) Count() float64 {
+	var count float64
+	for _, ipr := range iprl {
+		count += ipr.Count()
+	}
+	return count
+}
+
+// intersectIPRangeList returns the addresses both in a and b
+func intersectIPRangeList(a, b IPRangeList) IPRangeList {
+	result := IPRangeList{}
+	for _, x := range a {
+		for _, y := range b {
+			start, end := x.Start, x.End
+			if y.Start.GreaterThan(start) {
+				start = y.Start
+			}
+			if y.End.LessThan(end) {
+				end = y.End
+			}
+			if !start.GreaterThan(end) {
+				result = append(result, &IPRange{Start: start, End: end})
+			}
+		}
+	}
+	return result
+}
+
+// separateIPRangeList returns the addresses in a but not in b
+func separateIPRangeList(a, b IPRangeList) IPRangeList {
+	for _, y := range b {
+		newList := IPRangeList{}
+		for _, x := range a {
+			if iprl := splitRange(x, y); iprl != nil {
+				newList = append(newList, iprl...)
+			}
+		}
+		a = newList
+	}
+	return a
+}
+
+// unionIPRangeList returns the addresses in a or b
+func unionIPRangeList(a, b IPRangeList) IPRangeList {
+	all := make(IPRangeList, 0, len(a)+len(b))
+	for _, ipr := range append(append(IPRangeList{}, a...), b...) {
+		all = append(all, &IPRange{Start: ipr.Start, End: ipr.End})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Start.LessThan(all[j].Start) })
+
+	result := IPRangeList{}
+	for _, ipr := range all {
+		if len(result) != 0 {
+			last := result[len(result)-1]
+			if !last.End.Add(1).LessThan(ipr.Start) {
+				if ipr.End.GreaterThan(last.End) {
+					last.End = ipr.End
+				}
+				continue
+			}
+		}
+		result = append(result, ipr)
+	}
+	return result
+}
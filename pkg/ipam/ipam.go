@@ -2,7 +2,6 @@ package ipam
 
 import (
 	"errors"
-	"net"
 	"strconv"
 	"strings"
 	"sync"
@@ -38,7 +37,7 @@ func NewIPAM() *IPAM {
 	}
 }
 
-func (ipam *IPAM) GetRandomAddress(podName, nicName, mac, subnetName string, skippedAddrs []string, checkConflict bool) (string, string, string, error) {
+func (ipam *IPAM) GetRandomAddress(podName, nicName, mac, subnetName, poolName string, skippedAddrs []string, checkConflict bool) (string, string, string, error) {
 	ipam.mutex.RLock()
 	defer ipam.mutex.RUnlock()
 
@@ -47,7 +46,7 @@ func (ipam *IPAM) GetRandomAddress(podName, nicName, mac, subnetName string, ski
 		return "", "", "", ErrNoAvailable
 	}
 
-	v4IP, v6IP, mac, err := subnet.GetRandomAddress(podName, nicName, mac, skippedAddrs, checkConflict)
+	v4IP, v6IP, mac, err := subnet.GetRandomAddress(poolName, podName, nicName, mac, skippedAddrs, checkConflict)
 	klog.Infof("allocate v4 %s v6 %s mac %s for %s", v4IP, v6IP, mac, podName)
 	return string(v4IP), string(v6IP), mac, err
 }
@@ -100,10 +99,10 @@ func checkAndAppendIpsForDual(ips []IP, mac string, podName string, nicName stri
 	var err error
 	if util.CheckProtocol(string(ips[0])) == kubeovnv1.ProtocolIPv4 {
 		newIps = ips
-		_, ipAddr, _, err = subnet.getV6RandomAddress(podName, nicName, mac, nil, checkConflict)
+		_, ipAddr, _, err = subnet.getV6RandomAddress("", podName, nicName, mac, nil, checkConflict)
 		newIps = append(newIps, ipAddr)
 	} else if util.CheckProtocol(string(ips[0])) == kubeovnv1.ProtocolIPv6 {
-		ipAddr, _, _, err = subnet.getV4RandomAddress(podName, nicName, mac, nil, checkConflict)
+		ipAddr, _, _, err = subnet.getV4RandomAddress("", podName, nicName, mac, nil, checkConflict)
 		newIps = append(newIps, ipAddr)
 		newIps = append(newIps, ips...)
 	}
@@ -119,34 +118,28 @@ func (ipam *IPAM) ReleaseAddressByPod(podName string) {
 	}
 }
 
+// AddOrUpdateSubnet adds or updates the subnet, cidrStr holds the primary cidr blocks followed by
+// the secondary ones, which are allocated from in order once the former ones are exhausted
 func (ipam *IPAM) AddOrUpdateSubnet(name, cidrStr, gw string, excludeIps []string) error {
 	excludeIps = util.ExpandExcludeIPs(excludeIps, cidrStr)
 
 	ipam.mutex.Lock()
 	defer ipam.mutex.Unlock()
 
-	var v4cidrStr, v6cidrStr, v4Gw, v6Gw string
-	var cidrs []*net.IPNet
-	for _, cidrBlock := range strings.Split(cidrStr, ",") {
-		if _, cidr, err := net.ParseCIDR(cidrBlock); err != nil {
-			return ErrInvalidCIDR
-		} else {
-			cidrs = append(cidrs, cidr)
-		}
+	var v4Gw, v6Gw string
+	v4CIDRs, v6CIDRs, err := parseCIDRs(cidrStr)
+	if err != nil {
+		return err
 	}
-	protocol := util.CheckProtocol(cidrStr)
+	protocol := cidrsProtocol(v4CIDRs, v6CIDRs)
 	switch protocol {
 	case kubeovnv1.ProtocolDual:
-		v4cidrStr = cidrs[0].String()
-		v6cidrStr = cidrs[1].String()
 		gws := strings.Split(gw, ",")
 		v4Gw = gws[0]
 		v6Gw = gws[1]
 	case kubeovnv1.ProtocolIPv4:
-		v4cidrStr = cidrs[0].String()
 		v4Gw = gw
 	case kubeovnv1.ProtocolIPv6:
-		v6cidrStr = cidrs[0].String()
 		v6Gw = gw
 	}
 
@@ -154,16 +147,22 @@ func (ipam *IPAM) AddOrUpdateSubnet(name, cidrStr, gw string, excludeIps []strin
 	v4ExcludeIps, v6ExcludeIps := util.SplitIpsByProtocol(excludeIps)
 
 	if subnet, ok := ipam.Subnets[name]; ok {
+		// the cidr can be expanded in place, but shrinking it must not drop any allocated address
+		if err := subnet.checkAddressesInCIDR(v4CIDRs, v6CIDRs); err != nil {
+			klog.Errorf("failed to update subnet %s to cidr %s: %v", name, cidrStr, err)
+			return err
+		}
 		subnet.Protocol = protocol
+		subnet.V4Gw = v4Gw
+		subnet.V6Gw = v6Gw
 		if protocol == kubeovnv1.ProtocolDual || protocol == kubeovnv1.ProtocolIPv4 {
-			_, cidr, _ := net.ParseCIDR(v4cidrStr)
-			subnet.V4CIDR = cidr
+			subnet.V4CIDR = v4CIDRs[0]
+			subnet.V4SecondaryCIDRs = v4CIDRs[1:]
 			subnet.V4ReservedIPList = convertExcludeIps(v4ExcludeIps)
-			firstIP, _ := util.FirstIP(v4cidrStr)
-			lastIP, _ := util.LastIP(v4cidrStr)
-			subnet.V4FreeIPList = IPRangeList{&IPRange{Start: IP(firstIP), End: IP(lastIP)}}
+			subnet.V4FreeIPList = cidrsToIPRangeList(v4CIDRs)
 			subnet.joinFreeWithReserve()
 			subnet.V4ReleasedIPList = IPRangeList{}
+			subnet.joinFreeWithIPPools(kubeovnv1.ProtocolIPv4)
 			for nicName, ip := range subnet.V4NicToIP {
 				mac := subnet.NicToMac[nicName]
 				podName := subnet.V4IPToPod[ip]
@@ -173,14 +172,13 @@ func (ipam *IPAM) AddOrUpdateSubnet(name, cidrStr, gw string, excludeIps []strin
 			}
 		}
 		if protocol == kubeovnv1.ProtocolDual || protocol == kubeovnv1.ProtocolIPv6 {
-			_, cidr, _ := net.ParseCIDR(v6cidrStr)
-			subnet.V6CIDR = cidr
+			subnet.V6CIDR = v6CIDRs[0]
+			subnet.V6SecondaryCIDRs = v6CIDRs[1:]
 			subnet.V6ReservedIPList = convertExcludeIps(v6ExcludeIps)
-			firstIP, _ := util.FirstIP(v6cidrStr)
-			lastIP, _ := util.LastIP(v6cidrStr)
-			subnet.V6FreeIPList = IPRangeList{&IPRange{Start: IP(firstIP), End: IP(lastIP)}}
+			subnet.V6FreeIPList = cidrsToIPRangeList(v6CIDRs)
 			subnet.joinFreeWithReserve()
 			subnet.V6ReleasedIPList = IPRangeList{}
+			subnet.joinFreeWithIPPools(kubeovnv1.ProtocolIPv6)
 			for nicName, ip := range subnet.V6NicToIP {
 				mac := subnet.NicToMac[nicName]
 				podName := subnet.V6IPToPod[ip]
@@ -203,6 +201,84 @@ func (ipam *IPAM) AddOrUpdateSubnet(name, cidrStr, gw string, excludeIps []strin
 	return nil
 }
 
+func (ipam *IPAM) AddOrUpdateIPPool(subnetName, poolName string, ips []string) error {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	subnet, ok := ipam.Subnets[subnetName]
+	if !ok {
+		return ErrNoAvailable
+	}
+	klog.Infof("add or update ippool %s of subnet %s", poolName, subnetName)
+	return subnet.AddOrUpdateIPPool(poolName, ips)
+}
+
+func (ipam *IPAM) RemoveIPPool(subnetName, poolName string) {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	if subnet, ok := ipam.Subnets[subnetName]; ok {
+		klog.Infof("delete ippool %s of subnet %s", poolName, subnetName)
+		subnet.RemoveIPPool(poolName)
+	}
+}
+
+// IPPoolOfIP returns the name of the ippool of the subnet the ip belongs to, empty if none
+func (ipam *IPAM) IPPoolOfIP(subnetName, ip string) string {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	subnet, ok := ipam.Subnets[subnetName]
+	if !ok {
+		return ""
+	}
+	subnet.mutex.RLock()
+	defer subnet.mutex.RUnlock()
+	if pool := subnet.getIPPoolByIP(IP(ip)); pool != nil {
+		return pool.Name
+	}
+	return ""
+}
+
+// ListIPPools returns the names of ippools grouped by subnet
+func (ipam *IPAM) ListIPPools() map[string][]string {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	pools := make(map[string][]string, len(ipam.Subnets))
+	for name, subnet := range ipam.Subnets {
+		subnet.mutex.RLock()
+		for poolName := range subnet.IPPools {
+			pools[name] = append(pools[name], poolName)
+		}
+		subnet.mutex.RUnlock()
+	}
+	return pools
+}
+
+func (ipam *IPAM) IPPoolStatistics(subnetName, poolName string) (v4Available, v4Using, v6Available, v6Using float64, err error) {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	subnet, ok := ipam.Subnets[subnetName]
+	if !ok {
+		return 0, 0, 0, 0, ErrNoAvailable
+	}
+	return subnet.IPPoolStatistics(poolName)
+}
+
+// CIDRStatistics returns the address usage of each cidr of the subnet
+func (ipam *IPAM) CIDRStatistics(subnetName string) ([]CIDRUsage, error) {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	subnet, ok := ipam.Subnets[subnetName]
+	if !ok {
+		return nil, ErrNoAvailable
+	}
+	return subnet.CIDRStatistics(), nil
+}
+
 func (ipam *IPAM) DeleteSubnet(subnetName string) {
 	ipam.mutex.Lock()
 	defer ipam.mutex.Unlock()
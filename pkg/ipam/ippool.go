@@ -0,0 +1,152 @@
+package ipam
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// IPPool is a group of addresses carved out of a subnet, the addresses can
+// only be allocated to the workloads bound to the pool
+type IPPool struct {
+	Name             string
+	V4IPs            IPRangeList
+	V4FreeIPList     IPRangeList
+	V4ReleasedIPList IPRangeList
+	V6IPs            IPRangeList
+	V6FreeIPList     IPRangeList
+	V6ReleasedIPList IPRangeList
+}
+
+// NewIPPool parses ips which may contain addresses, address ranges
+// (e.g. 10.16.0.10..10.16.0.20) and CIDRs
+func NewIPPool(name string, ips []string) (*IPPool, error) {
+	pool := &IPPool{
+		Name:             name,
+		V4IPs:            IPRangeList{},
+		V4FreeIPList:     IPRangeList{},
+		V4ReleasedIPList: IPRangeList{},
+		V6IPs:            IPRangeList{},
+		V6FreeIPList:     IPRangeList{},
+		V6ReleasedIPList: IPRangeList{},
+	}
+
+	for _, s := range ips {
+		s = strings.TrimSpace(s)
+		var start, end string
+		if strings.Contains(s, "/") {
+			if _, _, err := net.ParseCIDR(s); err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidCIDR, s)
+			}
+			start, _ = util.FirstIP(s)
+			end, _ = util.LastIP(s)
+		} else {
+			parts := strings.Split(s, "..")
+			if len(parts) > 2 {
+				return nil, fmt.Errorf("invalid ip range %s", s)
+			}
+			start, end = parts[0], parts[len(parts)-1]
+		}
+
+		startIP, endIP := net.ParseIP(start), net.ParseIP(end)
+		if startIP == nil || endIP == nil {
+			return nil, fmt.Errorf("invalid ip range %s", s)
+		}
+		if (startIP.To4() == nil) != (endIP.To4() == nil) {
+			return nil, fmt.Errorf("ip range %s mixes IPv4 and IPv6 addresses", s)
+		}
+		ipr := &IPRange{Start: IP(startIP.String()), End: IP(endIP.String())}
+		if ipr.Start.GreaterThan(ipr.End) {
+			return nil, fmt.Errorf("invalid ip range %s", s)
+		}
+
+		if startIP.To4() != nil {
+			if len(intersectIPRangeList(pool.V4IPs, IPRangeList{ipr})) != 0 {
+				return nil, fmt.Errorf("ip range %s overlaps with other ranges of ippool %s", s, name)
+			}
+			pool.V4IPs = append(pool.V4IPs, ipr)
+		} else {
+			if len(intersectIPRangeList(pool.V6IPs, IPRangeList{ipr})) != 0 {
+				return nil, fmt.Errorf("ip range %s overlaps with other ranges of ippool %s", s, name)
+			}
+			pool.V6IPs = append(pool.V6IPs, ipr)
+		}
+	}
+	sort.Slice(pool.V4IPs, func(i, j int) bool { return pool.V4IPs[i].Start.LessThan(pool.V4IPs[j].Start) })
+	sort.Slice(pool.V6IPs, func(i, j int) bool { return pool.V6IPs[i].Start.LessThan(pool.V6IPs[j].Start) })
+	return pool, nil
+}
+
+// ValidateIPPool returns an error if the ips of the ippool are not in the cidr blocks of its subnet
+// or overlap with the ips of the other ippools of the subnet
+func ValidateIPPool(name string, ips, cidrBlocks []string, otherPools map[string][]string) error {
+	pool, err := NewIPPool(name, ips)
+	if err != nil {
+		return err
+	}
+	for _, ipr := range append(append(IPRangeList{}, pool.V4IPs...), pool.V6IPs...) {
+		contained := false
+		for _, cidrBlock := range cidrBlocks {
+			_, cidr, err := net.ParseCIDR(strings.TrimSpace(cidrBlock))
+			if err == nil && cidr.Contains(net.ParseIP(string(ipr.Start))) && cidr.Contains(net.ParseIP(string(ipr.End))) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			return fmt.Errorf("%w: %s..%s is not in %s", ErrOutOfRange, ipr.Start, ipr.End, strings.Join(cidrBlocks, ","))
+		}
+	}
+
+	names := make([]string, 0, len(otherPools))
+	for otherName := range otherPools {
+		names = append(names, otherName)
+	}
+	sort.Strings(names)
+	for _, otherName := range names {
+		other, err := NewIPPool(otherName, otherPools[otherName])
+		if err != nil {
+			// invalid ippools are never set up
+			continue
+		}
+		if len(intersectIPRangeList(other.V4IPs, pool.V4IPs)) != 0 || len(intersectIPRangeList(other.V6IPs, pool.V6IPs)) != 0 {
+			return fmt.Errorf("%w: ippool %s overlaps with ippool %s", ErrConflict, name, otherName)
+		}
+	}
+	return nil
+}
+
+func (pool *IPPool) contains(ip IP) bool {
+	if net.ParseIP(string(ip)).To4() != nil {
+		return pool.V4IPs.Contains(ip)
+	}
+	return pool.V6IPs.Contains(ip)
+}
+
+// take removes ip from the free or released list of the pool
+func (pool *IPPool) take(ip IP) bool {
+	if net.ParseIP(string(ip)).To4() != nil {
+		if split, newFreeList := splitIPRangeList(pool.V4FreeIPList, ip); split {
+			pool.V4FreeIPList = newFreeList
+			return true
+		}
+		if split, newReleasedList := splitIPRangeList(pool.V4ReleasedIPList, ip); split {
+			pool.V4ReleasedIPList = newReleasedList
+			return true
+		}
+		return false
+	}
+
+	if split, newFreeList := splitIPRangeList(pool.V6FreeIPList, ip); split {
+		pool.V6FreeIPList = newFreeList
+		return true
+	}
+	if split, newReleasedList := splitIPRangeList(pool.V6ReleasedIPList, ip); split {
+		pool.V6ReleasedIPList = newReleasedList
+		return true
+	}
+	return false
+}
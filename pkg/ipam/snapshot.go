@@ -0,0 +1,370 @@
+package ipam
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// Snapshot is a checkpoint of the allocation state of the ipam, the controller
+// resumes from it on startup instead of rebuilding every subnet from the API server
+type Snapshot struct {
+	Subnets map[string]*SubnetSnapshot `json:"subnets"`
+	// ResourceVersions of the IP CRs the snapshot was taken after, keyed by IP CR name
+	ResourceVersions map[string]string `json:"resourceVersions,omitempty"`
+}
+
+// SubnetSnapshot records the range lists and the allocations of a subnet,
+// range lists are formatted as "ip" or "start..end" to keep the checkpoint compact
+type SubnetSnapshot struct {
+	CIDR         string                     `json:"cidr"`
+	Gateway      string                     `json:"gateway,omitempty"`
+	V4Free       []string                   `json:"v4Free,omitempty"`
+	V4Released   []string                   `json:"v4Released,omitempty"`
+	V4Reserved   []string                   `json:"v4Reserved,omitempty"`
+	V4NicToIP    map[string]string          `json:"v4NicToIP,omitempty"`
+	V4IPToPod    map[string]string          `json:"v4IPToPod,omitempty"`
+	V6Free       []string                   `json:"v6Free,omitempty"`
+	V6Released   []string                   `json:"v6Released,omitempty"`
+	V6Reserved   []string                   `json:"v6Reserved,omitempty"`
+	V6NicToIP    map[string]string          `json:"v6NicToIP,omitempty"`
+	V6IPToPod    map[string]string          `json:"v6IPToPod,omitempty"`
+	NicToMac     map[string]string          `json:"nicToMac,omitempty"`
+	MacToPod     map[string]string          `json:"macToPod,omitempty"`
+	PodToNicList map[string][]string        `json:"podToNicList,omitempty"`
+	IPPools      map[string]*IPPoolSnapshot `json:"ippools,omitempty"`
+}
+
+// IPPoolSnapshot records the range lists of an ippool
+type IPPoolSnapshot struct {
+	V4IPs      []string `json:"v4IPs,omitempty"`
+	V4Free     []string `json:"v4Free,omitempty"`
+	V4Released []string `json:"v4Released,omitempty"`
+	V6IPs      []string `json:"v6IPs,omitempty"`
+	V6Free     []string `json:"v6Free,omitempty"`
+	V6Released []string `json:"v6Released,omitempty"`
+}
+
+// Snapshot takes a consistent copy of every subnet in the ipam
+func (ipam *IPAM) Snapshot() *Snapshot {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+
+	snapshot := &Snapshot{Subnets: make(map[string]*SubnetSnapshot, len(ipam.Subnets))}
+	for name, subnet := range ipam.Subnets {
+		snapshot.Subnets[name] = subnet.snapshot()
+	}
+	return snapshot
+}
+
+// Restore replaces the subnets in the ipam with the ones recorded in the snapshot,
+// the ipam is left untouched if any subnet in the snapshot is invalid
+func (ipam *IPAM) Restore(snapshot *Snapshot) error {
+	subnets := make(map[string]*Subnet, len(snapshot.Subnets))
+	for name, s := range snapshot.Subnets {
+		subnet, err := restoreSubnet(name, s)
+		if err != nil {
+			return fmt.Errorf("failed to restore subnet %s: %v", name, err)
+		}
+		subnets[name] = subnet
+	}
+
+	ipam.mutex.Lock()
+	defer ipam.mutex.Unlock()
+	ipam.Subnets = subnets
+	return nil
+}
+
+// ReleaseAddressByNic releases the addresses of the nic in all subnets, whichever pod it belongs to
+func (ipam *IPAM) ReleaseAddressByNic(nicName string) {
+	ipam.mutex.RLock()
+	defer ipam.mutex.RUnlock()
+	for _, subnet := range ipam.Subnets {
+		subnet.ReleaseAddressByNic(nicName)
+	}
+}
+
+func (subnet *Subnet) ReleaseAddressByNic(nicName string) {
+	subnet.mutex.Lock()
+	defer subnet.mutex.Unlock()
+
+	var pods []string
+	if ip, ok := subnet.V4NicToIP[nicName]; ok {
+		pods = strings.Split(subnet.V4IPToPod[ip], ",")
+	} else if ip, ok := subnet.V6NicToIP[nicName]; ok {
+		pods = strings.Split(subnet.V6IPToPod[ip], ",")
+	}
+	for _, podName := range pods {
+		if !util.ContainsString(subnet.PodToNicList[podName], nicName) {
+			continue
+		}
+		subnet.releaseAddr(podName, nicName)
+		subnet.popPodNic(podName, nicName)
+	}
+}
+
+// Matches returns whether the subnet was snapshotted with the same cidr, gateway and exclude ips
+func (s *SubnetSnapshot) Matches(cidrStr, gw string, excludeIps []string) bool {
+	v4CIDRs, v6CIDRs, err := parseCIDRs(cidrStr)
+	if err != nil {
+		return false
+	}
+	if s.CIDR != formatCIDRs(v4CIDRs, v6CIDRs) || s.Gateway != gw {
+		return false
+	}
+
+	v4ExcludeIps, v6ExcludeIps := util.SplitIpsByProtocol(util.ExpandExcludeIPs(excludeIps, cidrStr))
+	return equalStrings(s.V4Reserved, formatIPRangeList(convertExcludeIps(v4ExcludeIps))) &&
+		equalStrings(s.V6Reserved, formatIPRangeList(convertExcludeIps(v6ExcludeIps)))
+}
+
+// Nics returns the names of all the nics holding addresses in the subnet
+func (s *SubnetSnapshot) Nics() []string {
+	nics := make([]string, 0, len(s.V4NicToIP)+len(s.V6NicToIP))
+	for nic := range s.V4NicToIP {
+		nics = append(nics, nic)
+	}
+	for nic := range s.V6NicToIP {
+		if _, ok := s.V4NicToIP[nic]; !ok {
+			nics = append(nics, nic)
+		}
+	}
+	return nics
+}
+
+// Encode serializes the snapshot to gzipped json
+func (s *Snapshot) Encode() ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(w).Encode(s); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeSnapshot parses the data produced by Snapshot.Encode
+func DecodeSnapshot(data []byte) (*Snapshot, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{}
+	if err = json.Unmarshal(raw, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func (subnet *Subnet) snapshot() *SubnetSnapshot {
+	subnet.mutex.RLock()
+	defer subnet.mutex.RUnlock()
+
+	var gws []string
+	if subnet.V4CIDR != nil {
+		gws = append(gws, subnet.V4Gw)
+	}
+	if subnet.V6CIDR != nil {
+		gws = append(gws, subnet.V6Gw)
+	}
+
+	s := &SubnetSnapshot{
+		CIDR:         formatCIDRs(subnet.v4CIDRs(), subnet.v6CIDRs()),
+		Gateway:      strings.Join(gws, ","),
+		V4Free:       formatIPRangeList(subnet.V4FreeIPList),
+		V4Released:   formatIPRangeList(subnet.V4ReleasedIPList),
+		V4Reserved:   formatIPRangeList(subnet.V4ReservedIPList),
+		V4NicToIP:    make(map[string]string, len(subnet.V4NicToIP)),
+		V4IPToPod:    make(map[string]string, len(subnet.V4IPToPod)),
+		V6Free:       formatIPRangeList(subnet.V6FreeIPList),
+		V6Released:   formatIPRangeList(subnet.V6ReleasedIPList),
+		V6Reserved:   formatIPRangeList(subnet.V6ReservedIPList),
+		V6NicToIP:    make(map[string]string, len(subnet.V6NicToIP)),
+		V6IPToPod:    make(map[string]string, len(subnet.V6IPToPod)),
+		NicToMac:     make(map[string]string, len(subnet.NicToMac)),
+		MacToPod:     make(map[string]string, len(subnet.MacToPod)),
+		PodToNicList: make(map[string][]string, len(subnet.PodToNicList)),
+		IPPools:      make(map[string]*IPPoolSnapshot, len(subnet.IPPools)),
+	}
+	for nic, ip := range subnet.V4NicToIP {
+		s.V4NicToIP[nic] = string(ip)
+	}
+	for ip, pod := range subnet.V4IPToPod {
+		s.V4IPToPod[string(ip)] = pod
+	}
+	for nic, ip := range subnet.V6NicToIP {
+		s.V6NicToIP[nic] = string(ip)
+	}
+	for ip, pod := range subnet.V6IPToPod {
+		s.V6IPToPod[string(ip)] = pod
+	}
+	for nic, mac := range subnet.NicToMac {
+		s.NicToMac[nic] = mac
+	}
+	for mac, pod := range subnet.MacToPod {
+		s.MacToPod[mac] = pod
+	}
+	for pod, nics := range subnet.PodToNicList {
+		if len(nics) != 0 {
+			s.PodToNicList[pod] = append([]string{}, nics...)
+		}
+	}
+	for name, pool := range subnet.IPPools {
+		s.IPPools[name] = &IPPoolSnapshot{
+			V4IPs:      formatIPRangeList(pool.V4IPs),
+			V4Free:     formatIPRangeList(pool.V4FreeIPList),
+			V4Released: formatIPRangeList(pool.V4ReleasedIPList),
+			V6IPs:      formatIPRangeList(pool.V6IPs),
+			V6Free:     formatIPRangeList(pool.V6FreeIPList),
+			V6Released: formatIPRangeList(pool.V6ReleasedIPList),
+		}
+	}
+	return s
+}
+
+func restoreSubnet(name string, s *SubnetSnapshot) (*Subnet, error) {
+	v4CIDRs, v6CIDRs, err := parseCIDRs(s.CIDR)
+	if err != nil {
+		return nil, err
+	}
+	subnet := &Subnet{
+		Name:         name,
+		mutex:        sync.RWMutex{},
+		Protocol:     cidrsProtocol(v4CIDRs, v6CIDRs),
+		V4NicToIP:    make(map[string]IP, len(s.V4NicToIP)),
+		V4IPToPod:    make(map[IP]string, len(s.V4IPToPod)),
+		V6NicToIP:    make(map[string]IP, len(s.V6NicToIP)),
+		V6IPToPod:    make(map[IP]string, len(s.V6IPToPod)),
+		NicToMac:     make(map[string]string, len(s.NicToMac)),
+		MacToPod:     make(map[string]string, len(s.MacToPod)),
+		PodToNicList: make(map[string][]string, len(s.PodToNicList)),
+		IPPools:      make(map[string]*IPPool, len(s.IPPools)),
+	}
+
+	for _, gw := range strings.Split(s.Gateway, ",") {
+		if util.CheckProtocol(gw) == kubeovnv1.ProtocolIPv4 {
+			subnet.V4Gw = gw
+		} else if util.CheckProtocol(gw) == kubeovnv1.ProtocolIPv6 {
+			subnet.V6Gw = gw
+		}
+	}
+	if len(v4CIDRs) != 0 {
+		subnet.V4CIDR, subnet.V4SecondaryCIDRs = v4CIDRs[0], v4CIDRs[1:]
+	}
+	if len(v6CIDRs) != 0 {
+		subnet.V6CIDR, subnet.V6SecondaryCIDRs = v6CIDRs[0], v6CIDRs[1:]
+	}
+
+	lists := []struct {
+		dst *IPRangeList
+		src []string
+	}{
+		{&subnet.V4FreeIPList, s.V4Free},
+		{&subnet.V4ReleasedIPList, s.V4Released},
+		{&subnet.V4ReservedIPList, s.V4Reserved},
+		{&subnet.V6FreeIPList, s.V6Free},
+		{&subnet.V6ReleasedIPList, s.V6Released},
+		{&subnet.V6ReservedIPList, s.V6Reserved},
+	}
+	for _, l := range lists {
+		if *l.dst, err = parseIPRangeList(l.src); err != nil {
+			return nil, err
+		}
+	}
+
+	for nic, ip := range s.V4NicToIP {
+		subnet.V4NicToIP[nic] = IP(ip)
+	}
+	for ip, pod := range s.V4IPToPod {
+		subnet.V4IPToPod[IP(ip)] = pod
+	}
+	for nic, ip := range s.V6NicToIP {
+		subnet.V6NicToIP[nic] = IP(ip)
+	}
+	for ip, pod := range s.V6IPToPod {
+		subnet.V6IPToPod[IP(ip)] = pod
+	}
+	for nic, mac := range s.NicToMac {
+		subnet.NicToMac[nic] = mac
+	}
+	for mac, pod := range s.MacToPod {
+		subnet.MacToPod[mac] = pod
+	}
+	for pod, nics := range s.PodToNicList {
+		subnet.PodToNicList[pod] = append([]string{}, nics...)
+	}
+
+	for poolName, p := range s.IPPools {
+		pool := &IPPool{Name: poolName}
+		lists := []struct {
+			dst *IPRangeList
+			src []string
+		}{
+			{&pool.V4IPs, p.V4IPs},
+			{&pool.V4FreeIPList, p.V4Free},
+			{&pool.V4ReleasedIPList, p.V4Released},
+			{&pool.V6IPs, p.V6IPs},
+			{&pool.V6FreeIPList, p.V6Free},
+			{&pool.V6ReleasedIPList, p.V6Released},
+		}
+		for _, l := range lists {
+			if *l.dst, err = parseIPRangeList(l.src); err != nil {
+				return nil, fmt.Errorf("invalid ippool %s: %v", poolName, err)
+			}
+		}
+		subnet.IPPools[poolName] = pool
+	}
+	return subnet, nil
+}
+
+func formatIPRangeList(iprl IPRangeList) []string {
+	var ranges []string
+	for _, ipr := range iprl {
+		if ipr.Start.Equal(ipr.End) {
+			ranges = append(ranges, string(ipr.Start))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%s..%s", ipr.Start, ipr.End))
+		}
+	}
+	return ranges
+}
+
+func parseIPRangeList(ranges []string) (IPRangeList, error) {
+	iprl := make(IPRangeList, 0, len(ranges))
+	for _, r := range ranges {
+		parts := strings.Split(r, "..")
+		if len(parts) > 2 || net.ParseIP(parts[0]) == nil || net.ParseIP(parts[len(parts)-1]) == nil {
+			return nil, fmt.Errorf("invalid ip range %s", r)
+		}
+		iprl = append(iprl, &IPRange{Start: IP(parts[0]), End: IP(parts[len(parts)-1])})
+	}
+	return iprl, nil
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
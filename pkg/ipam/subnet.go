@@ -17,12 +17,14 @@ type Subnet struct {
 	mutex            sync.RWMutex
 	Protocol         string
 	V4CIDR           *net.IPNet
+	V4SecondaryCIDRs []*net.IPNet
 	V4FreeIPList     IPRangeList
 	V4ReleasedIPList IPRangeList
 	V4ReservedIPList IPRangeList
 	V4NicToIP        map[string]IP
 	V4IPToPod        map[IP]string
 	V6CIDR           *net.IPNet
+	V6SecondaryCIDRs []*net.IPNet
 	V6FreeIPList     IPRangeList
 	V6ReleasedIPList IPRangeList
 	V6ReservedIPList IPRangeList
@@ -33,35 +35,32 @@ type Subnet struct {
 	PodToNicList     map[string][]string
 	V4Gw             string
 	V6Gw             string
+	IPPools          map[string]*IPPool
 }
 
+// NewSubnet creates a subnet from the comma separated cidr blocks, the first block of each protocol
+// is the primary cidr and the others are secondary cidrs sharing the same logical switch
 func NewSubnet(name, cidrStr string, excludeIps []string) (*Subnet, error) {
 	excludeIps = util.ExpandExcludeIPs(excludeIps, cidrStr)
 
-	var cidrs []*net.IPNet
-	for _, cidrBlock := range strings.Split(cidrStr, ",") {
-		if _, cidr, err := net.ParseCIDR(cidrBlock); err != nil {
-			return nil, ErrInvalidCIDR
-		} else {
-			cidrs = append(cidrs, cidr)
-		}
+	v4CIDRs, v6CIDRs, err := parseCIDRs(cidrStr)
+	if err != nil {
+		return nil, err
 	}
 
 	// subnet.Spec.ExcludeIps contains both v4 and v6 addresses
 	v4ExcludeIps, v6ExcludeIps := util.SplitIpsByProtocol(excludeIps)
 
 	subnet := Subnet{}
-	protocol := util.CheckProtocol(cidrStr)
+	protocol := cidrsProtocol(v4CIDRs, v6CIDRs)
 	if protocol == kubeovnv1.ProtocolIPv4 {
-		firstIP, _ := util.FirstIP(cidrStr)
-		lastIP, _ := util.LastIP(cidrStr)
-
 		subnet = Subnet{
 			Name:             name,
 			mutex:            sync.RWMutex{},
 			Protocol:         protocol,
-			V4CIDR:           cidrs[0],
-			V4FreeIPList:     IPRangeList{&IPRange{Start: IP(firstIP), End: IP(lastIP)}},
+			V4CIDR:           v4CIDRs[0],
+			V4SecondaryCIDRs: v4CIDRs[1:],
+			V4FreeIPList:     cidrsToIPRangeList(v4CIDRs),
 			V4ReleasedIPList: IPRangeList{},
 			V4ReservedIPList: convertExcludeIps(v4ExcludeIps),
 			V4NicToIP:        map[string]IP{},
@@ -71,18 +70,17 @@ func NewSubnet(name, cidrStr string, excludeIps []string) (*Subnet, error) {
 			MacToPod:         map[string]string{},
 			NicToMac:         map[string]string{},
 			PodToNicList:     map[string][]string{},
+			IPPools:          map[string]*IPPool{},
 		}
 		subnet.joinFreeWithReserve()
 	} else if protocol == kubeovnv1.ProtocolIPv6 {
-		firstIP, _ := util.FirstIP(cidrStr)
-		lastIP, _ := util.LastIP(cidrStr)
-
 		subnet = Subnet{
 			Name:             name,
 			mutex:            sync.RWMutex{},
 			Protocol:         protocol,
-			V6CIDR:           cidrs[0],
-			V6FreeIPList:     IPRangeList{&IPRange{Start: IP(firstIP), End: IP(lastIP)}},
+			V6CIDR:           v6CIDRs[0],
+			V6SecondaryCIDRs: v6CIDRs[1:],
+			V6FreeIPList:     cidrsToIPRangeList(v6CIDRs),
 			V6ReleasedIPList: IPRangeList{},
 			V6ReservedIPList: convertExcludeIps(v6ExcludeIps),
 			V4NicToIP:        map[string]IP{},
@@ -92,27 +90,24 @@ func NewSubnet(name, cidrStr string, excludeIps []string) (*Subnet, error) {
 			MacToPod:         map[string]string{},
 			NicToMac:         map[string]string{},
 			PodToNicList:     map[string][]string{},
+			IPPools:          map[string]*IPPool{},
 		}
 		subnet.joinFreeWithReserve()
 	} else {
-		cidrBlocks := strings.Split(cidrStr, ",")
-		v4FirstIP, _ := util.FirstIP(cidrBlocks[0])
-		v4LastIP, _ := util.LastIP(cidrBlocks[0])
-		v6FirstIP, _ := util.FirstIP(cidrBlocks[1])
-		v6LastIP, _ := util.LastIP(cidrBlocks[1])
-
 		subnet = Subnet{
 			Name:             name,
 			mutex:            sync.RWMutex{},
 			Protocol:         protocol,
-			V4CIDR:           cidrs[0],
-			V4FreeIPList:     IPRangeList{&IPRange{Start: IP(v4FirstIP), End: IP(v4LastIP)}},
+			V4CIDR:           v4CIDRs[0],
+			V4SecondaryCIDRs: v4CIDRs[1:],
+			V4FreeIPList:     cidrsToIPRangeList(v4CIDRs),
 			V4ReleasedIPList: IPRangeList{},
 			V4ReservedIPList: convertExcludeIps(v4ExcludeIps),
 			V4NicToIP:        map[string]IP{},
 			V4IPToPod:        map[IP]string{},
-			V6CIDR:           cidrs[1],
-			V6FreeIPList:     IPRangeList{&IPRange{Start: IP(v6FirstIP), End: IP(v6LastIP)}},
+			V6CIDR:           v6CIDRs[0],
+			V6SecondaryCIDRs: v6CIDRs[1:],
+			V6FreeIPList:     cidrsToIPRangeList(v6CIDRs),
 			V6ReleasedIPList: IPRangeList{},
 			V6ReservedIPList: convertExcludeIps(v6ExcludeIps),
 			V6NicToIP:        map[string]IP{},
@@ -120,12 +115,125 @@ func NewSubnet(name, cidrStr string, excludeIps []string) (*Subnet, error) {
 			MacToPod:         map[string]string{},
 			NicToMac:         map[string]string{},
 			PodToNicList:     map[string][]string{},
+			IPPools:          map[string]*IPPool{},
 		}
 		subnet.joinFreeWithReserve()
 	}
 	return &subnet, nil
 }
 
+// parseCIDRs parses the comma separated cidr blocks into the cidrs of each protocol in order,
+// the first cidr of a protocol is the primary one
+func parseCIDRs(cidrStr string) (v4CIDRs, v6CIDRs []*net.IPNet, err error) {
+	for _, cidrBlock := range strings.Split(cidrStr, ",") {
+		_, cidr, err := net.ParseCIDR(strings.TrimSpace(cidrBlock))
+		if err != nil {
+			return nil, nil, ErrInvalidCIDR
+		}
+		if cidr.IP.To4() != nil {
+			v4CIDRs = append(v4CIDRs, cidr)
+		} else {
+			v6CIDRs = append(v6CIDRs, cidr)
+		}
+	}
+	return v4CIDRs, v6CIDRs, nil
+}
+
+// formatCIDRs formats the primary cidrs followed by the secondary ones, the reverse of parseCIDRs
+func formatCIDRs(v4CIDRs, v6CIDRs []*net.IPNet) string {
+	var cidrs []string
+	if len(v4CIDRs) != 0 {
+		cidrs = append(cidrs, v4CIDRs[0].String())
+	}
+	if len(v6CIDRs) != 0 {
+		cidrs = append(cidrs, v6CIDRs[0].String())
+	}
+	for _, list := range [][]*net.IPNet{v4CIDRs, v6CIDRs} {
+		for i := 1; i < len(list); i++ {
+			cidrs = append(cidrs, list[i].String())
+		}
+	}
+	return strings.Join(cidrs, ",")
+}
+
+func cidrsProtocol(v4CIDRs, v6CIDRs []*net.IPNet) string {
+	switch {
+	case len(v4CIDRs) != 0 && len(v6CIDRs) != 0:
+		return kubeovnv1.ProtocolDual
+	case len(v6CIDRs) != 0:
+		return kubeovnv1.ProtocolIPv6
+	default:
+		return kubeovnv1.ProtocolIPv4
+	}
+}
+
+func cidrsToIPRangeList(cidrs []*net.IPNet) IPRangeList {
+	ranges := make(IPRangeList, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		firstIP, _ := util.FirstIP(cidr.String())
+		lastIP, _ := util.LastIP(cidr.String())
+		ranges = append(ranges, &IPRange{Start: IP(firstIP), End: IP(lastIP)})
+	}
+	return ranges
+}
+
+// cidrIndex returns the index of the cidr containing the ip, or -1 if none contains it
+func cidrIndex(cidrs []*net.IPNet, ip IP) int {
+	addr := net.ParseIP(string(ip))
+	for i, cidr := range cidrs {
+		if cidr.Contains(addr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// v4CIDRs returns the primary and secondary ipv4 cidrs in allocation order
+func (subnet *Subnet) v4CIDRs() []*net.IPNet {
+	if subnet.V4CIDR == nil {
+		return nil
+	}
+	return append([]*net.IPNet{subnet.V4CIDR}, subnet.V4SecondaryCIDRs...)
+}
+
+// v6CIDRs returns the primary and secondary ipv6 cidrs in allocation order
+func (subnet *Subnet) v6CIDRs() []*net.IPNet {
+	if subnet.V6CIDR == nil {
+		return nil
+	}
+	return append([]*net.IPNet{subnet.V6CIDR}, subnet.V6SecondaryCIDRs...)
+}
+
+// pickFreeIP returns the first address not skipped in the free list and the index of its range,
+// ranges in a former cidr are preferred so that a secondary cidr is used only when the former ones are exhausted
+func pickFreeIP(freeList IPRangeList, cidrs []*net.IPNet, skippedAddrs []string) (IP, int) {
+	var ip IP
+	idx, rank := -1, len(cidrs)
+	for i, ipr := range freeList {
+		var candidate IP
+		for next := ipr.Start; !next.GreaterThan(ipr.End); next = next.Add(1) {
+			if !util.ContainsString(skippedAddrs, string(next)) {
+				candidate = next
+				break
+			}
+		}
+		if candidate == "" {
+			continue
+		}
+		r := cidrIndex(cidrs, candidate)
+		if r < 0 {
+			r = len(cidrs)
+		}
+		if ip == "" || r < rank {
+			ip, idx, rank = candidate, i, r
+		}
+		if rank == 0 {
+			break
+		}
+	}
+	return ip, idx
+}
+
 func (subnet *Subnet) GetRandomMac(podName, nicName string) string {
 	if mac, ok := subnet.NicToMac[nicName]; ok {
 		return mac
@@ -161,41 +269,47 @@ func (subnet *Subnet) popPodNic(podName, nicName string) {
 	subnet.PodToNicList[podName] = util.RemoveString(subnet.PodToNicList[podName], nicName)
 }
 
-func (subnet *Subnet) GetRandomAddress(podName, nicName string, mac string, skippedAddrs []string, checkConflict bool) (IP, IP, string, error) {
+func (subnet *Subnet) GetRandomAddress(poolName, podName, nicName string, mac string, skippedAddrs []string, checkConflict bool) (IP, IP, string, error) {
 	subnet.mutex.Lock()
 	defer func() {
 		subnet.pushPodNic(podName, nicName)
 		subnet.mutex.Unlock()
 	}()
 
+	if poolName != "" {
+		if _, ok := subnet.IPPools[poolName]; !ok {
+			return "", "", "", ErrNoAvailable
+		}
+	}
+
 	if subnet.Protocol == kubeovnv1.ProtocolDual {
-		return subnet.getDualRandomAddress(podName, nicName, mac, skippedAddrs, checkConflict)
+		return subnet.getDualRandomAddress(poolName, podName, nicName, mac, skippedAddrs, checkConflict)
 	} else if subnet.Protocol == kubeovnv1.ProtocolIPv4 {
-		return subnet.getV4RandomAddress(podName, nicName, mac, skippedAddrs, checkConflict)
+		return subnet.getV4RandomAddress(poolName, podName, nicName, mac, skippedAddrs, checkConflict)
 	} else {
-		return subnet.getV6RandomAddress(podName, nicName, mac, skippedAddrs, checkConflict)
+		return subnet.getV6RandomAddress(poolName, podName, nicName, mac, skippedAddrs, checkConflict)
 	}
 }
 
-func (subnet *Subnet) getDualRandomAddress(podName, nicName string, mac string, skippedAddrs []string, checkConflict bool) (IP, IP, string, error) {
-	v4IP, _, _, err := subnet.getV4RandomAddress(podName, nicName, mac, skippedAddrs, checkConflict)
+func (subnet *Subnet) getDualRandomAddress(poolName, podName, nicName string, mac string, skippedAddrs []string, checkConflict bool) (IP, IP, string, error) {
+	v4IP, _, _, err := subnet.getV4RandomAddress(poolName, podName, nicName, mac, skippedAddrs, checkConflict)
 	if err != nil {
 		return "", "", "", err
 	}
-	_, v6IP, mac, err := subnet.getV6RandomAddress(podName, nicName, mac, skippedAddrs, checkConflict)
+	_, v6IP, mac, err := subnet.getV6RandomAddress(poolName, podName, nicName, mac, skippedAddrs, checkConflict)
 	if err != nil {
 		return "", "", "", err
 	}
 
 	// allocated IPv4 address may be released in getV6RandomAddress()
 	if subnet.V4NicToIP[nicName] != v4IP {
-		v4IP, _, _, _ = subnet.getV4RandomAddress(podName, nicName, mac, skippedAddrs, checkConflict)
+		v4IP, _, _, _ = subnet.getV4RandomAddress(poolName, podName, nicName, mac, skippedAddrs, checkConflict)
 	}
 
 	return v4IP, v6IP, mac, nil
 }
 
-func (subnet *Subnet) getV4RandomAddress(podName, nicName string, mac string, skippedAddrs []string, checkConflict bool) (IP, IP, string, error) {
+func (subnet *Subnet) getV4RandomAddress(poolName, podName, nicName string, mac string, skippedAddrs []string, checkConflict bool) (IP, IP, string, error) {
 	// After 'macAdd' introduced to support only static mac address, pod restart will run into error mac AddressConflict
 	// controller will re-enqueue the new pod then wait for old pod deleted and address released.
 	// here will return only if both ip and mac exist, otherwise only ip without mac returned will trigger CreatePort error.
@@ -205,41 +319,32 @@ func (subnet *Subnet) getV4RandomAddress(podName, nicName string, mac string, sk
 		}
 		subnet.releaseAddr(podName, nicName)
 	}
-	if len(subnet.V4FreeIPList) == 0 {
-		if len(subnet.V4ReleasedIPList) == 0 {
+	freeList, releasedList := &subnet.V4FreeIPList, &subnet.V4ReleasedIPList
+	if pool := subnet.IPPools[poolName]; pool != nil {
+		freeList, releasedList = &pool.V4FreeIPList, &pool.V4ReleasedIPList
+	}
+	if len(*freeList) == 0 {
+		if len(*releasedList) == 0 {
 			return "", "", "", ErrNoAvailable
 		}
-		subnet.V4FreeIPList = subnet.V4ReleasedIPList
-		subnet.V4ReleasedIPList = IPRangeList{}
+		*freeList = *releasedList
+		*releasedList = IPRangeList{}
 	}
 
-	var ip IP
-	var idx int
-	for i, ipr := range subnet.V4FreeIPList {
-		for next := ipr.Start; !next.GreaterThan(ipr.End); next = next.Add(1) {
-			if !util.ContainsString(skippedAddrs, string(next)) {
-				ip = next
-				break
-			}
-		}
-		if ip != "" {
-			idx = i
-			break
-		}
-	}
+	ip, idx := pickFreeIP(*freeList, subnet.v4CIDRs(), skippedAddrs)
 	if ip == "" {
 		return "", "", "", ErrConflict
 	}
 
-	ipr := subnet.V4FreeIPList[idx]
+	ipr := (*freeList)[idx]
 	part1 := &IPRange{Start: ipr.Start, End: ip.Sub(1)}
 	part2 := &IPRange{Start: ip.Add(1), End: ipr.End}
-	subnet.V4FreeIPList = append(subnet.V4FreeIPList[:idx], subnet.V4FreeIPList[idx+1:]...)
+	*freeList = append((*freeList)[:idx], (*freeList)[idx+1:]...)
 	if !part1.Start.GreaterThan(part1.End) {
-		subnet.V4FreeIPList = append(subnet.V4FreeIPList, part1)
+		*freeList = append(*freeList, part1)
 	}
 	if !part2.Start.GreaterThan(part2.End) {
-		subnet.V4FreeIPList = append(subnet.V4FreeIPList, part2)
+		*freeList = append(*freeList, part2)
 	}
 
 	subnet.V4NicToIP[nicName] = ip
@@ -255,7 +360,7 @@ func (subnet *Subnet) getV4RandomAddress(podName, nicName string, mac string, sk
 	}
 }
 
-func (subnet *Subnet) getV6RandomAddress(podName, nicName string, mac string, skippedAddrs []string, checkConflict bool) (IP, IP, string, error) {
+func (subnet *Subnet) getV6RandomAddress(poolName, podName, nicName string, mac string, skippedAddrs []string, checkConflict bool) (IP, IP, string, error) {
 	// After 'macAdd' introduced to support only static mac address, pod restart will run into error mac AddressConflict
 	// controller will re-enqueue the new pod then wait for old pod deleted and address released.
 	// here will return only if both ip and mac exist, otherwise only ip without mac returned will trigger CreatePort error.
@@ -266,41 +371,32 @@ func (subnet *Subnet) getV6RandomAddress(podName, nicName string, mac string, sk
 		subnet.releaseAddr(podName, nicName)
 	}
 
-	if len(subnet.V6FreeIPList) == 0 {
-		if len(subnet.V6ReleasedIPList) == 0 {
+	freeList, releasedList := &subnet.V6FreeIPList, &subnet.V6ReleasedIPList
+	if pool := subnet.IPPools[poolName]; pool != nil {
+		freeList, releasedList = &pool.V6FreeIPList, &pool.V6ReleasedIPList
+	}
+	if len(*freeList) == 0 {
+		if len(*releasedList) == 0 {
 			return "", "", "", ErrNoAvailable
 		}
-		subnet.V6FreeIPList = subnet.V6ReleasedIPList
-		subnet.V6ReleasedIPList = IPRangeList{}
+		*freeList = *releasedList
+		*releasedList = IPRangeList{}
 	}
 
-	var ip IP
-	var idx int
-	for i, ipr := range subnet.V6FreeIPList {
-		for next := ipr.Start; !next.GreaterThan(ipr.End); next = next.Add(1) {
-			if !util.ContainsString(skippedAddrs, string(next)) {
-				ip = next
-				break
-			}
-		}
-		if ip != "" {
-			idx = i
-			break
-		}
-	}
+	ip, idx := pickFreeIP(*freeList, subnet.v6CIDRs(), skippedAddrs)
 	if ip == "" {
 		return "", "", "", ErrConflict
 	}
 
-	ipr := subnet.V6FreeIPList[idx]
+	ipr := (*freeList)[idx]
 	part1 := &IPRange{Start: ipr.Start, End: ip.Sub(1)}
 	part2 := &IPRange{Start: ip.Add(1), End: ipr.End}
-	subnet.V6FreeIPList = append(subnet.V6FreeIPList[:idx], subnet.V6FreeIPList[idx+1:]...)
+	*freeList = append((*freeList)[:idx], (*freeList)[idx+1:]...)
 	if !part1.Start.GreaterThan(part1.End) {
-		subnet.V6FreeIPList = append(subnet.V6FreeIPList, part1)
+		*freeList = append(*freeList, part1)
 	}
 	if !part2.Start.GreaterThan(part2.End) {
-		subnet.V6FreeIPList = append(subnet.V6FreeIPList, part2)
+		*freeList = append(*freeList, part2)
 	}
 
 	subnet.V6NicToIP[nicName] = ip
@@ -329,10 +425,10 @@ func (subnet *Subnet) GetStaticAddress(podName, nicName string, ip IP, mac strin
 	} else {
 		v6 = subnet.V6CIDR != nil
 	}
-	if v4 && !subnet.V4CIDR.Contains(net.ParseIP(string(ip))) {
+	if v4 && cidrIndex(subnet.v4CIDRs(), ip) < 0 {
 		return ip, mac, ErrOutOfRange
 	}
-	if v6 && !subnet.V6CIDR.Contains(net.ParseIP(string(ip))) {
+	if v6 && cidrIndex(subnet.v6CIDRs(), ip) < 0 {
 		return ip, mac, ErrOutOfRange
 	}
 
@@ -383,6 +479,12 @@ func (subnet *Subnet) GetStaticAddress(podName, nicName string, ip IP, mac strin
 				return ip, mac, nil
 			}
 		}
+
+		if pool := subnet.getIPPoolByIP(ip); pool != nil && pool.take(ip) {
+			subnet.V4NicToIP[nicName] = ip
+			subnet.V4IPToPod[ip] = podName
+			return ip, mac, nil
+		}
 	} else if v6 {
 		if existPod, ok := subnet.V6IPToPod[ip]; ok {
 			pods := strings.Split(existPod, ",")
@@ -418,6 +520,12 @@ func (subnet *Subnet) GetStaticAddress(podName, nicName string, ip IP, mac strin
 				return ip, mac, nil
 			}
 		}
+
+		if pool := subnet.getIPPoolByIP(ip); pool != nil && pool.take(ip) {
+			subnet.V6NicToIP[nicName] = ip
+			subnet.V6IPToPod[ip] = podName
+			return ip, mac, nil
+		}
 	}
 	return ip, mac, ErrNoAvailable
 }
@@ -439,7 +547,7 @@ func (subnet *Subnet) releaseAddr(podName, nicName string) {
 			}
 
 			// When CIDR changed, do not relocate ip to CIDR list
-			if !subnet.V4CIDR.Contains(net.ParseIP(string(ip))) {
+			if cidrIndex(subnet.v4CIDRs(), ip) < 0 {
 				// Continue to release IPv6 address
 				klog.Infof("release v4 %s mac %s for %s, ignore ip", ip, mac, podName)
 				changed = true
@@ -450,7 +558,12 @@ func (subnet *Subnet) releaseAddr(podName, nicName string) {
 				changed = true
 			}
 
-			if merged, newReleasedList := mergeIPRangeList(subnet.V4ReleasedIPList, ip); !changed && merged {
+			if pool := subnet.getIPPoolByIP(ip); pool != nil {
+				if merged, newReleasedList := mergeIPRangeList(pool.V4ReleasedIPList, ip); !changed && merged {
+					pool.V4ReleasedIPList = newReleasedList
+					klog.Infof("release v4 %s mac %s for %s, add ip to released list of ippool %s", ip, mac, podName, pool.Name)
+				}
+			} else if merged, newReleasedList := mergeIPRangeList(subnet.V4ReleasedIPList, ip); !changed && merged {
 				subnet.V4ReleasedIPList = newReleasedList
 				klog.Infof("release v4 %s mac %s for %s, add ip to released list", ip, mac, podName)
 			}
@@ -470,7 +583,7 @@ func (subnet *Subnet) releaseAddr(podName, nicName string) {
 			}
 			changed = false
 			// When CIDR changed, do not relocate ip to CIDR list
-			if !subnet.V6CIDR.Contains(net.ParseIP(string(ip))) {
+			if cidrIndex(subnet.v6CIDRs(), ip) < 0 {
 				klog.Infof("release v6 %s mac %s for %s, ignore ip", ip, mac, podName)
 				changed = true
 			}
@@ -480,7 +593,12 @@ func (subnet *Subnet) releaseAddr(podName, nicName string) {
 				changed = true
 			}
 
-			if merged, newReleasedList := mergeIPRangeList(subnet.V6ReleasedIPList, ip); !changed && merged {
+			if pool := subnet.getIPPoolByIP(ip); pool != nil {
+				if merged, newReleasedList := mergeIPRangeList(pool.V6ReleasedIPList, ip); !changed && merged {
+					pool.V6ReleasedIPList = newReleasedList
+					klog.Infof("release v6 %s mac %s for %s, add ip to released list of ippool %s", ip, mac, podName, pool.Name)
+				}
+			} else if merged, newReleasedList := mergeIPRangeList(subnet.V6ReleasedIPList, ip); !changed && merged {
 				subnet.V6ReleasedIPList = newReleasedList
 				klog.Infof("release v6 %s mac %s for %s, add ip to released list", ip, mac, podName)
 			}
@@ -509,6 +627,30 @@ func (subnet *Subnet) ContainAddress(address IP) bool {
 	return false
 }
 
+// checkAddressesInCIDR returns an error if any allocated address is out of the new cidrs of the same protocol,
+// addresses of a protocol without new cidrs are not checked
+func (subnet *Subnet) checkAddressesInCIDR(v4CIDRs, v6CIDRs []*net.IPNet) error {
+	subnet.mutex.RLock()
+	defer subnet.mutex.RUnlock()
+
+	check := func(cidrs []*net.IPNet, nicToIP map[string]IP) error {
+		if len(cidrs) == 0 {
+			return nil
+		}
+		for nicName, ip := range nicToIP {
+			if cidrIndex(cidrs, ip) < 0 {
+				return fmt.Errorf("%w: address %s of %s is out of cidr %s", ErrOutOfRange, ip, nicName, formatCIDRs(cidrs, nil))
+			}
+		}
+		return nil
+	}
+
+	if err := check(v4CIDRs, subnet.V4NicToIP); err != nil {
+		return err
+	}
+	return check(v6CIDRs, subnet.V6NicToIP)
+}
+
 func (subnet *Subnet) joinFreeWithReserve() {
 	protocol := subnet.Protocol
 	if protocol == kubeovnv1.ProtocolDual || protocol == kubeovnv1.ProtocolIPv4 {
@@ -568,3 +710,171 @@ func (subnet *Subnet) isIPAssignedToPod(ip, podName string) bool {
 	}
 	return false
 }
+
+func (subnet *Subnet) getIPPoolByIP(ip IP) *IPPool {
+	for _, pool := range subnet.IPPools {
+		if pool.contains(ip) {
+			return pool
+		}
+	}
+	return nil
+}
+
+// joinFreeWithIPPools moves the free and released addresses belonging to ippools
+// from the subnet to the ippools
+func (subnet *Subnet) joinFreeWithIPPools(protocol string) {
+	for _, pool := range subnet.IPPools {
+		subnet.joinFreeWithIPPool(pool, protocol)
+	}
+}
+
+func (subnet *Subnet) joinFreeWithIPPool(pool *IPPool, protocol string) {
+	if protocol == kubeovnv1.ProtocolIPv4 {
+		pool.V4FreeIPList = intersectIPRangeList(subnet.V4FreeIPList, pool.V4IPs)
+		pool.V4ReleasedIPList = intersectIPRangeList(subnet.V4ReleasedIPList, pool.V4IPs)
+		subnet.V4FreeIPList = separateIPRangeList(subnet.V4FreeIPList, pool.V4IPs)
+		subnet.V4ReleasedIPList = separateIPRangeList(subnet.V4ReleasedIPList, pool.V4IPs)
+	} else {
+		pool.V6FreeIPList = intersectIPRangeList(subnet.V6FreeIPList, pool.V6IPs)
+		pool.V6ReleasedIPList = intersectIPRangeList(subnet.V6ReleasedIPList, pool.V6IPs)
+		subnet.V6FreeIPList = separateIPRangeList(subnet.V6FreeIPList, pool.V6IPs)
+		subnet.V6ReleasedIPList = separateIPRangeList(subnet.V6ReleasedIPList, pool.V6IPs)
+	}
+}
+
+// returnIPPool gives the free and released addresses of the ippool back to the subnet
+func (subnet *Subnet) returnIPPool(pool *IPPool) {
+	subnet.V4FreeIPList = unionIPRangeList(subnet.V4FreeIPList, pool.V4FreeIPList)
+	subnet.V4ReleasedIPList = unionIPRangeList(subnet.V4ReleasedIPList, pool.V4ReleasedIPList)
+	subnet.V6FreeIPList = unionIPRangeList(subnet.V6FreeIPList, pool.V6FreeIPList)
+	subnet.V6ReleasedIPList = unionIPRangeList(subnet.V6ReleasedIPList, pool.V6ReleasedIPList)
+}
+
+func (subnet *Subnet) checkIPPoolRange(pool *IPPool) error {
+	v4CIDRs, v6CIDRs := subnet.v4CIDRs(), subnet.v6CIDRs()
+	for _, ipr := range pool.V4IPs {
+		if i := cidrIndex(v4CIDRs, ipr.Start); i < 0 || i != cidrIndex(v4CIDRs, ipr.End) {
+			return fmt.Errorf("%w: %s..%s is not in subnet %s", ErrOutOfRange, ipr.Start, ipr.End, subnet.Name)
+		}
+	}
+	for _, ipr := range pool.V6IPs {
+		if i := cidrIndex(v6CIDRs, ipr.Start); i < 0 || i != cidrIndex(v6CIDRs, ipr.End) {
+			return fmt.Errorf("%w: %s..%s is not in subnet %s", ErrOutOfRange, ipr.Start, ipr.End, subnet.Name)
+		}
+	}
+	for name, p := range subnet.IPPools {
+		if name == pool.Name {
+			continue
+		}
+		if len(intersectIPRangeList(p.V4IPs, pool.V4IPs)) != 0 || len(intersectIPRangeList(p.V6IPs, pool.V6IPs)) != 0 {
+			return fmt.Errorf("%w: ippool %s overlaps with ippool %s", ErrConflict, pool.Name, name)
+		}
+	}
+	return nil
+}
+
+func (subnet *Subnet) AddOrUpdateIPPool(name string, ips []string) error {
+	pool, err := NewIPPool(name, ips)
+	if err != nil {
+		return err
+	}
+
+	subnet.mutex.Lock()
+	defer subnet.mutex.Unlock()
+
+	if err = subnet.checkIPPoolRange(pool); err != nil {
+		return err
+	}
+	if oldPool, ok := subnet.IPPools[name]; ok {
+		subnet.returnIPPool(oldPool)
+	}
+
+	subnet.IPPools[name] = pool
+	subnet.joinFreeWithIPPool(pool, kubeovnv1.ProtocolIPv4)
+	subnet.joinFreeWithIPPool(pool, kubeovnv1.ProtocolIPv6)
+	return nil
+}
+
+func (subnet *Subnet) RemoveIPPool(name string) {
+	subnet.mutex.Lock()
+	defer subnet.mutex.Unlock()
+
+	if pool, ok := subnet.IPPools[name]; ok {
+		subnet.returnIPPool(pool)
+		delete(subnet.IPPools, name)
+	}
+}
+
+// IPPoolStatistics returns the count of available and using addresses of the ippool
+func (subnet *Subnet) IPPoolStatistics(name string) (v4Available, v4Using, v6Available, v6Using float64, err error) {
+	subnet.mutex.RLock()
+	defer subnet.mutex.RUnlock()
+
+	pool, ok := subnet.IPPools[name]
+	if !ok {
+		return 0, 0, 0, 0, fmt.Errorf("ippool %s not found in subnet %s", name, subnet.Name)
+	}
+
+	v4Available = pool.V4FreeIPList.Count() + pool.V4ReleasedIPList.Count()
+	v6Available = pool.V6FreeIPList.Count() + pool.V6ReleasedIPList.Count()
+	for ip := range subnet.V4IPToPod {
+		if pool.V4IPs.Contains(ip) {
+			v4Using++
+		}
+	}
+	for ip := range subnet.V6IPToPod {
+		if pool.V6IPs.Contains(ip) {
+			v6Using++
+		}
+	}
+	return v4Available, v4Using, v6Available, v6Using, nil
+}
+
+// CIDRUsage is the count of available and using addresses in a cidr of the subnet
+type CIDRUsage struct {
+	CIDR      string
+	Available float64
+	Using     float64
+}
+
+// CIDRStatistics returns the address usage of each cidr of the subnet, the primary cidrs come first
+func (subnet *Subnet) CIDRStatistics() []CIDRUsage {
+	subnet.mutex.RLock()
+	defer subnet.mutex.RUnlock()
+
+	v4Available := unionIPRangeList(subnet.V4FreeIPList, subnet.V4ReleasedIPList)
+	v6Available := unionIPRangeList(subnet.V6FreeIPList, subnet.V6ReleasedIPList)
+	for _, pool := range subnet.IPPools {
+		v4Available = unionIPRangeList(v4Available, unionIPRangeList(pool.V4FreeIPList, pool.V4ReleasedIPList))
+		v6Available = unionIPRangeList(v6Available, unionIPRangeList(pool.V6FreeIPList, pool.V6ReleasedIPList))
+	}
+
+	usage := func(cidr *net.IPNet, available IPRangeList, ipToPod map[IP]string) CIDRUsage {
+		u := CIDRUsage{
+			CIDR:      cidr.String(),
+			Available: intersectIPRangeList(available, cidrsToIPRangeList([]*net.IPNet{cidr})).Count(),
+		}
+		for ip := range ipToPod {
+			if cidr.Contains(net.ParseIP(string(ip))) {
+				u.Using++
+			}
+		}
+		return u
+	}
+
+	v4CIDRs, v6CIDRs := subnet.v4CIDRs(), subnet.v6CIDRs()
+	result := make([]CIDRUsage, 0, len(v4CIDRs)+len(v6CIDRs))
+	if len(v4CIDRs) != 0 {
+		result = append(result, usage(v4CIDRs[0], v4Available, subnet.V4IPToPod))
+	}
+	if len(v6CIDRs) != 0 {
+		result = append(result, usage(v6CIDRs[0], v6Available, subnet.V6IPToPod))
+	}
+	for i := 1; i < len(v4CIDRs); i++ {
+		result = append(result, usage(v4CIDRs[i], v4Available, subnet.V4IPToPod))
+	}
+	for i := 1; i < len(v6CIDRs); i++ {
+		result = append(result, usage(v6CIDRs[i], v6Available, subnet.V6IPToPod))
+	}
+	return result
+}
@@ -0,0 +1,566 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	"github.com/kubeovn/kube-ovn/pkg/ovs"
+	ovsclient "github.com/kubeovn/kube-ovn/pkg/ovsdb/client"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// Call is a method call recorded by the fake legacy client
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// LegacyClient is a fake ovs.LegacyOvnClient, logical switches, logical routers and port groups
+// are kept in the NB database it connects to, other methods only record their calls.
+// Errors injects the error returned by the method of the name,
+// Chassis holds the chassis of the nodes returned by GetChassis,
+// ServiceMonitors holds the service monitors returned by ListServiceMonitors.
+type LegacyClient struct {
+	Errors          map[string]error
+	Chassis         map[string]string
+	ServiceMonitors []ovs.ServiceMonitor
+
+	mutex sync.Mutex
+	calls []Call
+	nb    client.Client
+}
+
+var _ ovs.LegacyOvnClient = &LegacyClient{}
+
+// NewLegacyClient returns a fake legacy client backed by the NB database at nbAddr
+func NewLegacyClient(nbAddr string, timeout int) (*LegacyClient, error) {
+	nb, err := ovsclient.NewNbClient(nbAddr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &LegacyClient{Errors: make(map[string]error), Chassis: make(map[string]string), nb: nb}, nil
+}
+
+// Calls returns the recorded calls of the method, or all calls if method is empty
+func (c *LegacyClient) Calls(method string) []Call {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var calls []Call
+	for _, call := range c.calls {
+		if method == "" || call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Called returns whether the method has been called
+func (c *LegacyClient) Called(method string) bool {
+	return len(c.Calls(method)) != 0
+}
+
+// Reset clears the recorded calls
+func (c *LegacyClient) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls = nil
+}
+
+func (c *LegacyClient) record(method string, args ...interface{}) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+	return c.Errors[method]
+}
+
+func (c *LegacyClient) transact(ops ...[]ovsdb.Operation) error {
+	var allOps []ovsdb.Operation
+	for _, op := range ops {
+		allOps = append(allOps, op...)
+	}
+	if len(allOps) == 0 {
+		return nil
+	}
+	results, err := c.nb.Transact(context.TODO(), allOps...)
+	if err != nil {
+		return err
+	}
+	if _, err = ovsdb.CheckOperationResults(results, allOps); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *LegacyClient) getLogicalSwitch(name string) (*ovnnb.LogicalSwitch, error) {
+	var lsList []ovnnb.LogicalSwitch
+	if err := c.nb.WhereCache(func(ls *ovnnb.LogicalSwitch) bool {
+		return ls.Name == name
+	}).List(context.TODO(), &lsList); err != nil && err != client.ErrNotFound {
+		return nil, err
+	}
+	if len(lsList) == 0 {
+		return nil, nil
+	}
+	return &lsList[0], nil
+}
+
+func (c *LegacyClient) getLogicalRouter(name string) (*ovnnb.LogicalRouter, error) {
+	var lrList []ovnnb.LogicalRouter
+	if err := c.nb.WhereCache(func(lr *ovnnb.LogicalRouter) bool {
+		return lr.Name == name
+	}).List(context.TODO(), &lrList); err != nil && err != client.ErrNotFound {
+		return nil, err
+	}
+	if len(lrList) == 0 {
+		return nil, nil
+	}
+	return &lrList[0], nil
+}
+
+func (c *LegacyClient) getPortGroup(name string) (*ovnnb.PortGroup, error) {
+	pg := &ovnnb.PortGroup{Name: name}
+	if err := c.nb.Get(context.TODO(), pg); err != nil {
+		if err == client.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return pg, nil
+}
+
+func vendorMatches(externalIDs map[string]string, needVendorFilter bool) bool {
+	return !needVendorFilter || externalIDs["vendor"] == util.CniTypeName
+}
+
+func (c *LegacyClient) CreateLogicalRouter(lr string) error {
+	if err := c.record("CreateLogicalRouter", lr); err != nil {
+		return err
+	}
+	router, err := c.getLogicalRouter(lr)
+	if err != nil || router != nil {
+		return err
+	}
+	ops, err := c.nb.Create(&ovnnb.LogicalRouter{
+		UUID:        ovsclient.NamedUUID(),
+		Name:        lr,
+		ExternalIDs: map[string]string{"vendor": util.CniTypeName},
+	})
+	if err != nil {
+		return err
+	}
+	return c.transact(ops)
+}
+
+func (c *LegacyClient) DeleteLogicalRouter(lr string) error {
+	if err := c.record("DeleteLogicalRouter", lr); err != nil {
+		return err
+	}
+	router, err := c.getLogicalRouter(lr)
+	if err != nil || router == nil {
+		return err
+	}
+	ops, err := c.nb.Where(router).Delete()
+	if err != nil {
+		return err
+	}
+	return c.transact(ops)
+}
+
+func (c *LegacyClient) ListLogicalRouter(needVendorFilter bool, args ...string) ([]string, error) {
+	if err := c.record("ListLogicalRouter", needVendorFilter, args); err != nil {
+		return nil, err
+	}
+	var lrList []ovnnb.LogicalRouter
+	if err := c.nb.WhereCache(func(lr *ovnnb.LogicalRouter) bool {
+		return vendorMatches(lr.ExternalIDs, needVendorFilter)
+	}).List(context.TODO(), &lrList); err != nil && err != client.ErrNotFound {
+		return nil, err
+	}
+	names := make([]string, 0, len(lrList))
+	for _, lr := range lrList {
+		names = append(names, lr.Name)
+	}
+	return names, nil
+}
+
+func (c *LegacyClient) CreateLogicalSwitch(ls, lr, subnet, gateway string, needRouter bool) error {
+	if err := c.record("CreateLogicalSwitch", ls, lr, subnet, gateway, needRouter); err != nil {
+		return err
+	}
+	sw, err := c.getLogicalSwitch(ls)
+	if err != nil {
+		return err
+	}
+
+	var ops []ovsdb.Operation
+	var lspUUID string
+	if needRouter {
+		lsTolr := fmt.Sprintf("%s-%s", ls, lr)
+		exists, err := c.logicalSwitchPortExists(lsTolr)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			lsp := &ovnnb.LogicalSwitchPort{
+				UUID:        ovsclient.NamedUUID(),
+				Name:        lsTolr,
+				Type:        "router",
+				Addresses:   []string{"router"},
+				Options:     map[string]string{"router-port": fmt.Sprintf("%s-%s", lr, ls)},
+				ExternalIDs: map[string]string{"vendor": util.CniTypeName},
+			}
+			if ops, err = c.nb.Create(lsp); err != nil {
+				return err
+			}
+			lspUUID = lsp.UUID
+		}
+	}
+
+	if sw == nil {
+		sw = &ovnnb.LogicalSwitch{
+			UUID:        ovsclient.NamedUUID(),
+			Name:        ls,
+			ExternalIDs: map[string]string{"vendor": util.CniTypeName},
+		}
+		if lspUUID != "" {
+			sw.Ports = []string{lspUUID}
+		}
+		createOps, err := c.nb.Create(sw)
+		if err != nil {
+			return err
+		}
+		return c.transact(ops, createOps)
+	}
+	if lspUUID == "" {
+		return nil
+	}
+	mutateOps, err := c.nb.Where(sw).Mutate(sw, model.Mutation{
+		Field:   &sw.Ports,
+		Mutator: ovsdb.MutateOperationInsert,
+		Value:   []string{lspUUID},
+	})
+	if err != nil {
+		return err
+	}
+	return c.transact(ops, mutateOps)
+}
+
+func (c *LegacyClient) logicalSwitchPortExists(name string) (bool, error) {
+	lsp := &ovnnb.LogicalSwitchPort{Name: name}
+	if err := c.nb.Get(context.TODO(), lsp); err != nil {
+		if err == client.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *LegacyClient) DeleteLogicalSwitch(ls string) error {
+	if err := c.record("DeleteLogicalSwitch", ls); err != nil {
+		return err
+	}
+	sw, err := c.getLogicalSwitch(ls)
+	if err != nil || sw == nil {
+		return err
+	}
+	ops, err := c.nb.Where(sw).Delete()
+	if err != nil {
+		return err
+	}
+	return c.transact(ops)
+}
+
+func (c *LegacyClient) LogicalSwitchExists(logicalSwitch string, needVendorFilter bool, args ...string) (bool, error) {
+	if err := c.record("LogicalSwitchExists", logicalSwitch, needVendorFilter, args); err != nil {
+		return false, err
+	}
+	sw, err := c.getLogicalSwitch(logicalSwitch)
+	if err != nil || sw == nil {
+		return false, err
+	}
+	return vendorMatches(sw.ExternalIDs, needVendorFilter), nil
+}
+
+func (c *LegacyClient) ListLogicalSwitch(needVendorFilter bool, args ...string) ([]string, error) {
+	if err := c.record("ListLogicalSwitch", needVendorFilter, args); err != nil {
+		return nil, err
+	}
+	var lsList []ovnnb.LogicalSwitch
+	if err := c.nb.WhereCache(func(ls *ovnnb.LogicalSwitch) bool {
+		return vendorMatches(ls.ExternalIDs, needVendorFilter)
+	}).List(context.TODO(), &lsList); err != nil && err != client.ErrNotFound {
+		return nil, err
+	}
+	names := make([]string, 0, len(lsList))
+	for _, ls := range lsList {
+		names = append(names, ls.Name)
+	}
+	return names, nil
+}
+
+func (c *LegacyClient) RemoveRouterPort(ls, lr string) error {
+	if err := c.record("RemoveRouterPort", ls, lr); err != nil {
+		return err
+	}
+	sw, err := c.getLogicalSwitch(ls)
+	if err != nil || sw == nil {
+		return err
+	}
+	lsp := &ovnnb.LogicalSwitchPort{Name: fmt.Sprintf("%s-%s", ls, lr)}
+	if err = c.nb.Get(context.TODO(), lsp); err != nil {
+		if err == client.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+	ops, err := c.nb.Where(sw).Mutate(sw, model.Mutation{
+		Field:   &sw.Ports,
+		Mutator: ovsdb.MutateOperationDelete,
+		Value:   []string{lsp.UUID},
+	})
+	if err != nil {
+		return err
+	}
+	return c.transact(ops)
+}
+
+func (c *LegacyClient) DeletePortGroup(pgName string) error {
+	if err := c.record("DeletePortGroup", pgName); err != nil {
+		return err
+	}
+	pg, err := c.getPortGroup(pgName)
+	if err != nil || pg == nil {
+		return err
+	}
+	ops, err := c.nb.Where(pg).Delete()
+	if err != nil {
+		return err
+	}
+	return c.transact(ops)
+}
+
+func (c *LegacyClient) ListNpPortGroup() ([]ovs.NpPortGroup, error) {
+	if err := c.record("ListNpPortGroup"); err != nil {
+		return nil, err
+	}
+	var pgList []ovnnb.PortGroup
+	if err := c.nb.WhereCache(func(pg *ovnnb.PortGroup) bool {
+		return pg.ExternalIDs["np"] != ""
+	}).List(context.TODO(), &pgList); err != nil && err != client.ErrNotFound {
+		return nil, err
+	}
+	result := make([]ovs.NpPortGroup, 0, len(pgList))
+	for _, pg := range pgList {
+		np := strings.SplitN(pg.ExternalIDs["np"], "/", 2)
+		if len(np) == 2 {
+			result = append(result, ovs.NpPortGroup{Name: pg.Name, NpNamespace: np[0], NpName: np[1]})
+		}
+	}
+	return result, nil
+}
+
+func (c *LegacyClient) ListPgPorts(pgName string) ([]string, error) {
+	if err := c.record("ListPgPorts", pgName); err != nil {
+		return nil, err
+	}
+	pg, err := c.getPortGroup(pgName)
+	if err != nil || pg == nil {
+		return nil, err
+	}
+	return pg.Ports, nil
+}
+
+func (c *LegacyClient) SetOvnICNbAddress(addr string) {
+	_ = c.record("SetOvnICNbAddress", addr)
+}
+
+func (c *LegacyClient) SetOvnICSbAddress(addr string) {
+	_ = c.record("SetOvnICSbAddress", addr)
+}
+
+func (c *LegacyClient) ChassisExist(chassisName string) (bool, error) {
+	return false, c.record("ChassisExist", chassisName)
+}
+
+func (c *LegacyClient) CreateGatewaySwitch(name, network string, vlan int, ip, mac string) error {
+	return c.record("CreateGatewaySwitch", name, network, vlan, ip, mac)
+}
+
+func (c *LegacyClient) CreateLocalnetPort(ls, port, provider string, vlanID int) error {
+	return c.record("CreateLocalnetPort", ls, port, provider, vlanID)
+}
+
+func (c *LegacyClient) CreatePeerRouterPort(localRouter, remoteRouter, localRouterPortIP string) error {
+	return c.record("CreatePeerRouterPort", localRouter, remoteRouter, localRouterPortIP)
+}
+
+func (c *LegacyClient) CreatePort(ls, port, ip, mac, pod, namespace string, portSecurity bool, securityGroups string, vips string, liveMigration bool, enableDHCP bool, dhcpOptions *ovs.DHCPOptionsUUIDs) error {
+	return c.record("CreatePort", ls, port, ip, mac, pod, namespace, portSecurity, securityGroups, vips, liveMigration, enableDHCP, dhcpOptions)
+}
+
+func (c *LegacyClient) CreateVirtualPort(ls, ip string) error {
+	return c.record("CreateVirtualPort", ls, ip)
+}
+
+func (c *LegacyClient) DeleteChassisByName(chassisName string) error {
+	return c.record("DeleteChassisByName", chassisName)
+}
+
+func (c *LegacyClient) DeleteChassisByNode(node string) error {
+	return c.record("DeleteChassisByNode", node)
+}
+
+func (c *LegacyClient) DeleteGatewaySwitch(name string) error {
+	return c.record("DeleteGatewaySwitch", name)
+}
+
+func (c *LegacyClient) DeleteICLogicalRouterPort(az string) error {
+	return c.record("DeleteICLogicalRouterPort", az)
+}
+
+func (c *LegacyClient) DeleteLogicalRouterPort(port string) error {
+	return c.record("DeleteLogicalRouterPort", port)
+}
+
+func (c *LegacyClient) DeleteSgPortGroup(sgName string) error {
+	return c.record("DeleteSgPortGroup", sgName)
+}
+
+func (c *LegacyClient) DestroyChassis(uuid string) error {
+	return c.record("DestroyChassis", uuid)
+}
+
+func (c *LegacyClient) DestroyGateways(uuids []string) {
+	_ = c.record("DestroyGateways", uuids)
+}
+
+func (c *LegacyClient) DestroyRoutes(uuids []string) {
+	_ = c.record("DestroyRoutes", uuids)
+}
+
+func (c *LegacyClient) EnablePortLayer2forward(ls, port string) error {
+	return c.record("EnablePortLayer2forward", ls, port)
+}
+
+func (c *LegacyClient) GetAllChassis() ([]string, error) {
+	return nil, c.record("GetAllChassis")
+}
+
+func (c *LegacyClient) ListServiceMonitors() ([]ovs.ServiceMonitor, error) {
+	err := c.record("ListServiceMonitors")
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.ServiceMonitors, err
+}
+
+func (c *LegacyClient) GetAzUUID(az string) (string, error) {
+	return "", c.record("GetAzUUID", az)
+}
+
+func (c *LegacyClient) GetChassis(node string) (string, error) {
+	err := c.record("GetChassis", node)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.Chassis[node], err
+}
+
+func (c *LegacyClient) GetGatewayUUIDsInOneAZ(uuid string) ([]string, error) {
+	return nil, c.record("GetGatewayUUIDsInOneAZ", uuid)
+}
+
+func (c *LegacyClient) GetRouteUUIDsInOneAZ(uuid string) ([]string, error) {
+	return nil, c.record("GetRouteUUIDsInOneAZ", uuid)
+}
+
+func (c *LegacyClient) GetTsSubnet(ts string) (string, error) {
+	return "", c.record("GetTsSubnet", ts)
+}
+
+func (c *LegacyClient) InitChassisNodeTag(chassisName string, nodeName string) error {
+	return c.record("InitChassisNodeTag", chassisName, nodeName)
+}
+
+func (c *LegacyClient) ListLogicalEntity(entity string, args ...string) ([]string, error) {
+	return nil, c.record("ListLogicalEntity", entity, args)
+}
+
+func (c *LegacyClient) ListLogicalSwitchPort(needVendorFilter bool) ([]string, error) {
+	return nil, c.record("ListLogicalSwitchPort", needVendorFilter)
+}
+
+func (c *LegacyClient) ListLspForNodePortgroup() (map[string]string, map[string]string, error) {
+	return nil, nil, c.record("ListLspForNodePortgroup")
+}
+
+func (c *LegacyClient) ListPgPortsForNodePortgroup() (map[string][]string, error) {
+	return nil, c.record("ListPgPortsForNodePortgroup")
+}
+
+func (c *LegacyClient) ListRemoteLogicalSwitchPortAddress() ([]string, error) {
+	return nil, c.record("ListRemoteLogicalSwitchPortAddress")
+}
+
+func (c *LegacyClient) LogicalSwitchPortExists(port string) (bool, error) {
+	return false, c.record("LogicalSwitchPortExists", port)
+}
+
+func (c *LegacyClient) SetAzName(azName string) error {
+	return c.record("SetAzName", azName)
+}
+
+func (c *LegacyClient) SetICAutoRoute(enable bool, blackList []string) error {
+	return c.record("SetICAutoRoute", enable, blackList)
+}
+
+func (c *LegacyClient) SetLBCIDR(svccidr string) error {
+	return c.record("SetLBCIDR", svccidr)
+}
+
+func (c *LegacyClient) SetLogicalSwitchConfig(ls string) error {
+	return c.record("SetLogicalSwitchConfig", ls)
+}
+
+func (c *LegacyClient) SetLsDnatModDlDst(enabled bool) error {
+	return c.record("SetLsDnatModDlDst", enabled)
+}
+
+func (c *LegacyClient) SetLspExternalIds(name string, externalIDs map[string]string) error {
+	return c.record("SetLspExternalIds", name, externalIDs)
+}
+
+func (c *LegacyClient) SetPortAddress(port, mac, ip string) error {
+	return c.record("SetPortAddress", port, mac, ip)
+}
+
+func (c *LegacyClient) SetPortExternalIds(port, key, value string) error {
+	return c.record("SetPortExternalIds", port, key, value)
+}
+
+func (c *LegacyClient) SetPortSecurity(portSecurity bool, ls, port, mac, ipStr, vips string) error {
+	return c.record("SetPortSecurity", portSecurity, ls, port, mac, ipStr, vips)
+}
+
+func (c *LegacyClient) SetPortTag(name string, vlanID int) error {
+	return c.record("SetPortTag", name, vlanID)
+}
+
+func (c *LegacyClient) SetUseCtInvMatch() error {
+	return c.record("SetUseCtInvMatch")
+}
+
+func (c *LegacyClient) SetVirtualParents(ls, ip, parents string) error {
+	return c.record("SetVirtualParents", ls, ip, parents)
+}
+
+func (c *LegacyClient) UpdateRouterPortIPv6RA(ls, lr, cidrBlock, gateway, ipv6RAConfigsStr string, enableIPv6RA bool) error {
+	return c.record("UpdateRouterPortIPv6RA", ls, lr, cidrBlock, gateway, ipv6RAConfigsStr, enableIPv6RA)
+}
@@ -0,0 +1,88 @@
+package fake
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/ovn-org/libovsdb/ovsdb/serverdb"
+	"github.com/ovn-org/libovsdb/server"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+)
+
+// OvnNbServer is an in-memory OVN NB database served on a unix socket,
+// clients created by ovs.NewOvnClient can connect to it with Addr
+type OvnNbServer struct {
+	Addr string
+
+	dir    string
+	server *server.OvsdbServer
+}
+
+// NewOvnNbServer starts an in-memory OVN NB database server,
+// the caller is responsible for calling Close when it is no longer used
+func NewOvnNbServer() (*OvnNbServer, error) {
+	nbClientModel, err := ovnnb.FullDatabaseModel()
+	if err != nil {
+		return nil, err
+	}
+	nbModel, err := newDatabaseModel(ovnnb.Schema(), nbClientModel)
+	if err != nil {
+		return nil, err
+	}
+	// the NB client works in leader only mode, so the _Server database is required
+	serverClientModel, err := serverdb.FullDatabaseModel()
+	if err != nil {
+		return nil, err
+	}
+	serverModel, err := newDatabaseModel(serverdb.Schema(), serverClientModel)
+	if err != nil {
+		return nil, err
+	}
+
+	db := server.NewInMemoryDatabase(map[string]model.ClientDBModel{
+		nbClientModel.Name():     nbClientModel,
+		serverClientModel.Name(): serverClientModel,
+	})
+	s, err := server.NewOvsdbServer(db, nbModel, serverModel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ovsdb server: %v", err)
+	}
+
+	dir, err := os.MkdirTemp("", "ovn-nb-")
+	if err != nil {
+		return nil, err
+	}
+	sock := filepath.Join(dir, "ovnnb_db.sock")
+	go func() {
+		_ = s.Serve("unix", sock)
+	}()
+	if err = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return s.Ready(), nil
+	}); err != nil {
+		s.Close()
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("ovsdb server is not ready: %v", err)
+	}
+
+	return &OvnNbServer{Addr: "unix:" + sock, dir: dir, server: s}, nil
+}
+
+// Close stops the server and removes the unix socket
+func (s *OvnNbServer) Close() {
+	s.server.Close()
+	_ = os.RemoveAll(s.dir)
+}
+
+func newDatabaseModel(schema ovsdb.DatabaseSchema, clientModel model.ClientDBModel) (model.DatabaseModel, error) {
+	dbModel, errs := model.NewDatabaseModel(schema, clientModel)
+	if len(errs) != 0 {
+		return model.DatabaseModel{}, fmt.Errorf("failed to create database model of %s: %v", schema.Name, errs)
+	}
+	return dbModel, nil
+}
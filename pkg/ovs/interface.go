@@ -0,0 +1,256 @@
+package ovs
+
+import (
+	"time"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+)
+
+type LogicalRouter interface {
+	GetLogicalRouter(name string, ignoreNotFound bool) (*ovnnb.LogicalRouter, error)
+	LogicalRouterExists(name string) (bool, error)
+	ListLogicalRoutersWithFilter(filter func(lr *ovnnb.LogicalRouter) bool) ([]ovnnb.LogicalRouter, error)
+	LogicalRouterUpdateLoadBalancers(lrName string, op ovsdb.Mutator, lbNames ...string) error
+}
+
+type LogicalRouterPort interface {
+	GetLogicalRouterPort(name string, ignoreNotFound bool) (*ovnnb.LogicalRouterPort, error)
+	ListLogicalRouterPortsWithFilter(filter func(lrp *ovnnb.LogicalRouterPort) bool) ([]ovnnb.LogicalRouterPort, error)
+	AddLogicalRouterPort(lr, name, mac, networks string) error
+	LogicalRouterPortExists(name string) (bool, error)
+	SetLogicalRouterPortNetworks(name string, networks []string) error
+}
+
+type LogicalRouterPolicy interface {
+	AddRouterPolicy(lr *ovnnb.LogicalRouter, match string, action ovnnb.LogicalRouterPolicyAction, opts map[string]string, extIDs map[string]string, priority int) error
+	DeleteRouterPolicy(lr *ovnnb.LogicalRouter, uuid string) error
+	GetLogicalRouterPoliciesByExtID(key, value string) ([]ovnnb.LogicalRouterPolicy, error)
+	GetPolicyRouteList(router string) ([]*PolicyRoute, error)
+	AddPolicyRoute(router string, priority int32, match, action, nextHop string, externalIDs map[string]string) error
+	DeletePolicyRoute(router string, priority int32, match string) error
+	DeletePolicyRouteByNexthop(router string, priority int32, nexthop string) error
+	PolicyRouteExists(router string, priority int32, match string) (bool, error)
+	GetPolicyRouteParas(router string, priority int32, match string) ([]string, map[string]string, error)
+}
+
+type LogicalRouterStaticRoute interface {
+	GetLogicalRouterRouteByOpts(key, value string) ([]ovnnb.LogicalRouterStaticRoute, error)
+	ListStaticRoutes(lrName string) ([]*StaticRoute, error)
+	ListLearnedStaticRoutes(lrName string) ([]*StaticRoute, error)
+	AddStaticRoute(policy, cidr, nextHop, lrName string, routeType string) error
+	DeleteStaticRoute(cidr, lrName string) error
+}
+
+type LogicalSwitch interface {
+	GetLogicalSwitch(name string, ignoreNotFound bool) (*ovnnb.LogicalSwitch, error)
+	LogicalSwitchExists(name string) (bool, error)
+	ListLogicalSwitch(needVendorFilter bool) ([]string, error)
+	ListLogicalSwitchesWithFilter(filter func(ls *ovnnb.LogicalSwitch) bool) ([]ovnnb.LogicalSwitch, error)
+	LogicalSwitchUpdateLoadBalancers(lsName string, op ovsdb.Mutator, lbNames ...string) error
+}
+
+type LogicalSwitchPort interface {
+	GetLogicalSwitchPort(name string, ignoreNotFound bool) (*ovnnb.LogicalSwitchPort, error)
+	ListPodLogicalSwitchPorts(key string) ([]ovnnb.LogicalSwitchPort, error)
+	ListLogicalSwitchPorts(needVendorFilter bool, externalIDs map[string]string) ([]ovnnb.LogicalSwitchPort, error)
+	ListLogicalSwitchPortsWithFilter(filter func(lsp *ovnnb.LogicalSwitchPort) bool) ([]ovnnb.LogicalSwitchPort, error)
+	LogicalSwitchPortExists(name string) (bool, error)
+	CreatePortOps(ls, port, ip, mac, pod, namespace string, portSecurity bool, securityGroups string, vips string, liveMigration bool, enableDHCP bool, dhcpOptions *DHCPOptionsUUIDs, layer2Forward bool) (string, []ovsdb.Operation, error)
+	CreateRouterTypePort(ls, port, lrpName string) error
+	SetLogicalSwitchPortOptions(name string, options map[string]string) error
+	DeleteLogicalSwitchPort(name string) error
+}
+
+type LoadBalancer interface {
+	GetLoadBalancer(name string, ignoreNotFound bool) (*ovnnb.LoadBalancer, error)
+	LoadBalancerExists(name string) (bool, error)
+	ListLoadBalancers() ([]string, error)
+	CreateLoadBalancer(name, protocol, selectFields string) error
+	CreateOrUpdateLoadBalancer(name, protocol string, selectFields []string, options, externalIDs map[string]string) error
+	LoadBalancerAffinityTimeoutSupported() bool
+	ListLoadBalancersByExternalIDs(externalIDs map[string]string) ([]ovnnb.LoadBalancer, error)
+	DeleteLoadBalancers(names ...string) error
+	GetLoadBalancerVips(name string) (map[string]string, error)
+	LoadBalancerAddVip(name, vip string, backends ...string) error
+	LoadBalancerDeleteVip(name, vip string) error
+	GetLoadBalancerHealthCheck(name, vip string) (*ovnnb.LoadBalancerHealthCheck, error)
+	LoadBalancerAddHealthCheck(name, vip string, options map[string]string) error
+	LoadBalancerDeleteHealthCheck(name, vip string) error
+	LoadBalancerUpdateIPPortMappings(name string, mappings map[string]string) error
+}
+
+type ChassisTemplateVar interface {
+	ChassisTemplateVarSupported() bool
+	GetChassisTemplateVars() (map[string]map[string]string, error)
+	UpdateChassisTemplateVars(vars map[string]map[string]string) error
+}
+
+type PortGroup interface {
+	GetPortGroup(name string, ignoreNotFound bool) (*ovnnb.PortGroup, error)
+	CreatePortGroup(name string, externalIDs map[string]string) error
+	PortGroupAddPort(pgName, portName string) error
+	PortGroupRemovePort(pgName, portName string) error
+	PortGroupAddPortsOps(pgName string, lspUUIDs ...string) ([]ovsdb.Operation, error)
+	PortGroupRemovePortsOps(pgName string, lspUUIDs ...string) ([]ovsdb.Operation, error)
+	PortGroupSetPorts(pgName string, portNames []string) error
+	ListPortGroups(externalIDs map[string]string) ([]ovnnb.PortGroup, error)
+	DeletePortGroup(name string) error
+}
+
+type ACL interface {
+	ListPortGroupACLs(pgName, direction string) ([]ovnnb.ACL, error)
+	CreatePortGroupACLs(pgName string, acls ...*ovnnb.ACL) error
+	DeletePortGroupACLs(pgName, direction string) error
+	UpdatePortGroupACLs(pgName, direction string, acls ...*ovnnb.ACL) error
+	SetAclLog(pgName, ownerType, owner string, logEnable, isIngress bool) error
+	UpdateSgACL(sg *kubeovnv1.SecurityGroup, direction AclDirection) error
+	CreateGatewayACL(pgName, gateway, cidr string) error
+	CreateACLForNodePg(pgName, nodeIPStr string) error
+	CreateSgDenyAllACL() error
+	ListLogicalSwitchACLs(lsName string, externalIDs map[string]string) ([]ovnnb.ACL, error)
+	DeleteLogicalSwitchACLs(lsName string) error
+	SetLogicalSwitchPrivate(lsName, cidr, nodeSwitchCIDR string, allowSubnets []string) error
+	UpdateSubnetACL(lsName string, acls []kubeovnv1.Acl) error
+}
+
+type AddressSet interface {
+	GetAddressSet(name string, ignoreNotFound bool) (*ovnnb.AddressSet, error)
+	CreateAddressSet(name string, externalIDs map[string]string) error
+	CreateNpAddressSet(asName, npNamespace, npName, direction string) error
+	CreateSgAssociatedAddressSet(sgName string) error
+	CreateFqdnAddressSets(fqdn string) error
+	SetAddressesToAddressSet(addresses []string, asName string) error
+	DeleteAddressSet(name string) error
+	ListAddressSets(externalIDs map[string]string) ([]ovnnb.AddressSet, error)
+	ListNpAddressSet(npNamespace, npName, direction string) ([]string, error)
+	ListSgRuleAddressSet(sgName string, direction AclDirection) ([]string, error)
+	AddressSetAddAddressesOps(asName string, addresses ...string) ([]ovsdb.Operation, error)
+}
+
+type DHCPOptions interface {
+	ListDHCPOptions(needVendorFilter bool, ls, protocol string) ([]ovnnb.DHCPOptions, error)
+	DeleteDHCPOptionsByUUIDs(uuidList []string) error
+	DeleteDHCPOptions(ls, protocol string) error
+	UpdateDHCPOptions(ls, cidrBlock, gateway, dhcpV4OptionsStr, dhcpV6OptionsStr string, enableDHCP bool) (*DHCPOptionsUUIDs, error)
+}
+
+type NAT interface {
+	ListNats(lrName, natType, logicalIP string) ([]ovnnb.NAT, error)
+	NatRuleExists(logicalIP string) (bool, error)
+	AddNat(lrName, natType, externalIP, logicalIP, logicalMac, port string, stateless bool) error
+	DeleteNats(lrName string, nats ...ovnnb.NAT) error
+	DeleteNatRule(logicalIP, lrName string) error
+	UpdateSnat(lrName, externalIP, logicalIP string) error
+	UpdateDnatAndSnat(lrName, externalIP, logicalIP, lspName, externalMac, gatewayType string) error
+}
+
+type GatewayChassis interface {
+	SetGatewayChassises(lrpName string, chassises []string) error
+	DeleteGatewayChassises(lrpName string, chassises []string) error
+}
+
+type QoS interface {
+	CreateQoSOps(lsName string, qos *ovnnb.QoS) ([]ovsdb.Operation, error)
+	DeleteQoSOps(lsName string, qosUUIDs ...string) ([]ovsdb.Operation, error)
+	ListQoSs(externalIDs map[string]string) ([]ovnnb.QoS, error)
+}
+
+type Meter interface {
+	GetMeter(name string, ignoreNotFound bool) (*ovnnb.Meter, error)
+	CreateOrUpdateMeter(name string, unit ovnnb.MeterUnit, rate int) error
+}
+
+type Transaction interface {
+	NewTransaction() *NbTransaction
+	NewBatcher(maxOps int, interval time.Duration) *NbBatcher
+}
+
+// NbClient is the OVN NB client used by the controller, it is implemented by OvnClient
+// and can be replaced by an in-memory fake in unit tests
+type NbClient interface {
+	LogicalRouter
+	LogicalRouterPort
+	LogicalRouterPolicy
+	LogicalRouterStaticRoute
+	LogicalSwitch
+	LogicalSwitchPort
+	LoadBalancer
+	ChassisTemplateVar
+	PortGroup
+	ACL
+	AddressSet
+	DHCPOptions
+	NAT
+	GatewayChassis
+	QoS
+	Meter
+	Transaction
+}
+
+var _ NbClient = &OvnClient{}
+
+// LegacyOvnClient is the ovn-nbctl/ovn-sbctl based client used by the controller, it is implemented
+// by LegacyClient and can be replaced by a fake in unit tests
+type LegacyOvnClient interface {
+	ChassisExist(chassisName string) (bool, error)
+	CreateGatewaySwitch(name, network string, vlan int, ip, mac string) error
+	CreateLocalnetPort(ls, port, provider string, vlanID int) error
+	CreateLogicalRouter(lr string) error
+	CreateLogicalSwitch(ls, lr, subnet, gateway string, needRouter bool) error
+	CreatePeerRouterPort(localRouter, remoteRouter, localRouterPortIP string) error
+	CreatePort(ls, port, ip, mac, pod, namespace string, portSecurity bool, securityGroups string, vips string, liveMigration bool, enableDHCP bool, dhcpOptions *DHCPOptionsUUIDs) error
+	CreateVirtualPort(ls, ip string) error
+	DeleteChassisByName(chassisName string) error
+	DeleteChassisByNode(node string) error
+	DeleteGatewaySwitch(name string) error
+	DeleteICLogicalRouterPort(az string) error
+	DeleteLogicalRouter(lr string) error
+	DeleteLogicalRouterPort(port string) error
+	DeleteLogicalSwitch(ls string) error
+	DeletePortGroup(pgName string) error
+	DeleteSgPortGroup(sgName string) error
+	DestroyChassis(uuid string) error
+	DestroyGateways(uuids []string)
+	DestroyRoutes(uuids []string)
+	EnablePortLayer2forward(ls, port string) error
+	GetAllChassis() ([]string, error)
+	GetAzUUID(az string) (string, error)
+	GetChassis(node string) (string, error)
+	GetGatewayUUIDsInOneAZ(uuid string) ([]string, error)
+	GetRouteUUIDsInOneAZ(uuid string) ([]string, error)
+	GetTsSubnet(ts string) (string, error)
+	InitChassisNodeTag(chassisName string, nodeName string) error
+	ListLogicalEntity(entity string, args ...string) ([]string, error)
+	ListLogicalRouter(needVendorFilter bool, args ...string) ([]string, error)
+	ListLogicalSwitch(needVendorFilter bool, args ...string) ([]string, error)
+	ListLogicalSwitchPort(needVendorFilter bool) ([]string, error)
+	ListLspForNodePortgroup() (map[string]string, map[string]string, error)
+	ListNpPortGroup() ([]NpPortGroup, error)
+	ListPgPorts(pgName string) ([]string, error)
+	ListPgPortsForNodePortgroup() (map[string][]string, error)
+	ListRemoteLogicalSwitchPortAddress() ([]string, error)
+	ListServiceMonitors() ([]ServiceMonitor, error)
+	LogicalSwitchExists(logicalSwitch string, needVendorFilter bool, args ...string) (bool, error)
+	LogicalSwitchPortExists(port string) (bool, error)
+	RemoveRouterPort(ls, lr string) error
+	SetAzName(azName string) error
+	SetICAutoRoute(enable bool, blackList []string) error
+	SetLBCIDR(svccidr string) error
+	SetLogicalSwitchConfig(ls string) error
+	SetLsDnatModDlDst(enabled bool) error
+	SetLspExternalIds(name string, externalIDs map[string]string) error
+	SetOvnICNbAddress(addr string)
+	SetOvnICSbAddress(addr string)
+	SetPortAddress(port, mac, ip string) error
+	SetPortExternalIds(port, key, value string) error
+	SetPortSecurity(portSecurity bool, ls, port, mac, ipStr, vips string) error
+	SetPortTag(name string, vlanID int) error
+	SetUseCtInvMatch() error
+	SetVirtualParents(ls, ip, parents string) error
+	UpdateRouterPortIPv6RA(ls, lr, cidrBlock, gateway, ipv6RAConfigsStr string, enableIPv6RA bool) error
+}
+
+var _ LegacyOvnClient = &LegacyClient{}
@@ -0,0 +1,689 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	ovsclient "github.com/kubeovn/kube-ovn/pkg/ovsdb/client"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// ListPortGroupACLs returns the acls of the port group, in all directions if direction is empty
+func (c OvnClient) ListPortGroupACLs(pgName, direction string) ([]ovnnb.ACL, error) {
+	pg, err := c.GetPortGroup(pgName, true)
+	if err != nil || pg == nil {
+		return nil, err
+	}
+	aclMap := make(map[string]struct{}, len(pg.ACLs))
+	for _, uuid := range pg.ACLs {
+		aclMap[uuid] = struct{}{}
+	}
+
+	var aclList []ovnnb.ACL
+	if err = c.ovnNbClient.WhereCache(func(acl *ovnnb.ACL) bool {
+		if _, ok := aclMap[acl.UUID]; !ok {
+			return false
+		}
+		return direction == "" || acl.Direction == direction
+	}).List(context.TODO(), &aclList); err != nil && err != client.ErrNotFound {
+		return nil, fmt.Errorf("failed to list acls of port group %s: %v", pgName, err)
+	}
+
+	return aclList, nil
+}
+
+// CreatePortGroupACLs creates the acls and adds them to the port group in one transaction
+func (c OvnClient) CreatePortGroupACLs(pgName string, acls ...*ovnnb.ACL) error {
+	if len(acls) == 0 {
+		return nil
+	}
+	pg, err := c.GetPortGroup(pgName, false)
+	if err != nil {
+		return err
+	}
+
+	var ops []ovsdb.Operation
+	uuids := make([]string, 0, len(acls))
+	for _, acl := range acls {
+		acl.UUID = ovsclient.NamedUUID()
+		createOps, err := c.ovnNbClient.Create(acl)
+		if err != nil {
+			return fmt.Errorf("failed to generate create operations for acl %s: %v", acl.Match, err)
+		}
+		ops = append(ops, createOps...)
+		uuids = append(uuids, acl.UUID)
+	}
+	mutateOps, err := c.ovnNbClient.Where(pg).Mutate(pg, model.Mutation{
+		Field:   &pg.ACLs,
+		Mutator: ovsdb.MutateOperationInsert,
+		Value:   uuids,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate mutate operations for port group %s: %v", pgName, err)
+	}
+	ops = append(ops, mutateOps...)
+	if err = Transact(c.ovnNbClient, "acl-add", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to add acls to port group %s: %v", pgName, err)
+	}
+
+	return nil
+}
+
+// DeletePortGroupACLs removes the acls in the direction from the port group, in all directions if direction is empty,
+// acls are deleted by OVSDB garbage collection once no port group or logical switch refers to them
+func (c OvnClient) DeletePortGroupACLs(pgName, direction string) error {
+	acls, err := c.ListPortGroupACLs(pgName, direction)
+	if err != nil || len(acls) == 0 {
+		return err
+	}
+
+	uuids := make([]string, 0, len(acls))
+	for _, acl := range acls {
+		uuids = append(uuids, acl.UUID)
+	}
+	pg := &ovnnb.PortGroup{Name: pgName}
+	ops, err := c.ovnNbClient.Where(pg).Mutate(pg, model.Mutation{
+		Field:   &pg.ACLs,
+		Mutator: ovsdb.MutateOperationDelete,
+		Value:   uuids,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate mutate operations for port group %s: %v", pgName, err)
+	}
+	if err = Transact(c.ovnNbClient, "acl-del", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to delete acls from port group %s: %v", pgName, err)
+	}
+
+	return nil
+}
+
+// UpdatePortGroupACLs replaces the acls in the direction of the port group with the acls in one transaction,
+// acls with the same priority, match and action are only created once
+func (c OvnClient) UpdatePortGroupACLs(pgName, direction string, acls ...*ovnnb.ACL) error {
+	pg, err := c.GetPortGroup(pgName, false)
+	if err != nil {
+		return err
+	}
+	ops, err := c.updatePortGroupACLsOps(pg, direction, acls)
+	if err != nil {
+		return err
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	if err = Transact(c.ovnNbClient, "acl-update", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to update acls of port group %s: %v", pgName, err)
+	}
+
+	return nil
+}
+
+func (c OvnClient) updatePortGroupACLsOps(pg *ovnnb.PortGroup, direction string, acls []*ovnnb.ACL) ([]ovsdb.Operation, error) {
+	existing, err := c.ListPortGroupACLs(pg.Name, direction)
+	if err != nil {
+		return nil, err
+	}
+	staleUUIDs := make([]string, 0, len(existing))
+	for _, acl := range existing {
+		staleUUIDs = append(staleUUIDs, acl.UUID)
+	}
+
+	var ops []ovsdb.Operation
+	if len(staleUUIDs) != 0 {
+		deleteOps, err := c.ovnNbClient.Where(pg).Mutate(pg, model.Mutation{
+			Field:   &pg.ACLs,
+			Mutator: ovsdb.MutateOperationDelete,
+			Value:   staleUUIDs,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate mutate operations for port group %s: %v", pg.Name, err)
+		}
+		ops = append(ops, deleteOps...)
+	}
+
+	createOps, uuids, err := c.createACLsOps(acls)
+	if err != nil {
+		return nil, err
+	}
+	ops = append(ops, createOps...)
+	if len(uuids) != 0 {
+		insertOps, err := c.ovnNbClient.Where(pg).Mutate(pg, model.Mutation{
+			Field:   &pg.ACLs,
+			Mutator: ovsdb.MutateOperationInsert,
+			Value:   uuids,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate mutate operations for port group %s: %v", pg.Name, err)
+		}
+		ops = append(ops, insertOps...)
+	}
+	return ops, nil
+}
+
+// aclKey identifies the acls with the same direction, priority, match and action, which ovn-nbctl treats as duplicates
+func aclKey(acl *ovnnb.ACL) string {
+	return fmt.Sprintf("%s/%d/%s/%s", acl.Direction, acl.Priority, acl.Match, acl.Action)
+}
+
+// createACLsOps returns the operations creating the acls and the named uuids of them, duplicated acls are created once
+func (c OvnClient) createACLsOps(acls []*ovnnb.ACL) ([]ovsdb.Operation, []string, error) {
+	created := make(map[string]bool, len(acls))
+	var ops []ovsdb.Operation
+	uuids := make([]string, 0, len(acls))
+	for _, acl := range acls {
+		key := aclKey(acl)
+		if created[key] {
+			continue
+		}
+		created[key] = true
+		acl.UUID = ovsclient.NamedUUID()
+		createOps, err := c.ovnNbClient.Create(acl)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate create operations for acl %s: %v", acl.Match, err)
+		}
+		ops = append(ops, createOps...)
+		uuids = append(uuids, acl.UUID)
+	}
+	return ops, uuids, nil
+}
+
+// addPortGroupACLs adds the acls the port group does not have yet, as ovn-nbctl --may-exist acl-add does
+func (c OvnClient) addPortGroupACLs(pgName string, acls ...*ovnnb.ACL) error {
+	existing, err := c.ListPortGroupACLs(pgName, "")
+	if err != nil {
+		return err
+	}
+	var missing []*ovnnb.ACL
+	keys := make(map[string]bool, len(existing))
+	for i := range existing {
+		keys[aclKey(&existing[i])] = true
+	}
+	for _, acl := range acls {
+		if key := aclKey(acl); !keys[key] {
+			keys[key] = true
+			missing = append(missing, acl)
+		}
+	}
+	return c.CreatePortGroupACLs(pgName, missing...)
+}
+
+// CreateGatewayACL allows the traffic between the pods of the port group and the gateways of the subnet
+func (c OvnClient) CreateGatewayACL(pgName, gateway, cidr string) error {
+	var acls []*ovnnb.ACL
+	for _, cidrBlock := range strings.Split(cidr, ",") {
+		for _, gw := range strings.Split(gateway, ",") {
+			if util.CheckProtocol(cidrBlock) != util.CheckProtocol(gw) {
+				continue
+			}
+			ipSuffix := "ip4"
+			if util.CheckProtocol(cidrBlock) == kubeovnv1.ProtocolIPv6 {
+				ipSuffix = "ip6"
+			}
+			acls = append(acls,
+				newACL(ovnnb.ACLDirectionToLport, util.IngressAllowPriority, fmt.Sprintf("%s.src == %s", ipSuffix, gw), ovnnb.ACLActionAllowRelated),
+				newACL(ovnnb.ACLDirectionFromLport, util.EgressAllowPriority, fmt.Sprintf("%s.dst == %s", ipSuffix, gw), ovnnb.ACLActionAllowRelated),
+			)
+		}
+	}
+	return c.addPortGroupACLs(pgName, acls...)
+}
+
+// CreateACLForNodePg allows the traffic between the node and the pods of the node port group,
+// whose addresses are in the address sets named after the port group
+func (c OvnClient) CreateACLForNodePg(pgName, nodeIPStr string) error {
+	var acls []*ovnnb.ACL
+	for _, nodeIP := range strings.Split(nodeIPStr, ",") {
+		ipSuffix := "ip4"
+		if util.CheckProtocol(nodeIP) == kubeovnv1.ProtocolIPv6 {
+			ipSuffix = "ip6"
+		}
+		pgAs := fmt.Sprintf("%s_%s", pgName, ipSuffix)
+		acls = append(acls,
+			newACL(ovnnb.ACLDirectionToLport, util.NodeAllowPriority, fmt.Sprintf("%s.src == %s && %s.dst == $%s", ipSuffix, nodeIP, ipSuffix, pgAs), ovnnb.ACLActionAllowRelated),
+			newACL(ovnnb.ACLDirectionFromLport, util.NodeAllowPriority, fmt.Sprintf("%s.dst == %s && %s.src == $%s", ipSuffix, nodeIP, ipSuffix, pgAs), ovnnb.ACLActionAllowRelated),
+		)
+	}
+	return c.addPortGroupACLs(pgName, acls...)
+}
+
+// CreateSgDenyAllACL drops the traffic of the pods in the port group of the deny all security group
+func (c OvnClient) CreateSgDenyAllACL() error {
+	pgName := GetSgPortGroupName(util.DenyAllSecurityGroup)
+	return c.addPortGroupACLs(pgName,
+		newACL(string(SgAclIngressDirection), util.SecurityGroupDropPriority, fmt.Sprintf("outport==@%s && ip", pgName), ovnnb.ACLActionDrop),
+		newACL(string(SgAclEgressDirection), util.SecurityGroupDropPriority, fmt.Sprintf("inport==@%s && ip", pgName), ovnnb.ACLActionDrop),
+	)
+}
+
+// ListLogicalSwitchACLs returns the acls of the logical switch having all of the external ids
+func (c OvnClient) ListLogicalSwitchACLs(lsName string, externalIDs map[string]string) ([]ovnnb.ACL, error) {
+	ls, err := c.GetLogicalSwitch(lsName, true)
+	if err != nil || ls == nil {
+		return nil, err
+	}
+	aclMap := make(map[string]struct{}, len(ls.ACLs))
+	for _, uuid := range ls.ACLs {
+		aclMap[uuid] = struct{}{}
+	}
+
+	var aclList []ovnnb.ACL
+	if err = c.ovnNbClient.WhereCache(func(acl *ovnnb.ACL) bool {
+		if _, ok := aclMap[acl.UUID]; !ok {
+			return false
+		}
+		for k, v := range externalIDs {
+			if acl.ExternalIDs[k] != v {
+				return false
+			}
+		}
+		return true
+	}).List(context.TODO(), &aclList); err != nil && err != client.ErrNotFound {
+		return nil, fmt.Errorf("failed to list acls of logical switch %s: %v", lsName, err)
+	}
+
+	return aclList, nil
+}
+
+// updateLogicalSwitchACLs replaces the acls of the logical switch having all of the external ids with the acls
+// in one transaction, all acls of the switch are replaced if externalIDs is empty
+func (c OvnClient) updateLogicalSwitchACLs(lsName string, externalIDs map[string]string, acls []*ovnnb.ACL) error {
+	ls, err := c.GetLogicalSwitch(lsName, len(acls) == 0)
+	if err != nil || ls == nil {
+		return err
+	}
+	existing, err := c.ListLogicalSwitchACLs(lsName, externalIDs)
+	if err != nil {
+		return err
+	}
+
+	var ops []ovsdb.Operation
+	if len(existing) != 0 {
+		staleUUIDs := make([]string, 0, len(existing))
+		for _, acl := range existing {
+			staleUUIDs = append(staleUUIDs, acl.UUID)
+		}
+		deleteOps, err := c.ovnNbClient.Where(ls).Mutate(ls, model.Mutation{
+			Field:   &ls.ACLs,
+			Mutator: ovsdb.MutateOperationDelete,
+			Value:   staleUUIDs,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to generate mutate operations for logical switch %s: %v", lsName, err)
+		}
+		ops = append(ops, deleteOps...)
+	}
+	createOps, uuids, err := c.createACLsOps(acls)
+	if err != nil {
+		return err
+	}
+	ops = append(ops, createOps...)
+	if len(uuids) != 0 {
+		insertOps, err := c.ovnNbClient.Where(ls).Mutate(ls, model.Mutation{
+			Field:   &ls.ACLs,
+			Mutator: ovsdb.MutateOperationInsert,
+			Value:   uuids,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to generate mutate operations for logical switch %s: %v", lsName, err)
+		}
+		ops = append(ops, insertOps...)
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	if err = Transact(c.ovnNbClient, "acl-update", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to update acls of logical switch %s: %v", lsName, err)
+	}
+
+	return nil
+}
+
+// DeleteLogicalSwitchACLs removes all acls from the logical switch, it does nothing if the switch does not exist
+func (c OvnClient) DeleteLogicalSwitchACLs(lsName string) error {
+	return c.updateLogicalSwitchACLs(lsName, nil, nil)
+}
+
+// SetLogicalSwitchPrivate replaces the acls of the logical switch with the ones dropping the ingress traffic except the
+// one between the cidr blocks of the switch and from the node switch, and the one between the switch and the allowed
+// subnets. cidr holds all the cidr blocks of the switch
+func (c OvnClient) SetLogicalSwitchPrivate(lsName, cidr, nodeSwitchCIDR string, allowSubnets []string) error {
+	name := lsName
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	severity := ovnnb.ACLSeverityWarning
+	drop := newACL(ovnnb.ACLDirectionToLport, util.DefaultDropPriority, "ip", ovnnb.ACLActionDrop)
+	drop.Name, drop.Log, drop.Severity = &name, true, &severity
+	acls := []*ovnnb.ACL{drop}
+
+	cidrBlocks := strings.Split(cidr, ",")
+	for _, cidrBlock := range cidrBlocks {
+		protocol := util.CheckProtocol(cidrBlock)
+		if protocol != kubeovnv1.ProtocolIPv4 && protocol != kubeovnv1.ProtocolIPv6 {
+			klog.Errorf("the cidrBlock: %s format is error in subnet: %s", cidrBlock, lsName)
+			continue
+		}
+		ipSuffix := "ip4"
+		if protocol == kubeovnv1.ProtocolIPv6 {
+			ipSuffix = "ip6"
+		}
+
+		// traffic between any two cidr blocks of the switch is allowed
+		for _, srcBlock := range cidrBlocks {
+			if util.CheckProtocol(srcBlock) == protocol {
+				match := fmt.Sprintf("%s.src==%s && %s.dst==%s", ipSuffix, srcBlock, ipSuffix, cidrBlock)
+				acls = append(acls, newACL(ovnnb.ACLDirectionToLport, util.SubnetAllowPriority, match, ovnnb.ACLActionAllowRelated))
+			}
+		}
+		for _, nodeCidrBlock := range strings.Split(nodeSwitchCIDR, ",") {
+			if util.CheckProtocol(nodeCidrBlock) == protocol {
+				match := fmt.Sprintf("%s.src==%s", ipSuffix, nodeCidrBlock)
+				acls = append(acls, newACL(ovnnb.ACLDirectionToLport, util.NodeAllowPriority, match, ovnnb.ACLActionAllowRelated))
+			}
+		}
+		for _, subnet := range allowSubnets {
+			subnet = strings.TrimSpace(subnet)
+			if subnet == "" || util.CheckProtocol(subnet) != protocol {
+				continue
+			}
+			match := fmt.Sprintf("(%s.src==%s && %s.dst==%s) || (%s.src==%s && %s.dst==%s)", ipSuffix, subnet, ipSuffix, cidrBlock, ipSuffix, cidrBlock, ipSuffix, subnet)
+			acls = append(acls, newACL(ovnnb.ACLDirectionToLport, util.SubnetAllowPriority, match, ovnnb.ACLActionAllowRelated))
+		}
+	}
+	return c.updateLogicalSwitchACLs(lsName, nil, acls)
+}
+
+// UpdateSubnetACL replaces the acls of the subnet on the logical switch, which are marked by the external id subnet
+func (c OvnClient) UpdateSubnetACL(lsName string, acls []kubeovnv1.Acl) error {
+	subnetACLs := make([]*ovnnb.ACL, 0, len(acls))
+	for _, acl := range acls {
+		subnetACLs = append(subnetACLs, &ovnnb.ACL{
+			Direction:   acl.Direction,
+			Priority:    acl.Priority,
+			Match:       acl.Match,
+			Action:      ovnnb.ACLAction(acl.Action),
+			ExternalIDs: map[string]string{"subnet": lsName},
+		})
+	}
+	return c.updateLogicalSwitchACLs(lsName, map[string]string{"subnet": lsName}, subnetACLs)
+}
+
+// SetAclLog sets the log name, owner and severity of the acls in the direction of the port group,
+// the default drop acls of network policies in audit mode are always logged
+func (c OvnClient) SetAclLog(pgName, ownerType, owner string, logEnable, isIngress bool) error {
+	direction, aclDirection := ovnnb.ACLDirectionToLport, "ingress"
+	if !isIngress {
+		direction, aclDirection = ovnnb.ACLDirectionFromLport, "egress"
+	}
+	acls, err := c.ListPortGroupACLs(pgName, direction)
+	if err != nil || len(acls) == 0 {
+		return err
+	}
+
+	defaultDropPriority, _ := strconv.Atoi(util.IngressDefaultDrop)
+	meter := util.AclLogMeterName
+	var ops []ovsdb.Operation
+	for i := range acls {
+		acl := &acls[i]
+		aclOwnerType, aclLogEnable, severity := ownerType, logEnable, ovnnb.ACLSeverityInfo
+		if acl.Action == ovnnb.ACLActionDrop || acl.Action == ovnnb.ACLActionReject {
+			severity = ovnnb.ACLSeverityWarning
+		} else if ownerType == util.AclLogOwnerTypeNp && acl.Priority == defaultDropPriority {
+			aclOwnerType, aclLogEnable, severity = util.AclLogOwnerTypeNpAudit, true, ovnnb.ACLSeverityWarning
+		}
+
+		name := GetAclLogName(aclOwnerType, owner)
+		if acl.ExternalIDs == nil {
+			acl.ExternalIDs = make(map[string]string, 3)
+		}
+		acl.ExternalIDs[util.AclLogOwnerTypeKey] = aclOwnerType
+		acl.ExternalIDs[util.AclLogOwnerKey] = owner
+		acl.ExternalIDs[util.AclLogDirectionKey] = aclDirection
+		acl.Name, acl.Log, acl.Severity, acl.Meter = &name, aclLogEnable, &severity, &meter
+		updateOps, err := c.ovnNbClient.Where(acl).Update(acl, &acl.Name, &acl.ExternalIDs, &acl.Log, &acl.Severity, &acl.Meter)
+		if err != nil {
+			return fmt.Errorf("failed to generate update operations for acl %s: %v", acl.UUID, err)
+		}
+		ops = append(ops, updateOps...)
+	}
+	if err = Transact(c.ovnNbClient, "acl-update", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to set log of acls in port group %s: %v", pgName, err)
+	}
+
+	return nil
+}
+
+// SgRuleACLErrors is returned by UpdateSgACL when the acls of some rules fail to be created,
+// the errors are indexed by the rules and the acls of the other rules are still created
+type SgRuleACLErrors map[int]error
+
+func (e SgRuleACLErrors) Error() string {
+	indexes := make([]int, 0, len(e))
+	for index := range e {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	msgs := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		msgs = append(msgs, fmt.Sprintf("rule %d: %v", index, e[index]))
+	}
+	return fmt.Sprintf("failed to create acls of security group rules, %s", strings.Join(msgs, "; "))
+}
+
+// UpdateSgACL recreates the acls and the rule address sets of the security group in the direction,
+// the acls allowing the traffic of the same group replace the old acls in one transaction and
+// the acl of each rule is created in its own transaction so that a failed rule does not block the others
+func (c OvnClient) UpdateSgACL(sg *kubeovnv1.SecurityGroup, direction AclDirection) error {
+	pgName := GetSgPortGroupName(sg.Name)
+	pg, err := c.GetPortGroup(pgName, false)
+	if err != nil {
+		return err
+	}
+
+	// clear rule address_set
+	asList, err := c.ListSgRuleAddressSet(sg.Name, direction)
+	if err != nil {
+		return err
+	}
+	for _, as := range asList {
+		if err = c.DeleteAddressSet(as); err != nil {
+			return err
+		}
+	}
+
+	// replace acls with port_group associated acls
+	var acls []*ovnnb.ACL
+	if sg.Spec.AllowSameGroupTraffic {
+		portMatch, peer := "outport==@"+pgName, "src"
+		if direction == SgAclEgressDirection {
+			portMatch, peer = "inport==@"+pgName, "dst"
+		}
+		for _, as := range []struct{ ipSuffix, name string }{{"ip4", GetSgV4AssociatedName(sg.Name)}, {"ip6", GetSgV6AssociatedName(sg.Name)}} {
+			match := fmt.Sprintf("%s && %s && %s.%s==$%s", portMatch, as.ipSuffix, as.ipSuffix, peer, as.name)
+			acls = append(acls, newACL(string(direction), util.SecurityGroupAllowPriority, match, ovnnb.ACLActionAllowRelated))
+		}
+	}
+	if err = c.UpdatePortGroupACLs(pg.Name, string(direction), acls...); err != nil {
+		return err
+	}
+
+	// recreate rule ACL
+	var sgRules []*kubeovnv1.SgRule
+	if direction == SgAclIngressDirection {
+		sgRules = sg.Spec.IngressRules
+	} else {
+		sgRules = sg.Spec.EgressRules
+	}
+	ruleErrors := SgRuleACLErrors{}
+	for index, rule := range sgRules {
+		if err = c.CreatePortGroupACLs(pg.Name, sgRuleACL(sg.Name, direction, rule)); err != nil {
+			ruleErrors[index] = err
+		}
+	}
+	if len(ruleErrors) != 0 {
+		return ruleErrors
+	}
+	return nil
+}
+
+func sgRuleACL(sgName string, direction AclDirection, rule *kubeovnv1.SgRule) *ovnnb.ACL {
+	ipSuffix, protocol := "ip4", kubeovnv1.ProtocolIPv4
+	if rule.IPVersion == "ipv6" {
+		ipSuffix, protocol = "ip6", kubeovnv1.ProtocolIPv6
+	}
+
+	var remote string
+	switch rule.RemoteType {
+	case kubeovnv1.SgRemoteTypeAddress:
+		remote = rule.RemoteAddress
+	case kubeovnv1.SgRemoteTypeFQDN:
+		remote = "$" + GetFqdnAddressSetName(rule.RemoteAddress, protocol)
+	default:
+		remote = "$" + GetSgV4AssociatedName(rule.RemoteSecurityGroup)
+	}
+	sgPortGroupName := GetSgPortGroupName(sgName)
+	var matches []string
+	if direction == SgAclIngressDirection {
+		matches = append(matches, fmt.Sprintf("outport==@%s && %s && %s.src==%s", sgPortGroupName, ipSuffix, ipSuffix, remote))
+	} else {
+		matches = append(matches, fmt.Sprintf("inport==@%s && %s && %s.dst==%s", sgPortGroupName, ipSuffix, ipSuffix, remote))
+	}
+
+	switch rule.Protocol {
+	case kubeovnv1.ProtocolICMP:
+		if ipSuffix == "ip4" {
+			matches = append(matches, "icmp4")
+		} else {
+			matches = append(matches, "icmp6")
+		}
+	case kubeovnv1.ProtocolTCP, kubeovnv1.ProtocolUDP:
+		matches = append(matches, fmt.Sprintf("%d<=%s.dst<=%d", rule.PortRangeMin, rule.Protocol, rule.PortRangeMax))
+	}
+
+	action := ovnnb.ACLActionDrop
+	if rule.Policy == kubeovnv1.PolicyAllow {
+		action = ovnnb.ACLActionAllowRelated
+	}
+	highestPriority, _ := strconv.Atoi(util.SecurityGroupHighestPriority)
+	return newACL(string(direction), strconv.Itoa(highestPriority-rule.Priority), strings.Join(matches, " && "), action)
+}
+
+// newACL returns an acl with the priority in the form of the priority constants of util
+func newACL(direction, priority, match string, action ovnnb.ACLAction) *ovnnb.ACL {
+	p, _ := strconv.Atoi(priority)
+	return &ovnnb.ACL{Direction: direction, Priority: p, Match: match, Action: action}
+}
+
+// NamedPortInfo is a named port of a network policy resolved to the port number of the pods exposing it,
+// the address set contains the addresses of the pods
+type NamedPortInfo struct {
+	Protocol   corev1.Protocol
+	PortID     int32
+	AddressSet string
+}
+
+// npPortMatches returns the matches of the network policy port, the protocol defaults to TCP and a named port
+// returns a match for each port number it is resolved to, no match is returned if the named port is not resolved
+func npPortMatches(port netv1.NetworkPolicyPort, ipSuffix string, namedPorts map[string][]NamedPortInfo) []string {
+	protocol := corev1.ProtocolTCP
+	if port.Protocol != nil {
+		protocol = *port.Protocol
+	}
+	l4Protocol := strings.ToLower(string(protocol))
+
+	switch {
+	case port.Port == nil:
+		return []string{l4Protocol}
+	case port.Port.Type == intstr.String:
+		var matches []string
+		for _, info := range namedPorts[port.Port.StrVal] {
+			if info.Protocol == protocol {
+				matches = append(matches, fmt.Sprintf("%s.dst == $%s && %s.dst == %d", ipSuffix, info.AddressSet, l4Protocol, info.PortID))
+			}
+		}
+		return matches
+	case port.EndPort != nil && *port.EndPort > port.Port.IntVal:
+		return []string{fmt.Sprintf("%d <= %s.dst <= %d", port.Port.IntVal, l4Protocol, *port.EndPort)}
+	default:
+		return []string{fmt.Sprintf("%s.dst == %d", l4Protocol, port.Port.IntVal)}
+	}
+}
+
+// npDefaultDropACL returns the default drop acl of the network policy, in audit mode
+// the acl allows and logs the packets instead to observe what would be dropped
+func npDefaultDropACL(direction, priority, match string, logEnable, auditMode bool) *ovnnb.ACL {
+	acl := newACL(direction, priority, match, ovnnb.ACLActionDrop)
+	if auditMode {
+		acl.Action, logEnable = ovnnb.ACLActionAllowRelated, true
+	}
+	if logEnable {
+		severity := ovnnb.ACLSeverityWarning
+		acl.Log, acl.Severity = true, &severity
+	}
+	return acl
+}
+
+// NpIngressACLs returns the acls of an ingress rule of the network policy, the traffic from the allowed addresses
+// which are not excepted is allowed to the ports of the rule and the other traffic is dropped
+func NpIngressACLs(pgName, asAllowName, asExceptName, protocol string, npp []netv1.NetworkPolicyPort, namedPorts map[string][]NamedPortInfo, logEnable, auditMode bool) []*ovnnb.ACL {
+	return npRuleACLs(pgName, ovnnb.ACLDirectionToLport, asAllowName, asExceptName, protocol, npp, namedPorts, logEnable, auditMode)
+}
+
+// NpEgressACLs returns the acls of an egress rule of the network policy, the traffic to the allowed addresses
+// which are not excepted is allowed to the ports of the rule and the other traffic is dropped
+func NpEgressACLs(pgName, asAllowName, asExceptName, protocol string, npp []netv1.NetworkPolicyPort, namedPorts map[string][]NamedPortInfo, logEnable, auditMode bool) []*ovnnb.ACL {
+	return npRuleACLs(pgName, ovnnb.ACLDirectionFromLport, asAllowName, asExceptName, protocol, npp, namedPorts, logEnable, auditMode)
+}
+
+// NpEgressFqdnACLs returns the acls allowing the pods of the port group to access the addresses the domain names are resolved to
+func NpEgressFqdnACLs(pgName, protocol string, fqdns []string) []*ovnnb.ACL {
+	ipSuffix := "ip4"
+	if protocol == kubeovnv1.ProtocolIPv6 {
+		ipSuffix = "ip6"
+	}
+
+	acls := make([]*ovnnb.ACL, 0, len(fqdns))
+	for _, fqdn := range fqdns {
+		match := fmt.Sprintf("%s.dst == $%s && inport==@%s && ip", ipSuffix, GetFqdnAddressSetName(fqdn, protocol), pgName)
+		acls = append(acls, newACL(ovnnb.ACLDirectionFromLport, util.EgressAllowPriority, match, ovnnb.ACLActionAllowRelated))
+	}
+	return acls
+}
+
+func npRuleACLs(pgName, direction, asAllowName, asExceptName, protocol string, npp []netv1.NetworkPolicyPort, namedPorts map[string][]NamedPortInfo, logEnable, auditMode bool) []*ovnnb.ACL {
+	ipSuffix := "ip4"
+	if protocol == kubeovnv1.ProtocolIPv6 {
+		ipSuffix = "ip6"
+	}
+	peer, portMatch, allowPriority, dropPriority := "src", "outport==@"+pgName, util.IngressAllowPriority, util.IngressDefaultDrop
+	if direction == ovnnb.ACLDirectionFromLport {
+		peer, portMatch, allowPriority, dropPriority = "dst", "inport==@"+pgName, util.EgressAllowPriority, util.EgressDefaultDrop
+	}
+
+	acls := []*ovnnb.ACL{npDefaultDropACL(direction, dropPriority, portMatch+" && ip", logEnable, auditMode)}
+	peerMatch := fmt.Sprintf("%s.%s == $%s && %s.%s != $%s", ipSuffix, peer, asAllowName, ipSuffix, peer, asExceptName)
+	if len(npp) == 0 {
+		match := fmt.Sprintf("%s && %s && ip", peerMatch, portMatch)
+		return append(acls, newACL(direction, allowPriority, match, ovnnb.ACLActionAllowRelated))
+	}
+	for _, port := range npp {
+		for _, l4Match := range npPortMatches(port, ipSuffix, namedPorts) {
+			match := fmt.Sprintf("%s && %s && %s && ip", peerMatch, l4Match, portMatch)
+			acls = append(acls, newACL(direction, allowPriority, match, ovnnb.ACLActionAllowRelated))
+		}
+	}
+	return acls
+}
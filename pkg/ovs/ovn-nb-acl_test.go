@@ -0,0 +1,81 @@
+package ovs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+)
+
+func Test_npPortMatches(t *testing.T) {
+	ast := assert.New(t)
+	udp := corev1.ProtocolUDP
+	endPort := int32(2000)
+	namedPorts := map[string][]NamedPortInfo{
+		"http": {
+			{Protocol: corev1.ProtocolTCP, PortID: 8080, AddressSet: "np.http.8080"},
+			{Protocol: corev1.ProtocolTCP, PortID: 80, AddressSet: "np.http.80"},
+		},
+		"dns": {{Protocol: corev1.ProtocolUDP, PortID: 53, AddressSet: "np.dns.53"}},
+	}
+
+	port := intstr.FromInt(80)
+	ast.Equal([]string{"tcp.dst == 80"}, npPortMatches(netv1.NetworkPolicyPort{Port: &port}, "ip4", nil))
+	ast.Equal([]string{"udp"}, npPortMatches(netv1.NetworkPolicyPort{Protocol: &udp}, "ip4", nil))
+
+	port = intstr.FromInt(1000)
+	ast.Equal([]string{"1000 <= udp.dst <= 2000"}, npPortMatches(netv1.NetworkPolicyPort{Protocol: &udp, Port: &port, EndPort: &endPort}, "ip4", nil))
+
+	port = intstr.FromString("http")
+	ast.Equal([]string{"ip6.dst == $np.http.8080 && tcp.dst == 8080", "ip6.dst == $np.http.80 && tcp.dst == 80"},
+		npPortMatches(netv1.NetworkPolicyPort{Port: &port}, "ip6", namedPorts))
+	// the protocol of the named port should be the same as the policy port
+	ast.Empty(npPortMatches(netv1.NetworkPolicyPort{Protocol: &udp, Port: &port}, "ip4", namedPorts))
+	port = intstr.FromString("unknown")
+	ast.Empty(npPortMatches(netv1.NetworkPolicyPort{Port: &port}, "ip4", namedPorts))
+}
+
+func Test_npDefaultDropACL(t *testing.T) {
+	ast := assert.New(t)
+	acl := npDefaultDropACL("to-lport", "2000", "outport==@web.default && ip", false, false)
+	ast.Equal(&ovnnb.ACL{Direction: "to-lport", Priority: 2000, Match: "outport==@web.default && ip", Action: ovnnb.ACLActionDrop}, acl)
+
+	// the packets are allowed and logged in audit mode
+	acl = npDefaultDropACL("from-lport", "2000", "inport==@web.default && ip", false, true)
+	ast.Equal(ovnnb.ACLActionAllowRelated, acl.Action)
+	ast.True(acl.Log)
+	ast.Equal(ovnnb.ACLSeverityWarning, *acl.Severity)
+}
+
+func Test_NpIngressACLs(t *testing.T) {
+	ast := assert.New(t)
+	port := intstr.FromInt(80)
+	acls := NpIngressACLs("web.default", "allow", "except", kubeovnv1.ProtocolIPv4, []netv1.NetworkPolicyPort{{Port: &port}}, nil, false, false)
+	ast.Len(acls, 2)
+	ast.Equal("outport==@web.default && ip", acls[0].Match)
+	ast.Equal(2000, acls[0].Priority)
+	ast.Equal("ip4.src == $allow && ip4.src != $except && tcp.dst == 80 && outport==@web.default && ip", acls[1].Match)
+	ast.Equal(2001, acls[1].Priority)
+	ast.Equal(ovnnb.ACLActionAllowRelated, acls[1].Action)
+
+	acls = NpEgressACLs("web.default", "allow", "except", kubeovnv1.ProtocolIPv6, nil, nil, false, false)
+	ast.Len(acls, 2)
+	ast.Equal("from-lport", acls[1].Direction)
+	ast.Equal("ip6.dst == $allow && ip6.dst != $except && inport==@web.default && ip", acls[1].Match)
+}
+
+func Test_sgRuleACL(t *testing.T) {
+	ast := assert.New(t)
+	rule := &kubeovnv1.SgRule{IPVersion: "ipv4", Protocol: kubeovnv1.ProtocolTCP, Priority: 10, RemoteType: kubeovnv1.SgRemoteTypeAddress, RemoteAddress: "10.0.0.0/8", PortRangeMin: 80, PortRangeMax: 443, Policy: kubeovnv1.PolicyAllow}
+	acl := sgRuleACL("web", SgAclIngressDirection, rule)
+	ast.Equal(&ovnnb.ACL{Direction: "to-lport", Priority: 2290, Match: "outport==@ovn.sg.web && ip4 && ip4.src==10.0.0.0/8 && 80<=tcp.dst<=443", Action: ovnnb.ACLActionAllowRelated}, acl)
+
+	rule = &kubeovnv1.SgRule{IPVersion: "ipv6", Protocol: kubeovnv1.ProtocolICMP, Priority: 1, RemoteType: kubeovnv1.SgRemoteTypeSg, RemoteSecurityGroup: "db", Policy: kubeovnv1.PolicyDrop}
+	acl = sgRuleACL("web", SgAclEgressDirection, rule)
+	ast.Equal(&ovnnb.ACL{Direction: "from-lport", Priority: 2299, Match: "inport==@ovn.sg.web && ip6 && ip6.dst==$ovn.sg.db.associated.v4 && icmp6", Action: ovnnb.ACLActionDrop}, acl)
+}
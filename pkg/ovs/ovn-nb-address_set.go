@@ -0,0 +1,173 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+)
+
+// AddressSetAddAddressesOps generates the operations adding the addresses to the address set
+func (c OvnClient) AddressSetAddAddressesOps(asName string, addresses ...string) ([]ovsdb.Operation, error) {
+	as := &ovnnb.AddressSet{Name: asName}
+	ops, err := c.ovnNbClient.Where(as).Mutate(as, model.Mutation{
+		Field:   &as.Addresses,
+		Mutator: ovsdb.MutateOperationInsert,
+		Value:   addresses,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate mutate operations for address set %s: %v", asName, err)
+	}
+	return ops, nil
+}
+
+func (c OvnClient) GetAddressSet(name string, ignoreNotFound bool) (*ovnnb.AddressSet, error) {
+	as := &ovnnb.AddressSet{Name: name}
+	if err := c.ovnNbClient.Get(context.TODO(), as); err != nil {
+		if ignoreNotFound && err == client.ErrNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get address set %s: %v", name, err)
+	}
+
+	return as, nil
+}
+
+// CreateAddressSet creates the address set if it does not exist
+func (c OvnClient) CreateAddressSet(name string, externalIDs map[string]string) error {
+	as, err := c.GetAddressSet(name, true)
+	if err != nil {
+		return err
+	}
+	if as != nil {
+		return nil
+	}
+
+	as = &ovnnb.AddressSet{
+		Name:        name,
+		ExternalIDs: externalIDs,
+	}
+	ops, err := c.ovnNbClient.Create(as)
+	if err != nil {
+		return fmt.Errorf("failed to generate create operations for address set %s: %v", name, err)
+	}
+	if err = Transact(c.ovnNbClient, "as-add", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to create address set %s: %v", name, err)
+	}
+
+	return nil
+}
+
+// CreateNpAddressSet creates the address set of the network policy
+func (c OvnClient) CreateNpAddressSet(asName, npNamespace, npName, direction string) error {
+	return c.CreateAddressSet(asName, map[string]string{"np": fmt.Sprintf("%s/%s/%s", npNamespace, npName, direction)})
+}
+
+// CreateSgAssociatedAddressSet creates the v4 and v6 address sets of the security group
+func (c OvnClient) CreateSgAssociatedAddressSet(sgName string) error {
+	externalIDs := map[string]string{"sg": sgName}
+	if err := c.CreateAddressSet(GetSgV4AssociatedName(sgName), externalIDs); err != nil {
+		return err
+	}
+	return c.CreateAddressSet(GetSgV6AssociatedName(sgName), externalIDs)
+}
+
+// CreateFqdnAddressSets creates the v4 and v6 address sets of the domain name, which are shared by security groups and network policies
+func (c OvnClient) CreateFqdnAddressSets(fqdn string) error {
+	externalIDs := map[string]string{"fqdn": fqdn}
+	if err := c.CreateAddressSet(GetFqdnAddressSetName(fqdn, kubeovnv1.ProtocolIPv4), externalIDs); err != nil {
+		return err
+	}
+	return c.CreateAddressSet(GetFqdnAddressSetName(fqdn, kubeovnv1.ProtocolIPv6), externalIDs)
+}
+
+// SetAddressesToAddressSet replaces the addresses of the address set
+func (c OvnClient) SetAddressesToAddressSet(addresses []string, asName string) error {
+	as, err := c.GetAddressSet(asName, false)
+	if err != nil {
+		return err
+	}
+
+	if addresses == nil {
+		addresses = []string{}
+	}
+	as.Addresses = addresses
+	ops, err := c.ovnNbClient.Where(as).Update(as, &as.Addresses)
+	if err != nil {
+		return fmt.Errorf("failed to generate update operations for address set %s: %v", asName, err)
+	}
+	if err = Transact(c.ovnNbClient, "as-update", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to set addresses of address set %s: %v", asName, err)
+	}
+
+	return nil
+}
+
+// DeleteAddressSet deletes the address set if it exists
+func (c OvnClient) DeleteAddressSet(name string) error {
+	as, err := c.GetAddressSet(name, true)
+	if err != nil {
+		return err
+	}
+	if as == nil {
+		return nil
+	}
+
+	ops, err := c.ovnNbClient.Where(as).Delete()
+	if err != nil {
+		return fmt.Errorf("failed to generate delete operations for address set %s: %v", name, err)
+	}
+	if err = Transact(c.ovnNbClient, "as-del", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to delete address set %s: %v", name, err)
+	}
+
+	return nil
+}
+
+// ListAddressSets returns the address sets having all of the external ids
+func (c OvnClient) ListAddressSets(externalIDs map[string]string) ([]ovnnb.AddressSet, error) {
+	var asList []ovnnb.AddressSet
+	if err := c.ovnNbClient.WhereCache(func(as *ovnnb.AddressSet) bool {
+		for k, v := range externalIDs {
+			if as.ExternalIDs[k] != v {
+				return false
+			}
+		}
+		return true
+	}).List(context.TODO(), &asList); err != nil && err != client.ErrNotFound {
+		return nil, fmt.Errorf("failed to list address sets with external ids %v: %v", externalIDs, err)
+	}
+
+	return asList, nil
+}
+
+func (c OvnClient) listAddressSetNames(externalIDs map[string]string) ([]string, error) {
+	asList, err := c.ListAddressSets(externalIDs)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(asList))
+	for _, as := range asList {
+		names = append(names, as.Name)
+	}
+	return names, nil
+}
+
+// ListNpAddressSet returns the names of the address sets of the network policy in the direction
+func (c OvnClient) ListNpAddressSet(npNamespace, npName, direction string) ([]string, error) {
+	return c.listAddressSetNames(map[string]string{"np": fmt.Sprintf("%s/%s/%s", npNamespace, npName, direction)})
+}
+
+// ListSgRuleAddressSet returns the names of the address sets of the security group, in all directions if direction is empty
+func (c OvnClient) ListSgRuleAddressSet(sgName string, direction AclDirection) ([]string, error) {
+	externalIDs := map[string]string{"sg": sgName}
+	if direction != "" {
+		externalIDs["direction"] = string(direction)
+	}
+	return c.listAddressSetNames(externalIDs)
+}
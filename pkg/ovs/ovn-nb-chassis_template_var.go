@@ -0,0 +1,149 @@
+package ovs
+
+import (
+	"fmt"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// chassisTemplateVarTable is the table of the template variables instantiated by ovn-controller on each chassis,
+// it is added in OVN 22.12 and has no model in the client so that older NB servers are still supported
+const chassisTemplateVarTable = "Chassis_Template_Var"
+
+// ChassisTemplateVarSupported returns whether the running OVN supports the chassis template variables,
+// which the template load balancers refer to
+func (c OvnClient) ChassisTemplateVarSupported() bool {
+	_, ok := c.ovnNbClient.Schema().Tables[chassisTemplateVarTable]
+	return ok
+}
+
+// chassisTemplateVarRow is a row of the Chassis_Template_Var table
+type chassisTemplateVarRow struct {
+	uuid      string
+	variables map[string]string
+}
+
+// listChassisTemplateVars returns the rows of the Chassis_Template_Var table by chassis
+func (c OvnClient) listChassisTemplateVars() (map[string]*chassisTemplateVarRow, error) {
+	op := ovsdb.Operation{
+		Op:      ovsdb.OperationSelect,
+		Table:   chassisTemplateVarTable,
+		Columns: []string{"_uuid", "chassis", "variables"},
+	}
+	results, err := TransactWithResults(c.ovnNbClient, "chassis-template-var-list", []ovsdb.Operation{op}, c.ovnNbClient.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chassis template vars: %v", err)
+	}
+
+	rows := make(map[string]*chassisTemplateVarRow)
+	if len(results) == 0 {
+		return rows, nil
+	}
+	for _, row := range results[0].Rows {
+		uuid, _ := row["_uuid"].(ovsdb.UUID)
+		chassis, _ := row["chassis"].(string)
+		variables := make(map[string]string)
+		if m, ok := row["variables"].(ovsdb.OvsMap); ok {
+			for k, v := range m.GoMap {
+				key, _ := k.(string)
+				value, _ := v.(string)
+				variables[key] = value
+			}
+		}
+		rows[chassis] = &chassisTemplateVarRow{uuid: uuid.GoUUID, variables: variables}
+	}
+	return rows, nil
+}
+
+// GetChassisTemplateVars returns the values of the template variables on the chassises by variable name
+func (c OvnClient) GetChassisTemplateVars() (map[string]map[string]string, error) {
+	rows, err := c.listChassisTemplateVars()
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]map[string]string)
+	for chassis, row := range rows {
+		for name, value := range row.variables {
+			if vars[name] == nil {
+				vars[name] = make(map[string]string)
+			}
+			vars[name][chassis] = value
+		}
+	}
+	return vars, nil
+}
+
+// UpdateChassisTemplateVars sets the template variables to the values on the chassises by variable name,
+// a variable is removed from the chassises without a value, and from all chassises if it has no values
+func (c OvnClient) UpdateChassisTemplateVars(vars map[string]map[string]string) error {
+	if len(vars) == 0 {
+		return nil
+	}
+	rows, err := c.listChassisTemplateVars()
+	if err != nil {
+		return err
+	}
+
+	for _, values := range vars {
+		for chassis := range values {
+			if rows[chassis] == nil {
+				rows[chassis] = &chassisTemplateVarRow{variables: make(map[string]string)}
+			}
+		}
+	}
+
+	var ops []ovsdb.Operation
+	for chassis, row := range rows {
+		variables := make(map[interface{}]interface{}, len(row.variables))
+		for name, value := range row.variables {
+			variables[name] = value
+		}
+		changed := false
+		for name, values := range vars {
+			value, ok := values[chassis]
+			if existing, exists := row.variables[name]; exists == ok && existing == value {
+				continue
+			}
+			if ok {
+				variables[name] = value
+			} else {
+				delete(variables, name)
+			}
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+
+		switch {
+		case row.uuid == "":
+			ops = append(ops, ovsdb.Operation{
+				Op:    ovsdb.OperationInsert,
+				Table: chassisTemplateVarTable,
+				Row:   ovsdb.Row{"chassis": chassis, "variables": ovsdb.OvsMap{GoMap: variables}},
+			})
+		case len(variables) == 0:
+			ops = append(ops, ovsdb.Operation{
+				Op:    ovsdb.OperationDelete,
+				Table: chassisTemplateVarTable,
+				Where: []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: row.uuid})},
+			})
+		default:
+			ops = append(ops, ovsdb.Operation{
+				Op:    ovsdb.OperationUpdate,
+				Table: chassisTemplateVarTable,
+				Row:   ovsdb.Row{"variables": ovsdb.OvsMap{GoMap: variables}},
+				Where: []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: row.uuid})},
+			})
+		}
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+
+	if err = Transact(c.ovnNbClient, "chassis-template-var-update", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to update chassis template vars: %v", err)
+	}
+	return nil
+}
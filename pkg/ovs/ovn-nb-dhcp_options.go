@@ -0,0 +1,215 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"k8s.io/klog/v2"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	ovsclient "github.com/kubeovn/kube-ovn/pkg/ovsdb/client"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// DHCPOptionsUUIDs are the uuids of the dhcp options of a logical switch referred by its ports
+type DHCPOptionsUUIDs struct {
+	DHCPv4OptionsUUID string
+	DHCPv6OptionsUUID string
+}
+
+// ListDHCPOptions returns the dhcp options, filtered by vendor, logical switch and protocol if specified
+func (c OvnClient) ListDHCPOptions(needVendorFilter bool, ls, protocol string) ([]ovnnb.DHCPOptions, error) {
+	var dhcpOptionsList []ovnnb.DHCPOptions
+	if err := c.ovnNbClient.WhereCache(func(options *ovnnb.DHCPOptions) bool {
+		if needVendorFilter && options.ExternalIDs["vendor"] != util.CniTypeName {
+			return false
+		}
+		if ls != "" && options.ExternalIDs["ls"] != ls {
+			return false
+		}
+		if protocol != "" && protocol != kubeovnv1.ProtocolDual && options.ExternalIDs["protocol"] != protocol {
+			return false
+		}
+		return true
+	}).List(context.TODO(), &dhcpOptionsList); err != nil && err != client.ErrNotFound {
+		return nil, fmt.Errorf("failed to list dhcp options: %v", err)
+	}
+
+	return dhcpOptionsList, nil
+}
+
+// DeleteDHCPOptionsByUUIDs deletes the dhcp options in one transaction
+func (c OvnClient) DeleteDHCPOptionsByUUIDs(uuidList []string) error {
+	ops := make([]ovsdb.Operation, 0, len(uuidList))
+	for _, uuid := range uuidList {
+		options := &ovnnb.DHCPOptions{UUID: uuid}
+		op, err := c.ovnNbClient.Where(options).Delete()
+		if err != nil {
+			return fmt.Errorf("failed to generate delete operations for dhcp options %s: %v", uuid, err)
+		}
+		ops = append(ops, op...)
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	if err := Transact(c.ovnNbClient, "dhcp-options-del", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to delete dhcp options %v: %v", uuidList, err)
+	}
+
+	return nil
+}
+
+// DeleteDHCPOptions deletes the dhcp options of the logical switch in the protocol
+func (c OvnClient) DeleteDHCPOptions(ls, protocol string) error {
+	dhcpOptionsList, err := c.ListDHCPOptions(true, ls, protocol)
+	if err != nil {
+		return err
+	}
+	uuidList := make([]string, 0, len(dhcpOptionsList))
+	for _, options := range dhcpOptionsList {
+		uuidList = append(uuidList, options.UUID)
+	}
+	return c.DeleteDHCPOptionsByUUIDs(uuidList)
+}
+
+// parseDHCPOptions parses the options in the form of k1=v1,k2=v2,
+// the commas in braces are kept in the value, e.g. dns_server={8.8.8.8,8.8.4.4}
+func parseDHCPOptions(optionsStr string) map[string]string {
+	options := make(map[string]string)
+	var depth, start int
+	for i := 0; i <= len(optionsStr); i++ {
+		if i < len(optionsStr) {
+			switch optionsStr[i] {
+			case '{':
+				depth++
+				continue
+			case '}':
+				depth--
+				continue
+			case ',':
+				if depth != 0 {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+		if kv := strings.SplitN(optionsStr[start:i], "=", 2); len(kv) == 2 && kv[0] != "" {
+			options[kv[0]] = kv[1]
+		}
+		start = i + 1
+	}
+	return options
+}
+
+// UpdateDHCPOptions creates, updates or deletes the dhcp options of the logical switch in each protocol of the cidr block,
+// the default options are used if the ones of the protocol are not specified
+func (c OvnClient) UpdateDHCPOptions(ls, cidrBlock, gateway, dhcpV4OptionsStr, dhcpV6OptionsStr string, enableDHCP bool) (*DHCPOptionsUUIDs, error) {
+	if !enableDHCP {
+		if err := c.DeleteDHCPOptions(ls, kubeovnv1.ProtocolDual); err != nil {
+			klog.Errorf("delete dhcp options for switch %s failed: %v", ls, err)
+			return nil, err
+		}
+		return &DHCPOptionsUUIDs{}, nil
+	}
+
+	var v4CIDR, v6CIDR, v4Gateway string
+	switch util.CheckProtocol(cidrBlock) {
+	case kubeovnv1.ProtocolIPv4:
+		v4CIDR, v4Gateway = cidrBlock, gateway
+	case kubeovnv1.ProtocolIPv6:
+		v6CIDR = cidrBlock
+	case kubeovnv1.ProtocolDual:
+		cidrBlocks := strings.Split(cidrBlock, ",")
+		v4CIDR, v6CIDR = cidrBlocks[0], cidrBlocks[1]
+		v4Gateway = strings.Split(gateway, ",")[0]
+	}
+
+	var err error
+	uuids := &DHCPOptionsUUIDs{}
+	uuids.DHCPv4OptionsUUID, err = c.updateDHCPOptions(ls, kubeovnv1.ProtocolIPv4, v4CIDR, dhcpV4OptionsStr, func(existing map[string]string) map[string]string {
+		mac := existing["server_mac"]
+		if mac == "" {
+			mac = util.GenerateMac()
+		}
+		return map[string]string{"lease_time": "3600", "router": v4Gateway, "server_id": "169.254.0.254", "server_mac": mac}
+	})
+	if err != nil {
+		klog.Errorf("update dhcp options for switch %s failed: %v", ls, err)
+		return nil, err
+	}
+	uuids.DHCPv6OptionsUUID, err = c.updateDHCPOptions(ls, kubeovnv1.ProtocolIPv6, v6CIDR, dhcpV6OptionsStr, func(existing map[string]string) map[string]string {
+		mac := existing["server_id"]
+		if mac == "" {
+			mac = util.GenerateMac()
+		}
+		return map[string]string{"server_id": mac}
+	})
+	if err != nil {
+		klog.Errorf("update dhcp options for switch %s failed: %v", ls, err)
+		return nil, err
+	}
+	return uuids, nil
+}
+
+// updateDHCPOptions makes the dhcp options of the logical switch in the protocol consistent with the cidr
+// and returns the uuid, the options are deleted if the cidr is empty
+func (c OvnClient) updateDHCPOptions(ls, protocol, cidr, optionsStr string, defaultOptions func(existing map[string]string) map[string]string) (string, error) {
+	dhcpOptionsList, err := c.ListDHCPOptions(true, ls, protocol)
+	if err != nil {
+		return "", err
+	}
+	if cidr == "" {
+		if len(dhcpOptionsList) != 0 {
+			return "", c.DeleteDHCPOptions(ls, protocol)
+		}
+		return "", nil
+	}
+
+	var existing map[string]string
+	if len(dhcpOptionsList) != 0 {
+		existing = dhcpOptionsList[0].Options
+	}
+	options := parseDHCPOptions(strings.ReplaceAll(optionsStr, " ", ""))
+	if len(options) == 0 {
+		options = defaultOptions(existing)
+	}
+
+	if len(dhcpOptionsList) != 0 {
+		dhcpOptions := &dhcpOptionsList[0]
+		if dhcpOptions.Cidr == cidr && reflect.DeepEqual(dhcpOptions.Options, options) {
+			return dhcpOptions.UUID, nil
+		}
+		dhcpOptions.Cidr, dhcpOptions.Options = cidr, options
+		ops, err := c.ovnNbClient.Where(dhcpOptions).Update(dhcpOptions, &dhcpOptions.Cidr, &dhcpOptions.Options)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate update operations for dhcp options %s: %v", dhcpOptions.UUID, err)
+		}
+		if err = Transact(c.ovnNbClient, "dhcp-options-set", ops, c.ovnNbClient.Timeout); err != nil {
+			return "", fmt.Errorf("failed to update dhcp options %s of switch %s: %v", dhcpOptions.UUID, ls, err)
+		}
+		return dhcpOptions.UUID, nil
+	}
+
+	klog.Infof("create dhcp options ls:%s, cidr:%s, options:%v", ls, cidr, options)
+	dhcpOptions := &ovnnb.DHCPOptions{
+		UUID:        ovsclient.NamedUUID(),
+		Cidr:        cidr,
+		Options:     options,
+		ExternalIDs: map[string]string{"ls": ls, "protocol": protocol, "vendor": util.CniTypeName},
+	}
+	ops, err := c.ovnNbClient.Create(dhcpOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate create operations for dhcp options %s: %v", cidr, err)
+	}
+	results, err := TransactWithResults(c.ovnNbClient, "dhcp-options-create", ops, c.ovnNbClient.Timeout)
+	if err != nil {
+		return "", fmt.Errorf("failed to create dhcp options %s for switch %s: %v", cidr, ls, err)
+	}
+	return results[0].UUID.GoUUID, nil
+}
@@ -0,0 +1,20 @@
+package ovs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseDHCPOptions(t *testing.T) {
+	ast := assert.New(t)
+
+	ast.Equal(map[string]string{
+		"lease_time": "3600",
+		"router":     "10.16.0.1",
+		"dns_server": "{8.8.8.8,8.8.4.4}",
+		"server_mac": "00:00:00:2e:2f:b8",
+	}, parseDHCPOptions("lease_time=3600,router=10.16.0.1,dns_server={8.8.8.8,8.8.4.4},server_mac=00:00:00:2e:2f:b8"))
+	ast.Equal(map[string]string{"server_id": "00:00:00:2e:2f:b8"}, parseDHCPOptions("server_id=00:00:00:2e:2f:b8,"))
+	ast.Empty(parseDHCPOptions(""))
+}
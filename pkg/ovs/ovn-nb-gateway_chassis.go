@@ -0,0 +1,103 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	ovsclient "github.com/kubeovn/kube-ovn/pkg/ovsdb/client"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func gatewayChassisName(lrpName, chassis string) string {
+	return fmt.Sprintf("%s-%s", lrpName, chassis)
+}
+
+// SetGatewayChassises replaces the gateway chassises of the logical router port,
+// the first chassis gets the highest priority the same as lrp-set-gateway-chassis
+func (c OvnClient) SetGatewayChassises(lrpName string, chassises []string) error {
+	// the port is matched by name in the database instead of the cache, it may have been created by ovn-nbctl just now
+	ops := []ovsdb.Operation{ConstructWaitForNameExistsOperation(lrpName, "Logical_Router_Port")}
+	uuids := make([]string, 0, len(chassises))
+	for i, chassis := range chassises {
+		gwChassis := &ovnnb.GatewayChassis{Name: gatewayChassisName(lrpName, chassis)}
+		if err := c.ovnNbClient.Get(context.TODO(), gwChassis); err == nil {
+			// names are unique, reuse the existing one
+			gwChassis.Priority = 100 - i
+			updateOps, err := c.ovnNbClient.Where(gwChassis).Update(gwChassis, &gwChassis.Priority)
+			if err != nil {
+				return fmt.Errorf("failed to generate update operations for gateway chassis %s: %v", gwChassis.Name, err)
+			}
+			ops = append(ops, updateOps...)
+			uuids = append(uuids, gwChassis.UUID)
+			continue
+		} else if err != client.ErrNotFound {
+			return fmt.Errorf("failed to get gateway chassis %s: %v", gwChassis.Name, err)
+		}
+
+		gwChassis.UUID = ovsclient.NamedUUID()
+		gwChassis.ChassisName = chassis
+		gwChassis.Priority = 100 - i
+		gwChassis.ExternalIDs = map[string]string{"vendor": util.CniTypeName}
+		createOps, err := c.ovnNbClient.Create(gwChassis)
+		if err != nil {
+			return fmt.Errorf("failed to generate create operations for gateway chassis %s: %v", gwChassis.Name, err)
+		}
+		ops = append(ops, createOps...)
+		uuids = append(uuids, gwChassis.UUID)
+	}
+
+	// gateway chassises are garbage collected once the logical router port does not refer to them
+	lrp := &ovnnb.LogicalRouterPort{Name: lrpName, GatewayChassis: uuids}
+	updateOps, err := c.ovnNbClient.Where(lrp).Update(lrp, &lrp.GatewayChassis)
+	if err != nil {
+		return fmt.Errorf("failed to generate update operations for logical router port %s: %v", lrpName, err)
+	}
+	ops = append(ops, updateOps...)
+	if err = Transact(c.ovnNbClient, "lrp-set-gateway-chassis", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to set gateway chassises of logical router port %s: %v", lrpName, err)
+	}
+
+	return nil
+}
+
+// DeleteGatewayChassises removes the gateway chassises from the logical router port
+func (c OvnClient) DeleteGatewayChassises(lrpName string, chassises []string) error {
+	lrp, err := c.GetLogicalRouterPort(lrpName, true)
+	if err != nil || lrp == nil {
+		return err
+	}
+
+	var uuids []string
+	for _, chassis := range chassises {
+		gwChassis := &ovnnb.GatewayChassis{Name: gatewayChassisName(lrpName, chassis)}
+		if err = c.ovnNbClient.Get(context.TODO(), gwChassis); err != nil {
+			if err == client.ErrNotFound {
+				continue
+			}
+			return fmt.Errorf("failed to get gateway chassis %s: %v", gwChassis.Name, err)
+		}
+		uuids = append(uuids, gwChassis.UUID)
+	}
+	if len(uuids) == 0 {
+		return nil
+	}
+
+	ops, err := c.ovnNbClient.Where(lrp).Mutate(lrp, model.Mutation{
+		Field:   &lrp.GatewayChassis,
+		Mutator: ovsdb.MutateOperationDelete,
+		Value:   uuids,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate mutate operations for logical router port %s: %v", lrpName, err)
+	}
+	if err = Transact(c.ovnNbClient, "lrp-del-gateway-chassis", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to delete gateway chassises of logical router port %s: %v", lrpName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,259 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// lbAffinityTimeoutSchemaVersion is the NB schema version of the first OVN release supporting the affinity timeout
+const lbAffinityTimeoutSchemaVersion = "7.0.0"
+
+// GetLoadBalancer returns the load balancer in the monitor cache
+func (c OvnClient) GetLoadBalancer(name string, ignoreNotFound bool) (*ovnnb.LoadBalancer, error) {
+	predicate := func(model *ovnnb.LoadBalancer) bool {
+		return model.Name == name
+	}
+	// Load_Balancer has no indexes defined in the schema
+	var result []*ovnnb.LoadBalancer
+	if err := c.ovnNbClient.WhereCache(predicate).List(context.TODO(), &result); err != nil || len(result) == 0 {
+		if ignoreNotFound && (err == client.ErrNotFound || len(result) == 0) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get load balancer %s: %v", name, err)
+	}
+	if len(result) > 1 {
+		return nil, fmt.Errorf("%s has %d load balancer entries", name, len(result))
+	}
+
+	return result[0], nil
+}
+
+func (c OvnClient) LoadBalancerExists(name string) (bool, error) {
+	lb, err := c.GetLoadBalancer(name, true)
+	return lb != nil, err
+}
+
+// ListLoadBalancers returns the names of all load balancers
+func (c OvnClient) ListLoadBalancers() ([]string, error) {
+	var lbList []ovnnb.LoadBalancer
+	if err := c.ovnNbClient.List(context.TODO(), &lbList); err != nil {
+		return nil, fmt.Errorf("failed to list load balancers: %v", err)
+	}
+
+	names := make([]string, 0, len(lbList))
+	for _, lb := range lbList {
+		names = append(names, lb.Name)
+	}
+	return names, nil
+}
+
+// CreateLoadBalancer creates the load balancer if it does not exist
+func (c OvnClient) CreateLoadBalancer(name, protocol, selectFields string) error {
+	lb, err := c.GetLoadBalancer(name, true)
+	if err != nil {
+		return err
+	}
+	if lb != nil {
+		return nil
+	}
+
+	lb = &ovnnb.LoadBalancer{
+		Name:     name,
+		Protocol: &protocol,
+	}
+	if selectFields != "" {
+		lb.SelectionFields = strings.Split(selectFields, ",")
+	}
+	ops, err := c.ovnNbClient.Create(lb)
+	if err != nil {
+		return fmt.Errorf("failed to generate create operations for load balancer %s: %v", name, err)
+	}
+	if err = Transact(c.ovnNbClient, "lb-add", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to create load balancer %s: %v", name, err)
+	}
+
+	return nil
+}
+
+// CreateOrUpdateLoadBalancer creates the load balancer with the selection fields, options and external ids,
+// or updates them of the existing one
+func (c OvnClient) CreateOrUpdateLoadBalancer(name, protocol string, selectFields []string, options, externalIDs map[string]string) error {
+	lb, err := c.GetLoadBalancer(name, true)
+	if err != nil {
+		return err
+	}
+
+	var ops []ovsdb.Operation
+	if lb == nil {
+		lb = &ovnnb.LoadBalancer{
+			Name:            name,
+			Protocol:        &protocol,
+			SelectionFields: selectFields,
+			Options:         options,
+			ExternalIDs:     externalIDs,
+		}
+		if ops, err = c.ovnNbClient.Create(lb); err != nil {
+			return fmt.Errorf("failed to generate create operations for load balancer %s: %v", name, err)
+		}
+	} else {
+		if equalStrings(lb.SelectionFields, selectFields) && equalStringMaps(lb.Options, options) && equalStringMaps(lb.ExternalIDs, externalIDs) {
+			return nil
+		}
+		lb.SelectionFields, lb.Options, lb.ExternalIDs = selectFields, options, externalIDs
+		if ops, err = c.ovnNbClient.Where(lb).Update(lb, &lb.SelectionFields, &lb.Options, &lb.ExternalIDs); err != nil {
+			return fmt.Errorf("failed to generate update operations for load balancer %s: %v", name, err)
+		}
+	}
+	if err = Transact(c.ovnNbClient, "lb-update", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to create or update load balancer %s: %v", name, err)
+	}
+
+	return nil
+}
+
+// LoadBalancerAffinityTimeoutSupported returns whether the running OVN supports options:affinity_timeout of load balancers,
+// which is ignored by the releases before the NB schema 7.0.0
+func (c OvnClient) LoadBalancerAffinityTimeoutSupported() bool {
+	return util.CompareVersion(c.ovnNbClient.Schema().Version, lbAffinityTimeoutSchemaVersion) >= 0
+}
+
+// ListLoadBalancersByExternalIDs returns the load balancers with the external ids, empty values match any value
+func (c OvnClient) ListLoadBalancersByExternalIDs(externalIDs map[string]string) ([]ovnnb.LoadBalancer, error) {
+	var lbList []ovnnb.LoadBalancer
+	if err := c.ovnNbClient.WhereCache(func(lb *ovnnb.LoadBalancer) bool {
+		for k, v := range externalIDs {
+			if value, ok := lb.ExternalIDs[k]; !ok || (v != "" && value != v) {
+				return false
+			}
+		}
+		return true
+	}).List(context.TODO(), &lbList); err != nil && err != client.ErrNotFound {
+		return nil, fmt.Errorf("failed to list load balancers with external ids %v: %v", externalIDs, err)
+	}
+
+	return lbList, nil
+}
+
+func equalStrings(a, b []string) bool {
+	return len(a) == len(b) && (len(a) == 0 || reflect.DeepEqual(a, b))
+}
+
+func equalStringMaps(a, b map[string]string) bool {
+	return len(a) == len(b) && (len(a) == 0 || reflect.DeepEqual(a, b))
+}
+
+// DeleteLoadBalancers deletes the load balancers, load balancers not found are skipped
+func (c OvnClient) DeleteLoadBalancers(names ...string) error {
+	ops := make([]ovsdb.Operation, 0, len(names))
+	for _, name := range names {
+		lb, err := c.GetLoadBalancer(name, true)
+		if err != nil {
+			return err
+		}
+		if lb == nil {
+			continue
+		}
+		op, err := c.ovnNbClient.Where(lb).Delete()
+		if err != nil {
+			return fmt.Errorf("failed to generate delete operations for load balancer %s: %v", name, err)
+		}
+		ops = append(ops, op...)
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	if err := Transact(c.ovnNbClient, "lb-del", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to delete load balancers %v: %v", names, err)
+	}
+
+	return nil
+}
+
+// GetLoadBalancerVips returns the vips of the load balancer, nil if the load balancer does not exist
+func (c OvnClient) GetLoadBalancerVips(name string) (map[string]string, error) {
+	lb, err := c.GetLoadBalancer(name, true)
+	if err != nil || lb == nil {
+		return nil, err
+	}
+	return lb.Vips, nil
+}
+
+// LoadBalancerAddVip sets the backends of the vip, the existing backends of the vip are replaced
+func (c OvnClient) LoadBalancerAddVip(name, vip string, backends ...string) error {
+	lb, err := c.GetLoadBalancer(name, false)
+	if err != nil {
+		return err
+	}
+
+	value := strings.Join(backends, ",")
+	if existing, ok := lb.Vips[vip]; ok && existing == value {
+		return nil
+	}
+
+	ops, err := c.ovnNbClient.Where(lb).Mutate(lb,
+		model.Mutation{
+			Field:   &lb.Vips,
+			Mutator: ovsdb.MutateOperationDelete,
+			Value:   []string{vip},
+		},
+		model.Mutation{
+			Field:   &lb.Vips,
+			Mutator: ovsdb.MutateOperationInsert,
+			Value:   map[string]string{vip: value},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to generate mutate operations for load balancer %s: %v", name, err)
+	}
+	if err = Transact(c.ovnNbClient, "lb-add", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to add vip %s to load balancer %s: %v", vip, name, err)
+	}
+
+	return nil
+}
+
+// LoadBalancerDeleteVip removes the vip and its health check from the load balancer, unlike lb-del of ovn-nbctl,
+// the load balancer is kept even if the last vip is removed
+func (c OvnClient) LoadBalancerDeleteVip(name, vip string) error {
+	if vip == "" {
+		return nil
+	}
+	lb, err := c.GetLoadBalancer(name, true)
+	if err != nil {
+		return err
+	}
+	if lb == nil {
+		return nil
+	}
+	if _, ok := lb.Vips[vip]; !ok {
+		return nil
+	}
+
+	ops, err := c.ovnNbClient.Where(lb).Mutate(lb, model.Mutation{
+		Field:   &lb.Vips,
+		Mutator: ovsdb.MutateOperationDelete,
+		Value:   []string{vip},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate mutate operations for load balancer %s: %v", name, err)
+	}
+	hcOps, err := c.loadBalancerDeleteHealthCheckOps(lb, vip)
+	if err != nil {
+		return err
+	}
+	ops = append(ops, hcOps...)
+	if err = Transact(c.ovnNbClient, "lb-del", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to delete vip %s from load balancer %s: %v", vip, name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	ovsclient "github.com/kubeovn/kube-ovn/pkg/ovsdb/client"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// getLoadBalancerHealthChecks returns the health checks of the load balancer by vip
+func (c OvnClient) getLoadBalancerHealthChecks(lb *ovnnb.LoadBalancer) (map[string]*ovnnb.LoadBalancerHealthCheck, error) {
+	healthChecks := make(map[string]*ovnnb.LoadBalancerHealthCheck, len(lb.HealthCheck))
+	for _, uuid := range lb.HealthCheck {
+		hc := &ovnnb.LoadBalancerHealthCheck{UUID: uuid}
+		if err := c.ovnNbClient.Get(context.TODO(), hc); err != nil {
+			return nil, fmt.Errorf("failed to get health check %s of load balancer %s: %v", uuid, lb.Name, err)
+		}
+		healthChecks[hc.Vip] = hc
+	}
+	return healthChecks, nil
+}
+
+// GetLoadBalancerHealthCheck returns the health check of the vip, nil if the vip is not checked
+func (c OvnClient) GetLoadBalancerHealthCheck(name, vip string) (*ovnnb.LoadBalancerHealthCheck, error) {
+	lb, err := c.GetLoadBalancer(name, true)
+	if err != nil || lb == nil {
+		return nil, err
+	}
+	healthChecks, err := c.getLoadBalancerHealthChecks(lb)
+	if err != nil {
+		return nil, err
+	}
+	return healthChecks[vip], nil
+}
+
+// LoadBalancerAddHealthCheck checks the backends of the vip with the options,
+// the options of the existing health check of the vip are replaced
+func (c OvnClient) LoadBalancerAddHealthCheck(name, vip string, options map[string]string) error {
+	lb, err := c.GetLoadBalancer(name, false)
+	if err != nil {
+		return err
+	}
+	healthChecks, err := c.getLoadBalancerHealthChecks(lb)
+	if err != nil {
+		return err
+	}
+
+	var ops []ovsdb.Operation
+	if hc := healthChecks[vip]; hc != nil {
+		if len(hc.Options) == 0 && len(options) == 0 || reflect.DeepEqual(hc.Options, options) {
+			return nil
+		}
+		hc.Options = options
+		if ops, err = c.ovnNbClient.Where(hc).Update(hc, &hc.Options); err != nil {
+			return fmt.Errorf("failed to generate update operations for health check of vip %s: %v", vip, err)
+		}
+	} else {
+		hc = &ovnnb.LoadBalancerHealthCheck{
+			UUID:        ovsclient.NamedUUID(),
+			Vip:         vip,
+			Options:     options,
+			ExternalIDs: map[string]string{"vendor": util.CniTypeName, "lb": name},
+		}
+		if ops, err = c.ovnNbClient.Create(hc); err != nil {
+			return fmt.Errorf("failed to generate create operations for health check of vip %s: %v", vip, err)
+		}
+		mutateOps, err := c.ovnNbClient.Where(lb).Mutate(lb, model.Mutation{
+			Field:   &lb.HealthCheck,
+			Mutator: ovsdb.MutateOperationInsert,
+			Value:   []string{hc.UUID},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to generate mutate operations for load balancer %s: %v", name, err)
+		}
+		ops = append(ops, mutateOps...)
+	}
+
+	if err = Transact(c.ovnNbClient, "lb-hc-add", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to add health check of vip %s to load balancer %s: %v", vip, name, err)
+	}
+	return nil
+}
+
+// LoadBalancerDeleteHealthCheck stops checking the backends of the vip,
+// the ip port mappings of the backends not checked by any other vip are removed
+func (c OvnClient) LoadBalancerDeleteHealthCheck(name, vip string) error {
+	lb, err := c.GetLoadBalancer(name, true)
+	if err != nil || lb == nil {
+		return err
+	}
+	ops, err := c.loadBalancerDeleteHealthCheckOps(lb, vip)
+	if err != nil || len(ops) == 0 {
+		return err
+	}
+	if err = Transact(c.ovnNbClient, "lb-hc-del", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to delete health check of vip %s from load balancer %s: %v", vip, lb.Name, err)
+	}
+	return nil
+}
+
+// loadBalancerDeleteHealthCheckOps returns the operations deleting the health check of the vip
+// and the ip port mappings no longer used, nil if the vip is not checked
+func (c OvnClient) loadBalancerDeleteHealthCheckOps(lb *ovnnb.LoadBalancer, vip string) ([]ovsdb.Operation, error) {
+	healthChecks, err := c.getLoadBalancerHealthChecks(lb)
+	if err != nil {
+		return nil, err
+	}
+	hc := healthChecks[vip]
+	if hc == nil {
+		return nil, nil
+	}
+	delete(healthChecks, vip)
+
+	mutations := []model.Mutation{{
+		Field:   &lb.HealthCheck,
+		Mutator: ovsdb.MutateOperationDelete,
+		Value:   []string{hc.UUID},
+	}}
+	if stale := staleIPPortMappings(lb, healthChecks); len(stale) != 0 {
+		mutations = append(mutations, model.Mutation{
+			Field:   &lb.IPPortMappings,
+			Mutator: ovsdb.MutateOperationDelete,
+			Value:   stale,
+		})
+	}
+	ops, err := c.ovnNbClient.Where(lb).Mutate(lb, mutations...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate mutate operations for load balancer %s: %v", lb.Name, err)
+	}
+	deleteOps, err := c.ovnNbClient.Where(hc).Delete()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate delete operations for health check of vip %s: %v", vip, err)
+	}
+	return append(ops, deleteOps...), nil
+}
+
+// LoadBalancerUpdateIPPortMappings sets the logical port and source ip of the checked backends by backend ip,
+// the mappings of the ips which are not the backends of any checked vip are removed
+func (c OvnClient) LoadBalancerUpdateIPPortMappings(name string, mappings map[string]string) error {
+	lb, err := c.GetLoadBalancer(name, false)
+	if err != nil {
+		return err
+	}
+	healthChecks, err := c.getLoadBalancerHealthChecks(lb)
+	if err != nil {
+		return err
+	}
+
+	checked := checkedBackendIPs(lb, healthChecks)
+	expected := make(map[string]string, len(lb.IPPortMappings))
+	for ip, mapping := range lb.IPPortMappings {
+		if checked[strings.Trim(ip, "[]")] {
+			expected[ip] = mapping
+		}
+	}
+	for ip, mapping := range mappings {
+		if checked[strings.Trim(ip, "[]")] {
+			expected[ip] = mapping
+		}
+	}
+	if len(expected) == len(lb.IPPortMappings) && (len(expected) == 0 || reflect.DeepEqual(expected, lb.IPPortMappings)) {
+		return nil
+	}
+
+	lb.IPPortMappings = expected
+	ops, err := c.ovnNbClient.Where(lb).Update(lb, &lb.IPPortMappings)
+	if err != nil {
+		return fmt.Errorf("failed to generate update operations for load balancer %s: %v", name, err)
+	}
+	if err = Transact(c.ovnNbClient, "lb-update", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to update ip port mappings of load balancer %s: %v", name, err)
+	}
+	return nil
+}
+
+// checkedBackendIPs returns the backend ips of the vips with health checks
+func checkedBackendIPs(lb *ovnnb.LoadBalancer, healthChecks map[string]*ovnnb.LoadBalancerHealthCheck) map[string]bool {
+	ips := make(map[string]bool)
+	for vip := range healthChecks {
+		for _, backend := range strings.Split(lb.Vips[vip], ",") {
+			if backend == "" {
+				continue
+			}
+			if host, _, err := net.SplitHostPort(backend); err == nil {
+				ips[host] = true
+			} else {
+				ips[strings.Trim(backend, "[]")] = true
+			}
+		}
+	}
+	return ips
+}
+
+// staleIPPortMappings returns the keys of the ip port mappings not used by the health checks
+func staleIPPortMappings(lb *ovnnb.LoadBalancer, healthChecks map[string]*ovnnb.LoadBalancerHealthCheck) []string {
+	checked := checkedBackendIPs(lb, healthChecks)
+	var stale []string
+	for ip := range lb.IPPortMappings {
+		if !checked[strings.Trim(ip, "[]")] {
+			stale = append(stale, ip)
+		}
+	}
+	return stale
+}
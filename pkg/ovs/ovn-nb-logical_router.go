@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
 
 	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
 )
@@ -29,3 +31,54 @@ func (c OvnClient) LogicalRouterExists(name string) (bool, error) {
 	lr, err := c.GetLogicalRouter(name, true)
 	return lr != nil, err
 }
+
+// ListLogicalRoutersWithFilter returns the logical routers matching the filter, all logical routers are returned if filter is nil
+func (c OvnClient) ListLogicalRoutersWithFilter(filter func(lr *ovnnb.LogicalRouter) bool) ([]ovnnb.LogicalRouter, error) {
+	var lrList []ovnnb.LogicalRouter
+	if err := c.ovnNbClient.WhereCache(func(lr *ovnnb.LogicalRouter) bool {
+		return filter == nil || filter(lr)
+	}).List(context.TODO(), &lrList); err != nil && err != client.ErrNotFound {
+		return nil, fmt.Errorf("failed to list logical routers: %v", err)
+	}
+
+	return lrList, nil
+}
+
+// LogicalRouterUpdateLoadBalancers adds the load balancers to or removes them from the logical router
+func (c OvnClient) LogicalRouterUpdateLoadBalancers(lrName string, op ovsdb.Mutator, lbNames ...string) error {
+	lr, err := c.GetLogicalRouter(lrName, op == ovsdb.MutateOperationDelete)
+	if err != nil {
+		return err
+	}
+	if lr == nil {
+		return nil
+	}
+
+	lbUUIDs := make([]string, 0, len(lbNames))
+	for _, lbName := range lbNames {
+		lb, err := c.GetLoadBalancer(lbName, true)
+		if err != nil {
+			return err
+		}
+		if lb != nil {
+			lbUUIDs = append(lbUUIDs, lb.UUID)
+		}
+	}
+	if len(lbUUIDs) == 0 {
+		return nil
+	}
+
+	ops, err := c.ovnNbClient.Where(lr).Mutate(lr, model.Mutation{
+		Field:   &lr.LoadBalancer,
+		Mutator: op,
+		Value:   lbUUIDs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate mutate operations for logical router %s: %v", lrName, err)
+	}
+	if err = Transact(c.ovnNbClient, "lr-lb-update", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to update load balancers of logical router %s: %v", lrName, err)
+	}
+
+	return nil
+}
@@ -1,10 +1,14 @@
 package ovs
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
+	"github.com/ovn-org/libovsdb/client"
 	"github.com/ovn-org/libovsdb/model"
 	"github.com/ovn-org/libovsdb/ovsdb"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	ovsclient "github.com/kubeovn/kube-ovn/pkg/ovsdb/client"
 	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
@@ -71,3 +75,228 @@ func (c OvnClient) DeleteRouterPolicy(lr *ovnnb.LogicalRouter, uuid string) erro
 	}
 	return nil
 }
+
+// PolicyRoute is a logical router policy in the form used by the vpc policy routes
+type PolicyRoute struct {
+	Priority  int32
+	Match     string
+	Action    string
+	NextHopIP string
+}
+
+// GetPolicyRouteList returns the policies of the logical router
+func (c OvnClient) GetPolicyRouteList(router string) ([]*PolicyRoute, error) {
+	lr, err := c.GetLogicalRouter(router, false)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := c.listLogicalRouterPolicies(lr, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	routeList := make([]*PolicyRoute, 0, len(policies))
+	for _, policy := range policies {
+		routeList = append(routeList, &PolicyRoute{
+			Priority:  int32(policy.Priority),
+			Match:     policy.Match,
+			Action:    string(policy.Action),
+			NextHopIP: strings.Join(policy.Nexthops, ","),
+		})
+	}
+	return routeList, nil
+}
+
+// AddPolicyRoute adds a policy to the logical router, an existing policy with the same priority and match
+// is replaced when its action or nexthops differ, otherwise the external ids are merged into it
+func (c OvnClient) AddPolicyRoute(router string, priority int32, match, action, nextHop string, externalIDs map[string]string) error {
+	lr, err := c.GetLogicalRouter(router, false)
+	if err != nil {
+		return err
+	}
+	policies, err := c.listLogicalRouterPolicies(lr, func(policy *ovnnb.LogicalRouterPolicy) bool {
+		return policy.Priority == int(priority) && policy.Match == match
+	})
+	if err != nil {
+		return err
+	}
+
+	var nextHops []string
+	if nextHop != "" {
+		nextHops = strings.Split(nextHop, ",")
+	}
+	staleUUIDs := make([]string, 0, len(policies))
+	for i := range policies {
+		policy := &policies[i]
+		if string(policy.Action) != action || !sets.NewString(policy.Nexthops...).Equal(sets.NewString(nextHops...)) {
+			staleUUIDs = append(staleUUIDs, policy.UUID)
+			continue
+		}
+
+		if policy.ExternalIDs == nil {
+			policy.ExternalIDs = make(map[string]string, len(externalIDs))
+		}
+		changed := false
+		for k, v := range externalIDs {
+			if policy.ExternalIDs[k] != v {
+				policy.ExternalIDs[k] = v
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+		ops, err := c.ovnNbClient.Where(policy).Update(policy, &policy.ExternalIDs)
+		if err != nil {
+			return fmt.Errorf("failed to generate update operations for router policy %s: %v", match, err)
+		}
+		if err = Transact(c.ovnNbClient, "lr-policy-update", ops, c.ovnNbClient.Timeout); err != nil {
+			return fmt.Errorf("failed to update router policy %s: %v", match, err)
+		}
+		return nil
+	}
+
+	var ops []ovsdb.Operation
+	if len(staleUUIDs) != 0 {
+		if ops, err = c.deleteLogicalRouterPoliciesOps(lr, staleUUIDs); err != nil {
+			return err
+		}
+	}
+	lrPolicy := &ovnnb.LogicalRouterPolicy{
+		UUID:        ovsclient.NamedUUID(),
+		Priority:    int(priority),
+		Match:       match,
+		Action:      ovnnb.LogicalRouterPolicyAction(action),
+		Nexthops:    nextHops,
+		ExternalIDs: externalIDs,
+	}
+	createOps, err := c.ovnNbClient.Create(lrPolicy)
+	if err != nil {
+		return fmt.Errorf("failed to generate create operations for router policy %s: %v", match, err)
+	}
+	ops = append(ops, createOps...)
+	mutationOps, err := c.ovnNbClient.Where(lr).Mutate(lr, model.Mutation{
+		Field:   &lr.Policies,
+		Mutator: ovsdb.MutateOperationInsert,
+		Value:   []string{lrPolicy.UUID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate create operations for router policy %s: %v", match, err)
+	}
+	ops = append(ops, mutationOps...)
+
+	if err = Transact(c.ovnNbClient, "lr-policy-add", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to add router policy %s: %v", match, err)
+	}
+	return nil
+}
+
+// DeletePolicyRoute deletes the policies of the logical router with the priority and match,
+// all policies are deleted when priority is 0 and all policies with the priority when match is empty
+func (c OvnClient) DeletePolicyRoute(router string, priority int32, match string) error {
+	return c.deletePolicyRoutes(router, func(policy *ovnnb.LogicalRouterPolicy) bool {
+		if priority <= 0 {
+			return true
+		}
+		return policy.Priority == int(priority) && (match == "" || policy.Match == match)
+	})
+}
+
+// DeletePolicyRouteByNexthop deletes the policies of the logical router with the priority and the only nexthop
+func (c OvnClient) DeletePolicyRouteByNexthop(router string, priority int32, nexthop string) error {
+	return c.deletePolicyRoutes(router, func(policy *ovnnb.LogicalRouterPolicy) bool {
+		return policy.Priority == int(priority) && len(policy.Nexthops) == 1 && policy.Nexthops[0] == nexthop
+	})
+}
+
+// PolicyRouteExists checks whether the logical router has a policy with the priority and match
+func (c OvnClient) PolicyRouteExists(router string, priority int32, match string) (bool, error) {
+	policy, err := c.getPolicyRoute(router, priority, match)
+	return policy != nil, err
+}
+
+// GetPolicyRouteParas returns the nexthops and external ids of the logical router policy with the priority and match
+func (c OvnClient) GetPolicyRouteParas(router string, priority int32, match string) ([]string, map[string]string, error) {
+	policy, err := c.getPolicyRoute(router, priority, match)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the returned map is modified by the callers, so the one of the cached row is copied
+	nameIpMap := make(map[string]string)
+	if policy == nil {
+		return nil, nameIpMap, nil
+	}
+	for name, ip := range policy.ExternalIDs {
+		nameIpMap[name] = ip
+	}
+	return append([]string(nil), policy.Nexthops...), nameIpMap, nil
+}
+
+func (c OvnClient) getPolicyRoute(router string, priority int32, match string) (*ovnnb.LogicalRouterPolicy, error) {
+	lr, err := c.GetLogicalRouter(router, true)
+	if err != nil || lr == nil {
+		return nil, err
+	}
+	policies, err := c.listLogicalRouterPolicies(lr, func(policy *ovnnb.LogicalRouterPolicy) bool {
+		return policy.Priority == int(priority) && policy.Match == match
+	})
+	if err != nil || len(policies) == 0 {
+		return nil, err
+	}
+	return &policies[0], nil
+}
+
+func (c OvnClient) deletePolicyRoutes(router string, filter func(policy *ovnnb.LogicalRouterPolicy) bool) error {
+	lr, err := c.GetLogicalRouter(router, true)
+	if err != nil || lr == nil {
+		return err
+	}
+	policies, err := c.listLogicalRouterPolicies(lr, filter)
+	if err != nil || len(policies) == 0 {
+		return err
+	}
+
+	uuids := make([]string, 0, len(policies))
+	for _, policy := range policies {
+		uuids = append(uuids, policy.UUID)
+	}
+	ops, err := c.deleteLogicalRouterPoliciesOps(lr, uuids)
+	if err != nil {
+		return err
+	}
+	if err = Transact(c.ovnNbClient, "lr-policy-del", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to delete policies of logical router %s: %v", router, err)
+	}
+	return nil
+}
+
+func (c OvnClient) deleteLogicalRouterPoliciesOps(lr *ovnnb.LogicalRouter, uuids []string) ([]ovsdb.Operation, error) {
+	// policies are not root rows, they are garbage collected once removed from the router
+	ops, err := c.ovnNbClient.Where(lr).Mutate(lr, model.Mutation{
+		Field:   &lr.Policies,
+		Mutator: ovsdb.MutateOperationDelete,
+		Value:   uuids,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate delete operations for policies of logical router %s: %v", lr.Name, err)
+	}
+	return ops, nil
+}
+
+func (c OvnClient) listLogicalRouterPolicies(lr *ovnnb.LogicalRouter, filter func(policy *ovnnb.LogicalRouterPolicy) bool) ([]ovnnb.LogicalRouterPolicy, error) {
+	policyMap := make(map[string]struct{}, len(lr.Policies))
+	for _, uuid := range lr.Policies {
+		policyMap[uuid] = struct{}{}
+	}
+
+	var policies []ovnnb.LogicalRouterPolicy
+	if err := c.ovnNbClient.WhereCache(func(policy *ovnnb.LogicalRouterPolicy) bool {
+		if _, ok := policyMap[policy.UUID]; !ok {
+			return false
+		}
+		return filter == nil || filter(policy)
+	}).List(context.TODO(), &policies); err != nil && err != client.ErrNotFound {
+		return nil, fmt.Errorf("failed to list policies of logical router %s: %v", lr.Name, err)
+	}
+	return policies, nil
+}
@@ -26,6 +26,18 @@ func (c OvnClient) GetLogicalRouterPort(name string, ignoreNotFound bool) (*ovnn
 	return lrp, nil
 }
 
+// ListLogicalRouterPortsWithFilter returns the logical router ports matching the filter
+func (c OvnClient) ListLogicalRouterPortsWithFilter(filter func(lrp *ovnnb.LogicalRouterPort) bool) ([]ovnnb.LogicalRouterPort, error) {
+	var lrpList []ovnnb.LogicalRouterPort
+	if err := c.ovnNbClient.WhereCache(func(lrp *ovnnb.LogicalRouterPort) bool {
+		return filter == nil || filter(lrp)
+	}).List(context.TODO(), &lrpList); err != nil && err != client.ErrNotFound {
+		return nil, fmt.Errorf("failed to list logical router ports: %v", err)
+	}
+
+	return lrpList, nil
+}
+
 func (c OvnClient) AddLogicalRouterPort(lr, name, mac, networks string) error {
 	router, err := c.GetLogicalRouter(lr, false)
 	if err != nil {
@@ -78,3 +90,20 @@ func (c OvnClient) LogicalRouterPortExists(name string) (bool, error) {
 	lrp, err := c.GetLogicalRouterPort(name, true)
 	return lrp != nil, err
 }
+
+// SetLogicalRouterPortNetworks replaces the networks of the logical router port,
+// the port is matched by name so that one just created by ovn-nbctl is updated as well
+func (c OvnClient) SetLogicalRouterPortNetworks(name string, networks []string) error {
+	lrp := &ovnnb.LogicalRouterPort{Name: name, Networks: networks}
+	ops := []ovsdb.Operation{ConstructWaitForNameExistsOperation(name, "Logical_Router_Port")}
+	updateOps, err := c.ovnNbClient.Where(lrp).Update(lrp, &lrp.Networks)
+	if err != nil {
+		return fmt.Errorf("failed to generate update operations for logical router port %s: %v", name, err)
+	}
+	ops = append(ops, updateOps...)
+	if err = Transact(c.ovnNbClient, "lrp-set-networks", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to set networks of logical router port %s: %v", name, err)
+	}
+
+	return nil
+}
@@ -2,10 +2,16 @@ package ovs
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
 
+	ovsclient "github.com/kubeovn/kube-ovn/pkg/ovsdb/client"
 	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
 )
 
 func (c OvnClient) GetLogicalRouterRouteByOpts(key, value string) ([]ovnnb.LogicalRouterStaticRoute, error) {
@@ -33,3 +39,198 @@ func (c OvnClient) GetLogicalRouterPoliciesByExtID(key, value string) ([]ovnnb.L
 
 	return lrPolicyList, nil
 }
+
+// ListStaticRoutes returns the static routes of the logical router, routes learned from ovn-ic are skipped
+func (c OvnClient) ListStaticRoutes(lrName string) ([]*StaticRoute, error) {
+	lr, err := c.GetLogicalRouter(lrName, false)
+	if err != nil {
+		return nil, err
+	}
+	routes, err := c.listLogicalRouterStaticRoutes(lr, func(route *ovnnb.LogicalRouterStaticRoute) bool {
+		_, learned := route.ExternalIDs["ic-learned-route"]
+		return !learned
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	routeList := make([]*StaticRoute, 0, len(routes))
+	for _, route := range routes {
+		policy := PolicyDstIP
+		if route.Policy != nil {
+			policy = *route.Policy
+		}
+		routeList = append(routeList, &StaticRoute{Policy: policy, CIDR: route.IPPrefix, NextHop: route.Nexthop})
+	}
+	return routeList, nil
+}
+
+// ListLearnedStaticRoutes returns the static routes of the logical router learned from ovn-ic
+func (c OvnClient) ListLearnedStaticRoutes(lrName string) ([]*StaticRoute, error) {
+	lr, err := c.GetLogicalRouter(lrName, false)
+	if err != nil {
+		return nil, err
+	}
+	routes, err := c.listLogicalRouterStaticRoutes(lr, func(route *ovnnb.LogicalRouterStaticRoute) bool {
+		_, learned := route.ExternalIDs["ic-learned-route"]
+		return learned
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	routeList := make([]*StaticRoute, 0, len(routes))
+	for _, route := range routes {
+		policy := PolicyDstIP
+		if route.Policy != nil {
+			policy = *route.Policy
+		}
+		routeList = append(routeList, &StaticRoute{Policy: policy, CIDR: route.IPPrefix, NextHop: route.Nexthop})
+	}
+	return routeList, nil
+}
+
+func (c OvnClient) listLogicalRouterStaticRoutes(lr *ovnnb.LogicalRouter, filter func(route *ovnnb.LogicalRouterStaticRoute) bool) ([]ovnnb.LogicalRouterStaticRoute, error) {
+	routeMap := make(map[string]struct{}, len(lr.StaticRoutes))
+	for _, uuid := range lr.StaticRoutes {
+		routeMap[uuid] = struct{}{}
+	}
+
+	var routes []ovnnb.LogicalRouterStaticRoute
+	if err := c.ovnNbClient.WhereCache(func(route *ovnnb.LogicalRouterStaticRoute) bool {
+		if _, ok := routeMap[route.UUID]; !ok {
+			return false
+		}
+		return filter == nil || filter(route)
+	}).List(context.TODO(), &routes); err != nil && err != client.ErrNotFound {
+		return nil, fmt.Errorf("failed to list static routes of logical router %s: %v", lr.Name, err)
+	}
+	return routes, nil
+}
+
+// AddStaticRoute adds static routes of the cidrs to the nexthops in the same protocol,
+// a route to a different nexthop is rejected unless routeType is ecmp
+func (c OvnClient) AddStaticRoute(policy, cidr, nextHop, lrName string, routeType string) error {
+	if policy == "" {
+		policy = PolicyDstIP
+	}
+	lr, err := c.GetLogicalRouter(lrName, false)
+	if err != nil {
+		return err
+	}
+	existing, err := c.listLogicalRouterStaticRoutes(lr, func(route *ovnnb.LogicalRouterStaticRoute) bool {
+		return route.Policy == nil && policy == PolicyDstIP || route.Policy != nil && *route.Policy == policy
+	})
+	if err != nil {
+		return err
+	}
+
+	var ops []ovsdb.Operation
+	var uuids []string
+	for _, cidrBlock := range strings.Split(cidr, ",") {
+		for _, gw := range strings.Split(nextHop, ",") {
+			if util.CheckProtocol(cidrBlock) != util.CheckProtocol(gw) {
+				continue
+			}
+
+			var found bool
+			var stale *ovnnb.LogicalRouterStaticRoute
+			for i, route := range existing {
+				if route.IPPrefix != cidrBlock {
+					continue
+				}
+				if route.Nexthop == gw {
+					found = true
+					break
+				}
+				if routeType != util.EcmpRouteType {
+					if !strings.ContainsRune(cidrBlock, '/') {
+						return fmt.Errorf(`static route "policy=%s ip_prefix=%s" with different nexthop already exists on logical router %s`, policy, cidrBlock, lrName)
+					}
+					stale = &existing[i]
+				}
+			}
+			if found {
+				continue
+			}
+			if stale != nil {
+				// same as lr-route-add --may-exist, the nexthop of the existing route is updated
+				stale.Nexthop = gw
+				updateOps, err := c.ovnNbClient.Where(stale).Update(stale, &stale.Nexthop)
+				if err != nil {
+					return fmt.Errorf("failed to generate update operations for static route %s: %v", cidrBlock, err)
+				}
+				ops = append(ops, updateOps...)
+				continue
+			}
+
+			route := &ovnnb.LogicalRouterStaticRoute{
+				UUID:     ovsclient.NamedUUID(),
+				Policy:   &policy,
+				IPPrefix: cidrBlock,
+				Nexthop:  gw,
+			}
+			createOps, err := c.ovnNbClient.Create(route)
+			if err != nil {
+				return fmt.Errorf("failed to generate create operations for static route %s via %s: %v", cidrBlock, gw, err)
+			}
+			ops = append(ops, createOps...)
+			uuids = append(uuids, route.UUID)
+		}
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+
+	if len(uuids) != 0 {
+		mutateOps, err := c.ovnNbClient.Where(lr).Mutate(lr, model.Mutation{
+			Field:   &lr.StaticRoutes,
+			Mutator: ovsdb.MutateOperationInsert,
+			Value:   uuids,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to generate mutate operations for logical router %s: %v", lrName, err)
+		}
+		ops = append(ops, mutateOps...)
+	}
+	if err = Transact(c.ovnNbClient, "lr-route-add", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to add static route %s via %s to logical router %s: %v", cidr, nextHop, lrName, err)
+	}
+
+	return nil
+}
+
+// DeleteStaticRoute deletes the static routes of the cidr from the logical router
+func (c OvnClient) DeleteStaticRoute(cidr, lrName string) error {
+	if cidr == "" {
+		return nil
+	}
+	lr, err := c.GetLogicalRouter(lrName, true)
+	if err != nil || lr == nil {
+		return err
+	}
+	routes, err := c.listLogicalRouterStaticRoutes(lr, func(route *ovnnb.LogicalRouterStaticRoute) bool {
+		return route.IPPrefix == cidr
+	})
+	if err != nil || len(routes) == 0 {
+		return err
+	}
+
+	uuids := make([]string, 0, len(routes))
+	for _, route := range routes {
+		uuids = append(uuids, route.UUID)
+	}
+	ops, err := c.ovnNbClient.Where(lr).Mutate(lr, model.Mutation{
+		Field:   &lr.StaticRoutes,
+		Mutator: ovsdb.MutateOperationDelete,
+		Value:   uuids,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate mutate operations for logical router %s: %v", lrName, err)
+	}
+	if err = Transact(c.ovnNbClient, "lr-route-del", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to delete static route %s from logical router %s: %v", cidr, lrName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func (c OvnClient) GetLogicalSwitch(name string, ignoreNotFound bool) (*ovnnb.LogicalSwitch, error) {
+	predicate := func(model *ovnnb.LogicalSwitch) bool {
+		return model.Name == name
+	}
+	// Logical_Switch has no indexes defined in the schema
+	var result []*ovnnb.LogicalSwitch
+	if err := c.ovnNbClient.WhereCache(predicate).List(context.TODO(), &result); err != nil || len(result) == 0 {
+		if ignoreNotFound && (err == client.ErrNotFound || len(result) == 0) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get logical switch %s: %v", name, err)
+	}
+
+	return result[0], nil
+}
+
+func (c OvnClient) LogicalSwitchExists(name string) (bool, error) {
+	ls, err := c.GetLogicalSwitch(name, true)
+	return ls != nil, err
+}
+
+// ListLogicalSwitch returns the names of logical switches, only those created by kube-ovn if needVendorFilter is true
+func (c OvnClient) ListLogicalSwitch(needVendorFilter bool) ([]string, error) {
+	var lsList []ovnnb.LogicalSwitch
+	if err := c.ovnNbClient.WhereCache(func(ls *ovnnb.LogicalSwitch) bool {
+		return !needVendorFilter || ls.ExternalIDs["vendor"] == util.CniTypeName
+	}).List(context.TODO(), &lsList); err != nil && err != client.ErrNotFound {
+		return nil, fmt.Errorf("failed to list logical switches: %v", err)
+	}
+
+	names := make([]string, 0, len(lsList))
+	for _, ls := range lsList {
+		names = append(names, ls.Name)
+	}
+	return names, nil
+}
+
+// ListLogicalSwitchesWithFilter returns the logical switches matching the filter
+func (c OvnClient) ListLogicalSwitchesWithFilter(filter func(ls *ovnnb.LogicalSwitch) bool) ([]ovnnb.LogicalSwitch, error) {
+	var lsList []ovnnb.LogicalSwitch
+	if err := c.ovnNbClient.WhereCache(func(ls *ovnnb.LogicalSwitch) bool {
+		return filter == nil || filter(ls)
+	}).List(context.TODO(), &lsList); err != nil && err != client.ErrNotFound {
+		return nil, fmt.Errorf("failed to list logical switches: %v", err)
+	}
+
+	return lsList, nil
+}
+
+// LogicalSwitchUpdateLoadBalancers adds or removes the load balancers to or from the logical switch,
+// load balancers not found are skipped
+func (c OvnClient) LogicalSwitchUpdateLoadBalancers(lsName string, op ovsdb.Mutator, lbNames ...string) error {
+	ls, err := c.GetLogicalSwitch(lsName, op == ovsdb.MutateOperationDelete)
+	if err != nil {
+		return err
+	}
+	if ls == nil {
+		return nil
+	}
+
+	lbUUIDs := make([]string, 0, len(lbNames))
+	for _, lbName := range lbNames {
+		if lbName == "" {
+			continue
+		}
+		lb, err := c.GetLoadBalancer(lbName, true)
+		if err != nil {
+			return err
+		}
+		if lb != nil {
+			lbUUIDs = append(lbUUIDs, lb.UUID)
+		}
+	}
+	if len(lbUUIDs) == 0 {
+		return nil
+	}
+
+	ops, err := c.ovnNbClient.Where(ls).Mutate(ls, model.Mutation{
+		Field:   &ls.LoadBalancer,
+		Mutator: op,
+		Value:   lbUUIDs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate mutate operations for logical switch %s: %v", lsName, err)
+	}
+	if err = Transact(c.ovnNbClient, "ls-lb-update", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to update load balancers of logical switch %s: %v", lsName, err)
+	}
+
+	return nil
+}
@@ -3,10 +3,14 @@ package ovs
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
 	"k8s.io/klog/v2"
 
+	ovsclient "github.com/kubeovn/kube-ovn/pkg/ovsdb/client"
 	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
 	"github.com/kubeovn/kube-ovn/pkg/util"
 )
@@ -62,7 +66,237 @@ func (c OvnClient) ListLogicalSwitchPorts(needVendorFilter bool, externalIDs map
 	return lspList, nil
 }
 
+// ListLogicalSwitchPortsWithFilter returns the logical switch ports of any type matching the filter
+func (c OvnClient) ListLogicalSwitchPortsWithFilter(filter func(lsp *ovnnb.LogicalSwitchPort) bool) ([]ovnnb.LogicalSwitchPort, error) {
+	var lspList []ovnnb.LogicalSwitchPort
+	if err := c.ovnNbClient.WhereCache(func(lsp *ovnnb.LogicalSwitchPort) bool {
+		return filter == nil || filter(lsp)
+	}).List(context.TODO(), &lspList); err != nil && err != client.ErrNotFound {
+		return nil, fmt.Errorf("failed to list logical switch ports: %v", err)
+	}
+
+	return lspList, nil
+}
+
 func (c OvnClient) LogicalSwitchPortExists(name string) (bool, error) {
 	lsp, err := c.GetLogicalSwitchPort(name, true)
 	return lsp != nil, err
 }
+
+// CreatePortOps generates the operations creating or updating the logical switch port
+// the same way as LegacyClient.CreatePort does, the uuid of the port is returned so that
+// operations referring to it can be committed in the same transaction
+func (c OvnClient) CreatePortOps(ls, port, ip, mac, pod, namespace string, portSecurity bool, securityGroups string, vips string, liveMigration bool, enableDHCP bool, dhcpOptions *DHCPOptionsUUIDs, layer2Forward bool) (string, []ovsdb.Operation, error) {
+	lsp, err := c.GetLogicalSwitchPort(port, true)
+	if err != nil {
+		return "", nil, err
+	}
+	exists := lsp != nil
+	if !exists {
+		lsp = &ovnnb.LogicalSwitchPort{UUID: ovsclient.NamedUUID(), Name: port}
+	}
+	externalIDs := make(map[string]string, len(lsp.ExternalIDs)+4)
+	for k, v := range lsp.ExternalIDs {
+		externalIDs[k] = v
+	}
+	externalIDs["ls"] = ls
+	externalIDs["ip"] = strings.ReplaceAll(ip, ",", "/")
+	externalIDs["vendor"] = util.CniTypeName
+	if pod != "" && namespace != "" {
+		externalIDs["pod"] = fmt.Sprintf("%s/%s", namespace, pod)
+	}
+
+	addresses := append([]string{mac}, strings.Split(ip, ",")...)
+	lsp.Addresses = []string{strings.Join(addresses, " ")}
+	if liveMigration {
+		var ports []ovnnb.LogicalSwitchPort
+		if err = c.ovnNbClient.WhereCache(func(p *ovnnb.LogicalSwitchPort) bool {
+			return p.Name != port && p.ExternalIDs["ls"] == ls && p.ExternalIDs["ip"] == externalIDs["ip"]
+		}).List(context.TODO(), &ports); err != nil {
+			klog.Errorf("failed to list logical switch ports with address %s: %v", ip, err)
+			return "", nil, err
+		}
+		if len(ports) != 0 {
+			// only set mac, and set flag 'liveMigration'
+			lsp.Addresses = []string{mac}
+			externalIDs["liveMigration"] = "1"
+		}
+	}
+	if layer2Forward {
+		lsp.Addresses = []string{"unknown"}
+	}
+
+	fields := []interface{}{&lsp.Addresses, &lsp.ExternalIDs}
+	if portSecurity {
+		if vips != "" {
+			addresses = append(addresses, strings.Split(vips, ",")...)
+		}
+		lsp.PortSecurity = []string{strings.Join(addresses, " ")}
+		fields = append(fields, &lsp.PortSecurity)
+		if securityGroups != "" {
+			externalIDs["security_groups"] = strings.ReplaceAll(securityGroups, ",", "/")
+			for _, sg := range strings.Split(securityGroups, ",") {
+				externalIDs[fmt.Sprintf("associated_sg_%s", sg)] = "true"
+			}
+		}
+	}
+	if vips != "" {
+		externalIDs["vips"] = strings.ReplaceAll(vips, ",", "/")
+		externalIDs["attach-vips"] = "true"
+	}
+	if enableDHCP && dhcpOptions != nil {
+		if len(dhcpOptions.DHCPv4OptionsUUID) != 0 {
+			lsp.Dhcpv4Options = &dhcpOptions.DHCPv4OptionsUUID
+			fields = append(fields, &lsp.Dhcpv4Options)
+		}
+		if len(dhcpOptions.DHCPv6OptionsUUID) != 0 {
+			lsp.Dhcpv6Options = &dhcpOptions.DHCPv6OptionsUUID
+			fields = append(fields, &lsp.Dhcpv6Options)
+		}
+	}
+	lsp.ExternalIDs = externalIDs
+
+	if exists {
+		ops, err := c.ovnNbClient.Where(lsp).Update(lsp, fields...)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to generate update operations for logical switch port %s: %v", port, err)
+		}
+		return lsp.UUID, ops, nil
+	}
+
+	// the port not referenced by any switch is garbage collected by ovsdb,
+	// so the switch must exist when the operations are committed
+	if _, err = c.GetLogicalSwitch(ls, false); err != nil {
+		return "", nil, err
+	}
+	ops, err := c.ovnNbClient.Create(lsp)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate create operations for logical switch port %s: %v", port, err)
+	}
+	lsModel := &ovnnb.LogicalSwitch{Name: ls}
+	mutateOps, err := c.ovnNbClient.WhereAll(lsModel, model.Condition{
+		Field:    &lsModel.Name,
+		Function: ovsdb.ConditionEqual,
+		Value:    ls,
+	}).Mutate(lsModel, model.Mutation{
+		Field:   &lsModel.Ports,
+		Mutator: ovsdb.MutateOperationInsert,
+		Value:   []string{lsp.UUID},
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate operations adding port %s to logical switch %s: %v", port, ls, err)
+	}
+	ops = append([]ovsdb.Operation{ConstructWaitForNameExistsOperation(ls, "Logical_Switch")}, ops...)
+	return lsp.UUID, append(ops, mutateOps...), nil
+}
+
+// CreateRouterTypePort creates the logical switch port of type router peering with the logical router port,
+// it does nothing if the port exists
+func (c OvnClient) CreateRouterTypePort(ls, port, lrpName string) error {
+	exists, err := c.LogicalSwitchPortExists(port)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	lsp := &ovnnb.LogicalSwitchPort{
+		UUID:        ovsclient.NamedUUID(),
+		Name:        port,
+		Type:        "router",
+		Addresses:   []string{"router"},
+		Options:     map[string]string{"router-port": lrpName},
+		ExternalIDs: map[string]string{"vendor": util.CniTypeName},
+	}
+	ops, err := c.ovnNbClient.Create(lsp)
+	if err != nil {
+		return fmt.Errorf("failed to generate create operations for logical switch port %s: %v", port, err)
+	}
+	lsModel := &ovnnb.LogicalSwitch{Name: ls}
+	mutateOps, err := c.ovnNbClient.WhereAll(lsModel, model.Condition{
+		Field:    &lsModel.Name,
+		Function: ovsdb.ConditionEqual,
+		Value:    ls,
+	}).Mutate(lsModel, model.Mutation{
+		Field:   &lsModel.Ports,
+		Mutator: ovsdb.MutateOperationInsert,
+		Value:   []string{lsp.UUID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate operations adding port %s to logical switch %s: %v", port, ls, err)
+	}
+	if err = Transact(c.ovnNbClient, "lsp-add", append(ops, mutateOps...), c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to create logical switch port %s: %v", port, err)
+	}
+
+	return nil
+}
+
+// SetLogicalSwitchPortOptions merges the options into the ones of the logical switch port
+func (c OvnClient) SetLogicalSwitchPortOptions(name string, options map[string]string) error {
+	lsp, err := c.GetLogicalSwitchPort(name, false)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	if lsp.Options == nil {
+		lsp.Options = make(map[string]string, len(options))
+	}
+	for k, v := range options {
+		if lsp.Options[k] != v {
+			lsp.Options[k], changed = v, true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	ops, err := c.ovnNbClient.Where(lsp).Update(lsp, &lsp.Options)
+	if err != nil {
+		return fmt.Errorf("failed to generate update operations for logical switch port %s: %v", name, err)
+	}
+	if err = Transact(c.ovnNbClient, "lsp-set-options", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to set options of logical switch port %s: %v", name, err)
+	}
+
+	return nil
+}
+
+// DeleteLogicalSwitchPort removes the logical switch port from its switch, it does nothing if the port does not exist
+func (c OvnClient) DeleteLogicalSwitchPort(name string) error {
+	lsp, err := c.GetLogicalSwitchPort(name, true)
+	if err != nil || lsp == nil {
+		return err
+	}
+	lsList, err := c.ListLogicalSwitchesWithFilter(func(ls *ovnnb.LogicalSwitch) bool {
+		return util.ContainsString(ls.Ports, lsp.UUID)
+	})
+	if err != nil {
+		return err
+	}
+
+	// the port is deleted by ovsdb garbage collection once no switch refers to it
+	var ops []ovsdb.Operation
+	for i := range lsList {
+		ls := &lsList[i]
+		mutateOps, err := c.ovnNbClient.Where(ls).Mutate(ls, model.Mutation{
+			Field:   &ls.Ports,
+			Mutator: ovsdb.MutateOperationDelete,
+			Value:   []string{lsp.UUID},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to generate operations removing port %s from logical switch %s: %v", name, ls.Name, err)
+		}
+		ops = append(ops, mutateOps...)
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	if err = Transact(c.ovnNbClient, "lsp-del", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to delete logical switch port %s: %v", name, err)
+	}
+
+	return nil
+}
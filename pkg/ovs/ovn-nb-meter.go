@@ -0,0 +1,83 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	ovsclient "github.com/kubeovn/kube-ovn/pkg/ovsdb/client"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+)
+
+func (c OvnClient) GetMeter(name string, ignoreNotFound bool) (*ovnnb.Meter, error) {
+	meter := &ovnnb.Meter{Name: name}
+	if err := c.ovnNbClient.Get(context.TODO(), meter); err != nil {
+		if ignoreNotFound && err == client.ErrNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get meter %s: %v", name, err)
+	}
+
+	return meter, nil
+}
+
+// CreateOrUpdateMeter creates the fair meter dropping the packets over the rate, or updates the rate of the existing one,
+// each acl referring to a fair meter is rate limited separately
+func (c OvnClient) CreateOrUpdateMeter(name string, unit ovnnb.MeterUnit, rate int) error {
+	meter, err := c.GetMeter(name, true)
+	if err != nil {
+		return err
+	}
+
+	var ops []ovsdb.Operation
+	if meter != nil && len(meter.Bands) == 1 {
+		band := &ovnnb.MeterBand{UUID: meter.Bands[0]}
+		if err = c.ovnNbClient.Get(context.TODO(), band); err != nil {
+			return fmt.Errorf("failed to get band of meter %s: %v", name, err)
+		}
+		if meter.Unit == unit && band.Rate == rate {
+			return nil
+		}
+		band.Rate = rate
+		if ops, err = c.ovnNbClient.Where(band).Update(band, &band.Rate); err != nil {
+			return fmt.Errorf("failed to generate update operations for band of meter %s: %v", name, err)
+		}
+		meter.Unit = unit
+		updateOps, err := c.ovnNbClient.Where(meter).Update(meter, &meter.Unit)
+		if err != nil {
+			return fmt.Errorf("failed to generate update operations for meter %s: %v", name, err)
+		}
+		ops = append(ops, updateOps...)
+	} else {
+		if meter != nil {
+			if ops, err = c.ovnNbClient.Where(meter).Delete(); err != nil {
+				return fmt.Errorf("failed to generate delete operations for meter %s: %v", name, err)
+			}
+		}
+		band := &ovnnb.MeterBand{
+			UUID:   ovsclient.NamedUUID(),
+			Action: ovnnb.MeterBandActionDrop,
+			Rate:   rate,
+		}
+		fair := true
+		meter = &ovnnb.Meter{
+			UUID:  ovsclient.NamedUUID(),
+			Name:  name,
+			Unit:  unit,
+			Fair:  &fair,
+			Bands: []string{band.UUID},
+		}
+		createOps, err := c.ovnNbClient.Create(band, meter)
+		if err != nil {
+			return fmt.Errorf("failed to generate create operations for meter %s: %v", name, err)
+		}
+		ops = append(ops, createOps...)
+	}
+
+	if err = Transact(c.ovnNbClient, "meter-update", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to create or update meter %s: %v", name, err)
+	}
+	return nil
+}
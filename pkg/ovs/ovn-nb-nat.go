@@ -0,0 +1,185 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	ovsclient "github.com/kubeovn/kube-ovn/pkg/ovsdb/client"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+// ListNats returns the nat rules of the logical router, filtered by type and logical ip if specified
+func (c OvnClient) ListNats(lrName, natType, logicalIP string) ([]ovnnb.NAT, error) {
+	lr, err := c.GetLogicalRouter(lrName, true)
+	if err != nil || lr == nil {
+		return nil, err
+	}
+	natMap := make(map[string]struct{}, len(lr.Nat))
+	for _, uuid := range lr.Nat {
+		natMap[uuid] = struct{}{}
+	}
+
+	var natList []ovnnb.NAT
+	if err = c.ovnNbClient.WhereCache(func(nat *ovnnb.NAT) bool {
+		if _, ok := natMap[nat.UUID]; !ok {
+			return false
+		}
+		if natType != "" && nat.Type != natType {
+			return false
+		}
+		return logicalIP == "" || nat.LogicalIP == logicalIP
+	}).List(context.TODO(), &natList); err != nil && err != client.ErrNotFound {
+		return nil, fmt.Errorf("failed to list nat rules of logical router %s: %v", lrName, err)
+	}
+
+	return natList, nil
+}
+
+// NatRuleExists returns whether there is any nat rule of the logical ip
+func (c OvnClient) NatRuleExists(logicalIP string) (bool, error) {
+	var natList []ovnnb.NAT
+	if err := c.ovnNbClient.WhereCache(func(nat *ovnnb.NAT) bool {
+		return nat.LogicalIP == logicalIP
+	}).List(context.TODO(), &natList); err != nil && err != client.ErrNotFound {
+		return false, fmt.Errorf("failed to list nat rules of logical ip %s: %v", logicalIP, err)
+	}
+	return len(natList) != 0, nil
+}
+
+// AddNat adds a nat rule to the logical router, stateless dnat_and_snat rules are added
+// with the logical port and external mac for distributed gateway
+func (c OvnClient) AddNat(lrName, natType, externalIP, logicalIP, logicalMac, port string, stateless bool) error {
+	lr, err := c.GetLogicalRouter(lrName, false)
+	if err != nil {
+		return err
+	}
+
+	nat := &ovnnb.NAT{
+		UUID:       ovsclient.NamedUUID(),
+		Type:       natType,
+		ExternalIP: externalIP,
+		LogicalIP:  logicalIP,
+	}
+	if stateless {
+		nat.Options = map[string]string{"stateless": "true"}
+		if port != "" && logicalMac != "" {
+			nat.LogicalPort = &port
+			nat.ExternalMAC = &logicalMac
+		}
+	}
+
+	ops, err := c.ovnNbClient.Create(nat)
+	if err != nil {
+		return fmt.Errorf("failed to generate create operations for nat rule %s %s %s: %v", natType, externalIP, logicalIP, err)
+	}
+	mutateOps, err := c.ovnNbClient.Where(lr).Mutate(lr, model.Mutation{
+		Field:   &lr.Nat,
+		Mutator: ovsdb.MutateOperationInsert,
+		Value:   []string{nat.UUID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate mutate operations for logical router %s: %v", lrName, err)
+	}
+	ops = append(ops, mutateOps...)
+	if err = Transact(c.ovnNbClient, "lr-nat-add", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to add nat rule %s %s %s to logical router %s: %v", natType, externalIP, logicalIP, lrName, err)
+	}
+
+	return nil
+}
+
+// DeleteNats removes the nat rules from the logical router, nat rules are deleted
+// by OVSDB garbage collection once no logical router refers to them
+func (c OvnClient) DeleteNats(lrName string, nats ...ovnnb.NAT) error {
+	if len(nats) == 0 {
+		return nil
+	}
+	lr, err := c.GetLogicalRouter(lrName, true)
+	if err != nil || lr == nil {
+		return err
+	}
+
+	uuids := make([]string, 0, len(nats))
+	for _, nat := range nats {
+		uuids = append(uuids, nat.UUID)
+	}
+	ops, err := c.ovnNbClient.Where(lr).Mutate(lr, model.Mutation{
+		Field:   &lr.Nat,
+		Mutator: ovsdb.MutateOperationDelete,
+		Value:   uuids,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate mutate operations for logical router %s: %v", lrName, err)
+	}
+	if err = Transact(c.ovnNbClient, "lr-nat-del", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to delete nat rules from logical router %s: %v", lrName, err)
+	}
+
+	return nil
+}
+
+// DeleteNatRule deletes all nat rules of the logical ip from the logical router
+func (c OvnClient) DeleteNatRule(logicalIP, lrName string) error {
+	nats, err := c.ListNats(lrName, "", logicalIP)
+	if err != nil {
+		return err
+	}
+	return c.DeleteNats(lrName, nats...)
+}
+
+// UpdateSnat sets the external ip of the snat rule of the logical ip, the rule is deleted if externalIP is empty
+func (c OvnClient) UpdateSnat(lrName, externalIP, logicalIP string) error {
+	// dual stack pods have nat rules of the addresses in the same protocol only
+	if externalIP != "" && util.CheckProtocol(logicalIP) != util.CheckProtocol(externalIP) {
+		return nil
+	}
+
+	nats, err := c.ListNats(lrName, ovnnb.NATTypeSNAT, logicalIP)
+	if err != nil {
+		return err
+	}
+	if len(nats) == 1 && nats[0].ExternalIP == externalIP {
+		return nil
+	}
+	if err = c.DeleteNats(lrName, nats...); err != nil {
+		return err
+	}
+	if externalIP == "" {
+		return nil
+	}
+	return c.AddNat(lrName, ovnnb.NATTypeSNAT, externalIP, logicalIP, "", "", false)
+}
+
+// UpdateDnatAndSnat sets the external ip of the dnat_and_snat rule of the logical ip,
+// the rule is deleted if externalIP is empty
+func (c OvnClient) UpdateDnatAndSnat(lrName, externalIP, logicalIP, lspName, externalMac, gatewayType string) error {
+	if externalIP != "" && util.CheckProtocol(logicalIP) != util.CheckProtocol(externalIP) {
+		return nil
+	}
+
+	nats, err := c.ListNats(lrName, ovnnb.NATTypeDNATAndSNAT, logicalIP)
+	if err != nil {
+		return err
+	}
+	stale := make([]ovnnb.NAT, 0, len(nats))
+	var found bool
+	for _, nat := range nats {
+		if nat.ExternalIP == externalIP {
+			found = true
+			continue
+		}
+		stale = append(stale, nat)
+	}
+	if err = c.DeleteNats(lrName, stale...); err != nil {
+		return err
+	}
+	if externalIP == "" || found {
+		return nil
+	}
+	return c.AddNat(lrName, ovnnb.NATTypeDNATAndSNAT, externalIP, logicalIP, externalMac, lspName, gatewayType == "distributed")
+}
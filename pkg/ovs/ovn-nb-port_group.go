@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
 
 	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
 )
@@ -92,3 +94,91 @@ func (c OvnClient) PortGroupAddPort(pgName, portName string) error {
 func (c OvnClient) PortGroupRemovePort(pgName, portName string) error {
 	return c.portGroupPortOp(pgName, portName, false)
 }
+
+func (c OvnClient) portGroupPortsOps(pgName string, mutator ovsdb.Mutator, lspUUIDs []string) ([]ovsdb.Operation, error) {
+	pg := &ovnnb.PortGroup{Name: pgName}
+	ops, err := c.ovnNbClient.Where(pg).Mutate(pg, model.Mutation{
+		Field:   &pg.Ports,
+		Mutator: mutator,
+		Value:   lspUUIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate mutate operations for port group %s: %v", pgName, err)
+	}
+	return ops, nil
+}
+
+// PortGroupAddPortsOps generates the operations adding the ports to the port group,
+// named uuids of ports created in the same transaction are allowed
+func (c OvnClient) PortGroupAddPortsOps(pgName string, lspUUIDs ...string) ([]ovsdb.Operation, error) {
+	return c.portGroupPortsOps(pgName, ovsdb.MutateOperationInsert, lspUUIDs)
+}
+
+// PortGroupRemovePortsOps generates the operations removing the ports from the port group
+func (c OvnClient) PortGroupRemovePortsOps(pgName string, lspUUIDs ...string) ([]ovsdb.Operation, error) {
+	return c.portGroupPortsOps(pgName, ovsdb.MutateOperationDelete, lspUUIDs)
+}
+
+// PortGroupSetPorts replaces the ports of the port group with the logical switch ports, the ones not found are ignored
+func (c OvnClient) PortGroupSetPorts(pgName string, portNames []string) error {
+	pg, err := c.GetPortGroup(pgName, false)
+	if err != nil {
+		return err
+	}
+
+	pg.Ports = make([]string, 0, len(portNames))
+	for _, portName := range portNames {
+		lsp, err := c.GetLogicalSwitchPort(portName, true)
+		if err != nil {
+			return err
+		}
+		if lsp != nil {
+			pg.Ports = append(pg.Ports, lsp.UUID)
+		}
+	}
+
+	ops, err := c.ovnNbClient.Where(pg).Update(pg, &pg.Ports)
+	if err != nil {
+		return fmt.Errorf("failed to generate update operations for port group %s: %v", pgName, err)
+	}
+	if err = Transact(c.ovnNbClient, "pg-set-ports", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to set ports of port group %s: %v", pgName, err)
+	}
+
+	return nil
+}
+
+// ListPortGroups returns the port groups having all of the external ids, an empty value matches any value
+func (c OvnClient) ListPortGroups(externalIDs map[string]string) ([]ovnnb.PortGroup, error) {
+	var pgList []ovnnb.PortGroup
+	if err := c.ovnNbClient.WhereCache(func(pg *ovnnb.PortGroup) bool {
+		for k, v := range externalIDs {
+			if value, ok := pg.ExternalIDs[k]; !ok || (v != "" && value != v) {
+				return false
+			}
+		}
+		return true
+	}).List(context.TODO(), &pgList); err != nil && err != client.ErrNotFound {
+		return nil, fmt.Errorf("failed to list port groups with external ids %v: %v", externalIDs, err)
+	}
+
+	return pgList, nil
+}
+
+// DeletePortGroup deletes the port group, acls of it are deleted by OVSDB garbage collection
+func (c OvnClient) DeletePortGroup(name string) error {
+	pg, err := c.GetPortGroup(name, true)
+	if err != nil || pg == nil {
+		return err
+	}
+
+	ops, err := c.ovnNbClient.Where(pg).Delete()
+	if err != nil {
+		return fmt.Errorf("failed to generate delete operations for port group %s: %v", name, err)
+	}
+	if err = Transact(c.ovnNbClient, "pg-del", ops, c.ovnNbClient.Timeout); err != nil {
+		return fmt.Errorf("failed to delete port group %s: %v", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	ovsclient "github.com/kubeovn/kube-ovn/pkg/ovsdb/client"
+	"github.com/kubeovn/kube-ovn/pkg/ovsdb/ovnnb"
+)
+
+// CreateQoSOps generates the operations creating the qos rule and attaching it to the logical switch
+func (c OvnClient) CreateQoSOps(lsName string, qos *ovnnb.QoS) ([]ovsdb.Operation, error) {
+	qos.UUID = ovsclient.NamedUUID()
+	ops, err := c.ovnNbClient.Create(qos)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate create operations for qos %s: %v", qos.Match, err)
+	}
+
+	ls := &ovnnb.LogicalSwitch{Name: lsName}
+	mutateOps, err := c.ovnNbClient.WhereAll(ls, model.Condition{
+		Field:    &ls.Name,
+		Function: ovsdb.ConditionEqual,
+		Value:    lsName,
+	}).Mutate(ls, model.Mutation{
+		Field:   &ls.QOSRules,
+		Mutator: ovsdb.MutateOperationInsert,
+		Value:   []string{qos.UUID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate operations adding qos %s to logical switch %s: %v", qos.Match, lsName, err)
+	}
+	return append(ops, mutateOps...), nil
+}
+
+// ListQoSs returns the qos rules with the external ids, an external id with empty value matches any value
+func (c OvnClient) ListQoSs(externalIDs map[string]string) ([]ovnnb.QoS, error) {
+	var qosList []ovnnb.QoS
+	if err := c.ovnNbClient.WhereCache(func(qos *ovnnb.QoS) bool {
+		for k, v := range externalIDs {
+			if value, ok := qos.ExternalIDs[k]; !ok || (v != "" && value != v) {
+				return false
+			}
+		}
+		return true
+	}).List(context.TODO(), &qosList); err != nil && err != client.ErrNotFound {
+		return nil, fmt.Errorf("failed to list qos with external ids %v: %v", externalIDs, err)
+	}
+
+	return qosList, nil
+}
+
+// DeleteQoSOps generates the operations removing the qos rules from the logical switch and deleting them
+func (c OvnClient) DeleteQoSOps(lsName string, qosUUIDs ...string) ([]ovsdb.Operation, error) {
+	if len(qosUUIDs) == 0 {
+		return nil, nil
+	}
+
+	ls := &ovnnb.LogicalSwitch{Name: lsName}
+	ops, err := c.ovnNbClient.WhereAll(ls, model.Condition{
+		Field:    &ls.Name,
+		Function: ovsdb.ConditionEqual,
+		Value:    lsName,
+	}).Mutate(ls, model.Mutation{
+		Field:   &ls.QOSRules,
+		Mutator: ovsdb.MutateOperationDelete,
+		Value:   qosUUIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate operations removing qos from logical switch %s: %v", lsName, err)
+	}
+	for _, uuid := range qosUUIDs {
+		delOps, err := c.ovnNbClient.Where(&ovnnb.QoS{UUID: uuid}).Delete()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate delete operations for qos %s: %v", uuid, err)
+		}
+		ops = append(ops, delOps...)
+	}
+	return ops, nil
+}
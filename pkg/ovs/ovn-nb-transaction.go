@@ -0,0 +1,172 @@
+package ovs
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"k8s.io/klog/v2"
+)
+
+// NbTransaction accumulates operations on OVN NB, the operations are
+// committed in one transaction by Commit or by an NbBatcher
+type NbTransaction struct {
+	commit func(ops []ovsdb.Operation) error
+	ops    []ovsdb.Operation
+}
+
+// NewTransaction returns an empty transaction
+func (c OvnClient) NewTransaction() *NbTransaction {
+	return NewNbTransaction(func(ops []ovsdb.Operation) error {
+		return Transact(c.ovnNbClient, "transaction", ops, c.ovnNbClient.Timeout)
+	})
+}
+
+// NewNbTransaction returns an empty transaction committed by the commit function
+func NewNbTransaction(commit func(ops []ovsdb.Operation) error) *NbTransaction {
+	return &NbTransaction{commit: commit}
+}
+
+// Add appends operations to the transaction
+func (t *NbTransaction) Add(ops ...ovsdb.Operation) {
+	t.ops = append(t.ops, ops...)
+}
+
+// Len returns the count of operations in the transaction
+func (t *NbTransaction) Len() int {
+	return len(t.ops)
+}
+
+// Commit commits the operations in one transaction
+func (t *NbTransaction) Commit() error {
+	if len(t.ops) == 0 {
+		return nil
+	}
+	return t.commit(t.ops)
+}
+
+// ErrNbBatcherStopped is returned by NbBatcher.Commit once the batcher is stopped
+var ErrNbBatcherStopped = errors.New("nb batcher is stopped")
+
+type nbBatchRequest struct {
+	ops    []ovsdb.Operation
+	result chan error
+}
+
+// NbBatcher merges the transactions committed by concurrent workers in a short
+// interval into one, so that creating many pods costs only a few round trips to OVN NB
+type NbBatcher struct {
+	maxOps   int
+	interval time.Duration
+	requests chan *nbBatchRequest
+	done     chan struct{}
+	commit   func(ops []ovsdb.Operation) error
+}
+
+// NewBatcher returns a batcher committing at most maxOps operations at a time,
+// transactions are held no longer than interval waiting for others
+func (c OvnClient) NewBatcher(maxOps int, interval time.Duration) *NbBatcher {
+	return NewNbBatcher(maxOps, interval, func(ops []ovsdb.Operation) error {
+		return Transact(c.ovnNbClient, "batch", ops, c.ovnNbClient.Timeout)
+	})
+}
+
+// NewNbBatcher returns a batcher committing the merged transactions by the commit function
+func NewNbBatcher(maxOps int, interval time.Duration, commit func(ops []ovsdb.Operation) error) *NbBatcher {
+	return &NbBatcher{
+		maxOps:   maxOps,
+		interval: interval,
+		requests: make(chan *nbBatchRequest, maxOps),
+		done:     make(chan struct{}),
+		commit:   commit,
+	}
+}
+
+// Commit submits the transaction and waits until it is committed,
+// ErrNbBatcherStopped is returned if the batcher stops before committing it
+func (b *NbBatcher) Commit(t *NbTransaction) error {
+	if t.Len() == 0 {
+		return nil
+	}
+	req := &nbBatchRequest{ops: t.ops, result: make(chan error, 1)}
+	select {
+	case b.requests <- req:
+	case <-b.done:
+		return ErrNbBatcherStopped
+	}
+	select {
+	case err := <-req.result:
+		return err
+	case <-b.done:
+		// the last batch is flushed before done is closed
+		select {
+		case err := <-req.result:
+			return err
+		default:
+			return ErrNbBatcherStopped
+		}
+	}
+}
+
+// Run commits the submitted transactions until stopCh is closed,
+// it must be called only once
+func (b *NbBatcher) Run(stopCh <-chan struct{}) {
+	defer close(b.done)
+	for {
+		var req *nbBatchRequest
+		select {
+		case <-stopCh:
+			return
+		case req = <-b.requests:
+		}
+
+		batch := []*nbBatchRequest{req}
+		count := len(req.ops)
+		timer := time.NewTimer(b.interval)
+	collect:
+		for count < b.maxOps {
+			select {
+			case req = <-b.requests:
+				batch = append(batch, req)
+				count += len(req.ops)
+			case <-timer.C:
+				break collect
+			case <-stopCh:
+				break collect
+			}
+		}
+		timer.Stop()
+		b.flush(batch, count)
+	}
+}
+
+func (b *NbBatcher) flush(batch []*nbBatchRequest, count int) {
+	if len(batch) == 1 {
+		batch[0].result <- b.commit(batch[0].ops)
+		return
+	}
+
+	ops := make([]ovsdb.Operation, 0, count)
+	for _, req := range batch {
+		ops = append(ops, req.ops...)
+	}
+	err := b.commit(ops)
+	if err == nil {
+		klog.V(3).Infof("committed %d transactions with %d operations in one batch", len(batch), count)
+		for _, req := range batch {
+			req.result <- nil
+		}
+		return
+	}
+
+	// the whole batch is aborted if any operation fails,
+	// commit them one by one so that the failure is reported to its submitter only
+	klog.Warningf("failed to commit %d transactions in one batch, retry them one by one: %v", len(batch), err)
+	for _, req := range batch {
+		if err = b.commit(req.ops); err != nil {
+			err = fmt.Errorf("failed to commit transaction: %v", err)
+		}
+		req.result <- err
+	}
+}
@@ -0,0 +1,81 @@
+package ovs
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NbBatcher(t *testing.T) {
+	ast := assert.New(t)
+
+	var mutex sync.Mutex
+	var commits [][]ovsdb.Operation
+	batcher := NewNbBatcher(100, 100*time.Millisecond, func(ops []ovsdb.Operation) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		commits = append(commits, ops)
+		for _, op := range ops {
+			if op.Table == "bad" {
+				return errors.New("bad operation")
+			}
+		}
+		return nil
+	})
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go batcher.Run(stopCh)
+
+	tables := []string{"a", "b", "bad", "c"}
+	results := make([]error, len(tables))
+	var wg sync.WaitGroup
+	for i, table := range tables {
+		wg.Add(1)
+		go func(i int, table string) {
+			defer wg.Done()
+			tx := &NbTransaction{}
+			tx.Add(ovsdb.Operation{Op: ovsdb.OperationInsert, Table: table})
+			results[i] = batcher.Commit(tx)
+		}(i, table)
+	}
+	wg.Wait()
+
+	ast.Nil(results[0])
+	ast.Nil(results[1])
+	ast.NotNil(results[2])
+	ast.Nil(results[3])
+	// the failed batch is followed by one commit per transaction
+	ast.Equal(5, len(commits))
+	ast.Equal(4, len(commits[0]))
+
+	ast.Nil(batcher.Commit(&NbTransaction{}))
+}
+
+func Test_NbBatcherStopped(t *testing.T) {
+	ast := assert.New(t)
+
+	batcher := NewNbBatcher(1, 100*time.Millisecond, func(ops []ovsdb.Operation) error {
+		return nil
+	})
+	stopCh := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		batcher.Run(stopCh)
+		close(stopped)
+	}()
+
+	tx := &NbTransaction{}
+	tx.Add(ovsdb.Operation{Op: ovsdb.OperationInsert, Table: "a"})
+	ast.Nil(batcher.Commit(tx))
+
+	close(stopCh)
+	<-stopped
+	// the requests more than the buffer would block forever without the done channel
+	for i := 0; i < 3; i++ {
+		ast.Equal(ErrNbBatcherStopped, batcher.Commit(tx))
+	}
+}
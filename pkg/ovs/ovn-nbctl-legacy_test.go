@@ -1,6 +1,7 @@
 package ovs
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -30,14 +31,18 @@ func Test_parseLrRouteListOutput(t *testing.T) {
 	ast.Equal(6, len(routeList))
 }
 
-func Test_parseLrPolicyRouteListOutput(t *testing.T) {
+func Test_AclLogName(t *testing.T) {
 	ast := assert.New(t)
-	output := `        
-		10                              ip4.src == 1.1.0.0/24         reroute                198.19.0.4
-        10     ip4.src == 1.1.0.0/24 || ip4.src == 1.1.4.0/24         reroute                198.19.0.4
-        10 ip4.src == 1.1.0.0/24 || ip4.src == 1.1.4.0/24 || Iip4.src ==1.1.5.0/24         reroute                198.19.0.4
-        10                              ip4.src == 1.1.1.0/24            drop`
-	routeList, err := parseLrPolicyRouteListOutput(output)
-	ast.Nil(err)
-	ast.Equal(6, len(routeList))
+	name := GetAclLogName("np", "default/web")
+	ownerType, id := ParseAclLogName(name)
+	ast.Equal("np", ownerType)
+	ast.Len(id, 16)
+
+	// long owners sharing a prefix get distinct names within the 63 characters allowed by ovn
+	long1, long2 := GetAclLogName("sg", strings.Repeat("a", 100)+"1"), GetAclLogName("sg", strings.Repeat("a", 100)+"2")
+	ast.NotEqual(long1, long2)
+	ast.LessOrEqual(len(long1), 63)
+	ownerType, id = ParseAclLogName("<unnamed>")
+	ast.Empty(ownerType)
+	ast.Equal("<unnamed>", id)
 }
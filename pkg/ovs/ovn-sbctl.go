@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
 
@@ -132,3 +133,49 @@ func (c LegacyClient) GetAllChassis() ([]string, error) {
 	}
 	return result, nil
 }
+
+// ServiceMonitor is the health status of a load balancer backend checked by ovn-controller
+type ServiceMonitor struct {
+	IP          string
+	Port        int32
+	Protocol    string
+	LogicalPort string
+	Status      string
+}
+
+// ListServiceMonitors returns the service monitors created by ovn-northd for the load balancer health checks
+func (c LegacyClient) ListServiceMonitors() ([]ServiceMonitor, error) {
+	output, err := c.ovnSbCommand("--format=csv", "--no-heading", "--data=bare", "--columns=ip,port,protocol,logical_port,status", "list", "service_monitor")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service monitors, %v", err)
+	}
+	return parseServiceMonitorOutput(output), nil
+}
+
+func parseServiceMonitorOutput(output string) []ServiceMonitor {
+	lines := strings.Split(output, "\n")
+	monitors := make([]ServiceMonitor, 0, len(lines))
+	for _, l := range lines {
+		fields := strings.Split(strings.TrimSpace(l), ",")
+		if len(fields) != 5 {
+			continue
+		}
+		port, err := strconv.ParseInt(fields[1], 10, 32)
+		if err != nil {
+			continue
+		}
+		monitor := ServiceMonitor{
+			IP:          fields[0],
+			Port:        int32(port),
+			Protocol:    fields[2],
+			LogicalPort: fields[3],
+			Status:      fields[4],
+		}
+		// the protocol of the service monitor defaults to tcp
+		if monitor.Protocol == "" {
+			monitor.Protocol = "tcp"
+		}
+		monitors = append(monitors, monitor)
+	}
+	return monitors
+}
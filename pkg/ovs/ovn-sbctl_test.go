@@ -0,0 +1,20 @@
+package ovs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseServiceMonitorOutput(t *testing.T) {
+	output := `10.16.0.2,80,tcp,web-0.default,online
+fd00:10:16::2,53,udp,dns-0.default,offline
+10.16.0.3,8080,,web-1.default,
+invalid`
+	require.Equal(t, []ServiceMonitor{
+		{IP: "10.16.0.2", Port: 80, Protocol: "tcp", LogicalPort: "web-0.default", Status: "online"},
+		{IP: "fd00:10:16::2", Port: 53, Protocol: "udp", LogicalPort: "dns-0.default", Status: "offline"},
+		{IP: "10.16.0.3", Port: 8080, Protocol: "tcp", LogicalPort: "web-1.default"},
+	}, parseServiceMonitorOutput(output))
+	require.Empty(t, parseServiceMonitorOutput(""))
+}
@@ -31,7 +31,6 @@ type LegacyClient struct {
 	ClusterUdpSessionLoadBalancer string
 	NodeSwitch                    string
 	NodeSwitchCIDR                string
-	ExternalGatewayType           string
 	Version                       string
 }
 
@@ -75,6 +74,16 @@ func NewLegacyClient(ovnNbAddr string, ovnNbTimeout int, ovnSbAddr, clusterRoute
 	}
 }
 
+// SetOvnICNbAddress sets the address of the OVN-IC NB database used by ovn-ic-nbctl
+func (c *LegacyClient) SetOvnICNbAddress(addr string) {
+	c.OvnICNbAddress = addr
+}
+
+// SetOvnICSbAddress sets the address of the OVN-IC SB database used by ovn-ic-sbctl
+func (c *LegacyClient) SetOvnICSbAddress(addr string) {
+	c.OvnICSbAddress = addr
+}
+
 // TODO: support sb/ic-nb client
 func NewOvnClient(ovnNbAddr string, ovnNbTimeout int) (*OvnClient, error) {
 	nbClient, err := ovsclient.NewNbClient(ovnNbAddr, ovnNbTimeout)
@@ -87,6 +96,13 @@ func NewOvnClient(ovnNbAddr string, ovnNbTimeout int) (*OvnClient, error) {
 }
 
 func Transact(c client.Client, method string, operations []ovsdb.Operation, timeout int) error {
+	_, err := TransactWithResults(c, method, operations, timeout)
+	return err
+}
+
+// TransactWithResults is the same as Transact and returns the results of the operations,
+// the uuids of the inserted rows are in the results of the insert operations
+func TransactWithResults(c client.Client, method string, operations []ovsdb.Operation, timeout int) ([]ovsdb.OperationResult, error) {
 	ctx, cancel := context.WithTimeout(context.TODO(), time.Duration(timeout)*time.Second)
 	defer cancel()
 
@@ -108,7 +124,7 @@ func Transact(c client.Client, method string, operations []ovsdb.Operation, time
 	if err != nil {
 		code = "1"
 		klog.Errorf("error occurred in transact with %s operations: %+v in %vms", dbType, operations, elapsed)
-		return err
+		return nil, err
 	}
 
 	if elapsed > 500 {
@@ -118,16 +134,30 @@ func Transact(c client.Client, method string, operations []ovsdb.Operation, time
 	errors, err := ovsdb.CheckOperationResults(results, operations)
 	if err != nil {
 		klog.Errorf("error occurred in transact with operations %+v with operation errors %+v: %v", operations, errors, err)
-		return err
+		return nil, err
 	}
 
-	return nil
+	return results, nil
 }
 
 func ConstructWaitForNameNotExistsOperation(name string, table string) ovsdb.Operation {
 	return ConstructWaitForUniqueOperation(table, "name", name)
 }
 
+// ConstructWaitForNameExistsOperation returns an operation failing the transaction if the row named name does not exist
+func ConstructWaitForNameExistsOperation(name string, table string) ovsdb.Operation {
+	timeout := OVSDBWaitTimeout
+	return ovsdb.Operation{
+		Op:      ovsdb.OperationWait,
+		Table:   table,
+		Timeout: &timeout,
+		Where:   []ovsdb.Condition{{Column: "name", Function: ovsdb.ConditionEqual, Value: name}},
+		Columns: []string{"name"},
+		Until:   "==",
+		Rows:    []ovsdb.Row{{"name": name}},
+	}
+}
+
 func ConstructWaitForUniqueOperation(table string, column string, value interface{}) ovsdb.Operation {
 	timeout := OVSDBWaitTimeout
 	return ovsdb.Operation{
@@ -123,6 +123,12 @@ func BridgeExists(name string) (bool, error) {
 	return util.ContainsString(bridges, name), nil
 }
 
+// GetInterfaceExternalID returns the value of external_ids:key of the interface,
+// an empty string is returned if the interface or the key does not exist
+func GetInterfaceExternalID(iface, key string) (string, error) {
+	return Exec(IfExists, "get", "interface", iface, "external_ids:"+key)
+}
+
 // PortExists checks whether the port already exists
 func PortExists(name string) (bool, error) {
 	result, err := ovsFind("port", "_uuid", "name="+name)
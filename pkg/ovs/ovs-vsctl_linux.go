@@ -13,8 +13,18 @@ import (
 // SetInterfaceBandwidth set ingress/egress qos for given pod, annotation values are for node/pod
 // but ingress/egress parameters here are from the point of ovs port/interface view, so reverse input parameters when call func SetInterfaceBandwidth
 func SetInterfaceBandwidth(podName, podNamespace, iface, ingress, egress, podPriority string) error {
+	return SetInterfaceBandwidthWithBurst(podName, podNamespace, iface, ingress, egress, "", podPriority)
+}
+
+// SetInterfaceBandwidthWithBurst is the same as SetInterfaceBandwidth except that the burst in Mbit
+// of the ingress policing is specified, the default burst is 80% of the ingress rate
+func SetInterfaceBandwidthWithBurst(podName, podNamespace, iface, ingress, egress, burst, podPriority string) error {
 	ingressMPS, _ := strconv.Atoi(ingress)
 	ingressKPS := ingressMPS * 1000
+	burstKb := ingressKPS * 8 / 10
+	if burstMb, _ := strconv.Atoi(burst); burstMb > 0 && ingressKPS > 0 {
+		burstKb = burstMb * 1000
+	}
 	interfaceList, err := ovsFind("interface", "name", fmt.Sprintf("external-ids:iface-id=%s", iface))
 	if err != nil {
 		return err
@@ -32,7 +42,7 @@ func SetInterfaceBandwidth(podName, podNamespace, iface, ingress, egress, podPri
 
 	for _, ifName := range interfaceList {
 		// ingress_policing_rate is in Kbps
-		err := ovsSet("interface", ifName, fmt.Sprintf("ingress_policing_rate=%d", ingressKPS), fmt.Sprintf("ingress_policing_burst=%d", ingressKPS*8/10))
+		err := ovsSet("interface", ifName, fmt.Sprintf("ingress_policing_rate=%d", ingressKPS), fmt.Sprintf("ingress_policing_burst=%d", burstKb))
 		if err != nil {
 			return err
 		}
@@ -7,6 +7,11 @@ func SetInterfaceBandwidth(podName, podNamespace, iface, ingress, egress, podPri
 	return nil
 }
 
+func SetInterfaceBandwidthWithBurst(podName, podNamespace, iface, ingress, egress, burst, podPriority string) error {
+	// TODO
+	return nil
+}
+
 func ClearHtbQosQueue(podName, podNamespace, iface string) error {
 	//TODO
 	return nil
@@ -99,6 +99,17 @@ func NewNbClient(addr string, timeout int) (client.Client, error) {
 		client.WithTable(&ovnnb.PortGroup{}),
 		client.WithTable(&ovnnb.LogicalRouterStaticRoute{}),
 		client.WithTable(&ovnnb.LogicalRouterPolicy{}),
+		client.WithTable(&ovnnb.LogicalSwitch{}),
+		client.WithTable(&ovnnb.LoadBalancer{}),
+		client.WithTable(&ovnnb.LoadBalancerHealthCheck{}),
+		client.WithTable(&ovnnb.ACL{}),
+		client.WithTable(&ovnnb.AddressSet{}),
+		client.WithTable(&ovnnb.DHCPOptions{}),
+		client.WithTable(&ovnnb.NAT{}),
+		client.WithTable(&ovnnb.GatewayChassis{}),
+		client.WithTable(&ovnnb.QoS{}),
+		client.WithTable(&ovnnb.Meter{}),
+		client.WithTable(&ovnnb.MeterBand{}),
 	}
 	if _, err = c.Monitor(context.TODO(), c.NewMonitor(monitorOpts...)); err != nil {
 		klog.Errorf("failed to monitor database on OVN NB server %s: %v", addr, err)
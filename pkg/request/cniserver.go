@@ -36,6 +36,9 @@ type CniRequest struct {
 	// empty dir volume for sharing vhost user unix socket
 	VhostUserSocketVolumeName string `json:"vhost_user_socket_volume_name"`
 	VhostUserSocketName       string `json:"vhost_user_socket_name"`
+	// the addresses in the result of ADD, verified by CHECK
+	PrevIPs []string `json:"prev_ips,omitempty"`
+	PrevMac string   `json:"prev_mac,omitempty"`
 }
 
 // CniResponse is the cniserver response format
@@ -75,3 +78,27 @@ func (csc CniServerClient) Del(podRequest CniRequest) error {
 	}
 	return nil
 }
+
+// Check pod request
+func (csc CniServerClient) Check(podRequest CniRequest) error {
+	res, body, errors := csc.Post("http://dummy/api/v1/check").Send(podRequest).End()
+	if len(errors) != 0 {
+		return errors[0]
+	}
+	if res.StatusCode != 204 {
+		return fmt.Errorf("check pod return %d %s", res.StatusCode, body)
+	}
+	return nil
+}
+
+// Status of node request
+func (csc CniServerClient) Status() error {
+	res, body, errors := csc.Post("http://dummy/api/v1/status").End()
+	if len(errors) != 0 {
+		return errors[0]
+	}
+	if res.StatusCode != 204 {
+		return fmt.Errorf("check node status return %d %s", res.StatusCode, body)
+	}
+	return nil
+}
@@ -17,6 +17,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/klog/v2"
 
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
 	"github.com/kubeovn/kube-ovn/pkg/util"
 )
 
@@ -41,6 +42,20 @@ func isClusterIPService(svc *v1.Service) bool {
 		len(svc.Spec.ClusterIP) != 0
 }
 
+// getLoadBalancerRoutes returns the routes of the ipv4 ingress ips allocated to the loadbalancer service by kube-ovn
+func getLoadBalancerRoutes(svc *v1.Service) []string {
+	if svc.Spec.Type != v1.ServiceTypeLoadBalancer || svc.Annotations[util.LbIpamIPAnnotation] == "" {
+		return nil
+	}
+	var routes []string
+	for _, ip := range strings.Split(svc.Annotations[util.LbIpamIPAnnotation], ",") {
+		if util.CheckProtocol(ip) == kubeovnv1.ProtocolIPv4 {
+			routes = append(routes, fmt.Sprintf("%s/32", ip))
+		}
+	}
+	return routes
+}
+
 // TODO: ipv4 only, need ipv6/dual-stack support later
 func (c *Controller) syncSubnetRoutes() {
 	bgpExpected, bgpExists := []string{}, []string{}
@@ -55,22 +70,25 @@ func (c *Controller) syncSubnetRoutes() {
 		return
 	}
 
-	if c.config.AnnounceClusterIP {
-		services, err := c.servicesLister.List(labels.Everything())
-		if err != nil {
-			klog.Errorf("failed to list services, %v", err)
-			return
+	services, err := c.servicesLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list services, %v", err)
+		return
+	}
+	for _, svc := range services {
+		if svc.Annotations == nil || svc.Annotations[util.BgpAnnotation] != "true" {
+			continue
 		}
-		for _, svc := range services {
-			if svc.Annotations != nil && svc.Annotations[util.BgpAnnotation] == "true" && isClusterIPService(svc) {
-				bgpExpected = append(bgpExpected, fmt.Sprintf("%s/32", svc.Spec.ClusterIP))
-			}
+		if c.config.AnnounceClusterIP && isClusterIPService(svc) {
+			bgpExpected = append(bgpExpected, fmt.Sprintf("%s/32", svc.Spec.ClusterIP))
 		}
+		bgpExpected = append(bgpExpected, getLoadBalancerRoutes(svc)...)
 	}
 
 	for _, subnet := range subnets {
 		if subnet.Status.IsReady() && subnet.Annotations != nil && subnet.Annotations[util.BgpAnnotation] == "true" {
 			bgpExpected = append(bgpExpected, subnet.Spec.CIDRBlock)
+			bgpExpected = append(bgpExpected, subnet.Spec.SecondaryCIDRBlocks...)
 		}
 	}
 
@@ -24,6 +24,7 @@ const (
 
 	VpcNatGatewayAnnotation     = "ovn.kubernetes.io/vpc_nat_gw"
 	VpcNatGatewayInitAnnotation = "ovn.kubernetes.io/vpc_nat_gw_init"
+	VpcNatGatewayRoleAnnotation = "ovn.kubernetes.io/vpc_nat_gw_role"
 	VpcEipsAnnotation           = "ovn.kubernetes.io/vpc_eips"
 	VpcFloatingIpMd5Annotation  = "ovn.kubernetes.io/vpc_floating_ips"
 	VpcDnatMd5Annotation        = "ovn.kubernetes.io/vpc_dnat_md5"
@@ -35,7 +36,13 @@ const (
 	VpcDnatEPortLabel           = "ovn.kubernetes.io/vpc_dnat_eport"
 	VpcNatLabel                 = "ovn.kubernetes.io/vpc_nat"
 
-	SwitchLBRuleVipsAnnotation = "ovn.kubernetes.io/switch_lb_vip"
+	SwitchLBRuleVipsAnnotation   = "ovn.kubernetes.io/switch_lb_vip"
+	ServiceHealthCheckAnnotation = "ovn.kubernetes.io/health_check"
+	ServiceLbHashAnnotation      = "ovn.kubernetes.io/lb_hash"
+
+	LbIpamSubnetAnnotation = "ovn.kubernetes.io/lb_ipam_subnet"
+	LbIpamIPPoolAnnotation = "ovn.kubernetes.io/lb_ipam_ippool"
+	LbIpamIPAnnotation     = "ovn.kubernetes.io/lb_ipam_ip"
 
 	LogicalRouterAnnotation = "ovn.kubernetes.io/logical_router"
 	VpcAnnotation           = "ovn.kubernetes.io/vpc"
@@ -59,6 +66,8 @@ const (
 	VlanIdAnnotationTemplate        = "%s.kubernetes.io/vlan_id"
 	IngressRateAnnotationTemplate   = "%s.kubernetes.io/ingress_rate"
 	EgressRateAnnotationTemplate    = "%s.kubernetes.io/egress_rate"
+	QoSPolicyAnnotationTemplate     = "%s.kubernetes.io/qos_policy"
+	DscpAnnotationTemplate          = "%s.kubernetes.io/dscp"
 	SecurityGroupAnnotationTemplate = "%s.kubernetes.io/security_groups"
 	LiveMigrationAnnotationTemplate = "%s.kubernetes.io/allow_live_migration"
 	DefaultRouteAnnotationTemplate  = "%s.kubernetes.io/default_route"
@@ -76,6 +85,14 @@ const (
 
 	IngressRateAnnotation = "ovn.kubernetes.io/ingress_rate"
 	EgressRateAnnotation  = "ovn.kubernetes.io/egress_rate"
+	QoSPolicyAnnotation   = "ovn.kubernetes.io/qos_policy"
+	DscpAnnotation        = "ovn.kubernetes.io/dscp"
+
+	// bandwidth limits are enforced by ovs qos of the local interfaces or by qos rules of the logical switches
+	QoSModeHost = "host"
+	QoSModeOvn  = "ovn"
+	// the qos mode of kube-ovn-controller recorded on nodes, which the daemons must run with
+	QoSModeAnnotation = "ovn.kubernetes.io/qos_mode"
 
 	PortNameAnnotation      = "ovn.kubernetes.io/port_name"
 	LogicalSwitchAnnotation = "ovn.kubernetes.io/logical_switch"
@@ -94,6 +111,10 @@ const (
 	VpcLbLabel                 = "ovn.kubernetes.io/vpc_lb"
 	VpcDnsNameLabel            = "ovn.kubernetes.io/vpc-dns"
 	NetworkPolicyLogAnnotation = "ovn.kubernetes.io/enable_log"
+	// comma separated domain names the pods selected by the network policy are allowed to access
+	NetworkPolicyEgressFqdnsAnnotation = "ovn.kubernetes.io/egress_fqdns"
+	// the packets which would be dropped by the network policy are allowed and logged in audit mode
+	NetworkPolicyAuditAnnotation = "ovn.kubernetes.io/audit_mode"
 
 	ProtocolTCP  = "tcp"
 	ProtocolUDP  = "udp"
@@ -136,6 +157,7 @@ const (
 	InterconnectionSwitch  = "ts"
 	ExternalGatewaySwitch  = "ovn-external"
 	VpcNatGatewayConfig    = "ovn-vpc-nat-gw-config"
+	IPAMCheckpointConfig   = "ovn-ipam-checkpoint"
 	VpcExternalNet         = "ovn-vpc-external-network"
 	VpcLbNetworkAttachment = "ovn-vpc-lb"
 	VpcDnsConfig           = "vpc-dns-config"
@@ -155,6 +177,23 @@ const (
 	SubnetRouterPolicyPriority  = 31000
 	OvnICPolicyPriority         = 29500
 
+	// acls of admin network policies take priorities from AnpACLMaxPriority down,
+	// acls of baseline admin network policy take priorities between network policies and subnets
+	AnpACLMaxPriority  = 30000
+	BanpACLMaxPriority = 1900
+
+	// acls of network policies and security groups are named after the owner to trace the acl logs back to it,
+	// and the logs are rate limited by the meter
+	AclLogMeterName   = "kube-ovn-acl-log"
+	AclLogOwnerTypeNp = "np"
+	AclLogOwnerTypeSg = "sg"
+	// default drop acls of network policies in audit mode
+	AclLogOwnerTypeNpAudit = "np-audit"
+	AclLogVerdictAudit     = "audit"
+	AclLogOwnerTypeKey     = "policy-type"
+	AclLogOwnerKey         = "policy"
+	AclLogDirectionKey     = "direction"
+
 	OffloadType  = "offload-port"
 	InternalType = "internal-port"
 	DpdkType     = "dpdk-port"
@@ -188,6 +227,10 @@ const (
 	ContentType        = "application/vnd.kubernetes.protobuf"
 	AcceptContentTypes = "application/vnd.kubernetes.protobuf,application/json"
 
+	// the fields hashed by the load balancers to select the backends of services
+	LbHashClientIP = "ClientIP"
+	LbHash5Tuple   = "5-tuple"
+
 	AttachmentProvider = "ovn.kubernetes.io/attachmentprovider"
 	LbSvcPodImg        = "ovn.kubernetes.io/lb_svc_img"
 
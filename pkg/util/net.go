@@ -290,6 +290,87 @@ func GetIpAddrWithMask(ip, cidr string) string {
 	return ipAddr
 }
 
+// SubnetCIDRBlocks returns the cidr blocks of the subnet, CIDRBlock followed by the secondary ones
+func SubnetCIDRBlocks(subnet *kubeovnv1.Subnet) []string {
+	return append(strings.Split(subnet.Spec.CIDRBlock, ","), subnet.Spec.SecondaryCIDRBlocks...)
+}
+
+// SubnetContainIP returns whether every ip in ipStr is in one of the cidr blocks of the subnet
+func SubnetContainIP(subnet *kubeovnv1.Subnet, ipStr string) bool {
+	cidrBlocks := SubnetCIDRBlocks(subnet)
+	for _, ip := range strings.Split(ipStr, ",") {
+		contained := false
+		for _, cidrBlock := range cidrBlocks {
+			if CIDRContainIP(cidrBlock, ip) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			return false
+		}
+	}
+	return true
+}
+
+// SubnetSecondaryGateways returns the gateways of the secondary cidr blocks, the first address of each block
+func SubnetSecondaryGateways(subnet *kubeovnv1.Subnet) []string {
+	gws := make([]string, 0, len(subnet.Spec.SecondaryCIDRBlocks))
+	for _, cidrBlock := range subnet.Spec.SecondaryCIDRBlocks {
+		if gw, err := FirstIP(cidrBlock); err == nil {
+			gws = append(gws, gw)
+		}
+	}
+	return gws
+}
+
+// GetSubnetRouterNetworks returns the networks of the router port connecting the subnet,
+// the gateways of all the cidr blocks with their masks
+func GetSubnetRouterNetworks(subnet *kubeovnv1.Subnet) []string {
+	networks := strings.Split(GetIpAddrWithMask(subnet.Spec.Gateway, subnet.Spec.CIDRBlock), ",")
+	for _, cidrBlock := range subnet.Spec.SecondaryCIDRBlocks {
+		if gw, err := FirstIP(cidrBlock); err == nil {
+			networks = append(networks, GetIpAddrWithMask(gw, cidrBlock))
+		}
+	}
+	return networks
+}
+
+// GetSubnetCidrAndGateway returns the cidr blocks and gateways of the ranges the ips belong to,
+// in the format of the cidr and gateway annotations of pods
+func GetSubnetCidrAndGateway(subnet *kubeovnv1.Subnet, ipStr string) (string, string) {
+	if len(subnet.Spec.SecondaryCIDRBlocks) == 0 {
+		return subnet.Spec.CIDRBlock, subnet.Spec.Gateway
+	}
+
+	var cidrs, gws []string
+	for _, ip := range strings.Split(ipStr, ",") {
+		cidr, gw := "", ""
+		for _, cidrBlock := range subnet.Spec.SecondaryCIDRBlocks {
+			if CIDRContainIP(cidrBlock, ip) {
+				cidr = cidrBlock
+				gw, _ = FirstIP(cidrBlock)
+				break
+			}
+		}
+		if cidr == "" {
+			protocol := CheckProtocol(ip)
+			for _, cidrBlock := range strings.Split(subnet.Spec.CIDRBlock, ",") {
+				if CheckProtocol(cidrBlock) == protocol {
+					cidr = cidrBlock
+				}
+			}
+			for _, g := range strings.Split(subnet.Spec.Gateway, ",") {
+				if CheckProtocol(g) == protocol {
+					gw = g
+				}
+			}
+		}
+		cidrs, gws = append(cidrs, cidr), append(gws, gw)
+	}
+	return strings.Join(cidrs, ","), strings.Join(gws, ",")
+}
+
 func GetIpWithoutMask(ipStr string) string {
 	var ips []string
 	for _, ip := range strings.Split(ipStr, ",") {
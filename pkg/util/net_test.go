@@ -3,6 +3,8 @@ package util
 import (
 	"errors"
 	"testing"
+
+	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
 )
 
 func TestCheckCIDRsAll(t *testing.T) {
@@ -92,3 +94,34 @@ func TestCheckCIDRSpec(t *testing.T) {
 		})
 	}
 }
+
+func TestGetSubnetCidrAndGateway(t *testing.T) {
+	subnet := &kubeovnv1.Subnet{
+		Spec: kubeovnv1.SubnetSpec{
+			CIDRBlock:           "10.16.0.0/16,fd00:10:16::/64",
+			SecondaryCIDRBlocks: []string{"10.17.0.0/24", "fd00:10:17::/64"},
+			Gateway:             "10.16.0.1,fd00:10:16::1",
+		},
+	}
+	cases := []struct {
+		name    string
+		ip      string
+		cidr    string
+		gateway string
+	}{
+		{"primary", "10.16.0.5", "10.16.0.0/16", "10.16.0.1"},
+		{"secondary", "10.17.0.5", "10.17.0.0/24", "10.17.0.1"},
+		{"dual", "10.17.0.5,fd00:10:16::5", "10.17.0.0/24,fd00:10:16::/64", "10.17.0.1,fd00:10:16::1"},
+		{"secondaryV6", "10.16.0.5,fd00:10:17::5", "10.16.0.0/16,fd00:10:17::/64", "10.16.0.1,fd00:10:17::1"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cidr, gateway := GetSubnetCidrAndGateway(subnet, c.ip)
+			if cidr != c.cidr || gateway != c.gateway {
+				t.Fatalf("%v expected %v %v, but %v %v got",
+					c.ip, c.cidr, c.gateway, cidr, gateway)
+			}
+		})
+	}
+}
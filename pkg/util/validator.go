@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	kubeovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
 )
@@ -22,6 +23,26 @@ func ValidateSubnet(subnet kubeovnv1.Subnet) error {
 	if CheckProtocol(subnet.Spec.CIDRBlock) == "" {
 		return fmt.Errorf("CIDRBlock: %s formal error", subnet.Spec.CIDRBlock)
 	}
+	if subnet.Spec.Dscp != nil && (*subnet.Spec.Dscp < 0 || *subnet.Spec.Dscp > 63) {
+		return fmt.Errorf("%d is not a valid dscp", *subnet.Spec.Dscp)
+	}
+	cidrProtocol := CheckProtocol(subnet.Spec.CIDRBlock)
+	cidrBlocks := strings.Split(subnet.Spec.CIDRBlock, ",")
+	for _, cidr := range subnet.Spec.SecondaryCIDRBlocks {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("%s in secondaryCIDRBlocks is not a valid cidr", cidr)
+		}
+		if protocol := CheckProtocol(cidr); cidrProtocol != kubeovnv1.ProtocolDual && protocol != cidrProtocol {
+			return fmt.Errorf("secondary cidr %s is not %s as cidr %s", cidr, cidrProtocol, subnet.Spec.CIDRBlock)
+		}
+		if err := CIDRGlobalUnicast(cidr); err != nil {
+			return err
+		}
+		if CIDROverlap(strings.Join(cidrBlocks, ","), cidr) {
+			return fmt.Errorf("secondary cidr %s overlaps with cidr %s", cidr, strings.Join(cidrBlocks, ","))
+		}
+		cidrBlocks = append(cidrBlocks, cidr)
+	}
 	excludeIps := subnet.Spec.ExcludeIps
 	for _, ipr := range excludeIps {
 		ips := strings.Split(ipr, "..")
@@ -61,8 +82,8 @@ func ValidateSubnet(subnet kubeovnv1.Subnet) error {
 
 	if subnet.Spec.Vpc == DefaultVpc {
 		k8sApiServer := os.Getenv("KUBERNETES_SERVICE_HOST")
-		if k8sApiServer != "" && CIDRContainIP(subnet.Spec.CIDRBlock, k8sApiServer) {
-			return fmt.Errorf("subnet %s cidr %s conflicts with k8s apiserver svc ip %s", subnet.Name, subnet.Spec.CIDRBlock, k8sApiServer)
+		if k8sApiServer != "" && SubnetContainIP(&subnet, k8sApiServer) {
+			return fmt.Errorf("subnet %s cidr %s conflicts with k8s apiserver svc ip %s", subnet.Name, strings.Join(cidrBlocks, ","), k8sApiServer)
 		}
 	}
 
@@ -87,8 +108,8 @@ func ValidateSubnet(subnet kubeovnv1.Subnet) error {
 
 	if len(subnet.Spec.Vips) != 0 {
 		for _, vip := range subnet.Spec.Vips {
-			if !CIDRContainIP(subnet.Spec.CIDRBlock, vip) {
-				return fmt.Errorf("vip %s conflicts with subnet %s cidr %s", vip, subnet.Name, subnet.Spec.CIDRBlock)
+			if !SubnetContainIP(&subnet, vip) {
+				return fmt.Errorf("vip %s conflicts with subnet %s cidr %s", vip, subnet.Name, strings.Join(cidrBlocks, ","))
 			}
 		}
 	}
@@ -159,9 +180,27 @@ func ValidatePodNetwork(annotations map[string]string) error {
 		}
 	}
 
+	if dscp := annotations[DscpAnnotation]; dscp != "" {
+		if _, err := ParseDscp(dscp); err != nil {
+			errors = append(errors, fmt.Errorf("%s is not a valid %s", dscp, DscpAnnotation))
+		}
+	}
+
 	return utilerrors.NewAggregate(errors)
 }
 
+// ParseDscp parses the dscp value in the annotations, which should be in the range of 0 to 63
+func ParseDscp(dscp string) (int, error) {
+	v, err := strconv.Atoi(dscp)
+	if err != nil {
+		return 0, err
+	}
+	if v < 0 || v > 63 {
+		return 0, fmt.Errorf("dscp %d is out of range 0-63", v)
+	}
+	return v, nil
+}
+
 func ValidatePodCidr(cidr, ip string) error {
 	for _, cidrBlock := range strings.Split(cidr, ",") {
 		for _, ipAddr := range strings.Split(ip, ",") {
@@ -187,8 +226,9 @@ func ValidateCidrConflict(subnet kubeovnv1.Subnet, subnetList []kubeovnv1.Subnet
 			continue
 		}
 
-		if CIDROverlap(sub.Spec.CIDRBlock, subnet.Spec.CIDRBlock) {
-			err := fmt.Errorf("subnet %s cidr %s is conflict with subnet %s cidr %s", subnet.Name, subnet.Spec.CIDRBlock, sub.Name, sub.Spec.CIDRBlock)
+		cidrs, subCIDRs := strings.Join(SubnetCIDRBlocks(&subnet), ","), strings.Join(SubnetCIDRBlocks(&sub), ",")
+		if CIDROverlap(subCIDRs, cidrs) {
+			err := fmt.Errorf("subnet %s cidr %s is conflict with subnet %s cidr %s", subnet.Name, cidrs, sub.Name, subCIDRs)
 			return err
 		}
 
@@ -200,3 +240,163 @@ func ValidateCidrConflict(subnet kubeovnv1.Subnet, subnetList []kubeovnv1.Subnet
 	}
 	return nil
 }
+
+func ValidateQoSPolicy(policy kubeovnv1.QoSPolicy) error {
+	errors := []error{}
+	binding := policy.Spec.Binding
+	switch binding.Type {
+	case kubeovnv1.QoSBindingTypePod:
+		if binding.Namespace == "" || binding.PodSelector == nil {
+			errors = append(errors, fmt.Errorf("namespace and podSelector are required by binding type %s", binding.Type))
+		}
+	case kubeovnv1.QoSBindingTypeSubnet, kubeovnv1.QoSBindingTypeNamespace, kubeovnv1.QoSBindingTypeEIP, kubeovnv1.QoSBindingTypeNode:
+		if len(binding.Names) == 0 {
+			errors = append(errors, fmt.Errorf("names are required by binding type %s", binding.Type))
+		}
+	default:
+		errors = append(errors, fmt.Errorf("%s is not a valid binding type", binding.Type))
+	}
+
+	spec := policy.Spec
+	for _, field := range []struct{ name, value string }{
+		{"ingressRate", spec.IngressRate},
+		{"egressRate", spec.EgressRate},
+		{"burst", spec.Burst},
+		{"priority", spec.Priority},
+		{"latency", spec.Latency},
+		{"limit", spec.Limit},
+	} {
+		if field.value == "" {
+			continue
+		}
+		if v, err := strconv.Atoi(field.value); err != nil || v < 0 {
+			errors = append(errors, fmt.Errorf("%s is not a valid %s", field.value, field.name))
+		}
+	}
+	if spec.Loss != "" {
+		if v, err := strconv.ParseFloat(spec.Loss, 64); err != nil || v < 0 || v > 100 {
+			errors = append(errors, fmt.Errorf("%s is not a valid loss", spec.Loss))
+		}
+	}
+	if spec.Dscp != nil && (*spec.Dscp < 0 || *spec.Dscp > 63) {
+		errors = append(errors, fmt.Errorf("%d is not a valid dscp", *spec.Dscp))
+	}
+	for _, cidr := range spec.DestinationCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			errors = append(errors, fmt.Errorf("%s is not a valid destination cidr", cidr))
+		}
+	}
+
+	netem := spec.Latency != "" || spec.Limit != "" || spec.Loss != ""
+	// the ingress rate and priority are implemented by htb qos of the ovs port, which can not be used with netem qos
+	if netem && (spec.IngressRate != "" || spec.Priority != "") {
+		errors = append(errors, fmt.Errorf("netem settings can not be used together with ingressRate or priority"))
+	}
+	switch binding.Type {
+	case kubeovnv1.QoSBindingTypeEIP:
+		if spec.Burst != "" || spec.Priority != "" || spec.Dscp != nil || netem || len(spec.DestinationCIDRs) != 0 || len(spec.DestinationSubnets) != 0 {
+			errors = append(errors, fmt.Errorf("only ingressRate and egressRate are supported by binding type %s", binding.Type))
+		}
+	case kubeovnv1.QoSBindingTypeNode:
+		if spec.Dscp != nil || netem {
+			errors = append(errors, fmt.Errorf("dscp and netem settings are not supported by binding type %s", binding.Type))
+		}
+	}
+
+	return utilerrors.NewAggregate(errors)
+}
+
+// ValidateFqdn checks the domain name is in the canonical form, which is used as the name of the FqdnAddress
+func ValidateFqdn(fqdn string) error {
+	if errs := validation.IsDNS1123Subdomain(fqdn); len(errs) != 0 {
+		return fmt.Errorf("invalid domain name '%s', %s", fqdn, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// ValidateSgRule checks the fields of the security group rule, the existence of the remote security group is not checked
+func ValidateSgRule(rule *kubeovnv1.SgRule) error {
+	if rule.IPVersion != "ipv4" && rule.IPVersion != "ipv6" {
+		return fmt.Errorf("IPVersion should be 'ipv4' or 'ipv6'")
+	}
+
+	if rule.Priority < 1 || rule.Priority > 200 {
+		return fmt.Errorf("priority '%d' is not in the range of 1 to 200", rule.Priority)
+	}
+
+	switch rule.RemoteType {
+	case kubeovnv1.SgRemoteTypeAddress:
+		if strings.Contains(rule.RemoteAddress, "/") {
+			if _, _, err := net.ParseCIDR(rule.RemoteAddress); err != nil {
+				return fmt.Errorf("invalid CIDR '%s'", rule.RemoteAddress)
+			}
+		} else if net.ParseIP(rule.RemoteAddress) == nil {
+			return fmt.Errorf("invalid ip address '%s'", rule.RemoteAddress)
+		}
+		protocol := kubeovnv1.ProtocolIPv4
+		if rule.IPVersion == "ipv6" {
+			protocol = kubeovnv1.ProtocolIPv6
+		}
+		if CheckProtocol(rule.RemoteAddress) != protocol {
+			return fmt.Errorf("remote address '%s' does not match IPVersion '%s'", rule.RemoteAddress, rule.IPVersion)
+		}
+	case kubeovnv1.SgRemoteTypeSg:
+		if rule.RemoteSecurityGroup == "" {
+			return fmt.Errorf("remoteSecurityGroup is required by sgRemoteType '%s'", rule.RemoteType)
+		}
+	case kubeovnv1.SgRemoteTypeFQDN:
+		if err := ValidateFqdn(rule.RemoteAddress); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("not support sgRemoteType '%s'", rule.RemoteType)
+	}
+
+	if rule.Protocol == kubeovnv1.ProtocolTCP || rule.Protocol == kubeovnv1.ProtocolUDP {
+		if rule.PortRangeMin < 1 || rule.PortRangeMin > 65535 || rule.PortRangeMax < 1 || rule.PortRangeMax > 65535 {
+			return fmt.Errorf("portRange is out of range")
+		}
+		if rule.PortRangeMin > rule.PortRangeMax {
+			return fmt.Errorf("portRange err, range Minimum value greater than maximum value")
+		}
+	}
+	return nil
+}
+
+// ValidateSgRules checks the rules in the same direction and returns the errors by the indexes of the rules.
+// Rules of different policies can not share a priority, otherwise which one takes effect is undefined
+func ValidateSgRules(rules []*kubeovnv1.SgRule) map[int]error {
+	errors := map[int]error{}
+	policies := map[int]int{}
+	for i, rule := range rules {
+		if err := ValidateSgRule(rule); err != nil {
+			errors[i] = err
+			continue
+		}
+		if j, ok := policies[rule.Priority]; ok && rules[j].Policy != rule.Policy {
+			errors[i] = fmt.Errorf("priority '%d' overlaps with rule %d of policy '%s'", rule.Priority, j, rules[j].Policy)
+			continue
+		}
+		policies[rule.Priority] = i
+	}
+	return errors
+}
+
+func ValidateSecurityGroup(sg kubeovnv1.SecurityGroup) error {
+	errors := []error{}
+	for _, direction := range []struct {
+		name  string
+		rules []*kubeovnv1.SgRule
+	}{
+		{"ingress", sg.Spec.IngressRules},
+		{"egress", sg.Spec.EgressRules},
+	} {
+		ruleErrors := ValidateSgRules(direction.rules)
+		for i := range direction.rules {
+			if ruleErrors[i] != nil {
+				errors = append(errors, fmt.Errorf("%s rule %d: %v", direction.name, i, ruleErrors[i]))
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errors)
+}
@@ -0,0 +1,54 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	ovnv1 "github.com/kubeovn/kube-ovn/pkg/apis/kubeovn/v1"
+	"github.com/kubeovn/kube-ovn/pkg/ipam"
+	"github.com/kubeovn/kube-ovn/pkg/util"
+)
+
+func (v *ValidatingHook) IPPoolCreateOrUpdateHook(ctx context.Context, req admission.Request) admission.Response {
+	o := ovnv1.IPPool{}
+	if err := v.decoder.Decode(req, &o); err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+
+	if o.Spec.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(o.Spec.Selector); err != nil {
+			return ctrlwebhook.Denied(fmt.Sprintf("invalid selector: %v", err))
+		}
+	}
+
+	subnet := &ovnv1.Subnet{}
+	if err := v.cache.Get(ctx, client.ObjectKey{Name: o.Spec.Subnet}, subnet); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return ctrlwebhook.Denied(fmt.Sprintf("subnet %s not found", o.Spec.Subnet))
+		}
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+
+	ippoolList := &ovnv1.IPPoolList{}
+	if err := v.cache.List(ctx, ippoolList); err != nil {
+		return ctrlwebhook.Errored(http.StatusBadRequest, err)
+	}
+	otherPools := make(map[string][]string, len(ippoolList.Items))
+	for _, pool := range ippoolList.Items {
+		if pool.Name != o.Name && pool.Spec.Subnet == o.Spec.Subnet {
+			otherPools[pool.Name] = pool.Spec.IPs
+		}
+	}
+	if err := ipam.ValidateIPPool(o.Name, o.Spec.IPs, util.SubnetCIDRBlocks(subnet), otherPools); err != nil {
+		return ctrlwebhook.Denied(err.Error())
+	}
+
+	return ctrlwebhook.Allowed("by pass")
+}
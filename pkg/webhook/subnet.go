@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"reflect"
+	"strings"
 
 	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -52,6 +54,17 @@ func (v *ValidatingHook) SubnetUpdateHook(ctx context.Context, req admission.Req
 		return ctrlwebhook.Denied(err.Error())
 	}
 
+	// the cidr can be expanded in place, but shrinking it must not leave any allocated address outside
+	if o.Spec.CIDRBlock != oldSubnet.Spec.CIDRBlock || !reflect.DeepEqual(o.Spec.SecondaryCIDRBlocks, oldSubnet.Spec.SecondaryCIDRBlocks) {
+		ipList := &ovnv1.IPList{}
+		if err := v.cache.List(ctx, ipList); err != nil {
+			return ctrlwebhook.Errored(http.StatusBadRequest, err)
+		}
+		if err := checkAddressesInCIDR(o, ipList.Items); err != nil {
+			return ctrlwebhook.Denied(err.Error())
+		}
+	}
+
 	subnetList := &ovnv1.SubnetList{}
 	if err := v.cache.List(ctx, subnetList); err != nil {
 		return ctrlwebhook.Errored(http.StatusBadRequest, err)
@@ -63,6 +76,26 @@ func (v *ValidatingHook) SubnetUpdateHook(ctx context.Context, req admission.Req
 	return ctrlwebhook.Allowed("by pass")
 }
 
+// checkAddressesInCIDR returns an error if any address allocated from the subnet is out of its cidr,
+// addresses of a protocol the subnet no longer has are not checked
+func checkAddressesInCIDR(subnet ovnv1.Subnet, ipList []ovnv1.IP) error {
+	protocol := util.CheckProtocol(subnet.Spec.CIDRBlock)
+	for _, ip := range ipList {
+		if ip.Spec.Subnet != subnet.Name {
+			continue
+		}
+		for _, addr := range strings.Split(ip.Spec.IPAddress, ",") {
+			if addr == "" || (protocol != ovnv1.ProtocolDual && util.CheckProtocol(addr) != protocol) {
+				continue
+			}
+			if !util.SubnetContainIP(&subnet, addr) {
+				return fmt.Errorf("can't shrink cidr to %s, address %s of ip %s is out of it", strings.Join(util.SubnetCIDRBlocks(&subnet), ","), addr, ip.Name)
+			}
+		}
+	}
+	return nil
+}
+
 func (v *ValidatingHook) SubnetDeleteHook(ctx context.Context, req admission.Request) admission.Response {
 	subnet := ovnv1.Subnet{}
 	if err := v.decoder.DecodeRaw(req.OldObject, &subnet); err != nil {
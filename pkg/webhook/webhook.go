@@ -44,8 +44,14 @@ func NewValidatingHook(c cache.Cache) (*ValidatingHook, error) {
 	createHooks[daemonSetGVK] = v.DaemonSetCreateHook
 	createHooks[podGVK] = v.PodCreateHook
 	createHooks[subnetGVK] = v.SubnetCreateHook
+	createHooks[qosPolicyGVK] = v.QoSPolicyCreateOrUpdateHook
+	createHooks[sgGVK] = v.SecurityGroupCreateOrUpdateHook
+	createHooks[ippoolGVK] = v.IPPoolCreateOrUpdateHook
 
 	updateHooks[subnetGVK] = v.SubnetUpdateHook
+	updateHooks[qosPolicyGVK] = v.QoSPolicyCreateOrUpdateHook
+	updateHooks[sgGVK] = v.SecurityGroupCreateOrUpdateHook
+	updateHooks[ippoolGVK] = v.IPPoolCreateOrUpdateHook
 
 	deleteHooks[subnetGVK] = v.SubnetDeleteHook
 
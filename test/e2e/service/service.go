@@ -77,6 +77,88 @@ func setSvcEtpToLocal(kubeClientSet kubernetes.Interface, name string) (*corev1.
 	return kubeClientSet.CoreV1().Services(svc.Namespace).Update(context.Background(), newSvc, metav1.UpdateOptions{})
 }
 
+func createSvcItpLocal(kubeClientSet kubernetes.Interface, name string) (*corev1.Service, error) {
+	svc, err := kubeClientSet.CoreV1().Services(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	localName := name + "-itp-local"
+	if localSvc, err := kubeClientSet.CoreV1().Services(namespace).Get(context.Background(), localName, metav1.GetOptions{}); err == nil {
+		return localSvc, nil
+	} else if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	local := corev1.ServiceInternalTrafficPolicyLocal
+	ports := make([]corev1.ServicePort, 0, len(svc.Spec.Ports))
+	for _, port := range svc.Spec.Ports {
+		port.NodePort = 0
+		ports = append(ports, port)
+	}
+	localSvc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      localName,
+			Namespace: namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Type:                  corev1.ServiceTypeClusterIP,
+			Selector:              svc.Spec.Selector,
+			Ports:                 ports,
+			IPFamilies:            svc.Spec.IPFamilies,
+			IPFamilyPolicy:        svc.Spec.IPFamilyPolicy,
+			InternalTrafficPolicy: &local,
+		},
+	}
+	return kubeClientSet.CoreV1().Services(namespace).Create(context.Background(), localSvc, metav1.CreateOptions{})
+}
+
+func createSvcEtpLocal(kubeClientSet kubernetes.Interface, name string) (*corev1.Service, error) {
+	svc, err := kubeClientSet.CoreV1().Services(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	localName := name + "-etp-local"
+	if localSvc, err := kubeClientSet.CoreV1().Services(namespace).Get(context.Background(), localName, metav1.GetOptions{}); err == nil {
+		return localSvc, nil
+	} else if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	ports := make([]corev1.ServicePort, 0, len(svc.Spec.Ports))
+	for _, port := range svc.Spec.Ports {
+		port.NodePort = 0
+		ports = append(ports, port)
+	}
+	localSvc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      localName,
+			Namespace: namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Type:                  corev1.ServiceTypeNodePort,
+			Selector:              svc.Spec.Selector,
+			Ports:                 ports,
+			IPFamilies:            svc.Spec.IPFamilies,
+			IPFamilyPolicy:        svc.Spec.IPFamilyPolicy,
+			ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyTypeLocal,
+		},
+	}
+	return kubeClientSet.CoreV1().Services(namespace).Create(context.Background(), localSvc, metav1.CreateOptions{})
+}
+
+// servicePort returns the port of the service with the name
+func servicePort(svc *corev1.Service, name string) corev1.ServicePort {
+	for _, port := range svc.Spec.Ports {
+		if port.Name == name {
+			return port
+		}
+	}
+	Fail(fmt.Sprintf("port %s not found in service %s", name, svc.Name))
+	return corev1.ServicePort{}
+}
+
 func hasEndpoint(node string, endpoints *corev1.Endpoints) bool {
 	for _, subset := range endpoints.Subsets {
 		for _, addr := range subset.Addresses {
@@ -125,6 +207,20 @@ var _ = Describe("[Service]", func() {
 	localEtpHostEndpoints, err := f.KubeClientSet.CoreV1().Endpoints(namespace).Get(context.Background(), localEtpHostService.Name, metav1.GetOptions{})
 	Expect(err).NotTo(HaveOccurred())
 
+	// the service selects the same endpoints as kube-ovn-monitor
+	localItpHostService, err := createSvcItpLocal(f.KubeClientSet, "kube-ovn-monitor")
+	Expect(err).NotTo(HaveOccurred())
+
+	// coredns runs in the container network on some of the nodes, the metrics are served on port 9153
+	localItpContainerService, err := createSvcItpLocal(f.KubeClientSet, "kube-dns")
+	Expect(err).NotTo(HaveOccurred())
+
+	localEtpContainerService, err := createSvcEtpLocal(f.KubeClientSet, "kube-dns")
+	Expect(err).NotTo(HaveOccurred())
+
+	containerEndpoints, err := f.KubeClientSet.CoreV1().Endpoints(namespace).Get(context.Background(), "kube-dns", metav1.GetOptions{})
+	Expect(err).NotTo(HaveOccurred())
+
 	nodes, err := f.KubeClientSet.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
 	Expect(err).NotTo(HaveOccurred())
 	checkCount := len(nodes.Items)
@@ -355,4 +451,69 @@ var _ = Describe("[Service]", func() {
 			}
 		})
 	})
+
+	Context("host service with local internal traffic policy", func() {
+		It("container to ClusterIP", func() {
+			port := localItpHostService.Spec.Ports[0].Port
+			for _, pod := range containerPods.Items {
+				shouldSucceed := hasEndpoint(pod.Spec.NodeName, localEtpHostEndpoints)
+				for _, ip := range localItpHostService.Spec.ClusterIPs {
+					checkService(checkCount, shouldSucceed, "kubectl", strings.Fields(kubectlArgs(pod.Name, ip, port))...)
+				}
+			}
+		})
+
+		It("host to ClusterIP", func() {
+			port := localItpHostService.Spec.Ports[0].Port
+			for _, pod := range hostPods.Items {
+				shouldSucceed := hasEndpoint(pod.Spec.NodeName, localEtpHostEndpoints)
+				for _, ip := range localItpHostService.Spec.ClusterIPs {
+					checkService(checkCount, shouldSucceed, "kubectl", strings.Fields(kubectlArgs(pod.Name, ip, port))...)
+				}
+			}
+		})
+	})
+
+	Context("container service with local internal traffic policy", func() {
+		It("container to ClusterIP", func() {
+			port := servicePort(localItpContainerService, "metrics").Port
+			for _, pod := range containerPods.Items {
+				shouldSucceed := hasEndpoint(pod.Spec.NodeName, containerEndpoints)
+				for _, ip := range localItpContainerService.Spec.ClusterIPs {
+					checkService(checkCount, shouldSucceed, "kubectl", strings.Fields(kubectlArgs(pod.Name, ip, port))...)
+				}
+			}
+		})
+
+		It("host to ClusterIP", func() {
+			port := servicePort(localItpContainerService, "metrics").Port
+			for _, pod := range hostPods.Items {
+				shouldSucceed := hasEndpoint(pod.Spec.NodeName, containerEndpoints)
+				for _, ip := range localItpContainerService.Spec.ClusterIPs {
+					checkService(checkCount, shouldSucceed, "kubectl", strings.Fields(kubectlArgs(pod.Name, ip, port))...)
+				}
+			}
+		})
+	})
+
+	Context("container service with local external traffic policy", func() {
+		It("container to ClusterIP", func() {
+			port := servicePort(localEtpContainerService, "metrics").Port
+			for _, pod := range containerPods.Items {
+				for _, ip := range localEtpContainerService.Spec.ClusterIPs {
+					checkService(checkCount, true, "kubectl", strings.Fields(kubectlArgs(pod.Name, ip, port))...)
+				}
+			}
+		})
+
+		It("external to NodePort", func() {
+			port := servicePort(localEtpContainerService, "metrics").NodePort
+			for _, node := range nodes.Items {
+				shouldSucceed := proxyIpvsMode || hasEndpoint(node.Name, containerEndpoints)
+				for _, nodeIP := range nodeIPs(node) {
+					checkService(checkCount, shouldSucceed, "docker", append(dockerArgs, strings.Fields(curlArgs(nodeIP, port))...)...)
+				}
+			}
+		})
+	})
 })
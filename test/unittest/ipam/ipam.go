@@ -51,11 +51,11 @@ var _ = Describe("[IPAM]", func() {
 				_, _, _, err = im.GetStaticAddress("pod1.ns", "pod1.ns", "10.16.0.2", "", subnetName, true)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				ip, _, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, nil, true)
+				ip, _, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip).To(Equal("10.16.0.2"))
 
-				ip, _, _, err = im.GetRandomAddress("pod2.ns", "pod2.ns", "", subnetName, nil, true)
+				ip, _, _, err = im.GetRandomAddress("pod2.ns", "pod2.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip).To(Equal("10.16.0.3"))
 
@@ -66,13 +66,13 @@ var _ = Describe("[IPAM]", func() {
 				_, _, _, err = im.GetStaticAddress("pod3.ns", "pod3.ns", "10.16.0.2", "", subnetName, true)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				_, _, _, err = im.GetRandomAddress("pod4.ns", "pod4.ns", "", "invalid_subnet", nil, true)
+				_, _, _, err = im.GetRandomAddress("pod4.ns", "pod4.ns", "", "invalid_subnet", "", nil, true)
 				Expect(err).Should(MatchError(ipam.ErrNoAvailable))
 
 				err = im.AddOrUpdateSubnet(subnetName, ipv4CIDR, v4Gw, nil)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				ip, _, _, err = im.GetRandomAddress("pod5.ns", "pod5.ns", "", subnetName, nil, true)
+				ip, _, _, err = im.GetRandomAddress("pod5.ns", "pod5.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip).To(Equal("10.16.0.1"))
 
@@ -88,27 +88,101 @@ var _ = Describe("[IPAM]", func() {
 
 				err = im.AddOrUpdateSubnet(subnetName, "10.17.0.0/16", v4Gw, []string{"10.17.0.1"})
 				Expect(err).ShouldNot(HaveOccurred())
-				ip, _, _, err := im.GetRandomAddress("pod5.ns", "pod5.ns", "", subnetName, nil, true)
+				ip, _, _, err := im.GetRandomAddress("pod5.ns", "pod5.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip).To(Equal("10.17.0.2"))
 			})
 
+			It("expand cidr", func() {
+				im := ipam.NewIPAM()
+				err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/30", v4Gw, []string{v4Gw})
+				Expect(err).ShouldNot(HaveOccurred())
+				ip, _, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "", nil, true)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(ip).To(Equal("10.16.0.2"))
+				_, _, _, err = im.GetRandomAddress("pod2.ns", "pod2.ns", "", subnetName, "", nil, true)
+				Expect(err).Should(MatchError(ipam.ErrNoAvailable))
+
+				err = im.AddOrUpdateSubnet(subnetName, "10.16.0.0/29", v4Gw, []string{v4Gw})
+				Expect(err).ShouldNot(HaveOccurred())
+				addresses := im.GetPodAddress("pod1.ns")
+				Expect(addresses).To(HaveLen(1))
+				Expect(addresses[0].Ip).To(Equal("10.16.0.2"))
+				ip, _, _, err = im.GetRandomAddress("pod2.ns", "pod2.ns", "", subnetName, "", nil, true)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(ip).To(Equal("10.16.0.3"))
+			})
+
+			It("shrink cidr", func() {
+				im := ipam.NewIPAM()
+				err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/29", v4Gw, []string{v4Gw})
+				Expect(err).ShouldNot(HaveOccurred())
+				_, _, _, err = im.GetStaticAddress("pod1.ns", "pod1.ns", "10.16.0.6", "", subnetName, true)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				err = im.AddOrUpdateSubnet(subnetName, "10.16.0.0/30", v4Gw, []string{v4Gw})
+				Expect(err).Should(MatchError(ipam.ErrOutOfRange))
+				ip, _, _, err := im.GetRandomAddress("pod2.ns", "pod2.ns", "", subnetName, "", nil, true)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(ip).To(Equal("10.16.0.2"))
+
+				im.ReleaseAddressByPod("pod1.ns")
+				err = im.AddOrUpdateSubnet(subnetName, "10.16.0.0/30", v4Gw, []string{v4Gw})
+				Expect(err).ShouldNot(HaveOccurred())
+				addresses := im.GetPodAddress("pod2.ns")
+				Expect(addresses).To(HaveLen(1))
+				Expect(addresses[0].Ip).To(Equal("10.16.0.2"))
+			})
+
+			It("allocate from secondary cidr", func() {
+				im := ipam.NewIPAM()
+				err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/30,10.17.0.0/29", v4Gw, []string{v4Gw, "10.17.0.1"})
+				Expect(err).ShouldNot(HaveOccurred())
+
+				ip, _, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "", nil, true)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(ip).To(Equal("10.16.0.2"))
+				ip, _, _, err = im.GetRandomAddress("pod2.ns", "pod2.ns", "", subnetName, "", nil, true)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(ip).To(Equal("10.17.0.2"))
+				_, _, _, err = im.GetStaticAddress("pod3.ns", "pod3.ns", "10.17.0.6", "", subnetName, true)
+				Expect(err).ShouldNot(HaveOccurred())
+				_, _, _, err = im.GetStaticAddress("pod4.ns", "pod4.ns", "10.17.0.9", "", subnetName, true)
+				Expect(err).Should(MatchError(ipam.ErrOutOfRange))
+
+				usage, err := im.CIDRStatistics(subnetName)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(usage).To(Equal([]ipam.CIDRUsage{
+					{CIDR: "10.16.0.0/30", Available: 0, Using: 1},
+					{CIDR: "10.17.0.0/29", Available: 3, Using: 2},
+				}))
+
+				// free addresses of the secondary cidr are allocated before the released ones
+				im.ReleaseAddressByPod("pod1.ns")
+				ip, _, _, err = im.GetRandomAddress("pod5.ns", "pod5.ns", "", subnetName, "", nil, true)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(ip).To(Equal("10.17.0.3"))
+
+				err = im.AddOrUpdateSubnet(subnetName, "10.16.0.0/30", v4Gw, []string{v4Gw})
+				Expect(err).Should(MatchError(ipam.ErrOutOfRange))
+			})
+
 			It("reuse released address when no unused address", func() {
 				im := ipam.NewIPAM()
 				err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/30", v4Gw, nil)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				ip, _, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, nil, true)
+				ip, _, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip).To(Equal("10.16.0.1"))
 
 				im.ReleaseAddressByPod("pod1.ns")
-				ip, _, _, err = im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, nil, true)
+				ip, _, _, err = im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip).To(Equal("10.16.0.2"))
 
 				im.ReleaseAddressByPod("pod1.ns")
-				ip, _, _, err = im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, nil, true)
+				ip, _, _, err = im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip).To(Equal("10.16.0.1"))
 			})
@@ -118,7 +192,7 @@ var _ = Describe("[IPAM]", func() {
 				err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/30", v4Gw, nil)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				ip, _, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, nil, true)
+				ip, _, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip).To(Equal("10.16.0.1"))
 
@@ -126,7 +200,7 @@ var _ = Describe("[IPAM]", func() {
 				err = im.AddOrUpdateSubnet(subnetName, "10.16.0.0/30", v4Gw, []string{"10.16.0.1..10.16.0.2"})
 				Expect(err).ShouldNot(HaveOccurred())
 
-				_, _, _, err = im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, nil, true)
+				_, _, _, err = im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "", nil, true)
 				Expect(err).Should(MatchError(ipam.ErrNoAvailable))
 			})
 		})
@@ -148,11 +222,11 @@ var _ = Describe("[IPAM]", func() {
 				_, _, _, err = im.GetStaticAddress("pod1.ns", "pod1.ns", "fd00::2", "", subnetName, true)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				_, ip, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, nil, true)
+				_, ip, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip).To(Equal("fd00::2"))
 
-				_, ip, _, err = im.GetRandomAddress("pod2.ns", "pod2.ns", "", subnetName, nil, true)
+				_, ip, _, err = im.GetRandomAddress("pod2.ns", "pod2.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip).To(Equal("fd00::3"))
 
@@ -163,13 +237,13 @@ var _ = Describe("[IPAM]", func() {
 				_, _, _, err = im.GetStaticAddress("pod3.ns", "pod3.ns", "fd00::2", "", subnetName, true)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				_, _, _, err = im.GetRandomAddress("pod4.ns", "pod4.ns", "", "invalid_subnet", nil, true)
+				_, _, _, err = im.GetRandomAddress("pod4.ns", "pod4.ns", "", "invalid_subnet", "", nil, true)
 				Expect(err).Should(MatchError(ipam.ErrNoAvailable))
 
 				err = im.AddOrUpdateSubnet(subnetName, ipv6CIDR, v6Gw, nil)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				_, ip, _, err = im.GetRandomAddress("pod5.ns", "pod5.ns", "", subnetName, nil, true)
+				_, ip, _, err = im.GetRandomAddress("pod5.ns", "pod5.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip).To(Equal("fd00::1"))
 
@@ -185,7 +259,7 @@ var _ = Describe("[IPAM]", func() {
 
 				err = im.AddOrUpdateSubnet(subnetName, "fe00::/112", v6Gw, []string{"fe00::1"})
 				Expect(err).ShouldNot(HaveOccurred())
-				_, ip, _, err := im.GetRandomAddress("pod5.ns", "pod5.ns", "", subnetName, nil, true)
+				_, ip, _, err := im.GetRandomAddress("pod5.ns", "pod5.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip).To(Equal("fe00::2"))
 			})
@@ -195,17 +269,17 @@ var _ = Describe("[IPAM]", func() {
 				err := im.AddOrUpdateSubnet(subnetName, "fd00::/126", v6Gw, nil)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				_, ip, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, nil, true)
+				_, ip, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip).To(Equal("fd00::1"))
 
 				im.ReleaseAddressByPod("pod1.ns")
-				_, ip, _, err = im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, nil, true)
+				_, ip, _, err = im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip).To(Equal("fd00::2"))
 
 				im.ReleaseAddressByPod("pod1.ns")
-				_, ip, _, err = im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, nil, true)
+				_, ip, _, err = im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip).To(Equal("fd00::1"))
 			})
@@ -215,7 +289,7 @@ var _ = Describe("[IPAM]", func() {
 				err := im.AddOrUpdateSubnet(subnetName, "fd00::/126", v6Gw, nil)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				_, ip, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, nil, true)
+				_, ip, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip).To(Equal("fd00::1"))
 
@@ -223,7 +297,7 @@ var _ = Describe("[IPAM]", func() {
 				err = im.AddOrUpdateSubnet(subnetName, "fd00::/126", v6Gw, []string{"fd00::1..fd00::2"})
 				Expect(err).ShouldNot(HaveOccurred())
 
-				_, _, _, err = im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, nil, true)
+				_, _, _, err = im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "", nil, true)
 				Expect(err).Should(MatchError(ipam.ErrNoAvailable))
 			})
 		})
@@ -249,12 +323,12 @@ var _ = Describe("[IPAM]", func() {
 				_, _, _, err = im.GetStaticAddress("pod1.ns", "pod1.ns", "10.16.0.2,fd00::2", "", subnetName, true)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				ipv4, ipv6, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, nil, true)
+				ipv4, ipv6, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ipv4).To(Equal("10.16.0.2"))
 				Expect(ipv6).To(Equal("fd00::2"))
 
-				ipv4, ipv6, _, err = im.GetRandomAddress("pod2.ns", "pod2.ns", "", subnetName, nil, true)
+				ipv4, ipv6, _, err = im.GetRandomAddress("pod2.ns", "pod2.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ipv4).To(Equal("10.16.0.3"))
 				Expect(ipv6).To(Equal("fd00::3"))
@@ -266,13 +340,13 @@ var _ = Describe("[IPAM]", func() {
 				_, _, _, err = im.GetStaticAddress("pod3.ns", "pod3.ns", "10.16.0.2,fd00::2", "", subnetName, true)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				_, _, _, err = im.GetRandomAddress("pod4.ns", "pod4.ns", "", "invalid_subnet", nil, true)
+				_, _, _, err = im.GetRandomAddress("pod4.ns", "pod4.ns", "", "invalid_subnet", "", nil, true)
 				Expect(err).Should(MatchError(ipam.ErrNoAvailable))
 
 				err = im.AddOrUpdateSubnet(subnetName, dualCIDR, dualGw, nil)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				ipv4, ipv6, _, err = im.GetRandomAddress("pod5.ns", "pod5.ns", "", subnetName, nil, true)
+				ipv4, ipv6, _, err = im.GetRandomAddress("pod5.ns", "pod5.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ipv4).To(Equal("10.16.0.1"))
 				Expect(ipv6).To(Equal("fd00::1"))
@@ -290,7 +364,7 @@ var _ = Describe("[IPAM]", func() {
 
 				err = im.AddOrUpdateSubnet(subnetName, "10.17.0.2/16,fe00::/112", dualGw, []string{"10.17.0.1", "fe00::1"})
 				Expect(err).ShouldNot(HaveOccurred())
-				ipv4, ipv6, _, err := im.GetRandomAddress("pod5.ns", "pod5.ns", "", subnetName, nil, true)
+				ipv4, ipv6, _, err := im.GetRandomAddress("pod5.ns", "pod5.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ipv4).To(Equal("10.17.0.2"))
 				Expect(ipv6).To(Equal("fe00::2"))
@@ -301,19 +375,19 @@ var _ = Describe("[IPAM]", func() {
 				err := im.AddOrUpdateSubnet(subnetName, "10.16.0.2/30,fd00::/126", dualGw, nil)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				ipv4, ipv6, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, nil, true)
+				ipv4, ipv6, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ipv4).To(Equal("10.16.0.1"))
 				Expect(ipv6).To(Equal("fd00::1"))
 
 				im.ReleaseAddressByPod("pod1.ns")
-				ipv4, ipv6, _, err = im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, nil, true)
+				ipv4, ipv6, _, err = im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ipv4).To(Equal("10.16.0.2"))
 				Expect(ipv6).To(Equal("fd00::2"))
 
 				im.ReleaseAddressByPod("pod1.ns")
-				ipv4, ipv6, _, err = im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, nil, true)
+				ipv4, ipv6, _, err = im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ipv4).To(Equal("10.16.0.1"))
 				Expect(ipv6).To(Equal("fd00::1"))
@@ -324,7 +398,7 @@ var _ = Describe("[IPAM]", func() {
 				err := im.AddOrUpdateSubnet(subnetName, "10.16.0.2/30,fd00::/126", dualGw, nil)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				ipv4, ipv6, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, nil, true)
+				ipv4, ipv6, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ipv4).To(Equal("10.16.0.1"))
 				Expect(ipv6).To(Equal("fd00::1"))
@@ -333,7 +407,7 @@ var _ = Describe("[IPAM]", func() {
 				err = im.AddOrUpdateSubnet(subnetName, "10.16.0.2/30,fd00::/126", dualGw, []string{"10.16.0.1..10.16.0.2", "fd00::1..fd00::2"})
 				Expect(err).ShouldNot(HaveOccurred())
 
-				_, _, _, err = im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, nil, true)
+				_, _, _, err = im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "", nil, true)
 				Expect(err).Should(MatchError(ipam.ErrNoAvailable))
 			})
 		})
@@ -437,18 +511,18 @@ var _ = Describe("[IPAM]", func() {
 				subnet, err := ipam.NewSubnet(subnetName, "10.16.0.0/30", nil)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				ip1, _, _, err := subnet.GetRandomAddress("pod1.ns", "pod1.ns", "", nil, true)
+				ip1, _, _, err := subnet.GetRandomAddress("", "pod1.ns", "pod1.ns", "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip1).To(Equal(ipam.IP("10.16.0.1")))
-				ip1, _, _, err = subnet.GetRandomAddress("pod1.ns", "pod1.ns", "", nil, true)
+				ip1, _, _, err = subnet.GetRandomAddress("", "pod1.ns", "pod1.ns", "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip1).To(Equal(ipam.IP("10.16.0.1")))
 
-				ip2, _, _, err := subnet.GetRandomAddress("pod2.ns", "pod2.ns", "", nil, true)
+				ip2, _, _, err := subnet.GetRandomAddress("", "pod2.ns", "pod2.ns", "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip2).To(Equal(ipam.IP("10.16.0.2")))
 
-				_, _, _, err = subnet.GetRandomAddress("pod3.ns", "pod3.ns", "", nil, true)
+				_, _, _, err = subnet.GetRandomAddress("", "pod3.ns", "pod3.ns", "", nil, true)
 				Expect(err).Should(MatchError(ipam.ErrNoAvailable))
 				Expect(subnet.V4FreeIPList).To(BeEmpty())
 
@@ -525,18 +599,18 @@ var _ = Describe("[IPAM]", func() {
 				subnet, err := ipam.NewSubnet(subnetName, "fd00::/126", nil)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				_, ip1, _, err := subnet.GetRandomAddress("pod1.ns", "pod1.ns", "", nil, true)
+				_, ip1, _, err := subnet.GetRandomAddress("", "pod1.ns", "pod1.ns", "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip1).To(Equal(ipam.IP("fd00::1")))
-				_, ip1, _, err = subnet.GetRandomAddress("pod1.ns", "pod1.ns", "", nil, true)
+				_, ip1, _, err = subnet.GetRandomAddress("", "pod1.ns", "pod1.ns", "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip1).To(Equal(ipam.IP("fd00::1")))
 
-				_, ip2, _, err := subnet.GetRandomAddress("pod2.ns", "pod2.ns", "", nil, true)
+				_, ip2, _, err := subnet.GetRandomAddress("", "pod2.ns", "pod2.ns", "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ip2).To(Equal(ipam.IP("fd00::2")))
 
-				_, _, _, err = subnet.GetRandomAddress("pod3.ns", "pod3.ns", "", nil, true)
+				_, _, _, err = subnet.GetRandomAddress("", "pod3.ns", "pod3.ns", "", nil, true)
 				Expect(err).Should(MatchError(ipam.ErrNoAvailable))
 				Expect(subnet.V6FreeIPList).To(BeEmpty())
 
@@ -650,21 +724,21 @@ var _ = Describe("[IPAM]", func() {
 				subnet, err := ipam.NewSubnet(subnetName, "10.16.0.0/30,fd00::/126", nil)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				ipv4, ipv6, _, err := subnet.GetRandomAddress("pod1.ns", "pod1.ns", "", nil, true)
+				ipv4, ipv6, _, err := subnet.GetRandomAddress("", "pod1.ns", "pod1.ns", "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ipv4).To(Equal(ipam.IP("10.16.0.1")))
 				Expect(ipv6).To(Equal(ipam.IP("fd00::1")))
-				ipv4, ipv6, _, err = subnet.GetRandomAddress("pod1.ns", "pod1.ns", "", nil, true)
+				ipv4, ipv6, _, err = subnet.GetRandomAddress("", "pod1.ns", "pod1.ns", "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ipv4).To(Equal(ipam.IP("10.16.0.1")))
 				Expect(ipv6).To(Equal(ipam.IP("fd00::1")))
 
-				ipv4, ipv6, _, err = subnet.GetRandomAddress("pod2.ns", "pod2.ns", "", nil, true)
+				ipv4, ipv6, _, err = subnet.GetRandomAddress("", "pod2.ns", "pod2.ns", "", nil, true)
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(ipv4).To(Equal(ipam.IP("10.16.0.2")))
 				Expect(ipv6).To(Equal(ipam.IP("fd00::2")))
 
-				_, _, _, err = subnet.GetRandomAddress("pod3.ns", "pod3.ns", "", nil, true)
+				_, _, _, err = subnet.GetRandomAddress("", "pod3.ns", "pod3.ns", "", nil, true)
 				Expect(err).Should(MatchError(ipam.ErrNoAvailable))
 				Expect(subnet.V4FreeIPList).To(BeEmpty())
 				Expect(subnet.V6FreeIPList).To(BeEmpty())
@@ -691,4 +765,189 @@ var _ = Describe("[IPAM]", func() {
 			})
 		})
 	})
+
+	Describe("[IPPool]", func() {
+		Context("[IPv4]", func() {
+			It("invalid ippool", func() {
+				im := ipam.NewIPAM()
+				err := im.AddOrUpdateSubnet(subnetName, ipv4CIDR, v4Gw, ipv4ExcludeIPs)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				err = im.AddOrUpdateIPPool("invalid_subnet", "pool1", []string{"10.16.0.100"})
+				Expect(err).Should(MatchError(ipam.ErrNoAvailable))
+				err = im.AddOrUpdateIPPool(subnetName, "pool1", []string{"10.16.0.200..10.16.0.100"})
+				Expect(err).Should(HaveOccurred())
+				err = im.AddOrUpdateIPPool(subnetName, "pool1", []string{"10.17.0.0/24"})
+				Expect(err).Should(MatchError(ipam.ErrOutOfRange))
+
+				err = im.AddOrUpdateIPPool(subnetName, "pool1", []string{"10.16.0.100..10.16.0.110"})
+				Expect(err).ShouldNot(HaveOccurred())
+				err = im.AddOrUpdateIPPool(subnetName, "pool2", []string{"10.16.0.96/28"})
+				Expect(err).Should(MatchError(ipam.ErrConflict))
+			})
+
+			It("validate ippool", func() {
+				cidrBlocks := []string{ipv4CIDR, "10.17.0.0/24"}
+				others := map[string][]string{"pool2": {"10.16.0.96/28"}, "invalid": {"10.16.0.1..10.16.0.0"}}
+
+				err := ipam.ValidateIPPool("pool1", []string{"10.16.0.10..10.16.0.20", "10.17.0.0/25"}, cidrBlocks, others)
+				Expect(err).ShouldNot(HaveOccurred())
+				err = ipam.ValidateIPPool("pool1", []string{"10.16.0.200..10.16.0.100"}, cidrBlocks, others)
+				Expect(err).Should(HaveOccurred())
+				err = ipam.ValidateIPPool("pool1", []string{"10.18.0.0/24"}, cidrBlocks, others)
+				Expect(err).Should(MatchError(ipam.ErrOutOfRange))
+				// a range must not span several cidr blocks
+				err = ipam.ValidateIPPool("pool1", []string{"10.16.255.250..10.17.0.10"}, cidrBlocks, others)
+				Expect(err).Should(MatchError(ipam.ErrOutOfRange))
+				err = ipam.ValidateIPPool("pool1", []string{"10.16.0.100..10.16.0.110"}, cidrBlocks, others)
+				Expect(err).Should(MatchError(ipam.ErrConflict))
+			})
+
+			It("allocate from ippool", func() {
+				im := ipam.NewIPAM()
+				err := im.AddOrUpdateSubnet(subnetName, ipv4CIDR, v4Gw, ipv4ExcludeIPs)
+				Expect(err).ShouldNot(HaveOccurred())
+				_, _, _, err = im.GetStaticAddress("pod1.ns", "pod1.ns", "10.16.0.100", "", subnetName, true)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				err = im.AddOrUpdateIPPool(subnetName, "pool1", []string{"10.16.0.100..10.16.0.101", "10.16.0.20/30"})
+				Expect(err).ShouldNot(HaveOccurred())
+				v4Available, v4Using, _, _, err := im.IPPoolStatistics(subnetName, "pool1")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(v4Available).To(BeEquivalentTo(1))
+				Expect(v4Using).To(BeEquivalentTo(1))
+
+				ip, _, _, err := im.GetRandomAddress("pod2.ns", "pod2.ns", "", subnetName, "pool1", nil, true)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(ip).To(Equal("10.16.0.101"))
+				_, _, _, err = im.GetRandomAddress("pod3.ns", "pod3.ns", "", subnetName, "pool1", nil, true)
+				Expect(err).Should(MatchError(ipam.ErrNoAvailable))
+				_, _, _, err = im.GetRandomAddress("pod3.ns", "pod3.ns", "", subnetName, "pool2", nil, true)
+				Expect(err).Should(MatchError(ipam.ErrNoAvailable))
+
+				im.ReleaseAddressByPod("pod1.ns")
+				ip, _, _, err = im.GetRandomAddress("pod3.ns", "pod3.ns", "", subnetName, "pool1", nil, true)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(ip).To(Equal("10.16.0.100"))
+
+				v4Available, v4Using, _, _, err = im.IPPoolStatistics(subnetName, "pool1")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(v4Available).To(BeEquivalentTo(0))
+				Expect(v4Using).To(BeEquivalentTo(2))
+			})
+
+			It("ippool addresses are not allocated to unbound pods", func() {
+				im := ipam.NewIPAM()
+				err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/29", "10.16.0.1", []string{"10.16.0.1"})
+				Expect(err).ShouldNot(HaveOccurred())
+				err = im.AddOrUpdateIPPool(subnetName, "pool1", []string{"10.16.0.2..10.16.0.5"})
+				Expect(err).ShouldNot(HaveOccurred())
+
+				ip, _, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "", nil, true)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(ip).To(Equal("10.16.0.6"))
+				_, _, _, err = im.GetRandomAddress("pod2.ns", "pod2.ns", "", subnetName, "", nil, true)
+				Expect(err).Should(MatchError(ipam.ErrNoAvailable))
+
+				im.RemoveIPPool(subnetName, "pool1")
+				ip, _, _, err = im.GetRandomAddress("pod2.ns", "pod2.ns", "", subnetName, "", nil, true)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(ip).To(Equal("10.16.0.2"))
+			})
+
+			It("update subnet keeps ippool", func() {
+				im := ipam.NewIPAM()
+				err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/29", "10.16.0.1", []string{"10.16.0.1"})
+				Expect(err).ShouldNot(HaveOccurred())
+				err = im.AddOrUpdateIPPool(subnetName, "pool1", []string{"10.16.0.2..10.16.0.5"})
+				Expect(err).ShouldNot(HaveOccurred())
+				ip, _, _, err := im.GetRandomAddress("pod1.ns", "pod1.ns", "", subnetName, "pool1", nil, true)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(ip).To(Equal("10.16.0.2"))
+
+				err = im.AddOrUpdateSubnet(subnetName, "10.16.0.0/28", "10.16.0.1", []string{"10.16.0.1"})
+				Expect(err).ShouldNot(HaveOccurred())
+				v4Available, v4Using, _, _, err := im.IPPoolStatistics(subnetName, "pool1")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(v4Available).To(BeEquivalentTo(3))
+				Expect(v4Using).To(BeEquivalentTo(1))
+				Expect(im.Subnets[subnetName].V4FreeIPList).To(Equal(
+					ipam.IPRangeList{&ipam.IPRange{Start: "10.16.0.6", End: "10.16.0.14"}}))
+			})
+		})
+	})
+
+	Describe("[Snapshot]", func() {
+		It("restore dual stack subnet with ippool from snapshot", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/24,fd00::/112", "10.16.0.1,fd00::1", []string{"10.16.0.1", "fd00::1"})
+			Expect(err).ShouldNot(HaveOccurred())
+			err = im.AddOrUpdateIPPool(subnetName, "pool1", []string{"10.16.0.100..10.16.0.101", "fd00::100..fd00::101"})
+			Expect(err).ShouldNot(HaveOccurred())
+			_, _, _, err = im.GetStaticAddress("pod1.ns", "pod1.ns", "10.16.0.2,fd00::2", "00:11:22:33:44:55", subnetName, true)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, _, _, err = im.GetRandomAddress("pod2.ns", "pod2.ns", "", subnetName, "pool1", nil, true)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, _, _, err = im.GetStaticAddress("pod3.ns", "pod3.ns", "10.16.0.3,fd00::3", "", subnetName, true)
+			Expect(err).ShouldNot(HaveOccurred())
+			im.ReleaseAddressByPod("pod3.ns")
+
+			snapshot := im.Snapshot()
+			snapshot.ResourceVersions = map[string]string{"pod1.ns": "1"}
+			data, err := snapshot.Encode()
+			Expect(err).ShouldNot(HaveOccurred())
+			decoded, err := ipam.DecodeSnapshot(data)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(decoded).To(Equal(snapshot))
+			Expect(decoded.Subnets[subnetName].Matches("10.16.0.0/24,fd00::/112", "10.16.0.1,fd00::1", []string{"10.16.0.1", "fd00::1"})).To(BeTrue())
+			Expect(decoded.Subnets[subnetName].Matches("10.16.0.0/23,fd00::/112", "10.16.0.1,fd00::1", []string{"10.16.0.1", "fd00::1"})).To(BeFalse())
+			Expect(decoded.Subnets[subnetName].Nics()).To(ConsistOf("pod1.ns", "pod2.ns"))
+
+			restored := ipam.NewIPAM()
+			err = restored.Restore(decoded)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(restored.Snapshot()).To(Equal(im.Snapshot()))
+
+			addresses := restored.GetPodAddress("pod1.ns")
+			Expect(addresses).To(HaveLen(2))
+			Expect(addresses[0].Ip).To(Equal("10.16.0.2"))
+			Expect(addresses[1].Ip).To(Equal("fd00::2"))
+			Expect(addresses[0].Mac).To(Equal("00:11:22:33:44:55"))
+
+			v4Available, v4Using, _, _, err := restored.IPPoolStatistics(subnetName, "pool1")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(v4Available).To(BeEquivalentTo(1))
+			Expect(v4Using).To(BeEquivalentTo(1))
+
+			// released addresses are not reused before the free ones
+			ip, _, _, err := restored.GetRandomAddress("pod4.ns", "pod4.ns", "", subnetName, "", nil, true)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ip).To(Equal("10.16.0.4"))
+		})
+
+		It("release address by nic", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/24", "10.16.0.1", []string{"10.16.0.1"})
+			Expect(err).ShouldNot(HaveOccurred())
+			_, _, _, err = im.GetStaticAddress("pod1.ns", "pod1.ns", "10.16.0.2", "", subnetName, true)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, _, _, err = im.GetStaticAddress("pod1.ns", "pod1.ns.provider", "10.16.0.3", "", subnetName, true)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			im.ReleaseAddressByNic("pod1.ns.provider")
+			Expect(im.ContainAddress("10.16.0.3")).To(BeFalse())
+			Expect(im.ContainAddress("10.16.0.2")).To(BeTrue())
+			Expect(im.Subnets[subnetName].PodToNicList["pod1.ns"]).To(Equal([]string{"pod1.ns"}))
+		})
+
+		It("restore invalid snapshot", func() {
+			im := ipam.NewIPAM()
+			err := im.AddOrUpdateSubnet(subnetName, "10.16.0.0/24", "10.16.0.1", []string{"10.16.0.1"})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			err = im.Restore(&ipam.Snapshot{Subnets: map[string]*ipam.SubnetSnapshot{"invalid": {CIDR: "10.16.0.0"}}})
+			Expect(err).Should(HaveOccurred())
+			Expect(im.Subnets).To(HaveKey(subnetName))
+		})
+	})
 })